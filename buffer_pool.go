@@ -82,6 +82,21 @@ func getLargePacketBuffer() *packetBuffer {
 	return buf
 }
 
+// prewarmPacketBuffers allocates n packet buffers and puts them into the pool.
+// It is a no-op if n is not positive.
+func prewarmPacketBuffers(n int) {
+	if n <= 0 {
+		return
+	}
+	bufs := make([]*packetBuffer, n)
+	for i := range bufs {
+		bufs[i] = getPacketBuffer()
+	}
+	for _, b := range bufs {
+		b.Release()
+	}
+}
+
 func init() {
 	bufferPool.New = func() any {
 		return &packetBuffer{Data: make([]byte, 0, protocol.MaxPacketBufferSize)}
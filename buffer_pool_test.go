@@ -1,6 +1,9 @@
 package quic
 
 import (
+	"runtime"
+	"testing"
+
 	"github.com/quic-go/quic-go/internal/protocol"
 
 	. "github.com/onsi/ginkgo/v2"
@@ -46,6 +49,11 @@ var _ = Describe("Buffer Pool", func() {
 		Expect(func() { buf.Decrement() }).To(Panic())
 	})
 
+	It("doesn't panic when prewarming a non-positive number of buffers", func() {
+		Expect(func() { prewarmPacketBuffers(0) }).ToNot(Panic())
+		Expect(func() { prewarmPacketBuffers(-1) }).ToNot(Panic())
+	})
+
 	It("waits until all parts have been released", func() {
 		buf := getPacketBuffer()
 		buf.Split()
@@ -57,3 +65,31 @@ var _ = Describe("Buffer Pool", func() {
 		Expect(func() { buf.Decrement() }).To(Panic())
 	})
 })
+
+func BenchmarkPacketBufferBurst(b *testing.B) {
+	const burst = 256
+
+	run := func(b *testing.B, prewarm bool) {
+		b.ReportAllocs()
+		bufs := make([]*packetBuffer, burst)
+		for i := 0; i < b.N; i++ {
+			b.StopTimer()
+			// Two GC cycles clear the buffer pool, simulating a freshly started server.
+			runtime.GC()
+			runtime.GC()
+			if prewarm {
+				prewarmPacketBuffers(burst)
+			}
+			b.StartTimer()
+			for j := range bufs {
+				bufs[j] = getPacketBuffer()
+			}
+			for _, buf := range bufs {
+				buf.Release()
+			}
+		}
+	}
+
+	b.Run("cold", func(b *testing.B) { run(b, false) })
+	b.Run("prewarmed", func(b *testing.B) { run(b, true) })
+}
@@ -38,6 +38,7 @@ func (c *closedLocalConn) handlePacket(p receivedPacket) {
 	c.sendPacket(p.remoteAddr, p.info)
 }
 
+func (c *closedLocalConn) acceptsGreasedQUICBit() bool                { return false }
 func (c *closedLocalConn) destroy(error)                              {}
 func (c *closedLocalConn) closeWithTransportError(TransportErrorCode) {}
 
@@ -53,5 +54,6 @@ func newClosedRemoteConn() packetHandler {
 }
 
 func (c *closedRemoteConn) handlePacket(receivedPacket)                {}
+func (c *closedRemoteConn) acceptsGreasedQUICBit() bool                { return false }
 func (c *closedRemoteConn) destroy(error)                              {}
 func (c *closedRemoteConn) closeWithTransportError(TransportErrorCode) {}
@@ -1,10 +1,13 @@
 package quic
 
 import (
+	"errors"
 	"fmt"
+	"maps"
 	"time"
 
 	"github.com/quic-go/quic-go/internal/protocol"
+	"github.com/quic-go/quic-go/internal/wire"
 	"github.com/quic-go/quic-go/quicvarint"
 )
 
@@ -22,6 +25,22 @@ func (c *Config) maxRetryTokenAge() time.Duration {
 	return c.handshakeTimeout()
 }
 
+// ackDelay is the maximum time by which we delay sending ACKs.
+func (c *Config) ackDelay() time.Duration {
+	return max(0, c.MaxAckDelay)
+}
+
+// advertisedMaxAckDelay is the max_ack_delay transport parameter.
+// It includes the timer granularity, and is rounded up to full milliseconds,
+// since the transport parameter is encoded in milliseconds.
+func (c *Config) advertisedMaxAckDelay() time.Duration {
+	d := c.ackDelay() + protocol.TimerGranularity
+	if r := d % time.Millisecond; r > 0 {
+		d += time.Millisecond - r
+	}
+	return d
+}
+
 func validateConfig(config *Config) error {
 	if config == nil {
 		return nil
@@ -39,12 +58,75 @@ func validateConfig(config *Config) error {
 	if config.MaxConnectionReceiveWindow > quicvarint.Max {
 		config.MaxConnectionReceiveWindow = quicvarint.Max
 	}
+	// a stream can't carry more than 2^62-1 bytes
+	if config.MaxMessageSize > quicvarint.Max {
+		config.MaxMessageSize = quicvarint.Max
+	}
 	if config.InitialPacketSize > 0 && config.InitialPacketSize < protocol.MinInitialPacketSize {
 		config.InitialPacketSize = protocol.MinInitialPacketSize
 	}
 	if config.InitialPacketSize > protocol.MaxPacketBufferSize {
 		config.InitialPacketSize = protocol.MaxPacketBufferSize
 	}
+	if config.InitialCongestionWindow > protocol.MaxCongestionWindowPackets {
+		config.InitialCongestionWindow = protocol.MaxCongestionWindowPackets
+	}
+	// The max_ack_delay transport parameter includes the timer granularity.
+	if config.MaxAckDelay > protocol.MaxMaxAckDelay-protocol.TimerGranularity {
+		config.MaxAckDelay = protocol.MaxMaxAckDelay - protocol.TimerGranularity
+	}
+	if config.MaxCongestionWindow > 0 && config.MinCongestionWindow > config.MaxCongestionWindow {
+		return fmt.Errorf("invalid congestion window limits: minimum (%d) is larger than maximum (%d)", config.MinCongestionWindow, config.MaxCongestionWindow)
+	}
+	if config.MaxHandshakeRate < 0 {
+		return fmt.Errorf("invalid handshake rate: %d", config.MaxHandshakeRate)
+	}
+	if config.MaxPTOCount < 0 {
+		return fmt.Errorf("invalid PTO count: %d", config.MaxPTOCount)
+	}
+	if config.InitialRTT != 0 && (config.InitialRTT < protocol.TimerGranularity || config.InitialRTT > protocol.MaxInitialRTT) {
+		return fmt.Errorf("invalid initial RTT: %s", config.InitialRTT)
+	}
+	if config.MaxUndecryptablePackets < 0 {
+		return fmt.Errorf("invalid maximum number of undecryptable packets: %d", config.MaxUndecryptablePackets)
+	}
+	if config.ConnectionIDLength < 0 || config.ConnectionIDLength > protocol.MaxConnIDLen {
+		return fmt.Errorf("invalid connection ID length: %d", config.ConnectionIDLength)
+	}
+	if config.KeepAliveJitter < 0 {
+		return fmt.Errorf("invalid keep-alive jitter: %s", config.KeepAliveJitter)
+	}
+	if config.DatagramReceiveQueueLen < 0 {
+		return fmt.Errorf("invalid datagram receive queue length: %d", config.DatagramReceiveQueueLen)
+	}
+	if config.EnableExperimentalDatagramFlowControl && !config.EnableDatagrams {
+		return errors.New("datagram flow control requires datagrams to be enabled")
+	}
+	if config.RequireDatagrams && !config.EnableDatagrams {
+		return errors.New("requiring datagrams requires datagrams to be enabled")
+	}
+	if pa := config.PreferredAddress; pa != nil {
+		if pa.IPv4.IsValid() && !pa.IPv4.Addr().Is4() {
+			return fmt.Errorf("invalid preferred IPv4 address: %s", pa.IPv4)
+		}
+		if pa.IPv6.IsValid() && (!pa.IPv6.Addr().Is6() || pa.IPv6.Addr().Is4In6()) {
+			return fmt.Errorf("invalid preferred IPv6 address: %s", pa.IPv6)
+		}
+		if !pa.hasIPv4() && !pa.hasIPv6() {
+			return errors.New("preferred address needs an IPv4 or an IPv6 address")
+		}
+	}
+	for typ, handler := range config.CustomFrameHandlers {
+		if handler == nil {
+			return fmt.Errorf("no handler for custom frame type %#x", typ)
+		}
+		if typ > quicvarint.Max {
+			return fmt.Errorf("invalid custom frame type: %#x", typ)
+		}
+		if wire.IsStandardFrameType(typ) {
+			return fmt.Errorf("custom frame type %#x is a standard frame type", typ)
+		}
+	}
 	// check that all QUIC versions are actually supported
 	for _, v := range config.Versions {
 		if !protocol.IsValidVersion(v) {
@@ -88,6 +170,10 @@ func populateConfig(config *Config) *Config {
 	if maxConnectionReceiveWindow == 0 {
 		maxConnectionReceiveWindow = protocol.DefaultMaxReceiveConnectionFlowControlWindow
 	}
+	// The maximum windows bound the amount of data buffered for a stream and for the connection.
+	// Auto-tuning never grows a window beyond its maximum, so neither may the initial window.
+	initialStreamReceiveWindow = min(initialStreamReceiveWindow, maxStreamReceiveWindow)
+	initialConnectionReceiveWindow = min(initialConnectionReceiveWindow, maxConnectionReceiveWindow)
 	maxIncomingStreams := config.MaxIncomingStreams
 	if maxIncomingStreams == 0 {
 		maxIncomingStreams = protocol.DefaultMaxIncomingStreams
@@ -100,29 +186,90 @@ func populateConfig(config *Config) *Config {
 	} else if maxIncomingUniStreams < 0 {
 		maxIncomingUniStreams = 0
 	}
+	maxMessageSize := config.MaxMessageSize
+	if maxMessageSize == 0 {
+		maxMessageSize = protocol.DefaultMaxMessageSize
+	}
+	datagramReceiveQueueLen := config.DatagramReceiveQueueLen
+	if datagramReceiveQueueLen == 0 {
+		datagramReceiveQueueLen = maxDatagramRcvQueueLen
+	}
 	initialPacketSize := config.InitialPacketSize
 	if initialPacketSize == 0 {
 		initialPacketSize = protocol.InitialPacketSize
 	}
+	maxUndecryptablePackets := config.MaxUndecryptablePackets
+	if maxUndecryptablePackets == 0 {
+		maxUndecryptablePackets = protocol.MaxUndecryptablePackets
+	}
+	initialCongestionWindow := config.InitialCongestionWindow
+	if initialCongestionWindow == 0 {
+		initialCongestionWindow = protocol.InitialCongestionWindowPackets
+	}
+	maxAckDelay := config.MaxAckDelay
+	if maxAckDelay == 0 {
+		maxAckDelay = protocol.MaxAckDelay
+	}
 
 	return &Config{
-		GetConfigForClient:             config.GetConfigForClient,
-		Versions:                       versions,
-		HandshakeIdleTimeout:           handshakeIdleTimeout,
-		MaxIdleTimeout:                 idleTimeout,
-		KeepAlivePeriod:                config.KeepAlivePeriod,
-		InitialStreamReceiveWindow:     initialStreamReceiveWindow,
-		MaxStreamReceiveWindow:         maxStreamReceiveWindow,
-		InitialConnectionReceiveWindow: initialConnectionReceiveWindow,
-		MaxConnectionReceiveWindow:     maxConnectionReceiveWindow,
-		AllowConnectionWindowIncrease:  config.AllowConnectionWindowIncrease,
-		MaxIncomingStreams:             maxIncomingStreams,
-		MaxIncomingUniStreams:          maxIncomingUniStreams,
-		TokenStore:                     config.TokenStore,
-		EnableDatagrams:                config.EnableDatagrams,
-		InitialPacketSize:              initialPacketSize,
-		DisablePathMTUDiscovery:        config.DisablePathMTUDiscovery,
-		Allow0RTT:                      config.Allow0RTT,
-		Tracer:                         config.Tracer,
+		GetConfigForClient:                    config.GetConfigForClient,
+		Versions:                              versions,
+		ConnectionIDLength:                    config.ConnectionIDLength,
+		HandshakeIdleTimeout:                  handshakeIdleTimeout,
+		MaxIdleTimeout:                        idleTimeout,
+		MaxPTOCount:                           config.MaxPTOCount,
+		InitialRTT:                            config.InitialRTT,
+		MaxUndecryptablePackets:               maxUndecryptablePackets,
+		KeepAlivePeriod:                       config.KeepAlivePeriod,
+		KeepAliveJitter:                       config.KeepAliveJitter,
+		MaxAckDelay:                           maxAckDelay,
+		InitialStreamReceiveWindow:            initialStreamReceiveWindow,
+		MaxStreamReceiveWindow:                maxStreamReceiveWindow,
+		InitialConnectionReceiveWindow:        initialConnectionReceiveWindow,
+		MaxConnectionReceiveWindow:            maxConnectionReceiveWindow,
+		AllowConnectionWindowIncrease:         config.AllowConnectionWindowIncrease,
+		ConnectionIDUpdated:                   config.ConnectionIDUpdated,
+		MaxIncomingStreams:                    maxIncomingStreams,
+		MaxIncomingUniStreams:                 maxIncomingUniStreams,
+		MaxMessageSize:                        maxMessageSize,
+		TokenStore:                            config.TokenStore,
+		StoreNewToken:                         config.StoreNewToken,
+		GetStoredToken:                        config.GetStoredToken,
+		CongestionControlFactory:              config.CongestionControlFactory,
+		InitialCongestionWindow:               initialCongestionWindow,
+		MinCongestionWindow:                   config.MinCongestionWindow,
+		MaxCongestionWindow:                   config.MaxCongestionWindow,
+		EnableDatagrams:                       config.EnableDatagrams,
+		IdleTimeoutIgnoresDatagrams:           config.IdleTimeoutIgnoresDatagrams,
+		EnableResetStreamAt:                   config.EnableResetStreamAt,
+		EnableAckFrequency:                    config.EnableAckFrequency,
+		EnableQUICBitGreasing:                 config.EnableQUICBitGreasing,
+		EnableSpinBit:                         config.EnableSpinBit,
+		CustomFrameHandlers:                   maps.Clone(config.CustomFrameHandlers),
+		DatagramReceiveQueueLen:               datagramReceiveQueueLen,
+		RecordDatagramReceiveTime:             config.RecordDatagramReceiveTime,
+		EnableZeroCopyDatagrams:               config.EnableZeroCopyDatagrams,
+		EnableExperimentalDatagramFlowControl: config.EnableExperimentalDatagramFlowControl,
+		RequireDatagrams:                      config.RequireDatagrams,
+		InitialPacketSize:                     initialPacketSize,
+		DisablePathMTUDiscovery:               config.DisablePathMTUDiscovery,
+		DisableActiveMigration:                config.DisableActiveMigration,
+		PreferredAddress:                      config.PreferredAddress,
+		UsePreferredAddress:                   config.UsePreferredAddress,
+		DisableGSO:                            config.DisableGSO,
+		DisablePacing:                         config.DisablePacing,
+		DisableECN:                            config.DisableECN,
+		Allow0RTT:                             config.Allow0RTT,
+		MaxHandshakeRate:                      config.MaxHandshakeRate,
+		HandshakeOverflowPolicy:               config.HandshakeOverflowPolicy,
+		Clock:                                 config.Clock,
+		Tracer:                                config.Tracer,
+		OnPacketSent:                          config.OnPacketSent,
+		OnPacketReceived:                      config.OnPacketReceived,
+		OnFlowControlBlocked:                  config.OnFlowControlBlocked,
+		OnVersionNegotiated:                   config.OnVersionNegotiated,
+		AcceptUniStream:                       config.AcceptUniStream,
+		RejectedUniStreamErrorCode:            config.RejectedUniStreamErrorCode,
+		GetConfigForClientHello:               config.GetConfigForClientHello,
 	}
 }
@@ -4,10 +4,13 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"math"
+	"net/netip"
 	"reflect"
 	"time"
 
 	"github.com/quic-go/quic-go/internal/protocol"
+	"github.com/quic-go/quic-go/internal/utils"
 	"github.com/quic-go/quic-go/logging"
 	"github.com/quic-go/quic-go/quicvarint"
 
@@ -51,6 +54,103 @@ var _ = Describe("Config", func() {
 			Expect(conf.MaxConnectionReceiveWindow).To(BeEquivalentTo(uint64(quicvarint.Max)))
 		})
 
+		It("clips too large values for the maximum message size", func() {
+			conf := &Config{MaxMessageSize: math.MaxUint64}
+			Expect(validateConfig(conf)).To(Succeed())
+			Expect(conf.MaxMessageSize).To(BeEquivalentTo(uint64(quicvarint.Max)))
+		})
+
+		It("rejects negative datagram receive queue lengths", func() {
+			conf := &Config{DatagramReceiveQueueLen: -1}
+			Expect(validateConfig(conf)).To(MatchError("invalid datagram receive queue length: -1"))
+		})
+
+		It("rejects datagram flow control without datagram support", func() {
+			conf := &Config{EnableExperimentalDatagramFlowControl: true}
+			Expect(validateConfig(conf)).To(MatchError("datagram flow control requires datagrams to be enabled"))
+			conf.EnableDatagrams = true
+			Expect(validateConfig(conf)).To(Succeed())
+		})
+
+		It("rejects requiring datagrams without datagram support", func() {
+			conf := &Config{RequireDatagrams: true}
+			Expect(validateConfig(conf)).To(MatchError("requiring datagrams requires datagrams to be enabled"))
+			conf.EnableDatagrams = true
+			Expect(validateConfig(conf)).To(Succeed())
+		})
+
+		It("validates the preferred address", func() {
+			conf := &Config{PreferredAddress: &PreferredAddress{}}
+			Expect(validateConfig(conf)).To(MatchError("preferred address needs an IPv4 or an IPv6 address"))
+			conf.PreferredAddress = &PreferredAddress{IPv4: netip.MustParseAddrPort("[::1]:443")}
+			Expect(validateConfig(conf)).To(MatchError("invalid preferred IPv4 address: [::1]:443"))
+			conf.PreferredAddress = &PreferredAddress{IPv6: netip.MustParseAddrPort("[::ffff:1.2.3.4]:443")}
+			Expect(validateConfig(conf)).To(MatchError("invalid preferred IPv6 address: [::ffff:1.2.3.4]:443"))
+			conf.PreferredAddress = &PreferredAddress{IPv4: netip.MustParseAddrPort("1.2.3.4:443")}
+			Expect(validateConfig(conf)).To(Succeed())
+			conf.PreferredAddress = &PreferredAddress{IPv6: netip.MustParseAddrPort("[2001:db8::1]:443")}
+			Expect(validateConfig(conf)).To(Succeed())
+		})
+
+		It("rejects negative handshake rates", func() {
+			conf := &Config{MaxHandshakeRate: -1}
+			Expect(validateConfig(conf)).To(MatchError("invalid handshake rate: -1"))
+		})
+
+		It("rejects negative PTO counts", func() {
+			conf := &Config{MaxPTOCount: -1}
+			Expect(validateConfig(conf)).To(MatchError("invalid PTO count: -1"))
+		})
+
+		It("validates the initial RTT", func() {
+			Expect(validateConfig(&Config{InitialRTT: -time.Second})).To(MatchError("invalid initial RTT: -1s"))
+			Expect(validateConfig(&Config{InitialRTT: time.Microsecond})).To(MatchError("invalid initial RTT: 1µs"))
+			Expect(validateConfig(&Config{InitialRTT: time.Minute})).To(MatchError("invalid initial RTT: 1m0s"))
+			Expect(validateConfig(&Config{InitialRTT: 600 * time.Millisecond})).To(Succeed())
+		})
+
+		It("rejects a negative maximum number of undecryptable packets", func() {
+			conf := &Config{MaxUndecryptablePackets: -1}
+			Expect(validateConfig(conf)).To(MatchError("invalid maximum number of undecryptable packets: -1"))
+		})
+
+		It("rejects invalid connection ID lengths", func() {
+			Expect(validateConfig(&Config{ConnectionIDLength: -1})).To(MatchError("invalid connection ID length: -1"))
+			Expect(validateConfig(&Config{ConnectionIDLength: 21})).To(MatchError("invalid connection ID length: 21"))
+			Expect(validateConfig(&Config{ConnectionIDLength: 20})).To(Succeed())
+		})
+
+		It("rejects negative keep-alive jitter", func() {
+			conf := &Config{KeepAliveJitter: -time.Second}
+			Expect(validateConfig(conf)).To(MatchError("invalid keep-alive jitter: -1s"))
+		})
+
+		It("rejects invalid custom frame types", func() {
+			handler := func(Connection, []byte) {}
+			conf := &Config{CustomFrameHandlers: map[uint64]func(Connection, []byte){0x1337: handler}}
+			Expect(validateConfig(conf)).To(Succeed())
+			conf = &Config{CustomFrameHandlers: map[uint64]func(Connection, []byte){0x24: handler}}
+			Expect(validateConfig(conf)).To(MatchError("custom frame type 0x24 is a standard frame type"))
+			conf = &Config{CustomFrameHandlers: map[uint64]func(Connection, []byte){quicvarint.Max + 1: handler}}
+			Expect(validateConfig(conf)).To(MatchError("invalid custom frame type: 0x4000000000000000"))
+			conf = &Config{CustomFrameHandlers: map[uint64]func(Connection, []byte){0x1337: nil}}
+			Expect(validateConfig(conf)).To(MatchError("no handler for custom frame type 0x1337"))
+		})
+
+		It("clips too large initial congestion windows", func() {
+			conf := &Config{InitialCongestionWindow: protocol.MaxCongestionWindowPackets + 1}
+			Expect(validateConfig(conf)).To(Succeed())
+			Expect(conf.InitialCongestionWindow).To(BeEquivalentTo(protocol.MaxCongestionWindowPackets))
+		})
+
+		It("validates the congestion window limits", func() {
+			Expect(validateConfig(&Config{MinCongestionWindow: 5000, MaxCongestionWindow: 5000})).To(Succeed())
+			Expect(validateConfig(&Config{MinCongestionWindow: 5000})).To(Succeed())
+			Expect(validateConfig(&Config{MaxCongestionWindow: 5000})).To(Succeed())
+			conf := &Config{MinCongestionWindow: 5001, MaxCongestionWindow: 5000}
+			Expect(validateConfig(conf)).To(MatchError("invalid congestion window limits: minimum (5001) is larger than maximum (5000)"))
+		})
+
 		It("increases too small packet sizes", func() {
 			conf := &Config{InitialPacketSize: 10}
 			Expect(validateConfig(conf)).To(Succeed())
@@ -63,6 +163,13 @@ var _ = Describe("Config", func() {
 			Expect(conf.InitialPacketSize).To(BeEquivalentTo(protocol.MaxPacketBufferSize))
 		})
 
+		It("clips too large max ack delays", func() {
+			conf := &Config{MaxAckDelay: protocol.MaxMaxAckDelay}
+			Expect(validateConfig(conf)).To(Succeed())
+			Expect(conf.MaxAckDelay).To(Equal(protocol.MaxMaxAckDelay - protocol.TimerGranularity))
+			Expect(conf.advertisedMaxAckDelay()).To(Equal(protocol.MaxMaxAckDelay))
+		})
+
 		It("doesn't modify the InitialPacketSize if it is unset", func() {
 			conf := &Config{InitialPacketSize: 0}
 			Expect(validateConfig(conf)).To(Succeed())
@@ -83,7 +190,7 @@ var _ = Describe("Config", func() {
 			}
 
 			switch fn := typ.Field(i).Name; fn {
-			case "GetConfigForClient", "RequireAddressValidation", "GetLogWriter", "AllowConnectionWindowIncrease", "Tracer":
+			case "GetConfigForClient", "GetConfigForClientHello", "RequireAddressValidation", "GetLogWriter", "AllowConnectionWindowIncrease", "ConnectionIDUpdated", "CongestionControlFactory", "Tracer", "OnPacketSent", "OnPacketReceived", "OnFlowControlBlocked", "OnVersionNegotiated", "AcceptUniStream", "CustomFrameHandlers", "StoreNewToken", "GetStoredToken":
 				// Can't compare functions.
 			case "Versions":
 				f.Set(reflect.ValueOf([]Version{1, 2, 3}))
@@ -97,32 +204,90 @@ var _ = Describe("Config", func() {
 				f.Set(reflect.ValueOf(time.Hour))
 			case "TokenStore":
 				f.Set(reflect.ValueOf(NewLRUTokenStore(2, 3)))
+			case "Clock":
+				f.Set(reflect.ValueOf(utils.DefaultClock{}))
 			case "InitialStreamReceiveWindow":
 				f.Set(reflect.ValueOf(uint64(1234)))
 			case "MaxStreamReceiveWindow":
-				f.Set(reflect.ValueOf(uint64(9)))
+				f.Set(reflect.ValueOf(uint64(12340)))
 			case "InitialConnectionReceiveWindow":
 				f.Set(reflect.ValueOf(uint64(4321)))
 			case "MaxConnectionReceiveWindow":
-				f.Set(reflect.ValueOf(uint64(10)))
+				f.Set(reflect.ValueOf(uint64(43210)))
 			case "MaxIncomingStreams":
 				f.Set(reflect.ValueOf(int64(11)))
 			case "MaxIncomingUniStreams":
 				f.Set(reflect.ValueOf(int64(12)))
+			case "RejectedUniStreamErrorCode":
+				f.Set(reflect.ValueOf(StreamErrorCode(0x103)))
+			case "MaxMessageSize":
+				f.Set(reflect.ValueOf(uint64(1 << 16)))
 			case "StatelessResetKey":
 				f.Set(reflect.ValueOf(&StatelessResetKey{1, 2, 3, 4}))
 			case "KeepAlivePeriod":
 				f.Set(reflect.ValueOf(time.Second))
+			case "KeepAliveJitter":
+				f.Set(reflect.ValueOf(100 * time.Millisecond))
+			case "MaxAckDelay":
+				f.Set(reflect.ValueOf(10 * time.Millisecond))
 			case "EnableDatagrams":
 				f.Set(reflect.ValueOf(true))
+			case "IdleTimeoutIgnoresDatagrams":
+				f.Set(reflect.ValueOf(true))
+			case "EnableResetStreamAt":
+				f.Set(reflect.ValueOf(true))
+			case "EnableAckFrequency":
+				f.Set(reflect.ValueOf(true))
+			case "EnableQUICBitGreasing":
+				f.Set(reflect.ValueOf(true))
+			case "EnableSpinBit":
+				f.Set(reflect.ValueOf(true))
+			case "InitialCongestionWindow":
+				f.Set(reflect.ValueOf(uint32(100)))
+			case "MinCongestionWindow":
+				f.Set(reflect.ValueOf(uint64(12000)))
+			case "MaxCongestionWindow":
+				f.Set(reflect.ValueOf(uint64(120000)))
+			case "DatagramReceiveQueueLen":
+				f.Set(reflect.ValueOf(42))
+			case "RecordDatagramReceiveTime":
+				f.Set(reflect.ValueOf(true))
+			case "EnableZeroCopyDatagrams":
+				f.Set(reflect.ValueOf(true))
+			case "EnableExperimentalDatagramFlowControl":
+				f.Set(reflect.ValueOf(true))
+			case "RequireDatagrams":
+				f.Set(reflect.ValueOf(true))
 			case "DisableVersionNegotiationPackets":
 				f.Set(reflect.ValueOf(true))
 			case "InitialPacketSize":
 				f.Set(reflect.ValueOf(uint16(1350)))
 			case "DisablePathMTUDiscovery":
 				f.Set(reflect.ValueOf(true))
+			case "DisableActiveMigration":
+				f.Set(reflect.ValueOf(true))
+			case "PreferredAddress":
+				f.Set(reflect.ValueOf(&PreferredAddress{IPv4: netip.MustParseAddrPort("1.2.3.4:443")}))
+			case "UsePreferredAddress":
+				f.Set(reflect.ValueOf(true))
+			case "DisableGSO":
+				f.Set(reflect.ValueOf(true))
+			case "DisablePacing":
+				f.Set(reflect.ValueOf(true))
+			case "DisableECN":
+				f.Set(reflect.ValueOf(true))
 			case "Allow0RTT":
 				f.Set(reflect.ValueOf(true))
+			case "MaxPTOCount":
+				f.Set(reflect.ValueOf(5))
+			case "InitialRTT":
+				f.Set(reflect.ValueOf(time.Second))
+			case "MaxUndecryptablePackets":
+				f.Set(reflect.ValueOf(100))
+			case "MaxHandshakeRate":
+				f.Set(reflect.ValueOf(100))
+			case "HandshakeOverflowPolicy":
+				f.Set(reflect.ValueOf(HandshakeOverflowRetry))
 			default:
 				Fail(fmt.Sprintf("all fields must be accounted for, but saw unknown field %q", fn))
 			}
@@ -130,6 +295,15 @@ var _ = Describe("Config", func() {
 		return c
 	}
 
+	It("advertises the max ack delay including the timer granularity, rounded up to milliseconds", func() {
+		Expect(populateConfig(&Config{}).advertisedMaxAckDelay()).To(Equal(protocol.MaxAckDelay + protocol.TimerGranularity))
+		Expect((&Config{MaxAckDelay: 5 * time.Millisecond}).advertisedMaxAckDelay()).To(Equal(6 * time.Millisecond))
+		Expect((&Config{MaxAckDelay: 1500 * time.Microsecond}).advertisedMaxAckDelay()).To(Equal(3 * time.Millisecond))
+		c := &Config{MaxAckDelay: -1}
+		Expect(c.ackDelay()).To(BeZero())
+		Expect(c.advertisedMaxAckDelay()).To(Equal(protocol.TimerGranularity))
+	})
+
 	It("uses twice the handshake idle timeouts for the handshake timeout", func() {
 		c := &Config{HandshakeIdleTimeout: time.Second * 11 / 2}
 		Expect(c.handshakeTimeout()).To(Equal(11 * time.Second))
@@ -185,8 +359,39 @@ var _ = Describe("Config", func() {
 			Expect(c.MaxConnectionReceiveWindow).To(BeEquivalentTo(protocol.DefaultMaxReceiveConnectionFlowControlWindow))
 			Expect(c.MaxIncomingStreams).To(BeEquivalentTo(protocol.DefaultMaxIncomingStreams))
 			Expect(c.MaxIncomingUniStreams).To(BeEquivalentTo(protocol.DefaultMaxIncomingUniStreams))
+			Expect(c.MaxMessageSize).To(BeEquivalentTo(protocol.DefaultMaxMessageSize))
+			Expect(c.DatagramReceiveQueueLen).To(Equal(maxDatagramRcvQueueLen))
+			Expect(c.InitialCongestionWindow).To(BeEquivalentTo(protocol.InitialCongestionWindowPackets))
+			Expect(c.MaxUndecryptablePackets).To(Equal(protocol.MaxUndecryptablePackets))
+			Expect(c.MaxAckDelay).To(Equal(protocol.MaxAckDelay))
 			Expect(c.DisablePathMTUDiscovery).To(BeFalse())
 			Expect(c.GetConfigForClient).To(BeNil())
 		})
+
+		It("copies the custom frame handlers", func() {
+			handlers := map[uint64]func(Connection, []byte){0x1337: func(Connection, []byte) {}}
+			c := populateConfig(&Config{CustomFrameHandlers: handlers})
+			Expect(c.CustomFrameHandlers).To(HaveKey(uint64(0x1337)))
+			// modifying the application's map doesn't affect the populated config
+			delete(handlers, 0x1337)
+			handlers[0x1338] = func(Connection, []byte) {}
+			Expect(c.CustomFrameHandlers).To(HaveLen(1))
+			Expect(c.CustomFrameHandlers).To(HaveKey(uint64(0x1337)))
+		})
+
+		It("limits the initial receive windows to the maximum receive windows", func() {
+			c := populateConfig(&Config{
+				MaxStreamReceiveWindow:     1000,
+				MaxConnectionReceiveWindow: 2000,
+			})
+			Expect(c.InitialStreamReceiveWindow).To(BeEquivalentTo(1000))
+			Expect(c.InitialConnectionReceiveWindow).To(BeEquivalentTo(2000))
+			c = populateConfig(&Config{
+				InitialStreamReceiveWindow:     1 << 30,
+				InitialConnectionReceiveWindow: 1 << 30,
+			})
+			Expect(c.InitialStreamReceiveWindow).To(BeEquivalentTo(protocol.DefaultMaxReceiveStreamFlowControlWindow))
+			Expect(c.InitialConnectionReceiveWindow).To(BeEquivalentTo(protocol.DefaultMaxReceiveConnectionFlowControlWindow))
+		})
 	})
 })
@@ -56,9 +56,7 @@ func (m *connIDGenerator) SetMaxActiveConnIDs(limit uint64) error {
 	// The active_connection_id_limit transport parameter is the number of
 	// connection IDs the peer will store. This limit includes the connection ID
 	// used during the handshake, and the one sent in the preferred_address
-	// transport parameter.
-	// We currently don't send the preferred_address transport parameter,
-	// so we can issue (limit - 1) connection IDs.
+	// transport parameter (if any), both of which are contained in activeSrcConnIDs.
 	for i := uint64(len(m.activeSrcConnIDs)); i < min(limit, protocol.MaxIssuedConnectionIDs); i++ {
 		if err := m.issueNewConnID(); err != nil {
 			return err
@@ -67,6 +65,20 @@ func (m *connIDGenerator) SetMaxActiveConnIDs(limit uint64) error {
 	return nil
 }
 
+// IssuePreferredAddressConnID issues the connection ID with sequence number 1,
+// which is sent in the preferred_address transport parameter.
+// It must be called before any other connection ID is issued.
+func (m *connIDGenerator) IssuePreferredAddressConnID() (protocol.ConnectionID, protocol.StatelessResetToken, error) {
+	connID, err := m.generator.GenerateConnectionID()
+	if err != nil {
+		return protocol.ConnectionID{}, protocol.StatelessResetToken{}, err
+	}
+	m.highestSeq = 1
+	m.activeSrcConnIDs[1] = connID
+	m.addConnectionID(connID)
+	return connID, m.getStatelessResetToken(connID), nil
+}
+
 func (m *connIDGenerator) Retire(seq uint64, sentWithDestConnID protocol.ConnectionID) error {
 	if seq > m.highestSeq {
 		return &qerr.TransportError{
@@ -117,22 +129,27 @@ func (m *connIDGenerator) SetHandshakeComplete() {
 	}
 }
 
-func (m *connIDGenerator) RemoveAll() {
+// ActiveConnIDs returns all connection IDs that are currently active.
+func (m *connIDGenerator) ActiveConnIDs() []protocol.ConnectionID {
+	connIDs := make([]protocol.ConnectionID, 0, len(m.activeSrcConnIDs)+1)
 	if m.initialClientDestConnID != nil {
-		m.removeConnectionID(*m.initialClientDestConnID)
+		connIDs = append(connIDs, *m.initialClientDestConnID)
 	}
 	for _, connID := range m.activeSrcConnIDs {
-		m.removeConnectionID(connID)
+		connIDs = append(connIDs, connID)
 	}
+	return connIDs
 }
 
-func (m *connIDGenerator) ReplaceWithClosed(connClose []byte) {
-	connIDs := make([]protocol.ConnectionID, 0, len(m.activeSrcConnIDs)+1)
+func (m *connIDGenerator) RemoveAll() {
 	if m.initialClientDestConnID != nil {
-		connIDs = append(connIDs, *m.initialClientDestConnID)
+		m.removeConnectionID(*m.initialClientDestConnID)
 	}
 	for _, connID := range m.activeSrcConnIDs {
-		connIDs = append(connIDs, connID)
+		m.removeConnectionID(connID)
 	}
-	m.replaceWithClosed(connIDs, connClose)
+}
+
+func (m *connIDGenerator) ReplaceWithClosed(connClose []byte) {
+	m.replaceWithClosed(m.ActiveConnIDs(), connClose)
 }
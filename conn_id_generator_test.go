@@ -72,6 +72,23 @@ var _ = Describe("Connection ID Generator", func() {
 		Expect(queuedFrames).To(HaveLen(protocol.MaxIssuedConnectionIDs - 1))
 	})
 
+	It("issues the connection ID for the preferred address", func() {
+		connID, token, err := g.IssuePreferredAddressConnID()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(connID.Len()).To(Equal(7))
+		Expect(token).To(Equal(connIDToToken(connID)))
+		Expect(addedConnIDs).To(Equal([]protocol.ConnectionID{connID}))
+		Expect(queuedFrames).To(BeEmpty())
+		// the preferred address connection ID counts towards the limit
+		Expect(g.SetMaxActiveConnIDs(4)).To(Succeed())
+		Expect(queuedFrames).To(HaveLen(2))
+		Expect(queuedFrames[0].(*wire.NewConnectionIDFrame).SequenceNumber).To(BeEquivalentTo(2))
+		Expect(queuedFrames[1].(*wire.NewConnectionIDFrame).SequenceNumber).To(BeEquivalentTo(3))
+		// the peer can retire it
+		Expect(g.Retire(1, protocol.ParseConnectionID([]byte{9, 9, 9}))).To(Succeed())
+		Expect(retiredConnIDs).To(Equal([]protocol.ConnectionID{connID}))
+	})
+
 	// SetMaxActiveConnIDs is called twice when dialing a 0-RTT connection:
 	// once for the restored from the old connections, once when we receive the transport parameters
 	Context("dealing with 0-RTT", func() {
@@ -159,6 +176,18 @@ var _ = Describe("Connection ID Generator", func() {
 		Expect(retiredConnIDs[0]).To(Equal(initialClientDestConnID))
 	})
 
+	It("returns the active connection IDs", func() {
+		Expect(g.SetMaxActiveConnIDs(3)).To(Succeed())
+		Expect(queuedFrames).To(HaveLen(2))
+		Expect(g.Retire(1, protocol.ConnectionID{})).To(Succeed())
+		connIDs := g.ActiveConnIDs()
+		Expect(connIDs).To(HaveLen(4)) // initial conn ID, initial client dest conn id, and 2 issued ones
+		Expect(connIDs).To(ContainElement(initialConnID))
+		Expect(connIDs).To(ContainElement(initialClientDestConnID))
+		Expect(connIDs).ToNot(ContainElement(queuedFrames[0].(*wire.NewConnectionIDFrame).ConnectionID))
+		Expect(connIDs).To(ContainElement(queuedFrames[1].(*wire.NewConnectionIDFrame).ConnectionID))
+	})
+
 	It("removes all connection IDs", func() {
 		Expect(g.SetMaxActiveConnIDs(5)).To(Succeed())
 		Expect(queuedFrames).To(HaveLen(4))
@@ -24,6 +24,8 @@ type connIDManager struct {
 	highestRetired            uint64
 	activeConnectionID        protocol.ConnectionID
 	activeStatelessResetToken *protocol.StatelessResetToken
+	// reserved is a connection ID that was taken out of the queue to be used on a backup path.
+	reserved *newConnID
 
 	// We change the connection ID after sending on average
 	// protocol.PacketsPerConnectionID packets. The actual value is randomized
@@ -35,6 +37,12 @@ type connIDManager struct {
 	addStatelessResetToken    func(protocol.StatelessResetToken)
 	removeStatelessResetToken func(protocol.StatelessResetToken)
 	queueControlFrame         func(wire.Frame)
+
+	// connIDsUpdated is called when connection IDs are added to or retired from the set of active connection IDs.
+	// It may be nil.
+	connIDsUpdated func(retired, active []protocol.ConnectionID)
+	updated        bool
+	retired        []protocol.ConnectionID // connection IDs retired since the last call to connIDsUpdated
 }
 
 func newConnIDManager(
@@ -42,26 +50,37 @@ func newConnIDManager(
 	addStatelessResetToken func(protocol.StatelessResetToken),
 	removeStatelessResetToken func(protocol.StatelessResetToken),
 	queueControlFrame func(wire.Frame),
+	connIDsUpdated func(retired, active []protocol.ConnectionID),
 ) *connIDManager {
 	return &connIDManager{
 		activeConnectionID:        initialDestConnID,
 		addStatelessResetToken:    addStatelessResetToken,
 		removeStatelessResetToken: removeStatelessResetToken,
 		queueControlFrame:         queueControlFrame,
+		connIDsUpdated:            connIDsUpdated,
 	}
 }
 
 func (h *connIDManager) AddFromPreferredAddress(connID protocol.ConnectionID, resetToken protocol.StatelessResetToken) error {
-	return h.addConnectionID(1, connID, resetToken)
+	if err := h.addConnectionID(1, connID, resetToken); err != nil {
+		return err
+	}
+	h.reportUpdate()
+	return nil
 }
 
 func (h *connIDManager) Add(f *wire.NewConnectionIDFrame) error {
 	if err := h.add(f); err != nil {
 		return err
 	}
-	if h.queue.Len() >= protocol.MaxActiveConnectionIDs {
+	numConnIDs := h.queue.Len()
+	if h.reserved != nil {
+		numConnIDs++
+	}
+	if numConnIDs >= protocol.MaxActiveConnectionIDs {
 		return &qerr.TransportError{ErrorCode: qerr.ConnectionIDLimitError}
 	}
+	h.reportUpdate()
 	return nil
 }
 
@@ -87,14 +106,21 @@ func (h *connIDManager) add(f *wire.NewConnectionIDFrame) error {
 			h.queueControlFrame(&wire.RetireConnectionIDFrame{
 				SequenceNumber: el.Value.SequenceNumber,
 			})
+			h.retire(el.Value.ConnectionID)
 			h.queue.Remove(el)
 		}
+		if h.reserved != nil && h.reserved.SequenceNumber < f.RetirePriorTo {
+			h.releaseReserved()
+		}
 		h.highestRetired = f.RetirePriorTo
 	}
 
 	if f.SequenceNumber == h.activeSequenceNumber {
 		return nil
 	}
+	if h.reserved != nil && f.SequenceNumber == h.reserved.SequenceNumber {
+		return nil
+	}
 
 	if err := h.addConnectionID(f.SequenceNumber, f.ConnectionID, f.StatelessResetToken); err != nil {
 		return err
@@ -116,6 +142,7 @@ func (h *connIDManager) addConnectionID(seq uint64, connID protocol.ConnectionID
 			ConnectionID:        connID,
 			StatelessResetToken: resetToken,
 		})
+		h.updated = true
 		return nil
 	}
 	// insert a new element somewhere in the middle
@@ -135,6 +162,7 @@ func (h *connIDManager) addConnectionID(seq uint64, connID protocol.ConnectionID
 				ConnectionID:        connID,
 				StatelessResetToken: resetToken,
 			}, el)
+			h.updated = true
 			break
 		}
 	}
@@ -142,23 +170,56 @@ func (h *connIDManager) addConnectionID(seq uint64, connID protocol.ConnectionID
 }
 
 func (h *connIDManager) updateConnectionID() {
+	h.switchTo(h.queue.Remove(h.queue.Front()))
+}
+
+func (h *connIDManager) switchTo(next newConnID) {
 	h.queueControlFrame(&wire.RetireConnectionIDFrame{
 		SequenceNumber: h.activeSequenceNumber,
 	})
 	h.highestRetired = max(h.highestRetired, h.activeSequenceNumber)
+	h.retire(h.activeConnectionID)
 	if h.activeStatelessResetToken != nil {
 		h.removeStatelessResetToken(*h.activeStatelessResetToken)
 	}
 
-	front := h.queue.Remove(h.queue.Front())
-	h.activeSequenceNumber = front.SequenceNumber
-	h.activeConnectionID = front.ConnectionID
-	h.activeStatelessResetToken = &front.StatelessResetToken
+	h.activeSequenceNumber = next.SequenceNumber
+	h.activeConnectionID = next.ConnectionID
+	h.activeStatelessResetToken = &next.StatelessResetToken
 	h.packetsSinceLastChange = 0
 	h.packetsPerConnectionID = protocol.PacketsPerConnectionID/2 + uint32(h.rand.Int31n(protocol.PacketsPerConnectionID))
 	h.addStatelessResetToken(*h.activeStatelessResetToken)
 }
 
+func (h *connIDManager) retire(connID protocol.ConnectionID) {
+	h.updated = true
+	if h.connIDsUpdated != nil {
+		h.retired = append(h.retired, connID)
+	}
+}
+
+// reportUpdate calls connIDsUpdated, if the set of active connection IDs changed since the last call.
+func (h *connIDManager) reportUpdate() {
+	if !h.updated {
+		return
+	}
+	h.updated = false
+	if h.connIDsUpdated == nil {
+		return
+	}
+	active := make([]protocol.ConnectionID, 0, 2+h.queue.Len())
+	active = append(active, h.activeConnectionID)
+	if h.reserved != nil {
+		active = append(active, h.reserved.ConnectionID)
+	}
+	for el := h.queue.Front(); el != nil; el = el.Next() {
+		active = append(active, el.Value.ConnectionID)
+	}
+	retired := h.retired
+	h.retired = nil
+	h.connIDsUpdated(retired, active)
+}
+
 func (h *connIDManager) Close() {
 	if h.activeStatelessResetToken != nil {
 		h.removeStatelessResetToken(*h.activeStatelessResetToken)
@@ -205,10 +266,77 @@ func (h *connIDManager) shouldUpdateConnID() bool {
 func (h *connIDManager) Get() protocol.ConnectionID {
 	if h.shouldUpdateConnID() {
 		h.updateConnectionID()
+		h.reportUpdate()
 	}
 	return h.activeConnectionID
 }
 
+// PeekNext returns the connection ID that will be used after the next change of the connection ID.
+// It returns false if the peer hasn't provided any unused connection IDs.
+func (h *connIDManager) PeekNext() (protocol.ConnectionID, bool) {
+	if h.queue.Len() == 0 {
+		return protocol.ConnectionID{}, false
+	}
+	return h.queue.Front().Value.ConnectionID, true
+}
+
+// SwitchToNext retires the active connection ID and switches to the next one.
+// It is called when the connection is migrated to a new path.
+func (h *connIDManager) SwitchToNext() {
+	if h.queue.Len() == 0 {
+		return
+	}
+	h.updateConnectionID()
+	h.reportUpdate()
+}
+
+// Reserve takes the next connection ID out of the queue, such that it can be used on a backup path.
+// Only a single connection ID can be reserved at a time.
+func (h *connIDManager) Reserve() (protocol.ConnectionID, bool) {
+	if h.reserved != nil || h.queue.Len() == 0 {
+		return protocol.ConnectionID{}, false
+	}
+	front := h.queue.Remove(h.queue.Front())
+	h.reserved = &front
+	return front.ConnectionID, true
+}
+
+// Reserved returns the reserved connection ID.
+// It returns false if no connection ID is reserved, or if the peer asked us to retire it.
+func (h *connIDManager) Reserved() (protocol.ConnectionID, bool) {
+	if h.reserved == nil {
+		return protocol.ConnectionID{}, false
+	}
+	return h.reserved.ConnectionID, true
+}
+
+// SwitchToReserved retires the active connection ID and switches to the reserved one.
+// It is called when the connection switches to the backup path.
+func (h *connIDManager) SwitchToReserved() {
+	if h.reserved == nil {
+		return
+	}
+	next := *h.reserved
+	h.reserved = nil
+	h.switchTo(next)
+	h.reportUpdate()
+}
+
+// ReleaseReserved retires the reserved connection ID.
+func (h *connIDManager) ReleaseReserved() {
+	if h.reserved == nil {
+		return
+	}
+	h.releaseReserved()
+	h.reportUpdate()
+}
+
+func (h *connIDManager) releaseReserved() {
+	h.queueControlFrame(&wire.RetireConnectionIDFrame{SequenceNumber: h.reserved.SequenceNumber})
+	h.retire(h.reserved.ConnectionID)
+	h.reserved = nil
+}
+
 func (h *connIDManager) SetHandshakeComplete() {
 	h.handshakeComplete = true
 }
@@ -29,7 +29,9 @@ var _ = Describe("Connection ID Manager", func() {
 			func(f wire.Frame,
 			) {
 				frameQueue = append(frameQueue, f)
-			})
+			},
+			nil,
+		)
 	})
 
 	get := func() (protocol.ConnectionID, protocol.StatelessResetToken) {
@@ -347,6 +349,158 @@ var _ = Describe("Connection ID Manager", func() {
 		Expect(removedTokens[0]).To(Equal(protocol.StatelessResetToken{1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1}))
 	})
 
+	It("switches to the next connection ID when migrating", func() {
+		_, ok := m.PeekNext()
+		Expect(ok).To(BeFalse())
+		m.SwitchToNext() // no-op, since there's no connection ID to switch to
+		Expect(m.Get()).To(Equal(initialConnID))
+		Expect(frameQueue).To(BeEmpty())
+
+		Expect(m.Add(&wire.NewConnectionIDFrame{
+			SequenceNumber:      1,
+			ConnectionID:        protocol.ParseConnectionID([]byte{1, 2, 3, 4}),
+			StatelessResetToken: protocol.StatelessResetToken{1},
+		})).To(Succeed())
+		connID, ok := m.PeekNext()
+		Expect(ok).To(BeTrue())
+		Expect(connID).To(Equal(protocol.ParseConnectionID([]byte{1, 2, 3, 4})))
+		Expect(m.Get()).To(Equal(initialConnID))
+		m.SwitchToNext()
+		Expect(m.Get()).To(Equal(protocol.ParseConnectionID([]byte{1, 2, 3, 4})))
+		Expect(*tokenAdded).To(Equal(protocol.StatelessResetToken{1}))
+		Expect(frameQueue).To(Equal([]wire.Frame{&wire.RetireConnectionIDFrame{SequenceNumber: 0}}))
+		_, ok = m.PeekNext()
+		Expect(ok).To(BeFalse())
+	})
+
+	Context("reserving connection IDs", func() {
+		connID1 := protocol.ParseConnectionID([]byte{1, 2, 3, 4})
+		connID2 := protocol.ParseConnectionID([]byte{2, 3, 4, 5})
+
+		BeforeEach(func() {
+			Expect(m.Add(&wire.NewConnectionIDFrame{SequenceNumber: 1, ConnectionID: connID1, StatelessResetToken: protocol.StatelessResetToken{1}})).To(Succeed())
+			Expect(m.Add(&wire.NewConnectionIDFrame{SequenceNumber: 2, ConnectionID: connID2, StatelessResetToken: protocol.StatelessResetToken{2}})).To(Succeed())
+		})
+
+		It("reserves the next connection ID", func() {
+			_, ok := m.Reserved()
+			Expect(ok).To(BeFalse())
+			connID, ok := m.Reserve()
+			Expect(ok).To(BeTrue())
+			Expect(connID).To(Equal(connID1))
+			connID, ok = m.Reserved()
+			Expect(ok).To(BeTrue())
+			Expect(connID).To(Equal(connID1))
+			// only a single connection ID can be reserved
+			_, ok = m.Reserve()
+			Expect(ok).To(BeFalse())
+			// the reserved connection ID isn't used when migrating
+			connID, ok = m.PeekNext()
+			Expect(ok).To(BeTrue())
+			Expect(connID).To(Equal(connID2))
+			Expect(m.Get()).To(Equal(initialConnID))
+			Expect(frameQueue).To(BeEmpty())
+		})
+
+		It("ignores retransmissions of the NEW_CONNECTION_ID frame for the reserved connection ID", func() {
+			_, ok := m.Reserve()
+			Expect(ok).To(BeTrue())
+			Expect(m.Add(&wire.NewConnectionIDFrame{SequenceNumber: 1, ConnectionID: connID1})).To(Succeed())
+			connID, ok := m.PeekNext()
+			Expect(ok).To(BeTrue())
+			Expect(connID).To(Equal(connID2))
+		})
+
+		It("retires the reserved connection ID", func() {
+			_, ok := m.Reserve()
+			Expect(ok).To(BeTrue())
+			m.ReleaseReserved()
+			_, ok = m.Reserved()
+			Expect(ok).To(BeFalse())
+			Expect(frameQueue).To(Equal([]wire.Frame{&wire.RetireConnectionIDFrame{SequenceNumber: 1}}))
+		})
+
+		It("switches to the reserved connection ID", func() {
+			m.SetStatelessResetToken(protocol.StatelessResetToken{0})
+			_, ok := m.Reserve()
+			Expect(ok).To(BeTrue())
+			m.SwitchToReserved()
+			Expect(m.Get()).To(Equal(connID1))
+			Expect(*tokenAdded).To(Equal(protocol.StatelessResetToken{1}))
+			Expect(removedTokens).To(Equal([]protocol.StatelessResetToken{{0}}))
+			Expect(frameQueue).To(Equal([]wire.Frame{&wire.RetireConnectionIDFrame{SequenceNumber: 0}}))
+			_, ok = m.Reserved()
+			Expect(ok).To(BeFalse())
+			// the next connection ID is still available for migration
+			connID, ok := m.PeekNext()
+			Expect(ok).To(BeTrue())
+			Expect(connID).To(Equal(connID2))
+		})
+
+		It("retires the reserved connection ID when the peer asks for it", func() {
+			_, ok := m.Reserve()
+			Expect(ok).To(BeTrue())
+			Expect(m.Add(&wire.NewConnectionIDFrame{
+				SequenceNumber: 3,
+				ConnectionID:   protocol.ParseConnectionID([]byte{3, 4, 5, 6}),
+				RetirePriorTo:  2,
+			})).To(Succeed())
+			_, ok = m.Reserved()
+			Expect(ok).To(BeFalse())
+			Expect(frameQueue).To(ContainElement(&wire.RetireConnectionIDFrame{SequenceNumber: 1}))
+		})
+	})
+
+	Context("reporting connection ID updates", func() {
+		type update struct{ retired, active []protocol.ConnectionID }
+		var updates []update
+
+		BeforeEach(func() {
+			updates = nil
+			m.connIDsUpdated = func(retired, active []protocol.ConnectionID) {
+				updates = append(updates, update{retired: retired, active: active})
+			}
+		})
+
+		It("reports new connection IDs", func() {
+			connID1 := protocol.ParseConnectionID([]byte{1, 2, 3, 4})
+			connID2 := protocol.ParseConnectionID([]byte{2, 3, 4, 5})
+			Expect(m.Add(&wire.NewConnectionIDFrame{SequenceNumber: 1, ConnectionID: connID1})).To(Succeed())
+			Expect(m.Add(&wire.NewConnectionIDFrame{SequenceNumber: 2, ConnectionID: connID2})).To(Succeed())
+			Expect(updates).To(Equal([]update{
+				{active: []protocol.ConnectionID{initialConnID, connID1}},
+				{active: []protocol.ConnectionID{initialConnID, connID1, connID2}},
+			}))
+			// duplicates don't change the set of active connection IDs
+			Expect(m.Add(&wire.NewConnectionIDFrame{SequenceNumber: 2, ConnectionID: connID2})).To(Succeed())
+			Expect(updates).To(HaveLen(2))
+		})
+
+		It("reports connection IDs retired due to Retire Prior To", func() {
+			connID1 := protocol.ParseConnectionID([]byte{1, 2, 3, 4})
+			connID2 := protocol.ParseConnectionID([]byte{2, 3, 4, 5})
+			Expect(m.Add(&wire.NewConnectionIDFrame{SequenceNumber: 1, ConnectionID: connID1})).To(Succeed())
+			updates = nil
+			Expect(m.Add(&wire.NewConnectionIDFrame{SequenceNumber: 2, RetirePriorTo: 2, ConnectionID: connID2})).To(Succeed())
+			Expect(updates).To(HaveLen(1))
+			Expect(updates[0].retired).To(ConsistOf(initialConnID, connID1))
+			Expect(updates[0].active).To(Equal([]protocol.ConnectionID{connID2}))
+		})
+
+		It("reports when switching to a new connection ID", func() {
+			connID := protocol.ParseConnectionID([]byte{1, 2, 3, 4})
+			Expect(m.Add(&wire.NewConnectionIDFrame{SequenceNumber: 1, ConnectionID: connID})).To(Succeed())
+			updates = nil
+			m.SwitchToNext()
+			Expect(updates).To(Equal([]update{
+				{retired: []protocol.ConnectionID{initialConnID}, active: []protocol.ConnectionID{connID}},
+			}))
+			// Get doesn't report anything if the connection ID didn't change
+			Expect(m.Get()).To(Equal(connID))
+			Expect(updates).To(HaveLen(1))
+		})
+	})
+
 	It("removes the currently active stateless reset token when it is closed", func() {
 		m.Close()
 		Expect(removedTokens).To(BeEmpty())
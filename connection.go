@@ -3,17 +3,22 @@ package quic
 import (
 	"bytes"
 	"context"
+	"crypto/rand"
 	"crypto/tls"
 	"errors"
 	"fmt"
 	"io"
+	"maps"
 	"net"
+	"net/netip"
 	"reflect"
+	"slices"
 	"sync"
 	"sync/atomic"
 	"time"
 
 	"github.com/quic-go/quic-go/internal/ackhandler"
+	"github.com/quic-go/quic-go/internal/congestion"
 	"github.com/quic-go/quic-go/internal/flowcontrol"
 	"github.com/quic-go/quic-go/internal/handshake"
 	"github.com/quic-go/quic-go/internal/logutils"
@@ -41,13 +46,18 @@ type streamManager interface {
 	OpenUniStream() (SendStream, error)
 	OpenStreamSync(context.Context) (Stream, error)
 	OpenUniStreamSync(context.Context) (SendStream, error)
+	StreamsAvailable() (bidi, uni int64)
+	ActiveStreams() []protocol.StreamID
 	AcceptStream(context.Context) (Stream, error)
 	AcceptUniStream(context.Context) (ReceiveStream, error)
 	DeleteStream(protocol.StreamID) error
 	UpdateLimits(*wire.TransportParameters)
 	HandleMaxStreamsFrame(*wire.MaxStreamsFrame)
+	SetMaxIncomingStreams(uint64)
+	SetMaxIncomingUniStreams(uint64)
+	SetInitialMaxIncomingStreams(bidi, uni uint64)
 	CloseWithError(error)
-	ResetFor0RTT()
+	ResetFor0RTT(error)
 	UseResetMaps()
 }
 
@@ -61,6 +71,8 @@ type cryptoStreamHandler interface {
 	DiscardInitialKeys()
 	io.Closer
 	ConnectionState() handshake.ConnectionState
+	InitiateKeyUpdate() error
+	CurrentKeyPhase() protocol.KeyPhase
 }
 
 type receivedPacket struct {
@@ -98,6 +110,52 @@ type connRunner interface {
 	RemoveResetToken(protocol.StatelessResetToken)
 }
 
+// connRunners are the connRunners of all Transports that the connection receives packets on.
+// The first one belongs to the Transport that the connection was created on.
+// The Transports of new paths (see MigrateTo, ProbePath and AddPath) are added when path validation starts,
+// such that packets received on their sockets are routed to the connection by their connection ID.
+type connRunners []connRunner
+
+func (r connRunners) Add(connID protocol.ConnectionID, h packetHandler) {
+	for _, runner := range r {
+		runner.Add(connID, h)
+	}
+}
+
+func (r connRunners) GetStatelessResetToken(connID protocol.ConnectionID) protocol.StatelessResetToken {
+	return r[0].GetStatelessResetToken(connID)
+}
+
+func (r connRunners) Retire(connID protocol.ConnectionID) {
+	for _, runner := range r {
+		runner.Retire(connID)
+	}
+}
+
+func (r connRunners) Remove(connID protocol.ConnectionID) {
+	for _, runner := range r {
+		runner.Remove(connID)
+	}
+}
+
+func (r connRunners) ReplaceWithClosed(connIDs []protocol.ConnectionID, connClose []byte) {
+	for _, runner := range r {
+		runner.ReplaceWithClosed(connIDs, connClose)
+	}
+}
+
+func (r connRunners) AddResetToken(token protocol.StatelessResetToken, h packetHandler) {
+	for _, runner := range r {
+		runner.AddResetToken(token, h)
+	}
+}
+
+func (r connRunners) RemoveResetToken(token protocol.StatelessResetToken) {
+	for _, runner := range r {
+		runner.RemoveResetToken(token)
+	}
+}
+
 type closeError struct {
 	err       error
 	remote    bool
@@ -133,6 +191,19 @@ type connection struct {
 
 	conn      sendConn
 	sendQueue sender
+	// connMutex protects conn, which is replaced when the connection is migrated to a new path.
+	// It only needs to be held when accessing conn from outside the run loop.
+	connMutex sync.Mutex
+
+	pathMigrationChan chan *pathMigration
+	pathMigration     *pathMigration // the path validation currently in progress
+	migratedTransport *Transport     // the Transport opened for the path that the connection is currently using
+	runners           connRunners
+	pathRunner        connRunner // the connRunner of the Transport that the current path uses
+	sentPathChallenge bool
+	pathRequestChan   chan *pathRequest
+	backupPath        *backupPath // the path added by AddPath
+	lastPathID        PathID
 
 	streamsMap      streamManager
 	connIDManager   *connIDManager
@@ -150,12 +221,17 @@ type connection struct {
 	tokenStoreKey         string                    // only set for the client
 	tokenGenerator        *handshake.TokenGenerator // only set for the server
 
+	// streams that queued a STREAM_DATA_BLOCKED frame, reported to Config.OnFlowControlBlocked after sending
+	flowControlBlockedStreams []protocol.StreamID
+
 	unpacker      unpacker
 	frameParser   wire.FrameParser
 	packer        packer
 	mtuDiscoverer mtuDiscoverer // initialized when the transport parameters are received
 
 	maxPayloadSizeEstimate atomic.Uint32
+	// the largest packet size that was confirmed to be supported on the path
+	mtu atomic.Uint32
 
 	initialStream       cryptoStream
 	handshakeStream     cryptoStream
@@ -168,6 +244,10 @@ type connection struct {
 	closeOnce sync.Once
 	// closeChan is used to notify the run loop that it should terminate
 	closeChan chan closeError
+	// Set when a CONNECTION_CLOSE frame with the APPLICATION_ERROR error code was received
+	// in an Initial or a Handshake packet.
+	// The peer might have sent the application error in a 1-RTT packet coalesced into the same datagram.
+	handshakeCloseErr *qerr.TransportError
 
 	ctx                   context.Context
 	ctxCancel             context.CancelCauseFunc
@@ -191,6 +271,17 @@ type connection struct {
 	creationTime time.Time
 	// The idle timeout is set based on the max of the time we received the last packet...
 	lastPacketReceivedTime time.Time
+	// the buffer and the receive time of the packet that is currently being processed
+	rcvBuffer *packetBuffer
+	rcvTime   time.Time
+	// the address the 1-RTT packet that is currently being processed was received from,
+	// and the local address it was received on, nil if it was received on the current path
+	rcvAddr net.Addr
+	rcvInfo packetInfo
+	// set if the 1-RTT packet that is currently being processed contains a non-probing frame (RFC 9000, section 9.1)
+	rcvNonProbing bool
+	// the largest packet number of a non-probing 1-RTT packet received so far
+	largestNonProbingPN protocol.PacketNumber
 	// ... and the time we sent a new ack-eliciting packet after receiving a packet.
 	firstAckElicitingPacketAfterIdleSentTime time.Time
 	// pacingDeadline is the time when the next packet should be sent
@@ -199,17 +290,54 @@ type connection struct {
 	peerParams *wire.TransportParameters
 
 	timer connectionTimer
+	// The latency spin bit, and the packet number of the 1-RTT packet it was last updated from.
+	spinBit   atomic.Bool
+	spinBitPN protocol.PacketNumber
+
+	// set when the peer's transport parameters are received, accessed by MigrationAllowed
+	peerAllowsMigration atomic.Bool
+	// only set for the client, if the server sent the preferred_address transport parameter
+	peerPreferredAddress atomic.Pointer[PreferredAddress]
+
+	// The number of packets received with an ECN marking, accessed by ECNStats.
+	numReceivedECT0, numReceivedECT1, numReceivedECNCE atomic.Uint64
+
+	// The number of UDP payload bytes sent and received, accessed by BytesSent and BytesReceived.
+	bytesSent, bytesReceived atomic.Uint64
+
 	// keepAlivePingSent stores whether a keep alive PING is in flight.
 	// It is reset as soon as we receive a packet from the peer.
 	keepAlivePingSent bool
 	keepAliveInterval time.Duration
+	// keepAliveRequested is set when the application requests a PING using SendKeepAlive.
+	keepAliveRequested atomic.Bool
+	// immediateAckRequested is set when the application requests an IMMEDIATE_ACK using RequestImmediateAck.
+	immediateAckRequested atomic.Bool
+	// flushRequested is set when the application calls Flush.
+	// It allows the next packet to be sent without waiting for the pacer.
+	flushRequested atomic.Bool
+	// ackFrequencyRequested is set when the application calls RequestAckFrequency,
+	// protected by ackFrequencyMutex. The sequence number is assigned by the run loop.
+	ackFrequencyMutex     sync.Mutex
+	ackFrequencyRequested *wire.AckFrequencyFrame
+	nextAckFrequencySeq   uint64
 
 	datagramQueue *datagramQueue
+	// used by SendLargeDatagram and ReceiveLargeDatagram
+	nextLargeDatagramID atomic.Uint64
+	datagramReassembler *datagramReassembler
 
 	connStateMutex sync.Mutex
 	connState      ConnectionState
+	// the min_ack_delay sent by the peer, protected by connStateMutex
+	peerMinAckDelay time.Duration
+	// the stateless reset token of the connection ID currently in use, protected by connStateMutex
+	statelessResetToken *protocol.StatelessResetToken
+	// the transport parameters received from the peer during the handshake, protected by connStateMutex
+	receivedPeerParams *TransportParameters
 
 	logID  string
+	clock  utils.Clock
 	tracer *logging.ConnectionTracer
 	logger utils.Logger
 }
@@ -259,20 +387,31 @@ var newConnection = func(
 	} else {
 		s.logID = destConnID.String()
 	}
+	s.runners = connRunners{runner}
+	s.pathRunner = runner
 	s.connIDManager = newConnIDManager(
 		destConnID,
-		func(token protocol.StatelessResetToken) { runner.AddResetToken(token, s) },
-		runner.RemoveResetToken,
+		func(token protocol.StatelessResetToken) {
+			s.runners.AddResetToken(token, s)
+			s.setStatelessResetToken(&token)
+		},
+		func(token protocol.StatelessResetToken) {
+			s.runners.RemoveResetToken(token)
+			s.setStatelessResetToken(nil)
+		},
 		s.queueControlFrame,
+		s.config.ConnectionIDUpdated,
 	)
 	s.connIDGenerator = newConnIDGenerator(
 		srcConnID,
 		&clientDestConnID,
-		func(connID protocol.ConnectionID) { runner.Add(connID, s) },
+		func(connID protocol.ConnectionID) { s.runners.Add(connID, s) },
 		runner.GetStatelessResetToken,
-		runner.Remove,
-		runner.Retire,
-		runner.ReplaceWithClosed,
+		func(connID protocol.ConnectionID) { s.runners.Remove(connID) },
+		func(connID protocol.ConnectionID) { s.runners.Retire(connID) },
+		func(connIDs []protocol.ConnectionID, connClose []byte) {
+			s.runners.ReplaceWithClosed(connIDs, connClose)
+		},
 		s.queueControlFrame,
 		connIDGenerator,
 	)
@@ -281,13 +420,18 @@ var newConnection = func(
 		0,
 		protocol.ByteCount(s.config.InitialPacketSize),
 		s.rttStats,
+		s.newCongestionControl(),
+		s.config.ackDelay(),
 		clientAddressValidated,
-		s.conn.capabilities().ECN,
+		s.conn.capabilities().ECN && !s.config.DisableECN,
+		s.config.DisablePacing,
 		s.perspective,
 		s.tracer,
+		s.clock,
 		s.logger,
 	)
 	s.maxPayloadSizeEstimate.Store(uint32(estimateMaxPayloadSize(protocol.ByteCount(s.config.InitialPacketSize))))
+	s.mtu.Store(uint32(s.config.InitialPacketSize))
 	params := &wire.TransportParameters{
 		InitialMaxStreamDataBidiLocal:   protocol.ByteCount(s.config.InitialStreamReceiveWindow),
 		InitialMaxStreamDataBidiRemote:  protocol.ByteCount(s.config.InitialStreamReceiveWindow),
@@ -296,10 +440,10 @@ var newConnection = func(
 		MaxIdleTimeout:                  s.config.MaxIdleTimeout,
 		MaxBidiStreamNum:                protocol.StreamNum(s.config.MaxIncomingStreams),
 		MaxUniStreamNum:                 protocol.StreamNum(s.config.MaxIncomingUniStreams),
-		MaxAckDelay:                     protocol.MaxAckDelayInclGranularity,
+		MaxAckDelay:                     s.config.advertisedMaxAckDelay(),
 		AckDelayExponent:                protocol.AckDelayExponent,
 		MaxUDPPayloadSize:               protocol.MaxPacketBufferSize,
-		DisableActiveMigration:          true,
+		DisableActiveMigration:          s.config.DisableActiveMigration,
 		StatelessResetToken:             &statelessResetToken,
 		OriginalDestinationConnectionID: origDestConnID,
 		// For interoperability with quic-go versions before May 2023, this value must be set to a value
@@ -316,7 +460,23 @@ var newConnection = func(
 	} else {
 		params.MaxDatagramFrameSize = protocol.InvalidByteCount
 	}
-	if s.tracer != nil && s.tracer.SentTransportParameters != nil {
+	if s.config.EnableExperimentalDatagramFlowControl {
+		params.InitialMaxDatagrams = uint64(s.config.DatagramReceiveQueueLen)
+	}
+	params.EnableResetStreamAt = s.config.EnableResetStreamAt
+	params.GreaseQUICBit = s.acceptsGreasedQUICBit()
+	if s.config.EnableAckFrequency {
+		minAckDelay := protocol.MinAckDelay
+		params.MinAckDelay = &minAckDelay
+	}
+	// A server that uses zero-length connection IDs must not send a preferred address.
+	if s.config.PreferredAddress != nil && s.srcConnIDLen > 0 {
+		params.PreferredAddress = s.newPreferredAddressParameter(s.config.PreferredAddress)
+	}
+	if s.config.GetConfigForClientHello != nil {
+		// The transport parameters are traced once the ClientHello has been processed.
+		tlsConf = s.handleClientHello(tlsConf, params, clientAddressValidated)
+	} else if s.tracer != nil && s.tracer.SentTransportParameters != nil {
 		s.tracer.SentTransportParameters(params)
 	}
 	cs := handshake.NewCryptoSetupServer(
@@ -328,6 +488,7 @@ var newConnection = func(
 		conf.Allow0RTT,
 		s.rttStats,
 		tracer,
+		s.clock,
 		logger,
 		s.version,
 	)
@@ -338,6 +499,111 @@ var newConnection = func(
 	return s
 }
 
+// newPreferredAddressParameter issues the connection ID for the preferred address,
+// and returns the value of the preferred_address transport parameter.
+func (s *connection) newPreferredAddressParameter(pa *PreferredAddress) *wire.PreferredAddress {
+	connID, resetToken, err := s.connIDGenerator.IssuePreferredAddressConnID()
+	if err != nil {
+		s.logger.Debugf("Not sending the preferred_address: %s", err)
+		return nil
+	}
+	// Address families that are not advertised are encoded as the unspecified address with port 0.
+	p := &wire.PreferredAddress{
+		IPv4:                netip.AddrPortFrom(netip.IPv4Unspecified(), 0),
+		IPv6:                netip.AddrPortFrom(netip.IPv6Unspecified(), 0),
+		ConnectionID:        connID,
+		StatelessResetToken: resetToken,
+	}
+	if pa.hasIPv4() {
+		p.IPv4 = pa.IPv4
+	}
+	if pa.hasIPv6() {
+		p.IPv6 = pa.IPv6
+	}
+	return p
+}
+
+// handleClientHello sets up the tls.Config such that Config.GetConfigForClientHello is called when
+// crypto/tls processes the ClientHello, i.e. before the transport parameters are sent.
+func (s *connection) handleClientHello(tlsConf *tls.Config, params *wire.TransportParameters, addrVerified bool) *tls.Config {
+	tlsConf = tlsConf.Clone()
+	getConfigForClient := tlsConf.GetConfigForClient
+	tlsConf.GetConfigForClient = func(chi *tls.ClientHelloInfo) (*tls.Config, error) {
+		conf, err := s.config.GetConfigForClientHello(&ClientHelloInfo{
+			RemoteAddr:      s.conn.RemoteAddr(),
+			AddrVerified:    addrVerified,
+			ServerName:      chi.ServerName,
+			SupportedProtos: chi.SupportedProtos,
+		})
+		if err != nil {
+			s.logger.Debugf("Rejecting new connection due to GetConfigForClientHello callback: %s", err)
+			return nil, &qerr.TransportError{ErrorCode: qerr.ConnectionRefused}
+		}
+		if conf != nil {
+			if err := s.applyConfigForClientHello(conf, params); err != nil {
+				s.logger.Debugf("Rejecting new connection due to invalid Config returned by GetConfigForClientHello: %s", err)
+				return nil, err
+			}
+		}
+		if s.tracer != nil && s.tracer.SentTransportParameters != nil {
+			s.tracer.SentTransportParameters(params)
+		}
+		if getConfigForClient != nil {
+			return getConfigForClient(chi)
+		}
+		return nil, nil
+	}
+	return tlsConf
+}
+
+// applyConfigForClientHello applies the Config returned from Config.GetConfigForClientHello.
+// It is called on the run loop while processing the ClientHello.
+// At this point, the peer can't have opened any streams, and no STREAM or DATAGRAM frames have been processed yet.
+func (s *connection) applyConfigForClientHello(conf *Config, params *wire.TransportParameters) error {
+	// validateConfig adjusts some values, so it must not modify the application's Config
+	conf = conf.Clone()
+	if err := validateConfig(conf); err != nil {
+		return fmt.Errorf("invalid Config returned by GetConfigForClientHello: %w", err)
+	}
+	conf = populateConfig(conf)
+	c := s.config.Clone()
+	c.InitialStreamReceiveWindow = conf.InitialStreamReceiveWindow
+	c.MaxStreamReceiveWindow = conf.MaxStreamReceiveWindow
+	c.InitialConnectionReceiveWindow = conf.InitialConnectionReceiveWindow
+	c.MaxConnectionReceiveWindow = conf.MaxConnectionReceiveWindow
+	c.MaxIncomingStreams = conf.MaxIncomingStreams
+	c.MaxIncomingUniStreams = conf.MaxIncomingUniStreams
+	c.EnableDatagrams = conf.EnableDatagrams
+	c.RequireDatagrams = conf.RequireDatagrams
+	c.EnableExperimentalDatagramFlowControl = conf.EnableExperimentalDatagramFlowControl
+	c.MaxIdleTimeout = conf.MaxIdleTimeout
+	c.KeepAlivePeriod = conf.KeepAlivePeriod
+	s.config = c
+
+	s.setupFrameParser()
+	s.connFlowController = s.newConnectionFlowController()
+	s.streamsMap.SetInitialMaxIncomingStreams(uint64(c.MaxIncomingStreams), uint64(c.MaxIncomingUniStreams))
+	s.windowUpdateQueue = newWindowUpdateQueue(s.streamsMap, s.connFlowController, s.framer.QueueControlFrame)
+
+	params.InitialMaxStreamDataBidiLocal = protocol.ByteCount(c.InitialStreamReceiveWindow)
+	params.InitialMaxStreamDataBidiRemote = protocol.ByteCount(c.InitialStreamReceiveWindow)
+	params.InitialMaxStreamDataUni = protocol.ByteCount(c.InitialStreamReceiveWindow)
+	params.InitialMaxData = protocol.ByteCount(c.InitialConnectionReceiveWindow)
+	params.MaxIdleTimeout = c.MaxIdleTimeout
+	params.MaxBidiStreamNum = protocol.StreamNum(c.MaxIncomingStreams)
+	params.MaxUniStreamNum = protocol.StreamNum(c.MaxIncomingUniStreams)
+	if c.EnableDatagrams {
+		params.MaxDatagramFrameSize = wire.MaxDatagramSize
+	} else {
+		params.MaxDatagramFrameSize = protocol.InvalidByteCount
+	}
+	params.InitialMaxDatagrams = 0
+	if c.EnableExperimentalDatagramFlowControl {
+		params.InitialMaxDatagrams = uint64(c.DatagramReceiveQueueLen)
+	}
+	return nil
+}
+
 // declare this as a variable, such that we can it mock it in the tests
 var newClientConnection = func(
 	ctx context.Context,
@@ -368,20 +634,31 @@ var newClientConnection = func(
 		versionNegotiated:   hasNegotiatedVersion,
 		version:             v,
 	}
+	s.runners = connRunners{runner}
+	s.pathRunner = runner
 	s.connIDManager = newConnIDManager(
 		destConnID,
-		func(token protocol.StatelessResetToken) { runner.AddResetToken(token, s) },
-		runner.RemoveResetToken,
+		func(token protocol.StatelessResetToken) {
+			s.runners.AddResetToken(token, s)
+			s.setStatelessResetToken(&token)
+		},
+		func(token protocol.StatelessResetToken) {
+			s.runners.RemoveResetToken(token)
+			s.setStatelessResetToken(nil)
+		},
 		s.queueControlFrame,
+		s.config.ConnectionIDUpdated,
 	)
 	s.connIDGenerator = newConnIDGenerator(
 		srcConnID,
 		nil,
-		func(connID protocol.ConnectionID) { runner.Add(connID, s) },
+		func(connID protocol.ConnectionID) { s.runners.Add(connID, s) },
 		runner.GetStatelessResetToken,
-		runner.Remove,
-		runner.Retire,
-		runner.ReplaceWithClosed,
+		func(connID protocol.ConnectionID) { s.runners.Remove(connID) },
+		func(connID protocol.ConnectionID) { s.runners.Retire(connID) },
+		func(connIDs []protocol.ConnectionID, connClose []byte) {
+			s.runners.ReplaceWithClosed(connIDs, connClose)
+		},
 		s.queueControlFrame,
 		connIDGenerator,
 	)
@@ -391,13 +668,18 @@ var newClientConnection = func(
 		initialPacketNumber,
 		protocol.ByteCount(s.config.InitialPacketSize),
 		s.rttStats,
+		s.newCongestionControl(),
+		s.config.ackDelay(),
 		false, // has no effect
-		s.conn.capabilities().ECN,
+		s.conn.capabilities().ECN && !s.config.DisableECN,
+		s.config.DisablePacing,
 		s.perspective,
 		s.tracer,
+		s.clock,
 		s.logger,
 	)
 	s.maxPayloadSizeEstimate.Store(uint32(estimateMaxPayloadSize(protocol.ByteCount(s.config.InitialPacketSize))))
+	s.mtu.Store(uint32(s.config.InitialPacketSize))
 	oneRTTStream := newCryptoStream()
 	params := &wire.TransportParameters{
 		InitialMaxStreamDataBidiRemote: protocol.ByteCount(s.config.InitialStreamReceiveWindow),
@@ -407,7 +689,7 @@ var newClientConnection = func(
 		MaxIdleTimeout:                 s.config.MaxIdleTimeout,
 		MaxBidiStreamNum:               protocol.StreamNum(s.config.MaxIncomingStreams),
 		MaxUniStreamNum:                protocol.StreamNum(s.config.MaxIncomingUniStreams),
-		MaxAckDelay:                    protocol.MaxAckDelayInclGranularity,
+		MaxAckDelay:                    s.config.advertisedMaxAckDelay(),
 		MaxUDPPayloadSize:              protocol.MaxPacketBufferSize,
 		AckDelayExponent:               protocol.AckDelayExponent,
 		DisableActiveMigration:         true,
@@ -424,6 +706,15 @@ var newClientConnection = func(
 	} else {
 		params.MaxDatagramFrameSize = protocol.InvalidByteCount
 	}
+	if s.config.EnableExperimentalDatagramFlowControl {
+		params.InitialMaxDatagrams = uint64(s.config.DatagramReceiveQueueLen)
+	}
+	params.EnableResetStreamAt = s.config.EnableResetStreamAt
+	params.GreaseQUICBit = s.acceptsGreasedQUICBit()
+	if s.config.EnableAckFrequency {
+		minAckDelay := protocol.MinAckDelay
+		params.MinAckDelay = &minAckDelay
+	}
 	if s.tracer != nil && s.tracer.SentTransportParameters != nil {
 		s.tracer.SentTransportParameters(params)
 	}
@@ -434,6 +725,7 @@ var newClientConnection = func(
 		enable0RTT,
 		s.rttStats,
 		tracer,
+		s.clock,
 		logger,
 		s.version,
 	)
@@ -446,7 +738,11 @@ var newClientConnection = func(
 	} else {
 		s.tokenStoreKey = conn.RemoteAddr().String()
 	}
-	if s.config.TokenStore != nil {
+	if s.config.GetStoredToken != nil {
+		if token := s.config.GetStoredToken(conn.RemoteAddr()); len(token) > 0 {
+			s.packer.SetToken(token)
+		}
+	} else if s.config.TokenStore != nil {
 		if token := s.config.TokenStore.Pop(s.tokenStoreKey); token != nil {
 			s.packer.SetToken(token.data)
 		}
@@ -455,25 +751,22 @@ var newClientConnection = func(
 }
 
 func (s *connection) preSetup() {
+	s.clock = s.config.Clock
+	if s.clock == nil {
+		s.clock = utils.DefaultClock{}
+	}
 	s.initialStream = newCryptoStream()
 	s.handshakeStream = newCryptoStream()
 	s.sendQueue = newSendQueue(s.conn)
 	s.retransmissionQueue = newRetransmissionQueue()
-	s.frameParser = *wire.NewFrameParser(s.config.EnableDatagrams)
+	s.setupFrameParser()
 	s.rttStats = &utils.RTTStats{}
-	s.connFlowController = flowcontrol.NewConnectionFlowController(
-		protocol.ByteCount(s.config.InitialConnectionReceiveWindow),
-		protocol.ByteCount(s.config.MaxConnectionReceiveWindow),
-		s.onHasConnectionWindowUpdate,
-		func(size protocol.ByteCount) bool {
-			if s.config.AllowConnectionWindowIncrease == nil {
-				return true
-			}
-			return s.config.AllowConnectionWindowIncrease(s, uint64(size))
-		},
-		s.rttStats,
-		s.logger,
-	)
+	if s.config.InitialRTT > 0 {
+		s.rttStats.SetInitialRTTEstimate(s.config.InitialRTT)
+	}
+	s.spinBitPN = protocol.InvalidPacketNumber
+	s.largestNonProbingPN = protocol.InvalidPacketNumber
+	s.connFlowController = s.newConnectionFlowController()
 	s.earlyConnReadyChan = make(chan struct{})
 	s.streamsMap = newStreamsMap(
 		s.ctx,
@@ -481,29 +774,62 @@ func (s *connection) preSetup() {
 		s.newFlowController,
 		uint64(s.config.MaxIncomingStreams),
 		uint64(s.config.MaxIncomingUniStreams),
+		s.config.AcceptUniStream,
+		s.config.RejectedUniStreamErrorCode,
 		s.perspective,
+		s.clock,
 	)
 	s.framer = newFramer(s.streamsMap)
 	s.receivedPackets = make(chan receivedPacket, protocol.MaxConnUnprocessedPackets)
 	s.closeChan = make(chan closeError, 1)
 	s.sendingScheduled = make(chan struct{}, 1)
+	s.pathMigrationChan = make(chan *pathMigration)
+	s.pathRequestChan = make(chan *pathRequest)
 	s.handshakeCompleteChan = make(chan struct{})
 
-	now := time.Now()
+	now := s.clock.Now()
 	s.lastPacketReceivedTime = now
 	s.creationTime = now
 
 	s.windowUpdateQueue = newWindowUpdateQueue(s.streamsMap, s.connFlowController, s.framer.QueueControlFrame)
-	s.datagramQueue = newDatagramQueue(s.scheduleSending, s.logger)
+	s.datagramReassembler = newDatagramReassembler()
+	s.datagramQueue = newDatagramQueue(s.scheduleSending, maxDatagramSendQueueLen, s.config.DatagramReceiveQueueLen, 0, nil, s.config.RecordDatagramReceiveTime, s.config.EnableZeroCopyDatagrams, s.clock, s.logger)
 	s.connState.Version = s.version
 }
 
+func (s *connection) setupFrameParser() {
+	s.frameParser = *wire.NewFrameParser(s.config.EnableDatagrams, s.config.EnableResetStreamAt, s.config.EnableAckFrequency)
+	if s.config.EnableExperimentalDatagramFlowControl {
+		s.frameParser.EnableDatagramFlowControl()
+	}
+	for typ := range s.config.CustomFrameHandlers {
+		s.frameParser.RegisterCustomFrameType(typ)
+	}
+}
+
+func (s *connection) newConnectionFlowController() flowcontrol.ConnectionFlowController {
+	return flowcontrol.NewConnectionFlowController(
+		protocol.ByteCount(s.config.InitialConnectionReceiveWindow),
+		protocol.ByteCount(s.config.MaxConnectionReceiveWindow),
+		s.onHasConnectionWindowUpdate,
+		func(size protocol.ByteCount) bool {
+			if s.config.AllowConnectionWindowIncrease == nil {
+				return true
+			}
+			return s.config.AllowConnectionWindowIncrease(s, uint64(size))
+		},
+		s.rttStats,
+		s.clock,
+		s.logger,
+	)
+}
+
 // run the connection main loop
 func (s *connection) run() error {
 	var closeErr closeError
 	defer func() { s.ctxCancel(closeErr.err) }()
 
-	s.timer = *newTimer()
+	s.timer = *newTimer(s.clock)
 
 	if err := s.cryptoStreamHandler.StartHandshake(s.ctx); err != nil {
 		return err
@@ -511,11 +837,7 @@ func (s *connection) run() error {
 	if err := s.handleHandshakeEvents(); err != nil {
 		return err
 	}
-	go func() {
-		if err := s.sendQueue.Run(); err != nil {
-			s.destroyImpl(err)
-		}
-	}()
+	s.runSendQueue(s.sendQueue)
 
 	if s.perspective == protocol.PerspectiveClient {
 		s.scheduleSending() // so the ClientHello actually gets sent
@@ -566,7 +888,12 @@ runLoop:
 				// We do all the interesting stuff after the switch statement, so
 				// nothing to see here.
 			case <-sendQueueAvailable:
+			case m := <-s.pathMigrationChan:
+				s.startPathMigration(m, s.clock.Now())
+			case r := <-s.pathRequestChan:
+				r.result <- s.handlePathRequest(r)
 			case firstPacket := <-s.receivedPackets:
+				s.bytesReceived.Add(uint64(firstPacket.Size()))
 				wasProcessed := s.handlePacketImpl(firstPacket)
 				// Don't set timers and send packets if the packet made us close the connection.
 				select {
@@ -583,6 +910,7 @@ runLoop:
 					for i := 0; i < numPackets; i++ {
 						select {
 						case p := <-s.receivedPackets:
+							s.bytesReceived.Add(uint64(p.Size()))
 							if processed := s.handlePacketImpl(p); processed {
 								wasProcessed = true
 							}
@@ -605,15 +933,33 @@ runLoop:
 			}
 		}
 
-		now := time.Now()
+		now := s.clock.Now()
 		if timeout := s.sentPacketHandler.GetLossDetectionTimeout(); !timeout.IsZero() && timeout.Before(now) {
 			// This could cause packets to be retransmitted.
 			// Check it before trying to send packets.
 			if err := s.sentPacketHandler.OnLossDetectionTimeout(); err != nil {
 				s.closeLocal(err)
+			} else if s.config.MaxPTOCount > 0 && s.sentPacketHandler.Stats().PTOCount > uint32(s.config.MaxPTOCount) {
+				s.destroyImpl(qerr.ErrPTOLimitReached)
+				continue
 			}
 		}
 
+		if s.pathMigration != nil && !now.Before(s.pathMigration.nextProbe) {
+			s.maybeSendPathChallenge(now)
+		}
+		if s.backupPath != nil && s.pathMigration == nil && !now.Before(s.backupPath.nextValidation) {
+			s.revalidateBackupPath(now)
+		}
+
+		if s.keepAliveRequested.CompareAndSwap(true, false) {
+			s.framer.QueueControlFrame(&wire.PingFrame{})
+		}
+		if s.immediateAckRequested.CompareAndSwap(true, false) {
+			s.framer.QueueControlFrame(&wire.ImmediateAckFrame{})
+		}
+		s.maybeQueueAckFrequencyFrame()
+
 		if keepAliveTime := s.nextKeepAliveTime(); !keepAliveTime.IsZero() && !now.Before(keepAliveTime) {
 			// send a PING frame since there is no activity in the connection
 			s.logger.Debugf("Sending a keep-alive PING to keep the connection alive.")
@@ -640,6 +986,7 @@ runLoop:
 		if err := s.triggerSending(now); err != nil {
 			s.closeLocal(err)
 		}
+		s.reportFlowControlBlocked()
 		if s.sendQueue.WouldBlock() {
 			sendQueueAvailable = s.sendQueue.Available()
 		} else {
@@ -650,6 +997,16 @@ runLoop:
 	s.cryptoStreamHandler.Close()
 	s.sendQueue.Close() // close the send queue before sending the CONNECTION_CLOSE
 	s.handleCloseError(&closeErr)
+	if s.pathMigration != nil {
+		s.pathMigration.result <- closeErr.err
+		s.pathMigration = nil
+	}
+	if s.migratedTransport != nil {
+		s.migratedTransport.Close()
+	}
+	if s.backupPath != nil {
+		s.backupPath.transport.Close()
+	}
 	if s.tracer != nil && s.tracer.Close != nil {
 		if e := (&errCloseForRecreating{}); !errors.As(closeErr.err, &e) {
 			s.tracer.Close()
@@ -677,16 +1034,150 @@ func (s *connection) supportsDatagrams() bool {
 	return s.peerParams.MaxDatagramFrameSize > 0
 }
 
+func (s *connection) DatagramsSupported() bool {
+	if !s.config.EnableDatagrams {
+		return false
+	}
+	s.connStateMutex.Lock()
+	defer s.connStateMutex.Unlock()
+	return s.connState.SupportsDatagrams
+}
+
 func (s *connection) ConnectionState() ConnectionState {
 	s.connStateMutex.Lock()
 	defer s.connStateMutex.Unlock()
 	cs := s.cryptoStreamHandler.ConnectionState()
 	s.connState.TLS = cs.ConnectionState
 	s.connState.Used0RTT = cs.Used0RTT
-	s.connState.GSO = s.conn.capabilities().GSO
+	s.connState.KeyExchangeGroup = keyExchangeGroup(&cs.ConnectionState)
+	s.connState.HelloRetryRequest = usedHelloRetryRequest(&cs.ConnectionState)
+	s.connMutex.Lock()
+	s.connState.GSO = !s.config.DisableGSO && s.conn.capabilities().GSO
+	s.connMutex.Unlock()
 	return s.connState
 }
 
+func (s *connection) Used0RTT() bool {
+	return s.cryptoStreamHandler.ConnectionState().Used0RTT
+}
+
+func (s *connection) StatelessResetToken() (StatelessResetToken, bool) {
+	s.connStateMutex.Lock()
+	defer s.connStateMutex.Unlock()
+	if s.statelessResetToken == nil {
+		return StatelessResetToken{}, false
+	}
+	return *s.statelessResetToken, true
+}
+
+func (s *connection) PeerTransportParameters() *TransportParameters {
+	s.connStateMutex.Lock()
+	defer s.connStateMutex.Unlock()
+	if s.receivedPeerParams == nil {
+		return nil
+	}
+	params := *s.receivedPeerParams
+	return &params
+}
+
+func (s *connection) setStatelessResetToken(token *protocol.StatelessResetToken) {
+	s.connStateMutex.Lock()
+	s.statelessResetToken = token
+	s.connStateMutex.Unlock()
+}
+
+// newCongestionControl creates the congestion controller configured by the application.
+// If no CongestionControlFactory is set, it uses NewReno with the configured initial congestion window.
+func (s *connection) newCongestionControl() CongestionControl {
+	initialMaxDatagramSize := protocol.ByteCount(s.config.InitialPacketSize)
+	if s.config.CongestionControlFactory == nil {
+		return congestion.NewCubicSenderWithLimits(
+			s.clock,
+			s.rttStats,
+			initialMaxDatagramSize,
+			protocol.ByteCount(s.config.InitialCongestionWindow)*initialMaxDatagramSize,
+			protocol.ByteCount(s.config.MinCongestionWindow),
+			protocol.ByteCount(s.config.MaxCongestionWindow),
+			true, // use Reno
+			s.tracer,
+		)
+	}
+	return s.config.CongestionControlFactory(s.rttStats, initialMaxDatagramSize)
+}
+
+func (s *connection) Stats() ConnectionStats {
+	stats := s.sentPacketHandler.Stats()
+	rcvStats := s.receivedPacketHandler.Stats()
+	return ConnectionStats{
+		MinRTT:                        stats.MinRTT,
+		LatestRTT:                     stats.LatestRTT,
+		SmoothedRTT:                   stats.SmoothedRTT,
+		RTTVariance:                   stats.MeanDeviation,
+		CongestionWindow:              uint64(stats.CongestionWindow),
+		BytesInFlight:                 uint64(stats.BytesInFlight),
+		PacketsLost:                   stats.PacketsLost,
+		InitialPacketsRetransmitted:   stats.InitialPacketsRetransmitted,
+		HandshakePacketsRetransmitted: stats.HandshakePacketsRetransmitted,
+		ReorderedPackets:              rcvStats.ReorderedPackets,
+		DuplicatePackets:              rcvStats.DuplicatePackets,
+		BufferedStreamBytes:           uint64(s.connFlowController.UnreadBytes()),
+	}
+}
+
+func (s *connection) ECNStats() ECNStats {
+	stats := s.sentPacketHandler.Stats()
+	return ECNStats{
+		SentECT0:     stats.SentECT0,
+		SentECT1:     stats.SentECT1,
+		AckedECT0:    stats.AckedECT0,
+		AckedECT1:    stats.AckedECT1,
+		AckedCE:      stats.AckedECNCE,
+		ReceivedECT0: s.numReceivedECT0.Load(),
+		ReceivedECT1: s.numReceivedECT1.Load(),
+		ReceivedCE:   s.numReceivedECNCE.Load(),
+	}
+}
+
+func (s *connection) BytesSent() uint64     { return s.bytesSent.Load() }
+func (s *connection) BytesReceived() uint64 { return s.bytesReceived.Load() }
+
+func (s *connection) SetMaxIncomingStreams(num int64) {
+	s.streamsMap.SetMaxIncomingStreams(clipIncomingStreamLimit(num))
+}
+
+func (s *connection) SetMaxIncomingUniStreams(num int64) {
+	s.streamsMap.SetMaxIncomingUniStreams(clipIncomingStreamLimit(num))
+}
+
+func (s *connection) SetReceiveWindow(size protocol.ByteCount) {
+	s.connFlowController.SetReceiveWindowSize(size)
+}
+
+// clipIncomingStreamLimit converts a stream limit set by the application to the value used by the streams map.
+// Negative values don't allow any streams, and values larger than 2^60 are clipped to that value.
+func clipIncomingStreamLimit(num int64) uint64 {
+	if num < 0 {
+		return 0
+	}
+	return uint64(min(num, int64(protocol.MaxStreamCount)))
+}
+
+func (s *connection) EstimatedBandwidth() (uint64, bool) {
+	stats := s.sentPacketHandler.Stats()
+	return stats.DeliveryRate, stats.HasDeliveryRate
+}
+
+func (s *connection) GetConfig() *Config {
+	c := s.config.Clone()
+	c.Versions = slices.Clone(s.config.Versions)
+	c.CustomFrameHandlers = maps.Clone(s.config.CustomFrameHandlers)
+	if s.config.PreferredAddress != nil {
+		pa := *s.config.PreferredAddress
+		c.PreferredAddress = &pa
+	}
+	return c
+}
+
 // Time when the connection should time out
 func (s *connection) nextIdleTimeoutTime() time.Time {
 	idleTimeout := max(s.idleTimeout, s.rttStats.PTO(true)*3)
@@ -703,6 +1194,18 @@ func (s *connection) nextKeepAliveTime() time.Time {
 	return s.lastPacketReceivedTime.Add(keepAliveInterval)
 }
 
+// jitterKeepAliveInterval reduces the keep-alive interval by a random duration of up to jitter (capped to half the interval).
+// Only ever reducing the interval makes sure that the keep-alive is still sent before the idle timeout.
+func jitterKeepAliveInterval(interval, jitter time.Duration) time.Duration {
+	jitter = min(jitter, interval/2)
+	if jitter <= 0 {
+		return interval
+	}
+	const precision = 1 << 16
+	var r utils.Rand
+	return interval - time.Duration(int64(jitter)*int64(r.Int31n(precision+1))/precision)
+}
+
 func (s *connection) maybeResetTimer() {
 	var deadline time.Time
 	if !s.handshakeComplete {
@@ -716,6 +1219,11 @@ func (s *connection) maybeResetTimer() {
 		} else {
 			deadline = s.nextIdleTimeoutTime()
 		}
+		if s.pathMigration != nil {
+			deadline = utils.MinTime(deadline, s.pathMigration.nextProbe)
+		} else if s.backupPath != nil {
+			deadline = utils.MinTime(deadline, s.backupPath.nextValidation)
+		}
 	}
 
 	s.timer.SetTimer(
@@ -726,6 +1234,20 @@ func (s *connection) maybeResetTimer() {
 	)
 }
 
+// restartIdleTimer restarts the idle timer after receiving a packet.
+// If Config.IdleTimeoutIgnoresDatagrams is set, packets that don't contain any ack-eliciting frames other
+// than DATAGRAM frames only restart the idle timer if we're waiting for the peer to acknowledge a packet
+// that contained other frames.
+// This allows a connection that only sees DATAGRAM traffic to still time out.
+func (s *connection) restartIdleTimer(rcvTime time.Time, isDatagramOnly bool) {
+	if s.config.IdleTimeoutIgnoresDatagrams && isDatagramOnly && s.firstAckElicitingPacketAfterIdleSentTime.IsZero() {
+		return
+	}
+	s.lastPacketReceivedTime = rcvTime
+	s.firstAckElicitingPacketAfterIdleSentTime = time.Time{}
+	s.keepAlivePingSent = false
+}
+
 func (s *connection) idleTimeoutStartTime() time.Time {
 	return utils.MaxTime(s.lastPacketReceivedTime, s.firstAckElicitingPacketAfterIdleSentTime)
 }
@@ -739,6 +1261,10 @@ func (s *connection) handleHandshakeComplete() error {
 	s.connIDManager.SetHandshakeComplete()
 	s.connIDGenerator.SetHandshakeComplete()
 
+	s.connStateMutex.Lock()
+	s.connState.HandshakeDuration = s.clock.Now().Sub(s.creationTime)
+	s.connStateMutex.Unlock()
+
 	if s.tracer != nil && s.tracer.ChoseALPN != nil {
 		s.tracer.ChoseALPN(s.cryptoStreamHandler.ConnectionState().NegotiatedProtocol)
 	}
@@ -765,7 +1291,7 @@ func (s *connection) handleHandshakeComplete() error {
 			s.queueControlFrame(s.oneRTTStream.PopCryptoFrame(protocol.MaxPostHandshakeCryptoFrameSize))
 		}
 	}
-	token, err := s.tokenGenerator.NewToken(s.conn.RemoteAddr())
+	token, err := s.tokenGenerator.NewToken(s.conn.RemoteAddr(), s.clock.Now())
 	if err != nil {
 		return err
 	}
@@ -784,12 +1310,48 @@ func (s *connection) handleHandshakeConfirmed() error {
 	s.cryptoStreamHandler.SetHandshakeConfirmed()
 
 	if !s.config.DisablePathMTUDiscovery && s.conn.capabilities().DF {
-		s.mtuDiscoverer.Start()
+		s.mtuDiscoverer.Start(s.clock.Now())
+	}
+	if s.perspective == protocol.PerspectiveClient && s.config.UsePreferredAddress && s.peerPreferredAddress.Load() != nil {
+		s.migrateToPreferredAddress(s.clock.Now())
 	}
 	return nil
 }
 
+// migrateToPreferredAddress starts migrating the connection to the server's preferred address.
+// It must be called from the run loop. If path validation fails, the connection stays on the current path.
+func (s *connection) migrateToPreferredAddress(now time.Time) {
+	pa := s.peerPreferredAddress.Load()
+	// use an address of the same IP version as the current remote address, if possible
+	useIPv4 := pa.hasIPv4()
+	if addr, ok := s.RemoteAddr().(*net.UDPAddr); ok && addr.IP.To4() == nil && pa.hasIPv6() {
+		useIPv4 = false
+	}
+	local := &net.UDPAddr{IP: net.IPv6unspecified}
+	remote := net.UDPAddrFromAddrPort(pa.IPv6)
+	if useIPv4 {
+		local = &net.UDPAddr{IP: net.IPv4zero}
+		remote = net.UDPAddrFromAddrPort(pa.IPv4)
+	}
+	m, err := s.newPathMigration(local, remote)
+	if err != nil {
+		s.logger.Debugf("Not migrating to the preferred address %s: %s", remote, err)
+		return
+	}
+	m.preferredAddress = true
+	s.logger.Debugf("Migrating to the preferred address %s", remote)
+	s.startPathMigration(m, now)
+	go func() {
+		if err := <-m.result; err != nil {
+			s.logger.Debugf("Migration to the preferred address %s failed: %s", remote, err)
+			m.transport.Close()
+		}
+	}()
+}
+
 func (s *connection) handlePacketImpl(rp receivedPacket) bool {
+	// Put the packet buffers retained by datagrams that were released by the application back into the pool.
+	s.datagramQueue.ReleaseBuffers()
 	s.sentPacketHandler.ReceivedBytes(rp.Size())
 
 	if wire.IsVersionNegotiationPacket(rp.data) {
@@ -874,6 +1436,12 @@ func (s *connection) handlePacketImpl(rp receivedPacket) bool {
 		}
 	}
 
+	// The datagram didn't contain the application error corresponding to the APPLICATION_ERROR.
+	if s.handshakeCloseErr != nil {
+		s.closeRemote(s.handshakeCloseErr)
+		s.handshakeCloseErr = nil
+	}
+
 	p.buffer.MaybeRelease()
 	return processed
 }
@@ -888,6 +1456,16 @@ func (s *connection) handleShortHeaderPacket(p receivedPacket, destConnID protoc
 		}
 	}()
 
+	// The QUIC bit is not header-protected.
+	// The peer is only allowed to grease it if we sent the grease_quic_bit transport parameter.
+	if !s.acceptsGreasedQUICBit() && !wire.IsPotentialQUICPacket(p.data[0]) {
+		s.logger.Debugf("Dropping packet with an unset QUIC bit.")
+		if s.tracer != nil && s.tracer.DroppedPacket != nil {
+			s.tracer.DroppedPacket(logging.PacketType1RTT, protocol.InvalidPacketNumber, p.Size(), logging.PacketDropHeaderParseError)
+		}
+		return false
+	}
+
 	pn, pnLen, keyPhase, data, err := s.unpacker.UnpackShortHeader(p.rcvTime, p.data)
 	if err != nil {
 		wasQueued = s.handleUnpackError(err, p, logging.PacketType1RTT)
@@ -906,6 +1484,10 @@ func (s *connection) handleShortHeaderPacket(p receivedPacket, destConnID protoc
 		}
 		return false
 	}
+	if s.config.EnableSpinBit {
+		// The spin bit is not protected by header protection.
+		s.updateSpinBit(pn, p.data[0]&0x20 > 0)
+	}
 
 	var log func([]logging.Frame)
 	if s.tracer != nil && s.tracer.ReceivedShortHeaderPacket != nil {
@@ -923,13 +1505,132 @@ func (s *connection) handleShortHeaderPacket(p receivedPacket, destConnID protoc
 			)
 		}
 	}
-	if err := s.handleUnpackedShortHeaderPacket(destConnID, pn, data, p.ecn, p.rcvTime, log); err != nil {
+	if s.config.OnPacketReceived != nil {
+		s.config.OnPacketReceived(protocol.Encryption1RTT, pn, p.Size(), p.rcvTime)
+	}
+	s.rcvBuffer = p.buffer
+	s.rcvTime = p.rcvTime
+	if p.remoteAddr != nil && !isOnPath(s.conn, p.remoteAddr, p.info) {
+		s.rcvAddr = p.remoteAddr
+		s.rcvInfo = p.info
+	}
+	s.rcvNonProbing = false
+	err = s.handleUnpackedShortHeaderPacket(destConnID, pn, data, p.ecn, p.rcvTime, log)
+	s.rcvBuffer = nil
+	if err != nil {
+		s.rcvAddr = nil
 		s.closeLocal(err)
 		return false
 	}
+	if m := s.pathMigration; m != nil && m.peerAddr && s.rcvAddr != nil && isOnPath(m.conn, s.rcvAddr, s.rcvInfo) {
+		m.bytesReceived += p.Size()
+	}
+	if s.rcvNonProbing && pn > s.largestNonProbingPN {
+		s.largestNonProbingPN = pn
+		if s.rcvAddr != nil {
+			s.handlePeerAddressChange(p.Size())
+		} else if m := s.pathMigration; m != nil && m.peerAddr {
+			// The packet that triggered path validation might have been sent by an attacker,
+			// using a spoofed address (RFC 9000, section 9.3.2).
+			s.abortPathMigration(errors.New("peer continued using the current address"))
+		}
+	}
+	s.rcvAddr = nil
 	return true
 }
 
+// handlePeerAddressChange is called when the non-probing packet with the largest packet number
+// was received on a new path (RFC 9000, section 9.3), i.e. from a new peer address or on our preferred address.
+// This happens when the client migrated the connection, or when its address changed due to a NAT rebinding.
+// The server only switches to the new path once it was validated.
+// Until then, packets are sent on the current path, and at most three times the amount of data received
+// from the new address is sent to it (RFC 9000, section 8).
+func (s *connection) handlePeerAddressChange(size protocol.ByteCount) {
+	if s.perspective == protocol.PerspectiveClient || !s.handshakeConfirmed {
+		return
+	}
+	// disable_active_migration doesn't apply to the preferred address (RFC 9000, section 18.2)
+	if s.config.DisableActiveMigration && !s.isPreferredAddress(s.rcvInfo) {
+		return
+	}
+	if m := s.pathMigration; m != nil {
+		// the data received from the address was already accounted for
+		if isOnPath(m.conn, s.rcvAddr, s.rcvInfo) {
+			return
+		}
+		s.abortPathMigration(errors.New("peer migrated to a different address"))
+	}
+	s.logger.Debugf("Peer migrated to %s, validating the new address", s.rcvAddr)
+	now := s.clock.Now()
+	m := &pathMigration{
+		runner:        s.pathRunner,
+		conn:          s.conn.withRemoteAddr(s.rcvAddr, s.rcvInfo),
+		result:        make(chan error, 1),
+		peerAddr:      true,
+		bytesReceived: size,
+		deadline:      now.Add(3 * s.rttStats.PTO(true)),
+	}
+	// If the peer didn't provide an unused connection ID, the current one is used on the new path.
+	// This is allowed, since the peer didn't necessarily initiate the address change (RFC 9000, section 9.5).
+	connID, ok := s.connIDManager.PeekNext()
+	if !ok {
+		connID = s.connIDManager.Get()
+		m.reuseConnID = true
+	}
+	m.connID = connID
+	s.pathMigration = m
+	s.maybeSendPathChallenge(now)
+}
+
+// maxProbePacketSize returns the maximum size of a probe packet sent on a path.
+// This is only limited if the path is used to validate a new peer address.
+func (s *connection) maxProbePacketSize(conn sendConn) protocol.ByteCount {
+	m := s.pathMigration
+	if m == nil || !m.peerAddr || !equalAddrs(conn.RemoteAddr(), m.conn.RemoteAddr()) {
+		return protocol.MinInitialPacketSize
+	}
+	return 3*m.bytesReceived - m.bytesSent
+}
+
+func (s *connection) sentProbePacket(conn sendConn, size protocol.ByteCount) {
+	if m := s.pathMigration; m != nil && m.peerAddr && equalAddrs(conn.RemoteAddr(), m.conn.RemoteAddr()) {
+		m.bytesSent += size
+	}
+}
+
+// isPreferredAddress says if a packet was received on the preferred address that the server advertised.
+func (s *connection) isPreferredAddress(info packetInfo) bool {
+	pa := s.config.PreferredAddress
+	if pa == nil || !info.addr.IsValid() {
+		return false
+	}
+	addr := info.addr.Unmap()
+	return (pa.hasIPv4() && pa.IPv4.Addr() == addr) || (pa.hasIPv6() && pa.IPv6.Addr() == addr)
+}
+
+// isOnPath says if a packet received from remote, on the local address in info, was received on the path of conn.
+// The local address is only compared if the socket is bound to a specific address,
+// or if the packet info was available when the path was created.
+func isOnPath(conn sendConn, remote net.Addr, info packetInfo) bool {
+	if !equalAddrs(remote, conn.RemoteAddr()) {
+		return false
+	}
+	local, ok := conn.LocalAddr().(*net.UDPAddr)
+	if !ok || !info.addr.IsValid() || local.IP.IsUnspecified() {
+		return true
+	}
+	return local.IP.Equal(info.addr.AsSlice())
+}
+
+func equalAddrs(a, b net.Addr) bool {
+	ua, ok1 := a.(*net.UDPAddr)
+	ub, ok2 := b.(*net.UDPAddr)
+	if !ok1 || !ok2 {
+		return a.String() == b.String()
+	}
+	return ua.Port == ub.Port && ua.IP.Equal(ub.IP) && ua.Zone == ub.Zone
+}
+
 func (s *connection) handleLongHeaderPacket(p receivedPacket, hdr *wire.Header) bool /* was the packet successfully processed */ {
 	var wasQueued bool
 
@@ -980,7 +1681,11 @@ func (s *connection) handleLongHeaderPacket(p receivedPacket, hdr *wire.Header)
 		return false
 	}
 
-	if err := s.handleUnpackedLongHeaderPacket(packet, p.ecn, p.rcvTime, p.Size()); err != nil {
+	s.rcvBuffer = p.buffer
+	s.rcvTime = p.rcvTime
+	err = s.handleUnpackedLongHeaderPacket(packet, p.ecn, p.rcvTime, p.Size())
+	s.rcvBuffer = nil
+	if err != nil {
 		s.closeLocal(err)
 		return false
 	}
@@ -1147,7 +1852,7 @@ func (s *connection) handleUnpackedLongHeaderPacket(
 	packet *unpackedPacket,
 	ecn protocol.ECN,
 	rcvTime time.Time,
-	packetSize protocol.ByteCount, // only for logging
+	packetSize protocol.ByteCount, // only for logging and OnPacketReceived
 ) error {
 	if !s.receivedFirstPacket {
 		s.receivedFirstPacket = true
@@ -1161,6 +1866,9 @@ func (s *connection) handleUnpackedLongHeaderPacket(
 			}
 			s.tracer.NegotiatedVersion(s.version, clientVersions, serverVersions)
 		}
+		if s.config.OnVersionNegotiated != nil {
+			s.config.OnVersionNegotiated(s.version)
+		}
 		// The server can change the source connection ID with the first Handshake packet.
 		if s.perspective == protocol.PerspectiveClient && packet.hdr.SrcConnectionID != s.handshakeDestConnID {
 			cid := packet.hdr.SrcConnectionID
@@ -1198,21 +1906,21 @@ func (s *connection) handleUnpackedLongHeaderPacket(
 		}
 	}
 
-	s.lastPacketReceivedTime = rcvTime
-	s.firstAckElicitingPacketAfterIdleSentTime = time.Time{}
-	s.keepAlivePingSent = false
-
+	if s.config.OnPacketReceived != nil {
+		s.config.OnPacketReceived(packet.encryptionLevel, packet.hdr.PacketNumber, packetSize, rcvTime)
+	}
 	var log func([]logging.Frame)
 	if s.tracer != nil && s.tracer.ReceivedLongHeaderPacket != nil {
 		log = func(frames []logging.Frame) {
 			s.tracer.ReceivedLongHeaderPacket(packet.hdr, packetSize, ecn, frames)
 		}
 	}
-	isAckEliciting, err := s.handleFrames(packet.data, packet.hdr.DestConnectionID, packet.encryptionLevel, log)
+	isAckEliciting, isDatagramOnly, err := s.handleFrames(packet.data, packet.hdr.DestConnectionID, packet.encryptionLevel, log)
 	if err != nil {
 		return err
 	}
-	return s.receivedPacketHandler.ReceivedPacket(packet.hdr.PacketNumber, ecn, packet.encryptionLevel, rcvTime, isAckEliciting)
+	s.restartIdleTimer(rcvTime, isDatagramOnly)
+	return s.receivedPacketHandler.ReceivedPacket(packet.hdr.PacketNumber, s.countReceivedECN(ecn), packet.encryptionLevel, rcvTime, isAckEliciting)
 }
 
 func (s *connection) handleUnpackedShortHeaderPacket(
@@ -1223,23 +1931,63 @@ func (s *connection) handleUnpackedShortHeaderPacket(
 	rcvTime time.Time,
 	log func([]logging.Frame),
 ) error {
-	s.lastPacketReceivedTime = rcvTime
-	s.firstAckElicitingPacketAfterIdleSentTime = time.Time{}
-	s.keepAlivePingSent = false
-
-	isAckEliciting, err := s.handleFrames(data, destConnID, protocol.Encryption1RTT, log)
+	isAckEliciting, isDatagramOnly, err := s.handleFrames(data, destConnID, protocol.Encryption1RTT, log)
 	if err != nil {
 		return err
 	}
-	return s.receivedPacketHandler.ReceivedPacket(pn, ecn, protocol.Encryption1RTT, rcvTime, isAckEliciting)
+	s.restartIdleTimer(rcvTime, isDatagramOnly)
+	return s.receivedPacketHandler.ReceivedPacket(pn, s.countReceivedECN(ecn), protocol.Encryption1RTT, rcvTime, isAckEliciting)
 }
 
+// updateSpinBit updates the spin bit when receiving a 1-RTT packet (RFC 9000, section 17.4).
+// The server reflects the spin bit of the packet with the largest packet number received from the client,
+// and the client inverts it.
+func (s *connection) updateSpinBit(pn protocol.PacketNumber, spin bool) {
+	if pn <= s.spinBitPN {
+		return
+	}
+	s.spinBitPN = pn
+	if s.perspective == protocol.PerspectiveClient {
+		spin = !spin
+	}
+	if spin != s.spinBit.Load() {
+		s.spinBit.Store(spin)
+		s.packer.SetSpinBit(spin)
+	}
+}
+
+func (s *connection) SpinBit() uint8 {
+	if s.spinBit.Load() {
+		return 1
+	}
+	return 0
+}
+
+// countReceivedECN counts the ECN marking of a received packet.
+// It returns the marking that is reported to the peer in ACK frames.
+func (s *connection) countReceivedECN(ecn protocol.ECN) protocol.ECN {
+	//nolint:exhaustive // Only need to count ECT(0), ECT(1) and ECN-CE.
+	switch ecn {
+	case protocol.ECT0:
+		s.numReceivedECT0.Add(1)
+	case protocol.ECT1:
+		s.numReceivedECT1.Add(1)
+	case protocol.ECNCE:
+		s.numReceivedECNCE.Add(1)
+	}
+	if s.config.DisableECN {
+		return protocol.ECNNon
+	}
+	return ecn
+}
+
+// isDatagramOnly is true if the packet didn't contain any ack-eliciting frames other than DATAGRAM frames.
 func (s *connection) handleFrames(
 	data []byte,
 	destConnID protocol.ConnectionID,
 	encLevel protocol.EncryptionLevel,
 	log func([]logging.Frame),
-) (isAckEliciting bool, _ error) {
+) (isAckEliciting, isDatagramOnly bool, _ error) {
 	// Only used for tracing.
 	// If we're not tracing, this slice will always remain empty.
 	var frames []logging.Frame
@@ -1247,11 +1995,12 @@ func (s *connection) handleFrames(
 		frames = make([]logging.Frame, 0, 4)
 	}
 	handshakeWasComplete := s.handshakeComplete
+	var hasNonDatagramFrames bool
 	var handleErr error
 	for len(data) > 0 {
 		l, frame, err := s.frameParser.ParseNext(data, encLevel, s.version)
 		if err != nil {
-			return false, err
+			return false, false, err
 		}
 		data = data[l:]
 		if frame == nil {
@@ -1259,6 +2008,12 @@ func (s *connection) handleFrames(
 		}
 		if ackhandler.IsFrameAckEliciting(frame) {
 			isAckEliciting = true
+			if _, ok := frame.(*wire.DatagramFrame); !ok {
+				hasNonDatagramFrames = true
+			}
+		}
+		if !isProbingFrame(frame) {
+			s.rcvNonProbing = true
 		}
 		if log != nil {
 			frames = append(frames, logutils.ConvertFrame(frame))
@@ -1270,7 +2025,7 @@ func (s *connection) handleFrames(
 		}
 		if err := s.handleFrame(frame, encLevel, destConnID); err != nil {
 			if log == nil {
-				return false, err
+				return false, false, err
 			}
 			// If we're logging, we need to keep parsing (but not handling) all frames.
 			handleErr = err
@@ -1280,7 +2035,7 @@ func (s *connection) handleFrames(
 	if log != nil {
 		log(frames)
 		if handleErr != nil {
-			return false, handleErr
+			return false, false, handleErr
 		}
 	}
 
@@ -1290,15 +2045,27 @@ func (s *connection) handleFrames(
 	// and an ACK serialized after that CRYPTO frame. In this case, we still want to process the ACK frame.
 	if !handshakeWasComplete && s.handshakeComplete {
 		if err := s.handleHandshakeComplete(); err != nil {
-			return false, err
+			return false, false, err
 		}
 	}
 
-	return
+	return isAckEliciting, !hasNonDatagramFrames, nil
 }
 
-func (s *connection) handleFrame(f wire.Frame, encLevel protocol.EncryptionLevel, destConnID protocol.ConnectionID) error {
-	var err error
+// isProbingFrame says if a frame is a probing frame (RFC 9000, section 9.1).
+// PADDING frames are probing frames as well, but they are skipped by the frame parser.
+// Receiving a packet that only contains probing frames from a new address doesn't migrate the connection.
+func isProbingFrame(f wire.Frame) bool {
+	switch f.(type) {
+	case *wire.PathChallengeFrame, *wire.PathResponseFrame, *wire.NewConnectionIDFrame:
+		return true
+	default:
+		return false
+	}
+}
+
+func (s *connection) handleFrame(f wire.Frame, encLevel protocol.EncryptionLevel, destConnID protocol.ConnectionID) error {
+	var err error
 	wire.LogFrame(s.logger, f, false)
 	switch frame := f.(type) {
 	case *wire.CryptoFrame:
@@ -1308,9 +2075,15 @@ func (s *connection) handleFrame(f wire.Frame, encLevel protocol.EncryptionLevel
 	case *wire.AckFrame:
 		err = s.handleAckFrame(frame, encLevel)
 	case *wire.ConnectionCloseFrame:
-		s.handleConnectionCloseFrame(frame)
+		s.handleConnectionCloseFrame(frame, encLevel)
 	case *wire.ResetStreamFrame:
 		err = s.handleResetStreamFrame(frame)
+	case *wire.ResetStreamAtFrame:
+		err = s.handleResetStreamAtFrame(frame)
+	case *wire.AckFrequencyFrame:
+		err = s.handleAckFrequencyFrame(frame)
+	case *wire.ImmediateAckFrame:
+		s.receivedPacketHandler.ReceivedImmediateAck()
 	case *wire.MaxDataFrame:
 		s.handleMaxDataFrame(frame)
 	case *wire.MaxStreamDataFrame:
@@ -1326,8 +2099,7 @@ func (s *connection) handleFrame(f wire.Frame, encLevel protocol.EncryptionLevel
 	case *wire.PathChallengeFrame:
 		s.handlePathChallengeFrame(frame)
 	case *wire.PathResponseFrame:
-		// since we don't send PATH_CHALLENGEs, we don't expect PATH_RESPONSEs
-		err = errors.New("unexpected PATH_RESPONSE frame")
+		err = s.handlePathResponseFrame(frame)
 	case *wire.NewTokenFrame:
 		err = s.handleNewTokenFrame(frame)
 	case *wire.NewConnectionIDFrame:
@@ -1338,6 +2110,18 @@ func (s *connection) handleFrame(f wire.Frame, encLevel protocol.EncryptionLevel
 		err = s.handleHandshakeDoneFrame()
 	case *wire.DatagramFrame:
 		err = s.handleDatagramFrame(frame)
+	case *wire.MaxDatagramsFrame:
+		s.datagramQueue.SetMaxDatagrams(frame.MaximumDatagrams)
+	case *wire.CustomFrame:
+		handler, ok := s.config.CustomFrameHandlers[frame.Type]
+		if !ok {
+			return &qerr.TransportError{
+				ErrorCode:    qerr.FrameEncodingError,
+				FrameType:    frame.Type,
+				ErrorMessage: "unregistered custom frame type",
+			}
+		}
+		handler(s, frame.Data)
 	default:
 		err = fmt.Errorf("unexpected frame type: %s", reflect.ValueOf(&frame).Elem().Type().Name())
 	}
@@ -1346,6 +2130,10 @@ func (s *connection) handleFrame(f wire.Frame, encLevel protocol.EncryptionLevel
 
 // handlePacket is called by the server with a new packet
 func (s *connection) handlePacket(p receivedPacket) {
+	// The receive time is set by the Transport, using the wall clock.
+	if s.config.Clock != nil {
+		p.rcvTime = s.clock.Now()
+	}
 	// Discard packets once the amount of queued packets is larger than
 	// the channel size, protocol.MaxConnUnprocessedPackets
 	select {
@@ -1357,7 +2145,21 @@ func (s *connection) handlePacket(p receivedPacket) {
 	}
 }
 
-func (s *connection) handleConnectionCloseFrame(frame *wire.ConnectionCloseFrame) {
+func (s *connection) handleConnectionCloseFrame(frame *wire.ConnectionCloseFrame, encLevel protocol.EncryptionLevel) {
+	// Application errors can't be sent in Initial and Handshake packets (RFC 9000, section 10.2.3).
+	// When closing the connection with an application error during the handshake, the peer therefore
+	// sends the APPLICATION_ERROR error code in Initial and Handshake packets, and the application error
+	// in a 1-RTT packet (if it has the 1-RTT keys already).
+	// Wait for the rest of the datagram to be processed, such that the application error is surfaced, if possible.
+	if !frame.IsApplicationError && frame.ErrorCode == uint64(qerr.ApplicationErrorErrorCode) && encLevel != protocol.Encryption1RTT {
+		s.handshakeCloseErr = &qerr.TransportError{
+			Remote:       true,
+			ErrorCode:    qerr.ApplicationErrorErrorCode,
+			FrameType:    frame.FrameType,
+			ErrorMessage: frame.ReasonPhrase,
+		}
+		return
+	}
 	if frame.IsApplicationError {
 		s.closeRemote(&qerr.ApplicationError{
 			Remote:       true,
@@ -1402,6 +2204,7 @@ func (s *connection) handleHandshakeEvents() error {
 			s.undecryptablePacketsToProcess = s.undecryptablePackets
 			s.undecryptablePackets = nil
 		case handshake.EventDiscard0RTTKeys:
+			s.streamsMap.ResetFor0RTT(&ZeroRTTRejectedError{Reason: ev.ZeroRTTRejectionReason})
 			err = s.dropEncryptionLevel(protocol.Encryption0RTT)
 		case handshake.EventWriteInitialData:
 			_, err = s.initialStream.Write(ev.Data)
@@ -1460,6 +2263,18 @@ func (s *connection) handleResetStreamFrame(frame *wire.ResetStreamFrame) error
 	return str.handleResetStreamFrame(frame)
 }
 
+func (s *connection) handleResetStreamAtFrame(frame *wire.ResetStreamAtFrame) error {
+	str, err := s.streamsMap.GetOrOpenReceiveStream(frame.StreamID)
+	if err != nil {
+		return err
+	}
+	if str == nil {
+		// stream is closed and already garbage collected
+		return nil
+	}
+	return str.handleResetStreamAtFrame(frame)
+}
+
 func (s *connection) handleStopSendingFrame(frame *wire.StopSendingFrame) error {
 	str, err := s.streamsMap.GetOrOpenSendStream(frame.StreamID)
 	if err != nil {
@@ -1474,7 +2289,562 @@ func (s *connection) handleStopSendingFrame(frame *wire.StopSendingFrame) error
 }
 
 func (s *connection) handlePathChallengeFrame(frame *wire.PathChallengeFrame) {
-	s.queueControlFrame(&wire.PathResponseFrame{Data: frame.Data})
+	if s.rcvAddr == nil {
+		s.queueControlFrame(&wire.PathResponseFrame{Data: frame.Data})
+		return
+	}
+	// The PATH_RESPONSE needs to be sent on the path the PATH_CHALLENGE was received on (RFC 9000, section 8.2.2).
+	s.sendPathResponse(s.conn.withRemoteAddr(s.rcvAddr, s.rcvInfo), frame.Data)
+}
+
+// sendPathResponse sends a PATH_RESPONSE on a path other than the current path.
+func (s *connection) sendPathResponse(conn sendConn, data [8]byte) {
+	now := s.clock.Now()
+	response := ackhandler.Frame{Frame: &wire.PathResponseFrame{Data: data}}
+	p, buf, err := s.packer.PackPathProbePacket(s.connIDManager.Get(), response, s.maxProbePacketSize(conn), s.version)
+	if err != nil {
+		s.logger.Debugf("Failed to pack PATH_RESPONSE: %s", err)
+		return
+	}
+	s.sentProbePacket(conn, buf.Len())
+	s.logShortHeaderPacket(p.DestConnID, p.Ack, p.Frames, p.StreamFrames, p.PacketNumber, p.PacketNumberLen, p.KeyPhase, protocol.ECNNon, buf.Len(), false)
+	s.sentPacketHandler.SentPacket(now, p.PacketNumber, protocol.InvalidPacketNumber, nil, p.Frames, protocol.Encryption1RTT, protocol.ECNNon, p.Length, true)
+	s.reportPacketSent(protocol.Encryption1RTT, p.PacketNumber, p.Length, now)
+	ecn := protocol.ECNUnsupported
+	if conn.capabilities().ECN {
+		ecn = protocol.ECNNon
+	}
+	s.traceSentUDPDatagram(buf.Len(), 1)
+	if err := conn.Write(buf.Data, 0, ecn); err != nil {
+		s.logger.Debugf("Failed to send PATH_RESPONSE to %s: %s", conn.RemoteAddr(), err)
+	}
+	buf.Release()
+}
+
+func (s *connection) handlePathResponseFrame(frame *wire.PathResponseFrame) error {
+	// If we never sent a PATH_CHALLENGE, we don't expect PATH_RESPONSEs.
+	if !s.sentPathChallenge {
+		return errors.New("unexpected PATH_RESPONSE frame")
+	}
+	// PATH_RESPONSEs for retransmitted PATH_CHALLENGEs, or for a path that already failed validation, are ignored.
+	m := s.pathMigration
+	if m == nil {
+		return nil
+	}
+	i := slices.Index(m.challenges, frame.Data)
+	if i < 0 {
+		return nil
+	}
+	if m.probeOnly {
+		s.finishPathProbe(m, s.clock.Now().Sub(m.challengeSent[i]))
+		return nil
+	}
+	s.switchToPath(m)
+	return nil
+}
+
+var errPathValidationTimeout = errors.New("path validation timed out")
+
+// A pathMigration is a request to migrate the connection to a new path.
+// It is created by MigrateTo (or ProbePath) and then handed over to the run loop.
+// On the server side, it is used to validate a new peer address.
+type pathMigration struct {
+	transport *Transport
+	runner    connRunner // the connRunner of the Transport, packets received on the new path are routed by it
+	conn      sendConn
+	result    chan error // the result of path validation, nil if the connection switched to the new path
+	// If probeOnly is set, the path is only validated, and the connection stays on the current path.
+	probeOnly bool
+	rtt       time.Duration // the RTT measured by a successful probe, set before the result is sent
+	// backup is set when validating a backup path, either when it is added or when it is revalidated.
+	backup *backupPath
+	// peerAddr is set when the server validates a new peer address.
+	// The data sent to that address is limited by bytesReceived.
+	peerAddr                 bool
+	bytesReceived, bytesSent protocol.ByteCount
+	// reuseConnID is set if the current connection ID is used on the new path.
+	reuseConnID bool
+	// preferredAddress is set when the client migrates to the server's preferred address.
+	// This is allowed even if the server disabled active migration (RFC 9000, section 18.2).
+	preferredAddress bool
+
+	connID        protocol.ConnectionID
+	challenges    [][8]byte
+	challengeSent []time.Time
+	nextProbe     time.Time
+	deadline      time.Time
+}
+
+func (s *connection) MigrateTo(local net.Addr) error {
+	if s.perspective == protocol.PerspectiveServer {
+		return errors.New("only the client can migrate a connection")
+	}
+	addr, ok := local.(*net.UDPAddr)
+	if !ok {
+		return fmt.Errorf("cannot migrate to a %T address", local)
+	}
+	m, err := s.newPathMigration(addr, s.RemoteAddr())
+	if err != nil {
+		return err
+	}
+	if err := s.runPathMigration(m); err != nil {
+		m.transport.Close()
+		return err
+	}
+	return nil
+}
+
+func (s *connection) ProbePath(local, remote net.Addr) (PathInfo, error) {
+	if s.perspective == protocol.PerspectiveServer {
+		return PathInfo{}, errors.New("only the client can probe a path")
+	}
+	laddr, raddr, err := s.parsePathAddrs(local, remote)
+	if err != nil {
+		return PathInfo{}, err
+	}
+	m, err := s.newPathMigration(laddr, raddr)
+	if err != nil {
+		return PathInfo{}, err
+	}
+	// The probe socket is only used for path validation.
+	defer m.transport.Close()
+	m.probeOnly = true
+	if err := s.runPathMigration(m); err != nil {
+		if errors.Is(err, errPathValidationTimeout) {
+			return PathInfo{}, nil
+		}
+		return PathInfo{}, err
+	}
+	return PathInfo{Validated: true, RTT: m.rtt}, nil
+}
+
+func (s *connection) AddPath(local, remote net.Addr) (PathID, error) {
+	if s.perspective == protocol.PerspectiveServer {
+		return 0, errors.New("only the client can add a path")
+	}
+	laddr, raddr, err := s.parsePathAddrs(local, remote)
+	if err != nil {
+		return 0, err
+	}
+	m, err := s.newPathMigration(laddr, raddr)
+	if err != nil {
+		return 0, err
+	}
+	m.probeOnly = true
+	m.backup = &backupPath{transport: m.transport, runner: m.runner, conn: m.conn}
+	if err := s.runPathMigration(m); err != nil {
+		m.transport.Close()
+		return 0, err
+	}
+	return m.backup.id, nil
+}
+
+type pathRequestType uint8
+
+const (
+	pathRequestRemove pathRequestType = iota
+	pathRequestSwitch
+	pathRequestStatus
+)
+
+// A pathRequest is a request to remove, switch to, or query the status of a backup path.
+// It is created by RemovePath, SwitchToPath or PathStatus and then handed over to the run loop.
+type pathRequest struct {
+	id     PathID
+	typ    pathRequestType
+	info   PathInfo // the status of the path, set before the result is sent
+	result chan error
+}
+
+func (s *connection) PeerPreferredAddress() *PreferredAddress {
+	return s.peerPreferredAddress.Load()
+}
+
+func (s *connection) MigrationAllowed() bool {
+	if s.perspective == protocol.PerspectiveServer {
+		return !s.config.DisableActiveMigration
+	}
+	return s.peerAllowsMigration.Load()
+}
+
+func (s *connection) RemovePath(id PathID) error {
+	return s.runPathRequest(&pathRequest{id: id, typ: pathRequestRemove})
+}
+
+func (s *connection) SwitchToPath(id PathID) error {
+	return s.runPathRequest(&pathRequest{id: id, typ: pathRequestSwitch})
+}
+
+func (s *connection) PathStatus(id PathID) (PathInfo, error) {
+	r := &pathRequest{id: id, typ: pathRequestStatus}
+	if err := s.runPathRequest(r); err != nil {
+		return PathInfo{}, err
+	}
+	return r.info, nil
+}
+
+func (s *connection) runPathRequest(r *pathRequest) error {
+	r.result = make(chan error, 1)
+	select {
+	case s.pathRequestChan <- r:
+	case <-s.ctx.Done():
+		return context.Cause(s.ctx)
+	}
+	return <-r.result
+}
+
+// parsePathAddrs checks the addresses passed to ProbePath and AddPath.
+// If remote is nil, the peer's current address is used.
+func (s *connection) parsePathAddrs(local, remote net.Addr) (*net.UDPAddr, net.Addr, error) {
+	laddr, ok := local.(*net.UDPAddr)
+	if !ok {
+		return nil, nil, fmt.Errorf("cannot use a %T as local address", local)
+	}
+	if remote == nil {
+		return laddr, s.RemoteAddr(), nil
+	}
+	if _, ok := remote.(*net.UDPAddr); !ok {
+		return nil, nil, fmt.Errorf("cannot use a %T as remote address", remote)
+	}
+	return laddr, remote, nil
+}
+
+// newPathMigration opens a new UDP socket bound to local, and creates a Transport for it.
+// Packets received on this socket are routed to the connection by their connection ID,
+// the same way as packets received on the Transport that the connection was created on.
+func (s *connection) newPathMigration(local *net.UDPAddr, remote net.Addr) (*pathMigration, error) {
+	c, err := net.ListenUDP("udp", local)
+	if err != nil {
+		return nil, err
+	}
+	tr := &Transport{Conn: c, ConnectionIDLength: s.srcConnIDLen, createdConn: true}
+	if err := tr.init(s.srcConnIDLen == 0); err != nil {
+		c.Close()
+		return nil, err
+	}
+	return &pathMigration{
+		transport: tr,
+		runner:    tr.handlerMap,
+		conn:      newSendConn(tr.conn, remote, packetInfo{}, s.logger),
+		result:    make(chan error, 1),
+	}, nil
+}
+
+// runPathMigration hands the path migration over to the run loop, and blocks until path validation completes.
+func (s *connection) runPathMigration(m *pathMigration) error {
+	select {
+	case s.pathMigrationChan <- m:
+	case <-s.ctx.Done():
+		return context.Cause(s.ctx)
+	}
+	return <-m.result
+}
+
+// startPathMigration starts validating a new path.
+// It must be called from the run loop.
+func (s *connection) startPathMigration(m *pathMigration, now time.Time) {
+	if !s.handshakeConfirmed {
+		m.result <- errors.New("cannot migrate before the handshake is confirmed")
+		return
+	}
+	if s.peerParams.DisableActiveMigration && !m.preferredAddress {
+		m.result <- ErrActiveMigrationDisabled
+		return
+	}
+	if s.pathMigration != nil {
+		if s.backupPath == nil || s.pathMigration.backup != s.backupPath {
+			m.result <- errors.New("migration already in progress")
+			return
+		}
+		// Revalidation of the backup path is retried later.
+		s.pathMigration = nil
+		s.backupPath.nextValidation = now.Add(s.backupPathValidationInterval())
+	}
+	if m.backup != nil && s.backupPath != nil {
+		m.result <- errors.New("only a single backup path is supported")
+		return
+	}
+	// The ECN tracker is set up when the connection is created, and can't be disabled later on.
+	if s.conn.capabilities().ECN && !m.conn.capabilities().ECN {
+		m.result <- errors.New("new path doesn't support ECN")
+		return
+	}
+	// A new connection ID needs to be used on the new path,
+	// such that on-path observers can't link the two paths.
+	// A backup path keeps using its connection ID, so it is reserved.
+	var connID protocol.ConnectionID
+	var ok bool
+	if m.backup != nil {
+		connID, ok = s.connIDManager.Reserve()
+	} else {
+		connID, ok = s.connIDManager.PeekNext()
+	}
+	if !ok {
+		m.result <- errors.New("peer didn't provide an unused connection ID")
+		return
+	}
+	s.addRunner(m.runner)
+	m.connID = connID
+	m.deadline = now.Add(3 * s.rttStats.PTO(true))
+	s.pathMigration = m
+	s.maybeSendPathChallenge(now)
+}
+
+// maybeSendPathChallenge sends a (retransmission of a) PATH_CHALLENGE on the new path,
+// or terminates path validation if the deadline has passed.
+func (s *connection) maybeSendPathChallenge(now time.Time) {
+	m := s.pathMigration
+	if !now.Before(m.deadline) {
+		s.abortPathMigration(errPathValidationTimeout)
+		return
+	}
+	var data [8]byte
+	rand.Read(data[:])
+	challenge := ackhandler.Frame{Frame: &wire.PathChallengeFrame{Data: data}}
+	p, buf, err := s.packer.PackPathProbePacket(m.connID, challenge, s.maxProbePacketSize(m.conn), s.version)
+	if err == errNothingToPack {
+		// Blocked by the anti-amplification limit. Retry once more data was received on the path.
+		m.nextProbe = now.Add(s.rttStats.PTO(true))
+		return
+	}
+	if err != nil {
+		s.abortPathMigration(err)
+		return
+	}
+	m.challenges = append(m.challenges, data)
+	m.challengeSent = append(m.challengeSent, now)
+	s.sentProbePacket(m.conn, buf.Len())
+	s.logShortHeaderPacket(p.DestConnID, p.Ack, p.Frames, p.StreamFrames, p.PacketNumber, p.PacketNumberLen, p.KeyPhase, protocol.ECNNon, buf.Len(), false)
+	// Like Path MTU probe packets, the loss of path probe packets isn't reported to the congestion controller.
+	s.sentPacketHandler.SentPacket(now, p.PacketNumber, protocol.InvalidPacketNumber, nil, p.Frames, protocol.Encryption1RTT, protocol.ECNNon, p.Length, true)
+	s.reportPacketSent(protocol.Encryption1RTT, p.PacketNumber, p.Length, now)
+	s.sentPathChallenge = true
+	ecn := protocol.ECNUnsupported
+	if m.conn.capabilities().ECN {
+		ecn = protocol.ECNNon
+	}
+	s.traceSentUDPDatagram(buf.Len(), 1)
+	err = m.conn.Write(buf.Data, 0, ecn)
+	buf.Release()
+	if err != nil {
+		s.abortPathMigration(err)
+		return
+	}
+	m.nextProbe = now.Add(s.rttStats.PTO(true))
+}
+
+func (s *connection) abortPathMigration(err error) {
+	m := s.pathMigration
+	s.logger.Debugf("Migration to %s failed: %s", m.conn.LocalAddr(), err)
+	if b := m.backup; b != nil {
+		if b == s.backupPath {
+			b.validated = false
+			b.nextValidation = s.clock.Now().Add(s.backupPathValidationInterval())
+		} else {
+			s.connIDManager.ReleaseReserved()
+		}
+	}
+	s.pathMigration = nil
+	s.maybeRemoveRunner(m.runner)
+	m.result <- err
+}
+
+// addRunner registers the connection with the connRunner of the Transport used by a new path.
+func (s *connection) addRunner(r connRunner) {
+	if slices.Contains(s.runners, r) {
+		return
+	}
+	for _, connID := range s.connIDGenerator.ActiveConnIDs() {
+		r.Add(connID, s)
+	}
+	if s.statelessResetToken != nil {
+		r.AddResetToken(*s.statelessResetToken, s)
+	}
+	s.runners = append(s.runners, r)
+}
+
+// maybeRemoveRunner removes a connRunner added by addRunner, once it isn't used by any path anymore.
+func (s *connection) maybeRemoveRunner(r connRunner) {
+	if r == s.runners[0] || r == s.pathRunner {
+		return
+	}
+	if (s.backupPath != nil && s.backupPath.runner == r) || (s.pathMigration != nil && s.pathMigration.runner == r) {
+		return
+	}
+	i := slices.Index(s.runners, r)
+	if i < 0 {
+		return
+	}
+	for _, connID := range s.connIDGenerator.ActiveConnIDs() {
+		r.Remove(connID)
+	}
+	if s.statelessResetToken != nil {
+		r.RemoveResetToken(*s.statelessResetToken)
+	}
+	s.runners = slices.Delete(s.runners, i, i+1)
+}
+
+// finishPathProbe completes a successful path probe.
+// The connection stays on the current path.
+func (s *connection) finishPathProbe(m *pathMigration, rtt time.Duration) {
+	s.pathMigration = nil
+	s.logger.Debugf("Validated path to %s (RTT: %s)", m.conn.LocalAddr(), rtt)
+	m.rtt = rtt
+	if b := m.backup; b != nil {
+		if s.backupPath == nil {
+			s.lastPathID++
+			b.id = s.lastPathID
+			s.backupPath = b
+		}
+		b.validated = true
+		b.rtt = rtt
+		b.nextValidation = s.clock.Now().Add(s.backupPathValidationInterval())
+	}
+	s.maybeRemoveRunner(m.runner)
+	m.result <- nil
+}
+
+// A backupPath is a validated path that isn't used for sending packets.
+// Only PATH_CHALLENGE frames are sent on it, to keep it validated.
+type backupPath struct {
+	id        PathID
+	transport *Transport
+	runner    connRunner
+	conn      sendConn
+	// validated is set if the last (re)validation of the path succeeded
+	validated      bool
+	rtt            time.Duration // the RTT measured by the last successful validation
+	nextValidation time.Time
+}
+
+// backupPathValidationInterval is the interval at which the backup path is revalidated.
+// This keeps NAT bindings alive, and detects if the path stopped working.
+func (s *connection) backupPathValidationInterval() time.Duration {
+	return min(s.idleTimeout/2, protocol.MaxKeepAliveInterval)
+}
+
+// revalidateBackupPath sends a PATH_CHALLENGE on the backup path.
+// It must be called from the run loop, when no other path validation is in progress.
+func (s *connection) revalidateBackupPath(now time.Time) {
+	b := s.backupPath
+	// The peer might have asked us to retire the connection ID used on the backup path.
+	connID, ok := s.connIDManager.Reserved()
+	if !ok {
+		connID, ok = s.connIDManager.Reserve()
+	}
+	if !ok {
+		s.logger.Debugf("Not revalidating backup path %d: no unused connection ID", b.id)
+		b.nextValidation = now.Add(s.backupPathValidationInterval())
+		return
+	}
+	s.pathMigration = &pathMigration{
+		transport: b.transport,
+		runner:    b.runner,
+		conn:      b.conn,
+		result:    make(chan error, 1),
+		probeOnly: true,
+		backup:    b,
+		connID:    connID,
+		deadline:  now.Add(3 * s.rttStats.PTO(true)),
+	}
+	s.maybeSendPathChallenge(now)
+}
+
+// handlePathRequest handles a request created by RemovePath, SwitchToPath or PathStatus.
+// It must be called from the run loop.
+func (s *connection) handlePathRequest(r *pathRequest) error {
+	b := s.backupPath
+	if b == nil || b.id != r.id {
+		return fmt.Errorf("unknown path: %d", r.id)
+	}
+	switch r.typ {
+	case pathRequestRemove:
+		s.removeBackupPath()
+		s.connIDManager.ReleaseReserved()
+		s.maybeRemoveRunner(b.runner)
+		return b.transport.Close()
+	case pathRequestSwitch:
+		return s.switchToBackupPath()
+	case pathRequestStatus:
+		r.info = PathInfo{Validated: b.validated, RTT: b.rtt}
+	}
+	return nil
+}
+
+// removeBackupPath stops using the backup path, and cancels its revalidation, if it is in progress.
+func (s *connection) removeBackupPath() {
+	if s.pathMigration != nil && s.pathMigration.backup == s.backupPath {
+		s.pathMigration = nil
+	}
+	s.backupPath = nil
+}
+
+// switchToBackupPath migrates the connection to the backup path.
+// The backup path was validated before, so no new path validation is needed.
+func (s *connection) switchToBackupPath() error {
+	b := s.backupPath
+	if !b.validated {
+		return errors.New("backup path failed validation")
+	}
+	if s.pathMigration != nil && s.pathMigration.backup != b {
+		return errors.New("migration already in progress")
+	}
+	// The peer might have asked us to retire the connection ID used on the backup path.
+	if _, ok := s.connIDManager.Reserved(); !ok {
+		return errors.New("peer didn't provide an unused connection ID")
+	}
+	s.removeBackupPath()
+	s.logger.Debugf("Switching connection to backup path %d (%s)", b.id, b.conn.LocalAddr())
+	s.connIDManager.SwitchToReserved()
+	s.usePath(b.conn, b.runner, b.transport)
+	return nil
+}
+
+func (s *connection) switchToPath(m *pathMigration) {
+	s.pathMigration = nil
+	s.logger.Debugf("Migrating connection to %s", m.conn.LocalAddr())
+	if !m.reuseConnID {
+		s.connIDManager.SwitchToNext()
+	}
+	s.usePath(m.conn, m.runner, m.transport)
+	m.result <- nil
+}
+
+// usePath switches the connection to a path that uses the socket of a different Transport.
+func (s *connection) usePath(conn sendConn, runner connRunner, transport *Transport) {
+	s.useConn(conn)
+	// the Transport used by the previous path might not be used anymore
+	oldRunner := s.pathRunner
+	s.pathRunner = runner
+	s.maybeRemoveRunner(oldRunner)
+	// the socket opened for the previous path is not used anymore
+	if s.migratedTransport != nil {
+		s.migratedTransport.Close()
+	}
+	s.migratedTransport = transport
+}
+
+// useConn switches the connection to a new path.
+func (s *connection) useConn(conn sendConn) {
+	// make sure that all packets queued on the old path are sent out
+	s.sendQueue.Close()
+	s.connMutex.Lock()
+	s.conn = conn
+	s.connMutex.Unlock()
+	s.sendQueue = newSendQueue(conn)
+	s.runSendQueue(s.sendQueue)
+	s.sentPacketHandler.MigratedPath()
+	if s.tracer != nil && s.tracer.MigratedConnection != nil {
+		s.tracer.MigratedConnection(conn.LocalAddr(), conn.RemoteAddr())
+	}
+}
+
+func (s *connection) runSendQueue(q sender) {
+	go func() {
+		if err := q.Run(); err != nil {
+			s.destroyImpl(err)
+		}
+	}()
 }
 
 func (s *connection) handleNewTokenFrame(frame *wire.NewTokenFrame) error {
@@ -1484,7 +2854,9 @@ func (s *connection) handleNewTokenFrame(frame *wire.NewTokenFrame) error {
 			ErrorMessage: "received NEW_TOKEN frame from the client",
 		}
 	}
-	if s.config.TokenStore != nil {
+	if s.config.StoreNewToken != nil {
+		s.config.StoreNewToken(frame.Token)
+	} else if s.config.TokenStore != nil {
 		s.config.TokenStore.Put(s.tokenStoreKey, &ClientToken{data: frame.Token})
 	}
 	return nil
@@ -1498,6 +2870,17 @@ func (s *connection) handleRetireConnectionIDFrame(f *wire.RetireConnectionIDFra
 	return s.connIDGenerator.Retire(f.SequenceNumber, destConnID)
 }
 
+func (s *connection) handleAckFrequencyFrame(frame *wire.AckFrequencyFrame) error {
+	if frame.RequestMaxAckDelay < protocol.MinAckDelay {
+		return &qerr.TransportError{
+			ErrorCode:    qerr.ProtocolViolation,
+			ErrorMessage: fmt.Sprintf("requested max ack delay (%s) smaller than min_ack_delay (%s)", frame.RequestMaxAckDelay, protocol.MinAckDelay),
+		}
+	}
+	s.receivedPacketHandler.ReceivedAckFrequencyFrame(frame)
+	return nil
+}
+
 func (s *connection) handleHandshakeDoneFrame() error {
 	if s.perspective == protocol.PerspectiveServer {
 		return &qerr.TransportError{
@@ -1512,7 +2895,7 @@ func (s *connection) handleHandshakeDoneFrame() error {
 }
 
 func (s *connection) handleAckFrame(frame *wire.AckFrame, encLevel protocol.EncryptionLevel) error {
-	acked1RTTPacket, err := s.sentPacketHandler.ReceivedAck(frame, encLevel, s.lastPacketReceivedTime)
+	acked1RTTPacket, err := s.sentPacketHandler.ReceivedAck(frame, encLevel, s.rcvTime)
 	if err != nil {
 		return err
 	}
@@ -1531,13 +2914,16 @@ func (s *connection) handleAckFrame(frame *wire.AckFrame, encLevel protocol.Encr
 }
 
 func (s *connection) handleDatagramFrame(f *wire.DatagramFrame) error {
-	if f.Length(s.version) > wire.MaxDatagramSize {
+	// We advertise wire.MaxDatagramSize as our max_datagram_frame_size.
+	// RFC 9221, section 3: receiving a larger DATAGRAM frame is a protocol violation.
+	// The frame is never truncated.
+	if l := f.Length(s.version); l > wire.MaxDatagramSize {
 		return &qerr.TransportError{
 			ErrorCode:    qerr.ProtocolViolation,
-			ErrorMessage: "DATAGRAM frame too large",
+			ErrorMessage: fmt.Sprintf("DATAGRAM frame too large: %d bytes (max_datagram_frame_size: %d bytes)", l, wire.MaxDatagramSize),
 		}
 	}
-	s.datagramQueue.HandleDatagramFrame(f)
+	s.datagramQueue.HandleDatagramFrame(f, s.rcvTime, s.rcvBuffer)
 	return nil
 }
 
@@ -1586,6 +2972,13 @@ func (s *connection) CloseWithError(code ApplicationErrorCode, desc string) erro
 	return nil
 }
 
+// acceptsGreasedQUICBit says if we allow the peer to grease the QUIC bit (RFC 9287).
+// Packets with an unset QUIC bit are only routed to the connection by their connection ID,
+// so greasing isn't offered when using zero-length connection IDs.
+func (s *connection) acceptsGreasedQUICBit() bool {
+	return s.config.EnableQUICBitGreasing && s.srcConnIDLen > 0
+}
+
 func (s *connection) closeWithTransportError(code TransportErrorCode) {
 	s.closeLocal(&qerr.TransportError{ErrorCode: code})
 	<-s.ctx.Done()
@@ -1611,6 +3004,7 @@ func (s *connection) handleCloseError(closeErr *closeError) {
 	switch {
 	case errors.Is(e, qerr.ErrIdleTimeout),
 		errors.Is(e, qerr.ErrHandshakeTimeout),
+		errors.Is(e, qerr.ErrPTOLimitReached),
 		errors.As(e, &statelessResetErr),
 		errors.As(e, &versionNegotiationErr),
 		errors.As(e, &recreateErr),
@@ -1667,7 +3061,6 @@ func (s *connection) dropEncryptionLevel(encLevel protocol.EncryptionLevel) erro
 		s.droppedInitialKeys = true
 		s.cryptoStreamHandler.DiscardInitialKeys()
 	case protocol.Encryption0RTT:
-		s.streamsMap.ResetFor0RTT()
 		if err := s.connFlowController.Reset(); err != nil {
 			return err
 		}
@@ -1688,9 +3081,36 @@ func (s *connection) restoreTransportParameters(params *wire.TransportParameters
 	s.streamsMap.UpdateLimits(params)
 	s.connStateMutex.Lock()
 	s.connState.SupportsDatagrams = s.supportsDatagrams()
+	s.connState.SupportsResetStreamAt = s.config.EnableResetStreamAt && params.EnableResetStreamAt
 	s.connStateMutex.Unlock()
 }
 
+func toPublicTransportParameters(params *wire.TransportParameters) *TransportParameters {
+	tp := &TransportParameters{
+		MaxIdleTimeout:                 params.MaxIdleTimeout,
+		MaxUDPPayloadSize:              uint64(params.MaxUDPPayloadSize),
+		InitialMaxData:                 uint64(params.InitialMaxData),
+		InitialMaxStreamDataBidiLocal:  uint64(params.InitialMaxStreamDataBidiLocal),
+		InitialMaxStreamDataBidiRemote: uint64(params.InitialMaxStreamDataBidiRemote),
+		InitialMaxStreamDataUni:        uint64(params.InitialMaxStreamDataUni),
+		InitialMaxStreamsBidi:          int64(params.MaxBidiStreamNum),
+		InitialMaxStreamsUni:           int64(params.MaxUniStreamNum),
+		AckDelayExponent:               params.AckDelayExponent,
+		MaxAckDelay:                    params.MaxAckDelay,
+		DisableActiveMigration:         params.DisableActiveMigration,
+		ActiveConnectionIDLimit:        params.ActiveConnectionIDLimit,
+		EnableResetStreamAt:            params.EnableResetStreamAt,
+		GreaseQUICBit:                  params.GreaseQUICBit,
+	}
+	if params.MaxDatagramFrameSize != protocol.InvalidByteCount {
+		tp.MaxDatagramFrameSize = uint64(params.MaxDatagramFrameSize)
+	}
+	if params.MinAckDelay != nil {
+		tp.MinAckDelay = *params.MinAckDelay
+	}
+	return tp
+}
+
 func (s *connection) handleTransportParameters(params *wire.TransportParameters) error {
 	if s.tracer != nil && s.tracer.ReceivedTransportParameters != nil {
 		s.tracer.ReceivedTransportParameters(params)
@@ -1702,6 +3122,14 @@ func (s *connection) handleTransportParameters(params *wire.TransportParameters)
 		}
 	}
 
+	if s.config.RequireDatagrams && params.MaxDatagramFrameSize <= 0 {
+		// The peer's transport parameters are valid, we just refuse to use the connection.
+		return &qerr.TransportError{
+			ErrorCode:    qerr.ConnectionRefused,
+			ErrorMessage: "datagrams required, but not supported by the peer",
+		}
+	}
+
 	if s.perspective == protocol.PerspectiveClient && s.peerParams != nil && s.ConnectionState().Used0RTT && !params.ValidForUpdate(s.peerParams) {
 		return &qerr.TransportError{
 			ErrorCode:    qerr.ProtocolViolation,
@@ -1710,6 +3138,10 @@ func (s *connection) handleTransportParameters(params *wire.TransportParameters)
 	}
 
 	s.peerParams = params
+	s.peerAllowsMigration.Store(!params.DisableActiveMigration)
+	if s.config.EnableExperimentalDatagramFlowControl && params.InitialMaxDatagrams > 0 {
+		s.datagramQueue.EnableFlowControl(params.InitialMaxDatagrams, s.queueControlFrame)
+	}
 	// On the client side we have to wait for handshake completion.
 	// During a 0-RTT connection, we are only allowed to use the new transport parameters for 1-RTT packets.
 	if s.perspective == protocol.PerspectiveServer {
@@ -1721,7 +3153,18 @@ func (s *connection) handleTransportParameters(params *wire.TransportParameters)
 
 	s.connStateMutex.Lock()
 	s.connState.SupportsDatagrams = s.supportsDatagrams()
+	s.connState.SupportsResetStreamAt = s.config.EnableResetStreamAt && params.EnableResetStreamAt
+	s.connState.SupportsAckFrequency = s.config.EnableAckFrequency && params.MinAckDelay != nil
+	greaseQUICBit := s.config.EnableQUICBitGreasing && params.GreaseQUICBit
+	s.connState.SupportsQUICBitGreasing = greaseQUICBit
+	if params.MinAckDelay != nil {
+		s.peerMinAckDelay = *params.MinAckDelay
+	}
+	s.receivedPeerParams = toPublicTransportParameters(params)
 	s.connStateMutex.Unlock()
+	if greaseQUICBit {
+		s.packer.EnableQUICBitGreasing()
+	}
 	return nil
 }
 
@@ -1759,7 +3202,10 @@ func (s *connection) applyTransportParameters() {
 	params := s.peerParams
 	// Our local idle timeout will always be > 0.
 	s.idleTimeout = utils.MinNonZeroDuration(s.config.MaxIdleTimeout, params.MaxIdleTimeout)
-	s.keepAliveInterval = min(s.config.KeepAlivePeriod, min(s.idleTimeout/2, protocol.MaxKeepAliveInterval))
+	s.keepAliveInterval = jitterKeepAliveInterval(
+		min(s.config.KeepAlivePeriod, min(s.idleTimeout/2, protocol.MaxKeepAliveInterval)),
+		s.config.KeepAliveJitter,
+	)
 	s.streamsMap.UpdateLimits(params)
 	s.frameParser.SetAckDelayExponent(params.AckDelayExponent)
 	s.connFlowController.UpdateSendWindow(params.InitialMaxData)
@@ -1768,10 +3214,18 @@ func (s *connection) applyTransportParameters() {
 	if params.StatelessResetToken != nil {
 		s.connIDManager.SetStatelessResetToken(*params.StatelessResetToken)
 	}
-	// We don't support connection migration yet, so we don't have any use for the preferred_address.
 	if params.PreferredAddress != nil {
-		// Retire the connection ID.
+		// The connection ID is used when migrating to the preferred address.
+		// Otherwise, it's used like any other connection ID.
 		s.connIDManager.AddFromPreferredAddress(params.PreferredAddress.ConnectionID, params.PreferredAddress.StatelessResetToken)
+		pa := &PreferredAddress{IPv4: params.PreferredAddress.IPv4, IPv6: params.PreferredAddress.IPv6}
+		if !pa.hasIPv4() {
+			pa.IPv4 = netip.AddrPort{}
+		}
+		if !pa.hasIPv6() {
+			pa.IPv6 = netip.AddrPort{}
+		}
+		s.peerPreferredAddress.Store(pa)
 	}
 	maxPacketSize := protocol.ByteCount(protocol.MaxPacketBufferSize)
 	if params.MaxUDPPayloadSize > 0 && params.MaxUDPPayloadSize < maxPacketSize {
@@ -1793,10 +3247,16 @@ func (s *connection) triggerSending(now time.Time) error {
 	//nolint:exhaustive // No need to handle pacing limited here.
 	switch sendMode {
 	case ackhandler.SendAny:
+		s.flushRequested.Store(false)
 		return s.sendPackets(now)
 	case ackhandler.SendNone:
 		return nil
 	case ackhandler.SendPacingLimited:
+		// Flush only bypasses the pacer, not the congestion controller.
+		// Since the pacer is consulted after every packet, this sends at most a single packet (or GSO batch).
+		if s.flushRequested.CompareAndSwap(true, false) {
+			return s.sendPackets(now)
+		}
 		deadline := s.sentPacketHandler.TimeUntilSend()
 		if deadline.IsZero() {
 			deadline = deadlineSendImmediately
@@ -1849,7 +3309,7 @@ func (s *connection) sendPackets(now time.Time) error {
 	// Performance-wise, this doesn't matter, since we only send a very small (<10) number of
 	// MTU probe packets per connection.
 	if s.handshakeConfirmed && s.mtuDiscoverer != nil && s.mtuDiscoverer.ShouldSendProbe(now) {
-		ping, size := s.mtuDiscoverer.GetPing()
+		ping, size := s.mtuDiscoverer.GetPing(now)
 		p, buf, err := s.packer.PackMTUProbePacket(ping, size, s.version)
 		if err != nil {
 			return err
@@ -1857,6 +3317,7 @@ func (s *connection) sendPackets(now time.Time) error {
 		ecn := s.sentPacketHandler.ECNMode(true)
 		s.logShortHeaderPacket(p.DestConnID, p.Ack, p.Frames, p.StreamFrames, p.PacketNumber, p.PacketNumberLen, p.KeyPhase, ecn, buf.Len(), false)
 		s.registerPackedShortHeaderPacket(p, ecn, now)
+		s.traceSentUDPDatagram(buf.Len(), 1)
 		s.sendQueue.Send(buf, 0, ecn)
 		// This is kind of a hack. We need to trigger sending again somehow.
 		s.pacingDeadline = deadlineSendImmediately
@@ -1865,6 +3326,9 @@ func (s *connection) sendPackets(now time.Time) error {
 
 	if isBlocked, offset := s.connFlowController.IsNewlyBlocked(); isBlocked {
 		s.framer.QueueControlFrame(&wire.DataBlockedFrame{MaximumData: offset})
+		if s.config.OnFlowControlBlocked != nil {
+			s.config.OnFlowControlBlocked(nil)
+		}
 	}
 	s.windowUpdateQueue.QueueAll()
 	if cf := s.cryptoStreamManager.GetPostHandshakeData(protocol.MaxPostHandshakeCryptoFrameSize); cf != nil {
@@ -1889,7 +3353,7 @@ func (s *connection) sendPackets(now time.Time) error {
 		return nil
 	}
 
-	if s.conn.capabilities().GSO {
+	if !s.config.DisableGSO && s.conn.capabilities().GSO {
 		return s.sendPacketsWithGSO(now)
 	}
 	return s.sendPacketsWithoutGSO(now)
@@ -1907,6 +3371,7 @@ func (s *connection) sendPacketsWithoutGSO(now time.Time) error {
 			return err
 		}
 
+		s.traceSentUDPDatagram(buf.Len(), 1)
 		s.sendQueue.Send(buf, 0, ecn)
 
 		if s.sendQueue.WouldBlock() {
@@ -1969,6 +3434,7 @@ func (s *connection) sendPacketsWithGSO(now time.Time) error {
 			continue
 		}
 
+		s.traceSentGSODatagrams(buf.Len(), maxSize)
 		s.sendQueue.Send(buf, uint16(maxSize), ecn)
 
 		if dontSendMore {
@@ -2019,6 +3485,7 @@ func (s *connection) maybeSendAckOnlyPacket(now time.Time) error {
 	}
 	s.logShortHeaderPacket(p.DestConnID, p.Ack, p.Frames, p.StreamFrames, p.PacketNumber, p.PacketNumberLen, p.KeyPhase, ecn, buf.Len(), false)
 	s.registerPackedShortHeaderPacket(p, ecn, now)
+	s.traceSentUDPDatagram(buf.Len(), 1)
 	s.sendQueue.Send(buf, 0, ecn)
 	return nil
 }
@@ -2069,7 +3536,7 @@ func (s *connection) appendOneShortHeaderPacket(buf *packetBuffer, maxSize proto
 }
 
 func (s *connection) registerPackedShortHeaderPacket(p shortHeaderPacket, ecn protocol.ECN, now time.Time) {
-	if s.firstAckElicitingPacketAfterIdleSentTime.IsZero() && (len(p.StreamFrames) > 0 || ackhandler.HasAckElicitingFrames(p.Frames)) {
+	if s.firstAckElicitingPacketAfterIdleSentTime.IsZero() && s.restartsIdleTimer(p.StreamFrames, p.Frames) {
 		s.firstAckElicitingPacketAfterIdleSentTime = now
 	}
 
@@ -2078,9 +3545,34 @@ func (s *connection) registerPackedShortHeaderPacket(p shortHeaderPacket, ecn pr
 		largestAcked = p.Ack.LargestAcked()
 	}
 	s.sentPacketHandler.SentPacket(now, p.PacketNumber, largestAcked, p.StreamFrames, p.Frames, protocol.Encryption1RTT, ecn, p.Length, p.IsPathMTUProbePacket)
+	s.reportPacketSent(protocol.Encryption1RTT, p.PacketNumber, p.Length, now)
 	s.connIDManager.SentPacket()
 }
 
+func (s *connection) reportPacketSent(encLevel protocol.EncryptionLevel, pn protocol.PacketNumber, size protocol.ByteCount, now time.Time) {
+	if s.config.OnPacketSent != nil {
+		s.config.OnPacketSent(encLevel, pn, size, now)
+	}
+}
+
+// restartsIdleTimer says if sending a 1-RTT packet containing these frames restarts the idle timer.
+// If Config.IdleTimeoutIgnoresDatagrams is set, DATAGRAM frames are not taken into account.
+func (s *connection) restartsIdleTimer(streamFrames []ackhandler.StreamFrame, frames []ackhandler.Frame) bool {
+	if len(streamFrames) > 0 {
+		return true
+	}
+	for _, f := range frames {
+		if !ackhandler.IsFrameAckEliciting(f.Frame) {
+			continue
+		}
+		if _, ok := f.Frame.(*wire.DatagramFrame); ok && s.config.IdleTimeoutIgnoresDatagrams {
+			continue
+		}
+		return true
+	}
+	return false
+}
+
 func (s *connection) sendPackedCoalescedPacket(packet *coalescedPacket, ecn protocol.ECN, now time.Time) error {
 	s.logCoalescedPacket(packet, ecn)
 	for _, p := range packet.longHdrPackets {
@@ -2092,6 +3584,7 @@ func (s *connection) sendPackedCoalescedPacket(packet *coalescedPacket, ecn prot
 			largestAcked = p.ack.LargestAcked()
 		}
 		s.sentPacketHandler.SentPacket(now, p.header.PacketNumber, largestAcked, p.streamFrames, p.frames, p.EncryptionLevel(), ecn, p.length, false)
+		s.reportPacketSent(p.EncryptionLevel(), p.header.PacketNumber, p.length, now)
 		if s.perspective == protocol.PerspectiveClient && p.EncryptionLevel() == protocol.EncryptionHandshake &&
 			!s.droppedInitialKeys {
 			// On the client side, Initial keys are dropped as soon as the first Handshake packet is sent.
@@ -2102,7 +3595,7 @@ func (s *connection) sendPackedCoalescedPacket(packet *coalescedPacket, ecn prot
 		}
 	}
 	if p := packet.shortHdrPacket; p != nil {
-		if s.firstAckElicitingPacketAfterIdleSentTime.IsZero() && p.IsAckEliciting() {
+		if s.firstAckElicitingPacketAfterIdleSentTime.IsZero() && s.restartsIdleTimer(p.StreamFrames, p.Frames) {
 			s.firstAckElicitingPacketAfterIdleSentTime = now
 		}
 		largestAcked := protocol.InvalidPacketNumber
@@ -2110,6 +3603,7 @@ func (s *connection) sendPackedCoalescedPacket(packet *coalescedPacket, ecn prot
 			largestAcked = p.Ack.LargestAcked()
 		}
 		s.sentPacketHandler.SentPacket(now, p.PacketNumber, largestAcked, p.StreamFrames, p.Frames, protocol.Encryption1RTT, ecn, p.Length, p.IsPathMTUProbePacket)
+		s.reportPacketSent(protocol.Encryption1RTT, p.PacketNumber, p.Length, now)
 	}
 	s.connIDManager.SentPacket()
 	s.sendQueue.Send(packet.buffer, 0, ecn)
@@ -2263,6 +3757,7 @@ func (s *connection) logCoalescedPacket(packet *coalescedPacket, ecn protocol.EC
 				packet.shortHdrPacket.Length,
 				false,
 			)
+			s.traceSentUDPDatagram(packet.buffer.Len(), 1)
 			return
 		}
 		if len(packet.longHdrPackets) > 1 {
@@ -2277,6 +3772,34 @@ func (s *connection) logCoalescedPacket(packet *coalescedPacket, ecn protocol.EC
 	if p := packet.shortHdrPacket; p != nil {
 		s.logShortHeaderPacket(p.DestConnID, p.Ack, p.Frames, p.StreamFrames, p.PacketNumber, p.PacketNumberLen, p.KeyPhase, ecn, p.Length, true)
 	}
+	numPackets := len(packet.longHdrPackets)
+	if packet.shortHdrPacket != nil {
+		numPackets++
+	}
+	s.traceSentUDPDatagram(packet.buffer.Len(), numPackets)
+}
+
+// traceSentUDPDatagram counts a UDP datagram containing numPackets QUIC packets,
+// and reports it to the tracer.
+func (s *connection) traceSentUDPDatagram(size protocol.ByteCount, numPackets int) {
+	s.bytesSent.Add(uint64(size))
+	if s.tracer != nil && s.tracer.SentUDPDatagram != nil {
+		s.tracer.SentUDPDatagram(size, numPackets)
+	}
+}
+
+// traceSentGSODatagrams reports the segments of a GSO batch to the tracer.
+// Every segment contains a single QUIC packet, and all but the last segment are gsoSize bytes long.
+func (s *connection) traceSentGSODatagrams(size, gsoSize protocol.ByteCount) {
+	s.bytesSent.Add(uint64(size))
+	if s.tracer == nil || s.tracer.SentUDPDatagram == nil {
+		return
+	}
+	for size > 0 {
+		segmentSize := min(size, gsoSize)
+		s.tracer.SentUDPDatagram(segmentSize, 1)
+		size -= segmentSize
+	}
 }
 
 // AcceptStream returns the next stream openend by the peer
@@ -2305,6 +3828,57 @@ func (s *connection) OpenUniStreamSync(ctx context.Context) (SendStream, error)
 	return s.streamsMap.OpenUniStreamSync(ctx)
 }
 
+func (s *connection) SendMessage(ctx context.Context, data []byte) error {
+	str, err := s.OpenUniStreamSync(ctx)
+	if err != nil {
+		return err
+	}
+	stop := context.AfterFunc(ctx, func() { str.CancelWrite(0) })
+	_, err = str.Write(data)
+	if err == nil {
+		err = str.Close()
+	}
+	if !stop() {
+		return ctx.Err()
+	}
+	if err != nil {
+		str.CancelWrite(0)
+		return err
+	}
+	return nil
+}
+
+func (s *connection) AcceptMessage(ctx context.Context) ([]byte, error) {
+	str, err := s.AcceptUniStream(ctx)
+	if err != nil {
+		return nil, err
+	}
+	stop := context.AfterFunc(ctx, func() { str.CancelRead(0) })
+	maxSize := s.config.MaxMessageSize
+	// read one byte more than allowed, so we can detect messages that are too large
+	data, err := io.ReadAll(io.LimitReader(str, int64(maxSize)+1))
+	if !stop() {
+		return nil, ctx.Err()
+	}
+	if err != nil {
+		str.CancelRead(0)
+		return nil, err
+	}
+	if uint64(len(data)) > maxSize {
+		str.CancelRead(0)
+		return nil, &MessageTooLargeError{MaxMessageSize: int64(maxSize)}
+	}
+	return data, nil
+}
+
+func (s *connection) StreamsAvailable() (bidi, uni int64) {
+	return s.streamsMap.StreamsAvailable()
+}
+
+func (s *connection) ActiveStreams() []protocol.StreamID {
+	return s.streamsMap.ActiveStreams()
+}
+
 func (s *connection) newFlowController(id protocol.StreamID) flowcontrol.StreamFlowController {
 	initialSendWindow := s.peerParams.InitialMaxStreamDataUni
 	if id.Type() == protocol.StreamTypeBidi {
@@ -2322,6 +3896,7 @@ func (s *connection) newFlowController(id protocol.StreamID) flowcontrol.StreamF
 		initialSendWindow,
 		s.onHasStreamWindowUpdate,
 		s.rttStats,
+		s.clock,
 		s.logger,
 	)
 }
@@ -2340,7 +3915,7 @@ func (s *connection) tryQueueingUndecryptablePacket(p receivedPacket, pt logging
 	if s.handshakeComplete {
 		panic("shouldn't queue undecryptable packets after handshake completion")
 	}
-	if len(s.undecryptablePackets)+1 > protocol.MaxUndecryptablePackets {
+	if len(s.undecryptablePackets)+1 > s.config.MaxUndecryptablePackets {
 		if s.tracer != nil && s.tracer.DroppedPacket != nil {
 			s.tracer.DroppedPacket(pt, protocol.InvalidPacketNumber, p.Size(), logging.PacketDropDOSPrevention)
 		}
@@ -2355,10 +3930,26 @@ func (s *connection) tryQueueingUndecryptablePacket(p receivedPacket, pt logging
 }
 
 func (s *connection) queueControlFrame(f wire.Frame) {
+	// Streams queue a STREAM_DATA_BLOCKED frame when they're blocked by flow control.
+	// This happens when packing a packet, i.e. on the run loop.
+	if f, ok := f.(*wire.StreamDataBlockedFrame); ok && s.config.OnFlowControlBlocked != nil {
+		s.flowControlBlockedStreams = append(s.flowControlBlockedStreams, f.StreamID)
+	}
 	s.framer.QueueControlFrame(f)
 	s.scheduleSending()
 }
 
+// reportFlowControlBlocked calls OnFlowControlBlocked for the streams that were blocked by flow control.
+// The STREAM_DATA_BLOCKED frames are queued while the framer and the stream are locked,
+// so the callback is only called once the packets were sent.
+func (s *connection) reportFlowControlBlocked() {
+	for _, id := range s.flowControlBlockedStreams {
+		id := id
+		s.config.OnFlowControlBlocked(&id)
+	}
+	s.flowControlBlockedStreams = s.flowControlBlockedStreams[:0]
+}
+
 func (s *connection) onHasStreamWindowUpdate(id protocol.StreamID) {
 	s.windowUpdateQueue.AddStream(id)
 	s.scheduleSending()
@@ -2374,14 +3965,26 @@ func (s *connection) onHasStreamData(id protocol.StreamID) {
 	s.scheduleSending()
 }
 
+func (s *connection) onStreamPriorityChanged(id protocol.StreamID, p StreamPriority) {
+	s.framer.SetStreamPriority(id, p)
+}
+
+func (s *connection) supportsResetStreamAt() bool {
+	s.connStateMutex.Lock()
+	defer s.connStateMutex.Unlock()
+	return s.connState.SupportsResetStreamAt
+}
+
 func (s *connection) onStreamCompleted(id protocol.StreamID) {
 	if err := s.streamsMap.DeleteStream(id); err != nil {
 		s.closeLocal(err)
 	}
+	s.framer.RemoveStream(id)
 }
 
 func (s *connection) onMTUIncreased(mtu protocol.ByteCount) {
 	s.maxPayloadSizeEstimate.Store(uint32(estimateMaxPayloadSize(mtu)))
+	s.mtu.Store(uint32(mtu))
 	s.sentPacketHandler.SetMaxDatagramSize(mtu)
 }
 
@@ -2391,18 +3994,50 @@ func (s *connection) SendDatagram(p []byte) error {
 	}
 
 	f := &wire.DatagramFrame{DataLenPresent: true}
+	if err := s.checkDatagramSize(f, len(p)); err != nil {
+		return err
+	}
+	f.Data = make([]byte, len(p))
+	copy(f.Data, p)
+	return s.datagramQueue.Add(f, nil)
+}
+
+// checkDatagramSize returns a DatagramTooLargeError if a DATAGRAM frame with a payload of n bytes doesn't fit into a packet.
+func (s *connection) checkDatagramSize(f *wire.DatagramFrame, n int) error {
+	if maxDataLen := s.maxDatagramPayloadSize(f); protocol.ByteCount(n) > maxDataLen {
+		return &DatagramTooLargeError{MaxDatagramPayloadSize: int64(maxDataLen)}
+	}
+	return nil
+}
+
+func (s *connection) SetDatagramSendTimeout(d time.Duration) {
+	s.datagramQueue.SetSendTimeout(d)
+}
+
+// maxDatagramPayloadSize returns the maximum payload size of the DATAGRAM frame.
+// It must only be called if the peer supports datagrams.
+func (s *connection) maxDatagramPayloadSize(f *wire.DatagramFrame) protocol.ByteCount {
 	// The payload size estimate is conservative.
 	// Under many circumstances we could send a few more bytes.
-	maxDataLen := min(
+	return min(
 		f.MaxDataLen(s.peerParams.MaxDatagramFrameSize, s.version),
 		protocol.ByteCount(s.maxPayloadSizeEstimate.Load()),
 	)
-	if protocol.ByteCount(len(p)) > maxDataLen {
-		return &DatagramTooLargeError{MaxDatagramPayloadSize: int64(maxDataLen)}
+}
+
+func (s *connection) MaxDatagramSize() int {
+	select {
+	case <-s.ctx.Done():
+		return 0
+	default:
 	}
-	f.Data = make([]byte, len(p))
-	copy(f.Data, p)
-	return s.datagramQueue.Add(f)
+	s.connStateMutex.Lock()
+	supportsDatagrams := s.connState.SupportsDatagrams
+	s.connStateMutex.Unlock()
+	if !supportsDatagrams {
+		return 0
+	}
+	return int(s.maxDatagramPayloadSize(&wire.DatagramFrame{DataLenPresent: true}))
 }
 
 func (s *connection) ReceiveDatagram(ctx context.Context) ([]byte, error) {
@@ -2412,11 +4047,269 @@ func (s *connection) ReceiveDatagram(ctx context.Context) ([]byte, error) {
 	return s.datagramQueue.Receive(ctx)
 }
 
+func (s *connection) TryReceiveDatagram() ([]byte, bool) {
+	if !s.config.EnableDatagrams {
+		return nil, false
+	}
+	return s.datagramQueue.TryReceive()
+}
+
+func (s *connection) ReceiveDatagramBuffer(ctx context.Context) (*DatagramBuffer, error) {
+	if !s.config.EnableDatagrams {
+		return nil, errors.New("datagram support disabled")
+	}
+	return s.datagramQueue.ReceiveBuffer(ctx)
+}
+
+func (s *connection) ReceiveDatagramWithTime(ctx context.Context) ([]byte, time.Time, error) {
+	if !s.config.EnableDatagrams {
+		return nil, time.Time{}, errors.New("datagram support disabled")
+	}
+	return s.datagramQueue.ReceiveWithTime(ctx)
+}
+
+func (s *connection) SendLargeDatagram(data []byte) error {
+	if !s.supportsDatagrams() {
+		return errors.New("datagram support disabled")
+	}
+	if s.ctx.Err() != nil {
+		return context.Cause(s.ctx)
+	}
+	id := s.nextLargeDatagramID.Add(1) - 1
+	// Use the same fragment size for all fragments, even if the header of earlier fragments is shorter.
+	hdrLen := datagramFragmentHeaderLen(id, maxLargeDatagramFragments-1, maxLargeDatagramFragments)
+	fragmentSize := s.MaxDatagramSize() - hdrLen
+	if fragmentSize <= 0 {
+		return &DatagramTooLargeError{MaxDatagramPayloadSize: 0}
+	}
+	count := max(1, (len(data)+fragmentSize-1)/fragmentSize)
+	if count > maxLargeDatagramFragments {
+		return &DatagramTooLargeError{MaxDatagramPayloadSize: int64(maxLargeDatagramFragments * fragmentSize)}
+	}
+	// All fragments are sized using the same maximum datagram size, and built before the first one is queued.
+	// This way, the message is never cut short because the maximum datagram size changes while it is being queued.
+	frames := make([]*wire.DatagramFrame, 0, count)
+	for i := 0; i < count; i++ {
+		b := make([]byte, 0, hdrLen+fragmentSize)
+		b = appendDatagramFragmentHeader(b, id, i, count)
+		b = append(b, data[i*fragmentSize:min(len(data), (i+1)*fragmentSize)]...)
+		frames = append(frames, &wire.DatagramFrame{DataLenPresent: true, Data: b})
+	}
+	// Apply the same checks as SendDatagram to every fragment, before queueing the first one.
+	for _, f := range frames {
+		if err := s.checkDatagramSize(f, len(f.Data)); err != nil {
+			return err
+		}
+	}
+	for _, f := range frames {
+		if err := s.datagramQueue.Add(f, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *connection) ReceiveLargeDatagram(ctx context.Context) ([]byte, error) {
+	if !s.config.EnableDatagrams {
+		return nil, errors.New("datagram support disabled")
+	}
+	for {
+		b, err := s.datagramQueue.Receive(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if msg := s.datagramReassembler.Add(b, s.clock.Now()); msg != nil {
+			return msg, nil
+		}
+	}
+}
+
+func (s *connection) SendKeepAlive() error {
+	if s.ctx.Err() != nil {
+		return context.Cause(s.ctx)
+	}
+	// Multiple calls before the run loop picks up the request result in a single PING frame.
+	s.keepAliveRequested.Store(true)
+	s.scheduleSending()
+	return nil
+}
+
+func (s *connection) Ping(ctx context.Context) (time.Duration, error) {
+	if s.ctx.Err() != nil {
+		return 0, context.Cause(s.ctx)
+	}
+	h := &pingAckHandler{conn: s, done: make(chan time.Duration, 1), returned: make(chan struct{})}
+	defer close(h.returned)
+	h.queuePing()
+	s.connStateMutex.Lock()
+	supportsAckFrequency := s.connState.SupportsAckFrequency
+	s.connStateMutex.Unlock()
+	if supportsAckFrequency {
+		s.immediateAckRequested.Store(true)
+	}
+	s.scheduleSending()
+	select {
+	case rtt := <-h.done:
+		return rtt, nil
+	case <-ctx.Done():
+		return 0, ctx.Err()
+	case <-s.ctx.Done():
+		return 0, context.Cause(s.ctx)
+	}
+}
+
+// pingAckHandler measures the time until a PING frame sent by Ping is acknowledged.
+type pingAckHandler struct {
+	conn     *connection
+	start    time.Time
+	done     chan time.Duration
+	returned chan struct{} // closed when Ping returns
+}
+
+var _ ackhandler.FrameHandler = &pingAckHandler{}
+
+func (h *pingAckHandler) queuePing() {
+	h.start = h.conn.clock.Now()
+	h.conn.framer.QueueControlFrameWithHandler(&wire.PingFrame{}, h)
+}
+
+func (h *pingAckHandler) OnAcked(wire.Frame) {
+	select {
+	case h.done <- h.conn.clock.Now().Sub(h.start):
+	default:
+	}
+}
+
+func (h *pingAckHandler) OnLost(wire.Frame) {
+	// Nobody is waiting for the acknowledgment anymore, e.g. because the context passed to Ping was canceled.
+	select {
+	case <-h.returned:
+		return
+	default:
+	}
+	h.queuePing()
+}
+
+func (s *connection) Flush() error {
+	if s.ctx.Err() != nil {
+		return context.Cause(s.ctx)
+	}
+	s.flushRequested.Store(true)
+	s.scheduleSending()
+	return nil
+}
+
+func (s *connection) RequestImmediateAck() error {
+	if s.ctx.Err() != nil {
+		return context.Cause(s.ctx)
+	}
+	s.connStateMutex.Lock()
+	supported := s.connState.SupportsAckFrequency
+	s.connStateMutex.Unlock()
+	if !supported {
+		return errors.New("ACK Frequency extension not negotiated")
+	}
+	// Multiple calls before the run loop picks up the request result in a single IMMEDIATE_ACK frame.
+	s.immediateAckRequested.Store(true)
+	s.scheduleSending()
+	return nil
+}
+
+func (s *connection) RequestAckFrequency(ackElicitingThreshold uint64, maxAckDelay time.Duration) error {
+	if s.ctx.Err() != nil {
+		return context.Cause(s.ctx)
+	}
+	s.connStateMutex.Lock()
+	supported := s.connState.SupportsAckFrequency
+	minAckDelay := s.peerMinAckDelay
+	s.connStateMutex.Unlock()
+	if !supported {
+		return errors.New("ACK Frequency extension not negotiated")
+	}
+	if maxAckDelay < minAckDelay {
+		return fmt.Errorf("max ack delay (%s) smaller than the peer's min_ack_delay (%s)", maxAckDelay, minAckDelay)
+	}
+	maxAckDelay = min(maxAckDelay, protocol.MaxMaxAckDelay)
+	s.ackFrequencyMutex.Lock()
+	// Only the most recent request is sent, if the run loop didn't pick up the previous one yet.
+	s.ackFrequencyRequested = &wire.AckFrequencyFrame{
+		AckElicitingThreshold: ackElicitingThreshold,
+		RequestMaxAckDelay:    maxAckDelay,
+		ReorderingThreshold:   1,
+	}
+	s.ackFrequencyMutex.Unlock()
+	s.scheduleSending()
+	return nil
+}
+
+func (s *connection) SendCustomFrame(frameType uint64, payload []byte) error {
+	if s.ctx.Err() != nil {
+		return context.Cause(s.ctx)
+	}
+	if _, ok := s.config.CustomFrameHandlers[frameType]; !ok {
+		return fmt.Errorf("custom frame type %#x not registered", frameType)
+	}
+	f := &wire.CustomFrame{Type: frameType, Data: make([]byte, len(payload))}
+	copy(f.Data, payload)
+	// The frame needs to fit into a packet of the smallest size we might send.
+	if maxLen := estimateMaxPayloadSize(protocol.ByteCount(s.config.InitialPacketSize)); f.Length(s.version) > maxLen {
+		return fmt.Errorf("custom frame too large: %d bytes (maximum: %d bytes)", f.Length(s.version), maxLen)
+	}
+	s.framer.QueueControlFrame(f)
+	s.scheduleSending()
+	return nil
+}
+
+func (s *connection) InitiateKeyUpdate() error {
+	if s.ctx.Err() != nil {
+		return context.Cause(s.ctx)
+	}
+	if err := s.cryptoStreamHandler.InitiateKeyUpdate(); err != nil {
+		return err
+	}
+	s.keepAliveRequested.Store(true)
+	s.scheduleSending()
+	return nil
+}
+
+func (s *connection) KeyPhase() (phase uint8, updates uint64) {
+	keyPhase := s.cryptoStreamHandler.CurrentKeyPhase()
+	return uint8(keyPhase % 2), uint64(keyPhase)
+}
+
+// maybeQueueAckFrequencyFrame queues the ACK_FREQUENCY frame requested using RequestAckFrequency.
+// It must only be called from the run loop.
+func (s *connection) maybeQueueAckFrequencyFrame() {
+	s.ackFrequencyMutex.Lock()
+	f := s.ackFrequencyRequested
+	s.ackFrequencyRequested = nil
+	s.ackFrequencyMutex.Unlock()
+	if f == nil {
+		return
+	}
+	f.SequenceNumber = s.nextAckFrequencySeq
+	s.nextAckFrequencySeq++
+	// The peer might delay acknowledgments by up to the requested max ack delay,
+	// which needs to be taken into account when calculating the PTO.
+	if f.RequestMaxAckDelay > s.rttStats.MaxAckDelay() {
+		s.rttStats.SetMaxAckDelay(f.RequestMaxAckDelay)
+	}
+	s.framer.QueueControlFrame(f)
+}
+
+func (s *connection) CurrentMTU() logging.ByteCount {
+	return logging.ByteCount(s.mtu.Load())
+}
+
 func (s *connection) LocalAddr() net.Addr {
+	s.connMutex.Lock()
+	defer s.connMutex.Unlock()
 	return s.conn.LocalAddr()
 }
 
 func (s *connection) RemoteAddr() net.Addr {
+	s.connMutex.Lock()
+	defer s.connMutex.Unlock()
 	return s.conn.RemoteAddr()
 }
 
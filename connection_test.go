@@ -12,6 +12,7 @@ import (
 	"net/netip"
 	"runtime/pprof"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/quic-go/quic-go/internal/ackhandler"
@@ -24,6 +25,7 @@ import (
 	"github.com/quic-go/quic-go/internal/utils"
 	"github.com/quic-go/quic-go/internal/wire"
 	"github.com/quic-go/quic-go/logging"
+	"github.com/quic-go/quic-go/quicvarint"
 	"github.com/quic-go/quic-go/testutils"
 
 	. "github.com/onsi/ginkgo/v2"
@@ -37,6 +39,75 @@ func areConnsRunning() bool {
 	return strings.Contains(b.String(), "quic-go.(*connection).run")
 }
 
+// fakeClock is a clock that only advances when told to.
+// Its timers fire when the clock is advanced past their deadline.
+type fakeClock struct {
+	mutex  sync.Mutex
+	now    time.Time
+	timers []*fakeClockTimer
+}
+
+var _ utils.Clock = &fakeClock{}
+
+func (c *fakeClock) Now() time.Time {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) NewTimer(d time.Duration) utils.ClockTimer {
+	t := &fakeClockTimer{clock: c, c: make(chan time.Time, 1)}
+	t.Reset(d)
+	c.mutex.Lock()
+	c.timers = append(c.timers, t)
+	c.mutex.Unlock()
+	return t
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.now = c.now.Add(d)
+	for _, t := range c.timers {
+		t.maybeFire(c.now)
+	}
+}
+
+type fakeClockTimer struct {
+	clock    *fakeClock
+	c        chan time.Time
+	active   bool
+	deadline time.Time
+}
+
+func (t *fakeClockTimer) Chan() <-chan time.Time { return t.c }
+
+// maybeFire must be called with the clock's mutex held.
+func (t *fakeClockTimer) maybeFire(now time.Time) {
+	if t.active && !now.Before(t.deadline) {
+		t.active = false
+		t.c <- now
+	}
+}
+
+func (t *fakeClockTimer) Stop() bool {
+	t.clock.mutex.Lock()
+	defer t.clock.mutex.Unlock()
+	wasActive := t.active
+	t.active = false
+	return wasActive
+}
+
+func (t *fakeClockTimer) Reset(d time.Duration) bool {
+	t.clock.mutex.Lock()
+	defer t.clock.mutex.Unlock()
+	wasActive := t.active
+	t.active = true
+	t.deadline = t.clock.now.Add(d)
+	t.maybeFire(t.clock.now)
+	return wasActive
+}
+
 var _ = Describe("Connection", func() {
 	var (
 		conn          *connection
@@ -115,6 +186,7 @@ var _ = Describe("Connection", func() {
 		tracer.EXPECT().SentTransportParameters(gomock.Any())
 		tracer.EXPECT().UpdatedKeyFromTLS(gomock.Any(), gomock.Any()).AnyTimes()
 		tracer.EXPECT().UpdatedCongestionState(gomock.Any())
+		tracer.EXPECT().SentUDPDatagram(gomock.Any(), gomock.Any()).AnyTimes()
 		ctx, cancel := context.WithCancelCause(context.Background())
 		conn = newConnection(
 			ctx,
@@ -232,6 +304,29 @@ var _ = Describe("Connection", func() {
 			})
 		})
 
+		Context("handling RESET_STREAM_AT frames", func() {
+			It("passes the frame to the stream", func() {
+				f := &wire.ResetStreamAtFrame{
+					StreamID:     555,
+					ErrorCode:    42,
+					FinalSize:    0x1337,
+					ReliableSize: 0x42,
+				}
+				str := NewMockReceiveStreamI(mockCtrl)
+				streamManager.EXPECT().GetOrOpenReceiveStream(protocol.StreamID(555)).Return(str, nil)
+				str.EXPECT().handleResetStreamAtFrame(f)
+				Expect(conn.handleFrame(f, protocol.Encryption1RTT, protocol.ConnectionID{})).To(Succeed())
+			})
+
+			It("ignores RESET_STREAM_AT frames for closed streams", func() {
+				streamManager.EXPECT().GetOrOpenReceiveStream(protocol.StreamID(3)).Return(nil, nil)
+				Expect(conn.handleFrame(&wire.ResetStreamAtFrame{
+					StreamID:  3,
+					ErrorCode: 42,
+				}, protocol.Encryption1RTT, protocol.ConnectionID{})).To(Succeed())
+			})
+		})
+
 		Context("handling MAX_DATA and MAX_STREAM_DATA frames", func() {
 			var connFC *mocks.MockConnectionFlowController
 
@@ -348,6 +443,28 @@ var _ = Describe("Connection", func() {
 			Expect(err).NotTo(HaveOccurred())
 		})
 
+		It("handles ACK_FREQUENCY frames", func() {
+			rph := mockackhandler.NewMockReceivedPacketHandler(mockCtrl)
+			conn.receivedPacketHandler = rph
+			f := &wire.AckFrequencyFrame{SequenceNumber: 1, AckElicitingThreshold: 10, RequestMaxAckDelay: 50 * time.Millisecond}
+			rph.EXPECT().ReceivedAckFrequencyFrame(f)
+			Expect(conn.handleFrame(f, protocol.Encryption1RTT, protocol.ConnectionID{})).To(Succeed())
+		})
+
+		It("rejects ACK_FREQUENCY frames requesting a max ack delay smaller than the min_ack_delay", func() {
+			err := conn.handleFrame(&wire.AckFrequencyFrame{RequestMaxAckDelay: protocol.MinAckDelay - 1}, protocol.Encryption1RTT, protocol.ConnectionID{})
+			Expect(err).To(HaveOccurred())
+			Expect(err).To(BeAssignableToTypeOf(&qerr.TransportError{}))
+			Expect(err.(*qerr.TransportError).ErrorCode).To(Equal(qerr.ProtocolViolation))
+		})
+
+		It("handles IMMEDIATE_ACK frames", func() {
+			rph := mockackhandler.NewMockReceivedPacketHandler(mockCtrl)
+			conn.receivedPacketHandler = rph
+			rph.EXPECT().ReceivedImmediateAck()
+			Expect(conn.handleFrame(&wire.ImmediateAckFrame{}, protocol.Encryption1RTT, protocol.ConnectionID{})).To(Succeed())
+		})
+
 		It("handles CONNECTION_CLOSE frames, with a transport error code", func() {
 			expectedErr := &qerr.TransportError{
 				Remote:       true,
@@ -584,7 +701,7 @@ var _ = Describe("Connection", func() {
 			// don't EXPECT any calls to packer.PackPacket()
 			conn.handlePacket(receivedPacket{
 				rcvTime:    time.Now(),
-				remoteAddr: &net.UDPAddr{},
+				remoteAddr: remoteAddr,
 				buffer:     getPacketBuffer(),
 				data:       b,
 			})
@@ -736,6 +853,259 @@ var _ = Describe("Connection", func() {
 			Expect(conn.handlePacketImpl(p)).To(BeFalse())
 		})
 
+		It("drops short header packets with an unset QUIC bit, if QUIC bit greasing is disabled", func() {
+			p := getShortHeaderPacket(srcConnID, 0x42, nil)
+			p.data[0] &^= 0x40
+			tracer.EXPECT().DroppedPacket(logging.PacketType1RTT, protocol.InvalidPacketNumber, p.Size(), logging.PacketDropHeaderParseError)
+			Expect(conn.handlePacketImpl(p)).To(BeFalse())
+		})
+
+		It("accepts short header packets with an unset QUIC bit, if QUIC bit greasing is enabled", func() {
+			conn.config.EnableQUICBitGreasing = true
+			p := getShortHeaderPacket(srcConnID, 0x42, nil)
+			p.data[0] &^= 0x40
+			unpacker.EXPECT().UnpackShortHeader(gomock.Any(), gomock.Any()).Return(protocol.PacketNumber(0x42), protocol.PacketNumberLen2, protocol.KeyPhaseZero, []byte{0} /* PADDING */, nil)
+			tracer.EXPECT().ReceivedShortHeaderPacket(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any())
+			Expect(conn.handlePacketImpl(p)).To(BeTrue())
+		})
+
+		It("doesn't allow the peer to grease the QUIC bit when using zero-length connection IDs", func() {
+			conn.config.EnableQUICBitGreasing = true
+			Expect(conn.acceptsGreasedQUICBit()).To(BeTrue())
+			// packets with an unset QUIC bit can't be routed to the connection
+			conn.srcConnIDLen = 0
+			Expect(conn.acceptsGreasedQUICBit()).To(BeFalse())
+		})
+
+		Context("spin bit", func() {
+			receivePacket := func(pn protocol.PacketNumber, spin bool) {
+				p := getShortHeaderPacket(srcConnID, pn, nil)
+				if spin {
+					p.data[0] |= 0x20
+				}
+				unpacker.EXPECT().UnpackShortHeader(gomock.Any(), gomock.Any()).Return(pn, protocol.PacketNumberLen2, protocol.KeyPhaseZero, []byte{0} /* PADDING */, nil)
+				tracer.EXPECT().ReceivedShortHeaderPacket(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any())
+				Expect(conn.handlePacketImpl(p)).To(BeTrue())
+			}
+
+			It("doesn't spin if the spin bit is disabled", func() {
+				receivePacket(1, true)
+				receivePacket(2, false)
+				Expect(conn.SpinBit()).To(BeZero())
+			})
+
+			It("reflects the spin bit, as a server", func() {
+				conn.config.EnableSpinBit = true
+				receivePacket(1, false)
+				Expect(conn.SpinBit()).To(BeZero())
+				packer.EXPECT().SetSpinBit(true)
+				receivePacket(2, true)
+				Expect(conn.SpinBit()).To(BeEquivalentTo(1))
+				// reordered packets don't change the spin bit
+				receivePacket(0, false)
+				Expect(conn.SpinBit()).To(BeEquivalentTo(1))
+				receivePacket(3, true)
+				Expect(conn.SpinBit()).To(BeEquivalentTo(1))
+				packer.EXPECT().SetSpinBit(false)
+				receivePacket(4, false)
+				Expect(conn.SpinBit()).To(BeZero())
+			})
+
+			It("inverts the spin bit, as a client", func() {
+				conn.config.EnableSpinBit = true
+				conn.perspective = protocol.PerspectiveClient
+				packer.EXPECT().SetSpinBit(true)
+				receivePacket(1, false)
+				Expect(conn.SpinBit()).To(BeEquivalentTo(1))
+				// reordered packets don't change the spin bit
+				receivePacket(0, true)
+				Expect(conn.SpinBit()).To(BeEquivalentTo(1))
+				packer.EXPECT().SetSpinBit(false)
+				receivePacket(2, true)
+				Expect(conn.SpinBit()).To(BeZero())
+				packer.EXPECT().SetSpinBit(true)
+				receivePacket(3, false)
+				Expect(conn.SpinBit()).To(BeEquivalentTo(1))
+			})
+		})
+
+		Context("updating the remote address", func() {
+			newRemoteAddr := &net.UDPAddr{IP: net.IPv4(192, 168, 0, 100), Port: 4242}
+			var (
+				pathConn *MockSendConn
+				sender   *MockSender
+			)
+
+			BeforeEach(func() {
+				conn.handshakeConfirmed = true
+				pathConn = NewMockSendConn(mockCtrl)
+				pathConn.EXPECT().capabilities().AnyTimes()
+				pathConn.EXPECT().LocalAddr().Return(localAddr).AnyTimes()
+				pathConn.EXPECT().RemoteAddr().Return(newRemoteAddr).AnyTimes()
+				sender = NewMockSender(mockCtrl)
+				conn.sendQueue = sender
+			})
+
+			receivePacketOnPath := func(pn protocol.PacketNumber, addr net.Addr, info packetInfo, frame wire.Frame) protocol.ByteCount {
+				p := getShortHeaderPacket(srcConnID, pn, nil)
+				p.remoteAddr = addr
+				p.info = info
+				data, err := frame.Append(nil, conn.version)
+				Expect(err).ToNot(HaveOccurred())
+				unpacker.EXPECT().UnpackShortHeader(gomock.Any(), gomock.Any()).Return(pn, protocol.PacketNumberLen2, protocol.KeyPhaseZero, data, nil)
+				tracer.EXPECT().ReceivedShortHeaderPacket(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any())
+				Expect(conn.handlePacketImpl(p)).To(BeTrue())
+				return p.Size()
+			}
+
+			receivePacket := func(pn protocol.PacketNumber, addr net.Addr, frame wire.Frame) protocol.ByteCount {
+				return receivePacketOnPath(pn, addr, packetInfo{}, frame)
+			}
+
+			// expectPathChallenge expects a PATH_CHALLENGE of the maximum allowed size to be sent to the new address
+			expectPathChallenge := func(maxSize protocol.ByteCount) *[8]byte {
+				var data [8]byte
+				packer.EXPECT().PackPathProbePacket(gomock.Any(), gomock.Any(), maxSize, conn.version).DoAndReturn(
+					func(connID protocol.ConnectionID, f ackhandler.Frame, size protocol.ByteCount, _ protocol.Version) (shortHeaderPacket, *packetBuffer, error) {
+						Expect(f.Frame).To(BeAssignableToTypeOf(&wire.PathChallengeFrame{}))
+						data = f.Frame.(*wire.PathChallengeFrame).Data
+						buf := getPacketBuffer()
+						buf.Data = append(buf.Data, make([]byte, size)...)
+						return shortHeaderPacket{PacketNumber: 5, Frames: []ackhandler.Frame{f}, Length: size, DestConnID: connID}, buf, nil
+					},
+				)
+				tracer.EXPECT().SentShortHeaderPacket(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any())
+				tracer.EXPECT().UpdatedMetrics(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+				pathConn.EXPECT().Write(make([]byte, maxSize), uint16(0), protocol.ECNUnsupported)
+				return &data
+			}
+
+			It("validates the client's new address before switching to it", func() {
+				receivePacket(10, remoteAddr, &wire.PingFrame{})
+				mconn.EXPECT().withRemoteAddr(newRemoteAddr, gomock.Any()).Return(pathConn)
+				// the PATH_CHALLENGE is limited by the anti-amplification limit
+				p := getShortHeaderPacket(srcConnID, 11, nil)
+				data := expectPathChallenge(3 * p.Size())
+				receivePacket(11, newRemoteAddr, &wire.PingFrame{})
+				Expect(conn.RemoteAddr()).To(Equal(remoteAddr))
+
+				sender.EXPECT().Close()
+				tracer.EXPECT().MigratedConnection(localAddr, newRemoteAddr)
+				receivePacket(12, newRemoteAddr, &wire.PathResponseFrame{Data: *data})
+				Expect(conn.RemoteAddr()).To(Equal(newRemoteAddr))
+				Expect(conn.pathMigration).To(BeNil())
+				conn.sendQueue.Close()
+			})
+
+			It("doesn't switch to a spoofed address that never responds", func() {
+				mconn.EXPECT().withRemoteAddr(newRemoteAddr, gomock.Any()).Return(pathConn)
+				p := getShortHeaderPacket(srcConnID, 11, nil)
+				expectPathChallenge(3 * p.Size())
+				receivePacket(11, newRemoteAddr, &wire.PingFrame{})
+				m := conn.pathMigration
+				Expect(m).ToNot(BeNil())
+				// the anti-amplification limit was used up by the first PATH_CHALLENGE
+				packer.EXPECT().PackPathProbePacket(gomock.Any(), gomock.Any(), protocol.ByteCount(0), conn.version).Return(shortHeaderPacket{}, nil, errNothingToPack)
+				conn.maybeSendPathChallenge(m.nextProbe)
+				Expect(m.challenges).To(HaveLen(1))
+				// path validation times out, and the connection stays on the current path
+				conn.maybeSendPathChallenge(m.deadline)
+				Expect(m.result).To(Receive(MatchError("path validation timed out")))
+				Expect(conn.pathMigration).To(BeNil())
+				Expect(conn.RemoteAddr()).To(Equal(remoteAddr))
+			})
+
+			It("stops validating the new address if the client keeps using the current address", func() {
+				mconn.EXPECT().withRemoteAddr(newRemoteAddr, gomock.Any()).Return(pathConn)
+				p := getShortHeaderPacket(srcConnID, 11, nil)
+				expectPathChallenge(3 * p.Size())
+				receivePacket(11, newRemoteAddr, &wire.PingFrame{})
+				m := conn.pathMigration
+				Expect(m).ToNot(BeNil())
+				receivePacket(12, remoteAddr, &wire.PingFrame{})
+				Expect(m.result).To(Receive(MatchError("peer continued using the current address")))
+				Expect(conn.pathMigration).To(BeNil())
+				Expect(conn.RemoteAddr()).To(Equal(remoteAddr))
+			})
+
+			It("doesn't switch for reordered packets", func() {
+				receivePacket(10, remoteAddr, &wire.PingFrame{})
+				receivePacket(9, newRemoteAddr, &wire.PingFrame{})
+				Expect(conn.RemoteAddr()).To(Equal(remoteAddr))
+			})
+
+			It("doesn't switch for probing packets, and responds on the path the PATH_CHALLENGE was received on", func() {
+				data := [8]byte{1, 2, 3, 4, 5, 6, 7, 8}
+				mconn.EXPECT().withRemoteAddr(newRemoteAddr, gomock.Any()).Return(pathConn)
+				packer.EXPECT().PackPathProbePacket(gomock.Any(), ackhandler.Frame{Frame: &wire.PathResponseFrame{Data: data}}, protocol.ByteCount(protocol.MinInitialPacketSize), conn.version).DoAndReturn(
+					func(connID protocol.ConnectionID, f ackhandler.Frame, _ protocol.ByteCount, _ protocol.Version) (shortHeaderPacket, *packetBuffer, error) {
+						buf := getPacketBuffer()
+						buf.Data = append(buf.Data, "response"...)
+						return shortHeaderPacket{PacketNumber: 5, Frames: []ackhandler.Frame{f}, Length: 8, DestConnID: connID}, buf, nil
+					},
+				)
+				tracer.EXPECT().SentShortHeaderPacket(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any())
+				tracer.EXPECT().UpdatedMetrics(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+				pathConn.EXPECT().Write([]byte("response"), uint16(0), protocol.ECNUnsupported)
+				receivePacket(10, newRemoteAddr, &wire.PathChallengeFrame{Data: data})
+				Expect(conn.RemoteAddr()).To(Equal(remoteAddr))
+				// the PATH_RESPONSE isn't sent on the current path
+				frames, _ := conn.framer.AppendControlFrames(nil, 1000, protocol.Version1)
+				Expect(frames).To(BeEmpty())
+			})
+
+			It("doesn't switch if active migration is disabled", func() {
+				conn.config.DisableActiveMigration = true
+				Expect(conn.MigrationAllowed()).To(BeFalse())
+				receivePacket(10, newRemoteAddr, &wire.PingFrame{})
+				Expect(conn.RemoteAddr()).To(Equal(remoteAddr))
+			})
+
+			It("switches to the preferred address if active migration is disabled", func() {
+				conn.config.DisableActiveMigration = true
+				conn.config.PreferredAddress = &PreferredAddress{IPv4: netip.MustParseAddrPort("127.0.0.2:7331")}
+				info := packetInfo{addr: netip.MustParseAddr("127.0.0.2")}
+				preferredAddr := &net.UDPAddr{IP: net.IPv4(127, 0, 0, 2), Port: 7331}
+				pathConn = NewMockSendConn(mockCtrl)
+				pathConn.EXPECT().capabilities().AnyTimes()
+				pathConn.EXPECT().LocalAddr().Return(preferredAddr).AnyTimes()
+				pathConn.EXPECT().RemoteAddr().Return(remoteAddr).AnyTimes()
+				receivePacket(10, remoteAddr, &wire.PingFrame{})
+				// the PATH_CHALLENGE is sent from the preferred address
+				mconn.EXPECT().withRemoteAddr(remoteAddr, info).Return(pathConn)
+				p := getShortHeaderPacket(srcConnID, 11, nil)
+				data := expectPathChallenge(3 * p.Size())
+				receivePacketOnPath(11, remoteAddr, info, &wire.PingFrame{})
+				Expect(conn.pathMigration).ToNot(BeNil())
+
+				sender.EXPECT().Close()
+				tracer.EXPECT().MigratedConnection(preferredAddr, remoteAddr)
+				receivePacketOnPath(12, remoteAddr, info, &wire.PathResponseFrame{Data: *data})
+				Expect(conn.pathMigration).To(BeNil())
+				Expect(conn.LocalAddr()).To(Equal(preferredAddr))
+				conn.sendQueue.Close()
+			})
+
+			It("responds to PATH_CHALLENGEs on the local address they were received on", func() {
+				conn.config.PreferredAddress = &PreferredAddress{IPv4: netip.MustParseAddrPort("127.0.0.2:7331")}
+				info := packetInfo{addr: netip.MustParseAddr("127.0.0.2")}
+				data := [8]byte{1, 2, 3, 4, 5, 6, 7, 8}
+				mconn.EXPECT().withRemoteAddr(remoteAddr, info).Return(pathConn)
+				packer.EXPECT().PackPathProbePacket(gomock.Any(), ackhandler.Frame{Frame: &wire.PathResponseFrame{Data: data}}, protocol.ByteCount(protocol.MinInitialPacketSize), conn.version).DoAndReturn(
+					func(connID protocol.ConnectionID, f ackhandler.Frame, _ protocol.ByteCount, _ protocol.Version) (shortHeaderPacket, *packetBuffer, error) {
+						buf := getPacketBuffer()
+						buf.Data = append(buf.Data, "response"...)
+						return shortHeaderPacket{PacketNumber: 5, Frames: []ackhandler.Frame{f}, Length: 8, DestConnID: connID}, buf, nil
+					},
+				)
+				tracer.EXPECT().SentShortHeaderPacket(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any())
+				tracer.EXPECT().UpdatedMetrics(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+				pathConn.EXPECT().Write([]byte("response"), uint16(0), protocol.ECNUnsupported)
+				receivePacketOnPath(10, remoteAddr, info, &wire.PathChallengeFrame{Data: data})
+				Expect(conn.conn).To(Equal(mconn))
+			})
+		})
+
 		It("informs the ReceivedPacketHandler about non-ack-eliciting packets", func() {
 			hdr := &wire.ExtendedHeader{
 				Header: wire.Header{
@@ -792,6 +1162,69 @@ var _ = Describe("Connection", func() {
 			Expect(conn.handlePacketImpl(packet)).To(BeTrue())
 		})
 
+		It("counts the ECN markings of received packets", func() {
+			rph := mockackhandler.NewMockReceivedPacketHandler(mockCtrl)
+			rph.EXPECT().IsPotentiallyDuplicate(gomock.Any(), gomock.Any()).AnyTimes()
+			conn.receivedPacketHandler = rph
+			tracer.EXPECT().ReceivedShortHeaderPacket(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+			for i, ecn := range []protocol.ECN{protocol.ECT0, protocol.ECT1, protocol.ECT1, protocol.ECNCE, protocol.ECNNon} {
+				pn := protocol.PacketNumber(i)
+				packet := getShortHeaderPacket(srcConnID, pn, nil)
+				packet.ecn = ecn
+				unpacker.EXPECT().UnpackShortHeader(gomock.Any(), gomock.Any()).Return(pn, protocol.PacketNumberLen2, protocol.KeyPhaseZero, []byte{0} /* PADDING */, nil)
+				rph.EXPECT().ReceivedPacket(pn, ecn, protocol.Encryption1RTT, gomock.Any(), false)
+				Expect(conn.handlePacketImpl(packet)).To(BeTrue())
+			}
+			stats := conn.ECNStats()
+			Expect(stats.ReceivedECT0).To(BeEquivalentTo(1))
+			Expect(stats.ReceivedECT1).To(BeEquivalentTo(2))
+			Expect(stats.ReceivedCE).To(BeEquivalentTo(1))
+		})
+
+		It("doesn't report ECN markings to the peer if ECN is disabled", func() {
+			conn.config.DisableECN = true
+			rph := mockackhandler.NewMockReceivedPacketHandler(mockCtrl)
+			rph.EXPECT().IsPotentiallyDuplicate(gomock.Any(), gomock.Any())
+			rph.EXPECT().ReceivedPacket(protocol.PacketNumber(0x1337), protocol.ECNNon, protocol.Encryption1RTT, gomock.Any(), false)
+			conn.receivedPacketHandler = rph
+			packet := getShortHeaderPacket(srcConnID, 0x37, nil)
+			packet.ecn = protocol.ECNCE
+			unpacker.EXPECT().UnpackShortHeader(gomock.Any(), gomock.Any()).Return(protocol.PacketNumber(0x1337), protocol.PacketNumberLen2, protocol.KeyPhaseZero, []byte{0} /* PADDING */, nil)
+			tracer.EXPECT().ReceivedShortHeaderPacket(gomock.Any(), gomock.Any(), logging.ECNCE, gomock.Any())
+			Expect(conn.handlePacketImpl(packet)).To(BeTrue())
+			// the marking is still counted, which helps diagnosing networks that mismark packets
+			Expect(conn.ECNStats().ReceivedCE).To(BeEquivalentTo(1))
+		})
+
+		It("calls OnPacketReceived", func() {
+			type receivedPacket struct {
+				encLevel protocol.EncryptionLevel
+				pn       protocol.PacketNumber
+				size     protocol.ByteCount
+				rcvTime  time.Time
+			}
+			var received []receivedPacket
+			conn.config.OnPacketReceived = func(encLevel logging.EncryptionLevel, pn logging.PacketNumber, size logging.ByteCount, rcvTime time.Time) {
+				received = append(received, receivedPacket{encLevel: encLevel, pn: pn, size: size, rcvTime: rcvTime})
+			}
+			rcvTime := time.Now().Add(-10 * time.Second)
+			packet := getShortHeaderPacket(srcConnID, 0x37, nil)
+			packet.rcvTime = rcvTime
+			unpacker.EXPECT().UnpackShortHeader(rcvTime, gomock.Any()).Return(protocol.PacketNumber(0x1337), protocol.PacketNumberLen2, protocol.KeyPhaseZero, []byte{0} /* PADDING */, nil)
+			tracer.EXPECT().ReceivedShortHeaderPacket(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any())
+			Expect(conn.handlePacketImpl(packet)).To(BeTrue())
+			Expect(received).To(Equal([]receivedPacket{
+				{encLevel: protocol.Encryption1RTT, pn: 0x1337, size: packet.Size(), rcvTime: rcvTime},
+			}))
+
+			// duplicate packets are not reported
+			packet = getShortHeaderPacket(srcConnID, 0x37, nil)
+			unpacker.EXPECT().UnpackShortHeader(gomock.Any(), gomock.Any()).Return(protocol.PacketNumber(0x1337), protocol.PacketNumberLen2, protocol.KeyPhaseZero, []byte{0}, nil)
+			tracer.EXPECT().DroppedPacket(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any())
+			Expect(conn.handlePacketImpl(packet)).To(BeFalse())
+			Expect(received).To(HaveLen(1))
+		})
+
 		It("drops duplicate packets", func() {
 			packet := getShortHeaderPacket(srcConnID, 0x37, nil)
 			unpacker.EXPECT().UnpackShortHeader(gomock.Any(), gomock.Any()).Return(protocol.PacketNumber(0x1337), protocol.PacketNumberLen2, protocol.KeyPhaseOne, []byte("foobar"), nil)
@@ -1039,14 +1472,27 @@ var _ = Describe("Connection", func() {
 			Expect(conn.undecryptablePackets).To(Equal([]receivedPacket{packet}))
 		})
 
-		Context("updating the remote address", func() {
-			It("doesn't support connection migration", func() {
-				unpacker.EXPECT().UnpackShortHeader(gomock.Any(), gomock.Any()).Return(protocol.PacketNumber(10), protocol.PacketNumberLen2, protocol.KeyPhaseZero, []byte{0} /* one PADDING frame */, nil)
-				packet := getShortHeaderPacket(srcConnID, 0x42, nil)
-				packet.remoteAddr = &net.IPAddr{IP: net.IPv4(192, 168, 0, 100)}
-				tracer.EXPECT().ReceivedShortHeaderPacket(gomock.Any(), protocol.ByteCount(len(packet.data)), gomock.Any(), gomock.Any())
-				Expect(conn.handlePacketImpl(packet)).To(BeTrue())
-			})
+		It("drops undecryptable packets when the queue is full", func() {
+			conn.handshakeComplete = false
+			conn.config.MaxUndecryptablePackets = 5
+			hdr := &wire.ExtendedHeader{
+				Header: wire.Header{
+					Type:             protocol.PacketTypeHandshake,
+					DestConnectionID: destConnID,
+					SrcConnectionID:  srcConnID,
+					Length:           1,
+					Version:          conn.version,
+				},
+				PacketNumberLen: protocol.PacketNumberLen1,
+			}
+			unpacker.EXPECT().UnpackLongHeader(gomock.Any(), gomock.Any(), gomock.Any(), conn.version).Return(nil, handshake.ErrKeysNotYetAvailable).Times(50)
+			tracer.EXPECT().BufferedPacket(logging.PacketTypeHandshake, gomock.Any()).Times(5)
+			tracer.EXPECT().DroppedPacket(logging.PacketTypeHandshake, protocol.InvalidPacketNumber, gomock.Any(), logging.PacketDropDOSPrevention).Times(45)
+			for i := 0; i < 50; i++ {
+				hdr.PacketNumber = protocol.PacketNumber(i)
+				Expect(conn.handlePacketImpl(getLongHeaderPacket(hdr, nil))).To(BeFalse())
+			}
+			Expect(conn.undecryptablePackets).To(HaveLen(5))
 		})
 
 		Context("coalesced packets", func() {
@@ -1152,6 +1598,54 @@ var _ = Describe("Connection", func() {
 				Expect(conn.undecryptablePackets[0].data).To(HaveLen(hdrLen1 + 456 - 3))
 			})
 
+			It("closes with the APPLICATION_ERROR error code received during the handshake", func() {
+				_, packet := getPacketWithLength(srcConnID, 456)
+				ccf, err := (&wire.ConnectionCloseFrame{ErrorCode: uint64(qerr.ApplicationErrorErrorCode)}).Append(nil, conn.version)
+				Expect(err).ToNot(HaveOccurred())
+				unpacker.EXPECT().UnpackLongHeader(gomock.Any(), gomock.Any(), gomock.Any(), conn.version).Return(&unpackedPacket{
+					encryptionLevel: protocol.EncryptionHandshake,
+					data:            ccf,
+					hdr:             &wire.ExtendedHeader{Header: wire.Header{}},
+				}, nil)
+				tracer.EXPECT().DroppedEncryptionLevel(protocol.EncryptionInitial).AnyTimes()
+				cryptoSetup.EXPECT().DiscardInitialKeys().AnyTimes()
+				tracer.EXPECT().ReceivedLongHeaderPacket(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any())
+				Expect(conn.handlePacketImpl(packet)).To(BeTrue())
+				var closeErr closeError
+				Expect(conn.closeChan).To(Receive(&closeErr))
+				Expect(closeErr.err).To(Equal(&qerr.TransportError{Remote: true, ErrorCode: qerr.ApplicationErrorErrorCode}))
+			})
+
+			It("prefers the application error sent in a coalesced 1-RTT packet", func() {
+				_, packet1 := getPacketWithLength(srcConnID, 456)
+				ccf1, err := (&wire.ConnectionCloseFrame{ErrorCode: uint64(qerr.ApplicationErrorErrorCode)}).Append(nil, conn.version)
+				Expect(err).ToNot(HaveOccurred())
+				unpacker.EXPECT().UnpackLongHeader(gomock.Any(), gomock.Any(), gomock.Any(), conn.version).Return(&unpackedPacket{
+					encryptionLevel: protocol.EncryptionHandshake,
+					data:            ccf1,
+					hdr:             &wire.ExtendedHeader{Header: wire.Header{}},
+				}, nil)
+				packet2 := getShortHeaderPacket(srcConnID, 0x42, nil)
+				ccf2, err := (&wire.ConnectionCloseFrame{
+					IsApplicationError: true,
+					ErrorCode:          1337,
+					ReasonPhrase:       "rejected",
+				}).Append(nil, conn.version)
+				Expect(err).ToNot(HaveOccurred())
+				unpacker.EXPECT().UnpackShortHeader(gomock.Any(), gomock.Any()).Return(protocol.PacketNumber(0x42), protocol.PacketNumberLen2, protocol.KeyPhaseZero, ccf2, nil)
+				tracer.EXPECT().DroppedEncryptionLevel(protocol.EncryptionInitial).AnyTimes()
+				cryptoSetup.EXPECT().DiscardInitialKeys().AnyTimes()
+				gomock.InOrder(
+					tracer.EXPECT().ReceivedLongHeaderPacket(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()),
+					tracer.EXPECT().ReceivedShortHeaderPacket(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()),
+				)
+				packet1.data = append(packet1.data, packet2.data...)
+				Expect(conn.handlePacketImpl(packet1)).To(BeTrue())
+				var closeErr closeError
+				Expect(conn.closeChan).To(Receive(&closeErr))
+				Expect(closeErr.err).To(Equal(&qerr.ApplicationError{Remote: true, ErrorCode: 1337, ErrorMessage: "rejected"}))
+			})
+
 			It("ignores coalesced packet parts if the destination connection IDs don't match", func() {
 				wrongConnID := protocol.ParseConnectionID([]byte{0xde, 0xad, 0xbe, 0xef})
 				Expect(srcConnID).ToNot(Equal(wrongConnID))
@@ -1249,6 +1743,33 @@ var _ = Describe("Connection", func() {
 			Eventually(sent).Should(BeClosed())
 		})
 
+		It("calls OnPacketSent", func() {
+			type sentPacket struct {
+				encLevel protocol.EncryptionLevel
+				pn       protocol.PacketNumber
+				size     protocol.ByteCount
+			}
+			sentPackets := make(chan sentPacket, 1)
+			conn.config.OnPacketSent = func(encLevel logging.EncryptionLevel, pn logging.PacketNumber, size logging.ByteCount, sentTime time.Time) {
+				Expect(sentTime).To(BeTemporally("~", time.Now(), scaleDuration(50*time.Millisecond)))
+				sentPackets <- sentPacket{encLevel: encLevel, pn: pn, size: size}
+			}
+			conn.handshakeConfirmed = true
+			sph.EXPECT().TimeUntilSend().AnyTimes()
+			sph.EXPECT().GetLossDetectionTimeout().AnyTimes()
+			sph.EXPECT().SendMode(gomock.Any()).Return(ackhandler.SendAny).AnyTimes()
+			sph.EXPECT().ECNMode(true).Return(protocol.ECNNon).AnyTimes()
+			sph.EXPECT().SentPacket(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any())
+			runConn()
+			expectAppendPacket(packer, shortHeaderPacket{PacketNumber: 1337, Length: 6}, []byte("foobar"))
+			packer.EXPECT().AppendPacket(gomock.Any(), gomock.Any(), conn.version).Return(shortHeaderPacket{}, errNothingToPack).AnyTimes()
+			sender.EXPECT().WouldBlock().AnyTimes()
+			sender.EXPECT().Send(gomock.Any(), gomock.Any(), gomock.Any())
+			tracer.EXPECT().SentShortHeaderPacket(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any())
+			conn.scheduleSending()
+			Eventually(sentPackets).Should(Receive(Equal(sentPacket{encLevel: protocol.Encryption1RTT, pn: 1337, size: 6})))
+		})
+
 		It("doesn't send packets if there's nothing to send", func() {
 			conn.handshakeConfirmed = true
 			sph.EXPECT().GetLossDetectionTimeout().AnyTimes()
@@ -1287,6 +1808,8 @@ var _ = Describe("Connection", func() {
 			expectAppendPacket(packer, shortHeaderPacket{PacketNumber: 13}, []byte("foobar"))
 			packer.EXPECT().AppendPacket(gomock.Any(), gomock.Any(), conn.version).Return(shortHeaderPacket{}, errNothingToPack).AnyTimes()
 			conn.connFlowController = fc
+			blocked := make(chan *protocol.StreamID, 1)
+			conn.config.OnFlowControlBlocked = func(id *protocol.StreamID) { blocked <- id }
 			runConn()
 			sent := make(chan struct{})
 			sender.EXPECT().Send(gomock.Any(), gomock.Any(), gomock.Any()).Do(func(*packetBuffer, uint16, protocol.ECN) { close(sent) })
@@ -1295,6 +1818,7 @@ var _ = Describe("Connection", func() {
 			Eventually(sent).Should(BeClosed())
 			frames, _ := conn.framer.AppendControlFrames(nil, 1000, protocol.Version1)
 			Expect(frames).To(Equal([]ackhandler.Frame{{Frame: &logging.DataBlockedFrame{MaximumData: 1337}}}))
+			Expect(blocked).To(Receive(BeNil()))
 		})
 
 		It("doesn't send when the SentPacketHandler doesn't allow it", func() {
@@ -1436,6 +1960,33 @@ var _ = Describe("Connection", func() {
 			time.Sleep(50 * time.Millisecond) // make sure that only 2 packets are sent
 		})
 
+		It("sends multiple packets one by one immediately, if GSO is disabled", func() {
+			enableGSO()
+			conn.config.DisableGSO = true
+			sph.EXPECT().SentPacket(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(2)
+			sph.EXPECT().SendMode(gomock.Any()).Return(ackhandler.SendAny).Times(2)
+			sph.EXPECT().ECNMode(gomock.Any()).Times(2)
+			sph.EXPECT().SendMode(gomock.Any()).Return(ackhandler.SendPacingLimited)
+			sph.EXPECT().TimeUntilSend().Return(time.Now().Add(time.Hour))
+			expectAppendPacket(packer, shortHeaderPacket{PacketNumber: 10}, []byte("packet10"))
+			expectAppendPacket(packer, shortHeaderPacket{PacketNumber: 11}, []byte("packet11"))
+			sender.EXPECT().WouldBlock().AnyTimes()
+			sender.EXPECT().Send(gomock.Any(), uint16(0), gomock.Any()).Do(func(b *packetBuffer, _ uint16, _ protocol.ECN) {
+				Expect(b.Data).To(Equal([]byte("packet10")))
+			})
+			sender.EXPECT().Send(gomock.Any(), uint16(0), gomock.Any()).Do(func(b *packetBuffer, _ uint16, _ protocol.ECN) {
+				Expect(b.Data).To(Equal([]byte("packet11")))
+			})
+			go func() {
+				defer GinkgoRecover()
+				cryptoSetup.EXPECT().StartHandshake(gomock.Any()).MaxTimes(1)
+				cryptoSetup.EXPECT().NextEvent().Return(handshake.Event{Kind: handshake.EventNoEvent})
+				conn.run()
+			}()
+			conn.scheduleSending()
+			time.Sleep(50 * time.Millisecond) // make sure that only 2 packets are sent
+		})
+
 		It("sends multiple packets one by one immediately, with GSO", func() {
 			enableGSO()
 			sph.EXPECT().SentPacket(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(2)
@@ -1452,6 +2003,11 @@ var _ = Describe("Connection", func() {
 			sender.EXPECT().Send(gomock.Any(), uint16(conn.maxPacketSize()), gomock.Any()).Do(func(b *packetBuffer, _ uint16, _ protocol.ECN) {
 				Expect(b.Data).To(Equal(append(payload1, payload2...)))
 			})
+			// every GSO segment is reported as a separate UDP datagram
+			datagrams := make(chan [2]int, 10)
+			conn.tracer.SentUDPDatagram = func(size logging.ByteCount, numPackets int) {
+				datagrams <- [2]int{int(size), numPackets}
+			}
 			go func() {
 				defer GinkgoRecover()
 				cryptoSetup.EXPECT().StartHandshake(gomock.Any()).MaxTimes(1)
@@ -1460,6 +2016,9 @@ var _ = Describe("Connection", func() {
 			}()
 			conn.scheduleSending()
 			time.Sleep(50 * time.Millisecond) // make sure that only 2 packets are sent
+			Expect(datagrams).To(HaveLen(2))
+			Expect(<-datagrams).To(Equal([2]int{int(conn.maxPacketSize()), 1}))
+			Expect(<-datagrams).To(Equal([2]int{int(conn.maxPacketSize()), 1}))
 		})
 
 		It("stops appending packets when a smaller packet is packed, with GSO", func() {
@@ -1615,6 +2174,44 @@ var _ = Describe("Connection", func() {
 			Eventually(written, 2*pacingDelay).Should(HaveLen(2))
 		})
 
+		It("bypasses the pacer when flushing", func() {
+			gomock.InOrder(
+				sph.EXPECT().SendMode(gomock.Any()).Return(ackhandler.SendPacingLimited),
+				sph.EXPECT().ECNMode(gomock.Any()),
+				expectAppendPacket(packer, shortHeaderPacket{PacketNumber: 100}, []byte("packet100")),
+				sph.EXPECT().SentPacket(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()),
+				sph.EXPECT().SendMode(gomock.Any()).Return(ackhandler.SendPacingLimited),
+				sph.EXPECT().TimeUntilSend().Return(time.Now().Add(time.Hour)),
+			)
+			written := make(chan struct{}, 2)
+			sender.EXPECT().WouldBlock().AnyTimes()
+			sender.EXPECT().Send(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(*packetBuffer, uint16, protocol.ECN) { written <- struct{}{} })
+			go func() {
+				defer GinkgoRecover()
+				cryptoSetup.EXPECT().StartHandshake(gomock.Any()).MaxTimes(1)
+				cryptoSetup.EXPECT().NextEvent().Return(handshake.Event{Kind: handshake.EventNoEvent})
+				conn.run()
+			}()
+			Expect(conn.Flush()).To(Succeed())
+			Eventually(written).Should(HaveLen(1))
+			Consistently(written, scaleDuration(50*time.Millisecond)).Should(HaveLen(1))
+			Expect(conn.flushRequested.Load()).To(BeFalse())
+		})
+
+		It("doesn't bypass the congestion controller when flushing", func() {
+			sph.EXPECT().SendMode(gomock.Any()).Return(ackhandler.SendNone)
+			sender.EXPECT().WouldBlock().AnyTimes()
+			go func() {
+				defer GinkgoRecover()
+				cryptoSetup.EXPECT().StartHandshake(gomock.Any()).MaxTimes(1)
+				cryptoSetup.EXPECT().NextEvent().Return(handshake.Event{Kind: handshake.EventNoEvent})
+				conn.run()
+			}()
+			Expect(conn.Flush()).To(Succeed())
+			time.Sleep(50 * time.Millisecond) // make sure that no packet is sent
+			Expect(conn.flushRequested.Load()).To(BeTrue())
+		})
+
 		It("sends multiple packets at once", func() {
 			sph.EXPECT().SentPacket(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(3)
 			sph.EXPECT().SendMode(gomock.Any()).Return(ackhandler.SendAny).Times(3)
@@ -1761,7 +2358,7 @@ var _ = Describe("Connection", func() {
 			sender.EXPECT().Send(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(*packetBuffer, uint16, protocol.ECN) { written <- struct{}{} })
 			mtuDiscoverer.EXPECT().ShouldSendProbe(gomock.Any()).Return(true)
 			ping := ackhandler.Frame{Frame: &wire.PingFrame{}}
-			mtuDiscoverer.EXPECT().GetPing().Return(ping, protocol.ByteCount(1234))
+			mtuDiscoverer.EXPECT().GetPing(gomock.Any()).Return(ping, protocol.ByteCount(1234))
 			packer.EXPECT().PackMTUProbePacket(ping, protocol.ByteCount(1234), conn.version).Return(shortHeaderPacket{PacketNumber: 1}, getPacketBuffer(), nil)
 			go func() {
 				defer GinkgoRecover()
@@ -1773,6 +2370,38 @@ var _ = Describe("Connection", func() {
 			Eventually(written).Should(Receive())
 			mtuDiscoverer.EXPECT().CurrentSize().Return(protocol.ByteCount(1234))
 		})
+
+		It("doesn't send Path MTU probe packets when Path MTU discovery is disabled", func() {
+			capabilities = connCapabilities{DF: true}
+			conn.config.DisablePathMTUDiscovery = true
+			conn.mtuDiscoverer = newMTUDiscoverer(conn.rttStats, protocol.InitialPacketSize, protocol.MaxPacketBufferSize, conn.onMTUIncreased, nil)
+			sph.EXPECT().DropPackets(protocol.EncryptionHandshake)
+			sph.EXPECT().SetHandshakeConfirmed()
+			cryptoSetup.EXPECT().SetHandshakeConfirmed()
+			tracer.EXPECT().DroppedEncryptionLevel(protocol.EncryptionHandshake)
+			Expect(conn.handleHandshakeConfirmed()).To(Succeed())
+
+			sph.EXPECT().SendMode(gomock.Any()).Return(ackhandler.SendAny).AnyTimes()
+			sph.EXPECT().ECNMode(gomock.Any()).AnyTimes()
+			sender.EXPECT().WouldBlock().AnyTimes()
+			// don't EXPECT any calls to PackMTUProbePacket
+			sent := make(chan struct{}, 10)
+			packer.EXPECT().AppendPacket(gomock.Any(), gomock.Any(), conn.version).DoAndReturn(func(*packetBuffer, protocol.ByteCount, protocol.Version) (shortHeaderPacket, error) {
+				sent <- struct{}{}
+				return shortHeaderPacket{}, errNothingToPack
+			}).AnyTimes()
+			go func() {
+				defer GinkgoRecover()
+				cryptoSetup.EXPECT().StartHandshake(gomock.Any()).MaxTimes(1)
+				cryptoSetup.EXPECT().NextEvent().Return(handshake.Event{Kind: handshake.EventNoEvent})
+				conn.run()
+			}()
+			for i := 0; i < 3; i++ {
+				conn.scheduleSending()
+				Eventually(sent).Should(Receive())
+			}
+			Expect(conn.CurrentMTU()).To(BeEquivalentTo(protocol.InitialPacketSize))
+		})
 	})
 
 	Context("scheduling sending", func() {
@@ -1901,6 +2530,11 @@ var _ = Describe("Connection", func() {
 			}),
 		)
 
+		datagrams := make(chan [2]int, 10)
+		conn.tracer.SentUDPDatagram = func(size logging.ByteCount, numPackets int) {
+			datagrams <- [2]int{int(size), numPackets}
+		}
+
 		sent := make(chan struct{})
 		mconn.EXPECT().Write([]byte("foobar"), uint16(0), protocol.ECT1).Do(func([]byte, uint16, protocol.ECN) error { close(sent); return nil })
 
@@ -1913,6 +2547,8 @@ var _ = Describe("Connection", func() {
 
 		conn.scheduleSending()
 		Eventually(sent).Should(BeClosed())
+		// the two coalesced packets are sent in a single UDP datagram
+		Expect(datagrams).To(Receive(Equal([2]int{6, 2})))
 
 		// make sure the go routine returns
 		streamManager.EXPECT().CloseWithError(gomock.Any())
@@ -1947,6 +2583,21 @@ var _ = Describe("Connection", func() {
 		Eventually(handshakeCtx).Should(BeClosed())
 	})
 
+	It("records the handshake duration", func() {
+		packer.EXPECT().PackCoalescedPacket(false, gomock.Any(), conn.version).AnyTimes()
+		connRunner.EXPECT().Retire(clientDestConnID)
+		conn.sentPacketHandler.DropPackets(protocol.EncryptionInitial)
+		tracer.EXPECT().DroppedEncryptionLevel(protocol.EncryptionHandshake)
+		tracer.EXPECT().ChoseALPN(gomock.Any())
+		cryptoSetup.EXPECT().SetHandshakeConfirmed()
+		cryptoSetup.EXPECT().GetSessionTicket()
+		cryptoSetup.EXPECT().ConnectionState().AnyTimes()
+		conn.creationTime = time.Now().Add(-time.Second)
+		Expect(conn.ConnectionState().HandshakeDuration).To(BeZero())
+		Expect(conn.handleHandshakeComplete()).To(Succeed())
+		Expect(conn.ConnectionState().HandshakeDuration).To(BeNumerically("~", time.Second, scaleDuration(50*time.Millisecond)))
+	})
+
 	It("sends a session ticket when the handshake completes", func() {
 		const size = protocol.MaxPostHandshakeCryptoFrameSize * 3 / 2
 		packer.EXPECT().PackCoalescedPacket(false, gomock.Any(), conn.version).AnyTimes()
@@ -2112,10 +2763,111 @@ var _ = Describe("Connection", func() {
 			conn.handleTransportParameters(params)
 			Expect(conn.earlyConnReady()).To(BeClosed())
 		})
-	})
 
-	Context("keep-alives", func() {
-		setRemoteIdleTimeout := func(t time.Duration) {
+		It("exposes the transport parameters received from the peer", func() {
+			minAckDelay := 2 * time.Millisecond
+			params := &wire.TransportParameters{
+				MaxIdleTimeout:          90 * time.Second,
+				InitialMaxData:          0x5000,
+				InitialMaxStreamDataUni: 0x1234,
+				MaxBidiStreamNum:        42,
+				MaxUniStreamNum:         7,
+				AckDelayExponent:        5,
+				MaxAckDelay:             30 * time.Millisecond,
+				MinAckDelay:             &minAckDelay,
+				ActiveConnectionIDLimit: 3,
+				MaxDatagramFrameSize:    protocol.InvalidByteCount,
+				MaxUDPPayloadSize:       1400,
+				GreaseQUICBit:           true,
+
+				InitialSourceConnectionID: destConnID,
+			}
+			streamManager.EXPECT().UpdateLimits(params)
+			packer.EXPECT().PackCoalescedPacket(false, gomock.Any(), conn.version).MaxTimes(3)
+			tracer.EXPECT().ReceivedTransportParameters(params)
+			Expect(conn.PeerTransportParameters()).To(BeNil())
+			conn.handleTransportParameters(params)
+			tp := conn.PeerTransportParameters()
+			Expect(tp).To(Equal(&TransportParameters{
+				MaxIdleTimeout:          90 * time.Second,
+				MaxUDPPayloadSize:       1400,
+				InitialMaxData:          0x5000,
+				InitialMaxStreamDataUni: 0x1234,
+				InitialMaxStreamsBidi:   42,
+				InitialMaxStreamsUni:    7,
+				AckDelayExponent:        5,
+				MaxAckDelay:             30 * time.Millisecond,
+				ActiveConnectionIDLimit: 3,
+				MinAckDelay:             2 * time.Millisecond,
+				GreaseQUICBit:           true,
+			}))
+			// modifying the returned struct doesn't change the connection's copy
+			tp.InitialMaxData = 1
+			Expect(conn.PeerTransportParameters().InitialMaxData).To(BeEquivalentTo(0x5000))
+		})
+
+		It("doesn't expose restored transport parameters", func() {
+			params := &wire.TransportParameters{ActiveConnectionIDLimit: 3}
+			streamManager.EXPECT().UpdateLimits(params)
+			conn.restoreTransportParameters(params)
+			Expect(conn.PeerTransportParameters()).To(BeNil())
+		})
+
+		It("says if reliable stream resets are supported", func() {
+			conn.config.EnableResetStreamAt = true
+			params := &wire.TransportParameters{
+				ActiveConnectionIDLimit:   3,
+				InitialSourceConnectionID: destConnID,
+				EnableResetStreamAt:       true,
+			}
+			streamManager.EXPECT().UpdateLimits(params)
+			tracer.EXPECT().ReceivedTransportParameters(params)
+			Expect(conn.supportsResetStreamAt()).To(BeFalse())
+			conn.handleTransportParameters(params)
+			Expect(conn.supportsResetStreamAt()).To(BeTrue())
+		})
+
+		It("doesn't use reliable stream resets if they're not enabled locally", func() {
+			params := &wire.TransportParameters{
+				ActiveConnectionIDLimit:   3,
+				InitialSourceConnectionID: destConnID,
+				EnableResetStreamAt:       true,
+			}
+			streamManager.EXPECT().UpdateLimits(params)
+			tracer.EXPECT().ReceivedTransportParameters(params)
+			conn.handleTransportParameters(params)
+			Expect(conn.supportsResetStreamAt()).To(BeFalse())
+		})
+
+		It("greases the QUIC bit, if negotiated", func() {
+			conn.config.EnableQUICBitGreasing = true
+			params := &wire.TransportParameters{
+				ActiveConnectionIDLimit:   3,
+				InitialSourceConnectionID: destConnID,
+				GreaseQUICBit:             true,
+			}
+			streamManager.EXPECT().UpdateLimits(params)
+			tracer.EXPECT().ReceivedTransportParameters(params)
+			packer.EXPECT().EnableQUICBitGreasing()
+			conn.handleTransportParameters(params)
+			Expect(conn.connState.SupportsQUICBitGreasing).To(BeTrue())
+		})
+
+		It("doesn't grease the QUIC bit if it's not enabled locally", func() {
+			params := &wire.TransportParameters{
+				ActiveConnectionIDLimit:   3,
+				InitialSourceConnectionID: destConnID,
+				GreaseQUICBit:             true,
+			}
+			streamManager.EXPECT().UpdateLimits(params)
+			tracer.EXPECT().ReceivedTransportParameters(params)
+			conn.handleTransportParameters(params)
+			Expect(conn.connState.SupportsQUICBitGreasing).To(BeFalse())
+		})
+	})
+
+	Context("keep-alives", func() {
+		setRemoteIdleTimeout := func(t time.Duration) {
 			streamManager.EXPECT().UpdateLimits(gomock.Any())
 			tracer.EXPECT().ReceivedTransportParameters(gomock.Any())
 			conn.handleTransportParameters(&wire.TransportParameters{
@@ -2164,6 +2916,32 @@ var _ = Describe("Connection", func() {
 			Eventually(sent).Should(BeClosed())
 		})
 
+		It("randomizes the keep-alive interval", func() {
+			conn.config.KeepAliveJitter = 2 * time.Second
+			setRemoteIdleTimeout(30 * time.Second)
+			Expect(conn.keepAliveInterval).To(And(
+				BeNumerically(">=", 13*time.Second),
+				BeNumerically("<=", 15*time.Second),
+			))
+			// the interval varies between connections
+			intervals := make(map[time.Duration]struct{})
+			for i := 0; i < 100; i++ {
+				interval := jitterKeepAliveInterval(15*time.Second, 2*time.Second)
+				Expect(interval).To(And(
+					BeNumerically(">=", 13*time.Second),
+					BeNumerically("<=", 15*time.Second),
+				))
+				intervals[interval] = struct{}{}
+			}
+			Expect(len(intervals)).To(BeNumerically(">", 90))
+			// the jitter is capped to half the interval
+			for i := 0; i < 100; i++ {
+				Expect(jitterKeepAliveInterval(time.Second, time.Hour)).To(BeNumerically(">=", time.Second/2))
+			}
+			Expect(jitterKeepAliveInterval(time.Second, 0)).To(Equal(time.Second))
+			runConn()
+		})
+
 		It("sends a PING after a maximum of protocol.MaxKeepAliveInterval", func() {
 			conn.config.MaxIdleTimeout = time.Hour
 			setRemoteIdleTimeout(time.Hour)
@@ -2177,6 +2955,79 @@ var _ = Describe("Connection", func() {
 			Eventually(sent).Should(BeClosed())
 		})
 
+		It("sends a PING when the application requests it", func() {
+			conn.config.KeepAlivePeriod = 0
+			sent := make(chan []ackhandler.Frame, 1)
+			packer.EXPECT().PackCoalescedPacket(false, gomock.Any(), conn.version).Do(func(bool, protocol.ByteCount, protocol.Version) (*coalescedPacket, error) {
+				frames, _ := conn.framer.AppendControlFrames(nil, protocol.MaxByteCount, conn.version)
+				sent <- frames
+				return nil, nil
+			})
+			runConn()
+			// multiple requests only result in a single PING frame
+			Expect(conn.SendKeepAlive()).To(Succeed())
+			Expect(conn.SendKeepAlive()).To(Succeed())
+			var frames []ackhandler.Frame
+			Eventually(sent).Should(Receive(&frames))
+			Expect(frames).To(Equal([]ackhandler.Frame{{Frame: &wire.PingFrame{}}}))
+		})
+
+		It("measures the RTT using a PING", func() {
+			conn.config.KeepAlivePeriod = 0
+			packer.EXPECT().PackCoalescedPacket(false, gomock.Any(), conn.version).DoAndReturn(func(bool, protocol.ByteCount, protocol.Version) (*coalescedPacket, error) {
+				frames, _ := conn.framer.AppendControlFrames(nil, protocol.MaxByteCount, conn.version)
+				Expect(frames).To(HaveLen(1))
+				Expect(frames[0].Frame).To(Equal(&wire.PingFrame{}))
+				Expect(frames[0].Handler).ToNot(BeNil())
+				// when the PING is lost, a new PING is queued
+				frames[0].Handler.OnLost(frames[0].Frame)
+				retransmitted, _ := conn.framer.AppendControlFrames(nil, protocol.MaxByteCount, conn.version)
+				Expect(retransmitted).To(HaveLen(1))
+				Expect(retransmitted[0].Frame).To(Equal(&wire.PingFrame{}))
+				time.Sleep(scaleDuration(10 * time.Millisecond))
+				retransmitted[0].Handler.OnAcked(retransmitted[0].Frame)
+				return nil, nil
+			})
+			runConn()
+			rtt, err := conn.Ping(context.Background())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(rtt).To(BeNumerically(">=", scaleDuration(10*time.Millisecond)))
+		})
+
+		It("stops waiting for the PING to be acknowledged when the context is canceled", func() {
+			conn.config.KeepAlivePeriod = 0
+			packer.EXPECT().PackCoalescedPacket(false, gomock.Any(), conn.version).AnyTimes()
+			runConn()
+			ctx, cancel := context.WithTimeout(context.Background(), scaleDuration(20*time.Millisecond))
+			defer cancel()
+			_, err := conn.Ping(ctx)
+			Expect(err).To(MatchError(context.DeadlineExceeded))
+		})
+
+		It("doesn't retransmit the PING once Ping returned", func() {
+			conn.config.KeepAlivePeriod = 0
+			sent := make(chan []ackhandler.Frame, 1)
+			packer.EXPECT().PackCoalescedPacket(false, gomock.Any(), conn.version).DoAndReturn(func(bool, protocol.ByteCount, protocol.Version) (*coalescedPacket, error) {
+				frames, _ := conn.framer.AppendControlFrames(nil, protocol.MaxByteCount, conn.version)
+				sent <- frames
+				return nil, nil
+			})
+			runConn()
+			ctx, cancel := context.WithCancel(context.Background())
+			errChan := make(chan error, 1)
+			go func() {
+				_, err := conn.Ping(ctx)
+				errChan <- err
+			}()
+			var frames []ackhandler.Frame
+			Eventually(sent).Should(Receive(&frames))
+			Expect(frames).To(HaveLen(1))
+			cancel()
+			Eventually(errChan).Should(Receive(MatchError(context.Canceled)))
+			frames[0].Handler.OnLost(frames[0].Frame)
+			Expect(conn.framer.HasData()).To(BeFalse())
+		})
+
 		It("doesn't send a PING packet if keep-alive is disabled", func() {
 			setRemoteIdleTimeout(5 * time.Second)
 			conn.config.KeepAlivePeriod = 0
@@ -2242,6 +3093,35 @@ var _ = Describe("Connection", func() {
 			Eventually(done).Should(BeClosed())
 		})
 
+		It("uses the clock from the Config for the idle timeout", func() {
+			clock := &fakeClock{now: time.Now()}
+			conn.clock = clock
+			conn.lastPacketReceivedTime = clock.Now()
+			conn.idleTimeout = 30 * time.Second
+			connRunner.EXPECT().Remove(gomock.Any()).Times(2)
+			cryptoSetup.EXPECT().Close()
+			gomock.InOrder(
+				tracer.EXPECT().ClosedConnection(gomock.Any()).Do(func(e error) {
+					Expect(e).To(MatchError(&qerr.IdleTimeoutError{}))
+				}),
+				tracer.EXPECT().Close(),
+			)
+			done := make(chan struct{})
+			go func() {
+				defer GinkgoRecover()
+				cryptoSetup.EXPECT().StartHandshake(gomock.Any()).MaxTimes(1)
+				cryptoSetup.EXPECT().NextEvent().Return(handshake.Event{Kind: handshake.EventNoEvent})
+				err := conn.run()
+				Expect(err).To(MatchError(qerr.ErrIdleTimeout))
+				close(done)
+			}()
+			Consistently(done, scaleDuration(50*time.Millisecond)).ShouldNot(BeClosed())
+			clock.Advance(29 * time.Second)
+			Consistently(done, scaleDuration(50*time.Millisecond)).ShouldNot(BeClosed())
+			clock.Advance(2 * time.Second)
+			Eventually(done).Should(BeClosed())
+		})
+
 		It("times out due to non-completed handshake", func() {
 			conn.handshakeComplete = false
 			conn.creationTime = time.Now().Add(-2 * protocol.DefaultHandshakeIdleTimeout).Add(-time.Second)
@@ -2268,6 +3148,36 @@ var _ = Describe("Connection", func() {
 			Eventually(done).Should(BeClosed())
 		})
 
+		It("times out when the maximum number of PTOs is exceeded", func() {
+			conn.config.MaxPTOCount = 3
+			sph := mockackhandler.NewMockSentPacketHandler(mockCtrl)
+			conn.sentPacketHandler = sph
+			sph.EXPECT().GetLossDetectionTimeout().Return(time.Now().Add(-time.Millisecond)).AnyTimes()
+			sph.EXPECT().OnLossDetectionTimeout()
+			sph.EXPECT().Stats().Return(ackhandler.Stats{PTOCount: 4})
+			connRunner.EXPECT().Remove(gomock.Any()).Times(2)
+			cryptoSetup.EXPECT().Close()
+			gomock.InOrder(
+				tracer.EXPECT().ClosedConnection(gomock.Any()).Do(func(e error) {
+					Expect(e).To(MatchError(&PTOLimitReachedError{}))
+				}),
+				tracer.EXPECT().Close(),
+			)
+			done := make(chan struct{})
+			go func() {
+				defer GinkgoRecover()
+				cryptoSetup.EXPECT().StartHandshake(gomock.Any()).MaxTimes(1)
+				cryptoSetup.EXPECT().NextEvent().Return(handshake.Event{Kind: handshake.EventNoEvent})
+				err := conn.run()
+				nerr, ok := err.(net.Error)
+				Expect(ok).To(BeTrue())
+				Expect(nerr.Timeout()).To(BeTrue())
+				Expect(err).To(MatchError(qerr.ErrPTOLimitReached))
+				close(done)
+			}()
+			Eventually(done).Should(BeClosed())
+		})
+
 		It("does not use the idle timeout before the handshake complete", func() {
 			conn.handshakeComplete = false
 			conn.config.HandshakeIdleTimeout = 9999 * time.Second
@@ -2334,6 +3244,8 @@ var _ = Describe("Connection", func() {
 		It("closes the connection due to the idle timeout after handshake", func() {
 			conn.sentPacketHandler.DropPackets(protocol.EncryptionInitial)
 			packer.EXPECT().PackCoalescedPacket(false, gomock.Any(), conn.version).AnyTimes()
+			// the client's address was never validated
+			tracer.EXPECT().AmplificationLimited(gomock.Any(), gomock.Any()).MaxTimes(1)
 			gomock.InOrder(
 				connRunner.EXPECT().Retire(clientDestConnID),
 				connRunner.EXPECT().Remove(gomock.Any()),
@@ -2464,6 +3376,13 @@ var _ = Describe("Connection", func() {
 			Expect(str).To(Equal(mstr))
 		})
 
+		It("says how many streams are available", func() {
+			streamManager.EXPECT().StreamsAvailable().Return(int64(3), int64(7))
+			bidi, uni := conn.StreamsAvailable()
+			Expect(bidi).To(BeEquivalentTo(3))
+			Expect(uni).To(BeEquivalentTo(7))
+		})
+
 		It("accepts streams", func() {
 			ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
 			defer cancel()
@@ -2485,6 +3404,94 @@ var _ = Describe("Connection", func() {
 		})
 	})
 
+	Context("messages", func() {
+		It("sends a message", func() {
+			mstr := NewMockSendStreamI(mockCtrl)
+			streamManager.EXPECT().OpenUniStreamSync(context.Background()).Return(mstr, nil)
+			gomock.InOrder(
+				mstr.EXPECT().Write([]byte("foobar")).Return(6, nil),
+				mstr.EXPECT().Close(),
+			)
+			Expect(conn.SendMessage(context.Background(), []byte("foobar"))).To(Succeed())
+		})
+
+		It("returns the error when opening the stream fails", func() {
+			testErr := errors.New("test error")
+			streamManager.EXPECT().OpenUniStreamSync(context.Background()).Return(nil, testErr)
+			Expect(conn.SendMessage(context.Background(), []byte("foobar"))).To(MatchError(testErr))
+		})
+
+		It("resets the stream when writing fails", func() {
+			testErr := errors.New("test error")
+			mstr := NewMockSendStreamI(mockCtrl)
+			streamManager.EXPECT().OpenUniStreamSync(context.Background()).Return(mstr, nil)
+			mstr.EXPECT().Write(gomock.Any()).Return(0, testErr)
+			mstr.EXPECT().CancelWrite(StreamErrorCode(0))
+			Expect(conn.SendMessage(context.Background(), []byte("foobar"))).To(MatchError(testErr))
+		})
+
+		It("resets the stream when the context is canceled while sending", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			mstr := NewMockSendStreamI(mockCtrl)
+			streamManager.EXPECT().OpenUniStreamSync(ctx).Return(mstr, nil)
+			canceled := make(chan struct{})
+			mstr.EXPECT().Write(gomock.Any()).DoAndReturn(func([]byte) (int, error) {
+				cancel()
+				<-canceled
+				return 0, &StreamError{ErrorCode: 0}
+			})
+			mstr.EXPECT().CancelWrite(StreamErrorCode(0)).Do(func(StreamErrorCode) { close(canceled) })
+			Expect(conn.SendMessage(ctx, []byte("foobar"))).To(MatchError(context.Canceled))
+		})
+
+		It("accepts a message", func() {
+			mstr := NewMockReceiveStreamI(mockCtrl)
+			streamManager.EXPECT().AcceptUniStream(context.Background()).Return(mstr, nil)
+			r := bytes.NewReader([]byte("foobar"))
+			mstr.EXPECT().Read(gomock.Any()).DoAndReturn(r.Read).AnyTimes()
+			data, err := conn.AcceptMessage(context.Background())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(data).To(Equal([]byte("foobar")))
+		})
+
+		It("accepts a message of the maximum size", func() {
+			conn.config.MaxMessageSize = 6
+			mstr := NewMockReceiveStreamI(mockCtrl)
+			streamManager.EXPECT().AcceptUniStream(context.Background()).Return(mstr, nil)
+			r := bytes.NewReader([]byte("foobar"))
+			mstr.EXPECT().Read(gomock.Any()).DoAndReturn(r.Read).AnyTimes()
+			data, err := conn.AcceptMessage(context.Background())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(data).To(Equal([]byte("foobar")))
+		})
+
+		It("rejects messages that are too large", func() {
+			conn.config.MaxMessageSize = 5
+			mstr := NewMockReceiveStreamI(mockCtrl)
+			streamManager.EXPECT().AcceptUniStream(context.Background()).Return(mstr, nil)
+			r := bytes.NewReader([]byte("foobar"))
+			mstr.EXPECT().Read(gomock.Any()).DoAndReturn(r.Read).AnyTimes()
+			mstr.EXPECT().CancelRead(StreamErrorCode(0))
+			_, err := conn.AcceptMessage(context.Background())
+			Expect(err).To(MatchError(&MessageTooLargeError{MaxMessageSize: 5}))
+		})
+
+		It("cancels reading when the context is canceled", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			mstr := NewMockReceiveStreamI(mockCtrl)
+			streamManager.EXPECT().AcceptUniStream(ctx).Return(mstr, nil)
+			canceled := make(chan struct{})
+			mstr.EXPECT().Read(gomock.Any()).DoAndReturn(func([]byte) (int, error) {
+				cancel()
+				<-canceled
+				return 0, &StreamError{ErrorCode: 0}
+			})
+			mstr.EXPECT().CancelRead(StreamErrorCode(0)).Do(func(StreamErrorCode) { close(canceled) })
+			_, err := conn.AcceptMessage(ctx)
+			Expect(err).To(MatchError(context.Canceled))
+		})
+	})
+
 	Context("datagrams", func() {
 		It("doesn't allow datagrams if the peer didn't enable support", func() {
 			conn.peerParams = &wire.TransportParameters{MaxDatagramFrameSize: 0}
@@ -2499,6 +3506,17 @@ var _ = Describe("Connection", func() {
 			Expect(f.Data).To(Equal([]byte("foobar")))
 		})
 
+		It("drops datagrams queued for longer than the send timeout", func() {
+			conn.peerParams = &wire.TransportParameters{MaxDatagramFrameSize: 1000}
+			Expect(conn.SendDatagram([]byte("foo"))).To(Succeed())
+			conn.SetDatagramSendTimeout(scaleDuration(10 * time.Millisecond))
+			Expect(conn.SendDatagram([]byte("bar"))).To(Succeed())
+			time.Sleep(scaleDuration(20 * time.Millisecond))
+			Expect(conn.datagramQueue.Peek().Data).To(Equal([]byte("foo")))
+			conn.datagramQueue.Pop()
+			Expect(conn.datagramQueue.Peek()).To(BeNil())
+		})
+
 		It("says when a datagram is too big", func() {
 			conn.peerParams = &wire.TransportParameters{MaxDatagramFrameSize: 1000}
 			err := conn.SendDatagram(make([]byte, 2000))
@@ -2509,13 +3527,328 @@ var _ = Describe("Connection", func() {
 			Expect(conn.SendDatagram(make([]byte, derr.MaxDatagramPayloadSize))).To(Succeed())
 		})
 
+		It("returns the maximum datagram size", func() {
+			Expect(conn.MaxDatagramSize()).To(BeZero())
+			conn.peerParams = &wire.TransportParameters{MaxDatagramFrameSize: 2000}
+			conn.connState.SupportsDatagrams = true
+			size := conn.MaxDatagramSize()
+			Expect(size).To(BeNumerically(">", 1000))
+			Expect(size).To(BeNumerically("<", protocol.InitialPacketSize))
+			Expect(conn.SendDatagram(make([]byte, size))).To(Succeed())
+			Expect(conn.SendDatagram(make([]byte, size+1))).To(MatchError(&DatagramTooLargeError{}))
+			// increasing the MTU increases the maximum datagram size
+			conn.onMTUIncreased(protocol.InitialPacketSize + 100)
+			Expect(conn.MaxDatagramSize()).To(BeNumerically(">", size))
+			// but the limit set by the peer still applies
+			conn.onMTUIncreased(3000)
+			Expect(conn.MaxDatagramSize()).To(BeNumerically("<", 2000))
+			conn.ctxCancel(nil)
+			Expect(conn.MaxDatagramSize()).To(BeZero())
+		})
+
+		It("sends large datagrams", func() {
+			conn.peerParams = &wire.TransportParameters{MaxDatagramFrameSize: 2000}
+			conn.connState.SupportsDatagrams = true
+			data := make([]byte, 3*conn.MaxDatagramSize())
+			rand.Read(data)
+			Expect(conn.SendLargeDatagram(data)).To(Succeed())
+			r := newDatagramReassembler()
+			var msg []byte
+			for i := 0; i < 4; i++ {
+				f := conn.datagramQueue.Peek()
+				Expect(f).ToNot(BeNil())
+				Expect(len(f.Data)).To(BeNumerically("<=", conn.MaxDatagramSize()))
+				conn.datagramQueue.Pop()
+				msg = r.Add(f.Data, time.Now())
+			}
+			Expect(conn.datagramQueue.Peek()).To(BeNil())
+			Expect(msg).To(Equal(data))
+		})
+
+		It("doesn't send any fragment if the message is too large", func() {
+			conn.peerParams = &wire.TransportParameters{MaxDatagramFrameSize: 2000}
+			conn.connState.SupportsDatagrams = true
+			err := conn.SendLargeDatagram(make([]byte, 100*conn.MaxDatagramSize()))
+			Expect(err).To(BeAssignableToTypeOf(&DatagramTooLargeError{}))
+			Expect(conn.datagramQueue.Peek()).To(BeNil())
+		})
+
+		It("returns the close error when sending large datagrams on a closed connection", func() {
+			conn.peerParams = &wire.TransportParameters{MaxDatagramFrameSize: 2000}
+			conn.connState.SupportsDatagrams = true
+			testErr := errors.New("test error")
+			conn.ctxCancel(testErr)
+			Expect(conn.SendLargeDatagram([]byte("foobar"))).To(MatchError(testErr))
+			Expect(conn.datagramQueue.Peek()).To(BeNil())
+		})
+
 		It("receives datagrams", func() {
 			conn.config.EnableDatagrams = true
-			conn.datagramQueue.HandleDatagramFrame(&wire.DatagramFrame{Data: []byte("foobar")})
+			conn.datagramQueue.HandleDatagramFrame(&wire.DatagramFrame{Data: []byte("foobar")}, time.Now(), nil)
 			data, err := conn.ReceiveDatagram(context.Background())
 			Expect(err).ToNot(HaveOccurred())
 			Expect(data).To(Equal([]byte("foobar")))
 		})
+
+		It("accepts DATAGRAM frames up to the advertised max_datagram_frame_size", func() {
+			conn.config.EnableDatagrams = true
+			// 1 byte frame type and 2 bytes length
+			f := &wire.DatagramFrame{DataLenPresent: true, Data: make([]byte, wire.MaxDatagramSize-3)}
+			Expect(f.Length(conn.version)).To(Equal(wire.MaxDatagramSize))
+			Expect(conn.handleFrame(f, protocol.Encryption1RTT, protocol.ConnectionID{})).To(Succeed())
+			data, ok := conn.TryReceiveDatagram()
+			Expect(ok).To(BeTrue())
+			Expect(data).To(HaveLen(int(wire.MaxDatagramSize - 3)))
+		})
+
+		It("errors when receiving a DATAGRAM frame larger than the advertised max_datagram_frame_size", func() {
+			conn.config.EnableDatagrams = true
+			f := &wire.DatagramFrame{DataLenPresent: true, Data: make([]byte, wire.MaxDatagramSize-2)}
+			err := conn.handleFrame(f, protocol.Encryption1RTT, protocol.ConnectionID{})
+			Expect(err).To(MatchError(&qerr.TransportError{
+				ErrorCode:    qerr.ProtocolViolation,
+				ErrorMessage: fmt.Sprintf("DATAGRAM frame too large: %d bytes (max_datagram_frame_size: %d bytes)", wire.MaxDatagramSize+1, wire.MaxDatagramSize),
+			}))
+			_, ok := conn.TryReceiveDatagram()
+			Expect(ok).To(BeFalse())
+		})
+
+		It("tries to receive datagrams", func() {
+			conn.config.EnableDatagrams = true
+			_, ok := conn.TryReceiveDatagram()
+			Expect(ok).To(BeFalse())
+			conn.datagramQueue.HandleDatagramFrame(&wire.DatagramFrame{Data: []byte("foobar")}, time.Now(), nil)
+			data, ok := conn.TryReceiveDatagram()
+			Expect(ok).To(BeTrue())
+			Expect(data).To(Equal([]byte("foobar")))
+		})
+
+		It("doesn't receive datagrams if datagram support is disabled", func() {
+			conn.datagramQueue.HandleDatagramFrame(&wire.DatagramFrame{Data: []byte("foobar")}, time.Now(), nil)
+			_, ok := conn.TryReceiveDatagram()
+			Expect(ok).To(BeFalse())
+		})
+
+		It("receives datagrams with the receive time of the packet", func() {
+			conn.config.EnableDatagrams = true
+			conn.config.RecordDatagramReceiveTime = true
+			conn.datagramQueue = newDatagramQueue(func() {}, maxDatagramSendQueueLen, 0, 0, nil, true, false, utils.DefaultClock{}, utils.DefaultLogger)
+			rcvTime := time.Now().Add(-time.Second)
+			conn.rcvTime = rcvTime
+			Expect(conn.handleFrame(&wire.DatagramFrame{Data: []byte("foobar")}, protocol.Encryption1RTT, protocol.ConnectionID{})).To(Succeed())
+			data, t, err := conn.ReceiveDatagramWithTime(context.Background())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(data).To(Equal([]byte("foobar")))
+			Expect(t).To(Equal(rcvTime))
+		})
+
+		Context("ignoring DATAGRAM frames for the idle timeout", func() {
+			var datagramPacket, pingPacket []byte
+
+			BeforeEach(func() {
+				conn.config.EnableDatagrams = true
+				conn.frameParser = *wire.NewFrameParser(true, false, false)
+				conn.datagramQueue = newDatagramQueue(func() {}, maxDatagramSendQueueLen, 0, 0, nil, false, false, utils.DefaultClock{}, utils.DefaultLogger)
+				var err error
+				datagramPacket, err = (&wire.DatagramFrame{DataLenPresent: true, Data: []byte("foobar")}).Append(nil, protocol.Version1)
+				Expect(err).ToNot(HaveOccurred())
+				pingPacket, err = (&wire.PingFrame{}).Append(nil, protocol.Version1)
+				Expect(err).ToNot(HaveOccurred())
+			})
+
+			It("restarts the idle timer for DATAGRAM frames by default", func() {
+				lastRcvTime := time.Now().Add(-time.Minute)
+				conn.lastPacketReceivedTime = lastRcvTime
+				rcvTime := time.Now()
+				Expect(conn.handleUnpackedShortHeaderPacket(protocol.ConnectionID{}, 10, datagramPacket, protocol.ECNNon, rcvTime, nil)).To(Succeed())
+				Expect(conn.lastPacketReceivedTime).To(Equal(rcvTime))
+				Expect(conn.restartsIdleTimer(nil, []ackhandler.Frame{{Frame: &wire.DatagramFrame{}}})).To(BeTrue())
+			})
+
+			It("doesn't restart the idle timer for packets that only contain DATAGRAM frames", func() {
+				conn.config.IdleTimeoutIgnoresDatagrams = true
+				lastRcvTime := time.Now().Add(-time.Minute)
+				conn.lastPacketReceivedTime = lastRcvTime
+				Expect(conn.handleUnpackedShortHeaderPacket(protocol.ConnectionID{}, 10, datagramPacket, protocol.ECNNon, time.Now(), nil)).To(Succeed())
+				Expect(conn.lastPacketReceivedTime).To(Equal(lastRcvTime))
+				rcvTime := time.Now()
+				Expect(conn.handleUnpackedShortHeaderPacket(protocol.ConnectionID{}, 11, append(datagramPacket, pingPacket...), protocol.ECNNon, rcvTime, nil)).To(Succeed())
+				Expect(conn.lastPacketReceivedTime).To(Equal(rcvTime))
+			})
+
+			It("restarts the idle timer for packets that only contain DATAGRAM frames when waiting for an acknowledgment", func() {
+				conn.config.IdleTimeoutIgnoresDatagrams = true
+				conn.lastPacketReceivedTime = time.Now().Add(-time.Minute)
+				conn.firstAckElicitingPacketAfterIdleSentTime = time.Now().Add(-time.Second)
+				rcvTime := time.Now()
+				Expect(conn.handleUnpackedShortHeaderPacket(protocol.ConnectionID{}, 10, datagramPacket, protocol.ECNNon, rcvTime, nil)).To(Succeed())
+				Expect(conn.lastPacketReceivedTime).To(Equal(rcvTime))
+				Expect(conn.firstAckElicitingPacketAfterIdleSentTime).To(BeZero())
+			})
+
+			It("doesn't restart the idle timer when sending packets that only contain DATAGRAM frames", func() {
+				conn.config.IdleTimeoutIgnoresDatagrams = true
+				Expect(conn.restartsIdleTimer(nil, []ackhandler.Frame{{Frame: &wire.DatagramFrame{}}})).To(BeFalse())
+				Expect(conn.restartsIdleTimer(nil, []ackhandler.Frame{{Frame: &wire.DatagramFrame{}}, {Frame: &wire.PingFrame{}}})).To(BeTrue())
+				Expect(conn.restartsIdleTimer([]ackhandler.StreamFrame{{Frame: &wire.StreamFrame{}}}, nil)).To(BeTrue())
+			})
+		})
+
+		It("receives datagrams without copying them", func() {
+			conn.config.EnableDatagrams = true
+			conn.config.EnableZeroCopyDatagrams = true
+			conn.datagramQueue = newDatagramQueue(func() {}, maxDatagramSendQueueLen, 0, 0, nil, false, true, utils.DefaultClock{}, utils.DefaultLogger)
+			buf := getPacketBuffer()
+			buf.Data = append(buf.Data, []byte("foobar")...)
+			conn.rcvBuffer = buf
+			Expect(conn.handleFrame(&wire.DatagramFrame{Data: buf.Data}, protocol.Encryption1RTT, protocol.ConnectionID{})).To(Succeed())
+			conn.rcvBuffer = nil
+			b, err := conn.ReceiveDatagramBuffer(context.Background())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(b.Data()).To(Equal([]byte("foobar")))
+			Expect(&b.Data()[0]).To(BeIdenticalTo(&buf.Data[0]))
+			Expect(buf.refCount).To(Equal(2))
+			b.Release()
+			conn.datagramQueue.ReleaseBuffers()
+			Expect(buf.refCount).To(Equal(1))
+		})
+	})
+
+	Context("ACK frequency", func() {
+		It("errors when requesting ACKs if the extension wasn't negotiated", func() {
+			Expect(conn.RequestImmediateAck()).To(MatchError("ACK Frequency extension not negotiated"))
+			Expect(conn.RequestAckFrequency(10, 50*time.Millisecond)).To(MatchError("ACK Frequency extension not negotiated"))
+		})
+
+		It("rejects max ack delays smaller than the peer's min_ack_delay", func() {
+			conn.connState.SupportsAckFrequency = true
+			conn.peerMinAckDelay = 5 * time.Millisecond
+			Expect(conn.RequestAckFrequency(10, 4*time.Millisecond)).To(MatchError("max ack delay (4ms) smaller than the peer's min_ack_delay (5ms)"))
+		})
+
+		It("queues ACK_FREQUENCY frames with increasing sequence numbers", func() {
+			conn.connState.SupportsAckFrequency = true
+			conn.peerMinAckDelay = time.Millisecond
+			Expect(conn.RequestAckFrequency(10, 50*time.Millisecond)).To(Succeed())
+			conn.maybeQueueAckFrequencyFrame()
+			// only the most recent request is sent
+			Expect(conn.RequestAckFrequency(5, 100*time.Millisecond)).To(Succeed())
+			Expect(conn.RequestAckFrequency(20, 200*time.Millisecond)).To(Succeed())
+			conn.maybeQueueAckFrequencyFrame()
+			frames, _ := conn.framer.AppendControlFrames(nil, 1000, protocol.Version1)
+			Expect(frames).To(ConsistOf(
+				ackhandler.Frame{Frame: &wire.AckFrequencyFrame{
+					SequenceNumber:        0,
+					AckElicitingThreshold: 10,
+					RequestMaxAckDelay:    50 * time.Millisecond,
+					ReorderingThreshold:   1,
+				}},
+				ackhandler.Frame{Frame: &wire.AckFrequencyFrame{
+					SequenceNumber:        1,
+					AckElicitingThreshold: 20,
+					RequestMaxAckDelay:    200 * time.Millisecond,
+					ReorderingThreshold:   1,
+				}},
+			))
+			// the PTO takes the increased max ack delay into account
+			Expect(conn.rttStats.MaxAckDelay()).To(Equal(200 * time.Millisecond))
+		})
+
+		It("errors when requesting ACKs after the connection was closed", func() {
+			conn.connState.SupportsAckFrequency = true
+			testErr := errors.New("test error")
+			conn.ctxCancel(testErr)
+			Expect(conn.RequestImmediateAck()).To(MatchError(testErr))
+			Expect(conn.RequestAckFrequency(10, 50*time.Millisecond)).To(MatchError(testErr))
+		})
+	})
+
+	Context("key updates", func() {
+		It("initiates a key update", func() {
+			cryptoSetup.EXPECT().InitiateKeyUpdate()
+			Expect(conn.InitiateKeyUpdate()).To(Succeed())
+			Expect(conn.keepAliveRequested.Load()).To(BeTrue())
+		})
+
+		It("returns errors from the crypto setup", func() {
+			testErr := errors.New("key update not allowed")
+			cryptoSetup.EXPECT().InitiateKeyUpdate().Return(testErr)
+			Expect(conn.InitiateKeyUpdate()).To(MatchError(testErr))
+			Expect(conn.keepAliveRequested.Load()).To(BeFalse())
+		})
+
+		It("errors when initiating a key update after the connection was closed", func() {
+			testErr := errors.New("test error")
+			conn.ctxCancel(testErr)
+			Expect(conn.InitiateKeyUpdate()).To(MatchError(testErr))
+		})
+
+		It("returns the key phase", func() {
+			cryptoSetup.EXPECT().CurrentKeyPhase().Return(protocol.KeyPhase(5))
+			phase, updates := conn.KeyPhase()
+			Expect(phase).To(BeEquivalentTo(1))
+			Expect(updates).To(BeEquivalentTo(5))
+		})
+	})
+
+	Context("custom frames", func() {
+		It("passes received frames to the handler", func() {
+			var received []byte
+			conn.config.CustomFrameHandlers = map[uint64]func(Connection, []byte){
+				0x1337: func(c Connection, payload []byte) {
+					Expect(c).To(Equal(conn))
+					received = payload
+				},
+			}
+			f := &wire.CustomFrame{Type: 0x1337, Data: []byte("foobar")}
+			Expect(conn.handleFrame(f, protocol.Encryption1RTT, protocol.ConnectionID{})).To(Succeed())
+			Expect(received).To(Equal([]byte("foobar")))
+		})
+
+		It("errors when receiving a frame of an unregistered custom frame type", func() {
+			conn.config.CustomFrameHandlers = map[uint64]func(Connection, []byte){0x1337: func(Connection, []byte) {}}
+			f := &wire.CustomFrame{Type: 0x1338, Data: []byte("foobar")}
+			Expect(conn.handleFrame(f, protocol.Encryption1RTT, protocol.ConnectionID{})).To(MatchError(&qerr.TransportError{
+				ErrorCode:    qerr.FrameEncodingError,
+				FrameType:    0x1338,
+				ErrorMessage: "unregistered custom frame type",
+			}))
+		})
+
+		It("returns a copy of the custom frame handlers in the config", func() {
+			conn.config.CustomFrameHandlers = map[uint64]func(Connection, []byte){0x1337: func(Connection, []byte) {}}
+			conf := conn.GetConfig()
+			delete(conf.CustomFrameHandlers, 0x1337)
+			Expect(conn.config.CustomFrameHandlers).To(HaveKey(uint64(0x1337)))
+		})
+
+		It("queues custom frames", func() {
+			conn.config.CustomFrameHandlers = map[uint64]func(Connection, []byte){0x1337: func(Connection, []byte) {}}
+			payload := []byte("foobar")
+			Expect(conn.SendCustomFrame(0x1337, payload)).To(Succeed())
+			payload[0] = 'x' // the payload is copied
+			frames, _ := conn.framer.AppendControlFrames(nil, 1000, protocol.Version1)
+			Expect(frames).To(Equal([]ackhandler.Frame{{Frame: &wire.CustomFrame{Type: 0x1337, Data: []byte("foobar")}}}))
+		})
+
+		It("refuses to send frames of unregistered types", func() {
+			conn.config.CustomFrameHandlers = map[uint64]func(Connection, []byte){0x1337: func(Connection, []byte) {}}
+			Expect(conn.SendCustomFrame(0x1338, []byte("foobar"))).To(MatchError("custom frame type 0x1338 not registered"))
+		})
+
+		It("refuses to send frames that don't fit into a packet", func() {
+			conn.config.CustomFrameHandlers = map[uint64]func(Connection, []byte){0x1337: func(Connection, []byte) {}}
+			err := conn.SendCustomFrame(0x1337, make([]byte, conn.config.InitialPacketSize))
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("custom frame too large"))
+		})
+
+		It("refuses to send frames when the connection is closed", func() {
+			conn.config.CustomFrameHandlers = map[uint64]func(Connection, []byte){0x1337: func(Connection, []byte) {}}
+			testErr := errors.New("test error")
+			conn.ctxCancel(testErr)
+			Expect(conn.SendCustomFrame(0x1337, []byte("foobar"))).To(MatchError(testErr))
+		})
 	})
 
 	It("returns the local address", func() {
@@ -2525,6 +3858,278 @@ var _ = Describe("Connection", func() {
 	It("returns the remote address", func() {
 		Expect(conn.RemoteAddr()).To(Equal(remoteAddr))
 	})
+
+	It("returns the current MTU", func() {
+		sph := mockackhandler.NewMockSentPacketHandler(mockCtrl)
+		conn.sentPacketHandler = sph
+		Expect(conn.CurrentMTU()).To(BeEquivalentTo(protocol.InitialPacketSize))
+		sph.EXPECT().SetMaxDatagramSize(protocol.ByteCount(1400))
+		conn.onMTUIncreased(1400)
+		Expect(conn.CurrentMTU()).To(BeEquivalentTo(1400))
+	})
+
+	It("says if 0-RTT was used", func() {
+		cryptoSetup.EXPECT().ConnectionState().Return(handshake.ConnectionState{})
+		Expect(conn.Used0RTT()).To(BeFalse())
+		cryptoSetup.EXPECT().ConnectionState().Return(handshake.ConnectionState{Used0RTT: true})
+		Expect(conn.Used0RTT()).To(BeTrue())
+	})
+
+	It("returns the stateless reset token of the active connection ID", func() {
+		_, ok := conn.StatelessResetToken()
+		Expect(ok).To(BeFalse())
+		token := protocol.StatelessResetToken{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16}
+		connRunner.EXPECT().AddResetToken(token, gomock.Any())
+		conn.connIDManager.SetHandshakeComplete()
+		Expect(conn.handleNewConnectionIDFrame(&wire.NewConnectionIDFrame{
+			SequenceNumber:      1,
+			ConnectionID:        protocol.ParseConnectionID([]byte{1, 2, 3, 4, 5}),
+			StatelessResetToken: token,
+		})).To(Succeed())
+		Expect(conn.connIDManager.Get()).To(Equal(protocol.ParseConnectionID([]byte{1, 2, 3, 4, 5})))
+		t, ok := conn.StatelessResetToken()
+		Expect(ok).To(BeTrue())
+		Expect(t).To(Equal(token))
+
+		connRunner.EXPECT().RemoveResetToken(token)
+		conn.connIDManager.Close()
+		_, ok = conn.StatelessResetToken()
+		Expect(ok).To(BeFalse())
+	})
+
+	It("sets the incoming stream limits", func() {
+		streamManager.EXPECT().SetMaxIncomingStreams(uint64(42))
+		conn.SetMaxIncomingStreams(42)
+		streamManager.EXPECT().SetMaxIncomingUniStreams(uint64(0))
+		conn.SetMaxIncomingUniStreams(-1)
+		streamManager.EXPECT().SetMaxIncomingStreams(uint64(protocol.MaxStreamCount))
+		conn.SetMaxIncomingStreams(1<<60 + 1)
+	})
+
+	It("returns the estimated bandwidth", func() {
+		sph := mockackhandler.NewMockSentPacketHandler(mockCtrl)
+		conn.sentPacketHandler = sph
+		sph.EXPECT().Stats().Return(ackhandler.Stats{})
+		_, ok := conn.EstimatedBandwidth()
+		Expect(ok).To(BeFalse())
+		sph.EXPECT().Stats().Return(ackhandler.Stats{DeliveryRate: 1337, HasDeliveryRate: true})
+		bw, ok := conn.EstimatedBandwidth()
+		Expect(ok).To(BeTrue())
+		Expect(bw).To(BeEquivalentTo(1337))
+	})
+
+	It("returns the ECN stats", func() {
+		sph := mockackhandler.NewMockSentPacketHandler(mockCtrl)
+		conn.sentPacketHandler = sph
+		sph.EXPECT().Stats().Return(ackhandler.Stats{
+			SentECT0:   10,
+			SentECT1:   20,
+			AckedECT0:  7,
+			AckedECT1:  15,
+			AckedECNCE: 3,
+		})
+		Expect(conn.ECNStats()).To(Equal(ECNStats{
+			SentECT0:  10,
+			SentECT1:  20,
+			AckedECT0: 7,
+			AckedECT1: 15,
+			AckedCE:   3,
+		}))
+	})
+
+	It("returns the stats", func() {
+		sph := mockackhandler.NewMockSentPacketHandler(mockCtrl)
+		conn.sentPacketHandler = sph
+		sph.EXPECT().Stats().Return(ackhandler.Stats{
+			MinRTT:           time.Millisecond,
+			LatestRTT:        2 * time.Millisecond,
+			SmoothedRTT:      3 * time.Millisecond,
+			MeanDeviation:    4 * time.Millisecond,
+			CongestionWindow: 1234,
+			BytesInFlight:    567,
+			PacketsLost:      8,
+		})
+		rph := mockackhandler.NewMockReceivedPacketHandler(mockCtrl)
+		conn.receivedPacketHandler = rph
+		rph.EXPECT().Stats().Return(ackhandler.ReceivedStats{ReorderedPackets: 3, DuplicatePackets: 2})
+		connFC := mocks.NewMockConnectionFlowController(mockCtrl)
+		conn.connFlowController = connFC
+		connFC.EXPECT().UnreadBytes().Return(protocol.ByteCount(9012))
+		Expect(conn.Stats()).To(Equal(ConnectionStats{
+			MinRTT:              time.Millisecond,
+			LatestRTT:           2 * time.Millisecond,
+			SmoothedRTT:         3 * time.Millisecond,
+			RTTVariance:         4 * time.Millisecond,
+			CongestionWindow:    1234,
+			BytesInFlight:       567,
+			PacketsLost:         8,
+			ReorderedPackets:    3,
+			DuplicatePackets:    2,
+			BufferedStreamBytes: 9012,
+		}))
+	})
+
+	It("returns a copy of the config", func() {
+		conf := conn.GetConfig()
+		Expect(conf).To(Equal(conn.config))
+		Expect(conf.MaxIdleTimeout).To(Equal(protocol.DefaultIdleTimeout))
+		conf.MaxIdleTimeout = time.Hour
+		conf.Versions[0] = 0x1337
+		Expect(conn.config.MaxIdleTimeout).To(Equal(protocol.DefaultIdleTimeout))
+		Expect(conn.config.Versions[0]).ToNot(BeEquivalentTo(0x1337))
+	})
+
+	It("returns a copy of the preferred address", func() {
+		conn.config.PreferredAddress = &PreferredAddress{IPv4: netip.MustParseAddrPort("1.2.3.4:5678")}
+		conf := conn.GetConfig()
+		Expect(conf.PreferredAddress).To(Equal(conn.config.PreferredAddress))
+		conf.PreferredAddress.IPv4 = netip.MustParseAddrPort("4.3.2.1:1234")
+		Expect(conn.config.PreferredAddress.IPv4).To(Equal(netip.MustParseAddrPort("1.2.3.4:5678")))
+	})
+
+	It("refuses to send a keep-alive when the connection is closed", func() {
+		testErr := errors.New("test error")
+		conn.ctxCancel(testErr)
+		Expect(conn.SendKeepAlive()).To(MatchError(testErr))
+	})
+
+	It("refuses to send a PING when the connection is closed", func() {
+		testErr := errors.New("test error")
+		conn.ctxCancel(testErr)
+		_, err := conn.Ping(context.Background())
+		Expect(err).To(MatchError(testErr))
+	})
+
+	It("refuses to flush when the connection is closed", func() {
+		testErr := errors.New("test error")
+		conn.ctxCancel(testErr)
+		Expect(conn.Flush()).To(MatchError(testErr))
+	})
+
+	It("calls OnFlowControlBlocked when a stream is flow control blocked", func() {
+		var blocked []protocol.StreamID
+		conn.config.OnFlowControlBlocked = func(id *protocol.StreamID) {
+			Expect(id).ToNot(BeNil())
+			blocked = append(blocked, *id)
+		}
+		conn.queueControlFrame(&wire.StreamDataBlockedFrame{StreamID: 4, MaximumStreamData: 1337})
+		conn.queueControlFrame(&wire.MaxDataFrame{MaximumData: 42})
+		// the callback is only called after sending
+		Expect(blocked).To(BeEmpty())
+		conn.reportFlowControlBlocked()
+		Expect(blocked).To(Equal([]protocol.StreamID{4}))
+		conn.reportFlowControlBlocked()
+		Expect(blocked).To(Equal([]protocol.StreamID{4}))
+		frames, _ := conn.framer.AppendControlFrames(nil, 1000, protocol.Version1)
+		Expect(frames).To(ContainElement(ackhandler.Frame{Frame: &wire.StreamDataBlockedFrame{StreamID: 4, MaximumStreamData: 1337}}))
+	})
+
+	Context("GetConfigForClientHello", func() {
+		It("calls GetConfigForClientHello with the ClientHello", func() {
+			var info *ClientHelloInfo
+			conn.config.GetConfigForClientHello = func(chi *ClientHelloInfo) (*Config, error) {
+				info = chi
+				return nil, nil
+			}
+			params := &wire.TransportParameters{InitialMaxData: 1337}
+			tlsConf := conn.handleClientHello(&tls.Config{}, params, true)
+			tracer.EXPECT().SentTransportParameters(params)
+			c, err := tlsConf.GetConfigForClient(&tls.ClientHelloInfo{ServerName: "example.com", SupportedProtos: []string{"foo", "bar"}})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(c).To(BeNil())
+			Expect(info).To(Equal(&ClientHelloInfo{
+				RemoteAddr:      conn.RemoteAddr(),
+				AddrVerified:    true,
+				ServerName:      "example.com",
+				SupportedProtos: []string{"foo", "bar"},
+			}))
+			Expect(params.InitialMaxData).To(Equal(protocol.ByteCount(1337)))
+		})
+
+		It("fails the handshake if GetConfigForClientHello errors", func() {
+			conn.config.GetConfigForClientHello = func(*ClientHelloInfo) (*Config, error) { return nil, errors.New("unknown protocol") }
+			tlsConf := conn.handleClientHello(&tls.Config{}, &wire.TransportParameters{}, false)
+			_, err := tlsConf.GetConfigForClient(&tls.ClientHelloInfo{})
+			Expect(err).To(MatchError(&qerr.TransportError{ErrorCode: qerr.ConnectionRefused}))
+		})
+
+		It("calls the tls.Config's GetConfigForClient afterwards", func() {
+			conn.config.GetConfigForClientHello = func(*ClientHelloInfo) (*Config, error) { return nil, nil }
+			serverTLSConf := &tls.Config{ServerName: "foo"}
+			tlsConf := conn.handleClientHello(&tls.Config{
+				GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) { return serverTLSConf, nil },
+			}, &wire.TransportParameters{}, false)
+			tracer.EXPECT().SentTransportParameters(gomock.Any())
+			c, err := tlsConf.GetConfigForClient(&tls.ClientHelloInfo{})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(c).To(Equal(serverTLSConf))
+		})
+
+		It("applies the Config", func() {
+			params := &wire.TransportParameters{MaxDatagramFrameSize: protocol.InvalidByteCount}
+			streamManager.EXPECT().SetInitialMaxIncomingStreams(uint64(7), uint64(8))
+			Expect(conn.applyConfigForClientHello(&Config{
+				InitialStreamReceiveWindow:     1000,
+				InitialConnectionReceiveWindow: 2000,
+				MaxIncomingStreams:             7,
+				MaxIncomingUniStreams:          8,
+				EnableDatagrams:                true,
+				RequireDatagrams:               true,
+				MaxIdleTimeout:                 42 * time.Second,
+				// not applied
+				HandshakeIdleTimeout: time.Hour,
+			}, params)).To(Succeed())
+			Expect(params.InitialMaxStreamDataBidiLocal).To(Equal(protocol.ByteCount(1000)))
+			Expect(params.InitialMaxStreamDataBidiRemote).To(Equal(protocol.ByteCount(1000)))
+			Expect(params.InitialMaxStreamDataUni).To(Equal(protocol.ByteCount(1000)))
+			Expect(params.InitialMaxData).To(Equal(protocol.ByteCount(2000)))
+			Expect(params.MaxBidiStreamNum).To(Equal(protocol.StreamNum(7)))
+			Expect(params.MaxUniStreamNum).To(Equal(protocol.StreamNum(8)))
+			Expect(params.MaxIdleTimeout).To(Equal(42 * time.Second))
+			Expect(params.MaxDatagramFrameSize).To(Equal(protocol.ByteCount(wire.MaxDatagramSize)))
+			Expect(conn.config.EnableDatagrams).To(BeTrue())
+			Expect(conn.config.RequireDatagrams).To(BeTrue())
+			Expect(conn.config.InitialConnectionReceiveWindow).To(BeEquivalentTo(2000))
+			Expect(conn.config.HandshakeIdleTimeout).ToNot(Equal(time.Hour))
+		})
+
+		It("populates the Config", func() {
+			params := &wire.TransportParameters{}
+			streamManager.EXPECT().SetInitialMaxIncomingStreams(uint64(protocol.DefaultMaxIncomingStreams), uint64(protocol.DefaultMaxIncomingUniStreams))
+			conf := &Config{MaxStreamReceiveWindow: quicvarint.Max + 1}
+			Expect(conn.applyConfigForClientHello(conf, params)).To(Succeed())
+			Expect(params.InitialMaxData).To(Equal(protocol.ByteCount(protocol.DefaultInitialMaxData)))
+			Expect(conn.config.MaxStreamReceiveWindow).To(BeEquivalentTo(quicvarint.Max))
+			// the application's Config is not modified
+			Expect(conf.MaxStreamReceiveWindow).To(BeEquivalentTo(quicvarint.Max + 1))
+		})
+
+		It("rejects an invalid Config", func() {
+			conn.config.GetConfigForClientHello = func(*ClientHelloInfo) (*Config, error) {
+				return &Config{EnableExperimentalDatagramFlowControl: true}, nil
+			}
+			params := &wire.TransportParameters{InitialMaxData: 1337}
+			tlsConf := conn.handleClientHello(&tls.Config{}, params, false)
+			_, err := tlsConf.GetConfigForClient(&tls.ClientHelloInfo{})
+			Expect(err).To(MatchError("invalid Config returned by GetConfigForClientHello: datagram flow control requires datagrams to be enabled"))
+			Expect(params.InitialMaxData).To(Equal(protocol.ByteCount(1337)))
+			Expect(conn.config.EnableExperimentalDatagramFlowControl).To(BeFalse())
+		})
+	})
+
+	It("refuses to migrate a server connection", func() {
+		Expect(conn.MigrateTo(&net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})).To(MatchError("only the client can migrate a connection"))
+	})
+
+	It("refuses to probe a path on a server connection", func() {
+		_, err := conn.ProbePath(&net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)}, nil)
+		Expect(err).To(MatchError("only the client can probe a path"))
+	})
+
+	It("refuses to add a path on a server connection", func() {
+		_, err := conn.AddPath(&net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)}, nil)
+		Expect(err).To(MatchError("only the client can add a path"))
+	})
 })
 
 var _ = Describe("Client Connection", func() {
@@ -2574,6 +4179,7 @@ var _ = Describe("Client Connection", func() {
 		tracer.EXPECT().SentTransportParameters(gomock.Any())
 		tracer.EXPECT().UpdatedKeyFromTLS(gomock.Any(), gomock.Any()).AnyTimes()
 		tracer.EXPECT().UpdatedCongestionState(gomock.Any())
+		tracer.EXPECT().SentUDPDatagram(gomock.Any(), gomock.Any()).AnyTimes()
 		conn = newClientConnection(
 			context.Background(),
 			mconn,
@@ -2644,6 +4250,37 @@ var _ = Describe("Client Connection", func() {
 		time.Sleep(200 * time.Millisecond)
 	})
 
+	It("calls OnVersionNegotiated when receiving the first packet from the server", func() {
+		var versions []protocol.Version
+		conn.config.OnVersionNegotiated = func(v protocol.Version) { versions = append(versions, v) }
+		var pn protocol.PacketNumber
+		unpacker := NewMockUnpacker(mockCtrl)
+		unpacker.EXPECT().UnpackLongHeader(gomock.Any(), gomock.Any(), gomock.Any(), conn.version).DoAndReturn(func(hdr *wire.Header, _ time.Time, data []byte, _ protocol.Version) (*unpackedPacket, error) {
+			pn++
+			return &unpackedPacket{
+				encryptionLevel: protocol.EncryptionHandshake,
+				hdr:             &wire.ExtendedHeader{Header: *hdr, PacketNumber: pn},
+				data:            []byte{0}, // one PADDING frame
+			}, nil
+		}).Times(2)
+		conn.unpacker = unpacker
+		tracer.EXPECT().ReceivedLongHeaderPacket(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(2)
+		for i := 0; i < 2; i++ {
+			p := getPacket(&wire.ExtendedHeader{
+				Header: wire.Header{
+					Type:             protocol.PacketTypeHandshake,
+					SrcConnectionID:  destConnID,
+					DestConnectionID: srcConnID,
+					Length:           2 + 6,
+					Version:          conn.version,
+				},
+				PacketNumberLen: protocol.PacketNumberLen2,
+			}, []byte("foobar"))
+			Expect(conn.handlePacketImpl(p)).To(BeTrue())
+		}
+		Expect(versions).To(Equal([]protocol.Version{protocol.Version1}))
+	})
+
 	It("continues accepting Long Header packets after using a new connection ID", func() {
 		unpacker := NewMockUnpacker(mockCtrl)
 		conn.unpacker = unpacker
@@ -2717,6 +4354,356 @@ var _ = Describe("Client Connection", func() {
 		Eventually(areConnsRunning).Should(BeFalse())
 	})
 
+	Context("migrating to a new path", func() {
+		newLocalAddr := &net.UDPAddr{IP: net.IPv4(192, 168, 0, 1), Port: 4242}
+		newConnID := protocol.ParseConnectionID([]byte{0xde, 0xca, 0xfb, 0xad})
+		newConnID2 := protocol.ParseConnectionID([]byte{0xde, 0xca, 0xfb, 0xae})
+		var (
+			pathConn *MockSendConn
+			sph      *mockackhandler.MockSentPacketHandler
+		)
+
+		newPathMigration := func() *pathMigration {
+			pathConn = NewMockSendConn(mockCtrl)
+			pathConn.EXPECT().capabilities().AnyTimes()
+			pathConn.EXPECT().LocalAddr().Return(newLocalAddr).AnyTimes()
+			pathConn.EXPECT().RemoteAddr().Return(&net.UDPAddr{}).AnyTimes()
+			return &pathMigration{runner: connRunner, conn: pathConn, result: make(chan error, 1)}
+		}
+
+		expectPathChallenge := func() *[8]byte {
+			var data [8]byte
+			packer.EXPECT().PackPathProbePacket(newConnID, gomock.Any(), protocol.ByteCount(protocol.MinInitialPacketSize), conn.version).DoAndReturn(
+				func(_ protocol.ConnectionID, f ackhandler.Frame, _ protocol.ByteCount, _ protocol.Version) (shortHeaderPacket, *packetBuffer, error) {
+					Expect(f.Frame).To(BeAssignableToTypeOf(&wire.PathChallengeFrame{}))
+					data = f.Frame.(*wire.PathChallengeFrame).Data
+					buf := getPacketBuffer()
+					buf.Data = append(buf.Data, "probe"...)
+					return shortHeaderPacket{PacketNumber: 10, Frames: []ackhandler.Frame{f}, Length: 5, DestConnID: newConnID}, buf, nil
+				},
+			)
+			tracer.EXPECT().SentShortHeaderPacket(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any())
+			sph.EXPECT().SentPacket(gomock.Any(), protocol.PacketNumber(10), protocol.InvalidPacketNumber, gomock.Any(), gomock.Any(), protocol.Encryption1RTT, gomock.Any(), protocol.ByteCount(5), true)
+			pathConn.EXPECT().Write([]byte("probe"), uint16(0), protocol.ECNUnsupported)
+			return &data
+		}
+
+		JustBeforeEach(func() {
+			sph = mockackhandler.NewMockSentPacketHandler(mockCtrl)
+			conn.sentPacketHandler = sph
+			conn.handshakeConfirmed = true
+			conn.peerParams = &wire.TransportParameters{}
+		})
+
+		It("refuses to migrate before the handshake is confirmed", func() {
+			conn.handshakeConfirmed = false
+			m := newPathMigration()
+			conn.startPathMigration(m, time.Now())
+			Expect(<-m.result).To(MatchError("cannot migrate before the handshake is confirmed"))
+		})
+
+		It("refuses to migrate if the peer disabled active migration", func() {
+			conn.peerParams = &wire.TransportParameters{DisableActiveMigration: true}
+			m := newPathMigration()
+			conn.startPathMigration(m, time.Now())
+			Expect(<-m.result).To(MatchError(ErrActiveMigrationDisabled))
+		})
+
+		It("migrates to the preferred address if the peer disabled active migration", func() {
+			conn.peerParams = &wire.TransportParameters{DisableActiveMigration: true}
+			Expect(conn.handleNewConnectionIDFrame(&wire.NewConnectionIDFrame{SequenceNumber: 1, ConnectionID: newConnID})).To(Succeed())
+			m := newPathMigration()
+			m.preferredAddress = true
+			expectPathChallenge()
+			conn.startPathMigration(m, time.Now())
+			Expect(m.result).ToNot(Receive())
+			Expect(conn.pathMigration).To(Equal(m))
+		})
+
+		It("refuses to migrate if there's no unused connection ID", func() {
+			m := newPathMigration()
+			conn.startPathMigration(m, time.Now())
+			Expect(<-m.result).To(MatchError("peer didn't provide an unused connection ID"))
+		})
+
+		It("validates the new path and switches to it", func() {
+			Expect(conn.handleNewConnectionIDFrame(&wire.NewConnectionIDFrame{SequenceNumber: 1, ConnectionID: newConnID})).To(Succeed())
+			m := newPathMigration()
+			data := expectPathChallenge()
+			conn.startPathMigration(m, time.Now())
+			Consistently(m.result).ShouldNot(Receive())
+
+			// a PATH_RESPONSE that doesn't match the PATH_CHALLENGE is ignored
+			Expect(conn.handleFrame(&wire.PathResponseFrame{Data: [8]byte{1, 2, 3}}, protocol.Encryption1RTT, protocol.ConnectionID{})).To(Succeed())
+			Expect(m.result).ToNot(Receive())
+
+			sender := NewMockSender(mockCtrl)
+			conn.sendQueue = sender
+			sender.EXPECT().Close()
+			connRunner.EXPECT().AddResetToken(gomock.Any(), gomock.Any())
+			sph.EXPECT().MigratedPath()
+			tracer.EXPECT().MigratedConnection(newLocalAddr, &net.UDPAddr{})
+			Expect(conn.handleFrame(&wire.PathResponseFrame{Data: *data}, protocol.Encryption1RTT, protocol.ConnectionID{})).To(Succeed())
+			Expect(m.result).To(Receive(BeNil()))
+			Expect(conn.LocalAddr()).To(Equal(newLocalAddr))
+			Expect(conn.connIDManager.Get()).To(Equal(newConnID))
+			// the old connection ID is retired
+			frames, _ := conn.framer.AppendControlFrames(nil, 1000, protocol.Version1)
+			Expect(frames).To(ContainElement(ackhandler.Frame{Frame: &wire.RetireConnectionIDFrame{SequenceNumber: 0}}))
+			conn.sendQueue.Close()
+		})
+
+		It("retransmits the PATH_CHALLENGE and gives up if validation doesn't complete", func() {
+			Expect(conn.handleNewConnectionIDFrame(&wire.NewConnectionIDFrame{SequenceNumber: 1, ConnectionID: newConnID})).To(Succeed())
+			m := newPathMigration()
+			expectPathChallenge()
+			now := time.Now()
+			conn.startPathMigration(m, now)
+			Expect(m.nextProbe).To(Equal(now.Add(conn.rttStats.PTO(true))))
+			Expect(m.deadline).To(Equal(now.Add(3 * conn.rttStats.PTO(true))))
+
+			expectPathChallenge()
+			conn.maybeSendPathChallenge(m.nextProbe)
+			Expect(m.challenges).To(HaveLen(2))
+			Expect(m.result).ToNot(Receive())
+
+			conn.maybeSendPathChallenge(m.deadline)
+			Expect(m.result).To(Receive(MatchError("path validation timed out")))
+			Expect(conn.pathMigration).To(BeNil())
+			Expect(conn.LocalAddr()).ToNot(Equal(newLocalAddr))
+		})
+
+		It("registers with the Transport of the new path while validating it", func() {
+			Expect(conn.handleNewConnectionIDFrame(&wire.NewConnectionIDFrame{SequenceNumber: 1, ConnectionID: newConnID})).To(Succeed())
+			m := newPathMigration()
+			pathRunner := NewMockConnRunner(mockCtrl)
+			m.runner = pathRunner
+			// packets received on the new path are routed to this connection
+			pathRunner.EXPECT().Add(srcConnID, conn).Return(true)
+			expectPathChallenge()
+			conn.startPathMigration(m, time.Now())
+			Expect(conn.runners).To(Equal(connRunners{connRunner, pathRunner}))
+
+			pathRunner.EXPECT().Remove(srcConnID)
+			conn.maybeSendPathChallenge(m.deadline)
+			Expect(m.result).To(Receive(MatchError("path validation timed out")))
+			Expect(conn.runners).To(Equal(connRunners{connRunner}))
+		})
+
+		It("probes a path without switching to it", func() {
+			Expect(conn.handleNewConnectionIDFrame(&wire.NewConnectionIDFrame{SequenceNumber: 1, ConnectionID: newConnID})).To(Succeed())
+			m := newPathMigration()
+			pathRunner := NewMockConnRunner(mockCtrl)
+			m.runner = pathRunner
+			m.probeOnly = true
+			pathRunner.EXPECT().Add(srcConnID, conn).Return(true)
+			data := expectPathChallenge()
+			conn.startPathMigration(m, time.Now().Add(-10*time.Millisecond))
+			Expect(m.result).ToNot(Receive())
+
+			// the Transport of the probed path isn't used anymore
+			pathRunner.EXPECT().Remove(srcConnID)
+			Expect(conn.handleFrame(&wire.PathResponseFrame{Data: *data}, protocol.Encryption1RTT, protocol.ConnectionID{})).To(Succeed())
+			Expect(m.result).To(Receive(BeNil()))
+			Expect(m.rtt).To(BeNumerically(">=", 10*time.Millisecond))
+			Expect(conn.runners).To(Equal(connRunners{connRunner}))
+			Expect(conn.pathMigration).To(BeNil())
+			Expect(conn.LocalAddr()).ToNot(Equal(newLocalAddr))
+			Expect(conn.connIDManager.Get()).ToNot(Equal(newConnID))
+			// PATH_RESPONSEs for retransmitted PATH_CHALLENGEs are ignored
+			Expect(conn.handleFrame(&wire.PathResponseFrame{Data: *data}, protocol.Encryption1RTT, protocol.ConnectionID{})).To(Succeed())
+			Expect(m.result).ToNot(Receive())
+		})
+
+		Context("backup paths", func() {
+			var backupRunner *MockConnRunner
+
+			addBackupPath := func() *backupPath {
+				Expect(conn.handleNewConnectionIDFrame(&wire.NewConnectionIDFrame{SequenceNumber: 1, ConnectionID: newConnID})).To(Succeed())
+				m := newPathMigration()
+				backupRunner = NewMockConnRunner(mockCtrl)
+				backupRunner.EXPECT().Add(srcConnID, conn).Return(true)
+				m.transport = &Transport{}
+				m.runner = backupRunner
+				m.probeOnly = true
+				m.backup = &backupPath{transport: m.transport, runner: m.runner, conn: m.conn}
+				data := expectPathChallenge()
+				conn.startPathMigration(m, time.Now())
+				Expect(conn.handleFrame(&wire.PathResponseFrame{Data: *data}, protocol.Encryption1RTT, protocol.ConnectionID{})).To(Succeed())
+				Expect(m.result).To(Receive(BeNil()))
+				return m.backup
+			}
+
+			It("adds a backup path", func() {
+				b := addBackupPath()
+				Expect(b.id).To(Equal(PathID(1)))
+				Expect(conn.backupPath).To(Equal(b))
+				Expect(b.nextValidation).To(BeTemporally("~", time.Now().Add(conn.backupPathValidationInterval()), scaleDuration(10*time.Millisecond)))
+				// the connection keeps using the current path
+				Expect(conn.LocalAddr()).ToNot(Equal(newLocalAddr))
+				Expect(conn.connIDManager.Get()).ToNot(Equal(newConnID))
+				// the connection ID is reserved for the backup path
+				connID, ok := conn.connIDManager.Reserved()
+				Expect(ok).To(BeTrue())
+				Expect(connID).To(Equal(newConnID))
+
+				m := newPathMigration()
+				m.backup = &backupPath{}
+				conn.startPathMigration(m, time.Now())
+				Expect(m.result).To(Receive(MatchError("only a single backup path is supported")))
+			})
+
+			It("retires the connection ID if validation of the backup path fails", func() {
+				Expect(conn.handleNewConnectionIDFrame(&wire.NewConnectionIDFrame{SequenceNumber: 1, ConnectionID: newConnID})).To(Succeed())
+				m := newPathMigration()
+				m.probeOnly = true
+				m.backup = &backupPath{conn: m.conn}
+				expectPathChallenge()
+				conn.startPathMigration(m, time.Now())
+				conn.maybeSendPathChallenge(m.deadline)
+				Expect(m.result).To(Receive(MatchError("path validation timed out")))
+				Expect(conn.backupPath).To(BeNil())
+				_, ok := conn.connIDManager.Reserved()
+				Expect(ok).To(BeFalse())
+				frames, _ := conn.framer.AppendControlFrames(nil, 1000, protocol.Version1)
+				Expect(frames).To(ContainElement(ackhandler.Frame{Frame: &wire.RetireConnectionIDFrame{SequenceNumber: 1}}))
+			})
+
+			It("revalidates the backup path", func() {
+				b := addBackupPath()
+				now := time.Now()
+				data := expectPathChallenge()
+				conn.revalidateBackupPath(now)
+				Expect(conn.pathMigration).ToNot(BeNil())
+				Expect(conn.pathMigration.backup).To(Equal(b))
+				Expect(conn.handleFrame(&wire.PathResponseFrame{Data: *data}, protocol.Encryption1RTT, protocol.ConnectionID{})).To(Succeed())
+				Expect(conn.pathMigration).To(BeNil())
+				Expect(conn.backupPath).To(Equal(b))
+				Expect(b.nextValidation).To(BeTemporally(">", now))
+
+				// the backup path is kept if revalidation fails
+				expectPathChallenge()
+				conn.revalidateBackupPath(now)
+				conn.maybeSendPathChallenge(conn.pathMigration.deadline)
+				Expect(conn.pathMigration).To(BeNil())
+				Expect(conn.backupPath).To(Equal(b))
+				connID, ok := conn.connIDManager.Reserved()
+				Expect(ok).To(BeTrue())
+				Expect(connID).To(Equal(newConnID))
+			})
+
+			It("cancels revalidation of the backup path when migrating", func() {
+				addBackupPath()
+				expectPathChallenge()
+				conn.revalidateBackupPath(time.Now())
+				Expect(conn.pathMigration).ToNot(BeNil())
+
+				Expect(conn.handleNewConnectionIDFrame(&wire.NewConnectionIDFrame{SequenceNumber: 2, ConnectionID: newConnID2})).To(Succeed())
+				m := newPathMigration()
+				packer.EXPECT().PackPathProbePacket(newConnID2, gomock.Any(), gomock.Any(), conn.version).Return(shortHeaderPacket{}, nil, errors.New("test error"))
+				conn.startPathMigration(m, time.Now())
+				Expect(m.result).To(Receive(MatchError("test error")))
+			})
+
+			It("removes the backup path", func() {
+				addBackupPath()
+				Expect(conn.handlePathRequest(&pathRequest{id: 2, typ: pathRequestRemove})).To(MatchError("unknown path: 2"))
+				backupRunner.EXPECT().Remove(srcConnID)
+				Expect(conn.handlePathRequest(&pathRequest{id: 1, typ: pathRequestRemove})).To(Succeed())
+				Expect(conn.backupPath).To(BeNil())
+				_, ok := conn.connIDManager.Reserved()
+				Expect(ok).To(BeFalse())
+				Expect(conn.handlePathRequest(&pathRequest{id: 1, typ: pathRequestRemove})).To(MatchError("unknown path: 1"))
+			})
+
+			It("reports the status of the backup path", func() {
+				b := addBackupPath()
+				r := &pathRequest{id: 1, typ: pathRequestStatus}
+				Expect(conn.handlePathRequest(r)).To(Succeed())
+				Expect(r.info.Validated).To(BeTrue())
+				Expect(r.info.RTT).To(Equal(b.rtt))
+				Expect(conn.handlePathRequest(&pathRequest{id: 2, typ: pathRequestStatus})).To(MatchError("unknown path: 2"))
+
+				// revalidation fails
+				expectPathChallenge()
+				conn.revalidateBackupPath(time.Now())
+				conn.maybeSendPathChallenge(conn.pathMigration.deadline)
+				r = &pathRequest{id: 1, typ: pathRequestStatus}
+				Expect(conn.handlePathRequest(r)).To(Succeed())
+				Expect(r.info.Validated).To(BeFalse())
+			})
+
+			It("switches to the backup path", func() {
+				addBackupPath()
+				// revalidation of the backup path is in progress
+				expectPathChallenge()
+				conn.revalidateBackupPath(time.Now())
+				Expect(conn.pathMigration).ToNot(BeNil())
+
+				sender := NewMockSender(mockCtrl)
+				conn.sendQueue = sender
+				sender.EXPECT().Close()
+				connRunner.EXPECT().AddResetToken(gomock.Any(), gomock.Any())
+				backupRunner.EXPECT().AddResetToken(gomock.Any(), gomock.Any())
+				sph.EXPECT().MigratedPath()
+				tracer.EXPECT().MigratedConnection(newLocalAddr, &net.UDPAddr{})
+				Expect(conn.handlePathRequest(&pathRequest{id: 1, typ: pathRequestSwitch})).To(Succeed())
+				Expect(conn.LocalAddr()).To(Equal(newLocalAddr))
+				Expect(conn.connIDManager.Get()).To(Equal(newConnID))
+				Expect(conn.pathRunner).To(Equal(backupRunner))
+				Expect(conn.pathMigration).To(BeNil())
+				Expect(conn.backupPath).To(BeNil())
+				_, ok := conn.connIDManager.Reserved()
+				Expect(ok).To(BeFalse())
+				// the old connection ID is retired
+				frames, _ := conn.framer.AppendControlFrames(nil, 1000, protocol.Version1)
+				Expect(frames).To(ContainElement(ackhandler.Frame{Frame: &wire.RetireConnectionIDFrame{SequenceNumber: 0}}))
+				conn.sendQueue.Close()
+			})
+
+			It("refuses to switch to a backup path that failed revalidation", func() {
+				addBackupPath()
+				expectPathChallenge()
+				conn.revalidateBackupPath(time.Now())
+				conn.maybeSendPathChallenge(conn.pathMigration.deadline)
+				Expect(conn.handlePathRequest(&pathRequest{id: 1, typ: pathRequestSwitch})).To(MatchError("backup path failed validation"))
+				Expect(conn.LocalAddr()).ToNot(Equal(newLocalAddr))
+				Expect(conn.backupPath).ToNot(BeNil())
+			})
+
+			It("refuses to switch to the backup path if the peer retired its connection ID", func() {
+				addBackupPath()
+				// the active connection ID is retired as well
+				connRunner.EXPECT().AddResetToken(gomock.Any(), gomock.Any())
+				backupRunner.EXPECT().AddResetToken(gomock.Any(), gomock.Any())
+				Expect(conn.handleNewConnectionIDFrame(&wire.NewConnectionIDFrame{
+					SequenceNumber: 2,
+					ConnectionID:   newConnID2,
+					RetirePriorTo:  2,
+				})).To(Succeed())
+				Expect(conn.handlePathRequest(&pathRequest{id: 1, typ: pathRequestSwitch})).To(MatchError("peer didn't provide an unused connection ID"))
+				Expect(conn.backupPath).ToNot(BeNil())
+			})
+		})
+
+		It("measures the RTT of a probe from the PATH_CHALLENGE that was responded to", func() {
+			Expect(conn.handleNewConnectionIDFrame(&wire.NewConnectionIDFrame{SequenceNumber: 1, ConnectionID: newConnID})).To(Succeed())
+			m := newPathMigration()
+			m.probeOnly = true
+			expectPathChallenge()
+			conn.startPathMigration(m, time.Now().Add(-time.Hour))
+			m.deadline = time.Now().Add(time.Hour)
+			data := expectPathChallenge()
+			conn.maybeSendPathChallenge(time.Now().Add(-10 * time.Millisecond))
+			Expect(conn.handleFrame(&wire.PathResponseFrame{Data: *data}, protocol.Encryption1RTT, protocol.ConnectionID{})).To(Succeed())
+			Expect(m.result).To(Receive(BeNil()))
+			Expect(m.rtt).To(And(
+				BeNumerically(">=", 10*time.Millisecond),
+				BeNumerically("<", time.Hour),
+			))
+		})
+	})
+
 	Context("handling tokens", func() {
 		var mockTokenStore *MockTokenStore
 
@@ -2734,6 +4721,33 @@ var _ = Describe("Client Connection", func() {
 		})
 	})
 
+	Context("handling tokens using the application's callbacks", func() {
+		var storedTokens [][]byte
+		var tokenRequestedFor []net.Addr
+
+		BeforeEach(func() {
+			storedTokens = nil
+			tokenRequestedFor = nil
+			// the TokenStore is not used if the callbacks are set
+			quicConf.TokenStore = NewMockTokenStore(mockCtrl)
+			quicConf.StoreNewToken = func(token []byte) { storedTokens = append(storedTokens, token) }
+			quicConf.GetStoredToken = func(remote net.Addr) []byte {
+				tokenRequestedFor = append(tokenRequestedFor, remote)
+				return []byte("token")
+			}
+		})
+
+		It("gets the token when dialing", func() {
+			Expect(tokenRequestedFor).To(Equal([]net.Addr{&net.UDPAddr{}}))
+		})
+
+		It("passes tokens from NEW_TOKEN frames to the application", func() {
+			Expect(conn.handleNewTokenFrame(&wire.NewTokenFrame{Token: []byte("foo")})).To(Succeed())
+			Expect(conn.handleNewTokenFrame(&wire.NewTokenFrame{Token: []byte("bar")})).To(Succeed())
+			Expect(storedTokens).To(Equal([][]byte{[]byte("foo"), []byte("bar")}))
+		})
+	})
+
 	Context("handling Version Negotiation", func() {
 		getVNP := func(versions ...protocol.Version) receivedPacket {
 			b := wire.ComposeVersionNegotiation(
@@ -2967,6 +4981,10 @@ var _ = Describe("Client Connection", func() {
 			tracer.EXPECT().ReceivedTransportParameters(params).Do(func(*wire.TransportParameters) { close(processed) })
 			paramsChan <- params
 			Eventually(processed).Should(BeClosed())
+			Expect(conn.PeerPreferredAddress()).To(Equal(&PreferredAddress{
+				IPv4: netip.AddrPortFrom(netip.AddrFrom4([4]byte{127, 0, 0, 1}), 42),
+				IPv6: netip.AddrPortFrom(netip.AddrFrom16([16]byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16}), 13),
+			}))
 			// make sure the connection ID is not retired
 			cf, _ := conn.framer.AppendControlFrames(nil, protocol.MaxByteCount, protocol.Version1)
 			Expect(cf).To(BeEmpty())
@@ -2977,6 +4995,29 @@ var _ = Describe("Client Connection", func() {
 			expectClose(true, false)
 		})
 
+		It("doesn't report unspecified preferred addresses", func() {
+			params := &wire.TransportParameters{
+				OriginalDestinationConnectionID: destConnID,
+				InitialSourceConnectionID:       destConnID,
+				PreferredAddress: &wire.PreferredAddress{
+					IPv4:                netip.AddrPortFrom(netip.AddrFrom4([4]byte{127, 0, 0, 1}), 42),
+					IPv6:                netip.AddrPortFrom(netip.IPv6Unspecified(), 0),
+					ConnectionID:        protocol.ParseConnectionID([]byte{1, 2, 3, 4}),
+					StatelessResetToken: protocol.StatelessResetToken{16, 15, 14, 13, 12, 11, 10, 9, 8, 7, 6, 5, 4, 3, 2, 1},
+				},
+			}
+			Expect(conn.PeerPreferredAddress()).To(BeNil())
+			packer.EXPECT().PackCoalescedPacket(false, gomock.Any(), conn.version).MaxTimes(1)
+			processed := make(chan struct{})
+			tracer.EXPECT().ReceivedTransportParameters(params).Do(func(*wire.TransportParameters) { close(processed) })
+			paramsChan <- params
+			Eventually(processed).Should(BeClosed())
+			Expect(conn.PeerPreferredAddress()).To(Equal(&PreferredAddress{
+				IPv4: netip.AddrPortFrom(netip.AddrFrom4([4]byte{127, 0, 0, 1}), 42),
+			}))
+			expectClose(true, false)
+		})
+
 		It("uses the minimum of the peers' idle timeouts", func() {
 			conn.config.MaxIdleTimeout = 19 * time.Second
 			params := &wire.TransportParameters{
@@ -3013,6 +5054,25 @@ var _ = Describe("Client Connection", func() {
 			})))
 		})
 
+		It("errors if datagrams are required, but the server doesn't support them", func() {
+			conn.config.EnableDatagrams = true
+			conn.config.RequireDatagrams = true
+			params := &wire.TransportParameters{
+				OriginalDestinationConnectionID: destConnID,
+				InitialSourceConnectionID:       destConnID,
+				MaxDatagramFrameSize:            protocol.InvalidByteCount,
+			}
+			expectClose(false, true)
+			processed := make(chan struct{})
+			tracer.EXPECT().ReceivedTransportParameters(params).Do(func(*wire.TransportParameters) { close(processed) })
+			paramsChan <- params
+			Eventually(processed).Should(BeClosed())
+			Eventually(errChan).Should(Receive(MatchError(&qerr.TransportError{
+				ErrorCode:    qerr.ConnectionRefused,
+				ErrorMessage: "datagrams required, but not supported by the peer",
+			})))
+		})
+
 		It("errors if the transport parameters don't contain the retry_source_connection_id, if a Retry was performed", func() {
 			rcid := protocol.ParseConnectionID([]byte{0xde, 0xad, 0xbe, 0xef})
 			conn.retrySrcConnID = &rcid
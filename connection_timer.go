@@ -13,8 +13,8 @@ type connectionTimer struct {
 	last  time.Time
 }
 
-func newTimer() *connectionTimer {
-	return &connectionTimer{timer: utils.NewTimer()}
+func newTimer(clock utils.Clock) *connectionTimer {
+	return &connectionTimer{timer: utils.NewTimerWithClock(clock)}
 }
 
 func (t *connectionTimer) SetRead() {
@@ -2,8 +2,12 @@ package quic
 
 import (
 	"context"
+	"errors"
 	"sync"
+	"sync/atomic"
+	"time"
 
+	"github.com/quic-go/quic-go/internal/ackhandler"
 	"github.com/quic-go/quic-go/internal/utils"
 	"github.com/quic-go/quic-go/internal/utils/ringbuffer"
 	"github.com/quic-go/quic-go/internal/wire"
@@ -14,45 +18,287 @@ const (
 	maxDatagramRcvQueueLen  = 128
 )
 
+// ErrDatagramQueueFull is returned when trying to queue a DATAGRAM frame without blocking,
+// but the send queue is full.
+var ErrDatagramQueueFull = errors.New("DATAGRAM send queue full")
+
+var errDatagramQueueDraining = errors.New("DATAGRAM send queue is draining")
+
+type queuedDatagram struct {
+	frame  *wire.DatagramFrame
+	expiry time.Time // zero if the frame never expires
+	onSent func(error)
+}
+
+func (d *queuedDatagram) done(err error) {
+	if d.onSent != nil {
+		d.onSent(err)
+	}
+}
+
+type receivedDatagram struct {
+	data    []byte
+	rcvTime time.Time     // only set if the receive time is recorded
+	buf     *packetBuffer // the packet buffer that data references, only set in zero-copy mode
+}
+
+// A DatagramBuffer is a datagram received without copying it out of the packet it was received in.
+// The buffer must be released by calling Release once the application is done with it. Afterwards, the
+// payload returned by Data must not be used any more, since the underlying memory is reused for other packets.
+type DatagramBuffer struct {
+	data  []byte
+	buf   *packetBuffer
+	queue *datagramQueue
+}
+
+// Data returns the payload of the datagram.
+// It must not be modified, and must not be used after calling Release.
+func (b *DatagramBuffer) Data() []byte { return b.data }
+
+// Release releases the buffer. It must be called exactly once.
+func (b *DatagramBuffer) Release() {
+	if b.buf != nil {
+		b.queue.releaseBuffer(b.buf)
+		b.buf = nil
+	}
+	b.data = nil
+}
+
 type datagramQueue struct {
-	sendMx    sync.Mutex
-	sendQueue ringbuffer.RingBuffer[*wire.DatagramFrame]
-	sent      chan struct{} // used to notify Add that a datagram was dequeued
+	sendMx       sync.Mutex
+	sendQueue    ringbuffer.RingBuffer[*queuedDatagram]
+	prioQueue    ringbuffer.RingBuffer[*queuedDatagram] // always drained before the sendQueue
+	peeked       *queuedDatagram                        // the frame returned by the last call to Peek
+	sent         chan struct{}                          // used to notify Add that a datagram was dequeued
+	sendQueueLen int
+	sendTimeout  atomic.Int64 // a time.Duration, can be updated using SetSendTimeout
+
+	// Flow control, see EnableFlowControl.
+	// On the sending side, maxDatagrams is the number of DATAGRAM frames the peer allows us to send,
+	// and numSent is the number of frames sent so far (minus the frames that were declared lost).
+	fcEnabled    bool
+	maxDatagrams uint64
+	numSent      uint64
+
+	draining chan struct{} // closed when CloseAfterDrain is called
+	drained  chan struct{} // closed when the send queue is empty after CloseAfterDrain was called
+
+	rcvMx       sync.Mutex
+	rcvQueue    []receivedDatagram
+	rcvQueueLen int
+	rcvd        chan struct{} // used to notify Receive that a new datagram was received
+	// On the receiving side, numReceived is the number of DATAGRAM frames received within the limit,
+	// numConsumed is the number of those frames that were removed from the receive queue
+	// (either by the application or because the queue was full),
+	// and rcvMaxDatagrams is the limit last advertised to the peer.
+	numReceived       uint64
+	numConsumed       uint64
+	rcvMaxDatagrams   uint64
+	queueControlFrame func(wire.Frame) // only set if flow control is enabled
 
-	rcvMx    sync.Mutex
-	rcvQueue [][]byte
-	rcvd     chan struct{} // used to notify Receive that a new datagram was received
+	recordRcvTime bool
+	zeroCopy      bool
+
+	// packet buffers released by the application, waiting to be returned to the pool by the run loop
+	releasedMx  sync.Mutex
+	released    []*packetBuffer
+	numReleased atomic.Int64
+
+	dropped atomic.Uint64 // number of dropped received DATAGRAM frames, see DroppedDatagrams
+	onDrop  func(length int)
 
 	closeErr error
 	closed   chan struct{}
 
 	hasData func()
 
+	clock  utils.Clock
 	logger utils.Logger
 }
 
-func newDatagramQueue(hasData func(), logger utils.Logger) *datagramQueue {
-	return &datagramQueue{
-		hasData: hasData,
-		rcvd:    make(chan struct{}, 1),
-		sent:    make(chan struct{}, 1),
-		closed:  make(chan struct{}),
-		logger:  logger,
+// newDatagramQueue creates a new datagramQueue.
+// Up to sendQueueLen DATAGRAM frames are queued for sending. If sendQueueLen is 0, it defaults to 32.
+// Up to rcvQueueLen received DATAGRAM frames are queued until they are read by the application.
+// If rcvQueueLen is 0, it defaults to 128.
+// If sendTimeout is non-zero, DATAGRAM frames that have been queued for longer than sendTimeout
+// are dropped instead of being sent.
+// If set, onDrop is called with the payload length of every received DATAGRAM frame
+// that is dropped because the receive queue is full, or because it exceeds the flow control limit.
+// If recordRcvTime is set, the receive time of every queued DATAGRAM frame is stored,
+// and returned by ReceiveWithTime.
+// If zeroCopy is set, received DATAGRAM frames reference the packet buffer they were received in,
+// instead of being copied. The packet buffer is retained until the application releases the frame.
+// The clock is used to determine when queued frames expire.
+func newDatagramQueue(
+	hasData func(),
+	sendQueueLen int,
+	rcvQueueLen int,
+	sendTimeout time.Duration,
+	onDrop func(length int),
+	recordRcvTime bool,
+	zeroCopy bool,
+	clock utils.Clock,
+	logger utils.Logger,
+) *datagramQueue {
+	if sendQueueLen <= 0 {
+		sendQueueLen = maxDatagramSendQueueLen
+	}
+	if rcvQueueLen <= 0 {
+		rcvQueueLen = maxDatagramRcvQueueLen
+	}
+	q := &datagramQueue{
+		hasData:       hasData,
+		sendQueueLen:  sendQueueLen,
+		rcvQueueLen:   rcvQueueLen,
+		onDrop:        onDrop,
+		recordRcvTime: recordRcvTime,
+		zeroCopy:      zeroCopy,
+		rcvd:          make(chan struct{}, 1),
+		sent:          make(chan struct{}, 1),
+		draining:      make(chan struct{}),
+		drained:       make(chan struct{}),
+		closed:        make(chan struct{}),
+		clock:         clock,
+		logger:        logger,
+	}
+	q.sendTimeout.Store(int64(sendTimeout))
+	return q
+}
+
+// EnableFlowControl enables flow control for DATAGRAM frames.
+// Once enabled, no more than maxDatagrams frames are sent, until the peer grants more credit using
+// SetMaxDatagrams. On the receiving side, credit is granted to the peer by calling queueControlFrame
+// with a MAX_DATAGRAMS frame whenever the application has consumed enough frames from the receive queue.
+// It must be called from the connection's run loop, before the first frame was sent.
+func (h *datagramQueue) EnableFlowControl(maxDatagrams uint64, queueControlFrame func(wire.Frame)) {
+	h.sendMx.Lock()
+	h.fcEnabled = true
+	h.maxDatagrams = maxDatagrams
+	h.sendMx.Unlock()
+
+	h.rcvMx.Lock()
+	h.queueControlFrame = queueControlFrame
+	h.rcvMaxDatagrams = uint64(h.rcvQueueLen)
+	h.rcvMx.Unlock()
+	h.maybeGrantCredit()
+}
+
+// SetMaxDatagrams handles a MAX_DATAGRAMS frame received from the peer.
+// It must be called from the connection's run loop.
+func (h *datagramQueue) SetMaxDatagrams(maxDatagrams uint64) {
+	h.sendMx.Lock()
+	if maxDatagrams <= h.maxDatagrams {
+		h.sendMx.Unlock()
+		return
+	}
+	h.maxDatagrams = maxDatagrams
+	hasData := !h.sendQueue.Empty() || !h.prioQueue.Empty()
+	h.sendMx.Unlock()
+	if hasData {
+		h.hasData()
+	}
+}
+
+// AckHandler returns the handler that needs to be set on sent DATAGRAM frames.
+// It returns nil if flow control is not enabled.
+func (h *datagramQueue) AckHandler() ackhandler.FrameHandler {
+	h.sendMx.Lock()
+	defer h.sendMx.Unlock()
+	if !h.fcEnabled {
+		return nil
+	}
+	return (*datagramQueueAckHandler)(h)
+}
+
+type datagramQueueAckHandler datagramQueue
+
+func (h *datagramQueueAckHandler) OnAcked(wire.Frame) {}
+
+// OnLost returns the credit consumed by the lost frame, since the peer will most likely never receive it.
+// If the loss was spurious, the peer receives the frame after all, and the credit was returned twice.
+// The peer then drops the frame that exceeds its limit without granting credit for it,
+// which brings sender and receiver back in sync.
+func (h *datagramQueueAckHandler) OnLost(wire.Frame) {
+	q := (*datagramQueue)(h)
+	q.sendMx.Lock()
+	if q.numSent > 0 {
+		q.numSent--
+	}
+	hasData := !q.sendQueue.Empty() || !q.prioQueue.Empty()
+	q.sendMx.Unlock()
+	if hasData {
+		q.hasData()
 	}
 }
 
+// SetSendTimeout sets the send timeout for DATAGRAM frames queued after this call.
+// Frames that are already queued keep their expiry time.
+// If d is not positive, frames queued afterwards never expire.
+func (h *datagramQueue) SetSendTimeout(d time.Duration) {
+	h.sendTimeout.Store(int64(d))
+}
+
 // Add queues a new DATAGRAM frame for sending.
-// Up to 32 DATAGRAM frames will be queued.
-// Once that limit is reached, Add blocks until the queue size has reduced.
-func (h *datagramQueue) Add(f *wire.DatagramFrame) error {
+// Once the send queue is full, Add blocks until the queue size has reduced.
+// Add doesn't wait for the frame to be sent. If onSent is set, it is called exactly once,
+// from the send loop when the frame is dequeued (with a nil error),
+// when the frame didn't fit into a packet (with a DatagramTooLargeError),
+// when the frame expired (with a DatagramQueuedTooLong error),
+// or when the queue is closed before the frame was dequeued (with the close error).
+// If Add returns an error, the frame was not queued, and onSent is not called.
+func (h *datagramQueue) Add(f *wire.DatagramFrame, onSent func(error)) error {
+	return h.add(context.Background(), &h.sendQueue, h.newQueuedDatagram(f, onSent))
+}
+
+// AddPriority queues a new high-priority DATAGRAM frame for sending.
+// High-priority frames are always sent before the frames queued using Add.
+// The high-priority queue holds up to the same number of frames as the normal queue,
+// apart from that, AddPriority behaves exactly like Add.
+func (h *datagramQueue) AddPriority(f *wire.DatagramFrame, onSent func(error)) error {
+	return h.add(context.Background(), &h.prioQueue, h.newQueuedDatagram(f, onSent))
+}
+
+// AddAndWaitContext queues a new DATAGRAM frame for sending, and blocks until it was dequeued.
+// It returns a DatagramQueuedTooLong error if the frame expired, a DatagramTooLargeError if it didn't fit into a packet,
+// and the close error if the queue was closed.
+// If the context is canceled before the frame was dequeued, the frame is removed from the queue,
+// and the context's error is returned.
+// If the frame is just being sent out, it waits for the frame to be dequeued instead.
+func (h *datagramQueue) AddAndWaitContext(ctx context.Context, f *wire.DatagramFrame) error {
+	result := make(chan error, 1)
+	d := h.newQueuedDatagram(f, func(err error) { result <- err })
+	if err := h.add(ctx, &h.sendQueue, d); err != nil {
+		return err
+	}
+	select {
+	case err := <-result:
+		return err
+	case <-ctx.Done():
+	}
+
 	h.sendMx.Lock()
+	if d != h.peeked && remove(&h.sendQueue, d) {
+		h.signalSent()
+		h.maybeSignalDrained()
+		h.sendMx.Unlock()
+		return ctx.Err()
+	}
+	h.sendMx.Unlock()
+	// The frame was already dequeued, or it was peeked by the packer.
+	// Make sure the send loop runs, so that the frame is dequeued soon.
+	h.hasData()
+	return <-result
+}
 
+func (h *datagramQueue) add(ctx context.Context, queue *ringbuffer.RingBuffer[*queuedDatagram], d *queuedDatagram) error {
+	h.sendMx.Lock()
 	for {
-		if h.sendQueue.Len() < maxDatagramSendQueueLen {
-			h.sendQueue.PushBack(f)
+		if queued, err := h.tryPush(queue, d); queued || err != nil {
 			h.sendMx.Unlock()
-			h.hasData()
-			return nil
+			if queued {
+				h.hasData()
+			}
+			return err
 		}
 		select {
 		case <-h.sent: // drain the queue so we don't loop immediately
@@ -62,64 +308,348 @@ func (h *datagramQueue) Add(f *wire.DatagramFrame) error {
 		select {
 		case <-h.closed:
 			return h.closeErr
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-h.draining:
 		case <-h.sent:
 		}
 		h.sendMx.Lock()
 	}
 }
 
+// TrySend queues a new DATAGRAM frame for sending.
+// Unlike Add, it doesn't block if the send queue is full, but returns ErrDatagramQueueFull.
+func (h *datagramQueue) TrySend(f *wire.DatagramFrame) error {
+	d := h.newQueuedDatagram(f, nil)
+
+	h.sendMx.Lock()
+	queued, err := h.tryPush(&h.sendQueue, d)
+	h.sendMx.Unlock()
+	if err != nil {
+		return err
+	}
+	if !queued {
+		return ErrDatagramQueueFull
+	}
+	h.hasData()
+	return nil
+}
+
+func (h *datagramQueue) newQueuedDatagram(f *wire.DatagramFrame, onSent func(error)) *queuedDatagram {
+	d := &queuedDatagram{frame: f, onSent: onSent}
+	if sendTimeout := time.Duration(h.sendTimeout.Load()); sendTimeout > 0 {
+		d.expiry = h.clock.Now().Add(sendTimeout)
+	}
+	return d
+}
+
+// tryPush appends the frame to the queue, if the queue is not full.
+// It must be called with the sendMx held.
+func (h *datagramQueue) tryPush(queue *ringbuffer.RingBuffer[*queuedDatagram], d *queuedDatagram) (queued bool, _ error) {
+	select {
+	case <-h.closed:
+		return false, h.closeErr
+	case <-h.draining:
+		return false, errDatagramQueueDraining
+	default:
+	}
+	if queue.Len() >= h.sendQueueLen {
+		return false, nil
+	}
+	queue.PushBack(d)
+	return true, nil
+}
+
+// remove removes a frame from the queue. It returns false if the frame is not queued.
+// It must be called with the sendMx held.
+func remove(queue *ringbuffer.RingBuffer[*queuedDatagram], d *queuedDatagram) bool {
+	var found bool
+	for n := queue.Len(); n > 0; n-- {
+		e := queue.PopFront()
+		if e == d {
+			found = true
+			continue
+		}
+		queue.PushBack(e)
+	}
+	return found
+}
+
 // Peek gets the next DATAGRAM frame for sending.
+// High-priority frames are returned before all other frames.
+// Frames that expired while being queued are dropped.
+// If flow control is enabled, and the peer doesn't allow sending more frames, Peek returns nil.
 // If actually sent out, Pop needs to be called before the next call to Peek.
 func (h *datagramQueue) Peek() *wire.DatagramFrame {
 	h.sendMx.Lock()
-	defer h.sendMx.Unlock()
-	if h.sendQueue.Empty() {
-		return nil
+	expired := h.dropExpired(&h.prioQueue, nil)
+	expired = h.dropExpired(&h.sendQueue, expired)
+	h.peeked = nil
+	if h.fcEnabled && h.numSent >= h.maxDatagrams { // blocked by flow control
+		h.peeked = nil
+	} else if !h.prioQueue.Empty() {
+		h.peeked = h.prioQueue.PeekFront()
+	} else if !h.sendQueue.Empty() {
+		h.peeked = h.sendQueue.PeekFront()
 	}
-	return h.sendQueue.PeekFront()
+	var f *wire.DatagramFrame
+	if h.peeked != nil {
+		f = h.peeked.frame
+	}
+	h.sendMx.Unlock()
+
+	for _, d := range expired {
+		if h.logger.Debug() {
+			h.logger.Debugf("Discarding DATAGRAM frame (%d bytes payload), since it was queued for too long", len(d.frame.Data))
+		}
+		d.done(&DatagramQueuedTooLong{})
+	}
+	return f
 }
 
+// dropExpired removes all expired frames from the front of the queue, and appends them to expired.
+// It must be called with the sendMx held.
+func (h *datagramQueue) dropExpired(queue *ringbuffer.RingBuffer[*queuedDatagram], expired []*queuedDatagram) []*queuedDatagram {
+	n := len(expired)
+	var now time.Time
+	for !queue.Empty() {
+		d := queue.PeekFront()
+		if d.expiry.IsZero() {
+			break
+		}
+		if now.IsZero() {
+			now = h.clock.Now()
+		}
+		if now.Before(d.expiry) {
+			break
+		}
+		expired = append(expired, queue.PopFront())
+	}
+	if len(expired) > n {
+		h.signalSent()
+		h.maybeSignalDrained()
+	}
+	return expired
+}
+
+// Pop removes the frame returned by the last call to Peek from the queue, after it was sent out.
 func (h *datagramQueue) Pop() {
+	h.pop(nil)
+}
+
+// Discard removes the frame returned by the last call to Peek from the queue, without sending it.
+// The frame's onSent callback is called with err.
+// Unlike Pop, it doesn't consume any flow control credit.
+func (h *datagramQueue) Discard(err error) {
+	h.pop(err)
+}
+
+// pop removes the peeked frame from the queue. If err is nil, the frame was sent out.
+func (h *datagramQueue) pop(err error) {
 	h.sendMx.Lock()
-	defer h.sendMx.Unlock()
-	_ = h.sendQueue.PopFront()
+	// A high-priority frame might have been added since the frame was peeked,
+	// so we need to check which queue the peeked frame belongs to.
+	var d *queuedDatagram
+	switch peeked := h.peeked; {
+	case peeked != nil && !h.prioQueue.Empty() && h.prioQueue.PeekFront() == peeked:
+		d = h.prioQueue.PopFront()
+	case peeked != nil && !h.sendQueue.Empty() && h.sendQueue.PeekFront() == peeked:
+		d = h.sendQueue.PopFront()
+	case !h.prioQueue.Empty():
+		d = h.prioQueue.PopFront()
+	case !h.sendQueue.Empty():
+		d = h.sendQueue.PopFront()
+	}
+	h.peeked = nil
+	if d == nil {
+		h.sendMx.Unlock()
+		return
+	}
+	if err == nil && h.fcEnabled {
+		h.numSent++
+	}
+	h.signalSent()
+	h.maybeSignalDrained()
+	h.sendMx.Unlock()
+	d.done(err)
+}
+
+// signalSent must be called with the sendMx held.
+func (h *datagramQueue) signalSent() {
 	select {
 	case h.sent <- struct{}{}:
 	default:
 	}
 }
 
+// maybeSignalDrained must be called with the sendMx held.
+func (h *datagramQueue) maybeSignalDrained() {
+	if !h.sendQueue.Empty() || !h.prioQueue.Empty() {
+		return
+	}
+	select {
+	case <-h.draining:
+	default:
+		return
+	}
+	select {
+	case <-h.drained:
+	default:
+		close(h.drained)
+	}
+}
+
+// CloseAfterDrain stops accepting new DATAGRAM frames for sending.
+// Frames that were already queued are still returned by Peek and Pop.
+// It blocks until all queued frames have been dequeued, or the context is canceled.
+// It doesn't close the queue: CloseWithError still needs to be called.
+func (h *datagramQueue) CloseAfterDrain(ctx context.Context) error {
+	h.sendMx.Lock()
+	select {
+	case <-h.draining:
+	default:
+		close(h.draining)
+	}
+	h.maybeSignalDrained()
+	h.sendMx.Unlock()
+
+	select {
+	case <-h.drained:
+		return nil
+	case <-h.closed:
+		return h.closeErr
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
 // HandleDatagramFrame handles a received DATAGRAM frame.
-func (h *datagramQueue) HandleDatagramFrame(f *wire.DatagramFrame) {
-	data := make([]byte, len(f.Data))
-	copy(data, f.Data)
-	var queued bool
+// rcvTime is the time the packet containing the frame was received.
+// buf is the buffer of that packet. In zero-copy mode, it is retained until the application
+// releases the datagram, otherwise the payload of the frame is copied.
+// It must be called from the connection's run loop.
+func (h *datagramQueue) HandleDatagramFrame(f *wire.DatagramFrame, rcvTime time.Time, buf *packetBuffer) {
+	d := receivedDatagram{data: f.Data}
+	if h.zeroCopy && buf != nil {
+		d.buf = buf
+	} else {
+		d.data = make([]byte, len(f.Data))
+		copy(d.data, f.Data)
+	}
+	if h.recordRcvTime {
+		d.rcvTime = rcvTime
+	}
+	var queued, exceedsLimit bool
 	h.rcvMx.Lock()
-	if len(h.rcvQueue) < maxDatagramRcvQueueLen {
-		h.rcvQueue = append(h.rcvQueue, data)
+	if h.queueControlFrame != nil {
+		// Frames exceeding the limit are dropped without granting credit for them.
+		exceedsLimit = h.numReceived >= h.rcvMaxDatagrams
+		if !exceedsLimit {
+			h.numReceived++
+		}
+	}
+	if !exceedsLimit && len(h.rcvQueue) < h.rcvQueueLen {
+		if d.buf != nil {
+			d.buf.Split()
+		}
+		h.rcvQueue = append(h.rcvQueue, d)
 		queued = true
 		select {
 		case h.rcvd <- struct{}{}:
 		default:
 		}
 	}
+	if !queued && !exceedsLimit {
+		h.numConsumed++
+	}
 	h.rcvMx.Unlock()
-	if !queued && h.logger.Debug() {
-		h.logger.Debugf("Discarding received DATAGRAM frame (%d bytes payload)", len(f.Data))
+	if queued {
+		return
+	}
+	if !exceedsLimit {
+		h.maybeGrantCredit()
 	}
+	h.dropped.Add(1)
+	if h.logger.Debug() {
+		if exceedsLimit {
+			h.logger.Debugf("Discarding received DATAGRAM frame (%d bytes payload), since it exceeds the flow control limit", len(f.Data))
+		} else {
+			h.logger.Debugf("Discarding received DATAGRAM frame (%d bytes payload)", len(f.Data))
+		}
+	}
+	if h.onDrop != nil {
+		h.onDrop(len(f.Data))
+	}
+}
+
+// DroppedDatagrams returns the number of received DATAGRAM frames
+// that were dropped because the receive queue was full,
+// or because they exceeded the flow control limit.
+func (h *datagramQueue) DroppedDatagrams() uint64 {
+	return h.dropped.Load()
 }
 
 // Receive gets a received DATAGRAM frame.
 func (h *datagramQueue) Receive(ctx context.Context) ([]byte, error) {
+	data, _, err := h.ReceiveWithTime(ctx)
+	return data, err
+}
+
+// TryReceive gets a received DATAGRAM frame, if one is queued.
+// Unlike Receive, it never blocks, and returns false if the receive queue is empty.
+func (h *datagramQueue) TryReceive() ([]byte, bool) {
+	ds := h.tryDequeue(1)
+	if len(ds) == 0 {
+		return nil, false
+	}
+	return h.ownedData(ds[0]), true
+}
+
+// ReceiveWithTime gets a received DATAGRAM frame, and the time the packet containing it was received.
+// The receive time is only recorded if the queue was created with recordRcvTime set,
+// otherwise the zero time.Time is returned.
+func (h *datagramQueue) ReceiveWithTime(ctx context.Context) ([]byte, time.Time, error) {
+	ds, err := h.dequeue(ctx, 1)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	return h.ownedData(ds[0]), ds[0].rcvTime, nil
+}
+
+// ReceiveBatch gets up to maxFrames received DATAGRAM frames, in the order they were received.
+// It only blocks if no DATAGRAM frame is queued, and returns as soon as at least one frame is available.
+// If maxFrames is smaller than 1, at most one frame is returned.
+func (h *datagramQueue) ReceiveBatch(ctx context.Context, maxFrames int) ([][]byte, error) {
+	ds, err := h.dequeue(ctx, max(maxFrames, 1))
+	if err != nil {
+		return nil, err
+	}
+	batch := make([][]byte, len(ds))
+	for i, d := range ds {
+		batch[i] = h.ownedData(d)
+	}
+	return batch, nil
+}
+
+// ReceiveBuffer gets a received DATAGRAM frame, without copying its payload.
+// The DatagramBuffer must be released once the application is done with it.
+func (h *datagramQueue) ReceiveBuffer(ctx context.Context) (*DatagramBuffer, error) {
+	ds, err := h.dequeue(ctx, 1)
+	if err != nil {
+		return nil, err
+	}
+	return &DatagramBuffer{data: ds[0].data, buf: ds[0].buf, queue: h}, nil
+}
+
+// dequeue removes up to maxFrames DATAGRAM frames from the receive queue.
+// It blocks until at least one frame is available.
+func (h *datagramQueue) dequeue(ctx context.Context, maxFrames int) ([]receivedDatagram, error) {
 	for {
-		h.rcvMx.Lock()
-		if len(h.rcvQueue) > 0 {
-			data := h.rcvQueue[0]
-			h.rcvQueue = h.rcvQueue[1:]
-			h.rcvMx.Unlock()
-			return data, nil
+		if ds := h.tryDequeue(maxFrames); len(ds) > 0 {
+			return ds, nil
 		}
-		h.rcvMx.Unlock()
+		// A signal on rcvd might be left over from a frame that was already dequeued by tryDequeue.
+		// In that case, the queue is checked again, and we wait for the next signal.
 		select {
 		case <-h.rcvd:
 			continue
@@ -131,7 +661,101 @@ func (h *datagramQueue) Receive(ctx context.Context) ([]byte, error) {
 	}
 }
 
+// tryDequeue removes up to maxFrames DATAGRAM frames from the receive queue, without blocking.
+// Dequeuing frames grants flow control credit to the peer.
+func (h *datagramQueue) tryDequeue(maxFrames int) []receivedDatagram {
+	h.rcvMx.Lock()
+	n := min(maxFrames, len(h.rcvQueue))
+	if n == 0 {
+		h.rcvMx.Unlock()
+		return nil
+	}
+	ds := make([]receivedDatagram, n)
+	copy(ds, h.rcvQueue)
+	h.rcvQueue = h.rcvQueue[n:]
+	h.numConsumed += uint64(n)
+	h.rcvMx.Unlock()
+	h.maybeGrantCredit()
+	return ds
+}
+
+// maybeGrantCredit queues a MAX_DATAGRAMS frame, if flow control is enabled and
+// enough frames were consumed since the last update.
+// The peer is always allowed to fill the entire receive queue. To avoid sending too many MAX_DATAGRAMS frames,
+// an update is only sent once half of the receive queue was consumed.
+func (h *datagramQueue) maybeGrantCredit() {
+	h.rcvMx.Lock()
+	if h.queueControlFrame == nil {
+		h.rcvMx.Unlock()
+		return
+	}
+	maxDatagrams := h.numConsumed + uint64(h.rcvQueueLen)
+	if maxDatagrams-h.rcvMaxDatagrams < uint64(max(h.rcvQueueLen/2, 1)) {
+		h.rcvMx.Unlock()
+		return
+	}
+	h.rcvMaxDatagrams = maxDatagrams
+	queueControlFrame := h.queueControlFrame
+	h.rcvMx.Unlock()
+	queueControlFrame(&wire.MaxDatagramsFrame{MaximumDatagrams: maxDatagrams})
+}
+
+// ownedData returns the payload of a DATAGRAM frame in a slice that is owned by the caller.
+// If the payload references a packet buffer, it is copied, and the packet buffer is released.
+func (h *datagramQueue) ownedData(d receivedDatagram) []byte {
+	if d.buf == nil {
+		return d.data
+	}
+	data := make([]byte, len(d.data))
+	copy(data, d.data)
+	h.releaseBuffer(d.buf)
+	return data
+}
+
+// releaseBuffer marks a packet buffer retained by a DATAGRAM frame as released.
+// The reference count of packet buffers is not safe for concurrent use,
+// so the buffer is only returned to the pool when the run loop calls ReleaseBuffers.
+func (h *datagramQueue) releaseBuffer(buf *packetBuffer) {
+	h.releasedMx.Lock()
+	h.released = append(h.released, buf)
+	h.releasedMx.Unlock()
+	h.numReleased.Add(1)
+}
+
+// ReleaseBuffers returns the packet buffers released by the application to the pool.
+// It must be called from the connection's run loop.
+func (h *datagramQueue) ReleaseBuffers() {
+	if h.numReleased.Load() == 0 {
+		return
+	}
+	h.releasedMx.Lock()
+	released := h.released
+	h.released = nil
+	h.numReleased.Store(0)
+	h.releasedMx.Unlock()
+	for _, buf := range released {
+		buf.Decrement()
+		buf.MaybeRelease()
+	}
+}
+
+// CloseWithError closes the queue.
+// The send callbacks of all frames that are still queued are called with the close error.
 func (h *datagramQueue) CloseWithError(e error) {
+	h.sendMx.Lock()
 	h.closeErr = e
 	close(h.closed)
+	h.peeked = nil
+	dropped := make([]*queuedDatagram, 0, h.prioQueue.Len()+h.sendQueue.Len())
+	for !h.prioQueue.Empty() {
+		dropped = append(dropped, h.prioQueue.PopFront())
+	}
+	for !h.sendQueue.Empty() {
+		dropped = append(dropped, h.sendQueue.PopFront())
+	}
+	h.sendMx.Unlock()
+
+	for _, d := range dropped {
+		d.done(e)
+	}
 }
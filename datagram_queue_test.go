@@ -3,8 +3,13 @@ package quic
 import (
 	"context"
 	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"testing"
 	"time"
 
+	"github.com/quic-go/quic-go/internal/qerr"
 	"github.com/quic-go/quic-go/internal/utils"
 	"github.com/quic-go/quic-go/internal/wire"
 
@@ -12,13 +17,22 @@ import (
 	. "github.com/onsi/gomega"
 )
 
+// manualClock is a clock that only advances when told to.
+// Timers are backed by the wall clock.
+type manualClock struct {
+	utils.DefaultClock
+	now time.Time
+}
+
+func (c *manualClock) Now() time.Time { return c.now }
+
 var _ = Describe("Datagram Queue", func() {
 	var queue *datagramQueue
 	var queued chan struct{}
 
 	BeforeEach(func() {
 		queued = make(chan struct{}, 100)
-		queue = newDatagramQueue(func() { queued <- struct{}{} }, utils.DefaultLogger)
+		queue = newDatagramQueue(func() { queued <- struct{}{} }, maxDatagramSendQueueLen, 0, 0, nil, false, false, utils.DefaultClock{}, utils.DefaultLogger)
 	})
 
 	Context("sending", func() {
@@ -28,7 +42,7 @@ var _ = Describe("Datagram Queue", func() {
 
 		It("queues a datagram", func() {
 			frame := &wire.DatagramFrame{Data: []byte("foobar")}
-			Expect(queue.Add(frame)).To(Succeed())
+			Expect(queue.Add(frame, nil)).To(Succeed())
 			Expect(queued).To(HaveLen(1))
 			f := queue.Peek()
 			Expect(f.Data).To(Equal([]byte("foobar")))
@@ -38,12 +52,12 @@ var _ = Describe("Datagram Queue", func() {
 
 		It("blocks when the maximum number of datagrams have been queued", func() {
 			for i := 0; i < maxDatagramSendQueueLen; i++ {
-				Expect(queue.Add(&wire.DatagramFrame{Data: []byte{0}})).To(Succeed())
+				Expect(queue.Add(&wire.DatagramFrame{Data: []byte{0}}, nil)).To(Succeed())
 			}
 			errChan := make(chan error, 1)
 			go func() {
 				defer GinkgoRecover()
-				errChan <- queue.Add(&wire.DatagramFrame{Data: []byte("foobar")})
+				errChan <- queue.Add(&wire.DatagramFrame{Data: []byte("foobar")}, nil)
 			}()
 			Consistently(errChan, 50*time.Millisecond).ShouldNot(Receive())
 			Expect(queue.Peek()).ToNot(BeNil())
@@ -58,9 +72,54 @@ var _ = Describe("Datagram Queue", func() {
 			Expect(f.Data).To(Equal([]byte("foobar")))
 		})
 
+		It("uses a custom send queue length", func() {
+			queue = newDatagramQueue(func() {}, 3, 0, 0, nil, false, false, utils.DefaultClock{}, utils.DefaultLogger)
+			for i := 0; i < 3; i++ {
+				Expect(queue.Add(&wire.DatagramFrame{Data: []byte{uint8(i)}}, nil)).To(Succeed())
+			}
+			errChan := make(chan error, 1)
+			go func() {
+				defer GinkgoRecover()
+				errChan <- queue.Add(&wire.DatagramFrame{Data: []byte{3}}, nil)
+			}()
+			Consistently(errChan, 50*time.Millisecond).ShouldNot(Receive())
+			queue.Pop()
+			Eventually(errChan).Should(Receive(BeNil()))
+			for i := 1; i <= 3; i++ {
+				f := queue.Peek()
+				Expect(f).ToNot(BeNil())
+				Expect(f.Data).To(Equal([]byte{uint8(i)}))
+				queue.Pop()
+			}
+			Expect(queue.Peek()).To(BeNil())
+		})
+
+		It("returns an error when trying to send a datagram when the queue is full", func() {
+			queue = newDatagramQueue(func() { queued <- struct{}{} }, 2, 0, 0, nil, false, false, utils.DefaultClock{}, utils.DefaultLogger)
+			Expect(queue.TrySend(&wire.DatagramFrame{Data: []byte("foo")})).To(Succeed())
+			Expect(queue.TrySend(&wire.DatagramFrame{Data: []byte("bar")})).To(Succeed())
+			Expect(queued).To(HaveLen(2))
+			Expect(queue.TrySend(&wire.DatagramFrame{Data: []byte("baz")})).To(MatchError(ErrDatagramQueueFull))
+			Expect(queued).To(HaveLen(2))
+			Expect(queue.Peek().Data).To(Equal([]byte("foo")))
+			queue.Pop()
+			Expect(queue.TrySend(&wire.DatagramFrame{Data: []byte("baz")})).To(Succeed())
+			Expect(queue.Peek().Data).To(Equal([]byte("bar")))
+			queue.Pop()
+			Expect(queue.Peek().Data).To(Equal([]byte("baz")))
+			queue.Pop()
+			Expect(queue.Peek()).To(BeNil())
+		})
+
+		It("returns the close error when trying to send a datagram after closing", func() {
+			testErr := errors.New("test error")
+			queue.CloseWithError(testErr)
+			Expect(queue.TrySend(&wire.DatagramFrame{Data: []byte("foo")})).To(MatchError(testErr))
+		})
+
 		It("returns the same datagram multiple times, when Pop isn't called", func() {
-			Expect(queue.Add(&wire.DatagramFrame{Data: []byte("foo")})).To(Succeed())
-			Expect(queue.Add(&wire.DatagramFrame{Data: []byte("bar")})).To(Succeed())
+			Expect(queue.Add(&wire.DatagramFrame{Data: []byte("foo")}, nil)).To(Succeed())
+			Expect(queue.Add(&wire.DatagramFrame{Data: []byte("bar")}, nil)).To(Succeed())
 
 			Eventually(queued).Should(HaveLen(2))
 			f := queue.Peek()
@@ -75,24 +134,485 @@ var _ = Describe("Datagram Queue", func() {
 
 		It("closes", func() {
 			for i := 0; i < maxDatagramSendQueueLen; i++ {
-				Expect(queue.Add(&wire.DatagramFrame{Data: []byte("foo")})).To(Succeed())
+				Expect(queue.Add(&wire.DatagramFrame{Data: []byte("foo")}, nil)).To(Succeed())
 			}
 			errChan := make(chan error, 1)
 			go func() {
 				defer GinkgoRecover()
-				errChan <- queue.Add(&wire.DatagramFrame{Data: []byte("foo")})
+				errChan <- queue.Add(&wire.DatagramFrame{Data: []byte("foo")}, nil)
 			}()
 			Consistently(errChan, 25*time.Millisecond).ShouldNot(Receive())
 			testErr := errors.New("test error")
 			queue.CloseWithError(testErr)
 			Eventually(errChan).Should(Receive(MatchError(testErr)))
 		})
+		It("calls the send callback when the datagram is dequeued", func() {
+			errChan := make(chan error, 2)
+			frame := &wire.DatagramFrame{Data: []byte("foobar")}
+			Expect(queue.Add(frame, func(err error) { errChan <- err })).To(Succeed())
+			Expect(queue.Peek()).To(Equal(frame))
+			Expect(errChan).To(BeEmpty())
+			queue.Pop()
+			Expect(errChan).To(Receive(BeNil()))
+			queue.CloseWithError(errors.New("test error"))
+			Expect(errChan).To(BeEmpty())
+		})
+
+		It("calls the send callback with the close error for queued datagrams", func() {
+			errChan := make(chan error, 4)
+			for i := 0; i < 2; i++ {
+				Expect(queue.Add(&wire.DatagramFrame{Data: []byte("foo")}, func(err error) { errChan <- err })).To(Succeed())
+			}
+			f := queue.Peek()
+			Expect(f).ToNot(BeNil())
+			testErr := errors.New("test error")
+			queue.CloseWithError(testErr)
+			Expect(errChan).To(Receive(MatchError(testErr)))
+			Expect(errChan).To(Receive(MatchError(testErr)))
+			// the frame was peeked before the queue was closed
+			queue.Pop()
+			Expect(queue.Peek()).To(BeNil())
+			Expect(errChan).To(BeEmpty())
+		})
+
+		It("rejects datagrams after the queue was closed", func() {
+			testErr := errors.New("test error")
+			queue.CloseWithError(testErr)
+			var called bool
+			Expect(queue.Add(&wire.DatagramFrame{Data: []byte("foo")}, func(error) { called = true })).To(MatchError(testErr))
+			Expect(queue.Peek()).To(BeNil())
+			Expect(called).To(BeFalse())
+		})
+
+		It("drops datagrams that were queued for too long", func() {
+			queue = newDatagramQueue(func() {}, maxDatagramSendQueueLen, 0, scaleDuration(20*time.Millisecond), nil, false, false, utils.DefaultClock{}, utils.DefaultLogger)
+			errChan := make(chan error, 2)
+			Expect(queue.Add(&wire.DatagramFrame{Data: []byte("foo")}, func(err error) { errChan <- err })).To(Succeed())
+			time.Sleep(scaleDuration(30 * time.Millisecond))
+			Expect(queue.Add(&wire.DatagramFrame{Data: []byte("bar")}, func(err error) { errChan <- err })).To(Succeed())
+			f := queue.Peek()
+			Expect(f).ToNot(BeNil())
+			Expect(f.Data).To(Equal([]byte("bar")))
+			Expect(errChan).To(Receive(MatchError(&DatagramQueuedTooLong{})))
+			queue.Pop()
+			Expect(errChan).To(Receive(BeNil()))
+		})
+
+		It("uses the clock to determine if datagrams expired", func() {
+			clock := &manualClock{now: time.Now()}
+			queue = newDatagramQueue(func() {}, maxDatagramSendQueueLen, 0, time.Minute, nil, false, false, clock, utils.DefaultLogger)
+			errChan := make(chan error, 2)
+			Expect(queue.Add(&wire.DatagramFrame{Data: []byte("foo")}, func(err error) { errChan <- err })).To(Succeed())
+			clock.now = clock.now.Add(30 * time.Second)
+			Expect(queue.Add(&wire.DatagramFrame{Data: []byte("bar")}, func(err error) { errChan <- err })).To(Succeed())
+			clock.now = clock.now.Add(31 * time.Second)
+			Expect(queue.Peek().Data).To(Equal([]byte("bar")))
+			Expect(errChan).To(Receive(MatchError(&DatagramQueuedTooLong{})))
+			clock.now = clock.now.Add(30 * time.Second)
+			Expect(queue.Peek()).To(BeNil())
+			Expect(errChan).To(Receive(MatchError(&DatagramQueuedTooLong{})))
+		})
+
+		It("applies an updated send timeout to datagrams queued afterwards", func() {
+			errChan := make(chan error, 2)
+			Expect(queue.Add(&wire.DatagramFrame{Data: []byte("foo")}, func(err error) { errChan <- err })).To(Succeed())
+			queue.SetSendTimeout(scaleDuration(20 * time.Millisecond))
+			Expect(queue.Add(&wire.DatagramFrame{Data: []byte("bar")}, func(err error) { errChan <- err })).To(Succeed())
+			time.Sleep(scaleDuration(30 * time.Millisecond))
+			// the first datagram was queued before the timeout was set
+			Expect(queue.Peek().Data).To(Equal([]byte("foo")))
+			queue.Pop()
+			Expect(errChan).To(Receive(BeNil()))
+			Expect(queue.Peek()).To(BeNil())
+			Expect(errChan).To(Receive(MatchError(&DatagramQueuedTooLong{})))
+		})
+
+		It("keeps the expiry of queued datagrams when the send timeout is removed", func() {
+			queue = newDatagramQueue(func() {}, maxDatagramSendQueueLen, 0, scaleDuration(20*time.Millisecond), nil, false, false, utils.DefaultClock{}, utils.DefaultLogger)
+			errChan := make(chan error, 2)
+			Expect(queue.Add(&wire.DatagramFrame{Data: []byte("foo")}, func(err error) { errChan <- err })).To(Succeed())
+			queue.SetSendTimeout(0)
+			Expect(queue.Add(&wire.DatagramFrame{Data: []byte("bar")}, func(err error) { errChan <- err })).To(Succeed())
+			time.Sleep(scaleDuration(30 * time.Millisecond))
+			Expect(queue.Peek().Data).To(Equal([]byte("bar")))
+			Expect(errChan).To(Receive(MatchError(&DatagramQueuedTooLong{})))
+			queue.Pop()
+			Expect(errChan).To(Receive(BeNil()))
+		})
+	})
+
+	Context("priorities", func() {
+		It("sends high-priority datagrams first", func() {
+			Expect(queue.Add(&wire.DatagramFrame{Data: []byte("foo")}, nil)).To(Succeed())
+			Expect(queue.AddPriority(&wire.DatagramFrame{Data: []byte("bar")}, nil)).To(Succeed())
+			Expect(queue.Add(&wire.DatagramFrame{Data: []byte("baz")}, nil)).To(Succeed())
+			Expect(queue.AddPriority(&wire.DatagramFrame{Data: []byte("qux")}, nil)).To(Succeed())
+			for _, data := range []string{"bar", "qux", "foo", "baz"} {
+				f := queue.Peek()
+				Expect(f).ToNot(BeNil())
+				Expect(f.Data).To(Equal([]byte(data)))
+				queue.Pop()
+			}
+			Expect(queue.Peek()).To(BeNil())
+		})
+
+		It("pops the peeked datagram, when a high-priority datagram is added in the meantime", func() {
+			errChan := make(chan error, 2)
+			Expect(queue.Add(&wire.DatagramFrame{Data: []byte("foo")}, func(err error) { errChan <- err })).To(Succeed())
+			Expect(queue.Peek().Data).To(Equal([]byte("foo")))
+			Expect(queue.AddPriority(&wire.DatagramFrame{Data: []byte("bar")}, func(err error) { errChan <- err })).To(Succeed())
+			queue.Pop()
+			Expect(errChan).To(Receive(BeNil()))
+			Expect(errChan).To(BeEmpty())
+			Expect(queue.Peek().Data).To(Equal([]byte("bar")))
+		})
+
+		It("blocks when the high-priority queue is full", func() {
+			for i := 0; i < maxDatagramSendQueueLen; i++ {
+				Expect(queue.AddPriority(&wire.DatagramFrame{Data: []byte{0}}, nil)).To(Succeed())
+			}
+			// the normal queue is independent from the high-priority queue
+			Expect(queue.Add(&wire.DatagramFrame{Data: []byte("foo")}, nil)).To(Succeed())
+			errChan := make(chan error, 1)
+			go func() {
+				defer GinkgoRecover()
+				errChan <- queue.AddPriority(&wire.DatagramFrame{Data: []byte("bar")}, nil)
+			}()
+			Consistently(errChan, 50*time.Millisecond).ShouldNot(Receive())
+			queue.Peek()
+			queue.Pop()
+			Eventually(errChan).Should(Receive(BeNil()))
+		})
+
+		It("drops high-priority datagrams that were queued for too long", func() {
+			queue = newDatagramQueue(func() {}, maxDatagramSendQueueLen, 0, scaleDuration(20*time.Millisecond), nil, false, false, utils.DefaultClock{}, utils.DefaultLogger)
+			errChan := make(chan error, 2)
+			Expect(queue.AddPriority(&wire.DatagramFrame{Data: []byte("foo")}, func(err error) { errChan <- err })).To(Succeed())
+			time.Sleep(scaleDuration(30 * time.Millisecond))
+			Expect(queue.Add(&wire.DatagramFrame{Data: []byte("bar")}, nil)).To(Succeed())
+			Expect(queue.Peek().Data).To(Equal([]byte("bar")))
+			Expect(errChan).To(Receive(MatchError(&DatagramQueuedTooLong{})))
+		})
+
+		It("calls the send callback of high-priority datagrams when closed", func() {
+			errChan := make(chan error, 1)
+			Expect(queue.AddPriority(&wire.DatagramFrame{Data: []byte("foo")}, func(err error) { errChan <- err })).To(Succeed())
+			testErr := errors.New("test error")
+			queue.CloseWithError(testErr)
+			Expect(errChan).To(Receive(MatchError(testErr)))
+			Expect(queue.Peek()).To(BeNil())
+		})
+	})
+
+	Context("waiting for datagrams to be dequeued", func() {
+		It("returns when the datagram is dequeued", func() {
+			errChan := make(chan error, 1)
+			go func() {
+				defer GinkgoRecover()
+				errChan <- queue.AddAndWaitContext(context.Background(), &wire.DatagramFrame{Data: []byte("foo")})
+			}()
+			Eventually(queued).Should(Receive())
+			Consistently(errChan, 50*time.Millisecond).ShouldNot(Receive())
+			Expect(queue.Peek().Data).To(Equal([]byte("foo")))
+			queue.Pop()
+			Eventually(errChan).Should(Receive(BeNil()))
+		})
+
+		It("returns the close error", func() {
+			errChan := make(chan error, 1)
+			go func() {
+				defer GinkgoRecover()
+				errChan <- queue.AddAndWaitContext(context.Background(), &wire.DatagramFrame{Data: []byte("foo")})
+			}()
+			Eventually(queued).Should(Receive())
+			queue.CloseWithError(errors.New("test error"))
+			Eventually(errChan).Should(Receive(MatchError("test error")))
+		})
+
+		It("removes the datagram when the context is canceled", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			errChan := make(chan error, 1)
+			go func() {
+				defer GinkgoRecover()
+				errChan <- queue.AddAndWaitContext(ctx, &wire.DatagramFrame{Data: []byte("foo")})
+			}()
+			Eventually(queued).Should(Receive())
+			Expect(queue.Add(&wire.DatagramFrame{Data: []byte("bar")}, nil)).To(Succeed())
+			cancel()
+			Eventually(errChan).Should(Receive(MatchError(context.Canceled)))
+			Expect(queue.Peek().Data).To(Equal([]byte("bar")))
+			queue.Pop()
+			Expect(queue.Peek()).To(BeNil())
+		})
+
+		It("waits for a peeked datagram to be dequeued when the context is canceled", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			errChan := make(chan error, 1)
+			go func() {
+				defer GinkgoRecover()
+				errChan <- queue.AddAndWaitContext(ctx, &wire.DatagramFrame{Data: []byte("foo")})
+			}()
+			Eventually(queued).Should(Receive())
+			Expect(queue.Peek().Data).To(Equal([]byte("foo")))
+			cancel()
+			// the send loop is woken up
+			Eventually(queued).Should(Receive())
+			Consistently(errChan, 50*time.Millisecond).ShouldNot(Receive())
+			queue.Pop()
+			Eventually(errChan).Should(Receive(BeNil()))
+		})
+
+		It("stops waiting for space in the queue when the context is canceled", func() {
+			for i := 0; i < maxDatagramSendQueueLen; i++ {
+				Expect(queue.Add(&wire.DatagramFrame{Data: []byte{0}}, nil)).To(Succeed())
+			}
+			ctx, cancel := context.WithCancel(context.Background())
+			errChan := make(chan error, 1)
+			go func() {
+				defer GinkgoRecover()
+				errChan <- queue.AddAndWaitContext(ctx, &wire.DatagramFrame{Data: []byte("foo")})
+			}()
+			Consistently(errChan, 50*time.Millisecond).ShouldNot(Receive())
+			cancel()
+			Eventually(errChan).Should(Receive(MatchError(context.Canceled)))
+			for i := 0; i < maxDatagramSendQueueLen; i++ {
+				Expect(queue.Peek().Data).To(Equal([]byte{0}))
+				queue.Pop()
+			}
+			Expect(queue.Peek()).To(BeNil())
+		})
+	})
+
+	It("distinguishes expired datagrams from a closed queue", func() {
+		queue = newDatagramQueue(func() {}, maxDatagramSendQueueLen, 0, scaleDuration(20*time.Millisecond), nil, false, false, utils.DefaultClock{}, utils.DefaultLogger)
+		errChan := make(chan error, 2)
+		go func() {
+			defer GinkgoRecover()
+			errChan <- queue.AddAndWaitContext(context.Background(), &wire.DatagramFrame{Data: []byte("foo")})
+		}()
+		time.Sleep(scaleDuration(30 * time.Millisecond))
+		Expect(queue.Add(&wire.DatagramFrame{Data: []byte("bar")}, func(err error) { errChan <- err })).To(Succeed())
+		Expect(queue.Peek().Data).To(Equal([]byte("bar")))
+		// the first datagram expired
+		var expiryErr error
+		Eventually(errChan).Should(Receive(&expiryErr))
+		var queuedTooLong *DatagramQueuedTooLong
+		Expect(errors.As(expiryErr, &queuedTooLong)).To(BeTrue())
+		var nerr net.Error
+		Expect(errors.As(expiryErr, &nerr)).To(BeTrue())
+		Expect(nerr.Timeout()).To(BeTrue())
+		// the connection might be closed with a timeout error as well
+		queue.CloseWithError(fmt.Errorf("connection closed: %w", &qerr.IdleTimeoutError{}))
+		var closeErr error
+		Eventually(errChan).Should(Receive(&closeErr))
+		Expect(errors.As(closeErr, &queuedTooLong)).To(BeFalse())
+		Expect(closeErr).To(MatchError(&qerr.IdleTimeoutError{}))
+	})
+
+	Context("draining", func() {
+		It("drains queued datagrams", func() {
+			errChan := make(chan error, 2)
+			Expect(queue.Add(&wire.DatagramFrame{Data: []byte("foo")}, func(err error) { errChan <- err })).To(Succeed())
+			Expect(queue.Add(&wire.DatagramFrame{Data: []byte("bar")}, func(err error) { errChan <- err })).To(Succeed())
+			drainErr := make(chan error, 1)
+			go func() {
+				defer GinkgoRecover()
+				drainErr <- queue.CloseAfterDrain(context.Background())
+			}()
+			Eventually(queue.draining).Should(BeClosed())
+			Expect(queue.TrySend(&wire.DatagramFrame{Data: []byte("baz")})).To(MatchError(errDatagramQueueDraining))
+			Expect(queue.Add(&wire.DatagramFrame{Data: []byte("baz")}, nil)).To(MatchError(errDatagramQueueDraining))
+			Expect(queue.Peek().Data).To(Equal([]byte("foo")))
+			queue.Pop()
+			Expect(errChan).To(Receive(BeNil()))
+			Consistently(drainErr, 50*time.Millisecond).ShouldNot(Receive())
+			Expect(queue.Peek().Data).To(Equal([]byte("bar")))
+			queue.Pop()
+			Expect(errChan).To(Receive(BeNil()))
+			Eventually(drainErr).Should(Receive(BeNil()))
+			Expect(queue.Peek()).To(BeNil())
+		})
+
+		It("returns immediately if the queue is empty", func() {
+			Expect(queue.CloseAfterDrain(context.Background())).To(Succeed())
+			Expect(queue.TrySend(&wire.DatagramFrame{Data: []byte("foo")})).To(MatchError(errDatagramQueueDraining))
+		})
+
+		It("unblocks Add calls waiting for space in the queue", func() {
+			for i := 0; i < maxDatagramSendQueueLen; i++ {
+				Expect(queue.Add(&wire.DatagramFrame{Data: []byte{0}}, nil)).To(Succeed())
+			}
+			errChan := make(chan error, 1)
+			go func() {
+				defer GinkgoRecover()
+				errChan <- queue.Add(&wire.DatagramFrame{Data: []byte("foobar")}, nil)
+			}()
+			Consistently(errChan, 50*time.Millisecond).ShouldNot(Receive())
+			ctx, cancel := context.WithTimeout(context.Background(), scaleDuration(10*time.Millisecond))
+			defer cancel()
+			Expect(queue.CloseAfterDrain(ctx)).To(MatchError(context.DeadlineExceeded))
+			Eventually(errChan).Should(Receive(MatchError(errDatagramQueueDraining)))
+		})
+
+		It("stops waiting when the queue is closed", func() {
+			Expect(queue.Add(&wire.DatagramFrame{Data: []byte("foo")}, nil)).To(Succeed())
+			drainErr := make(chan error, 1)
+			go func() {
+				defer GinkgoRecover()
+				drainErr <- queue.CloseAfterDrain(context.Background())
+			}()
+			Consistently(drainErr, 50*time.Millisecond).ShouldNot(Receive())
+			queue.CloseWithError(errors.New("test error"))
+			Eventually(drainErr).Should(Receive(MatchError("test error")))
+		})
+	})
+
+	Context("flow control", func() {
+		It("doesn't send more datagrams than allowed by the peer", func() {
+			queue.EnableFlowControl(2, func(wire.Frame) {})
+			Expect(queue.AckHandler()).ToNot(BeNil())
+			for i := 0; i < 3; i++ {
+				Expect(queue.Add(&wire.DatagramFrame{Data: []byte{byte(i)}}, nil)).To(Succeed())
+			}
+			Expect(queue.Peek().Data).To(Equal([]byte{0}))
+			queue.Pop()
+			Expect(queue.Peek().Data).To(Equal([]byte{1}))
+			queue.Pop()
+			Expect(queue.Peek()).To(BeNil())
+			// an outdated limit is ignored
+			queue.SetMaxDatagrams(1)
+			Expect(queue.Peek()).To(BeNil())
+			queued = make(chan struct{}, 1)
+			queue.SetMaxDatagrams(3)
+			Expect(queued).To(HaveLen(1))
+			Expect(queue.Peek().Data).To(Equal([]byte{2}))
+		})
+
+		It("blocks AddAndWait until the peer grants more credit", func() {
+			queue.EnableFlowControl(0, func(wire.Frame) {})
+			errChan := make(chan error, 1)
+			go func() {
+				defer GinkgoRecover()
+				errChan <- queue.AddAndWaitContext(context.Background(), &wire.DatagramFrame{Data: []byte("foo")})
+			}()
+			Eventually(queued).Should(Receive())
+			Expect(queue.Peek()).To(BeNil())
+			Consistently(errChan, 50*time.Millisecond).ShouldNot(Receive())
+			queue.SetMaxDatagrams(1)
+			Expect(queue.Peek().Data).To(Equal([]byte("foo")))
+			queue.Pop()
+			Eventually(errChan).Should(Receive(BeNil()))
+		})
+
+		It("returns credit for lost datagrams", func() {
+			queue.EnableFlowControl(1, func(wire.Frame) {})
+			Expect(queue.Add(&wire.DatagramFrame{Data: []byte("foo")}, nil)).To(Succeed())
+			Expect(queue.Add(&wire.DatagramFrame{Data: []byte("bar")}, nil)).To(Succeed())
+			f := queue.Peek()
+			queue.Pop()
+			Expect(queue.Peek()).To(BeNil())
+			queue.AckHandler().OnLost(f)
+			Expect(queue.Peek().Data).To(Equal([]byte("bar")))
+		})
+
+		It("doesn't consume credit for discarded datagrams", func() {
+			queue.EnableFlowControl(1, func(wire.Frame) {})
+			var sentErr error
+			Expect(queue.Add(&wire.DatagramFrame{Data: []byte("foo")}, func(err error) { sentErr = err })).To(Succeed())
+			Expect(queue.Add(&wire.DatagramFrame{Data: []byte("bar")}, nil)).To(Succeed())
+			Expect(queue.Peek()).ToNot(BeNil())
+			queue.Discard(&DatagramTooLargeError{MaxDatagramPayloadSize: 2})
+			Expect(sentErr).To(Equal(&DatagramTooLargeError{MaxDatagramPayloadSize: 2}))
+			Expect(queue.Peek().Data).To(Equal([]byte("bar")))
+		})
+
+		It("doesn't set an ack handler if flow control is disabled", func() {
+			Expect(queue.AckHandler()).To(BeNil())
+		})
+
+		It("grants credit when datagrams are received by the application", func() {
+			queue = newDatagramQueue(func() {}, maxDatagramSendQueueLen, 4, 0, nil, false, false, utils.DefaultClock{}, utils.DefaultLogger)
+			var frames []wire.Frame
+			queue.EnableFlowControl(100, func(f wire.Frame) { frames = append(frames, f) })
+			for i := 0; i < 4; i++ {
+				queue.HandleDatagramFrame(&wire.DatagramFrame{Data: []byte{byte(i)}}, time.Now(), nil)
+			}
+			Expect(frames).To(BeEmpty())
+			_, err := queue.Receive(context.Background())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(frames).To(BeEmpty())
+			_, ok := queue.TryReceive()
+			Expect(ok).To(BeTrue())
+			Expect(frames).To(Equal([]wire.Frame{&wire.MaxDatagramsFrame{MaximumDatagrams: 6}}))
+			_, err = queue.ReceiveBatch(context.Background(), 2)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(frames).To(HaveLen(2))
+			Expect(frames[1]).To(Equal(&wire.MaxDatagramsFrame{MaximumDatagrams: 8}))
+		})
+
+		It("drops datagrams exceeding the limit, without granting credit for them", func() {
+			var dropped []int
+			queue = newDatagramQueue(func() {}, maxDatagramSendQueueLen, 2, 0, func(l int) { dropped = append(dropped, l) }, false, false, utils.DefaultClock{}, utils.DefaultLogger)
+			var frames []wire.Frame
+			queue.EnableFlowControl(100, func(f wire.Frame) { frames = append(frames, f) })
+			for i := 0; i < 3; i++ {
+				queue.HandleDatagramFrame(&wire.DatagramFrame{Data: []byte{byte(i)}}, time.Now(), nil)
+			}
+			Expect(queue.DroppedDatagrams()).To(BeEquivalentTo(1))
+			Expect(dropped).To(Equal([]int{1}))
+			Expect(frames).To(BeEmpty())
+			data, err := queue.Receive(context.Background())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(data).To(Equal([]byte{0}))
+			Expect(frames).To(Equal([]wire.Frame{&wire.MaxDatagramsFrame{MaximumDatagrams: 3}}))
+			// the limit now allows receiving one more frame
+			queue.HandleDatagramFrame(&wire.DatagramFrame{Data: []byte{3}}, time.Now(), nil)
+			queue.HandleDatagramFrame(&wire.DatagramFrame{Data: []byte{4}}, time.Now(), nil)
+			Expect(queue.DroppedDatagrams()).To(BeEquivalentTo(2))
+			ds, err := queue.ReceiveBatch(context.Background(), 2)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ds).To(Equal([][]byte{{1}, {3}}))
+		})
+
+		It("resynchronizes after a spurious loss", func() {
+			receiver := newDatagramQueue(func() {}, maxDatagramSendQueueLen, 2, 0, nil, false, false, utils.DefaultClock{}, utils.DefaultLogger)
+			var frames []wire.Frame
+			receiver.EnableFlowControl(100, func(f wire.Frame) { frames = append(frames, f) })
+			queue.EnableFlowControl(2, func(wire.Frame) {})
+			send := func() *wire.DatagramFrame {
+				f := queue.Peek()
+				Expect(f).ToNot(BeNil())
+				queue.Pop()
+				return f
+			}
+			for i := 0; i < 4; i++ {
+				Expect(queue.Add(&wire.DatagramFrame{Data: []byte{byte(i)}}, nil)).To(Succeed())
+			}
+			receiver.HandleDatagramFrame(send(), time.Now(), nil)
+			f := send()
+			// the frame is declared lost, but it is received by the peer after all
+			queue.AckHandler().OnLost(f)
+			receiver.HandleDatagramFrame(f, time.Now(), nil)
+			// the credit returned on loss allows sending one more frame, which exceeds the peer's limit
+			receiver.HandleDatagramFrame(send(), time.Now(), nil)
+			Expect(receiver.DroppedDatagrams()).To(BeEquivalentTo(1))
+			Expect(queue.Peek()).To(BeNil())
+			// sender and receiver now agree on the number of frames sent
+			ds, err := receiver.ReceiveBatch(context.Background(), 2)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ds).To(HaveLen(2))
+			Expect(frames).To(Equal([]wire.Frame{&wire.MaxDatagramsFrame{MaximumDatagrams: 4}}))
+			queue.SetMaxDatagrams(4)
+			receiver.HandleDatagramFrame(send(), time.Now(), nil)
+			Expect(queue.Peek()).To(BeNil())
+			Expect(receiver.DroppedDatagrams()).To(BeEquivalentTo(1))
+		})
 	})
 
 	Context("receiving", func() {
 		It("receives DATAGRAM frames", func() {
-			queue.HandleDatagramFrame(&wire.DatagramFrame{Data: []byte("foo")})
-			queue.HandleDatagramFrame(&wire.DatagramFrame{Data: []byte("bar")})
+			queue.HandleDatagramFrame(&wire.DatagramFrame{Data: []byte("foo")}, time.Now(), nil)
+			queue.HandleDatagramFrame(&wire.DatagramFrame{Data: []byte("bar")}, time.Now(), nil)
 			data, err := queue.Receive(context.Background())
 			Expect(err).ToNot(HaveOccurred())
 			Expect(data).To(Equal([]byte("foo")))
@@ -101,6 +621,180 @@ var _ = Describe("Datagram Queue", func() {
 			Expect(data).To(Equal([]byte("bar")))
 		})
 
+		It("records the receive time", func() {
+			queue = newDatagramQueue(func() {}, maxDatagramSendQueueLen, 0, 0, nil, true, false, utils.DefaultClock{}, utils.DefaultLogger)
+			t1 := time.Now().Add(-time.Second)
+			t2 := t1.Add(10 * time.Millisecond)
+			t3 := t2.Add(10 * time.Millisecond)
+			queue.HandleDatagramFrame(&wire.DatagramFrame{Data: []byte("foo")}, t1, nil)
+			queue.HandleDatagramFrame(&wire.DatagramFrame{Data: []byte("bar")}, t2, nil)
+			queue.HandleDatagramFrame(&wire.DatagramFrame{Data: []byte("baz")}, t3, nil)
+			data, rcvTime, err := queue.ReceiveWithTime(context.Background())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(data).To(Equal([]byte("foo")))
+			Expect(rcvTime).To(Equal(t1))
+			// ReceiveBatch skips over the receive times of the frames it returns
+			batch, err := queue.ReceiveBatch(context.Background(), 1)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(batch).To(Equal([][]byte{[]byte("bar")}))
+			data, rcvTime, err = queue.ReceiveWithTime(context.Background())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(data).To(Equal([]byte("baz")))
+			Expect(rcvTime).To(Equal(t3))
+		})
+
+		It("doesn't record the receive time, if disabled", func() {
+			queue.HandleDatagramFrame(&wire.DatagramFrame{Data: []byte("foo")}, time.Now(), nil)
+			data, rcvTime, err := queue.ReceiveWithTime(context.Background())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(data).To(Equal([]byte("foo")))
+			Expect(rcvTime).To(BeZero())
+		})
+
+		Context("zero-copy", func() {
+			BeforeEach(func() {
+				queue = newDatagramQueue(func() {}, maxDatagramSendQueueLen, 0, 0, nil, false, true, utils.DefaultClock{}, utils.DefaultLogger)
+			})
+
+			It("retains the packet buffer until the datagram is released", func() {
+				buf := getPacketBuffer()
+				buf.Data = append(buf.Data, []byte("foobar")...)
+				queue.HandleDatagramFrame(&wire.DatagramFrame{Data: buf.Data[3:]}, time.Now(), buf)
+				Expect(buf.refCount).To(Equal(2))
+				buf.Decrement() // done processing the packet
+				b, err := queue.ReceiveBuffer(context.Background())
+				Expect(err).ToNot(HaveOccurred())
+				Expect(b.Data()).To(Equal([]byte("bar")))
+				Expect(&b.Data()[0]).To(BeIdenticalTo(&buf.Data[3])) // the data wasn't copied
+				b.Release()
+				Expect(b.Data()).To(BeNil())
+				// the buffer is only returned to the pool by the run loop
+				Expect(buf.refCount).To(Equal(1))
+				queue.ReleaseBuffers()
+				Expect(buf.refCount).To(BeZero())
+			})
+
+			It("copies the data when using Receive", func() {
+				buf := getPacketBuffer()
+				buf.Data = append(buf.Data, []byte("foobar")...)
+				queue.HandleDatagramFrame(&wire.DatagramFrame{Data: buf.Data}, time.Now(), buf)
+				queue.HandleDatagramFrame(&wire.DatagramFrame{Data: buf.Data}, time.Now(), buf)
+				Expect(buf.refCount).To(Equal(3))
+				data, err := queue.Receive(context.Background())
+				Expect(err).ToNot(HaveOccurred())
+				Expect(data).To(Equal([]byte("foobar")))
+				Expect(&data[0]).ToNot(BeIdenticalTo(&buf.Data[0]))
+				batch, err := queue.ReceiveBatch(context.Background(), 10)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(batch).To(Equal([][]byte{[]byte("foobar")}))
+				queue.ReleaseBuffers()
+				Expect(buf.refCount).To(Equal(1))
+			})
+
+			It("doesn't retain the packet buffer if the datagram is dropped", func() {
+				queue = newDatagramQueue(func() {}, maxDatagramSendQueueLen, 1, 0, nil, false, true, utils.DefaultClock{}, utils.DefaultLogger)
+				buf := getPacketBuffer()
+				buf.Data = append(buf.Data, []byte("foobar")...)
+				queue.HandleDatagramFrame(&wire.DatagramFrame{Data: buf.Data}, time.Now(), buf)
+				queue.HandleDatagramFrame(&wire.DatagramFrame{Data: buf.Data}, time.Now(), buf)
+				Expect(queue.DroppedDatagrams()).To(BeEquivalentTo(1))
+				Expect(buf.refCount).To(Equal(2))
+			})
+
+			It("copies the data if the packet buffer is not available", func() {
+				data := []byte("foobar")
+				queue.HandleDatagramFrame(&wire.DatagramFrame{Data: data}, time.Now(), nil)
+				b, err := queue.ReceiveBuffer(context.Background())
+				Expect(err).ToNot(HaveOccurred())
+				Expect(b.Data()).To(Equal(data))
+				Expect(&b.Data()[0]).ToNot(BeIdenticalTo(&data[0]))
+				b.Release()
+			})
+		})
+
+		It("uses a custom receive queue length", func() {
+			queue = newDatagramQueue(func() {}, maxDatagramSendQueueLen, 2, 0, nil, false, false, utils.DefaultClock{}, utils.DefaultLogger)
+			for i := 0; i < 3; i++ {
+				queue.HandleDatagramFrame(&wire.DatagramFrame{Data: []byte{uint8(i)}}, time.Now(), nil)
+			}
+			Expect(queue.DroppedDatagrams()).To(BeEquivalentTo(1))
+			batch, err := queue.ReceiveBatch(context.Background(), 10)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(batch).To(Equal([][]byte{{0}, {1}}))
+		})
+
+		It("counts dropped DATAGRAM frames", func() {
+			dropped := make(chan int, 2*maxDatagramRcvQueueLen)
+			queue = newDatagramQueue(func() {}, maxDatagramSendQueueLen, 0, 0, func(l int) { dropped <- l }, false, false, utils.DefaultClock{}, utils.DefaultLogger)
+			var wg sync.WaitGroup
+			for i := 0; i < 4; i++ {
+				wg.Add(1)
+				go func() {
+					defer GinkgoRecover()
+					defer wg.Done()
+					for j := 0; j < maxDatagramRcvQueueLen/2; j++ {
+						queue.HandleDatagramFrame(&wire.DatagramFrame{Data: []byte("foo")}, time.Now(), nil)
+						_ = queue.DroppedDatagrams()
+					}
+				}()
+			}
+			wg.Wait()
+			Expect(queue.DroppedDatagrams()).To(BeEquivalentTo(maxDatagramRcvQueueLen))
+			Expect(dropped).To(HaveLen(maxDatagramRcvQueueLen))
+			Expect(dropped).To(Receive(Equal(3)))
+			// receiving frees up space in the receive queue
+			_, err := queue.Receive(context.Background())
+			Expect(err).ToNot(HaveOccurred())
+			queue.HandleDatagramFrame(&wire.DatagramFrame{Data: []byte("foobar")}, time.Now(), nil)
+			Expect(queue.DroppedDatagrams()).To(BeEquivalentTo(maxDatagramRcvQueueLen))
+			queue.HandleDatagramFrame(&wire.DatagramFrame{Data: []byte("foobar")}, time.Now(), nil)
+			Expect(queue.DroppedDatagrams()).To(BeEquivalentTo(maxDatagramRcvQueueLen + 1))
+		})
+
+		It("receives DATAGRAM frames in batches", func() {
+			for i := 0; i < 5; i++ {
+				queue.HandleDatagramFrame(&wire.DatagramFrame{Data: []byte{uint8(i)}}, time.Now(), nil)
+			}
+			batch, err := queue.ReceiveBatch(context.Background(), 3)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(batch).To(Equal([][]byte{{0}, {1}, {2}}))
+			batch, err = queue.ReceiveBatch(context.Background(), 3)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(batch).To(Equal([][]byte{{3}, {4}}))
+			queue.HandleDatagramFrame(&wire.DatagramFrame{Data: []byte{5}}, time.Now(), nil)
+			queue.HandleDatagramFrame(&wire.DatagramFrame{Data: []byte{6}}, time.Now(), nil)
+			batch, err = queue.ReceiveBatch(context.Background(), 0)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(batch).To(Equal([][]byte{{5}}))
+			data, err := queue.Receive(context.Background())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(data).To(Equal([]byte{6}))
+		})
+
+		It("blocks until a frame is received, when receiving in batches", func() {
+			c := make(chan [][]byte, 1)
+			go func() {
+				defer GinkgoRecover()
+				batch, err := queue.ReceiveBatch(context.Background(), 10)
+				Expect(err).ToNot(HaveOccurred())
+				c <- batch
+			}()
+
+			Consistently(c).ShouldNot(Receive())
+			queue.HandleDatagramFrame(&wire.DatagramFrame{Data: []byte("foobar")}, time.Now(), nil)
+			Eventually(c).Should(Receive(Equal([][]byte{[]byte("foobar")})))
+		})
+
+		It("stops receiving batches when the context is done, or the queue is closed", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			_, err := queue.ReceiveBatch(ctx, 10)
+			Expect(err).To(MatchError(context.Canceled))
+			queue.CloseWithError(errors.New("test error"))
+			_, err = queue.ReceiveBatch(context.Background(), 10)
+			Expect(err).To(MatchError("test error"))
+		})
+
 		It("blocks until a frame is received", func() {
 			c := make(chan []byte, 1)
 			go func() {
@@ -111,10 +805,53 @@ var _ = Describe("Datagram Queue", func() {
 			}()
 
 			Consistently(c).ShouldNot(Receive())
-			queue.HandleDatagramFrame(&wire.DatagramFrame{Data: []byte("foobar")})
+			queue.HandleDatagramFrame(&wire.DatagramFrame{Data: []byte("foobar")}, time.Now(), nil)
 			Eventually(c).Should(Receive(Equal([]byte("foobar"))))
 		})
 
+		It("doesn't block when trying to receive from an empty queue", func() {
+			data, ok := queue.TryReceive()
+			Expect(ok).To(BeFalse())
+			Expect(data).To(BeNil())
+		})
+
+		It("tries to receive DATAGRAM frames", func() {
+			queue.HandleDatagramFrame(&wire.DatagramFrame{Data: []byte("foo")}, time.Now(), nil)
+			queue.HandleDatagramFrame(&wire.DatagramFrame{Data: []byte("bar")}, time.Now(), nil)
+			data, ok := queue.TryReceive()
+			Expect(ok).To(BeTrue())
+			Expect(data).To(Equal([]byte("foo")))
+			data, ok = queue.TryReceive()
+			Expect(ok).To(BeTrue())
+			Expect(data).To(Equal([]byte("bar")))
+			_, ok = queue.TryReceive()
+			Expect(ok).To(BeFalse())
+		})
+
+		It("wakes up a blocking Receive after a frame was dequeued by TryReceive", func() {
+			queue.HandleDatagramFrame(&wire.DatagramFrame{Data: []byte("foo")}, time.Now(), nil)
+			data, ok := queue.TryReceive()
+			Expect(ok).To(BeTrue())
+			Expect(data).To(Equal([]byte("foo")))
+
+			c := make(chan []byte, 1)
+			go func() {
+				defer GinkgoRecover()
+				data, err := queue.Receive(context.Background())
+				Expect(err).ToNot(HaveOccurred())
+				c <- data
+			}()
+			Consistently(c).ShouldNot(Receive())
+			queue.HandleDatagramFrame(&wire.DatagramFrame{Data: []byte("bar")}, time.Now(), nil)
+			Eventually(c).Should(Receive(Equal([]byte("bar"))))
+		})
+
+		It("doesn't try to receive from a closed queue", func() {
+			queue.CloseWithError(errors.New("test error"))
+			_, ok := queue.TryReceive()
+			Expect(ok).To(BeFalse())
+		})
+
 		It("blocks until context is done", func() {
 			ctx, cancel := context.WithCancel(context.Background())
 			errChan := make(chan error)
@@ -143,3 +880,32 @@ var _ = Describe("Datagram Queue", func() {
 		})
 	})
 })
+
+func BenchmarkDatagramQueueReceive(b *testing.B) {
+	for _, zeroCopy := range []bool{false, true} {
+		b.Run(fmt.Sprintf("zero-copy: %t", zeroCopy), func(b *testing.B) {
+			queue := newDatagramQueue(func() {}, maxDatagramSendQueueLen, 0, 0, nil, false, zeroCopy, utils.DefaultClock{}, utils.DefaultLogger)
+			payload := make([]byte, 1200)
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				// this is what the connection does when it receives a packet containing a DATAGRAM frame
+				queue.ReleaseBuffers()
+				buf := getPacketBuffer()
+				buf.Data = append(buf.Data, payload...)
+				queue.HandleDatagramFrame(&wire.DatagramFrame{Data: buf.Data}, time.Time{}, buf)
+				buf.Decrement()
+				buf.MaybeRelease()
+
+				d, err := queue.ReceiveBuffer(context.Background())
+				if err != nil {
+					b.Fatal(err)
+				}
+				if len(d.Data()) != len(payload) {
+					b.Fatalf("unexpected length: %d", len(d.Data()))
+				}
+				d.Release()
+			}
+		})
+	}
+}
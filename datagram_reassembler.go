@@ -0,0 +1,160 @@
+package quic
+
+import (
+	"sync"
+	"time"
+
+	"github.com/quic-go/quic-go/quicvarint"
+)
+
+// Large datagrams (see Connection.SendLargeDatagram) are split into fragments,
+// each of which is sent in a separate DATAGRAM frame.
+// Every fragment starts with a header consisting of QUIC variable-length integers:
+//
+//	Fragment {
+//	  Message ID (i),
+//	  Fragment Index (i),
+//	  Fragment Count (i),
+//	  Fragment Payload (..),
+//	}
+//
+// All fragments of a message carry the same message ID and fragment count.
+// The fragment index ranges from 0 to fragment count - 1.
+// The message is the concatenation of the fragment payloads, ordered by fragment index.
+const (
+	// maxLargeDatagramFragments is the maximum number of fragments a message is split into.
+	maxLargeDatagramFragments = 64
+	// maxIncompleteLargeDatagrams is the maximum number of messages that are reassembled at the same time.
+	maxIncompleteLargeDatagrams = 16
+	// maxLargeDatagramReassemblyBytes is the maximum number of bytes buffered for incomplete messages.
+	maxLargeDatagramReassemblyBytes = 1 << 20
+	// largeDatagramReassemblyTimeout is the time after which an incomplete message is dropped.
+	largeDatagramReassemblyTimeout = time.Second
+)
+
+func appendDatagramFragmentHeader(b []byte, id uint64, index, count int) []byte {
+	b = quicvarint.Append(b, id)
+	b = quicvarint.Append(b, uint64(index))
+	return quicvarint.Append(b, uint64(count))
+}
+
+func datagramFragmentHeaderLen(id uint64, index, count int) int {
+	return quicvarint.Len(id) + quicvarint.Len(uint64(index)) + quicvarint.Len(uint64(count))
+}
+
+// parseDatagramFragment parses a fragment. It returns false if the fragment is malformed.
+func parseDatagramFragment(b []byte) (id uint64, index, count int, payload []byte, ok bool) {
+	id, l, err := quicvarint.Parse(b)
+	if err != nil {
+		return 0, 0, 0, nil, false
+	}
+	b = b[l:]
+	idx, l, err := quicvarint.Parse(b)
+	if err != nil {
+		return 0, 0, 0, nil, false
+	}
+	b = b[l:]
+	cnt, l, err := quicvarint.Parse(b)
+	if err != nil {
+		return 0, 0, 0, nil, false
+	}
+	if cnt == 0 || cnt > maxLargeDatagramFragments || idx >= cnt {
+		return 0, 0, 0, nil, false
+	}
+	return id, int(idx), int(cnt), b[l:], true
+}
+
+type incompleteLargeDatagram struct {
+	fragments     [][]byte // indexed by the fragment index, nil if not yet received
+	numReceived   int
+	size          int
+	firstReceived time.Time
+}
+
+// The datagramReassembler reassembles messages from the fragments received in DATAGRAM frames.
+// Like datagrams, reassembly is best-effort: messages that are not complete within
+// largeDatagramReassemblyTimeout are dropped, as are malformed and duplicate fragments.
+// The amount of memory used for reassembly is bounded: when the limits are exceeded,
+// the oldest incomplete message is dropped.
+type datagramReassembler struct {
+	mutex         sync.Mutex
+	messages      map[uint64]*incompleteLargeDatagram
+	bufferedBytes int
+}
+
+func newDatagramReassembler() *datagramReassembler {
+	return &datagramReassembler{messages: make(map[uint64]*incompleteLargeDatagram)}
+}
+
+// Add adds a fragment. If this fragment completes a message, the message is returned.
+func (r *datagramReassembler) Add(b []byte, now time.Time) []byte {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.dropExpired(now)
+	id, index, count, payload, ok := parseDatagramFragment(b)
+	if !ok {
+		return nil
+	}
+	if count == 1 {
+		return payload
+	}
+	m, ok := r.messages[id]
+	if !ok {
+		if len(r.messages) >= maxIncompleteLargeDatagrams {
+			r.dropOldest()
+		}
+		m = &incompleteLargeDatagram{fragments: make([][]byte, count), firstReceived: now}
+		r.messages[id] = m
+	}
+	if len(m.fragments) != count || m.fragments[index] != nil {
+		return nil
+	}
+	for r.bufferedBytes+len(payload) > maxLargeDatagramReassemblyBytes {
+		oldest := r.dropOldest()
+		if oldest == id {
+			return nil
+		}
+	}
+	m.fragments[index] = payload
+	m.numReceived++
+	m.size += len(payload)
+	r.bufferedBytes += len(payload)
+	if m.numReceived < count {
+		return nil
+	}
+	r.drop(id)
+	msg := make([]byte, 0, m.size)
+	for _, f := range m.fragments {
+		msg = append(msg, f...)
+	}
+	return msg
+}
+
+func (r *datagramReassembler) dropExpired(now time.Time) {
+	for id, m := range r.messages {
+		if now.Sub(m.firstReceived) >= largeDatagramReassemblyTimeout {
+			r.drop(id)
+		}
+	}
+}
+
+// dropOldest drops the oldest incomplete message, and returns its ID.
+func (r *datagramReassembler) dropOldest() uint64 {
+	var oldestID uint64
+	var oldest *incompleteLargeDatagram
+	for id, m := range r.messages {
+		if oldest == nil || m.firstReceived.Before(oldest.firstReceived) {
+			oldestID, oldest = id, m
+		}
+	}
+	r.drop(oldestID)
+	return oldestID
+}
+
+func (r *datagramReassembler) drop(id uint64) {
+	if m, ok := r.messages[id]; ok {
+		r.bufferedBytes -= m.size
+		delete(r.messages, id)
+	}
+}
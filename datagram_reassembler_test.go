@@ -0,0 +1,123 @@
+package quic
+
+import (
+	"bytes"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Datagram Reassembler", func() {
+	var r *datagramReassembler
+
+	fragment := func(id uint64, index, count int, payload []byte) []byte {
+		return append(appendDatagramFragmentHeader(nil, id, index, count), payload...)
+	}
+
+	BeforeEach(func() {
+		r = newDatagramReassembler()
+	})
+
+	It("parses fragments", func() {
+		b := fragment(1337, 3, 10, []byte("foobar"))
+		Expect(b).To(HaveLen(datagramFragmentHeaderLen(1337, 3, 10) + 6))
+		id, index, count, payload, ok := parseDatagramFragment(b)
+		Expect(ok).To(BeTrue())
+		Expect(id).To(BeEquivalentTo(1337))
+		Expect(index).To(Equal(3))
+		Expect(count).To(Equal(10))
+		Expect(payload).To(Equal([]byte("foobar")))
+	})
+
+	It("rejects malformed fragments", func() {
+		_, _, _, _, ok := parseDatagramFragment(nil)
+		Expect(ok).To(BeFalse())
+		b := fragment(1, 2, 3, nil)
+		_, _, _, _, ok = parseDatagramFragment(b[:len(b)-1])
+		Expect(ok).To(BeFalse())
+		_, _, _, _, ok = parseDatagramFragment(fragment(1, 3, 3, nil))
+		Expect(ok).To(BeFalse())
+		_, _, _, _, ok = parseDatagramFragment(fragment(1, 0, 0, nil))
+		Expect(ok).To(BeFalse())
+		_, _, _, _, ok = parseDatagramFragment(fragment(1, 0, maxLargeDatagramFragments+1, nil))
+		Expect(ok).To(BeFalse())
+		Expect(r.Add([]byte{0x40}, time.Now())).To(BeNil())
+	})
+
+	It("returns messages consisting of a single fragment", func() {
+		Expect(r.Add(fragment(0, 0, 1, []byte("foobar")), time.Now())).To(Equal([]byte("foobar")))
+		Expect(r.messages).To(BeEmpty())
+	})
+
+	It("reassembles fragments received in order", func() {
+		now := time.Now()
+		Expect(r.Add(fragment(42, 0, 3, []byte("foo")), now)).To(BeNil())
+		Expect(r.Add(fragment(42, 1, 3, []byte("bar")), now)).To(BeNil())
+		Expect(r.bufferedBytes).To(Equal(6))
+		Expect(r.Add(fragment(42, 2, 3, []byte("baz")), now)).To(Equal([]byte("foobarbaz")))
+		Expect(r.messages).To(BeEmpty())
+		Expect(r.bufferedBytes).To(BeZero())
+	})
+
+	It("reassembles reordered fragments of interleaved messages", func() {
+		now := time.Now()
+		Expect(r.Add(fragment(2, 1, 2, []byte("bar")), now)).To(BeNil())
+		Expect(r.Add(fragment(1, 2, 3, []byte("baz")), now)).To(BeNil())
+		Expect(r.Add(fragment(1, 0, 3, []byte("foo")), now)).To(BeNil())
+		Expect(r.Add(fragment(2, 0, 2, []byte("foo")), now)).To(Equal([]byte("foobar")))
+		Expect(r.Add(fragment(1, 1, 3, []byte("bar")), now)).To(Equal([]byte("foobarbaz")))
+		Expect(r.messages).To(BeEmpty())
+	})
+
+	It("ignores duplicate and inconsistent fragments", func() {
+		now := time.Now()
+		Expect(r.Add(fragment(1, 0, 2, []byte("foo")), now)).To(BeNil())
+		Expect(r.Add(fragment(1, 0, 2, []byte("xxx")), now)).To(BeNil())
+		Expect(r.Add(fragment(1, 1, 3, []byte("xxx")), now)).To(BeNil())
+		Expect(r.Add(fragment(1, 1, 2, []byte("bar")), now)).To(Equal([]byte("foobar")))
+	})
+
+	It("drops incomplete messages after the timeout", func() {
+		start := time.Now()
+		Expect(r.Add(fragment(1, 0, 2, []byte("foo")), start)).To(BeNil())
+		Expect(r.Add(fragment(2, 0, 2, []byte("foo")), start.Add(largeDatagramReassemblyTimeout/2))).To(BeNil())
+		// message 1 is dropped, so its second fragment starts a new message
+		Expect(r.Add(fragment(1, 1, 2, []byte("bar")), start.Add(largeDatagramReassemblyTimeout))).To(BeNil())
+		Expect(r.messages).To(HaveLen(2))
+		Expect(r.messages[1].numReceived).To(Equal(1))
+		Expect(r.Add(fragment(2, 1, 2, []byte("bar")), start.Add(largeDatagramReassemblyTimeout))).To(Equal([]byte("foobar")))
+	})
+
+	It("limits the number of incomplete messages", func() {
+		start := time.Now()
+		for i := 0; i < maxIncompleteLargeDatagrams; i++ {
+			Expect(r.Add(fragment(uint64(i), 0, 2, []byte("foo")), start.Add(time.Duration(i)*time.Millisecond))).To(BeNil())
+		}
+		Expect(r.messages).To(HaveLen(maxIncompleteLargeDatagrams))
+		// the oldest message is dropped
+		Expect(r.Add(fragment(maxIncompleteLargeDatagrams, 0, 2, []byte("foo")), start.Add(time.Second/2))).To(BeNil())
+		Expect(r.messages).To(HaveLen(maxIncompleteLargeDatagrams))
+		Expect(r.messages).ToNot(HaveKey(uint64(0)))
+		Expect(r.bufferedBytes).To(Equal(3 * maxIncompleteLargeDatagrams))
+	})
+
+	It("limits the number of buffered bytes", func() {
+		const fragmentSize = 1200
+		payload := bytes.Repeat([]byte{'a'}, fragmentSize)
+		now := time.Now()
+		var n int
+		for id := uint64(0); r.bufferedBytes+fragmentSize <= maxLargeDatagramReassemblyBytes; id++ {
+			for i := 0; i < maxLargeDatagramFragments-1 && r.bufferedBytes+fragmentSize <= maxLargeDatagramReassemblyBytes; i++ {
+				Expect(r.Add(fragment(id, i, maxLargeDatagramFragments, payload), now.Add(time.Duration(id)))).To(BeNil())
+				n++
+			}
+		}
+		Expect(r.bufferedBytes).To(Equal(n * fragmentSize))
+		// adding another fragment drops the oldest message
+		Expect(r.Add(fragment(1000, 0, 2, payload), now.Add(time.Millisecond))).To(BeNil())
+		Expect(r.bufferedBytes).To(BeNumerically("<=", maxLargeDatagramReassemblyBytes))
+		Expect(r.messages).ToNot(HaveKey(uint64(0)))
+		Expect(r.messages).To(HaveKey(uint64(1000)))
+	})
+})
@@ -1,8 +1,12 @@
 package quic
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"net"
 
+	"github.com/quic-go/quic-go/internal/handshake"
 	"github.com/quic-go/quic-go/internal/qerr"
 )
 
@@ -13,6 +17,7 @@ type (
 	StatelessResetError     = qerr.StatelessResetError
 	IdleTimeoutError        = qerr.IdleTimeoutError
 	HandshakeTimeoutError   = qerr.HandshakeTimeoutError
+	PTOLimitReachedError    = qerr.PTOLimitReachedError
 )
 
 type (
@@ -73,3 +78,86 @@ func (e *DatagramTooLargeError) Is(target error) bool {
 }
 
 func (e *DatagramTooLargeError) Error() string { return "DATAGRAM frame too large" }
+
+// MessageTooLargeError is returned from Connection.AcceptMessage if the message received
+// is larger than the maximum message size (see Config.MaxMessageSize).
+type MessageTooLargeError struct {
+	MaxMessageSize int64
+}
+
+func (e *MessageTooLargeError) Is(target error) bool {
+	_, ok := target.(*MessageTooLargeError)
+	return ok
+}
+
+func (e *MessageTooLargeError) Error() string {
+	return fmt.Sprintf("message exceeds the maximum message size of %d bytes", e.MaxMessageSize)
+}
+
+// DatagramQueuedTooLong is the error passed to the send callback of a DATAGRAM frame
+// that was dropped because it was queued for longer than the send timeout.
+// It is a net.Error that reports a temporary timeout.
+// Note that the connection might be closed with a timeout error as well (e.g. an IdleTimeoutError),
+// so errors.As should be used to tell whether a DATAGRAM frame expired and sending can be retried.
+type DatagramQueuedTooLong struct{}
+
+var _ net.Error = &DatagramQueuedTooLong{}
+
+func (e *DatagramQueuedTooLong) Timeout() bool   { return true }
+func (e *DatagramQueuedTooLong) Temporary() bool { return true }
+
+func (e *DatagramQueuedTooLong) Is(target error) bool {
+	_, ok := target.(*DatagramQueuedTooLong)
+	return ok
+}
+
+func (e *DatagramQueuedTooLong) Error() string { return "DATAGRAM frame queued for too long" }
+
+// StreamsBlockedError is returned from Connection.OpenStreamSync and Connection.OpenUniStreamSync
+// if the context was canceled while waiting for the peer to increase the stream limit (using a MAX_STREAMS frame).
+// It wraps the error returned by context.Context.Err.
+// It is a net.Error that reports a temporary error, and a timeout if the context's deadline was exceeded.
+type StreamsBlockedError struct {
+	Err error
+}
+
+var _ net.Error = &StreamsBlockedError{}
+
+func (e *StreamsBlockedError) Timeout() bool   { return errors.Is(e.Err, context.DeadlineExceeded) }
+func (e *StreamsBlockedError) Temporary() bool { return true }
+
+func (e *StreamsBlockedError) Error() string {
+	return fmt.Sprintf("blocked by the peer's stream limit: %s", e.Err)
+}
+
+func (e *StreamsBlockedError) Unwrap() error { return e.Err }
+
+// ZeroRTTRejectionReason is the reason why the server rejected 0-RTT, as inferred by the client.
+type ZeroRTTRejectionReason = handshake.ZeroRTTRejectionReason
+
+const (
+	ZeroRTTRejectionReasonUnknown             = handshake.ZeroRTTRejectionReasonUnknown
+	ZeroRTTRejectionReasonSessionTicket       = handshake.ZeroRTTRejectionReasonSessionTicket
+	ZeroRTTRejectionReasonALPN                = handshake.ZeroRTTRejectionReasonALPN
+	ZeroRTTRejectionReasonTransportParameters = handshake.ZeroRTTRejectionReasonTransportParameters
+)
+
+// ZeroRTTRejectedError is returned (on the client side) when the server rejects 0-RTT.
+// It matches Err0RTTRejected when using errors.Is.
+// The server doesn't send a reason when rejecting 0-RTT. The Reason is inferred by the client,
+// and can be used to decide if it's worth attempting 0-RTT again.
+type ZeroRTTRejectedError struct {
+	Reason ZeroRTTRejectionReason
+}
+
+func (e *ZeroRTTRejectedError) Is(target error) bool {
+	if target == Err0RTTRejected {
+		return true
+	}
+	_, ok := target.(*ZeroRTTRejectedError)
+	return ok
+}
+
+func (e *ZeroRTTRejectedError) Error() string {
+	return fmt.Sprintf("%s: %s", Err0RTTRejected, e.Reason)
+}
@@ -231,6 +231,26 @@ func (s *frameSorter) Pop() (protocol.ByteCount, []byte, func()) {
 	return offset, entry.Data, entry.DoneCb
 }
 
+// Peek returns (a copy of) up to n bytes of contiguous data at the read position, without dequeueing them.
+func (s *frameSorter) Peek(n int) []byte {
+	var b []byte
+	pos := s.readPos
+	for len(b) < n {
+		entry, ok := s.queue[pos]
+		if !ok {
+			break
+		}
+		b = append(b, entry.Data[:min(len(entry.Data), n-len(b))]...)
+		pos += protocol.ByteCount(len(entry.Data))
+	}
+	return b
+}
+
+// ContiguousEnd returns the offset up to which all data was received, without any gaps.
+func (s *frameSorter) ContiguousEnd() protocol.ByteCount {
+	return s.gaps.Front().Value.Start
+}
+
 // HasMoreData says if there is any more data queued at *any* offset.
 func (s *frameSorter) HasMoreData() bool {
 	return len(s.queue) > 0
@@ -124,6 +124,33 @@ var _ = Describe("frame sorter", func() {
 		Expect(s.HasMoreData()).To(BeFalse())
 	})
 
+	It("says up to which offset data was received contiguously", func() {
+		Expect(s.ContiguousEnd()).To(BeZero())
+		Expect(s.Push([]byte("bar"), 3, nil)).To(Succeed())
+		Expect(s.ContiguousEnd()).To(BeZero())
+		Expect(s.Push([]byte("foo"), 0, nil)).To(Succeed())
+		Expect(s.ContiguousEnd()).To(BeEquivalentTo(6))
+		Expect(s.Push([]byte("baz"), 10, nil)).To(Succeed())
+		Expect(s.ContiguousEnd()).To(BeEquivalentTo(6))
+		_, data, _ := s.Pop()
+		Expect(data).To(Equal([]byte("foo")))
+		Expect(s.ContiguousEnd()).To(BeEquivalentTo(6))
+	})
+
+	It("peeks at contiguous data", func() {
+		Expect(s.Peek(4)).To(BeEmpty())
+		Expect(s.Push([]byte("bar"), 3, nil)).To(Succeed())
+		Expect(s.Peek(4)).To(BeEmpty())
+		Expect(s.Push([]byte("foo"), 0, nil)).To(Succeed())
+		Expect(s.Push([]byte("baz"), 10, nil)).To(Succeed())
+		Expect(s.Peek(4)).To(Equal([]byte("foob")))
+		Expect(s.Peek(100)).To(Equal([]byte("foobar")))
+		// peeking doesn't dequeue any data
+		_, data, _ := s.Pop()
+		Expect(data).To(Equal([]byte("foo")))
+		Expect(s.Peek(2)).To(Equal([]byte("ba")))
+	})
+
 	Context("Gap handling", func() {
 		var dataCounter uint8
 
@@ -15,9 +15,14 @@ type framer interface {
 	HasData() bool
 
 	QueueControlFrame(wire.Frame)
+	// QueueControlFrameWithHandler queues a control frame, and sets the handler that is called
+	// when the packet containing the frame is acknowledged or declared lost.
+	QueueControlFrameWithHandler(wire.Frame, ackhandler.FrameHandler)
 	AppendControlFrames([]ackhandler.Frame, protocol.ByteCount, protocol.Version) ([]ackhandler.Frame, protocol.ByteCount)
 
 	AddActiveStream(protocol.StreamID)
+	SetStreamPriority(protocol.StreamID, StreamPriority)
+	RemoveStream(protocol.StreamID)
 	AppendStreamFrames([]ackhandler.StreamFrame, protocol.ByteCount, protocol.Version) ([]ackhandler.StreamFrame, protocol.ByteCount)
 
 	Handle0RTTRejection() error
@@ -35,16 +40,21 @@ const (
 	maxControlFrames = 16 << 10
 )
 
+const maxStreamUrgency = 7
+
 type framerI struct {
 	mutex sync.Mutex
 
 	streamGetter streamGetter
 
 	activeStreams map[protocol.StreamID]struct{}
-	streamQueue   ringbuffer.RingBuffer[protocol.StreamID]
+	// one queue per urgency level, the queue with the lowest urgency is served first
+	streamQueues [maxStreamUrgency + 1]ringbuffer.RingBuffer[protocol.StreamID]
+	// only contains streams that don't use the DefaultStreamPriority
+	priorities map[protocol.StreamID]StreamPriority
 
 	controlFrameMutex          sync.Mutex
-	controlFrames              []wire.Frame
+	controlFrames              []ackhandler.Frame
 	pathResponses              []*wire.PathResponseFrame
 	queuedTooManyControlFrames bool
 }
@@ -55,12 +65,13 @@ func newFramer(streamGetter streamGetter) framer {
 	return &framerI{
 		streamGetter:  streamGetter,
 		activeStreams: make(map[protocol.StreamID]struct{}),
+		priorities:    make(map[protocol.StreamID]StreamPriority),
 	}
 }
 
 func (f *framerI) HasData() bool {
 	f.mutex.Lock()
-	hasData := !f.streamQueue.Empty()
+	hasData := len(f.activeStreams) > 0
 	f.mutex.Unlock()
 	if hasData {
 		return true
@@ -71,6 +82,10 @@ func (f *framerI) HasData() bool {
 }
 
 func (f *framerI) QueueControlFrame(frame wire.Frame) {
+	f.QueueControlFrameWithHandler(frame, nil)
+}
+
+func (f *framerI) QueueControlFrameWithHandler(frame wire.Frame, handler ackhandler.FrameHandler) {
 	f.controlFrameMutex.Lock()
 	defer f.controlFrameMutex.Unlock()
 
@@ -89,7 +104,7 @@ func (f *framerI) QueueControlFrame(frame wire.Frame) {
 		f.queuedTooManyControlFrames = true
 		return
 	}
-	f.controlFrames = append(f.controlFrames, frame)
+	f.controlFrames = append(f.controlFrames, ackhandler.Frame{Frame: frame, Handler: handler})
 }
 
 func (f *framerI) AppendControlFrames(frames []ackhandler.Frame, maxLen protocol.ByteCount, v protocol.Version) ([]ackhandler.Frame, protocol.ByteCount) {
@@ -110,11 +125,11 @@ func (f *framerI) AppendControlFrames(frames []ackhandler.Frame, maxLen protocol
 
 	for len(f.controlFrames) > 0 {
 		frame := f.controlFrames[len(f.controlFrames)-1]
-		frameLen := frame.Length(v)
+		frameLen := frame.Frame.Length(v)
 		if length+frameLen > maxLen {
 			break
 		}
-		frames = append(frames, ackhandler.Frame{Frame: frame})
+		frames = append(frames, frame)
 		length += frameLen
 		f.controlFrames = f.controlFrames[:len(f.controlFrames)-1]
 	}
@@ -128,23 +143,89 @@ func (f *framerI) QueuedTooManyControlFrames() bool {
 func (f *framerI) AddActiveStream(id protocol.StreamID) {
 	f.mutex.Lock()
 	if _, ok := f.activeStreams[id]; !ok {
-		f.streamQueue.PushBack(id)
+		f.streamQueues[f.priority(id).Urgency].PushBack(id)
 		f.activeStreams[id] = struct{}{}
 	}
 	f.mutex.Unlock()
 }
 
+func (f *framerI) SetStreamPriority(id protocol.StreamID, p StreamPriority) {
+	p.Urgency = min(p.Urgency, maxStreamUrgency)
+
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	oldUrgency := f.priority(id).Urgency
+	if p == DefaultStreamPriority {
+		delete(f.priorities, id)
+	} else {
+		f.priorities[id] = p
+	}
+	if _, ok := f.activeStreams[id]; !ok || oldUrgency == p.Urgency {
+		return
+	}
+	// move the stream to the queue for its new urgency
+	queue := &f.streamQueues[oldUrgency]
+	for n := queue.Len(); n > 0; n-- {
+		if sid := queue.PopFront(); sid != id {
+			queue.PushBack(sid)
+		}
+	}
+	f.streamQueues[p.Urgency].PushBack(id)
+}
+
+// RemoveStream is called when a stream is completed.
+func (f *framerI) RemoveStream(id protocol.StreamID) {
+	f.mutex.Lock()
+	delete(f.priorities, id)
+	f.mutex.Unlock()
+}
+
+// priority must be called with the mutex held.
+func (f *framerI) priority(id protocol.StreamID) StreamPriority {
+	if p, ok := f.priorities[id]; ok {
+		return p
+	}
+	return DefaultStreamPriority
+}
+
 func (f *framerI) AppendStreamFrames(frames []ackhandler.StreamFrame, maxLen protocol.ByteCount, v protocol.Version) ([]ackhandler.StreamFrame, protocol.ByteCount) {
 	startLen := len(frames)
 	var length protocol.ByteCount
 	f.mutex.Lock()
-	// pop STREAM frames, until less than MinStreamFrameSize bytes are left in the packet
-	numActiveStreams := f.streamQueue.Len()
+	for i := range f.streamQueues {
+		if protocol.MinStreamFrameSize+length > maxLen {
+			break
+		}
+		frames, length = f.appendStreamFramesFromQueue(&f.streamQueues[i], frames, length, maxLen, v)
+	}
+	f.mutex.Unlock()
+	if len(frames) > startLen {
+		l := frames[len(frames)-1].Frame.Length(v)
+		// account for the smaller size of the last STREAM frame
+		frames[len(frames)-1].Frame.DataLenPresent = false
+		length += frames[len(frames)-1].Frame.Length(v) - l
+	}
+	return frames, length
+}
+
+// appendStreamFramesFromQueue pops STREAM frames from the streams in the queue,
+// until less than MinStreamFrameSize bytes are left in the packet.
+// It must be called with the mutex held.
+func (f *framerI) appendStreamFramesFromQueue(
+	queue *ringbuffer.RingBuffer[protocol.StreamID],
+	frames []ackhandler.StreamFrame,
+	length, maxLen protocol.ByteCount,
+	v protocol.Version,
+) ([]ackhandler.StreamFrame, protocol.ByteCount) {
+	// non-incremental streams that still have data stay at the front of the queue
+	var sequential []protocol.StreamID
+	numActiveStreams := queue.Len()
 	for i := 0; i < numActiveStreams; i++ {
 		if protocol.MinStreamFrameSize+length > maxLen {
 			break
 		}
-		id := f.streamQueue.PopFront()
+		id := queue.PopFront()
 		// This should never return an error. Better check it anyway.
 		// The stream will only be in the streamQueue, if it enqueued itself there.
 		str, err := f.streamGetter.GetOrOpenSendStream(id)
@@ -159,10 +240,12 @@ func (f *framerI) AppendStreamFrames(frames []ackhandler.StreamFrame, maxLen pro
 		// the STREAM frame (which will always have the DataLen set).
 		remainingLen += protocol.ByteCount(quicvarint.Len(uint64(remainingLen)))
 		frame, ok, hasMoreData := str.popStreamFrame(remainingLen, v)
-		if hasMoreData { // put the stream back in the queue (at the end)
-			f.streamQueue.PushBack(id)
-		} else { // no more data to send. Stream is not active
+		if !hasMoreData { // no more data to send. Stream is not active
 			delete(f.activeStreams, id)
+		} else if f.priority(id).Incremental { // put the stream back in the queue (at the end)
+			queue.PushBack(id)
+		} else {
+			sequential = append(sequential, id)
 		}
 		// The frame can be "nil"
 		// * if the receiveStream was canceled after it said it had data
@@ -173,12 +256,13 @@ func (f *framerI) AppendStreamFrames(frames []ackhandler.StreamFrame, maxLen pro
 		frames = append(frames, frame)
 		length += frame.Frame.Length(v)
 	}
-	f.mutex.Unlock()
-	if len(frames) > startLen {
-		l := frames[len(frames)-1].Frame.Length(v)
-		// account for the smaller size of the last STREAM frame
-		frames[len(frames)-1].Frame.DataLenPresent = false
-		length += frames[len(frames)-1].Frame.Length(v) - l
+	if len(sequential) > 0 {
+		for n := queue.Len(); n > 0; n-- {
+			sequential = append(sequential, queue.PopFront())
+		}
+		for _, id := range sequential {
+			queue.PushBack(id)
+		}
 	}
 	return frames, length
 }
@@ -188,13 +272,15 @@ func (f *framerI) Handle0RTTRejection() error {
 	defer f.mutex.Unlock()
 
 	f.controlFrameMutex.Lock()
-	f.streamQueue.Clear()
+	for i := range f.streamQueues {
+		f.streamQueues[i].Clear()
+	}
 	for id := range f.activeStreams {
 		delete(f.activeStreams, id)
 	}
 	var j int
 	for i, frame := range f.controlFrames {
-		switch frame.(type) {
+		switch frame.Frame.(type) {
 		case *wire.MaxDataFrame, *wire.MaxStreamDataFrame, *wire.MaxStreamsFrame:
 			return errors.New("didn't expect MAX_DATA / MAX_STREAM_DATA / MAX_STREAMS frame to be sent in 0-RTT")
 		case *wire.DataBlockedFrame, *wire.StreamDataBlockedFrame, *wire.StreamsBlockedFrame:
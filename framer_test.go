@@ -50,6 +50,14 @@ var _ = Describe("Framer", func() {
 			Expect(length).To(Equal(mdf.Length(version) + msf.Length(version)))
 		})
 
+		It("adds control frames with a handler", func() {
+			handler := &pingAckHandler{}
+			ping := &wire.PingFrame{}
+			framer.QueueControlFrameWithHandler(ping, handler)
+			frames, _ := framer.AppendControlFrames(nil, 1000, protocol.Version1)
+			Expect(frames).To(Equal([]ackhandler.Frame{{Frame: ping, Handler: handler}}))
+		})
+
 		It("says if it has data", func() {
 			Expect(framer.HasData()).To(BeFalse())
 			f := &wire.MaxDataFrame{MaximumData: 0x42}
@@ -443,6 +451,82 @@ var _ = Describe("Framer", func() {
 			Expect(length).To(Equal(f.Length(version)))
 		})
 
+		Context("stream priorities", func() {
+			It("drains streams with a higher priority first", func() {
+				streamGetter.EXPECT().GetOrOpenSendStream(id1).Return(stream1, nil)
+				streamGetter.EXPECT().GetOrOpenSendStream(id2).Return(stream2, nil).Times(2)
+				f1 := &wire.StreamFrame{StreamID: id1, Data: []byte("foobar")}
+				f21 := &wire.StreamFrame{StreamID: id2, Data: []byte("foobaz")}
+				f22 := &wire.StreamFrame{StreamID: id2, Data: []byte("raboof")}
+				stream2.EXPECT().popStreamFrame(gomock.Any(), protocol.Version1).Return(ackhandler.StreamFrame{Frame: f21}, true, true)
+				stream2.EXPECT().popStreamFrame(gomock.Any(), protocol.Version1).Return(ackhandler.StreamFrame{Frame: f22}, true, false)
+				stream1.EXPECT().popStreamFrame(gomock.Any(), protocol.Version1).Return(ackhandler.StreamFrame{Frame: f1}, true, false)
+				framer.SetStreamPriority(id2, StreamPriority{Urgency: 0, Incremental: true})
+				framer.AddActiveStream(id1)
+				framer.AddActiveStream(id2)
+				for _, f := range []*wire.StreamFrame{f21, f22, f1} {
+					frames, _ := framer.AppendStreamFrames(nil, protocol.MinStreamFrameSize, protocol.Version1)
+					Expect(frames).To(HaveLen(1))
+					Expect(frames[0].Frame).To(Equal(f))
+				}
+				Expect(framer.HasData()).To(BeFalse())
+			})
+
+			It("moves an active stream when its priority changes", func() {
+				streamGetter.EXPECT().GetOrOpenSendStream(id1).Return(stream1, nil)
+				streamGetter.EXPECT().GetOrOpenSendStream(id2).Return(stream2, nil)
+				f1 := &wire.StreamFrame{StreamID: id1, Data: []byte("foobar")}
+				f2 := &wire.StreamFrame{StreamID: id2, Data: []byte("foobaz")}
+				stream2.EXPECT().popStreamFrame(gomock.Any(), protocol.Version1).Return(ackhandler.StreamFrame{Frame: f2}, true, false)
+				stream1.EXPECT().popStreamFrame(gomock.Any(), protocol.Version1).Return(ackhandler.StreamFrame{Frame: f1}, true, false)
+				framer.AddActiveStream(id1)
+				framer.AddActiveStream(id2)
+				framer.SetStreamPriority(id1, StreamPriority{Urgency: 5})
+				frames, _ := framer.AppendStreamFrames(nil, 1000, protocol.Version1)
+				Expect(frames).To(HaveLen(2))
+				Expect(frames[0].Frame).To(Equal(f2))
+				Expect(frames[1].Frame).To(Equal(f1))
+			})
+
+			It("sends non-incremental streams one after the other", func() {
+				streamGetter.EXPECT().GetOrOpenSendStream(id1).Return(stream1, nil).Times(2)
+				streamGetter.EXPECT().GetOrOpenSendStream(id2).Return(stream2, nil)
+				f11 := &wire.StreamFrame{StreamID: id1, Data: []byte("foobar")}
+				f12 := &wire.StreamFrame{StreamID: id1, Data: []byte("foobaz")}
+				f2 := &wire.StreamFrame{StreamID: id2, Data: []byte("raboof")}
+				stream1.EXPECT().popStreamFrame(gomock.Any(), protocol.Version1).Return(ackhandler.StreamFrame{Frame: f11}, true, true)
+				stream1.EXPECT().popStreamFrame(gomock.Any(), protocol.Version1).Return(ackhandler.StreamFrame{Frame: f12}, true, false)
+				stream2.EXPECT().popStreamFrame(gomock.Any(), protocol.Version1).Return(ackhandler.StreamFrame{Frame: f2}, true, false)
+				framer.SetStreamPriority(id1, StreamPriority{Urgency: 3})
+				framer.SetStreamPriority(id2, StreamPriority{Urgency: 3})
+				framer.AddActiveStream(id1)
+				framer.AddActiveStream(id2)
+				// unlike incremental streams, stream 1 is not re-queued at the end
+				for _, f := range []*wire.StreamFrame{f11, f12, f2} {
+					frames, _ := framer.AppendStreamFrames(nil, protocol.MinStreamFrameSize, protocol.Version1)
+					Expect(frames).To(HaveLen(1))
+					Expect(frames[0].Frame).To(Equal(f))
+				}
+			})
+
+			It("treats urgencies larger than 7 as 7", func() {
+				streamGetter.EXPECT().GetOrOpenSendStream(id1).Return(stream1, nil)
+				streamGetter.EXPECT().GetOrOpenSendStream(id2).Return(stream2, nil)
+				f1 := &wire.StreamFrame{StreamID: id1, Data: []byte("foobar")}
+				f2 := &wire.StreamFrame{StreamID: id2, Data: []byte("foobaz")}
+				stream1.EXPECT().popStreamFrame(gomock.Any(), protocol.Version1).Return(ackhandler.StreamFrame{Frame: f1}, true, false)
+				stream2.EXPECT().popStreamFrame(gomock.Any(), protocol.Version1).Return(ackhandler.StreamFrame{Frame: f2}, true, false)
+				framer.SetStreamPriority(id1, StreamPriority{Urgency: 100, Incremental: true})
+				framer.SetStreamPriority(id2, StreamPriority{Urgency: 7, Incremental: true})
+				framer.AddActiveStream(id1)
+				framer.AddActiveStream(id2)
+				frames, _ := framer.AppendStreamFrames(nil, 1000, protocol.Version1)
+				Expect(frames).To(HaveLen(2))
+				Expect(frames[0].Frame).To(Equal(f1))
+				Expect(frames[1].Frame).To(Equal(f2))
+			})
+		})
+
 		It("drops all STREAM frames when 0-RTT is rejected", func() {
 			framer.AddActiveStream(id1)
 			Expect(framer.Handle0RTTRejection()).To(Succeed())
@@ -248,6 +248,22 @@ func getFrames() []wire.Frame {
 		Data: data2,
 	})
 
+	finalSize := getRandomNumber()
+	frames = append(frames, &wire.ResetStreamAtFrame{
+		StreamID:     protocol.StreamID(getRandomNumber()),
+		ErrorCode:    quic.StreamErrorCode(getRandomNumber()),
+		FinalSize:    protocol.ByteCount(finalSize),
+		ReliableSize: protocol.ByteCount(getRandomNumberLowerOrEqual(finalSize)),
+	})
+
+	frames = append(frames, &wire.AckFrequencyFrame{
+		SequenceNumber:        getRandomNumber(),
+		AckElicitingThreshold: getRandomNumber(),
+		RequestMaxAckDelay:    time.Duration(getRandomNumberLowerOrEqual(uint64(protocol.MaxMaxAckDelay/time.Microsecond))) * time.Microsecond,
+		ReorderingThreshold:   getRandomNumber(),
+	})
+	frames = append(frames, &wire.ImmediateAckFrame{})
+
 	return frames
 }
 
@@ -31,6 +31,7 @@ func main() {
 		false,
 		utils.NewRTTStats(),
 		nil,
+		utils.DefaultClock{},
 		utils.DefaultLogger.WithPrefix("client"),
 		protocol.Version1,
 	)
@@ -46,6 +47,7 @@ func main() {
 		false,
 		utils.NewRTTStats(),
 		nil,
+		utils.DefaultClock{},
 		utils.DefaultLogger.WithPrefix("server"),
 		protocol.Version1,
 	)
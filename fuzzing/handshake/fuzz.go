@@ -284,6 +284,7 @@ func runHandshake(runConfig [confLen]byte, messageConfig uint8, clientConf *tls.
 		enable0RTTClient,
 		utils.NewRTTStats(),
 		nil,
+		utils.DefaultClock{},
 		utils.DefaultLogger.WithPrefix("client"),
 		protocol.Version1,
 	)
@@ -301,6 +302,7 @@ func runHandshake(runConfig [confLen]byte, messageConfig uint8, clientConf *tls.
 		enable0RTTServer,
 		utils.NewRTTStats(),
 		nil,
+		utils.DefaultClock{},
 		utils.DefaultLogger.WithPrefix("server"),
 		protocol.Version1,
 	)
@@ -57,7 +57,7 @@ func newToken(tg *handshake.TokenGenerator, data []byte) int {
 		}
 	}
 	start := time.Now()
-	encrypted, err := tg.NewToken(addr)
+	encrypted, err := tg.NewToken(addr, time.Now())
 	if err != nil {
 		panic(err)
 	}
@@ -116,7 +116,7 @@ func newRetryToken(tg *handshake.TokenGenerator, data []byte) int {
 			IP:   net.IP(data[2:]),
 		}
 	}
-	encrypted, err := tg.NewRetryToken(addr, origDestConnID, retrySrcConnID)
+	encrypted, err := tg.NewRetryToken(addr, origDestConnID, retrySrcConnID, time.Now())
 	if err != nil {
 		panic(err)
 	}
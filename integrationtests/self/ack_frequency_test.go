@@ -0,0 +1,102 @@
+package self_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"time"
+
+	"github.com/quic-go/quic-go"
+	"github.com/quic-go/quic-go/logging"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ACK Frequency", func() {
+	It("errors when the extension wasn't negotiated", func() {
+		server, err := quic.ListenAddr("localhost:0", getTLSConfig(), getQuicConfig(nil))
+		Expect(err).ToNot(HaveOccurred())
+		defer server.Close()
+
+		conn, err := quic.DialAddr(
+			context.Background(),
+			fmt.Sprintf("localhost:%d", server.Addr().(*net.UDPAddr).Port),
+			getTLSClientConfig(),
+			getQuicConfig(&quic.Config{EnableAckFrequency: true}),
+		)
+		Expect(err).ToNot(HaveOccurred())
+		defer conn.CloseWithError(0, "")
+		Expect(conn.ConnectionState().SupportsAckFrequency).To(BeFalse())
+		Expect(conn.RequestImmediateAck()).To(MatchError("ACK Frequency extension not negotiated"))
+		Expect(conn.RequestAckFrequency(10, 50*time.Millisecond)).To(MatchError("ACK Frequency extension not negotiated"))
+	})
+
+	It("sends ACK_FREQUENCY and IMMEDIATE_ACK frames", func() {
+		serverCounter, serverTracer := newPacketTracer()
+		server, err := quic.ListenAddr(
+			"localhost:0",
+			getTLSConfig(),
+			getQuicConfig(&quic.Config{
+				EnableAckFrequency: true,
+				Tracer:             newTracer(serverTracer),
+			}),
+		)
+		Expect(err).ToNot(HaveOccurred())
+		defer server.Close()
+
+		serverConnChan := make(chan quic.Connection, 1)
+		go func() {
+			defer GinkgoRecover()
+			conn, err := server.Accept(context.Background())
+			Expect(err).ToNot(HaveOccurred())
+			serverConnChan <- conn
+			str, err := conn.AcceptStream(context.Background())
+			Expect(err).ToNot(HaveOccurred())
+			data, err := io.ReadAll(str)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(data).To(Equal(PRData))
+			conn.CloseWithError(0, "")
+		}()
+
+		conn, err := quic.DialAddr(
+			context.Background(),
+			fmt.Sprintf("localhost:%d", server.Addr().(*net.UDPAddr).Port),
+			getTLSClientConfig(),
+			getQuicConfig(&quic.Config{EnableAckFrequency: true}),
+		)
+		Expect(err).ToNot(HaveOccurred())
+		defer conn.CloseWithError(0, "")
+		Expect(conn.ConnectionState().SupportsAckFrequency).To(BeTrue())
+		var serverConn quic.Connection
+		Eventually(serverConnChan).Should(Receive(&serverConn))
+		Expect(serverConn.ConnectionState().SupportsAckFrequency).To(BeTrue())
+
+		Expect(conn.RequestAckFrequency(10, 50*time.Millisecond)).To(Succeed())
+		str, err := conn.OpenStream()
+		Expect(err).ToNot(HaveOccurred())
+		_, err = str.Write(PRData)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(conn.RequestImmediateAck()).To(Succeed())
+		Expect(str.Close()).To(Succeed())
+		Eventually(conn.Context().Done()).Should(BeClosed())
+
+		var ackFrequencyFrames []*logging.AckFrequencyFrame
+		var numImmediateAcks int
+		for _, p := range serverCounter.getRcvdShortHeaderPackets() {
+			for _, f := range p.frames {
+				switch f := f.(type) {
+				case *logging.AckFrequencyFrame:
+					ackFrequencyFrames = append(ackFrequencyFrames, f)
+				case *logging.ImmediateAckFrame:
+					numImmediateAcks++
+				}
+			}
+		}
+		Expect(ackFrequencyFrames).To(HaveLen(1))
+		Expect(ackFrequencyFrames[0].AckElicitingThreshold).To(BeEquivalentTo(10))
+		Expect(ackFrequencyFrames[0].RequestMaxAckDelay).To(Equal(50 * time.Millisecond))
+		Expect(numImmediateAcks).To(BeNumerically(">=", 1))
+	})
+})
@@ -0,0 +1,86 @@
+package self_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"time"
+
+	"github.com/quic-go/quic-go"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Byte Counters", func() {
+	It("counts the bytes sent and received", func() {
+		ln, err := quic.ListenAddr("localhost:0", getTLSConfig(), getQuicConfig(nil))
+		Expect(err).ToNot(HaveOccurred())
+		defer ln.Close()
+
+		conn, err := quic.DialAddr(
+			context.Background(),
+			fmt.Sprintf("localhost:%d", ln.Addr().(*net.UDPAddr).Port),
+			getTLSClientConfig(),
+			getQuicConfig(nil),
+		)
+		Expect(err).ToNot(HaveOccurred())
+		defer conn.CloseWithError(0, "")
+		serverConn, err := ln.Accept(context.Background())
+		Expect(err).ToNot(HaveOccurred())
+		defer serverConn.CloseWithError(0, "")
+
+		// the handshake was completed, so both sides have sent and received some bytes
+		Expect(conn.BytesSent()).ToNot(BeZero())
+		Expect(conn.BytesReceived()).ToNot(BeZero())
+		sentBefore := conn.BytesSent()
+
+		// poll the counters while the transfer is running
+		done := make(chan struct{})
+		pollerDone := make(chan struct{})
+		go func() {
+			defer GinkgoRecover()
+			defer close(pollerDone)
+			var last uint64
+			for {
+				select {
+				case <-done:
+					return
+				default:
+				}
+				sent := conn.BytesSent()
+				Expect(sent).To(BeNumerically(">=", last))
+				last = sent
+				time.Sleep(time.Millisecond)
+			}
+		}()
+
+		str, err := conn.OpenUniStream()
+		Expect(err).ToNot(HaveOccurred())
+		_, err = str.Write(PRData)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(str.Close()).To(Succeed())
+		serverStr, err := serverConn.AcceptUniStream(context.Background())
+		Expect(err).ToNot(HaveOccurred())
+		data, err := io.ReadAll(serverStr)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(data).To(Equal(PRData))
+		// make sure the server's ACKs for the stream data arrive at the client
+		Eventually(func() uint64 { return conn.Stats().BytesInFlight }).Should(BeZero())
+		close(done)
+		Eventually(pollerDone).Should(BeClosed())
+
+		// The stream data is sent in packets, which adds some overhead.
+		sent := conn.BytesSent() - sentBefore
+		Expect(sent).To(BeNumerically(">", len(PRData)))
+		Expect(sent).To(BeNumerically("<", len(PRData)*11/10))
+		// The server can't have received more than the client sent.
+		// Packets might have been lost, and the server might not have read all packets from the socket yet.
+		Expect(serverConn.BytesReceived()).To(BeNumerically("<=", conn.BytesSent()))
+		Expect(serverConn.BytesReceived()).To(BeNumerically(">", len(PRData)))
+		Expect(conn.BytesReceived()).To(BeNumerically("<=", serverConn.BytesSent()))
+		// The server only sent the handshake and ACKs.
+		Expect(serverConn.BytesSent()).To(BeNumerically("<", len(PRData)/10))
+	})
+})
@@ -263,6 +263,43 @@ var _ = Describe("Stream Cancellations", func() {
 			return streamCount
 		}
 
+		It("delivers the data received before the reset, if configured to drain on reset", func() {
+			server, err := quic.ListenAddr("localhost:0", getTLSConfig(), getQuicConfig(nil))
+			Expect(err).ToNot(HaveOccurred())
+			defer server.Close()
+
+			conn, err := quic.DialAddr(
+				context.Background(),
+				fmt.Sprintf("localhost:%d", server.Addr().(*net.UDPAddr).Port),
+				getTLSClientConfig(),
+				getQuicConfig(nil),
+			)
+			Expect(err).ToNot(HaveOccurred())
+			defer conn.CloseWithError(0, "")
+
+			str, err := conn.OpenStream()
+			Expect(err).ToNot(HaveOccurred())
+			// the server only accepts the stream once it receives data on it
+			_, err = str.Write([]byte("foo"))
+			Expect(err).ToNot(HaveOccurred())
+			serverConn, err := server.Accept(context.Background())
+			Expect(err).ToNot(HaveOccurred())
+			serverStr, err := serverConn.AcceptStream(context.Background())
+			Expect(err).ToNot(HaveOccurred())
+			serverStr.SetDrainOnReset(true)
+
+			data := GeneratePRData(10000)
+			_, err = str.Write(data)
+			Expect(err).ToNot(HaveOccurred())
+			// make sure that all data was received before resetting the stream
+			Eventually(func() uint64 { return serverConn.Stats().BufferedStreamBytes }).Should(BeEquivalentTo(3 + len(data)))
+			str.CancelWrite(1337)
+
+			b, err := io.ReadAll(serverStr)
+			Expect(err).To(MatchError(&quic.StreamError{StreamID: str.StreamID(), ErrorCode: 1337, Remote: true}))
+			Expect(b).To(Equal(append([]byte("foo"), data...)))
+		})
+
 		It("downloads when the server cancels some streams immediately", func() {
 			server, err := quic.ListenAddr("localhost:0", getTLSConfig(), nil)
 			Expect(err).ToNot(HaveOccurred())
@@ -2,8 +2,10 @@ package self_test
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net"
+	"strings"
 	"sync/atomic"
 	"time"
 
@@ -75,4 +77,97 @@ var _ = Describe("Connection ID lengths tests", func() {
 			Expect(<-dropped).To(Equal(first)) // these packets are all identical
 		}
 	})
+
+	It("sends the reason phrase to the peer, truncating long reason phrases", func() {
+		server, err := quic.ListenAddr("localhost:0", getTLSConfig(), getQuicConfig(nil))
+		Expect(err).ToNot(HaveOccurred())
+		defer server.Close()
+
+		for _, tc := range []struct{ reason, expected string }{
+			{reason: "short reason", expected: "short reason"},
+			{reason: strings.Repeat("€", 1000), expected: strings.Repeat("€", 85)},
+		} {
+			conn, err := quic.DialAddr(
+				context.Background(),
+				fmt.Sprintf("localhost:%d", server.Addr().(*net.UDPAddr).Port),
+				getTLSClientConfig(),
+				getQuicConfig(nil),
+			)
+			Expect(err).ToNot(HaveOccurred())
+			sconn, err := server.Accept(context.Background())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(sconn.CloseWithError(1337, tc.reason)).To(Succeed())
+
+			Eventually(conn.Context().Done()).Should(BeClosed())
+			var appErr *quic.ApplicationError
+			Expect(errors.As(context.Cause(conn.Context()), &appErr)).To(BeTrue())
+			Expect(appErr.Remote).To(BeTrue())
+			Expect(appErr.ErrorCode).To(BeEquivalentTo(1337))
+			Expect(appErr.ErrorMessage).To(Equal(tc.expected))
+		}
+	})
+
+	Context("unblocking AcceptStream", func() {
+		// acceptAndClose blocks in AcceptStream and AcceptUniStream on one side of the connection,
+		// closes the connection using closeFn, and returns the errors returned by the Accept calls.
+		acceptAndClose := func(closeFn func(client, server quic.Connection)) []error {
+			server, err := quic.ListenAddr("localhost:0", getTLSConfig(), getQuicConfig(nil))
+			Expect(err).ToNot(HaveOccurred())
+			defer server.Close()
+
+			conn, err := quic.DialAddr(
+				context.Background(),
+				fmt.Sprintf("localhost:%d", server.Addr().(*net.UDPAddr).Port),
+				getTLSClientConfig(),
+				getQuicConfig(nil),
+			)
+			Expect(err).ToNot(HaveOccurred())
+			defer conn.CloseWithError(0, "")
+			sconn, err := server.Accept(context.Background())
+			Expect(err).ToNot(HaveOccurred())
+			defer sconn.CloseWithError(0, "")
+
+			errChan := make(chan error, 2)
+			go func() {
+				_, err := conn.AcceptStream(context.Background())
+				errChan <- err
+			}()
+			go func() {
+				_, err := conn.AcceptUniStream(context.Background())
+				errChan <- err
+			}()
+			Consistently(errChan, scaleDuration(50*time.Millisecond)).ShouldNot(Receive())
+			closeFn(conn, sconn)
+			errs := make([]error, 0, 2)
+			for i := 0; i < 2; i++ {
+				var err error
+				Eventually(errChan, scaleDuration(100*time.Millisecond)).Should(Receive(&err))
+				errs = append(errs, err)
+			}
+			return errs
+		}
+
+		It("unblocks when the connection is closed locally", func() {
+			for _, err := range acceptAndClose(func(client, _ quic.Connection) {
+				Expect(client.CloseWithError(1337, "closing")).To(Succeed())
+			}) {
+				var appErr *quic.ApplicationError
+				Expect(errors.As(err, &appErr)).To(BeTrue())
+				Expect(appErr.Remote).To(BeFalse())
+				Expect(appErr.ErrorCode).To(BeEquivalentTo(1337))
+			}
+		})
+
+		It("unblocks when the peer closes the connection", func() {
+			for _, err := range acceptAndClose(func(_, server quic.Connection) {
+				Expect(server.CloseWithError(1337, "closing")).To(Succeed())
+			}) {
+				var appErr *quic.ApplicationError
+				Expect(errors.As(err, &appErr)).To(BeTrue())
+				Expect(appErr.Remote).To(BeTrue())
+				Expect(appErr.ErrorCode).To(BeEquivalentTo(1337))
+				Expect(appErr.ErrorMessage).To(Equal("closing"))
+			}
+		})
+	})
 })
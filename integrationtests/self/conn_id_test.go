@@ -7,9 +7,11 @@ import (
 	"io"
 	mrand "math/rand"
 	"net"
+	"sync"
 
 	"github.com/quic-go/quic-go"
 	"github.com/quic-go/quic-go/internal/protocol"
+	"github.com/quic-go/quic-go/logging"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -122,6 +124,84 @@ var _ = Describe("Connection ID lengths tests", func() {
 		runClient(ln.Addr(), randomConnIDLen(), nil)
 	})
 
+	It("downloads a file when both client and server use 20 byte connection IDs", func() {
+		ln, closeFn := runServer(protocol.MaxConnIDLen, nil)
+		defer closeFn()
+		runClient(ln.Addr(), protocol.MaxConnIDLen, nil)
+	})
+
+	// runWithConfig downloads a file using ListenAddr and DialAddr, which create their own Transports.
+	// It returns the lengths of the server's and the client's connection IDs used in short header packets.
+	runWithConfig := func(serverConnIDLen, clientConnIDLen int) (serverLen, clientLen int) {
+		var mutex sync.Mutex
+		serverLen, clientLen = -1, -1
+		server, err := quic.ListenAddr(
+			"localhost:0",
+			getTLSConfig(),
+			getQuicConfig(&quic.Config{
+				ConnectionIDLength: serverConnIDLen,
+				Tracer: newTracer(&logging.ConnectionTracer{
+					SentShortHeaderPacket: func(hdr *logging.ShortHeader, _ logging.ByteCount, _ logging.ECN, _ *logging.AckFrame, _ []logging.Frame) {
+						mutex.Lock()
+						clientLen = hdr.DestConnectionID.Len()
+						mutex.Unlock()
+					},
+				}),
+			}),
+		)
+		Expect(err).ToNot(HaveOccurred())
+		defer server.Close()
+		go func() {
+			defer GinkgoRecover()
+			conn, err := server.Accept(context.Background())
+			Expect(err).ToNot(HaveOccurred())
+			str, err := conn.OpenStream()
+			Expect(err).ToNot(HaveOccurred())
+			defer str.Close()
+			_, err = str.Write(PRData)
+			Expect(err).ToNot(HaveOccurred())
+		}()
+
+		conn, err := quic.DialAddr(
+			context.Background(),
+			fmt.Sprintf("localhost:%d", server.Addr().(*net.UDPAddr).Port),
+			getTLSClientConfig(),
+			getQuicConfig(&quic.Config{
+				ConnectionIDLength: clientConnIDLen,
+				Tracer: newTracer(&logging.ConnectionTracer{
+					SentShortHeaderPacket: func(hdr *logging.ShortHeader, _ logging.ByteCount, _ logging.ECN, _ *logging.AckFrame, _ []logging.Frame) {
+						mutex.Lock()
+						serverLen = hdr.DestConnectionID.Len()
+						mutex.Unlock()
+					},
+				}),
+			}),
+		)
+		Expect(err).ToNot(HaveOccurred())
+		defer conn.CloseWithError(0, "")
+		str, err := conn.AcceptStream(context.Background())
+		Expect(err).ToNot(HaveOccurred())
+		data, err := io.ReadAll(str)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(data).To(Equal(PRData))
+
+		mutex.Lock()
+		defer mutex.Unlock()
+		return serverLen, clientLen
+	}
+
+	It("uses the connection ID length configured in the Config", func() {
+		serverLen, clientLen := runWithConfig(protocol.MaxConnIDLen, protocol.MaxConnIDLen)
+		Expect(serverLen).To(Equal(protocol.MaxConnIDLen))
+		Expect(clientLen).To(Equal(protocol.MaxConnIDLen))
+	})
+
+	It("uses 0 byte connection IDs for the client, if configured in the Config", func() {
+		serverLen, clientLen := runWithConfig(1, 0)
+		Expect(serverLen).To(Equal(1))
+		Expect(clientLen).To(BeZero())
+	})
+
 	It("downloads a file when both client and server use a custom connection ID generator", func() {
 		ln, closeFn := runServer(0, &connIDGenerator{length: randomConnIDLen()})
 		defer closeFn()
@@ -0,0 +1,58 @@
+//go:build go1.26
+
+package self_test
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+
+	"github.com/quic-go/quic-go"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Connection State", func() {
+	dial := func(serverTLSConf *tls.Config) (client, server quic.ConnectionState) {
+		ln, err := quic.ListenAddr("localhost:0", serverTLSConf, getQuicConfig(nil))
+		Expect(err).ToNot(HaveOccurred())
+		defer ln.Close()
+
+		conn, err := quic.DialAddr(
+			context.Background(),
+			fmt.Sprintf("localhost:%d", ln.Addr().(*net.UDPAddr).Port),
+			getTLSClientConfig(),
+			getQuicConfig(nil),
+		)
+		Expect(err).ToNot(HaveOccurred())
+		defer conn.CloseWithError(0, "")
+		serverConn, err := ln.Accept(context.Background())
+		Expect(err).ToNot(HaveOccurred())
+		defer serverConn.CloseWithError(0, "")
+		return conn.ConnectionState(), serverConn.ConnectionState()
+	}
+
+	It("reports the negotiated crypto parameters", func() {
+		serverTLSConf := getTLSConfig()
+		serverTLSConf.CurvePreferences = []tls.CurveID{tls.X25519}
+		client, server := dial(serverTLSConf)
+		for _, state := range []quic.ConnectionState{client, server} {
+			Expect(state.KeyExchangeGroup).To(Equal(tls.X25519))
+			Expect(state.TLS.CipherSuite).ToNot(BeZero())
+			Expect(state.HelloRetryRequest).To(BeFalse())
+			Expect(state.HandshakeDuration).ToNot(BeZero())
+		}
+	})
+
+	It("reports when a HelloRetryRequest was performed", func() {
+		serverTLSConf := getTLSConfig()
+		serverTLSConf.CurvePreferences = []tls.CurveID{tls.CurveP384}
+		client, server := dial(serverTLSConf)
+		for _, state := range []quic.ConnectionState{client, server} {
+			Expect(state.KeyExchangeGroup).To(Equal(tls.CurveP384))
+			Expect(state.HelloRetryRequest).To(BeTrue())
+		}
+	})
+})
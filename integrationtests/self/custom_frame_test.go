@@ -0,0 +1,77 @@
+package self_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+
+	"github.com/quic-go/quic-go"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Custom frames", func() {
+	const frameType = 0x1337
+
+	It("sends and receives custom frames", func() {
+		server, err := quic.ListenAddr(
+			"localhost:0",
+			getTLSConfig(),
+			getQuicConfig(&quic.Config{
+				CustomFrameHandlers: map[uint64]func(quic.Connection, []byte){
+					// echo the payload back to the client
+					frameType: func(conn quic.Connection, payload []byte) {
+						defer GinkgoRecover()
+						Expect(conn.SendCustomFrame(frameType, append([]byte("echo: "), payload...))).To(Succeed())
+					},
+				},
+			}),
+		)
+		Expect(err).ToNot(HaveOccurred())
+		defer server.Close()
+
+		received := make(chan []byte, 10)
+		conn, err := quic.DialAddr(
+			context.Background(),
+			fmt.Sprintf("localhost:%d", server.Addr().(*net.UDPAddr).Port),
+			getTLSClientConfig(),
+			getQuicConfig(&quic.Config{
+				CustomFrameHandlers: map[uint64]func(quic.Connection, []byte){
+					frameType: func(_ quic.Connection, payload []byte) { received <- payload },
+				},
+			}),
+		)
+		Expect(err).ToNot(HaveOccurred())
+		defer conn.CloseWithError(0, "")
+
+		Expect(conn.SendCustomFrame(frameType, []byte("foobar"))).To(Succeed())
+		Eventually(received).Should(Receive(Equal([]byte("echo: foobar"))))
+		Expect(conn.SendCustomFrame(frameType+1, []byte("foobar"))).To(MatchError("custom frame type 0x1338 not registered"))
+	})
+
+	It("closes the connection when receiving a custom frame that the peer didn't register", func() {
+		server, err := quic.ListenAddr("localhost:0", getTLSConfig(), getQuicConfig(nil))
+		Expect(err).ToNot(HaveOccurred())
+		defer server.Close()
+
+		conn, err := quic.DialAddr(
+			context.Background(),
+			fmt.Sprintf("localhost:%d", server.Addr().(*net.UDPAddr).Port),
+			getTLSClientConfig(),
+			getQuicConfig(&quic.Config{
+				CustomFrameHandlers: map[uint64]func(quic.Connection, []byte){frameType: func(quic.Connection, []byte) {}},
+			}),
+		)
+		Expect(err).ToNot(HaveOccurred())
+		defer conn.CloseWithError(0, "")
+
+		Expect(conn.SendCustomFrame(frameType, []byte("foobar"))).To(Succeed())
+		Eventually(conn.Context().Done()).Should(BeClosed())
+		var transportErr *quic.TransportError
+		Expect(errors.As(context.Cause(conn.Context()), &transportErr)).To(BeTrue())
+		Expect(transportErr.Remote).To(BeTrue())
+		Expect(transportErr.ErrorCode).To(Equal(quic.FrameEncodingError))
+	})
+})
@@ -3,6 +3,7 @@ package self_test
 import (
 	"context"
 	"encoding/binary"
+	"errors"
 	"fmt"
 	mrand "math/rand"
 	"net"
@@ -147,6 +148,54 @@ var _ = Describe("Datagram test", func() {
 		wire.MaxDatagramSize = oldMaxDatagramSize
 	})
 
+	It("slows down the sender when the receiver doesn't read datagrams", func() {
+		const rcvQueueLen = 4
+		const num = 100
+		server, err := quic.ListenAddr(
+			"localhost:0",
+			getTLSConfig(),
+			getQuicConfig(&quic.Config{EnableDatagrams: true, EnableExperimentalDatagramFlowControl: true, DatagramReceiveQueueLen: rcvQueueLen}),
+		)
+		Expect(err).ToNot(HaveOccurred())
+		defer server.Close()
+
+		conn, err := quic.DialAddr(
+			context.Background(),
+			server.Addr().String(),
+			getTLSClientConfig(),
+			getQuicConfig(&quic.Config{EnableDatagrams: true, EnableExperimentalDatagramFlowControl: true}),
+		)
+		Expect(err).ToNot(HaveOccurred())
+		defer conn.CloseWithError(0, "")
+		serverConn, err := server.Accept(context.Background())
+		Expect(err).ToNot(HaveOccurred())
+
+		var sent atomic.Int32
+		go func() {
+			defer GinkgoRecover()
+			for i := 0; i < num; i++ {
+				b := make([]byte, 8)
+				binary.BigEndian.PutUint64(b, uint64(i))
+				Expect(conn.SendDatagram(b)).To(Succeed())
+				sent.Add(1)
+			}
+		}()
+
+		// The server's receive queue fills up, and the client's send queue (32 datagrams) fills up afterwards.
+		Eventually(func() int32 { return sent.Load() }).Should(BeNumerically(">=", rcvQueueLen))
+		Consistently(func() int32 { return sent.Load() }, scaleDuration(50*time.Millisecond)).Should(BeNumerically("<=", rcvQueueLen+32))
+
+		// once the server reads the datagrams, all of them are delivered
+		for i := 0; i < num; i++ {
+			ctx, cancel := context.WithTimeout(context.Background(), scaleDuration(time.Second))
+			b, err := serverConn.ReceiveDatagram(ctx)
+			cancel()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(binary.BigEndian.Uint64(b)).To(BeEquivalentTo(i))
+		}
+		Expect(sent.Load()).To(BeEquivalentTo(num))
+	})
+
 	It("server can disable datagram", func() {
 		proxyPort, close := startServerAndProxy(false, true)
 		raddr, err := net.ResolveUDPAddr("udp", fmt.Sprintf("localhost:%d", proxyPort))
@@ -184,4 +233,84 @@ var _ = Describe("Datagram test", func() {
 		close()
 		conn.CloseWithError(0, "")
 	})
+	Context("requiring datagram support", func() {
+		It("establishes the connection if the peer supports datagrams", func() {
+			ln, err := quic.ListenAddr("localhost:0", getTLSConfig(), getQuicConfig(&quic.Config{EnableDatagrams: true}))
+			Expect(err).ToNot(HaveOccurred())
+			defer ln.Close()
+			conn, err := quic.DialAddr(
+				context.Background(),
+				ln.Addr().String(),
+				getTLSClientConfig(),
+				getQuicConfig(&quic.Config{EnableDatagrams: true, RequireDatagrams: true}),
+			)
+			Expect(err).ToNot(HaveOccurred())
+			defer conn.CloseWithError(0, "")
+			Expect(conn.DatagramsSupported()).To(BeTrue())
+			serverConn, err := ln.Accept(context.Background())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(serverConn.DatagramsSupported()).To(BeTrue())
+		})
+
+		It("fails the handshake if the peer doesn't support datagrams", func() {
+			ln, err := quic.ListenAddr("localhost:0", getTLSConfig(), getQuicConfig(nil))
+			Expect(err).ToNot(HaveOccurred())
+			defer ln.Close()
+			_, err = quic.DialAddr(
+				context.Background(),
+				ln.Addr().String(),
+				getTLSClientConfig(),
+				getQuicConfig(&quic.Config{EnableDatagrams: true, RequireDatagrams: true}),
+			)
+			Expect(err).To(HaveOccurred())
+			var transportErr *quic.TransportError
+			Expect(errors.As(err, &transportErr)).To(BeTrue())
+			Expect(transportErr.ErrorCode).To(Equal(quic.ConnectionRefused))
+			Expect(transportErr.Remote).To(BeFalse())
+		})
+
+		It("doesn't report datagram support if the peer doesn't support datagrams", func() {
+			ln, err := quic.ListenAddr("localhost:0", getTLSConfig(), getQuicConfig(nil))
+			Expect(err).ToNot(HaveOccurred())
+			defer ln.Close()
+			conn, err := quic.DialAddr(
+				context.Background(),
+				ln.Addr().String(),
+				getTLSClientConfig(),
+				getQuicConfig(&quic.Config{EnableDatagrams: true}),
+			)
+			Expect(err).ToNot(HaveOccurred())
+			defer conn.CloseWithError(0, "")
+			Expect(conn.DatagramsSupported()).To(BeFalse())
+		})
+	})
+	It("sends large datagrams", func() {
+		ln, err := quic.ListenAddr("localhost:0", getTLSConfig(), getQuicConfig(&quic.Config{EnableDatagrams: true}))
+		Expect(err).ToNot(HaveOccurred())
+		defer ln.Close()
+		conn, err := quic.DialAddr(
+			context.Background(),
+			ln.Addr().String(),
+			getTLSClientConfig(),
+			getQuicConfig(&quic.Config{EnableDatagrams: true}),
+		)
+		Expect(err).ToNot(HaveOccurred())
+		defer conn.CloseWithError(0, "")
+		serverConn, err := ln.Accept(context.Background())
+		Expect(err).ToNot(HaveOccurred())
+
+		data := GeneratePRData(20 * conn.MaxDatagramSize())
+		Expect(conn.SendLargeDatagram(data)).To(Succeed())
+		Expect(conn.SendLargeDatagram([]byte("foobar"))).To(Succeed())
+		ctx, cancel := context.WithTimeout(context.Background(), scaleDuration(time.Second))
+		defer cancel()
+		msg, err := serverConn.ReceiveLargeDatagram(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(msg).To(Equal(data))
+		msg, err = serverConn.ReceiveLargeDatagram(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(msg).To(Equal([]byte("foobar")))
+
+		Expect(conn.SendLargeDatagram(make([]byte, 100*conn.MaxDatagramSize()))).To(MatchError(&quic.DatagramTooLargeError{}))
+	})
 })
@@ -0,0 +1,90 @@
+package self_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"time"
+
+	"github.com/quic-go/quic-go"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Draining", func() {
+	It("keeps established connections alive while draining", func() {
+		server, err := quic.ListenAddr("localhost:0", getTLSConfig(), getQuicConfig(nil))
+		Expect(err).ToNot(HaveOccurred())
+		defer server.Close()
+		addr := fmt.Sprintf("localhost:%d", server.Addr().(*net.UDPAddr).Port)
+
+		conn, err := quic.DialAddr(context.Background(), addr, getTLSClientConfig(), getQuicConfig(nil))
+		Expect(err).ToNot(HaveOccurred())
+		defer conn.CloseWithError(0, "")
+		serverConn, err := server.Accept(context.Background())
+		Expect(err).ToNot(HaveOccurred())
+
+		drained := make(chan error, 1)
+		go func() { drained <- server.Drain(context.Background()) }()
+		_, err = server.Accept(context.Background())
+		Expect(err).To(MatchError(quic.ErrServerClosed))
+
+		// new connections are not accepted
+		_, err = quic.DialAddr(
+			context.Background(),
+			addr,
+			getTLSClientConfig(),
+			getQuicConfig(&quic.Config{HandshakeIdleTimeout: scaleDuration(50 * time.Millisecond)}),
+		)
+		Expect(err).To(HaveOccurred())
+
+		// the established connection still works
+		go func() {
+			defer GinkgoRecover()
+			str, err := serverConn.AcceptStream(context.Background())
+			Expect(err).ToNot(HaveOccurred())
+			_, err = io.Copy(str, str)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(str.Close()).To(Succeed())
+		}()
+		str, err := conn.OpenStreamSync(context.Background())
+		Expect(err).ToNot(HaveOccurred())
+		_, err = str.Write(PRData)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(str.Close()).To(Succeed())
+		data, err := io.ReadAll(str)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(data).To(Equal(PRData))
+		Consistently(drained).ShouldNot(Receive())
+
+		// Drain returns once the last connection is closed
+		Expect(serverConn.CloseWithError(0, "")).To(Succeed())
+		Eventually(drained).Should(Receive(BeNil()))
+		Eventually(conn.Context().Done()).Should(BeClosed())
+	})
+
+	It("closes connections when the context expires", func() {
+		server, err := quic.ListenAddr("localhost:0", getTLSConfig(), getQuicConfig(nil))
+		Expect(err).ToNot(HaveOccurred())
+		defer server.Close()
+
+		conn, err := quic.DialAddr(
+			context.Background(),
+			fmt.Sprintf("localhost:%d", server.Addr().(*net.UDPAddr).Port),
+			getTLSClientConfig(),
+			getQuicConfig(nil),
+		)
+		Expect(err).ToNot(HaveOccurred())
+		defer conn.CloseWithError(0, "")
+		serverConn, err := server.Accept(context.Background())
+		Expect(err).ToNot(HaveOccurred())
+
+		ctx, cancel := context.WithTimeout(context.Background(), scaleDuration(50*time.Millisecond))
+		defer cancel()
+		Expect(server.Drain(ctx)).To(MatchError(context.DeadlineExceeded))
+		// the listener was created using ListenAddr, so closing it closes all connections
+		Eventually(serverConn.Context().Done()).Should(BeClosed())
+	})
+})
@@ -0,0 +1,77 @@
+package self_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"runtime"
+
+	"github.com/quic-go/quic-go"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ECN", func() {
+	BeforeEach(func() {
+		if runtime.GOOS != "linux" {
+			Skip("ECN is only used on Linux")
+		}
+	})
+
+	runTransfer := func(clientConf *quic.Config) (client, server quic.ECNStats) {
+		ln, err := quic.ListenAddr("localhost:0", getTLSConfig(), getQuicConfig(nil))
+		Expect(err).ToNot(HaveOccurred())
+		defer ln.Close()
+
+		conn, err := quic.DialAddr(
+			context.Background(),
+			fmt.Sprintf("localhost:%d", ln.Addr().(*net.UDPAddr).Port),
+			getTLSClientConfig(),
+			getQuicConfig(clientConf),
+		)
+		Expect(err).ToNot(HaveOccurred())
+		defer conn.CloseWithError(0, "")
+
+		serverConn, err := ln.Accept(context.Background())
+		Expect(err).ToNot(HaveOccurred())
+		defer serverConn.CloseWithError(0, "")
+
+		str, err := conn.OpenUniStream()
+		Expect(err).ToNot(HaveOccurred())
+		_, err = str.Write(PRData)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(str.Close()).To(Succeed())
+		serverStr, err := serverConn.AcceptUniStream(context.Background())
+		Expect(err).ToNot(HaveOccurred())
+		data, err := io.ReadAll(serverStr)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(data).To(Equal(PRData))
+		// make sure the server's ACKs for the stream data arrive at the client
+		Eventually(func() uint64 { return conn.Stats().BytesInFlight }).Should(BeZero())
+		return conn.ECNStats(), serverConn.ECNStats()
+	}
+
+	It("marks packets and counts the markings", func() {
+		client, server := runTransfer(nil)
+		Expect(client.SentECT0).ToNot(BeZero())
+		Expect(server.ReceivedECT0).ToNot(BeZero())
+		Expect(server.ReceivedECT0).To(BeNumerically("<=", client.SentECT0))
+		Expect(client.AckedECT0).ToNot(BeZero())
+		Expect(client.AckedECT0).To(BeNumerically("<=", client.SentECT0))
+		Expect(client.AckedCE).To(BeZero())
+	})
+
+	It("doesn't mark packets if ECN is disabled", func() {
+		client, server := runTransfer(&quic.Config{DisableECN: true})
+		Expect(client.SentECT0).To(BeZero())
+		Expect(client.SentECT1).To(BeZero())
+		Expect(server.ReceivedECT0).To(BeZero())
+		// the server still marks the packets it sends
+		Expect(server.SentECT0).ToNot(BeZero())
+		Expect(client.ReceivedECT0).ToNot(BeZero())
+		// but the client doesn't report the markings to the server
+		Expect(server.AckedECT0).To(BeZero())
+	})
+})
@@ -0,0 +1,362 @@
+package self_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/quic-go/quic-go"
+	"github.com/quic-go/quic-go/logging"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Stream flow control", func() {
+	const streamWindow = 10000
+
+	// runTransfer opens a stream and sends data on it, while the server never reads from the stream.
+	// It returns the highest offset at which the client was blocked by stream flow control.
+	runTransfer := func(receiveWindow logging.ByteCount) logging.ByteCount {
+		server, err := quic.ListenAddr(
+			"localhost:0",
+			getTLSConfig(),
+			getQuicConfig(&quic.Config{
+				InitialStreamReceiveWindow: streamWindow,
+				MaxStreamReceiveWindow:     streamWindow,
+			}),
+		)
+		Expect(err).ToNot(HaveOccurred())
+		defer server.Close()
+
+		windowSet := make(chan struct{})
+		go func() {
+			defer GinkgoRecover()
+			conn, err := server.Accept(context.Background())
+			Expect(err).ToNot(HaveOccurred())
+			str, err := conn.AcceptStream(context.Background())
+			Expect(err).ToNot(HaveOccurred())
+			if receiveWindow > 0 {
+				str.SetReceiveWindow(receiveWindow)
+			}
+			close(windowSet)
+		}()
+
+		blockedChan := make(chan logging.ByteCount, 100)
+		conn, err := quic.DialAddr(
+			context.Background(),
+			fmt.Sprintf("localhost:%d", server.Addr().(*net.UDPAddr).Port),
+			getTLSClientConfig(),
+			getQuicConfig(&quic.Config{
+				Tracer: newTracer(&logging.ConnectionTracer{
+					SentShortHeaderPacket: func(_ *logging.ShortHeader, _ logging.ByteCount, _ logging.ECN, _ *logging.AckFrame, frames []logging.Frame) {
+						for _, f := range frames {
+							if f, ok := f.(*logging.StreamDataBlockedFrame); ok {
+								blockedChan <- f.MaximumStreamData
+							}
+						}
+					},
+				}),
+			}),
+		)
+		Expect(err).ToNot(HaveOccurred())
+		defer conn.CloseWithError(0, "")
+
+		str, err := conn.OpenStream()
+		Expect(err).ToNot(HaveOccurred())
+		// the server only accepts the stream once it receives data on it
+		_, err = str.Write([]byte{0})
+		Expect(err).ToNot(HaveOccurred())
+		Eventually(windowSet).Should(BeClosed())
+
+		Expect(str.SetWriteDeadline(time.Now().Add(scaleDuration(500 * time.Millisecond)))).To(Succeed())
+		_, err = str.Write(make([]byte, 1<<20))
+		Expect(err).To(HaveOccurred())
+		Expect(err.(net.Error).Timeout()).To(BeTrue())
+
+		// The window update might arrive after the client was first blocked at the initial window.
+		var blockedAt logging.ByteCount
+		Eventually(blockedChan).Should(Receive(&blockedAt))
+		for {
+			select {
+			case offset := <-blockedChan:
+				blockedAt = max(blockedAt, offset)
+			default:
+				return blockedAt
+			}
+		}
+	}
+
+	It("blocks at the configured window", func() {
+		Expect(runTransfer(0)).To(BeEquivalentTo(streamWindow))
+	})
+
+	It("allows more data in flight after increasing the receive window", func() {
+		const receiveWindow = 100000
+		Expect(runTransfer(receiveWindow)).To(BeEquivalentTo(receiveWindow))
+	})
+
+	It("allows using the stream from OnFlowControlBlocked", func() {
+		server, err := quic.ListenAddr(
+			"localhost:0",
+			getTLSConfig(),
+			getQuicConfig(&quic.Config{
+				InitialStreamReceiveWindow: streamWindow,
+				MaxStreamReceiveWindow:     streamWindow,
+			}),
+		)
+		Expect(err).ToNot(HaveOccurred())
+		defer server.Close()
+
+		var str atomic.Pointer[quic.Stream]
+		blocked := make(chan quic.StreamID, 1)
+		conn, err := quic.DialAddr(
+			context.Background(),
+			fmt.Sprintf("localhost:%d", server.Addr().(*net.UDPAddr).Port),
+			getTLSClientConfig(),
+			getQuicConfig(&quic.Config{
+				OnFlowControlBlocked: func(id *quic.StreamID) {
+					if id == nil {
+						return
+					}
+					// this unblocks the Write call below
+					Expect((*str.Load()).SetWriteDeadline(time.Now())).To(Succeed())
+					select {
+					case blocked <- *id:
+					default:
+					}
+				},
+			}),
+		)
+		Expect(err).ToNot(HaveOccurred())
+		defer conn.CloseWithError(0, "")
+
+		s, err := conn.OpenStream()
+		Expect(err).ToNot(HaveOccurred())
+		str.Store(&s)
+		errChan := make(chan error, 1)
+		go func() {
+			_, err := s.Write(make([]byte, 1<<20))
+			errChan <- err
+		}()
+		Eventually(blocked).Should(Receive(Equal(s.StreamID())))
+		var writeErr error
+		Eventually(errChan).Should(Receive(&writeErr))
+		Expect(writeErr).To(HaveOccurred())
+		Expect(writeErr.(net.Error).Timeout()).To(BeTrue())
+	})
+})
+
+var _ = Describe("Connection flow control", func() {
+	It("bounds the unread data buffered across all streams", func() {
+		const (
+			connWindow = 100000
+			numStreams = 5
+			dataLen    = 200000
+		)
+
+		server, err := quic.ListenAddr(
+			"localhost:0",
+			getTLSConfig(),
+			getQuicConfig(&quic.Config{MaxConnectionReceiveWindow: connWindow}),
+		)
+		Expect(err).ToNot(HaveOccurred())
+		defer server.Close()
+
+		conn, err := quic.DialAddr(
+			context.Background(),
+			fmt.Sprintf("localhost:%d", server.Addr().(*net.UDPAddr).Port),
+			getTLSClientConfig(),
+			getQuicConfig(nil),
+		)
+		Expect(err).ToNot(HaveOccurred())
+		defer conn.CloseWithError(0, "")
+
+		data := GeneratePRData(dataLen)
+		for i := 0; i < numStreams; i++ {
+			str, err := conn.OpenStream()
+			Expect(err).ToNot(HaveOccurred())
+			go func() {
+				defer GinkgoRecover()
+				_, err := str.Write(data)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(str.Close()).To(Succeed())
+			}()
+		}
+
+		serverConn, err := server.Accept(context.Background())
+		Expect(err).ToNot(HaveOccurred())
+		// The server doesn't read any data, so the client's writes fill up the connection-level window.
+		Eventually(func() uint64 { return serverConn.Stats().BufferedStreamBytes }).Should(BeEquivalentTo(connWindow))
+		Consistently(func() uint64 { return serverConn.Stats().BufferedStreamBytes }, scaleDuration(50*time.Millisecond)).Should(BeEquivalentTo(connWindow))
+
+		done := make(chan struct{})
+		var maxBuffered uint64
+		go func() {
+			defer GinkgoRecover()
+			defer close(done)
+			var wg sync.WaitGroup
+			wg.Add(numStreams)
+			for i := 0; i < numStreams; i++ {
+				str, err := serverConn.AcceptStream(context.Background())
+				Expect(err).ToNot(HaveOccurred())
+				go func() {
+					defer GinkgoRecover()
+					defer wg.Done()
+					b, err := io.ReadAll(str)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(b).To(Equal(data))
+				}()
+			}
+			wg.Wait()
+		}()
+
+		ticker := time.NewTicker(time.Millisecond)
+		defer ticker.Stop()
+	loop:
+		for {
+			maxBuffered = max(maxBuffered, serverConn.Stats().BufferedStreamBytes)
+			select {
+			case <-done:
+				break loop
+			case <-ticker.C:
+			}
+		}
+		Expect(maxBuffered).To(BeNumerically("<=", connWindow))
+		Expect(serverConn.Stats().BufferedStreamBytes).To(BeZero())
+	})
+
+	It("sends data on streams with a higher priority first", func() {
+		const (
+			connWindow = 50000
+			dataLen    = 500000
+		)
+
+		server, err := quic.ListenAddr(
+			"localhost:0",
+			getTLSConfig(),
+			getQuicConfig(&quic.Config{MaxConnectionReceiveWindow: connWindow}),
+		)
+		Expect(err).ToNot(HaveOccurred())
+		defer server.Close()
+
+		conn, err := quic.DialAddr(
+			context.Background(),
+			fmt.Sprintf("localhost:%d", server.Addr().(*net.UDPAddr).Port),
+			getTLSClientConfig(),
+			getQuicConfig(nil),
+		)
+		Expect(err).ToNot(HaveOccurred())
+		defer conn.CloseWithError(0, "")
+
+		data := GeneratePRData(dataLen)
+		write := func(str quic.Stream) {
+			defer GinkgoRecover()
+			_, err := str.Write(data)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(str.Close()).To(Succeed())
+		}
+		lowStr, err := conn.OpenStream()
+		Expect(err).ToNot(HaveOccurred())
+		lowStr.SetPriority(quic.StreamPriority{Urgency: 7})
+		go write(lowStr)
+
+		serverConn, err := server.Accept(context.Background())
+		Expect(err).ToNot(HaveOccurred())
+		// The server doesn't read any data, so the low-priority stream fills up the connection-level window.
+		Eventually(func() uint64 { return serverConn.Stats().BufferedStreamBytes }).Should(BeEquivalentTo(connWindow))
+
+		highStr, err := conn.OpenStream()
+		Expect(err).ToNot(HaveOccurred())
+		highStr.SetPriority(quic.StreamPriority{Urgency: 0})
+		go write(highStr)
+
+		// Every byte read on the server grants new flow control credit,
+		// which is used for the high-priority stream, until all of its data was sent.
+		var lowRead atomic.Int64
+		lowReadWhenHighDone := make(chan int64, 1)
+		var wg sync.WaitGroup
+		wg.Add(2)
+		for i := 0; i < 2; i++ {
+			str, err := serverConn.AcceptStream(context.Background())
+			Expect(err).ToNot(HaveOccurred())
+			go func() {
+				defer GinkgoRecover()
+				defer wg.Done()
+				var b []byte
+				buf := make([]byte, 1000)
+				for {
+					n, err := str.Read(buf)
+					b = append(b, buf[:n]...)
+					if str.StreamID() == lowStr.StreamID() {
+						lowRead.Add(int64(n))
+					}
+					if err == io.EOF {
+						break
+					}
+					Expect(err).ToNot(HaveOccurred())
+				}
+				Expect(b).To(Equal(data))
+				if str.StreamID() == highStr.StreamID() {
+					lowReadWhenHighDone <- lowRead.Load()
+				}
+			}()
+		}
+		wg.Wait()
+		var n int64
+		Expect(lowReadWhenHighDone).To(Receive(&n))
+		fmt.Fprintf(GinkgoWriter, "read %d bytes on the low-priority stream before the high-priority stream completed\n", n)
+		Expect(n).To(BeNumerically("<=", 2*connWindow))
+	})
+
+	It("allows more data in flight after increasing the receive window", func() {
+		const (
+			connWindow = 100000
+			numStreams = 5
+			dataLen    = 200000
+		)
+
+		server, err := quic.ListenAddr(
+			"localhost:0",
+			getTLSConfig(),
+			getQuicConfig(&quic.Config{MaxConnectionReceiveWindow: connWindow}),
+		)
+		Expect(err).ToNot(HaveOccurred())
+		defer server.Close()
+
+		conn, err := quic.DialAddr(
+			context.Background(),
+			fmt.Sprintf("localhost:%d", server.Addr().(*net.UDPAddr).Port),
+			getTLSClientConfig(),
+			getQuicConfig(nil),
+		)
+		Expect(err).ToNot(HaveOccurred())
+		defer conn.CloseWithError(0, "")
+
+		data := GeneratePRData(dataLen)
+		for i := 0; i < numStreams; i++ {
+			str, err := conn.OpenStream()
+			Expect(err).ToNot(HaveOccurred())
+			go func() {
+				defer GinkgoRecover()
+				// the write fails when the connection is closed at the end of the test
+				str.Write(data)
+			}()
+		}
+
+		serverConn, err := server.Accept(context.Background())
+		Expect(err).ToNot(HaveOccurred())
+		defer serverConn.CloseWithError(0, "")
+		// The server doesn't read any data, so the client's writes fill up the connection-level window.
+		Eventually(func() uint64 { return serverConn.Stats().BufferedStreamBytes }).Should(BeEquivalentTo(connWindow))
+		Consistently(func() uint64 { return serverConn.Stats().BufferedStreamBytes }, scaleDuration(50*time.Millisecond)).Should(BeEquivalentTo(connWindow))
+
+		serverConn.SetReceiveWindow(3 * connWindow)
+		Eventually(func() uint64 { return serverConn.Stats().BufferedStreamBytes }).Should(BeEquivalentTo(3 * connWindow))
+		Consistently(func() uint64 { return serverConn.Stats().BufferedStreamBytes }, scaleDuration(50*time.Millisecond)).Should(BeEquivalentTo(3 * connWindow))
+	})
+})
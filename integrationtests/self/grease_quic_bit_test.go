@@ -0,0 +1,108 @@
+package self_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"sync/atomic"
+
+	"github.com/quic-go/quic-go"
+	quicproxy "github.com/quic-go/quic-go/integrationtests/tools/proxy"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Greasing the QUIC bit", func() {
+	// runTransfer transfers data from the server to the client.
+	// It returns the number of short header packets that the client and the server sent with an unset QUIC bit.
+	// The client dials with zero-length connection IDs if zeroLengthConnIDs is set,
+	// in which case it doesn't allow the server to grease the QUIC bit.
+	runTransfer := func(enableClient, enableServer, zeroLengthConnIDs bool) (clientGreased, serverGreased int64) {
+		server, err := quic.ListenAddr(
+			"localhost:0",
+			getTLSConfig(),
+			getQuicConfig(&quic.Config{EnableQUICBitGreasing: enableServer}),
+		)
+		Expect(err).ToNot(HaveOccurred())
+		defer server.Close()
+
+		go func() {
+			defer GinkgoRecover()
+			conn, err := server.Accept(context.Background())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(conn.ConnectionState().SupportsQUICBitGreasing).To(Equal(enableClient && enableServer && !zeroLengthConnIDs))
+			str, err := conn.OpenUniStream()
+			Expect(err).ToNot(HaveOccurred())
+			_, err = str.Write(PRData)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(str.Close()).To(Succeed())
+		}()
+
+		var numClientGreased, numServerGreased atomic.Int64
+		proxy, err := quicproxy.NewQuicProxy("localhost:0", &quicproxy.Opts{
+			RemoteAddr: fmt.Sprintf("localhost:%d", server.Addr().(*net.UDPAddr).Port),
+			DropPacket: func(dir quicproxy.Direction, b []byte) bool {
+				// only short header packets may be greased
+				if b[0]&0x80 == 0 && b[0]&0x40 == 0 {
+					if dir == quicproxy.DirectionIncoming {
+						numClientGreased.Add(1)
+					} else {
+						numServerGreased.Add(1)
+					}
+				}
+				return false
+			},
+		})
+		Expect(err).ToNot(HaveOccurred())
+		defer proxy.Close()
+
+		var conn quic.Connection
+		clientConf := getQuicConfig(&quic.Config{EnableQUICBitGreasing: enableClient})
+		if zeroLengthConnIDs {
+			conn, err = quic.DialAddr(context.Background(), fmt.Sprintf("localhost:%d", proxy.LocalPort()), getTLSClientConfig(), clientConf)
+		} else {
+			var udpConn *net.UDPConn
+			udpConn, err = net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 0})
+			Expect(err).ToNot(HaveOccurred())
+			tr := &quic.Transport{Conn: udpConn, ConnectionIDLength: 4}
+			defer tr.Close()
+			conn, err = tr.Dial(context.Background(), &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: proxy.LocalPort()}, getTLSClientConfig(), clientConf)
+		}
+		Expect(err).ToNot(HaveOccurred())
+		defer conn.CloseWithError(0, "")
+		Expect(conn.ConnectionState().SupportsQUICBitGreasing).To(Equal(enableClient && enableServer))
+
+		str, err := conn.AcceptUniStream(context.Background())
+		Expect(err).ToNot(HaveOccurred())
+		data, err := io.ReadAll(str)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(data).To(Equal(PRData))
+		return numClientGreased.Load(), numServerGreased.Load()
+	}
+
+	It("greases the QUIC bit if both endpoints enable it", func() {
+		clientGreased, serverGreased := runTransfer(true, true, false)
+		Expect(clientGreased).To(BeNumerically(">", 0))
+		Expect(serverGreased).To(BeNumerically(">", 0))
+	})
+
+	It("doesn't allow the server to grease the QUIC bit if the client uses zero-length connection IDs", func() {
+		clientGreased, serverGreased := runTransfer(true, true, true)
+		Expect(clientGreased).To(BeNumerically(">", 0))
+		Expect(serverGreased).To(BeZero())
+	})
+
+	It("doesn't grease the QUIC bit if only the client enables it", func() {
+		clientGreased, serverGreased := runTransfer(true, false, false)
+		Expect(clientGreased).To(BeZero())
+		Expect(serverGreased).To(BeZero())
+	})
+
+	It("doesn't grease the QUIC bit if only the server enables it", func() {
+		clientGreased, serverGreased := runTransfer(false, true, false)
+		Expect(clientGreased).To(BeZero())
+		Expect(serverGreased).To(BeZero())
+	})
+})
@@ -294,4 +294,37 @@ var _ = Describe("Handshake drop tests", func() {
 			clientSpeaksFirst.run(ln, proxyPort)
 		})
 	}
+
+	It("counts the retransmitted Initial and Handshake packets", func() {
+		var numDropped atomic.Int32
+		ln, proxyPort, closeFn := startListenerAndProxy(func(d quicproxy.Direction, _ []byte) bool {
+			// drop the server's first flight, which contains both Initial and Handshake packets
+			return d == quicproxy.DirectionOutgoing && numDropped.Add(1) <= 2
+		}, false, false)
+		defer closeFn()
+
+		serverConnChan := make(chan quic.Connection, 1)
+		go func() {
+			defer GinkgoRecover()
+			conn, err := ln.Accept(context.Background())
+			Expect(err).ToNot(HaveOccurred())
+			serverConnChan <- conn
+		}()
+		conn, err := quic.DialAddr(
+			context.Background(),
+			fmt.Sprintf("localhost:%d", proxyPort),
+			getTLSClientConfig(),
+			getQuicConfig(&quic.Config{HandshakeIdleTimeout: timeout}),
+		)
+		Expect(err).ToNot(HaveOccurred())
+		defer conn.CloseWithError(0, "")
+		var serverConn quic.Connection
+		Eventually(serverConnChan, timeout).Should(Receive(&serverConn))
+		defer serverConn.CloseWithError(0, "")
+
+		// The server can only complete the handshake after retransmitting its Initial and Handshake packets.
+		stats := serverConn.Stats()
+		Expect(stats.InitialPacketsRetransmitted).To(BeNumerically(">=", 1))
+		Expect(stats.HandshakePacketsRetransmitted).To(BeNumerically(">=", 1))
+	})
 })
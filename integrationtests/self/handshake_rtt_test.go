@@ -105,6 +105,10 @@ var _ = Describe("Handshake RTT tests", func() {
 		Expect(err).ToNot(HaveOccurred())
 		defer conn.CloseWithError(0, "")
 		expectDurationInRTTs(startTime, 1)
+		Expect(conn.ConnectionState().HandshakeDuration).To(SatisfyAll(
+			BeNumerically(">=", rtt),
+			BeNumerically("<", 2*rtt),
+		))
 	})
 
 	It("establishes a connection in 2 RTTs if a HelloRetryRequest is performed", func() {
@@ -124,6 +128,10 @@ var _ = Describe("Handshake RTT tests", func() {
 		Expect(err).ToNot(HaveOccurred())
 		defer conn.CloseWithError(0, "")
 		expectDurationInRTTs(startTime, 2)
+		Expect(conn.ConnectionState().HandshakeDuration).To(SatisfyAll(
+			BeNumerically(">=", 2*rtt),
+			BeNumerically("<", 3*rtt),
+		))
 	})
 
 	It("receives the first message from the server after 2 RTTs, when the server uses ListenAddr", func() {
@@ -1,12 +1,15 @@
 package self_test
 
 import (
+	"bytes"
 	"context"
 	"crypto/tls"
 	"errors"
 	"fmt"
 	"io"
 	"net"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/quic-go/quic-go"
@@ -20,6 +23,45 @@ import (
 	. "github.com/onsi/gomega"
 )
 
+type retryTokenInfo struct {
+	remoteAddr                     string
+	origDestConnID, retrySrcConnID quic.ConnectionID
+}
+
+// statefulRetryTokenGenerator issues random Retry tokens, and keeps track of them.
+type statefulRetryTokenGenerator struct {
+	mutex             sync.Mutex
+	tokens            map[string]retryTokenInfo
+	issued, validated int
+}
+
+var _ quic.RetryTokenGenerator = &statefulRetryTokenGenerator{}
+
+func (g *statefulRetryTokenGenerator) NewRetryToken(remoteAddr net.Addr, origDestConnID, retrySrcConnID quic.ConnectionID) ([]byte, error) {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	g.issued++
+	token := fmt.Sprintf("token-%d", g.issued)
+	g.tokens[token] = retryTokenInfo{remoteAddr: remoteAddr.String(), origDestConnID: origDestConnID, retrySrcConnID: retrySrcConnID}
+	return []byte(token), nil
+}
+
+func (g *statefulRetryTokenGenerator) IsRetryToken(token []byte) bool {
+	return bytes.HasPrefix(token, []byte("token-"))
+}
+
+func (g *statefulRetryTokenGenerator) ValidateRetryToken(token []byte, remoteAddr net.Addr) (quic.ConnectionID, quic.ConnectionID, error) {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	info, ok := g.tokens[string(token)]
+	if !ok || info.remoteAddr != remoteAddr.String() {
+		return quic.ConnectionID{}, quic.ConnectionID{}, errors.New("invalid token")
+	}
+	delete(g.tokens, string(token))
+	g.validated++
+	return info.origDestConnID, info.retrySrcConnID, nil
+}
+
 type tokenStore struct {
 	store quic.TokenStore
 	gets  chan<- string
@@ -103,6 +145,35 @@ var _ = Describe("Handshake tests", func() {
 		Expect(err).To(MatchError(context.DeadlineExceeded))
 	})
 
+	It("uses the configured initial RTT for the first PTO", func() {
+		// the server never responds, so the client retransmits its Initial when the PTO fires
+		conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 0})
+		Expect(err).ToNot(HaveOccurred())
+		defer conn.Close()
+		received := make(chan time.Time, 10)
+		go func() {
+			b := make([]byte, 2000)
+			for {
+				if _, _, err := conn.ReadFrom(b); err != nil {
+					return
+				}
+				received <- time.Now()
+			}
+		}()
+
+		initialRTT := scaleDuration(250 * time.Millisecond)
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go quic.DialAddr(ctx, conn.LocalAddr().String(), getTLSClientConfig(), getQuicConfig(&quic.Config{InitialRTT: initialRTT}))
+
+		var first, second time.Time
+		Eventually(received).Should(Receive(&first))
+		Eventually(received, 3*initialRTT).Should(Receive(&second))
+		// the first PTO is twice the initial RTT (the default initial RTT would lead to a PTO of 200ms)
+		Expect(second.Sub(first)).To(BeNumerically(">=", 2*initialRTT-scaleDuration(10*time.Millisecond)))
+		Expect(second.Sub(first)).To(BeNumerically("<", 3*initialRTT))
+	})
+
 	It("returns the cancellation reason when a dial is canceled", func() {
 		ctx, cancel := context.WithCancelCause(context.Background())
 		errChan := make(chan error, 1)
@@ -645,6 +716,44 @@ var _ = Describe("Handshake tests", func() {
 		})
 	})
 
+	It("refuses connections once the Transport's connection limit is reached", func() {
+		udpConn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 0})
+		Expect(err).ToNot(HaveOccurred())
+		var numRejected atomic.Int32
+		tr := &quic.Transport{
+			Conn:                 udpConn,
+			MaxConnections:       2,
+			OnConnectionRejected: func(net.Addr) { numRejected.Add(1) },
+		}
+		addTracer(tr)
+		defer tr.Close()
+		ln, err := tr.Listen(getTLSConfig(), getQuicConfig(nil))
+		Expect(err).ToNot(HaveOccurred())
+		defer ln.Close()
+
+		conn1, err := quic.DialAddr(context.Background(), ln.Addr().String(), getTLSClientConfig(), getQuicConfig(nil))
+		Expect(err).ToNot(HaveOccurred())
+		defer conn1.CloseWithError(0, "")
+		conn2, err := quic.DialAddr(context.Background(), ln.Addr().String(), getTLSClientConfig(), getQuicConfig(nil))
+		Expect(err).ToNot(HaveOccurred())
+		defer conn2.CloseWithError(0, "")
+		Expect(tr.NumConnections()).To(Equal(2))
+
+		_, err = quic.DialAddr(context.Background(), ln.Addr().String(), getTLSClientConfig(), getQuicConfig(nil))
+		var transportErr *quic.TransportError
+		Expect(errors.As(err, &transportErr)).To(BeTrue())
+		Expect(transportErr.ErrorCode).To(Equal(quic.ConnectionRefused))
+		Expect(numRejected.Load()).To(BeEquivalentTo(1))
+
+		// closing a connection frees a slot
+		Expect(conn1.CloseWithError(0, "")).To(Succeed())
+		Eventually(tr.NumConnections).Should(Equal(1))
+		conn3, err := quic.DialAddr(context.Background(), ln.Addr().String(), getTLSClientConfig(), getQuicConfig(nil))
+		Expect(err).ToNot(HaveOccurred())
+		defer conn3.CloseWithError(0, "")
+		Expect(numRejected.Load()).To(BeEquivalentTo(1))
+	})
+
 	Context("ALPN", func() {
 		It("negotiates an application protocol", func() {
 			ln, err := quic.ListenAddr("localhost:0", getTLSConfig(), serverConfig)
@@ -743,6 +852,70 @@ var _ = Describe("Handshake tests", func() {
 			Eventually(done).Should(BeClosed())
 		})
 
+		It("uses tokens persisted by the application across transports", func() {
+			addrVerified := make(chan bool, 2)
+			sconf := getQuicConfig(nil)
+			sconf.GetConfigForClient = func(info *quic.ClientHelloInfo) (*quic.Config, error) {
+				addrVerified <- info.AddrVerified
+				return sconf, nil
+			}
+			udpConn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 0})
+			Expect(err).ToNot(HaveOccurred())
+			str := &quic.Transport{Conn: udpConn, MaxTokenAge: time.Hour}
+			defer str.Close()
+			server, err := str.Listen(getTLSConfig(), sconf)
+			Expect(err).ToNot(HaveOccurred())
+			defer server.Close()
+			go func() {
+				defer GinkgoRecover()
+				for {
+					if _, err := server.Accept(context.Background()); err != nil {
+						return
+					}
+				}
+			}()
+
+			// the token store shared by both transports, e.g. backed by a file on disk
+			var mx sync.Mutex
+			var token []byte
+			newTokenStored := make(chan struct{}, 10)
+			quicConf := getQuicConfig(&quic.Config{
+				StoreNewToken: func(t []byte) {
+					mx.Lock()
+					token = t
+					mx.Unlock()
+					newTokenStored <- struct{}{}
+				},
+				GetStoredToken: func(remote net.Addr) []byte {
+					Expect(remote.String()).To(Equal(server.Addr().String()))
+					mx.Lock()
+					defer mx.Unlock()
+					return token
+				},
+			})
+			dial := func() (*quic.Transport, quic.Connection) {
+				udpConn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 0})
+				Expect(err).ToNot(HaveOccurred())
+				tr := &quic.Transport{Conn: udpConn}
+				addTracer(tr)
+				conn, err := tr.Dial(context.Background(), server.Addr(), getTLSClientConfig(), quicConf)
+				Expect(err).ToNot(HaveOccurred())
+				return tr, conn
+			}
+
+			tr1, conn := dial()
+			defer tr1.Close()
+			Expect(addrVerified).To(Receive(BeFalse()))
+			Eventually(newTokenStored).Should(Receive())
+			Expect(conn.CloseWithError(0, "")).To(Succeed())
+
+			// a new transport, with a different local address, but the token is valid for the IP
+			tr2, conn := dial()
+			defer tr2.Close()
+			defer conn.CloseWithError(0, "")
+			Expect(addrVerified).To(Receive(BeTrue()))
+		})
+
 		It("rejects invalid Retry token with the INVALID_TOKEN error", func() {
 			const rtt = 10 * time.Millisecond
 
@@ -788,6 +961,38 @@ var _ = Describe("Handshake tests", func() {
 			Expect(errors.As(err, &transportErr)).To(BeTrue())
 			Expect(transportErr.ErrorCode).To(Equal(quic.InvalidToken))
 		})
+
+		It("uses a custom Retry token generator", func() {
+			gen := &statefulRetryTokenGenerator{tokens: make(map[string]retryTokenInfo)}
+			laddr, err := net.ResolveUDPAddr("udp", "localhost:0")
+			Expect(err).ToNot(HaveOccurred())
+			udpConn, err := net.ListenUDP("udp", laddr)
+			Expect(err).ToNot(HaveOccurred())
+			defer udpConn.Close()
+			tr := &quic.Transport{
+				Conn:                udpConn,
+				VerifySourceAddress: func(net.Addr) bool { return true },
+				RetryTokenGenerator: gen,
+			}
+			addTracer(tr)
+			defer tr.Close()
+			server, err := tr.Listen(getTLSConfig(), serverConfig)
+			Expect(err).ToNot(HaveOccurred())
+			defer server.Close()
+
+			conn, err := quic.DialAddr(
+				context.Background(),
+				fmt.Sprintf("localhost:%d", server.Addr().(*net.UDPAddr).Port),
+				getTLSClientConfig(),
+				getQuicConfig(nil),
+			)
+			Expect(err).ToNot(HaveOccurred())
+			defer conn.CloseWithError(0, "")
+			gen.mutex.Lock()
+			defer gen.mutex.Unlock()
+			Expect(gen.issued).To(Equal(1))
+			Expect(gen.validated).To(Equal(1))
+		})
 	})
 
 	Context("GetConfigForClient", func() {
@@ -856,6 +1061,212 @@ var _ = Describe("Handshake tests", func() {
 		})
 	})
 
+	Context("GetConfigForClientHello", func() {
+		It("selects the quic.Config based on the ALPN", func() {
+			serverConfig.EnableDatagrams = false
+			serverConfig.GetConfigForClientHello = func(info *quic.ClientHelloInfo) (*quic.Config, error) {
+				Expect(info.ServerName).To(Equal("localhost"))
+				for _, proto := range info.SupportedProtos {
+					if proto == "with-datagrams" {
+						return &quic.Config{EnableDatagrams: true, MaxIncomingStreams: 5}, nil
+					}
+				}
+				return nil, nil
+			}
+			tlsConf := getTLSConfig()
+			tlsConf.NextProtos = []string{"with-datagrams", "without-datagrams"}
+			ln, err := quic.ListenAddr("localhost:0", tlsConf, serverConfig)
+			Expect(err).ToNot(HaveOccurred())
+			defer ln.Close()
+
+			go func() {
+				defer GinkgoRecover()
+				for {
+					if _, err := ln.Accept(context.Background()); err != nil {
+						return
+					}
+				}
+			}()
+
+			dial := func(proto string) quic.Connection {
+				clientTLSConf := getTLSClientConfig()
+				clientTLSConf.NextProtos = []string{proto}
+				clientTLSConf.ServerName = "localhost"
+				conn, err := quic.DialAddr(
+					context.Background(),
+					fmt.Sprintf("localhost:%d", ln.Addr().(*net.UDPAddr).Port),
+					clientTLSConf,
+					getQuicConfig(&quic.Config{EnableDatagrams: true}),
+				)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(conn.ConnectionState().TLS.NegotiatedProtocol).To(Equal(proto))
+				return conn
+			}
+
+			conn1 := dial("with-datagrams")
+			defer conn1.CloseWithError(0, "")
+			Expect(conn1.ConnectionState().SupportsDatagrams).To(BeTrue())
+			bidi, _ := conn1.StreamsAvailable()
+			Expect(bidi).To(BeEquivalentTo(5))
+
+			conn2 := dial("without-datagrams")
+			defer conn2.CloseWithError(0, "")
+			Expect(conn2.ConnectionState().SupportsDatagrams).To(BeFalse())
+			bidi, _ = conn2.StreamsAvailable()
+			Expect(bidi).To(BeNumerically(">", 5))
+		})
+
+		It("rejects the connection attempt if GetConfigForClientHello errors", func() {
+			serverConfig.GetConfigForClientHello = func(*quic.ClientHelloInfo) (*quic.Config, error) {
+				return nil, errors.New("unsupported protocol")
+			}
+			ln, err := quic.ListenAddr("localhost:0", getTLSConfig(), serverConfig)
+			Expect(err).ToNot(HaveOccurred())
+			defer ln.Close()
+
+			_, err = quic.DialAddr(
+				context.Background(),
+				fmt.Sprintf("localhost:%d", ln.Addr().(*net.UDPAddr).Port),
+				getTLSClientConfig(),
+				getQuicConfig(nil),
+			)
+			Expect(err).To(HaveOccurred())
+			var transportErr *quic.TransportError
+			Expect(errors.As(err, &transportErr)).To(BeTrue())
+			Expect(transportErr.ErrorCode).To(Equal(qerr.ConnectionRefused))
+		})
+
+		It("requires datagrams, if required by the quic.Config", func() {
+			serverConfig.EnableDatagrams = false
+			serverConfig.GetConfigForClientHello = func(*quic.ClientHelloInfo) (*quic.Config, error) {
+				return &quic.Config{EnableDatagrams: true, RequireDatagrams: true}, nil
+			}
+			ln, err := quic.ListenAddr("localhost:0", getTLSConfig(), serverConfig)
+			Expect(err).ToNot(HaveOccurred())
+			defer ln.Close()
+
+			_, err = quic.DialAddr(
+				context.Background(),
+				fmt.Sprintf("localhost:%d", ln.Addr().(*net.UDPAddr).Port),
+				getTLSClientConfig(),
+				getQuicConfig(&quic.Config{EnableDatagrams: false}),
+			)
+			Expect(err).To(HaveOccurred())
+			var transportErr *quic.TransportError
+			Expect(errors.As(err, &transportErr)).To(BeTrue())
+			Expect(transportErr.Remote).To(BeTrue())
+			Expect(transportErr.ErrorCode).To(Equal(qerr.ConnectionRefused))
+		})
+	})
+
+	Context("closing during the handshake", func() {
+		It("returns the transport error, when the server closes the connection with a transport error", func() {
+			ln, err := quic.ListenAddr(
+				"localhost:0",
+				getTLSConfig(),
+				getQuicConfig(&quic.Config{EnableDatagrams: true, RequireDatagrams: true}),
+			)
+			Expect(err).ToNot(HaveOccurred())
+			defer ln.Close()
+
+			_, err = quic.DialAddr(
+				context.Background(),
+				fmt.Sprintf("localhost:%d", ln.Addr().(*net.UDPAddr).Port),
+				getTLSClientConfig(),
+				getQuicConfig(&quic.Config{EnableDatagrams: false}),
+			)
+			Expect(err).To(HaveOccurred())
+			var transportErr *quic.TransportError
+			Expect(errors.As(err, &transportErr)).To(BeTrue())
+			Expect(transportErr.Remote).To(BeTrue())
+			Expect(transportErr.ErrorCode).To(Equal(quic.ConnectionRefused))
+			Expect(transportErr.ErrorMessage).To(Equal("datagrams required, but not supported by the peer"))
+			var appErr *quic.ApplicationError
+			Expect(errors.As(err, &appErr)).To(BeFalse())
+		})
+
+		It("returns the APPLICATION_ERROR error code, when the server closes the connection with an application error", func() {
+			ln, err := quic.ListenAddrEarly("localhost:0", getTLSConfig(), getQuicConfig(nil))
+			Expect(err).ToNot(HaveOccurred())
+			defer ln.Close()
+
+			// Drop all packets sent by the server until (some time after) the connection was closed.
+			// The client only receives a retransmission of the CONNECTION_CLOSE, and can't complete the handshake.
+			var closed atomic.Bool
+			proxy, err := quicproxy.NewQuicProxy("localhost:0", &quicproxy.Opts{
+				RemoteAddr: fmt.Sprintf("localhost:%d", ln.Addr().(*net.UDPAddr).Port),
+				DropPacket: func(dir quicproxy.Direction, _ []byte) bool {
+					return dir == quicproxy.DirectionOutgoing && !closed.Load()
+				},
+			})
+			Expect(err).ToNot(HaveOccurred())
+			defer proxy.Close()
+
+			done := make(chan struct{})
+			go func() {
+				defer GinkgoRecover()
+				defer close(done)
+				conn, err := ln.Accept(context.Background())
+				Expect(err).ToNot(HaveOccurred())
+				Expect(conn.CloseWithError(1337, "rejected")).To(Succeed())
+				// make sure the packets sent before are dropped by the proxy
+				time.Sleep(scaleDuration(25 * time.Millisecond))
+				closed.Store(true)
+			}()
+
+			_, err = quic.DialAddr(
+				context.Background(),
+				fmt.Sprintf("localhost:%d", proxy.LocalPort()),
+				getTLSClientConfig(),
+				getQuicConfig(nil),
+			)
+			Expect(err).To(HaveOccurred())
+			// Application errors can't be sent in Initial packets.
+			var transportErr *quic.TransportError
+			Expect(errors.As(err, &transportErr)).To(BeTrue())
+			Expect(transportErr.Remote).To(BeTrue())
+			Expect(transportErr.ErrorCode).To(Equal(quic.ApplicationErrorErrorCode))
+			Eventually(done).Should(BeClosed())
+		})
+
+		It("returns the application error, when the server closes the connection before confirming the handshake", func() {
+			ln, err := quic.ListenAddrEarly("localhost:0", getTLSConfig(), getQuicConfig(nil))
+			Expect(err).ToNot(HaveOccurred())
+			defer ln.Close()
+
+			done := make(chan struct{})
+			go func() {
+				defer GinkgoRecover()
+				defer close(done)
+				conn, err := ln.Accept(context.Background())
+				Expect(err).ToNot(HaveOccurred())
+				// Close the connection before receiving the client's Finished.
+				// The CONNECTION_CLOSE is sent in both Handshake and 1-RTT packets.
+				Expect(conn.CloseWithError(1337, "rejected")).To(Succeed())
+			}()
+
+			conn, err := quic.DialAddr(
+				context.Background(),
+				fmt.Sprintf("localhost:%d", ln.Addr().(*net.UDPAddr).Port),
+				getTLSClientConfig(),
+				getQuicConfig(nil),
+			)
+			// Depending on timing, the client might complete the handshake before processing the CONNECTION_CLOSE.
+			if err == nil {
+				Eventually(conn.Context().Done()).Should(BeClosed())
+				err = context.Cause(conn.Context())
+			}
+			var appErr *quic.ApplicationError
+			Expect(errors.As(err, &appErr)).To(BeTrue())
+			Expect(appErr.Remote).To(BeTrue())
+			Expect(appErr.ErrorCode).To(BeEquivalentTo(1337))
+			Expect(appErr.ErrorMessage).To(Equal("rejected"))
+			var transportErr *quic.TransportError
+			Expect(errors.As(err, &transportErr)).To(BeFalse())
+			Eventually(done).Should(BeClosed())
+		})
+	})
+
 	It("doesn't send any packets when generating the ClientHello fails", func() {
 		ln, err := net.ListenUDP("udp", nil)
 		Expect(err).ToNot(HaveOccurred())
@@ -889,4 +1300,32 @@ var _ = Describe("Handshake tests", func() {
 		ln.Close()
 		Eventually(done).Should(BeClosed())
 	})
+
+	It("advertises the configured max_ack_delay", func() {
+		server, err := quic.ListenAddr(
+			"localhost:0",
+			getTLSConfig(),
+			getQuicConfig(&quic.Config{MaxAckDelay: 5 * time.Millisecond}),
+		)
+		Expect(err).ToNot(HaveOccurred())
+		defer server.Close()
+
+		paramsChan := make(chan *logging.TransportParameters, 1)
+		conn, err := quic.DialAddr(
+			context.Background(),
+			fmt.Sprintf("localhost:%d", server.Addr().(*net.UDPAddr).Port),
+			getTLSClientConfig(),
+			getQuicConfig(&quic.Config{
+				Tracer: newTracer(&logging.ConnectionTracer{
+					ReceivedTransportParameters: func(p *logging.TransportParameters) { paramsChan <- p },
+				}),
+			}),
+		)
+		Expect(err).ToNot(HaveOccurred())
+		defer conn.CloseWithError(0, "")
+		var params *logging.TransportParameters
+		Eventually(paramsChan).Should(Receive(&params))
+		// the advertised value includes the timer granularity
+		Expect(params.MaxAckDelay).To(Equal(6 * time.Millisecond))
+	})
 })
@@ -89,4 +89,65 @@ var _ = Describe("Key Update tests", func() {
 		Expect(keyPhasesReceived).To(BeNumerically(">", 10))
 		Expect(keyPhasesReceived).To(BeNumerically("~", keyPhasesSent, 2))
 	})
+
+	It("updates keys when requested by the application", func() {
+		server, err := quic.ListenAddr("localhost:0", getTLSConfig(), getQuicConfig(nil))
+		Expect(err).ToNot(HaveOccurred())
+		defer server.Close()
+
+		done := make(chan struct{})
+		go func() {
+			defer GinkgoRecover()
+			defer close(done)
+			conn, err := server.Accept(context.Background())
+			Expect(err).ToNot(HaveOccurred())
+			str, err := conn.AcceptStream(context.Background())
+			Expect(err).ToNot(HaveOccurred())
+			defer str.Close()
+			_, err = io.Copy(str, str)
+			Expect(err).ToNot(HaveOccurred())
+		}()
+
+		conn, err := quic.DialAddr(
+			context.Background(),
+			fmt.Sprintf("localhost:%d", server.Addr().(*net.UDPAddr).Port),
+			getTLSClientConfig(),
+			getQuicConfig(nil),
+		)
+		Expect(err).ToNot(HaveOccurred())
+		defer conn.CloseWithError(0, "")
+		str, err := conn.OpenStream()
+		Expect(err).ToNot(HaveOccurred())
+		echo := func() {
+			_, err := str.Write([]byte("foobar"))
+			Expect(err).ToNot(HaveOccurred())
+			b := make([]byte, 6)
+			_, err = io.ReadFull(str, b)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(b).To(Equal([]byte("foobar")))
+		}
+		echo()
+
+		phase, updates := conn.KeyPhase()
+		Expect(phase).To(BeZero())
+		Expect(updates).To(BeZero())
+		// the handshake is confirmed once the client receives the HANDSHAKE_DONE frame
+		Eventually(conn.InitiateKeyUpdate).Should(Succeed())
+		// a second key update is not allowed before the first one was acknowledged
+		Expect(conn.InitiateKeyUpdate()).ToNot(Succeed())
+		echo()
+		Eventually(func() uint64 { _, updates := conn.KeyPhase(); return updates }).Should(BeEquivalentTo(1))
+		phase, _ = conn.KeyPhase()
+		Expect(phase).To(BeEquivalentTo(1))
+
+		Eventually(func() error { echo(); return conn.InitiateKeyUpdate() }).Should(Succeed())
+		echo()
+		Eventually(func() uint64 { _, updates := conn.KeyPhase(); return updates }).Should(BeEquivalentTo(2))
+
+		// wait for the server to finish echoing before closing the connection
+		Expect(str.Close()).To(Succeed())
+		_, err = io.ReadAll(str)
+		Expect(err).ToNot(HaveOccurred())
+		Eventually(done).Should(BeClosed())
+	})
 })
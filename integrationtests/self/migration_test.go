@@ -0,0 +1,191 @@
+package self_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/netip"
+
+	"github.com/quic-go/quic-go"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Connection Migration", func() {
+	// dial establishes a connection to a server that echoes all data sent on streams.
+	dial := func(serverConf *quic.Config) (*quic.Listener, quic.Connection, quic.Connection) {
+		server, err := quic.ListenAddr("localhost:0", getTLSConfig(), getQuicConfig(serverConf))
+		Expect(err).ToNot(HaveOccurred())
+		serverConnChan := make(chan quic.Connection, 1)
+		go func() {
+			defer GinkgoRecover()
+			conn, err := server.Accept(context.Background())
+			Expect(err).ToNot(HaveOccurred())
+			serverConnChan <- conn
+			for {
+				str, err := conn.AcceptStream(context.Background())
+				if err != nil {
+					return
+				}
+				go func() {
+					defer GinkgoRecover()
+					_, err := io.Copy(str, str)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(str.Close()).To(Succeed())
+				}()
+			}
+		}()
+
+		conn, err := quic.DialAddr(context.Background(), server.Addr().String(), getTLSClientConfig(), getQuicConfig(nil))
+		Expect(err).ToNot(HaveOccurred())
+		var serverConn quic.Connection
+		Eventually(serverConnChan).Should(Receive(&serverConn))
+		return server, conn, serverConn
+	}
+
+	echo := func(conn quic.Connection) {
+		str, err := conn.OpenStream()
+		Expect(err).ToNot(HaveOccurred())
+		_, err = str.Write(PRData)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(str.Close()).To(Succeed())
+		data, err := io.ReadAll(str)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(data).To(Equal(PRData))
+	}
+
+	It("migrates the client to a new address", func() {
+		server, conn, serverConn := dial(nil)
+		defer server.Close()
+		defer conn.CloseWithError(0, "")
+		Expect(conn.MigrationAllowed()).To(BeTrue())
+		Expect(serverConn.MigrationAllowed()).To(BeTrue())
+		// make sure that the handshake is confirmed
+		echo(conn)
+
+		oldAddr := conn.LocalAddr()
+		Expect(conn.MigrateTo(&net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 0})).To(Succeed())
+		newAddr := conn.LocalAddr()
+		Expect(newAddr).ToNot(Equal(oldAddr))
+		// the connection still works, and the server switched to the client's new address
+		echo(conn)
+		Expect(serverConn.RemoteAddr().String()).To(Equal(newAddr.String()))
+	})
+
+	It("fails over to a backup path", func() {
+		server, conn, serverConn := dial(nil)
+		defer server.Close()
+		defer conn.CloseWithError(0, "")
+		// make sure that the handshake is confirmed
+		echo(conn)
+
+		oldAddr := conn.LocalAddr()
+		id, err := conn.AddPath(&net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 0}, nil)
+		Expect(err).ToNot(HaveOccurred())
+		info, err := conn.PathStatus(id)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(info.Validated).To(BeTrue())
+		// the connection keeps using the current path
+		Expect(conn.LocalAddr()).To(Equal(oldAddr))
+		echo(conn)
+
+		Expect(conn.SwitchToPath(id)).To(Succeed())
+		newAddr := conn.LocalAddr()
+		Expect(newAddr).ToNot(Equal(oldAddr))
+		echo(conn)
+		Expect(serverConn.RemoteAddr().String()).To(Equal(newAddr.String()))
+		// the path isn't a backup path anymore
+		_, err = conn.PathStatus(id)
+		Expect(err).To(MatchError(fmt.Sprintf("unknown path: %d", id)))
+	})
+
+	It("refuses to migrate if the server disabled active migration", func() {
+		server, conn, serverConn := dial(&quic.Config{DisableActiveMigration: true})
+		defer server.Close()
+		defer conn.CloseWithError(0, "")
+		Expect(conn.MigrationAllowed()).To(BeFalse())
+		Expect(serverConn.MigrationAllowed()).To(BeFalse())
+		echo(conn)
+
+		oldAddr := conn.LocalAddr()
+		Expect(conn.MigrateTo(&net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 0})).To(MatchError(quic.ErrActiveMigrationDisabled))
+		Expect(conn.LocalAddr()).To(Equal(oldAddr))
+		echo(conn)
+	})
+
+	Context("preferred address", func() {
+		// migrateToPreferredAddress starts a server listening on the unspecified address,
+		// which advertises preferredIP as its preferred address, and dials it on dialIP.
+		migrateToPreferredAddress := func(network string, dialIP, preferredIP netip.Addr, disableActiveMigration bool) {
+			udpConn, err := net.ListenUDP(network, nil)
+			Expect(err).ToNot(HaveOccurred())
+			tr := &quic.Transport{Conn: udpConn}
+			defer tr.Close()
+			port := uint16(udpConn.LocalAddr().(*net.UDPAddr).Port)
+			pa := &quic.PreferredAddress{}
+			if preferredIP.Is4() {
+				pa.IPv4 = netip.AddrPortFrom(preferredIP, port)
+			} else {
+				pa.IPv6 = netip.AddrPortFrom(preferredIP, port)
+			}
+			server, err := tr.Listen(getTLSConfig(), getQuicConfig(&quic.Config{PreferredAddress: pa, DisableActiveMigration: disableActiveMigration}))
+			Expect(err).ToNot(HaveOccurred())
+			defer server.Close()
+			serverConnChan := make(chan quic.Connection, 1)
+			go func() {
+				defer GinkgoRecover()
+				conn, err := server.Accept(context.Background())
+				Expect(err).ToNot(HaveOccurred())
+				serverConnChan <- conn
+				for {
+					str, err := conn.AcceptStream(context.Background())
+					if err != nil {
+						return
+					}
+					_, err = io.Copy(str, str)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(str.Close()).To(Succeed())
+				}
+			}()
+
+			conn, err := quic.DialAddr(
+				context.Background(),
+				netip.AddrPortFrom(dialIP, port).String(),
+				getTLSClientConfig(),
+				getQuicConfig(&quic.Config{UsePreferredAddress: true}),
+			)
+			Expect(err).ToNot(HaveOccurred())
+			defer conn.CloseWithError(0, "")
+			Expect(conn.PeerPreferredAddress()).To(Equal(pa))
+			// make sure that the handshake is confirmed
+			echo(conn)
+			Eventually(func() string { return conn.RemoteAddr().String() }).Should(Equal(netip.AddrPortFrom(preferredIP, port).String()))
+			echo(conn)
+			// the server sends from the preferred address once it switched to the new path
+			var serverConn quic.Connection
+			Eventually(serverConnChan).Should(Receive(&serverConn))
+			Eventually(func() string { return serverConn.LocalAddr().String() }).Should(Equal(netip.AddrPortFrom(preferredIP, port).String()))
+			echo(conn)
+		}
+
+		It("migrates to the server's preferred IPv4 address", func() {
+			migrateToPreferredAddress("udp4", netip.MustParseAddr("127.0.0.1"), netip.MustParseAddr("127.0.0.2"), false)
+		})
+
+		It("migrates to the server's preferred address, even if active migration is disabled", func() {
+			migrateToPreferredAddress("udp4", netip.MustParseAddr("127.0.0.1"), netip.MustParseAddr("127.0.0.2"), true)
+		})
+
+		It("migrates to the server's preferred IPv6 address", func() {
+			c, err := net.ListenUDP("udp6", &net.UDPAddr{IP: net.IPv6loopback})
+			if err != nil {
+				Skip(fmt.Sprintf("IPv6 not available: %s", err))
+			}
+			c.Close()
+			// listen on a dual-stack socket
+			migrateToPreferredAddress("udp", netip.MustParseAddr("127.0.0.1"), netip.IPv6Loopback(), false)
+		})
+	})
+})
@@ -133,6 +133,65 @@ var _ = Describe("Multiplexing", func() {
 		})
 	})
 
+	It("counts the connections on a transport", func() {
+		newTransport := func() *quic.Transport {
+			conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 0})
+			Expect(err).ToNot(HaveOccurred())
+			tr := &quic.Transport{Conn: conn}
+			addTracer(tr)
+			return tr
+		}
+
+		serverTr := newTransport()
+		defer serverTr.Conn.Close()
+		defer serverTr.Close()
+		server, err := serverTr.Listen(getTLSConfig(), getQuicConfig(nil))
+		Expect(err).ToNot(HaveOccurred())
+		defer server.Close()
+		tr := newTransport()
+		defer tr.Conn.Close()
+		defer tr.Close()
+
+		Expect(tr.NumConnections()).To(BeZero())
+		conn1, err := tr.Dial(context.Background(), server.Addr(), getTLSClientConfig(), getQuicConfig(nil))
+		Expect(err).ToNot(HaveOccurred())
+		conn2, err := tr.Dial(context.Background(), server.Addr(), getTLSClientConfig(), getQuicConfig(nil))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(tr.NumConnections()).To(Equal(2))
+		Eventually(serverTr.NumConnections).Should(Equal(2))
+
+		var numCalled, numCalledServer atomic.Int32
+		tr.OnEmpty(func() { numCalled.Add(1) })
+		serverTr.OnEmpty(func() { numCalledServer.Add(1) })
+		Expect(conn1.CloseWithError(0, "")).To(Succeed())
+		Eventually(tr.NumConnections).Should(Equal(1))
+		Eventually(serverTr.NumConnections).Should(Equal(1))
+		Consistently(numCalled.Load, scaleDuration(20*time.Millisecond)).Should(BeZero())
+		Expect(numCalledServer.Load()).To(BeZero())
+		Expect(conn2.CloseWithError(0, "")).To(Succeed())
+		Eventually(tr.NumConnections).Should(BeZero())
+		Eventually(numCalled.Load).Should(BeEquivalentTo(1))
+		Eventually(serverTr.NumConnections).Should(BeZero())
+		Eventually(numCalledServer.Load).Should(BeEquivalentTo(1))
+		Consistently(numCalled.Load, scaleDuration(20*time.Millisecond)).Should(BeEquivalentTo(1))
+		Expect(numCalledServer.Load()).To(BeEquivalentTo(1))
+
+		// connections are counted while they are being dialed
+		ctx, cancel := context.WithTimeout(context.Background(), scaleDuration(100*time.Millisecond))
+		defer cancel()
+		dialErr := make(chan error, 1)
+		go func() {
+			_, err := tr.Dial(ctx, &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 1}, getTLSClientConfig(), getQuicConfig(nil))
+			dialErr <- err
+		}()
+		Eventually(tr.NumConnections).Should(Equal(1))
+		tr.OnEmpty(func() { numCalled.Add(1) })
+		Expect(numCalled.Load()).To(BeEquivalentTo(1))
+		Eventually(dialErr).Should(Receive(HaveOccurred()))
+		Eventually(numCalled.Load).Should(BeEquivalentTo(2))
+		Expect(tr.NumConnections()).To(BeZero())
+	})
+
 	Context("multiplexing server and client on the same conn", func() {
 		It("connects to itself", func() {
 			addr, err := net.ResolveUDPAddr("udp", "localhost:0")
@@ -293,4 +352,47 @@ var _ = Describe("Multiplexing", func() {
 		Eventually(func() int64 { return sentPackets.Load() }).Should(BeNumerically(">", 10))
 		Eventually(func() int64 { return rcvdPackets.Load() }).Should(BeNumerically(">=", sentPackets.Load()*4/5))
 	})
+
+	It("passes non-QUIC packets to the unknown packet handler", func() {
+		conn1, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 0})
+		Expect(err).ToNot(HaveOccurred())
+		defer conn1.Close()
+		tr1 := &quic.Transport{Conn: conn1}
+		addTracer(tr1)
+		type unknownPacket struct {
+			data []byte
+			addr net.Addr
+		}
+		unknownPackets := make(chan unknownPacket, 10)
+		tr1.SetUnknownPacketHandler(func(data []byte, addr net.Addr) {
+			unknownPackets <- unknownPacket{data: append([]byte(nil), data...), addr: addr}
+		})
+		server, err := tr1.Listen(getTLSConfig(), getQuicConfig(nil))
+		Expect(err).ToNot(HaveOccurred())
+		runServer(server)
+		defer server.Close()
+
+		conn2, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 0})
+		Expect(err).ToNot(HaveOccurred())
+		defer conn2.Close()
+		tr2 := &quic.Transport{Conn: conn2}
+		addTracer(tr2)
+		defer tr2.Close()
+
+		// QUIC connections still work
+		dial(tr2, server.Addr())
+		Expect(unknownPackets).To(BeEmpty())
+
+		// a STUN Binding Request: the first two bits are 0
+		stun := make([]byte, 20)
+		stun[1] = 0x01                                  // message type: Binding Request
+		copy(stun[4:8], []byte{0x21, 0x12, 0xa4, 0x42}) // magic cookie
+		rand.Read(stun[8:])                             // transaction ID
+		_, err = tr2.WriteTo(stun, server.Addr())
+		Expect(err).ToNot(HaveOccurred())
+		var p unknownPacket
+		Eventually(unknownPackets).Should(Receive(&p))
+		Expect(p.data).To(Equal(stun))
+		Expect(p.addr).To(Equal(conn2.LocalAddr()))
+	})
 })
@@ -0,0 +1,89 @@
+package self_test
+
+import (
+	"context"
+	"io"
+	"net"
+	"sync/atomic"
+	"time"
+
+	"github.com/quic-go/quic-go"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type packetInterceptorFunc func([]byte, net.Addr) (quic.PacketAction, time.Duration)
+
+func (f packetInterceptorFunc) InterceptPacket(b []byte, addr net.Addr) (quic.PacketAction, time.Duration) {
+	return f(b, addr)
+}
+
+var _ = Describe("Packet Interceptor", func() {
+	// runTransfer downloads PRData from a server that uses the interceptor.
+	// It returns the number of packets the server declared lost, and the client's connection stats.
+	runTransfer := func(interceptor quic.PacketInterceptor) (uint64, quic.ConnectionStats) {
+		udpConn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 0})
+		Expect(err).ToNot(HaveOccurred())
+		tr := &quic.Transport{Conn: udpConn, PacketInterceptor: interceptor}
+		defer tr.Close()
+		server, err := tr.Listen(getTLSConfig(), getQuicConfig(nil))
+		Expect(err).ToNot(HaveOccurred())
+		defer server.Close()
+
+		serverConnChan := make(chan quic.Connection, 1)
+		go func() {
+			defer GinkgoRecover()
+			conn, err := server.Accept(context.Background())
+			Expect(err).ToNot(HaveOccurred())
+			serverConnChan <- conn
+			str, err := conn.OpenUniStream()
+			Expect(err).ToNot(HaveOccurred())
+			_, err = str.Write(PRData)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(str.Close()).To(Succeed())
+		}()
+
+		conn, err := quic.DialAddr(context.Background(), server.Addr().String(), getTLSClientConfig(), getQuicConfig(nil))
+		Expect(err).ToNot(HaveOccurred())
+		defer conn.CloseWithError(0, "")
+		str, err := conn.AcceptUniStream(context.Background())
+		Expect(err).ToNot(HaveOccurred())
+		data, err := io.ReadAll(str)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(data).To(Equal(PRData))
+
+		var serverConn quic.Connection
+		Eventually(serverConnChan).Should(Receive(&serverConn))
+		return serverConn.Stats().PacketsLost, conn.Stats()
+	}
+
+	It("recovers from dropping every 10th packet", func() {
+		var counter atomic.Int64
+		var dropped atomic.Int64
+		packetsLost, _ := runTransfer(packetInterceptorFunc(func([]byte, net.Addr) (quic.PacketAction, time.Duration) {
+			if counter.Add(1)%10 == 0 {
+				dropped.Add(1)
+				return quic.PacketActionDrop, 0
+			}
+			return quic.PacketActionSend, 0
+		}))
+		Expect(dropped.Load()).To(BeNumerically(">", 10))
+		// loss detection declared (at least most of) the dropped packets lost
+		Expect(packetsLost).To(BeNumerically(">", dropped.Load()/2))
+	})
+
+	It("handles reordered packets", func() {
+		var counter atomic.Int64
+		var delayed atomic.Int64
+		_, clientStats := runTransfer(packetInterceptorFunc(func([]byte, net.Addr) (quic.PacketAction, time.Duration) {
+			if counter.Add(1)%5 == 0 {
+				delayed.Add(1)
+				return quic.PacketActionDelay, scaleDuration(time.Millisecond)
+			}
+			return quic.PacketActionSend, 0
+		}))
+		Expect(clientStats.ReorderedPackets).To(BeNumerically(">", 0))
+		Expect(clientStats.ReorderedPackets).To(BeNumerically("<=", delayed.Load()))
+	})
+})
@@ -3,7 +3,9 @@ package self_test
 import (
 	"context"
 	"fmt"
+	"io"
 	"net"
+	"sync"
 	"time"
 
 	"github.com/quic-go/quic-go"
@@ -117,4 +119,76 @@ var _ = Describe("Packetization", func() {
 			BeNumerically(">", numMsg*9/10),
 		))
 	})
+
+	It("transfers data when GSO is disabled", func() {
+		ln, err := quic.ListenAddr("localhost:0", getTLSConfig(), getQuicConfig(&quic.Config{DisableGSO: true}))
+		Expect(err).ToNot(HaveOccurred())
+		defer ln.Close()
+
+		serverConnChan := make(chan quic.Connection, 1)
+		go func() {
+			defer GinkgoRecover()
+			conn, err := ln.Accept(context.Background())
+			Expect(err).ToNot(HaveOccurred())
+			str, err := conn.OpenUniStream()
+			Expect(err).ToNot(HaveOccurred())
+			_, err = str.Write(PRData)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(str.Close()).To(Succeed())
+			serverConnChan <- conn
+		}()
+
+		conn, err := quic.DialAddr(
+			context.Background(),
+			fmt.Sprintf("localhost:%d", ln.Addr().(*net.UDPAddr).Port),
+			getTLSClientConfig(),
+			getQuicConfig(&quic.Config{DisableGSO: true}),
+		)
+		Expect(err).ToNot(HaveOccurred())
+		defer conn.CloseWithError(0, "")
+		Expect(conn.ConnectionState().GSO).To(BeFalse())
+		str, err := conn.AcceptUniStream(context.Background())
+		Expect(err).ToNot(HaveOccurred())
+		data, err := io.ReadAll(str)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(data).To(Equal(PRData))
+		var serverConn quic.Connection
+		Eventually(serverConnChan).Should(Receive(&serverConn))
+		Expect(serverConn.ConnectionState().GSO).To(BeFalse())
+	})
+
+	It("reports the number of coalesced packets per UDP datagram", func() {
+		var mx sync.Mutex
+		var numPackets []int
+		ln, err := quic.ListenAddr(
+			"localhost:0",
+			getTLSConfig(),
+			getQuicConfig(&quic.Config{
+				Tracer: newTracer(&logging.ConnectionTracer{
+					SentUDPDatagram: func(_ logging.ByteCount, n int) {
+						mx.Lock()
+						defer mx.Unlock()
+						numPackets = append(numPackets, n)
+					},
+				}),
+			}),
+		)
+		Expect(err).ToNot(HaveOccurred())
+		defer ln.Close()
+
+		conn, err := quic.DialAddr(
+			context.Background(),
+			fmt.Sprintf("localhost:%d", ln.Addr().(*net.UDPAddr).Port),
+			getTLSClientConfig(),
+			getQuicConfig(nil),
+		)
+		Expect(err).ToNot(HaveOccurred())
+		conn.CloseWithError(0, "")
+
+		mx.Lock()
+		defer mx.Unlock()
+		Expect(numPackets).ToNot(BeEmpty())
+		// the server coalesces its Initial and Handshake packets
+		Expect(numPackets[0]).To(BeNumerically(">=", 2))
+	})
 })
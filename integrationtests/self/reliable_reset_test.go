@@ -0,0 +1,108 @@
+package self_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math/rand"
+	"net"
+	"time"
+
+	"github.com/quic-go/quic-go"
+	quicproxy "github.com/quic-go/quic-go/integrationtests/tools/proxy"
+	"github.com/quic-go/quic-go/logging"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Reliable Stream Resets", func() {
+	const errorCode quic.StreamErrorCode = 42
+
+	// runServer accepts a single connection, opens a unidirectional stream,
+	// writes data on it and then resets it at the reliable offset.
+	runServer := func(ln *quic.Listener, data []byte, reliableOffset logging.ByteCount) <-chan quic.Connection {
+		connChan := make(chan quic.Connection, 1)
+		go func() {
+			defer GinkgoRecover()
+			conn, err := ln.Accept(context.Background())
+			Expect(err).ToNot(HaveOccurred())
+			str, err := conn.OpenUniStream()
+			Expect(err).ToNot(HaveOccurred())
+			_, err = str.Write(data)
+			Expect(err).ToNot(HaveOccurred())
+			str.ResetAt(errorCode, reliableOffset)
+			connChan <- conn
+		}()
+		return connChan
+	}
+
+	It("delivers the data up to the reliable offset, with packet loss", func() {
+		ln, err := quic.ListenAddr("localhost:0", getTLSConfig(), getQuicConfig(&quic.Config{EnableResetStreamAt: true}))
+		Expect(err).ToNot(HaveOccurred())
+		defer ln.Close()
+		proxy, err := quicproxy.NewQuicProxy("localhost:0", &quicproxy.Opts{
+			RemoteAddr:  fmt.Sprintf("localhost:%d", ln.Addr().(*net.UDPAddr).Port),
+			DelayPacket: func(quicproxy.Direction, []byte) time.Duration { return 5 * time.Millisecond },
+			DropPacket: func(dir quicproxy.Direction, _ []byte) bool {
+				return dir == quicproxy.DirectionOutgoing && rand.Intn(10) == 0
+			},
+		})
+		Expect(err).ToNot(HaveOccurred())
+		defer proxy.Close()
+
+		reliableOffset := logging.ByteCount(len(PRData) / 2)
+		serverConnChan := runServer(ln, PRData, reliableOffset)
+		conn, err := quic.DialAddr(
+			context.Background(),
+			fmt.Sprintf("localhost:%d", proxy.LocalPort()),
+			getTLSClientConfig(),
+			getQuicConfig(&quic.Config{EnableResetStreamAt: true}),
+		)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(conn.ConnectionState().SupportsResetStreamAt).To(BeTrue())
+		str, err := conn.AcceptUniStream(context.Background())
+		Expect(err).ToNot(HaveOccurred())
+		data, err := io.ReadAll(str)
+		Expect(err).To(MatchError(&quic.StreamError{
+			StreamID:  str.StreamID(),
+			ErrorCode: errorCode,
+			Remote:    true,
+		}))
+		// Data beyond the reliable offset might have been read before the RESET_STREAM_AT frame was received.
+		Expect(len(data)).To(BeNumerically(">=", reliableOffset))
+		Expect(data).To(Equal(PRData[:len(data)]))
+		conn.CloseWithError(0, "")
+		var serverConn quic.Connection
+		Eventually(serverConnChan).Should(Receive(&serverConn))
+		Expect(serverConn.ConnectionState().SupportsResetStreamAt).To(BeTrue())
+	})
+
+	It("falls back to RESET_STREAM if the peer doesn't support reliable resets", func() {
+		ln, err := quic.ListenAddr("localhost:0", getTLSConfig(), getQuicConfig(&quic.Config{EnableResetStreamAt: true}))
+		Expect(err).ToNot(HaveOccurred())
+		defer ln.Close()
+
+		serverConnChan := runServer(ln, PRData, logging.ByteCount(len(PRData)/2))
+		conn, err := quic.DialAddr(
+			context.Background(),
+			fmt.Sprintf("localhost:%d", ln.Addr().(*net.UDPAddr).Port),
+			getTLSClientConfig(),
+			getQuicConfig(nil),
+		)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(conn.ConnectionState().SupportsResetStreamAt).To(BeFalse())
+		str, err := conn.AcceptUniStream(context.Background())
+		Expect(err).ToNot(HaveOccurred())
+		_, err = io.ReadAll(str)
+		Expect(err).To(MatchError(&quic.StreamError{
+			StreamID:  str.StreamID(),
+			ErrorCode: errorCode,
+			Remote:    true,
+		}))
+		conn.CloseWithError(0, "")
+		var serverConn quic.Connection
+		Eventually(serverConnChan).Should(Receive(&serverConn))
+		Expect(serverConn.ConnectionState().SupportsResetStreamAt).To(BeFalse())
+	})
+})
@@ -110,4 +110,39 @@ var _ = Describe("non-zero RTT", func() {
 			downloadFile(proxy.LocalPort())
 		})
 	}
+	It("measures the RTT using Ping", func() {
+		ln, err := quic.ListenAddr("localhost:0", getTLSConfig(), getQuicConfig(nil))
+		Expect(err).ToNot(HaveOccurred())
+		defer ln.Close()
+		go func() {
+			defer GinkgoRecover()
+			conn, err := ln.Accept(context.Background())
+			Expect(err).ToNot(HaveOccurred())
+			<-conn.Context().Done()
+		}()
+
+		const rtt = 50 * time.Millisecond
+		proxy, err := quicproxy.NewQuicProxy("localhost:0", &quicproxy.Opts{
+			RemoteAddr:  fmt.Sprintf("localhost:%d", ln.Addr().(*net.UDPAddr).Port),
+			DelayPacket: func(quicproxy.Direction, []byte) time.Duration { return scaleDuration(rtt / 2) },
+		})
+		Expect(err).ToNot(HaveOccurred())
+		defer proxy.Close()
+
+		conn, err := quic.DialAddr(
+			context.Background(),
+			fmt.Sprintf("localhost:%d", proxy.LocalPort()),
+			getTLSClientConfig(),
+			getQuicConfig(nil),
+		)
+		Expect(err).ToNot(HaveOccurred())
+		defer conn.CloseWithError(0, "")
+		for i := 0; i < 3; i++ {
+			measured, err := conn.Ping(context.Background())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(measured).To(BeNumerically(">=", scaleDuration(rtt)))
+			// the peer might delay the acknowledgment by up to its max_ack_delay (25ms)
+			Expect(measured).To(BeNumerically("<", scaleDuration(2*rtt)))
+		}
+	})
 })
@@ -0,0 +1,105 @@
+package self_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/quic-go/quic-go"
+	quicproxy "github.com/quic-go/quic-go/integrationtests/tools/proxy"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Spin Bit", func() {
+	const rtt = 10 * time.Millisecond
+
+	// runTransfer transfers data from the server to the client.
+	// It returns the number of times the spin bit flipped on short header packets sent by the client and by the server.
+	runTransfer := func(enable bool) (clientFlips, serverFlips int) {
+		server, err := quic.ListenAddr(
+			"localhost:0",
+			getTLSConfig(),
+			getQuicConfig(&quic.Config{EnableSpinBit: enable}),
+		)
+		Expect(err).ToNot(HaveOccurred())
+		defer server.Close()
+
+		go func() {
+			defer GinkgoRecover()
+			conn, err := server.Accept(context.Background())
+			Expect(err).ToNot(HaveOccurred())
+			str, err := conn.OpenUniStream()
+			Expect(err).ToNot(HaveOccurred())
+			_, err = str.Write(PRData)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(str.Close()).To(Succeed())
+		}()
+
+		var mx sync.Mutex
+		var clientSpin, serverSpin bool
+		proxy, err := quicproxy.NewQuicProxy("localhost:0", &quicproxy.Opts{
+			RemoteAddr:  fmt.Sprintf("localhost:%d", server.Addr().(*net.UDPAddr).Port),
+			DelayPacket: func(quicproxy.Direction, []byte) time.Duration { return rtt / 2 },
+			DropPacket: func(dir quicproxy.Direction, b []byte) bool {
+				// the spin bit is only present on short header packets
+				if b[0]&0x80 > 0 {
+					return false
+				}
+				spin := b[0]&0x20 > 0
+				mx.Lock()
+				defer mx.Unlock()
+				if dir == quicproxy.DirectionIncoming {
+					if spin != clientSpin {
+						clientFlips++
+					}
+					clientSpin = spin
+				} else {
+					if spin != serverSpin {
+						serverFlips++
+					}
+					serverSpin = spin
+				}
+				return false
+			},
+		})
+		Expect(err).ToNot(HaveOccurred())
+		defer proxy.Close()
+
+		conn, err := quic.DialAddr(
+			context.Background(),
+			fmt.Sprintf("localhost:%d", proxy.LocalPort()),
+			getTLSClientConfig(),
+			getQuicConfig(&quic.Config{EnableSpinBit: enable}),
+		)
+		Expect(err).ToNot(HaveOccurred())
+		defer conn.CloseWithError(0, "")
+
+		str, err := conn.AcceptUniStream(context.Background())
+		Expect(err).ToNot(HaveOccurred())
+		data, err := io.ReadAll(str)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(data).To(Equal(PRData))
+
+		mx.Lock()
+		defer mx.Unlock()
+		return clientFlips, serverFlips
+	}
+
+	It("spins once per round trip", func() {
+		clientFlips, serverFlips := runTransfer(true)
+		Expect(clientFlips).To(BeNumerically(">", 2))
+		// The server reflects the client's spin bit, so it lags by at most one flip.
+		Expect(serverFlips).To(BeNumerically("~", clientFlips, 1))
+	})
+
+	It("doesn't spin if disabled", func() {
+		clientFlips, serverFlips := runTransfer(false)
+		Expect(clientFlips).To(BeZero())
+		Expect(serverFlips).To(BeZero())
+	})
+})
@@ -0,0 +1,106 @@
+package self_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"time"
+
+	"github.com/quic-go/quic-go"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Stream Limits", func() {
+	It("stops granting new streams when the limit is reduced", func() {
+		server, err := quic.ListenAddr("localhost:0", getTLSConfig(), getQuicConfig(&quic.Config{MaxIncomingStreams: 3}))
+		Expect(err).ToNot(HaveOccurred())
+		defer server.Close()
+
+		limitReduced := make(chan struct{})
+		streamsDone := make(chan struct{})
+		go func() {
+			defer GinkgoRecover()
+			conn, err := server.Accept(context.Background())
+			Expect(err).ToNot(HaveOccurred())
+			conn.SetMaxIncomingStreams(1)
+			close(limitReduced)
+			for i := 0; i < 3; i++ {
+				str, err := conn.AcceptStream(context.Background())
+				Expect(err).ToNot(HaveOccurred())
+				data, err := io.ReadAll(str)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(data).To(Equal(PRData))
+				Expect(str.Close()).To(Succeed())
+			}
+			close(streamsDone)
+		}()
+
+		conn, err := quic.DialAddr(
+			context.Background(),
+			fmt.Sprintf("localhost:%d", server.Addr().(*net.UDPAddr).Port),
+			getTLSClientConfig(),
+			getQuicConfig(nil),
+		)
+		Expect(err).ToNot(HaveOccurred())
+		defer conn.CloseWithError(0, "")
+		Eventually(limitReduced).Should(BeClosed())
+		// Reducing the limit doesn't affect the streams that were already granted.
+		for i := 0; i < 3; i++ {
+			str, err := conn.OpenStream()
+			Expect(err).ToNot(HaveOccurred())
+			_, err = str.Write(PRData)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(str.Close()).To(Succeed())
+		}
+		_, err = conn.OpenStream()
+		Expect(err).To(HaveOccurred())
+		Expect(err.(net.Error).Temporary()).To(BeTrue())
+		Eventually(streamsDone).Should(BeClosed())
+
+		// Once all streams are closed, the server grants a single new stream.
+		ctx, cancel := context.WithTimeout(context.Background(), scaleDuration(time.Second))
+		defer cancel()
+		_, err = conn.OpenStreamSync(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		_, err = conn.OpenStream()
+		Expect(err).To(HaveOccurred())
+		Expect(err.(net.Error).Temporary()).To(BeTrue())
+	})
+
+	It("grants new streams when the limit is increased", func() {
+		server, err := quic.ListenAddr("localhost:0", getTLSConfig(), getQuicConfig(&quic.Config{MaxIncomingUniStreams: 1}))
+		Expect(err).ToNot(HaveOccurred())
+		defer server.Close()
+
+		conn, err := quic.DialAddr(
+			context.Background(),
+			fmt.Sprintf("localhost:%d", server.Addr().(*net.UDPAddr).Port),
+			getTLSClientConfig(),
+			getQuicConfig(nil),
+		)
+		Expect(err).ToNot(HaveOccurred())
+		defer conn.CloseWithError(0, "")
+		serverConn, err := server.Accept(context.Background())
+		Expect(err).ToNot(HaveOccurred())
+
+		_, err = conn.OpenUniStream()
+		Expect(err).ToNot(HaveOccurred())
+		_, err = conn.OpenUniStream()
+		Expect(err).To(HaveOccurred())
+		Expect(err.(net.Error).Temporary()).To(BeTrue())
+
+		serverConn.SetMaxIncomingUniStreams(3)
+		ctx, cancel := context.WithTimeout(context.Background(), scaleDuration(time.Second))
+		defer cancel()
+		for i := 0; i < 2; i++ {
+			_, err := conn.OpenUniStreamSync(ctx)
+			Expect(err).ToNot(HaveOccurred())
+		}
+		_, err = conn.OpenUniStream()
+		Expect(err).To(HaveOccurred())
+		Expect(err.(net.Error).Temporary()).To(BeTrue())
+	})
+})
@@ -5,11 +5,15 @@ import (
 	"fmt"
 	"io"
 	"net"
+	"slices"
 	"sync"
+	"time"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	"github.com/quic-go/quic-go"
+	quicproxy "github.com/quic-go/quic-go/integrationtests/tools/proxy"
+	"github.com/quic-go/quic-go/internal/protocol"
 )
 
 var _ = Describe("Bidirectional streams", func() {
@@ -153,4 +157,96 @@ var _ = Describe("Bidirectional streams", func() {
 		<-done2
 		client.CloseWithError(0, "")
 	})
+
+	It("waits for the peer to acknowledge stream data", func() {
+		const delay = 10 * time.Millisecond
+		proxy, err := quicproxy.NewQuicProxy("localhost:0", &quicproxy.Opts{
+			RemoteAddr:  serverAddr,
+			DelayPacket: func(quicproxy.Direction, []byte) time.Duration { return scaleDuration(delay / 2) },
+		})
+		Expect(err).ToNot(HaveOccurred())
+		defer proxy.Close()
+
+		serverDone := make(chan struct{})
+		go func() {
+			defer GinkgoRecover()
+			defer close(serverDone)
+			conn, err := server.Accept(context.Background())
+			Expect(err).ToNot(HaveOccurred())
+			str, err := conn.AcceptStream(context.Background())
+			Expect(err).ToNot(HaveOccurred())
+			data, err := io.ReadAll(str)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(data).To(Equal(PRData))
+		}()
+
+		client, err := quic.DialAddr(
+			context.Background(),
+			fmt.Sprintf("localhost:%d", proxy.LocalPort()),
+			getTLSClientConfig(),
+			getQuicConfig(nil),
+		)
+		Expect(err).ToNot(HaveOccurred())
+		defer client.CloseWithError(0, "")
+
+		str, err := client.OpenStream()
+		Expect(err).ToNot(HaveOccurred())
+		_, err = str.Write(PRData)
+		Expect(err).ToNot(HaveOccurred())
+		start := time.Now()
+		Expect(str.WaitForAck(context.Background(), protocol.ByteCount(len(PRData)))).To(Succeed())
+		// the last bytes need to travel to the server, and the ACK needs to travel back
+		Expect(time.Since(start)).To(BeNumerically(">=", scaleDuration(delay)))
+		Expect(str.Close()).To(Succeed())
+		Expect(str.WaitForAck(context.Background(), protocol.ByteCount(len(PRData)+1))).To(MatchError(ContainSubstring("larger than the final size")))
+		Eventually(serverDone).Should(BeClosed())
+	})
+	It("reports the number of bytes acknowledged", func() {
+		serverDone := make(chan struct{})
+		go func() {
+			defer GinkgoRecover()
+			defer close(serverDone)
+			conn, err := server.Accept(context.Background())
+			Expect(err).ToNot(HaveOccurred())
+			str, err := conn.AcceptStream(context.Background())
+			Expect(err).ToNot(HaveOccurred())
+			data, err := io.ReadAll(str)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(data).To(Equal(PRData))
+		}()
+
+		client, err := quic.DialAddr(
+			context.Background(),
+			serverAddr,
+			getTLSClientConfig(),
+			getQuicConfig(nil),
+		)
+		Expect(err).ToNot(HaveOccurred())
+		defer client.CloseWithError(0, "")
+
+		str, err := client.OpenStream()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(str.BytesAcked()).To(BeZero())
+		done := make(chan struct{})
+		go func() {
+			defer GinkgoRecover()
+			defer close(done)
+			_, err := str.Write(PRData)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(str.Close()).To(Succeed())
+		}()
+		// poll the counter while the data is being sent
+		var observed []protocol.ByteCount
+		Eventually(func() protocol.ByteCount {
+			n := str.BytesAcked()
+			if len(observed) == 0 || observed[len(observed)-1] != n {
+				observed = append(observed, n)
+			}
+			return n
+		}).WithPolling(time.Millisecond).Should(Equal(protocol.ByteCount(len(PRData))))
+		Eventually(done).Should(BeClosed())
+		Eventually(serverDone).Should(BeClosed())
+		Expect(len(observed)).To(BeNumerically(">", 2))
+		Expect(slices.IsSorted(observed)).To(BeTrue())
+	})
 })
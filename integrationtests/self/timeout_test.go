@@ -169,6 +169,60 @@ var _ = Describe("Timeout tests", func() {
 		checkTimeoutError(err)
 	})
 
+	It("closes the connection after the maximum number of PTOs", func() {
+		const maxPTOCount = 3
+
+		server, err := quic.ListenAddr("localhost:0", getTLSConfig(), getQuicConfig(nil))
+		Expect(err).ToNot(HaveOccurred())
+		defer server.Close()
+
+		var drop atomic.Bool
+		proxy, err := quicproxy.NewQuicProxy("localhost:0", &quicproxy.Opts{
+			RemoteAddr: fmt.Sprintf("localhost:%d", server.Addr().(*net.UDPAddr).Port),
+			DropPacket: func(quicproxy.Direction, []byte) bool { return drop.Load() },
+		})
+		Expect(err).ToNot(HaveOccurred())
+		defer proxy.Close()
+
+		var ptoCount atomic.Uint32
+		conn, err := quic.DialAddr(
+			context.Background(),
+			fmt.Sprintf("localhost:%d", proxy.LocalPort()),
+			getTLSClientConfig(),
+			getQuicConfig(&quic.Config{
+				DisablePathMTUDiscovery: true,
+				MaxIdleTimeout:          time.Minute,
+				MaxPTOCount:             maxPTOCount,
+				Tracer: func(context.Context, logging.Perspective, quic.ConnectionID) *logging.ConnectionTracer {
+					return &logging.ConnectionTracer{UpdatedPTOCount: func(n uint32) { ptoCount.Store(n) }}
+				},
+			}),
+		)
+		Expect(err).ToNot(HaveOccurred())
+		defer conn.CloseWithError(0, "")
+		serverConn, err := server.Accept(context.Background())
+		Expect(err).ToNot(HaveOccurred())
+		defer serverConn.CloseWithError(0, "")
+
+		// blackhole the path, and send some data that won't be acknowledged
+		drop.Store(true)
+		start := time.Now()
+		str, err := conn.OpenUniStream()
+		Expect(err).ToNot(HaveOccurred())
+		_, err = str.Write([]byte("foobar"))
+		Expect(err).ToNot(HaveOccurred())
+
+		Eventually(conn.Context().Done(), scaleDuration(10*time.Second)).Should(BeClosed())
+		Expect(time.Since(start)).To(BeNumerically("<", 30*time.Second))
+		err = context.Cause(conn.Context())
+		Expect(err).To(MatchError(&quic.PTOLimitReachedError{}))
+		nerr, ok := err.(net.Error)
+		Expect(ok).To(BeTrue())
+		Expect(nerr.Timeout()).To(BeTrue())
+		// the connection is only closed when the PTO expired once more after sending maxPTOCount probes
+		Expect(ptoCount.Load()).To(BeEquivalentTo(maxPTOCount + 1))
+	})
+
 	Context("timing out at the right time", func() {
 		var idleTimeout time.Duration
 
@@ -316,6 +370,72 @@ var _ = Describe("Timeout tests", func() {
 			(<-serverConnChan).CloseWithError(0, "")
 			Eventually(serverConnClosed).Should(BeClosed())
 		})
+
+		It("times out if only DATAGRAMs are exchanged, if configured to ignore DATAGRAMs", func() {
+			server, err := quic.ListenAddr(
+				"localhost:0",
+				getTLSConfig(),
+				getQuicConfig(&quic.Config{EnableDatagrams: true, DisablePathMTUDiscovery: true}),
+			)
+			Expect(err).ToNot(HaveOccurred())
+			defer server.Close()
+
+			go func() {
+				defer GinkgoRecover()
+				conn, err := server.Accept(context.Background())
+				Expect(err).ToNot(HaveOccurred())
+				// echo all DATAGRAMs
+				for {
+					data, err := conn.ReceiveDatagram(context.Background())
+					if err != nil {
+						return
+					}
+					conn.SendDatagram(data)
+				}
+			}()
+
+			conn, err := quic.DialAddr(
+				context.Background(),
+				fmt.Sprintf("localhost:%d", server.Addr().(*net.UDPAddr).Port),
+				getTLSClientConfig(),
+				getQuicConfig(&quic.Config{
+					MaxIdleTimeout:              idleTimeout,
+					EnableDatagrams:             true,
+					IdleTimeoutIgnoresDatagrams: true,
+					DisablePathMTUDiscovery:     true,
+				}),
+			)
+			Expect(err).ToNot(HaveOccurred())
+			start := time.Now()
+			var numEchoed atomic.Int32
+			go func() {
+				defer GinkgoRecover()
+				for {
+					if _, err := conn.ReceiveDatagram(context.Background()); err != nil {
+						return
+					}
+					numEchoed.Add(1)
+				}
+			}()
+			ticker := time.NewTicker(idleTimeout / 10)
+			defer ticker.Stop()
+		loop:
+			for {
+				select {
+				case <-ticker.C:
+					conn.SendDatagram([]byte("foobar"))
+				case <-conn.Context().Done():
+					break loop
+				}
+			}
+			checkTimeoutError(context.Cause(conn.Context()))
+			Expect(time.Since(start)).To(And(
+				BeNumerically(">=", idleTimeout),
+				BeNumerically("<", 2*idleTimeout),
+			))
+			// make sure that DATAGRAMs were actually exchanged
+			Expect(numEchoed.Load()).To(BeNumerically(">", 3))
+		})
 	})
 
 	It("does not time out if keepalive is set", func() {
@@ -9,6 +9,7 @@ import (
 
 	"github.com/quic-go/quic-go"
 	"github.com/quic-go/quic-go/internal/protocol"
+	"github.com/quic-go/quic-go/quicvarint"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -144,4 +145,97 @@ var _ = Describe("Unidirectional Streams", func() {
 		<-done2
 		client.CloseWithError(0, "")
 	})
+
+	It("rejects streams by stream type", func() {
+		const allowedType = 0x54
+		var mx sync.Mutex
+		var streamTypes []uint64
+		server.Close()
+		var err error
+		server, err = quic.ListenAddr(
+			"localhost:0",
+			getTLSConfig(),
+			getQuicConfig(&quic.Config{
+				AcceptUniStream: func(streamType uint64) bool {
+					mx.Lock()
+					defer mx.Unlock()
+					streamTypes = append(streamTypes, streamType)
+					return streamType == allowedType
+				},
+				RejectedUniStreamErrorCode: 0x103,
+			}),
+		)
+		Expect(err).ToNot(HaveOccurred())
+
+		client, err := quic.DialAddr(
+			context.Background(),
+			fmt.Sprintf("localhost:%d", server.Addr().(*net.UDPAddr).Port),
+			getTLSClientConfig(),
+			getQuicConfig(nil),
+		)
+		Expect(err).ToNot(HaveOccurred())
+		defer client.CloseWithError(0, "")
+
+		// the server rejects the stream as soon as it receives the stream type
+		rejected, err := client.OpenUniStream()
+		Expect(err).ToNot(HaveOccurred())
+		_, err = rejected.Write(quicvarint.Append(nil, 0x21))
+		Expect(err).ToNot(HaveOccurred())
+		Eventually(func() error {
+			_, err := rejected.Write(PRData)
+			return err
+		}).Should(MatchError(&quic.StreamError{StreamID: rejected.StreamID(), ErrorCode: 0x103, Remote: true}))
+
+		accepted, err := client.OpenUniStream()
+		Expect(err).ToNot(HaveOccurred())
+		_, err = accepted.Write(append(quicvarint.Append(nil, allowedType), []byte("foobar")...))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(accepted.Close()).To(Succeed())
+
+		serverConn, err := server.Accept(context.Background())
+		Expect(err).ToNot(HaveOccurred())
+		str, err := serverConn.AcceptUniStream(context.Background())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(str.StreamID()).To(Equal(accepted.StreamID()))
+		// the stream type is not consumed
+		streamType, err := quicvarint.Read(quicvarint.NewReader(str))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(streamType).To(BeEquivalentTo(allowedType))
+		data, err := io.ReadAll(str)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(data).To(Equal([]byte("foobar")))
+		mx.Lock()
+		Expect(streamTypes).To(Equal([]uint64{0x21, allowedType}))
+		mx.Unlock()
+	})
+
+	It("sends and receives messages", func() {
+		const numMessages = 50
+		go func() {
+			defer GinkgoRecover()
+			conn, err := server.Accept(context.Background())
+			Expect(err).ToNot(HaveOccurred())
+			for i := 0; i < numMessages; i++ {
+				Expect(conn.SendMessage(context.Background(), GeneratePRData(100*i))).To(Succeed())
+			}
+			// a message exceeding the client's maximum message size
+			Expect(conn.SendMessage(context.Background(), GeneratePRData(10000))).To(Succeed())
+		}()
+
+		client, err := quic.DialAddr(
+			context.Background(),
+			serverAddr,
+			getTLSClientConfig(),
+			getQuicConfig(&quic.Config{MaxMessageSize: 5000}),
+		)
+		Expect(err).ToNot(HaveOccurred())
+		defer client.CloseWithError(0, "")
+		for i := 0; i < numMessages; i++ {
+			msg, err := client.AcceptMessage(context.Background())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(msg).To(Equal(GeneratePRData(100 * i)))
+		}
+		_, err = client.AcceptMessage(context.Background())
+		Expect(err).To(MatchError(&quic.MessageTooLargeError{MaxMessageSize: 5000}))
+	})
 })
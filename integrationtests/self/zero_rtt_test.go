@@ -3,6 +3,7 @@ package self_test
 import (
 	"context"
 	"crypto/tls"
+	"errors"
 	"fmt"
 	"io"
 	"net"
@@ -50,6 +51,41 @@ func (m metadataClientSessionCache) Put(key string, session *tls.ClientSessionSt
 	m.cache.Put(key, session)
 }
 
+// serializingClientSessionCache stores serialized sessions,
+// simulating a client that persists its sessions to disk.
+type serializingClientSessionCache struct {
+	mx       sync.Mutex
+	sessions map[string][]byte
+}
+
+func newSerializingClientSessionCache(sessions map[string][]byte) *serializingClientSessionCache {
+	return &serializingClientSessionCache{sessions: sessions}
+}
+
+func (c *serializingClientSessionCache) Get(key string) (*tls.ClientSessionState, bool) {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+	b, ok := c.sessions[key]
+	if !ok {
+		return nil, false
+	}
+	session, err := quic.UnmarshalClientSessionState(b)
+	Expect(err).ToNot(HaveOccurred())
+	return session, true
+}
+
+func (c *serializingClientSessionCache) Put(key string, session *tls.ClientSessionState) {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+	if session == nil {
+		delete(c.sessions, key)
+		return
+	}
+	b, err := quic.MarshalClientSessionState(session)
+	Expect(err).ToNot(HaveOccurred())
+	c.sessions[key] = b
+}
+
 // contains0RTTPacket says if a packet contains a 0-RTT long header packet.
 // It correctly handles coalesced packets.
 func contains0RTTPacket(data []byte) bool {
@@ -210,6 +246,7 @@ var _ = Describe("0-RTT", func() {
 		ln *quic.EarlyListener,
 		proxyPort int,
 		clientConf *tls.Config,
+		reason quic.ZeroRTTRejectionReason,
 	) {
 		conn, err := quic.DialAddrEarly(
 			context.Background(),
@@ -224,6 +261,14 @@ var _ = Describe("0-RTT", func() {
 		Expect(err).ToNot(HaveOccurred())
 		Expect(str.Close()).To(Succeed())
 		Expect(conn.ConnectionState().Used0RTT).To(BeFalse())
+		// after completion of the handshake, the client knows that 0-RTT was rejected
+		Eventually(conn.HandshakeComplete()).Should(BeClosed())
+		Expect(conn.Used0RTT()).To(BeFalse())
+		_, err = conn.OpenUniStream()
+		Expect(err).To(MatchError(quic.Err0RTTRejected))
+		var rejectedErr *quic.ZeroRTTRejectedError
+		Expect(errors.As(err, &rejectedErr)).To(BeTrue())
+		Expect(rejectedErr.Reason).To(Equal(reason))
 
 		// make sure the server doesn't process the data
 		ctx, cancel := context.WithTimeout(context.Background(), scaleDuration(50*time.Millisecond))
@@ -555,6 +600,69 @@ var _ = Describe("0-RTT", func() {
 		Expect(serverConn.ConnectionState().Used0RTT).To(BeFalse())
 	})
 
+	It("transfers 0-RTT data using a serialized session", func() {
+		tlsConf := getTLSConfig()
+		sessions := make(map[string][]byte)
+		clientConf := getTLSClientConfig()
+		clientConf.ClientSessionCache = newSerializingClientSessionCache(sessions)
+		dialAndReceiveSessionTicket(tlsConf, nil, clientConf)
+		Expect(sessions).To(HaveLen(1))
+
+		ln, err := quic.ListenAddrEarly(
+			"localhost:0",
+			tlsConf,
+			getQuicConfig(&quic.Config{Allow0RTT: true}),
+		)
+		Expect(err).ToNot(HaveOccurred())
+		defer ln.Close()
+		proxy, num0RTTPackets := runCountingProxy(ln.Addr().(*net.UDPAddr).Port)
+		defer proxy.Close()
+
+		// Use a new session cache, initialized from the serialized sessions.
+		// This simulates a client process that restores the sessions saved by a previous process.
+		restored := make(map[string][]byte, len(sessions))
+		for k, v := range sessions {
+			restored[k] = append([]byte{}, v...)
+		}
+		clientConf = getTLSClientConfig()
+		clientConf.ClientSessionCache = newSerializingClientSessionCache(restored)
+		transfer0RTTData(ln, proxy.LocalPort(), protocol.DefaultConnectionIDLength, clientConf, nil, PRData)
+		Expect(num0RTTPackets.Load()).ToNot(BeZero())
+	})
+
+	It("refuses to restore a serialized session with invalid QUIC resumption state", func() {
+		tlsConf := getTLSConfig()
+		cache := tls.NewLRUClientSessionCache(1)
+		gets := make(chan string, 100)
+		clientConf := getTLSClientConfig()
+		clientConf.ClientSessionCache = newClientSessionCache(cache, gets, make(chan string, 100))
+		dialAndReceiveSessionTicket(tlsConf, nil, clientConf)
+
+		// find the session key by resuming the session once
+		var key string
+		Expect(gets).To(Receive(&key))
+		session, ok := cache.Get(key)
+		Expect(ok).To(BeTrue())
+		b, err := quic.MarshalClientSessionState(session)
+		Expect(err).ToNot(HaveOccurred())
+		restored, err := quic.UnmarshalClientSessionState(b)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(restored).ToNot(BeNil())
+
+		// corrupt the transport parameters saved by quic-go
+		ticket, state, err := session.ResumptionState()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(state.EarlyData).To(BeTrue())
+		Expect(state.Extra).To(HaveLen(1))
+		state.Extra[0] = append(state.Extra[0], 0x42)
+		session, err = tls.NewResumptionState(ticket, state)
+		Expect(err).ToNot(HaveOccurred())
+		b, err = quic.MarshalClientSessionState(session)
+		Expect(err).ToNot(HaveOccurred())
+		_, err = quic.UnmarshalClientSessionState(b)
+		Expect(err).To(MatchError(ContainSubstring("invalid QUIC resumption state")))
+	})
+
 	It("doesn't reject 0-RTT when the server's transport stream limit increased", func() {
 		const maxStreams = 1
 		tlsConf := getTLSConfig()
@@ -596,6 +704,8 @@ var _ = Describe("0-RTT", func() {
 		defer cancel()
 		_, err = conn.OpenUniStreamSync(ctx)
 		Expect(err).ToNot(HaveOccurred())
+		Eventually(conn.HandshakeComplete()).Should(BeClosed())
+		Expect(conn.Used0RTT()).To(BeTrue())
 		Expect(conn.ConnectionState().Used0RTT).To(BeTrue())
 		Expect(conn.CloseWithError(0, "")).To(Succeed())
 	})
@@ -623,7 +733,7 @@ var _ = Describe("0-RTT", func() {
 		proxy, num0RTTPackets := runCountingProxy(ln.Addr().(*net.UDPAddr).Port)
 		defer proxy.Close()
 
-		check0RTTRejected(ln, proxy.LocalPort(), clientConf)
+		check0RTTRejected(ln, proxy.LocalPort(), clientConf, quic.ZeroRTTRejectionReasonTransportParameters)
 
 		// The client should send 0-RTT packets, but the server doesn't process them.
 		num0RTT := num0RTTPackets.Load()
@@ -656,7 +766,7 @@ var _ = Describe("0-RTT", func() {
 		proxy, num0RTTPackets := runCountingProxy(ln.Addr().(*net.UDPAddr).Port)
 		defer proxy.Close()
 
-		check0RTTRejected(ln, proxy.LocalPort(), clientConf)
+		check0RTTRejected(ln, proxy.LocalPort(), clientConf, quic.ZeroRTTRejectionReasonALPN)
 
 		// The client should send 0-RTT packets, but the server doesn't process them.
 		num0RTT := num0RTTPackets.Load()
@@ -685,7 +795,38 @@ var _ = Describe("0-RTT", func() {
 		proxy, num0RTTPackets := runCountingProxy(ln.Addr().(*net.UDPAddr).Port)
 		defer proxy.Close()
 
-		check0RTTRejected(ln, proxy.LocalPort(), clientConf)
+		check0RTTRejected(ln, proxy.LocalPort(), clientConf, quic.ZeroRTTRejectionReasonUnknown)
+
+		// The client should send 0-RTT packets, but the server doesn't process them.
+		num0RTT := num0RTTPackets.Load()
+		fmt.Fprintf(GinkgoWriter, "Sent %d 0-RTT packets.", num0RTT)
+		Expect(num0RTT).ToNot(BeZero())
+		Expect(get0RTTPackets(counter.getRcvdLongHeaderPackets())).To(BeEmpty())
+	})
+
+	It("rejects 0-RTT when the server doesn't accept the session ticket", func() {
+		tlsConf := getTLSConfig()
+		clientConf := getTLSClientConfig()
+		dialAndReceiveSessionTicket(tlsConf, nil, clientConf)
+
+		// rotate the session ticket key, so the server can't decrypt the session ticket
+		tlsConf = tlsConf.Clone()
+		tlsConf.SetSessionTicketKeys([][32]byte{{1, 2, 3, 4}})
+		counter, tracer := newPacketTracer()
+		ln, err := quic.ListenAddrEarly(
+			"localhost:0",
+			tlsConf,
+			getQuicConfig(&quic.Config{
+				Allow0RTT: true,
+				Tracer:    newTracer(tracer),
+			}),
+		)
+		Expect(err).ToNot(HaveOccurred())
+		defer ln.Close()
+		proxy, num0RTTPackets := runCountingProxy(ln.Addr().(*net.UDPAddr).Port)
+		defer proxy.Close()
+
+		check0RTTRejected(ln, proxy.LocalPort(), clientConf, quic.ZeroRTTRejectionReasonSessionTicket)
 
 		// The client should send 0-RTT packets, but the server doesn't process them.
 		num0RTT := num0RTTPackets.Load()
@@ -869,7 +1010,7 @@ var _ = Describe("0-RTT", func() {
 		Expect(err).To(MatchError(quic.Err0RTTRejected))
 
 		_, err = conn.AcceptStream(ctx)
-		Expect(err).To(Equal(quic.Err0RTTRejected))
+		Expect(err).To(Equal(&quic.ZeroRTTRejectedError{Reason: quic.ZeroRTTRejectionReasonTransportParameters}))
 
 		newConn, err := conn.NextConnection(context.Background())
 		Expect(err).ToNot(HaveOccurred())
@@ -150,6 +150,33 @@ var _ = Describe("Handshake tests", func() {
 			Expect(serverResult.clientVersions).To(BeEmpty())
 		})
 
+		It("downgrades to a version that's not preferred by the client", func() {
+			serverConfig := &quic.Config{Versions: []protocol.Version{protocol.Version1}}
+			server, cl := startServer(getTLSConfig(), serverConfig)
+			defer cl()
+			negotiated := make(chan protocol.Version, 2)
+			clientResult, clientTracer := newVersionNegotiationTracer()
+			conn, err := quic.DialAddr(
+				context.Background(),
+				fmt.Sprintf("localhost:%d", server.Addr().(*net.UDPAddr).Port),
+				getTLSClientConfig(),
+				maybeAddQLOGTracer(&quic.Config{
+					Versions:            []protocol.Version{protocol.Version2, protocol.Version1},
+					OnVersionNegotiated: func(v quic.Version) { negotiated <- v },
+					Tracer: func(context.Context, logging.Perspective, quic.ConnectionID) *logging.ConnectionTracer {
+						return clientTracer
+					},
+				}),
+			)
+			Expect(err).ToNot(HaveOccurred())
+			defer conn.CloseWithError(0, "")
+			Expect(conn.ConnectionState().Version).To(Equal(protocol.Version1))
+			Expect(clientResult.receivedVersionNegotiation).To(BeTrue())
+			Expect(clientResult.chosen).To(Equal(protocol.Version1))
+			Eventually(negotiated).Should(Receive(Equal(protocol.Version1)))
+			Consistently(negotiated, 50*time.Millisecond).ShouldNot(Receive())
+		})
+
 		It("fails if the server disables version negotiation", func() {
 			// The server doesn't support the highest supported version, which is the first one the client will try,
 			// but it supports a bunch of versions that the client doesn't speak
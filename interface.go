@@ -6,10 +6,13 @@ import (
 	"errors"
 	"io"
 	"net"
+	"net/netip"
 	"time"
 
+	"github.com/quic-go/quic-go/internal/congestion"
 	"github.com/quic-go/quic-go/internal/handshake"
 	"github.com/quic-go/quic-go/internal/protocol"
+	"github.com/quic-go/quic-go/internal/utils"
 	"github.com/quic-go/quic-go/logging"
 )
 
@@ -52,8 +55,14 @@ type TokenStore interface {
 // * Accept{Uni}Stream
 // * Stream.Read and Stream.Write
 // when the server rejects a 0-RTT connection attempt.
+// Data sent on these streams was not processed by the server, and needs to be resent.
+// The error returned is a *ZeroRTTRejectedError, which contains the reason for the rejection.
 var Err0RTTRejected = errors.New("0-RTT rejected")
 
+// ErrActiveMigrationDisabled is returned by Connection.MigrateTo, Connection.ProbePath and Connection.AddPath
+// if the server sent the disable_active_migration transport parameter.
+var ErrActiveMigrationDisabled = errors.New("peer disabled active migration")
+
 // ConnectionTracingKey can be used to associate a ConnectionTracer with a Connection.
 // It is set on the Connection.Context() context,
 // as well as on the context passed to logging.Tracer.NewConnectionTracer.
@@ -80,6 +89,16 @@ type Stream interface {
 	// with the connection. It is equivalent to calling both
 	// SetReadDeadline and SetWriteDeadline.
 	SetDeadline(t time.Time) error
+	// CloseRead closes the read-direction of the stream, like CancelRead.
+	// It doesn't affect the write-direction. Only the first call has an effect,
+	// and it can be called before or after CloseWrite.
+	CloseRead(StreamErrorCode)
+	// CloseWrite closes the write-direction of the stream, like Close.
+	// It doesn't affect the read-direction. Only the first call has an effect,
+	// and it can be called before or after CloseRead.
+	// Unlike Close, it is a no-op if the write-direction was already closed or canceled,
+	// either using CancelWrite, or by the peer sending a STOP_SENDING frame.
+	CloseWrite()
 }
 
 // A ReceiveStream is a unidirectional Receive Stream.
@@ -99,11 +118,30 @@ type ReceiveStream interface {
 	// Read will unblock immediately, and future Read calls will fail.
 	// When called multiple times or after reading the io.EOF it is a no-op.
 	CancelRead(StreamErrorCode)
+	// StopSending asks the peer to stop transmitting stream data, like CancelRead.
+	// Unlike CancelRead, data that was already received remains readable:
+	// Read returns the contiguous data received before StopSending was called,
+	// and only then fails with a StreamError. Data that arrives later is not delivered.
+	// If nothing is buffered, it behaves like CancelRead.
+	// If all data (including the FIN) was already received, it is a no-op.
+	StopSending(StreamErrorCode)
 	// SetReadDeadline sets the deadline for future Read calls and
 	// any currently-blocked Read call.
 	// A zero value for t means Read will not time out.
 
 	SetReadDeadline(t time.Time) error
+	// SetReceiveWindow raises the flow control window of this stream to at least size.
+	// Auto-tuning of the window continues from the new size, and may grow it beyond Config.MaxStreamReceiveWindow up to size.
+	// The connection-level flow control window is increased accordingly, but it is still limited by Config.MaxConnectionReceiveWindow.
+	// The window is never decreased: calls with a size smaller than the current window are a no-op.
+	// Sizes exceeding the maximum window that can be advertised (quicvarint.Max minus the data read so far) are reduced.
+	SetReceiveWindow(size logging.ByteCount)
+	// SetDrainOnReset controls what happens to data that was already received when the peer resets the stream.
+	// By default, Read returns the reset error right away, and buffered data is discarded.
+	// If enabled, Read first returns the contiguous data that was received before the RESET_STREAM frame,
+	// and only then returns the reset error. Data that arrives after the RESET_STREAM frame is not delivered.
+	// It needs to be called before the RESET_STREAM frame is received.
+	SetDrainOnReset(bool)
 }
 
 // A SendStream is a unidirectional Send Stream.
@@ -118,6 +156,12 @@ type SendStream interface {
 	// If the connection was closed due to a timeout, the error satisfies
 	// the net.Error interface, and Timeout() will be true.
 	io.Writer
+	// SafeWrite writes data to the stream, like Write, but can be called from multiple goroutines.
+	// Calls are serialized in the order they are made: the data of every call is written
+	// contiguously, and after the data of all calls that started before it.
+	// Goroutines waiting for their turn don't hold any locks, and return as soon as it's their turn
+	// if the stream was canceled or the write deadline has passed.
+	SafeWrite(p []byte) (n int, err error)
 	// Close closes the write-direction of the stream.
 	// Future calls to Write are not permitted after calling Close.
 	// It must not be called concurrently with Write.
@@ -130,6 +174,21 @@ type SendStream interface {
 	// When called after Close, it aborts delivery. Note that there is no guarantee if
 	// the peer will receive the FIN or the reset first.
 	CancelWrite(StreamErrorCode)
+	// CloseThenReset closes the write-direction of the stream, like Close.
+	// All data written so far is delivered reliably, followed by a FIN.
+	// The final size of the stream is the number of bytes written before calling CloseThenReset.
+	// Since the FIN already communicates the final size to the peer, no RESET_STREAM frame is sent,
+	// and the peer's flow controller accounts for exactly the bytes delivered.
+	// Future calls to Write fail with a StreamError carrying the error code, as if CancelWrite had been called.
+	// It must not be called concurrently with Write.
+	CloseThenReset(StreamErrorCode) error
+	// ResetAt aborts sending on this stream, like CancelWrite, but guarantees delivery
+	// of the stream data up to the reliable offset (using a RESET_STREAM_AT frame).
+	// A reliable offset larger than the amount of data written is reduced to that amount.
+	// If the peer doesn't support reliable stream resets, ResetAt behaves exactly like CancelWrite.
+	// Future calls to Write fail with a StreamError carrying the error code.
+	// Calling CancelWrite after ResetAt aborts delivery of the remaining data.
+	ResetAt(code StreamErrorCode, reliableOffset logging.ByteCount)
 	// The Context is canceled as soon as the write-side of the stream is closed.
 	// This happens when Close() or CancelWrite() is called, or when the peer
 	// cancels the read-side of their stream.
@@ -142,8 +201,46 @@ type SendStream interface {
 	// some data was successfully written.
 	// A zero value for t means Write will not time out.
 	SetWriteDeadline(t time.Time) error
+	// SetPriority sets the priority of the stream.
+	// When multiple streams have data to send, streams with a higher priority are sent first.
+	// See StreamPriority for details.
+	SetPriority(StreamPriority)
+	// WaitForAck blocks until the peer has acknowledged all stream data up to offset,
+	// i.e. the first offset bytes written to the stream.
+	// It returns an error if the stream is canceled (unless offset is covered by the reliable size of a ResetAt),
+	// if the connection is closed, or if the stream was closed before offset bytes were written.
+	// The context can be used to stop waiting.
+	WaitForAck(ctx context.Context, offset logging.ByteCount) error
+	// BytesAcked returns the number of bytes at the beginning of the stream that were acknowledged by the peer.
+	// Data acknowledged out of order is only counted once all data before it was acknowledged.
+	// It is safe to call BytesAcked concurrently with all other methods, and it doesn't block.
+	BytesAcked() logging.ByteCount
+	// SetWriteBufferLimit limits the amount of data buffered on the stream.
+	// Once the number of bytes that were written, but not yet acknowledged by the peer, reaches the limit,
+	// Write blocks until enough data has been acknowledged.
+	// This is independent of flow control: Write also blocks if the peer's flow control window is exhausted.
+	// The limit applies to future Write calls and any currently-blocked Write call.
+	// A limit of 0 (the default) means that the amount of buffered data is not limited.
+	SetWriteBufferLimit(logging.ByteCount)
 }
 
+// StreamPriority is the sending priority of a stream.
+// It is modeled after the Extensible Prioritization Scheme for HTTP (RFC 9218).
+type StreamPriority struct {
+	// Urgency is the urgency of the stream, ranging from 0 to 7.
+	// Streams with a lower urgency value are sent before streams with a higher urgency value.
+	// Values larger than 7 are treated as 7.
+	Urgency uint8
+	// Incremental says if the stream can be sent interleaved with other streams of the same urgency.
+	// Incremental streams of the same urgency share the available bandwidth in a round-robin fashion.
+	// Non-incremental streams are sent one after the other, in the order they started sending data.
+	Incremental bool
+}
+
+// DefaultStreamPriority is the priority of streams that SetPriority was not called on.
+// Note that unlike the default priority defined in RFC 9218, streams are incremental by default.
+var DefaultStreamPriority = StreamPriority{Urgency: 3, Incremental: true}
+
 // A Connection is a QUIC connection between two peers.
 // Calls to the connection (and to streams) can return the following types of errors:
 // * ApplicationError: for errors triggered by the application running on top of QUIC
@@ -152,15 +249,42 @@ type SendStream interface {
 // * HandshakeTimeoutError: when the cryptographic handshake takes too long (this is a net.Error timeout error)
 // * StatelessResetError: when we receive a stateless reset (this is a net.Error temporary error)
 // * VersionNegotiationError: returned by the client, when there's no version overlap between the peers
+//
+// The same errors are returned from Dial when the connection is closed during the handshake.
+// Application errors can't be sent before the handshake completes (RFC 9000, section 10.2.3):
+// If the peer closes the connection with an application error before it can be sent in a 1-RTT packet,
+// a TransportError with the ApplicationErrorErrorCode error code is returned instead.
 type Connection interface {
 	// AcceptStream returns the next stream opened by the peer, blocking until one is available.
+	// When the connection is closed, a blocked AcceptStream call returns the connection's error right away.
 	// If the connection was closed due to a timeout, the error satisfies
 	// the net.Error interface, and Timeout() will be true.
 	AcceptStream(context.Context) (Stream, error)
 	// AcceptUniStream returns the next unidirectional stream opened by the peer, blocking until one is available.
+	// When the connection is closed, a blocked AcceptUniStream call returns the connection's error right away.
 	// If the connection was closed due to a timeout, the error satisfies
 	// the net.Error interface, and Timeout() will be true.
 	AcceptUniStream(context.Context) (ReceiveStream, error)
+	// SetMaxIncomingStreams sets the maximum number of concurrent bidirectional streams that the peer
+	// is allowed to open, overriding Config.MaxIncomingStreams.
+	// Since the stream limit granted to the peer can't be revoked, lowering the limit doesn't close any
+	// streams, and doesn't prevent the peer from opening the streams it was already allowed to open.
+	// Instead, the peer isn't granted any new streams until the number of open streams drops below the new limit.
+	// If set to a negative value or 0, the peer isn't granted any new streams.
+	// Values larger than 2^60 will be clipped to that value.
+	SetMaxIncomingStreams(int64)
+	// SetMaxIncomingUniStreams is the same as SetMaxIncomingStreams, but for unidirectional streams.
+	// It overrides Config.MaxIncomingUniStreams.
+	SetMaxIncomingUniStreams(int64)
+	// SetReceiveWindow raises the connection-level flow control window to at least size,
+	// for example when the application knows that a large transfer is coming.
+	// A MAX_DATA frame is sent right away if the larger window warrants it.
+	// Auto-tuning of the window continues from the new size, and may grow it beyond Config.MaxConnectionReceiveWindow up to size.
+	// Config.AllowConnectionWindowIncrease is not called for this increase.
+	// The window is never decreased: calls with a size smaller than the current window are a no-op.
+	// Sizes exceeding the maximum window that can be advertised (quicvarint.Max minus the data read so far) are reduced.
+	// Note that the stream-level flow control windows still apply, see ReceiveStream.SetReceiveWindow.
+	SetReceiveWindow(size logging.ByteCount)
 	// OpenStream opens a new bidirectional QUIC stream.
 	// There is no signaling to the peer about new streams:
 	// The peer can only accept the stream after data has been sent on the stream.
@@ -174,6 +298,8 @@ type Connection interface {
 	// The peer can only accept the stream after data has been sent on the stream,
 	// or the stream has been reset or closed.
 	// If the error is non-nil, it satisfies the net.Error interface.
+	// If the context is canceled while waiting for the peer's stream limit to increase,
+	// the error is a StreamsBlockedError.
 	// If the connection was closed due to a timeout, Timeout() will be true.
 	OpenStreamSync(context.Context) (Stream, error)
 	// OpenUniStream opens a new outgoing unidirectional QUIC stream.
@@ -184,14 +310,88 @@ type Connection interface {
 	// OpenUniStreamSync opens a new outgoing unidirectional QUIC stream.
 	// It blocks until a new stream can be opened.
 	// If the error is non-nil, it satisfies the net.Error interface.
+	// If the context is canceled while waiting for the peer's stream limit to increase,
+	// the error is a StreamsBlockedError.
 	// If the connection was closed due to a timeout, Timeout() will be true.
 	OpenUniStreamSync(context.Context) (SendStream, error)
+	// SendMessage sends a message on a new unidirectional stream, and closes the stream.
+	// It blocks until the stream can be opened and all data was handed to the stream,
+	// but doesn't wait for the peer to acknowledge the message.
+	// If the context is canceled before the message was sent, the stream is reset
+	// (with error code 0), such that the peer never receives a partial message.
+	SendMessage(ctx context.Context, data []byte) error
+	// AcceptMessage accepts the next unidirectional stream opened by the peer,
+	// and reads the message sent on it (e.g. using SendMessage) until the end of the stream.
+	// It uses the same queue of incoming streams as AcceptUniStream.
+	// If the message is larger than Config.MaxMessageSize, reading is canceled (with error code 0),
+	// and a MessageTooLargeError is returned.
+	// If the context is canceled while reading the message, reading is canceled as well.
+	AcceptMessage(context.Context) ([]byte, error)
+	// StreamsAvailable returns the number of bidirectional and unidirectional streams
+	// that can be opened right now, without blocking, given the peer's stream limits.
+	StreamsAvailable() (bidi, uni int64)
+	// ActiveStreams returns the IDs of all streams that are currently open, sorted in ascending order.
+	// This includes bidirectional and unidirectional streams, opened by either peer,
+	// as well as streams opened by the peer that were not yet accepted.
+	// A stream is removed once it is completed, i.e. once both its send and its receive direction are done.
+	ActiveStreams() []StreamID
 	// LocalAddr returns the local address.
 	LocalAddr() net.Addr
 	// RemoteAddr returns the address of the peer.
 	RemoteAddr() net.Addr
+	// MigrateTo migrates the connection to a new local address, e.g. when switching from Wi-Fi to cellular.
+	// It opens a new UDP socket bound to this address and validates the new path using PATH_CHALLENGE frames.
+	// Once the peer's PATH_RESPONSE is received, the connection switches to the new path,
+	// and the MigratedConnection callback of the ConnectionTracer is called.
+	// It blocks until path validation completes or fails.
+	// Only the client can migrate a connection, and only after the handshake has been confirmed.
+	// If the server disabled active migration, ErrActiveMigrationDisabled is returned.
+	// The only exception is the migration to the server's preferred address, see Config.UsePreferredAddress.
+	MigrateTo(local net.Addr) error
+	// ProbePath validates a path without migrating the connection to it.
+	// It opens a new UDP socket bound to the local address and sends PATH_CHALLENGE frames to the remote address.
+	// If remote is nil, the peer's current address is used.
+	// The connection keeps sending all other packets on the current path.
+	// It blocks until path validation completes or times out. A path that doesn't validate within 3 PTOs
+	// is reported with PathInfo.Validated set to false.
+	// The same restrictions as for MigrateTo apply, and only one path can be validated at a time.
+	ProbePath(local, remote net.Addr) (PathInfo, error)
+	// AddPath validates a path and keeps it as a backup path, which the connection can fail over to using SwitchToPath.
+	// It opens a new UDP socket bound to the local address and validates the path to the remote address
+	// (the peer's current address, if nil), the same way ProbePath does.
+	// The connection keeps sending on the current path; only PATH_CHALLENGE frames are sent on the backup path.
+	// The backup path is revalidated periodically, which keeps NAT bindings alive.
+	// The result of the last validation is reported by PathStatus.
+	// Only a single backup path is supported, and the same restrictions as for MigrateTo apply.
+	// It blocks until path validation completes, and returns an error if it fails.
+	// This is not the QUIC multipath extension: the enable_multipath transport parameter is not negotiated,
+	// and there are no path-specific packet number spaces or acknowledgements. The backup path is validated
+	// using the connection migration mechanism of RFC 9000, and application data is only sent on one path at a time.
+	AddPath(local, remote net.Addr) (PathID, error)
+	// SwitchToPath migrates the connection to a path added by AddPath.
+	// Since the path was validated before, the connection switches to it immediately.
+	// It returns an error if the last revalidation of the path failed.
+	// After switching, the path is not a backup path anymore.
+	SwitchToPath(PathID) error
+	// PathStatus returns the status of a path added by AddPath.
+	// PathInfo.Validated is false if the last revalidation of the path failed.
+	PathStatus(PathID) (PathInfo, error)
+	// RemovePath removes a path added by AddPath and closes its UDP socket.
+	RemovePath(PathID) error
+	// MigrationAllowed says if active connection migration is allowed.
+	// For the client, this is the case unless the server sent the disable_active_migration transport parameter,
+	// and it is false until the server's transport parameters have been received.
+	// For the server, this is the case unless Config.DisableActiveMigration is set.
+	MigrationAllowed() bool
+	// PeerPreferredAddress returns the preferred address that the server sent in its transport parameters
+	// (RFC 9000, section 9.6), see Config.UsePreferredAddress.
+	// It returns nil if the server didn't send a preferred address, if the transport parameters
+	// haven't been received yet, and on the server side.
+	PeerPreferredAddress() *PreferredAddress
 	// CloseWithError closes the connection with an error.
-	// The error string will be sent to the peer.
+	// The error string will be sent to the peer, where it is returned as the ErrorMessage of the ApplicationError.
+	// Error strings longer than 256 bytes are truncated. If the error string is valid UTF-8,
+	// it is truncated at a character boundary.
 	CloseWithError(ApplicationErrorCode, string) error
 	// Context returns a context that is cancelled when the connection is closed.
 	// The cancellation cause is set to the error that caused the connection to
@@ -200,6 +400,45 @@ type Connection interface {
 	// ConnectionState returns basic details about the QUIC connection.
 	// Warning: This API should not be considered stable and might change soon.
 	ConnectionState() ConnectionState
+	// Used0RTT says if the data sent in 0-RTT was accepted by the server.
+	// It only returns a meaningful value once the handshake has completed, see EarlyConnection.HandshakeComplete.
+	// If 0-RTT was rejected, all streams opened before completion of the handshake return Err0RTTRejected,
+	// and the application needs to resend the data on the connection returned by EarlyConnection.NextConnection.
+	Used0RTT() bool
+	// StatelessResetToken returns the stateless reset token associated with the connection ID currently
+	// used to send packets to the peer. The peer uses this token to signal a stateless reset.
+	// It returns false if the peer didn't provide a token for this connection ID, which is always the
+	// case for the connection ID chosen by the client during the handshake.
+	StatelessResetToken() (StatelessResetToken, bool)
+	// PeerTransportParameters returns the transport parameters sent by the peer during the handshake.
+	// It returns nil until the transport parameters were received.
+	// Transport parameters restored for a 0-RTT connection attempt are not returned.
+	// The returned struct is a copy, and modifying it has no effect on the connection.
+	PeerTransportParameters() *TransportParameters
+	// Stats returns statistics about the connection, such as the RTT and the congestion window.
+	// The values are a consistent snapshot taken at a single point in time.
+	Stats() ConnectionStats
+	// ECNStats returns the number of packets with the different ECN markings sent and received on the connection.
+	ECNStats() ECNStats
+	// BytesSent returns the total number of bytes sent on the connection, counting the UDP payload of every datagram.
+	// This includes retransmissions, acknowledgements and other control data. The counter never decreases.
+	BytesSent() uint64
+	// BytesReceived returns the total number of bytes received on the connection, counting the UDP payload of every datagram,
+	// including datagrams that were dropped because they couldn't be decrypted. The counter never decreases.
+	BytesReceived() uint64
+	// SpinBit returns the current value of the latency spin bit, i.e. the value sent on short header packets.
+	// It is always 0 unless the spin bit is enabled using Config.EnableSpinBit.
+	SpinBit() uint8
+	// EstimatedBandwidth returns the current estimate of the rate at which data is delivered to the peer,
+	// in bytes per second. The estimate is derived from the acknowledgements received in the most recent
+	// round trip, and therefore also reflects periods where the application didn't send enough data to
+	// fully utilize the path.
+	// It returns false if the congestion controller doesn't estimate the delivery rate
+	// (see DeliveryRateEstimator), or if no estimate is available yet.
+	EstimatedBandwidth() (bytesPerSecond uint64, ok bool)
+	// GetConfig returns a copy of the config used by the connection,
+	// with all unset fields populated with their default values.
+	GetConfig() *Config
 
 	// SendDatagram sends a message using a QUIC datagram, as specified in RFC 9221.
 	// There is no delivery guarantee for DATAGRAM frames, they are not retransmitted if lost.
@@ -207,8 +446,97 @@ type Connection interface {
 	// In addition, a datagram may be dropped before being sent out if the available packet size suddenly decreases.
 	// If the payload is too large to be sent at the current time, a DatagramTooLargeError is returned.
 	SendDatagram(payload []byte) error
+	// DatagramsSupported says if support for QUIC datagrams was negotiated,
+	// i.e. if both this node (via Config.EnableDatagrams) and the peer enabled the extension.
+	// Before the peer's transport parameters were received, it returns false.
+	DatagramsSupported() bool
+	// SetDatagramSendTimeout sets the maximum time a datagram is queued before being sent.
+	// Datagrams that are still queued after that time are dropped instead of being sent.
+	// The timeout only applies to datagrams queued after this call, datagrams that are already queued keep their timeout.
+	// By default, or if d is not positive, datagrams are never dropped for being queued too long.
+	SetDatagramSendTimeout(d time.Duration)
 	// ReceiveDatagram gets a message received in a datagram, as specified in RFC 9221.
 	ReceiveDatagram(context.Context) ([]byte, error)
+	// TryReceiveDatagram is like ReceiveDatagram, but it never blocks.
+	// If no datagram has been received, or if datagram support is disabled, it returns false.
+	TryReceiveDatagram() ([]byte, bool)
+	// ReceiveDatagramWithTime is like ReceiveDatagram, but additionally returns the time
+	// the packet containing the datagram was received.
+	// The receive time is only recorded if Config.RecordDatagramReceiveTime is set,
+	// otherwise the zero time.Time is returned.
+	ReceiveDatagramWithTime(context.Context) ([]byte, time.Time, error)
+	// ReceiveDatagramBuffer is like ReceiveDatagram, but returns the datagram in a DatagramBuffer,
+	// which must be released after use.
+	// If Config.EnableZeroCopyDatagrams is set, the datagram is not copied out of the packet it was received in.
+	ReceiveDatagramBuffer(context.Context) (*DatagramBuffer, error)
+	// SendLargeDatagram sends a message that might not fit into a single datagram.
+	// The message is split into up to 64 fragments, each of which is sent in a separate datagram.
+	// Every fragment is prefixed by a header consisting of three QUIC variable-length integers:
+	// the message ID, the fragment index (starting at 0) and the number of fragments of the message.
+	// Like datagrams, large datagrams are not retransmitted: if a single fragment is lost, the message is lost.
+	// The peer has to receive the messages using ReceiveLargeDatagram.
+	// Since all datagrams received by ReceiveLargeDatagram are treated as fragments,
+	// large datagrams can't be mixed with datagrams sent using SendDatagram on the same connection.
+	// If the message is too large to be sent in 64 fragments, a DatagramTooLargeError is returned,
+	// and no fragment is sent.
+	// Like SendDatagram, it blocks while the send queue is full. If datagram flow control is used and the peer
+	// doesn't grant enough credit, it might block after queueing only some of the fragments.
+	SendLargeDatagram(data []byte) error
+	// ReceiveLargeDatagram gets a message sent using SendLargeDatagram.
+	// Fragments are reassembled in a bounded buffer. Incomplete messages are dropped after one second,
+	// or when the buffer is full.
+	ReceiveLargeDatagram(context.Context) ([]byte, error)
+	// SendKeepAlive sends a PING frame with the next packet, e.g. to keep NAT bindings alive after a network change.
+	// Unlike the Config.KeepAlivePeriod, this allows the application to send event-driven keep-alives.
+	// It is safe to call at any time. It only returns an error if the connection is already closed.
+	SendKeepAlive() error
+	// Ping sends a PING frame and waits until the peer acknowledges it.
+	// It returns the time that passed between queueing the PING frame and receiving the acknowledgment,
+	// which includes the time the peer delayed the acknowledgment (unless the peer supports the ACK Frequency extension,
+	// in which case an immediate acknowledgment is requested).
+	// If the packet containing the PING frame is lost, a new PING frame is sent, and the measurement starts over.
+	// Like any other ack-eliciting packet, the PING counts towards the bytes in flight.
+	// The context can be used to stop waiting.
+	Ping(context.Context) (time.Duration, error)
+	// Flush sends out the data that is currently buffered on the connection's streams,
+	// without waiting for the pacer to release the next packet.
+	// This is useful for latency-sensitive applications, similar to TCP_NODELAY.
+	// Flush still respects the congestion controller: if the congestion window is full,
+	// the data is sent as soon as the congestion controller allows sending again.
+	// It only returns an error if the connection is already closed.
+	Flush() error
+	// RequestImmediateAck requests the peer to acknowledge the next packet immediately,
+	// by sending an IMMEDIATE_ACK frame.
+	// It returns an error if support for the ACK Frequency extension wasn't negotiated.
+	RequestImmediateAck() error
+	// RequestAckFrequency requests the peer to only send an acknowledgment after receiving more than
+	// ackElicitingThreshold ack-eliciting packets, or after maxAckDelay, by sending an ACK_FREQUENCY frame.
+	// This reduces the number of acknowledgments for bulk transfers.
+	// It returns an error if support for the ACK Frequency extension wasn't negotiated, or if
+	// maxAckDelay is smaller than the minimum ack delay advertised by the peer.
+	RequestAckFrequency(ackElicitingThreshold uint64, maxAckDelay time.Duration) error
+	// SendCustomFrame sends a frame of an application-defined frame type.
+	// The frame type must be registered using Config.CustomFrameHandlers.
+	// Custom frames are retransmitted if lost, and the entire frame needs to fit into a single QUIC packet.
+	SendCustomFrame(frameType uint64, payload []byte) error
+	// InitiateKeyUpdate initiates a key update (RFC 9001, section 6).
+	// The new keys are used starting with the next packet sent, and a PING frame is sent to make sure this happens promptly.
+	// It returns an error if the handshake is not yet confirmed, or if a packet sent after the previous key update
+	// has not been acknowledged by the peer yet.
+	InitiateKeyUpdate() error
+	// KeyPhase returns the current key phase bit, as well as the total number of key updates,
+	// including key updates initiated by the peer.
+	KeyPhase() (phase uint8, updates uint64)
+	// CurrentMTU returns the size of the largest QUIC packet that can currently be sent on the path.
+	// It starts at the InitialPacketSize, and increases when Path MTU Discovery confirms that
+	// the path supports larger packets. The size of a probe packet that is still in flight is not reflected.
+	// If Path MTU Discovery is disabled, it remains at the InitialPacketSize.
+	CurrentMTU() logging.ByteCount
+	// MaxDatagramSize returns the maximum payload size of a datagram that can currently be sent using SendDatagram.
+	// The value depends on the peer's max_datagram_frame_size transport parameter and on the current packet size,
+	// and increases when Path MTU Discovery finds that larger packets can be sent.
+	// It returns 0 if the peer doesn't support datagrams, or if the connection is closed.
+	MaxDatagramSize() int
 }
 
 // An EarlyConnection is a connection that is handshaking.
@@ -230,9 +558,30 @@ type EarlyConnection interface {
 // StatelessResetKey is a key used to derive stateless reset tokens.
 type StatelessResetKey [32]byte
 
+// A StatelessResetToken is a stateless reset token, as defined in RFC 9000 section 10.3.
+type StatelessResetToken = protocol.StatelessResetToken
+
 // TokenGeneratorKey is a key used to encrypt session resumption tokens.
 type TokenGeneratorKey = handshake.TokenProtectorKey
 
+// A RetryTokenGenerator generates and validates the address validation tokens sent in Retry packets.
+// See section 8.1.2 of RFC 9000 for details.
+type RetryTokenGenerator interface {
+	// NewRetryToken generates a token for a Retry packet sent to remoteAddr.
+	// It must be possible to recover both connection IDs from the token.
+	NewRetryToken(remoteAddr net.Addr, origDestConnID, retrySrcConnID ConnectionID) ([]byte, error)
+	// IsRetryToken says if a token was generated by NewRetryToken, e.g. by checking a prefix.
+	// Only tokens for which it returns true are passed to ValidateRetryToken.
+	// All other tokens are treated as if the Initial packet didn't contain a token, as required by
+	// section 8.1.3 of RFC 9000: this applies to tokens from NEW_TOKEN frames that are stale
+	// or were issued by a different server.
+	IsRetryToken(token []byte) bool
+	// ValidateRetryToken validates a token received on an Initial packet from remoteAddr,
+	// and returns the connection IDs that were used to generate it.
+	// It returns an error if the token is invalid, was issued for a different address or has expired.
+	ValidateRetryToken(token []byte, remoteAddr net.Addr) (origDestConnID, retrySrcConnID ConnectionID, err error)
+}
+
 // A ConnectionID is a QUIC Connection ID, as defined in RFC 9000.
 // It is not able to handle QUIC Connection IDs longer than 20 bytes,
 // as they are allowed by RFC 8999.
@@ -263,13 +612,35 @@ type ConnectionIDGenerator interface {
 }
 
 // Config contains all configuration data needed for a QUIC server or client.
+// Unless noted otherwise, the callbacks are called synchronously while the connection processes
+// packets or frames, so they must return quickly and must not block.
 type Config struct {
 	// GetConfigForClient is called for incoming connections.
 	// If the error is not nil, the connection attempt is refused.
 	GetConfigForClient func(info *ClientHelloInfo) (*Config, error)
+	// GetConfigForClientHello is called for incoming connections, once the ClientHello has been received.
+	// Unlike GetConfigForClient, the ClientHelloInfo contains the server name (SNI) and
+	// the application protocols (ALPN) offered by the client, allowing different settings per application protocol.
+	// It is called before the transport parameters are sent, so it can only change settings that take effect
+	// during the handshake: the receive windows (InitialStreamReceiveWindow, MaxStreamReceiveWindow,
+	// InitialConnectionReceiveWindow, MaxConnectionReceiveWindow), MaxIncomingStreams, MaxIncomingUniStreams,
+	// EnableDatagrams, MaxIdleTimeout and KeepAlivePeriod. All other fields of the returned Config are ignored.
+	// If the returned Config is nil, the settings are not changed.
+	// If the error is not nil, or if the returned Config is invalid, the handshake fails.
+	// If the server also uses a tls.Config.GetConfigForClient callback, it is called afterwards.
+	GetConfigForClientHello func(info *ClientHelloInfo) (*Config, error)
 	// The QUIC versions that can be negotiated.
 	// If not set, it uses all versions available.
 	Versions []Version
+	// ConnectionIDLength is the length of the connection IDs issued to the peer, between 0 and 20 bytes.
+	// It is used by ListenAddr, Listen, DialAddr and Dial (and their Early variants), which create their own Transport.
+	// When using a Transport, the Transport's ConnectionIDLength (or its ConnectionIDGenerator) determines the length,
+	// since short header packets can only be demultiplexed if all connection IDs issued by a Transport have the same
+	// length. In that case, ConnectionIDLength must either be unset, or equal the Transport's connection ID length.
+	// Load balancers that route packets based on the connection ID need to be configured with the same length.
+	// If unset, servers use 4 byte connection IDs, and clients use 0 byte connection IDs,
+	// unless they share a Transport with other connections. Servers can't use 0 byte connection IDs.
+	ConnectionIDLength int
 	// HandshakeIdleTimeout is the idle timeout before completion of the handshake.
 	// If we don't receive any packet from the peer within this time, the connection attempt is aborted.
 	// Additionally, if the handshake doesn't complete in twice this time, the connection attempt is also aborted.
@@ -281,11 +652,40 @@ type Config struct {
 	// If the timeout is exceeded, the connection is closed.
 	// If this value is zero, the timeout is set to 30 seconds.
 	MaxIdleTimeout time.Duration
+	// MaxPTOCount is the maximum number of consecutive probe timeouts (PTOs, RFC 9002, section 6.2).
+	// If no acknowledgement is received after sending probe packets MaxPTOCount times in a row,
+	// the connection is declared lost when the PTO expires the next time, and it is closed with a PTOLimitReachedError.
+	// This detects a dead path faster than the idle timeout.
+	// If this value is zero, the number of PTOs is not limited, and the connection is only closed by the idle timeout.
+	// Negative values are invalid.
+	MaxPTOCount int
+	// InitialRTT is the RTT estimate used before the first RTT sample is taken.
+	// It determines the first probe timeout (PTO), which is twice the initial RTT.
+	// On high-latency links (e.g. satellite links), setting it avoids spurious retransmissions during the handshake.
+	// If not set, it defaults to 100ms. Values must be between 1ms and 10s.
+	InitialRTT time.Duration
+	// MaxUndecryptablePackets is the maximum number of packets that are buffered during the handshake,
+	// because the keys to decrypt them are not yet available.
+	// Packets exceeding this limit are dropped.
+	// Setting a high value allows a peer to make us buffer a large number of packets for every connection.
+	// Setting a value smaller than 32 might lead to 0-RTT packets being dropped.
+	// If not set, it defaults to 32. Negative values are invalid.
+	MaxUndecryptablePackets int
 	// The TokenStore stores tokens received from the server.
 	// Tokens are used to skip address validation on future connection attempts.
 	// The key used to store tokens is the ServerName from the tls.Config, if set
 	// otherwise the token is associated with the server's IP address.
 	TokenStore TokenStore
+	// StoreNewToken is called when the client receives a token in a NEW_TOKEN frame.
+	// Together with GetStoredToken, this allows the application to control the persistence of tokens,
+	// for example to reuse them across process restarts. The application is free to discard tokens.
+	// If set, the token is not stored in the TokenStore.
+	StoreNewToken func(token []byte)
+	// GetStoredToken is called when dialing a new connection, and returns the token that is sent
+	// in the client's Initial packets, or nil if no token should be used.
+	// It is passed the address of the server.
+	// If set, the TokenStore is not consulted.
+	GetStoredToken func(remote net.Addr) []byte
 	// InitialStreamReceiveWindow is the initial size of the stream-level flow control window for receiving data.
 	// If the application is consuming data quickly enough, the flow control auto-tuning algorithm
 	// will increase the window up to MaxStreamReceiveWindow.
@@ -293,6 +693,7 @@ type Config struct {
 	// Values larger than the maximum varint (quicvarint.Max) will be clipped to that value.
 	InitialStreamReceiveWindow uint64
 	// MaxStreamReceiveWindow is the maximum stream-level flow control window for receiving data.
+	// If InitialStreamReceiveWindow is larger, it is reduced to this value.
 	// If this value is zero, it will default to 6 MB.
 	// Values larger than the maximum varint (quicvarint.Max) will be clipped to that value.
 	MaxStreamReceiveWindow uint64
@@ -303,6 +704,9 @@ type Config struct {
 	// Values larger than the maximum varint (quicvarint.Max) will be clipped to that value.
 	InitialConnectionReceiveWindow uint64
 	// MaxConnectionReceiveWindow is the connection-level flow control window for receiving data.
+	// Flow control credit is only granted up to this window, so it bounds the amount of data buffered
+	// across all streams of a connection that the application hasn't read yet (see ConnectionStats.BufferedStreamBytes).
+	// If InitialConnectionReceiveWindow is larger, it is reduced to this value.
 	// If this value is zero, it will default to 15 MB.
 	// Values larger than the maximum varint (quicvarint.Max) will be clipped to that value.
 	MaxConnectionReceiveWindow uint64
@@ -313,6 +717,13 @@ type Config struct {
 	// To avoid deadlocks, it is not valid to call other functions on the connection or on streams
 	// in this callback.
 	AllowConnectionWindowIncrease func(conn Connection, delta uint64) bool
+	// ConnectionIDUpdated is called every time the set of connection IDs issued by the peer changes,
+	// i.e. when the peer issues new connection IDs, or when connection IDs are retired.
+	// It is passed the connection IDs that were retired since the last call, as well as the set of
+	// currently active connection IDs. The first element of active is the connection ID currently in use.
+	// To avoid deadlocks, it is not valid to call other functions on the connection or on streams
+	// in this callback.
+	ConnectionIDUpdated func(retired, active []ConnectionID)
 	// MaxIncomingStreams is the maximum number of concurrent bidirectional streams that a peer is allowed to open.
 	// If not set, it will default to 100.
 	// If set to a negative value, it doesn't allow any bidirectional streams.
@@ -323,10 +734,39 @@ type Config struct {
 	// If set to a negative value, it doesn't allow any unidirectional streams.
 	// Values larger than 2^60 will be clipped to that value.
 	MaxIncomingUniStreams int64
+	// AcceptUniStream is called with the stream type of every unidirectional stream opened by the peer.
+	// The stream type is the varint at the beginning of the stream, as used by HTTP/3 (RFC 9114, section 6.2).
+	// If it returns false, the stream is rejected: STOP_SENDING (with the RejectedUniStreamErrorCode) is sent,
+	// and all data received on the stream is discarded.
+	// Streams are only returned by Connection.AcceptUniStream once the stream type was accepted.
+	// The stream type is not consumed, i.e. reading from the stream returns the stream type.
+	// If the stream is reset or ends before the stream type was received, the stream is accepted.
+	// Messages (see Connection.SendMessage) are sent on unidirectional streams, so they are checked as well.
+	AcceptUniStream func(streamType uint64) bool
+	// RejectedUniStreamErrorCode is the error code sent in the STOP_SENDING frame for streams rejected by AcceptUniStream.
+	// HTTP/3 uses H3_STREAM_CREATION_ERROR (0x103). If not set, it defaults to 0.
+	RejectedUniStreamErrorCode StreamErrorCode
+	// MaxMessageSize is the maximum size of a message received using Connection.AcceptMessage.
+	// If not set, it will default to 1 MB. Values larger than 2^62-1 are reduced to 2^62-1.
+	MaxMessageSize uint64
 	// KeepAlivePeriod defines whether this peer will periodically send a packet to keep the connection alive.
 	// If set to 0, then no keep alive is sent. Otherwise, the keep alive is sent on that period (or at most
 	// every half of MaxIdleTimeout, whichever is smaller).
 	KeepAlivePeriod time.Duration
+	// KeepAliveJitter randomizes the keep-alive period of every connection, such that keep-alives of
+	// connections established at the same time don't synchronize.
+	// The keep-alive period is reduced by a random duration of up to KeepAliveJitter, chosen once per connection,
+	// so the jitter never pushes the keep-alive past the idle timeout.
+	// It is capped to half of the keep-alive period.
+	// Negative values are invalid.
+	KeepAliveJitter time.Duration
+	// MaxAckDelay is the maximum time by which this peer delays sending acknowledgments.
+	// Lower values reduce latency of loss recovery, higher values reduce the number of ACK frames sent.
+	// It is advertised to the peer in the max_ack_delay transport parameter (including the timer granularity of 1ms).
+	// If not set, it will default to 25ms.
+	// If set to a negative value, acknowledgments are not delayed.
+	// Values larger than 16382ms will be clipped to that value.
+	MaxAckDelay time.Duration
 	// InitialPacketSize is the initial size of packets sent.
 	// It is usually not necessary to manually set this value,
 	// since Path MTU discovery very quickly finds the path's MTU.
@@ -336,13 +776,232 @@ type Config struct {
 	// DisablePathMTUDiscovery disables Path MTU Discovery (RFC 8899).
 	// This allows the sending of QUIC packets that fully utilize the available MTU of the path.
 	// Path MTU discovery is only available on systems that allow setting of the Don't Fragment (DF) bit.
+	// When disabled, no probe packets are sent, and packets are never larger than the InitialPacketSize.
+	// Together with InitialPacketSize, this allows pinning a conservative packet size on paths with broken ICMP.
 	DisablePathMTUDiscovery bool
+	// DisableActiveMigration makes the server send the disable_active_migration transport parameter,
+	// which forbids the client from migrating the connection to a new path (RFC 9000, section 9).
+	// Unless set, the server switches to the client's new address when the client migrates,
+	// or when its address changes due to a NAT rebinding, once the new address was validated.
+	// It doesn't apply to the migration to the PreferredAddress.
+	// Only valid for the server.
+	DisableActiveMigration bool
+	// PreferredAddress is the address that the server asks clients to migrate to after the handshake
+	// (RFC 9000, section 9.6). It is sent in the preferred_address transport parameter, together with a
+	// new connection ID. The server needs to be reachable on this address using the same Transport,
+	// for example by listening on the unspecified address.
+	// Clients migrate even if active migration is disabled using DisableActiveMigration,
+	// since that only applies to the address used during the handshake (RFC 9000, section 18.2).
+	// This is the only case where DisableActiveMigration doesn't prevent a migration.
+	// The server answers PATH_CHALLENGEs on the path they were received on, and switches to the preferred address
+	// once the client sends non-probing packets to it, and the new path was validated (RFC 9000, section 9.6.3).
+	// Only valid for the server. It is not sent if the Transport uses zero-length connection IDs.
+	PreferredAddress *PreferredAddress
+	// UsePreferredAddress makes the client migrate to the server's preferred address,
+	// once the handshake is confirmed (see Connection.PeerPreferredAddress).
+	// An address of the same IP version as the current remote address is preferred.
+	// If migration fails, the connection continues to use the current path.
+	// Only valid for the client.
+	UsePreferredAddress bool
+	// DisableGSO disables the use of Generic Segmentation Offload (GSO) when sending packets.
+	// GSO is only available on Linux. When disabled, every packet is sent using a separate syscall.
+	// This can be used as a workaround for kernels and network drivers with broken GSO support.
+	DisableGSO bool
+	// DisablePacing disables packet pacing.
+	// Packets are then sent out as fast as the congestion controller allows, i.e. bursts are only
+	// limited by the congestion window and the number of bytes in flight.
+	// This is useful for throughput benchmarks and on local networks, but can cause packet loss on
+	// paths with small buffers.
+	DisablePacing bool
+	// DisableECN disables Explicit Congestion Notification (RFC 9000, section 13.4).
+	// When disabled, packets are sent without an ECN marking, and ECN counts aren't reported to the peer.
+	// This can be used on networks that mangle or mismark the ECN bits.
+	// Received ECN markings are still counted in the connection's ECNStats.
+	DisableECN bool
 	// Allow0RTT allows the application to decide if a 0-RTT connection attempt should be accepted.
 	// Only valid for the server.
 	Allow0RTT bool
+	// MaxHandshakeRate limits the number of handshakes the server starts per second.
+	// This mitigates handshake floods, since every handshake requires expensive cryptographic operations.
+	// Bursts of up to MaxHandshakeRate handshakes are allowed.
+	// Connection attempts exceeding the rate are handled according to the HandshakeOverflowPolicy.
+	// If not set, the handshake rate is not limited. Negative values are invalid.
+	// Only valid for the server. It is not applied to the Config returned by GetConfigForClient.
+	MaxHandshakeRate int
+	// HandshakeOverflowPolicy determines how connection attempts exceeding the MaxHandshakeRate are handled.
+	// If not set, it defaults to HandshakeOverflowDrop.
+	HandshakeOverflowPolicy HandshakeOverflowPolicy
+	// CongestionControlFactory creates the congestion controller for a new connection.
+	// The RTTStats are updated by the connection, and can be used by the congestion controller.
+	// If not set, NewReno is used.
+	CongestionControlFactory func(rttStats *logging.RTTStats, initialMaxDatagramSize logging.ByteCount) CongestionControl
+	// InitialCongestionWindow is the initial congestion window, in packets.
+	// On paths with a large bandwidth-delay product, a larger initial window saves round trips in slow start.
+	// However, it allows sending a large burst of packets before the path capacity is known,
+	// which can cause packet loss for this and other flows sharing the path, and it increases the amount of data
+	// an attacker can elicit towards a spoofed address once the address is validated.
+	// If not set, it will default to 32.
+	// Values larger than 10000 will be clipped to that value.
+	// It has no effect if a CongestionControlFactory is set.
+	InitialCongestionWindow uint32
+	// MinCongestionWindow is the minimum congestion window, in bytes.
+	// The congestion window is never reduced below this value, not even after packet loss.
+	// This keeps up the throughput on lossy links, at the cost of reacting less to congestion.
+	// If not set, or if smaller than 2 packets, the minimum congestion window is 2 packets.
+	// It has no effect if a CongestionControlFactory is set.
+	MinCongestionWindow uint64
+	// MaxCongestionWindow is the maximum congestion window, in bytes.
+	// This prevents a single connection from using all the capacity of a shared link.
+	// If not set, it will default to 10000 packets.
+	// It must not be smaller than MinCongestionWindow.
+	// It has no effect if a CongestionControlFactory is set.
+	MaxCongestionWindow uint64
 	// Enable QUIC datagram support (RFC 9221).
+	// Received datagrams are never truncated: as required by RFC 9221, the connection is closed with a
+	// PROTOCOL_VIOLATION if the peer sends a DATAGRAM frame larger than the advertised max_datagram_frame_size,
+	// or if it sends a DATAGRAM frame although datagram support wasn't negotiated.
 	EnableDatagrams bool
-	Tracer          func(context.Context, logging.Perspective, ConnectionID) *logging.ConnectionTracer
+	// IdleTimeoutIgnoresDatagrams makes the idle timeout ignore DATAGRAM frames.
+	// Sending and receiving packets that only contain DATAGRAM frames (and acknowledgments for them)
+	// then doesn't restart the idle timer, so a connection that only sees DATAGRAM traffic still times out
+	// after MaxIdleTimeout. This is useful if DATAGRAMs are best-effort and shouldn't keep the connection alive.
+	IdleTimeoutIgnoresDatagrams bool
+	// Enable support for reliable stream resets (RESET_STREAM_AT frames).
+	// If the peer also enables it, SendStream.ResetAt guarantees delivery of stream data up to the reliable offset.
+	EnableResetStreamAt bool
+	// Enable support for the ACK Frequency extension (ACK_FREQUENCY and IMMEDIATE_ACK frames).
+	// If the peer also enables it, it can control how often we send acknowledgments, and vice versa,
+	// see Connection.RequestAckFrequency and Connection.RequestImmediateAck.
+	EnableAckFrequency bool
+	// EnableQUICBitGreasing enables greasing of the QUIC bit (RFC 9287).
+	// The grease_quic_bit transport parameter is sent, and packets with the QUIC bit set to 0 are accepted.
+	// If the peer also enables it, the QUIC bit is randomized on short header packets we send.
+	// Packets with an unset QUIC bit are routed by their connection ID, so the grease_quic_bit transport parameter
+	// is not sent if zero-length connection IDs are used.
+	// This should not be used when multiplexing QUIC with other protocols on the same socket
+	// (see Transport.ReadNonQUICPacket), since packets can't be distinguished by the QUIC bit anymore.
+	EnableQUICBitGreasing bool
+	// EnableSpinBit enables the latency spin bit (RFC 9000, section 17.4).
+	// The spin bit allows passive on-path observers to measure the RTT of the connection.
+	// For privacy reasons, it is disabled by default, and the spin bit is always set to 0.
+	EnableSpinBit bool
+	// CustomFrameHandlers enables sending and receiving of frames of application-defined frame types.
+	// This is meant for experimenting with QUIC extensions, and must only be used if the peer registers the same
+	// frame types: receiving a frame of an unknown type is a protocol violation.
+	// The handler is called with the payload of every received frame of the respective type.
+	// Frame types defined in RFC 9000, or used by extensions implemented by quic-go, can't be registered.
+	// Custom frames are sent using Connection.SendCustomFrame.
+	CustomFrameHandlers map[uint64]func(conn Connection, payload []byte)
+	// DatagramReceiveQueueLen is the maximum number of received datagrams that are queued
+	// until they are read by the application. Datagrams received while the queue is full are dropped.
+	// Since every queued datagram can be as large as the packet it was received in,
+	// this bounds the memory used for buffering datagrams per connection.
+	// If not set, it will default to 128.
+	// Negative values are invalid.
+	DatagramReceiveQueueLen int
+	// RecordDatagramReceiveTime records the receive time of every received datagram,
+	// so that it can be retrieved using Connection.ReceiveDatagramWithTime.
+	RecordDatagramReceiveTime bool
+	// EnableZeroCopyDatagrams avoids copying received datagrams out of the packet they were received in.
+	// The datagrams need to be received using Connection.ReceiveDatagramBuffer, and the buffer needs to be
+	// released after use. Until then, the memory of the entire packet is retained, so applications should
+	// release the buffer as soon as possible.
+	// Datagrams received using ReceiveDatagram are copied, as if this option wasn't set.
+	EnableZeroCopyDatagrams bool
+	// EnableExperimentalDatagramFlowControl enables an experimental extension for datagram flow control.
+	// The extension is not standardized, and its frame type and transport parameter are not registered with IANA.
+	// It is only used if the peer is also a quic-go endpoint that enables it. Otherwise, datagrams are sent
+	// and received without flow control.
+	// If used, the peer is only allowed to send as many datagrams as fit into our receive queue
+	// (see DatagramReceiveQueueLen), and datagrams exceeding that limit are dropped.
+	// Receiving datagrams from the queue grants the peer credit to send more.
+	// Vice versa, once the peer's application hasn't consumed enough datagrams, sent datagrams stay queued,
+	// and SendDatagram blocks when the send queue is full. This trades the best-effort semantics of datagrams
+	// for backpressure.
+	// It can only be used if EnableDatagrams is set.
+	EnableExperimentalDatagramFlowControl bool
+	// RequireDatagrams makes the handshake fail if the peer doesn't support QUIC datagrams.
+	// The connection is closed with a CONNECTION_REFUSED error as soon as the peer's transport parameters are received.
+	// It can only be used if EnableDatagrams is set.
+	RequireDatagrams bool
+	// Clock is the source of time used by the connection, e.g. for loss detection, timeouts and pacing,
+	// as well as for the expiry of queued datagrams, stream deadlines, and the age of address validation tokens.
+	// If set, the receive time of packets is also taken from this clock.
+	// The age of tokens is evaluated using the Clock of the Config passed to Listen.
+	// The Clock must be safe for concurrent use: Now is called from the connection's run loop,
+	// from the Transport's goroutine when packets are received, and from the application's goroutines
+	// when a stream deadline is evaluated.
+	// This is mostly useful for deterministic testing. If not set, the wall clock is used.
+	Clock  Clock
+	Tracer func(context.Context, logging.Perspective, ConnectionID) *logging.ConnectionTracer
+	// OnPacketSent is called for every QUIC packet sent on the connection, with the encryption level,
+	// the packet number, the size of the packet and the time it was sent.
+	// Packets coalesced into a single UDP datagram are reported individually.
+	// OnPacketReceived is called for every QUIC packet that was successfully decrypted,
+	// with the time it was received. Duplicate packets are not reported.
+	// They are a lightweight alternative to the Tracer, meant to be used for always-on sampling of packet timings.
+	OnPacketSent     func(encLevel logging.EncryptionLevel, pn logging.PacketNumber, size logging.ByteCount, sentTime time.Time)
+	OnPacketReceived func(encLevel logging.EncryptionLevel, pn logging.PacketNumber, size logging.ByteCount, rcvTime time.Time)
+	// OnFlowControlBlocked is called when sending is blocked by flow control,
+	// i.e. when a STREAM_DATA_BLOCKED or a DATA_BLOCKED frame is queued.
+	// The stream ID is nil if the connection-level flow control window is exhausted.
+	// It is called at most once for every flow control window the peer grants.
+	// It is called from the connection's run loop without holding any of the stream's locks,
+	// so it is safe to call the stream's methods, but it must not block.
+	OnFlowControlBlocked func(streamID *StreamID)
+	// OnVersionNegotiated is called once the QUIC version of a connection is established,
+	// i.e. when the first packet from the peer is processed.
+	// On the client side, this happens after version negotiation (if any) completed.
+	// The client selects the first version in Versions that is also supported by the server.
+	// The negotiated version is also available from Connection.ConnectionState.
+	OnVersionNegotiated func(Version)
+}
+
+// HandshakeOverflowPolicy determines how connection attempts exceeding Config.MaxHandshakeRate are handled.
+type HandshakeOverflowPolicy uint8
+
+const (
+	// HandshakeOverflowDrop drops the Initial packets of connection attempts exceeding the handshake rate.
+	HandshakeOverflowDrop HandshakeOverflowPolicy = iota
+	// HandshakeOverflowRetry sends a Retry packet in response to connection attempts exceeding the handshake rate,
+	// forcing the client to validate its address (RFC 9000, section 8.1.2).
+	// Connection attempts from validated addresses are accepted, even if they exceed the handshake rate.
+	HandshakeOverflowRetry
+)
+
+// CongestionControl is a congestion controller.
+// All methods are called from the connection's run loop, so implementations don't need to be safe for concurrent use.
+// Warning: This API should not be considered stable and might change soon.
+type CongestionControl = congestion.SendAlgorithmWithDebugInfos
+
+// A DeliveryRateEstimator is a CongestionControl that estimates the delivery rate.
+// If the CongestionControl returned from Config.CongestionControlFactory implements this interface,
+// its estimate is returned from Connection.EstimatedBandwidth.
+// Warning: This API should not be considered stable and might change soon.
+type DeliveryRateEstimator = congestion.DeliveryRateEstimator
+
+// A Clock is a source of time, see Config.Clock.
+// Warning: This API should not be considered stable and might change soon.
+type Clock = utils.Clock
+
+// A ClockTimer is a timer created by a Clock.
+// It behaves like a time.Timer.
+type ClockTimer = utils.ClockTimer
+
+// PreferredAddress is a server's preferred address (RFC 9000, section 9.6).
+// A server can advertise an IPv4 address, an IPv6 address, or both.
+// Addresses that are not set (or set to the unspecified address) are not advertised.
+type PreferredAddress struct {
+	IPv4 netip.AddrPort
+	IPv6 netip.AddrPort
+}
+
+func (a *PreferredAddress) hasIPv4() bool {
+	return a.IPv4.IsValid() && !a.IPv4.Addr().IsUnspecified() && a.IPv4.Port() != 0
+}
+
+func (a *PreferredAddress) hasIPv6() bool {
+	return a.IPv6.IsValid() && !a.IPv6.Addr().IsUnspecified() && a.IPv6.Port() != 0
 }
 
 // ClientHelloInfo contains information about an incoming connection attempt.
@@ -354,6 +1013,12 @@ type ClientHelloInfo struct {
 	// Note that the Retry mechanism costs one network roundtrip,
 	// and is not performed unless Transport.MaxUnvalidatedHandshakes is surpassed.
 	AddrVerified bool
+	// ServerName is the server name (SNI) requested by the client.
+	// It is only set for Config.GetConfigForClientHello.
+	ServerName string
+	// SupportedProtos are the application protocols (ALPN) offered by the client.
+	// It is only set for Config.GetConfigForClientHello.
+	SupportedProtos []string
 }
 
 // ConnectionState records basic details about a QUIC connection
@@ -365,10 +1030,117 @@ type ConnectionState struct {
 	// If datagram support was negotiated, datagrams can be sent and received using the
 	// SendDatagram and ReceiveDatagram methods on the Connection.
 	SupportsDatagrams bool
+	// SupportsResetStreamAt says if support for reliable stream resets was negotiated.
+	// This requires both nodes to enable the extension (via Config.EnableResetStreamAt).
+	SupportsResetStreamAt bool
+	// SupportsAckFrequency says if support for the ACK Frequency extension was negotiated.
+	// This requires both nodes to enable the extension (via Config.EnableAckFrequency).
+	SupportsAckFrequency bool
+	// SupportsQUICBitGreasing says if greasing of the QUIC bit (RFC 9287) was negotiated.
+	// This requires both nodes to enable it (via Config.EnableQUICBitGreasing).
+	// If negotiated, the QUIC bit is randomized on short header packets.
+	SupportsQUICBitGreasing bool
 	// Used0RTT says if 0-RTT resumption was used.
 	Used0RTT bool
+	// HandshakeDuration is the time it took to complete the handshake, measured from the start of the connection.
+	// It is 0 until the handshake completes.
+	HandshakeDuration time.Duration
+	// KeyExchangeGroup is the key exchange group used in the handshake.
+	// The negotiated cipher suite is available in TLS.CipherSuite.
+	// The key exchange group is only available when built with Go 1.25 or newer.
+	KeyExchangeGroup tls.CurveID
+	// HelloRetryRequest says if the server sent a HelloRetryRequest during the handshake,
+	// for example because the client didn't send a key share for a key exchange group supported by the server.
+	// This is only available when built with Go 1.26 or newer.
+	HelloRetryRequest bool
 	// Version is the QUIC version of the QUIC connection.
 	Version Version
 	// GSO says if generic segmentation offload is used
 	GSO bool
 }
+
+// ConnectionStats contains statistics about the path used by a QUIC connection.
+type ConnectionStats struct {
+	// MinRTT is the minimum RTT observed on the connection.
+	MinRTT time.Duration
+	// LatestRTT is the last RTT sample.
+	LatestRTT time.Duration
+	// SmoothedRTT is the exponentially weighted moving average of the RTT samples (RFC 9002, section 5.3).
+	SmoothedRTT time.Duration
+	// RTTVariance is the mean deviation of the RTT samples (rttvar in RFC 9002, section 5.3).
+	RTTVariance time.Duration
+	// CongestionWindow is the current congestion window, in bytes.
+	CongestionWindow uint64
+	// BytesInFlight is the number of bytes sent in packets that were not yet acknowledged or declared lost.
+	BytesInFlight uint64
+	// PacketsLost is the number of packets that were declared lost.
+	PacketsLost uint64
+	// InitialPacketsRetransmitted is the number of Initial packets that were retransmitted,
+	// either because they were declared lost or because a PTO fired.
+	InitialPacketsRetransmitted uint64
+	// HandshakePacketsRetransmitted is the number of Handshake packets that were retransmitted,
+	// either because they were declared lost or because a PTO fired.
+	HandshakePacketsRetransmitted uint64
+	// ReorderedPackets is the number of 0-RTT and 1-RTT packets that were received after a packet with a higher packet number.
+	// Together with PacketsLost, this helps distinguish packet loss from reordering on the path.
+	ReorderedPackets uint64
+	// DuplicatePackets is the number of received packets that were dropped because they were (potentially) duplicates.
+	// This includes packets that are too old to be tracked for acknowledgement.
+	DuplicatePackets uint64
+	// BufferedStreamBytes is the number of bytes received on streams that the application hasn't read yet.
+	// It never exceeds the connection-level flow control window, which is limited by MaxConnectionReceiveWindow.
+	BufferedStreamBytes uint64
+}
+
+// TransportParameters are the transport parameters sent by the peer (RFC 9000, section 18.2).
+// Parameters that the peer didn't send are set to the default value defined by the RFC.
+type TransportParameters struct {
+	MaxIdleTimeout                 time.Duration
+	MaxUDPPayloadSize              uint64
+	InitialMaxData                 uint64
+	InitialMaxStreamDataBidiLocal  uint64
+	InitialMaxStreamDataBidiRemote uint64
+	InitialMaxStreamDataUni        uint64
+	InitialMaxStreamsBidi          int64
+	InitialMaxStreamsUni           int64
+	AckDelayExponent               uint8
+	MaxAckDelay                    time.Duration
+	DisableActiveMigration         bool
+	ActiveConnectionIDLimit        uint64
+	// MaxDatagramFrameSize is 0 if the peer doesn't support QUIC datagrams (RFC 9221).
+	MaxDatagramFrameSize uint64
+	// MinAckDelay is 0 if the peer doesn't support the ACK Frequency extension.
+	MinAckDelay time.Duration
+	// EnableResetStreamAt says if the peer supports reliable stream resets.
+	EnableResetStreamAt bool
+	// GreaseQUICBit says if the peer supports greasing of the QUIC bit (RFC 9287).
+	GreaseQUICBit bool
+}
+
+// ECNStats contains the number of packets sent and received with the different ECN markings (RFC 3168).
+type ECNStats struct {
+	// SentECT0 and SentECT1 are the number of packets sent with the ECT(0) and ECT(1) codepoint.
+	// Packets are only marked if ECN is supported by the platform and not disabled by Config.DisableECN,
+	// and only as long as ECN validation of the path succeeds.
+	SentECT0, SentECT1 uint64
+	// AckedECT0, AckedECT1 and AckedCE are the ECN counts for the 1-RTT packets sent,
+	// as reported by the peer in its most recent ACK frame.
+	// AckedCE is the number of packets that were marked CE (Congestion Experienced) on the way to the peer.
+	AckedECT0, AckedECT1, AckedCE uint64
+	// ReceivedECT0, ReceivedECT1 and ReceivedCE are the number of packets received with the respective codepoint.
+	ReceivedECT0, ReceivedECT1, ReceivedCE uint64
+}
+
+// A PathID identifies a path added by Connection.AddPath.
+// It is only used locally, and is unrelated to the path IDs of the QUIC multipath extension.
+type PathID uint64
+
+// PathInfo is the result of probing a path using Connection.ProbePath,
+// or the status of a path added by Connection.AddPath.
+type PathInfo struct {
+	// Validated says if the peer responded to the PATH_CHALLENGE sent on the path.
+	Validated bool
+	// RTT is the round-trip time measured from sending a PATH_CHALLENGE until receiving the peer's PATH_RESPONSE.
+	// It is only set if the path was validated.
+	RTT time.Duration
+}
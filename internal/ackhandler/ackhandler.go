@@ -1,6 +1,9 @@
 package ackhandler
 
 import (
+	"time"
+
+	"github.com/quic-go/quic-go/internal/congestion"
 	"github.com/quic-go/quic-go/internal/protocol"
 	"github.com/quic-go/quic-go/internal/utils"
 	"github.com/quic-go/quic-go/logging"
@@ -9,16 +12,23 @@ import (
 // NewAckHandler creates a new SentPacketHandler and a new ReceivedPacketHandler.
 // clientAddressValidated indicates whether the address was validated beforehand by an address validation token.
 // clientAddressValidated has no effect for a client.
+// maxAckDelay is the maximum time by which the sending of ACKs for 1-RTT packets is delayed.
+// If congestionControl is nil, the default congestion controller is used.
+// If disablePacing is set, packets are sent as fast as the congestion window allows.
 func NewAckHandler(
 	initialPacketNumber protocol.PacketNumber,
 	initialMaxDatagramSize protocol.ByteCount,
 	rttStats *utils.RTTStats,
+	congestionControl congestion.SendAlgorithmWithDebugInfos,
+	maxAckDelay time.Duration,
 	clientAddressValidated bool,
 	enableECN bool,
+	disablePacing bool,
 	pers protocol.Perspective,
 	tracer *logging.ConnectionTracer,
+	clock utils.Clock,
 	logger utils.Logger,
 ) (SentPacketHandler, ReceivedPacketHandler) {
-	sph := newSentPacketHandler(initialPacketNumber, initialMaxDatagramSize, rttStats, clientAddressValidated, enableECN, pers, tracer, logger)
-	return sph, newReceivedPacketHandler(sph, logger)
+	sph := newSentPacketHandler(initialPacketNumber, initialMaxDatagramSize, rttStats, congestionControl, clientAddressValidated, enableECN, disablePacing, pers, tracer, clock, logger)
+	return sph, newReceivedPacketHandler(sph, maxAckDelay, clock, logger)
 }
@@ -25,6 +25,9 @@ type SentPacketHandler interface {
 	// It is used for pacing packets.
 	TimeUntilSend() time.Time
 	SetMaxDatagramSize(count protocol.ByteCount)
+	// MigratedPath is called when the connection switches to a new path.
+	// It resets the RTT estimate and the congestion controller.
+	MigratedPath()
 
 	// only to be called once the handshake is complete
 	QueueProbePacket(protocol.EncryptionLevel) bool /* was a packet queued */
@@ -35,6 +38,40 @@ type SentPacketHandler interface {
 
 	GetLossDetectionTimeout() time.Time
 	OnLossDetectionTimeout() error
+
+	// Stats returns a consistent snapshot of the RTT and congestion control statistics.
+	// It is safe to call from any goroutine.
+	Stats() Stats
+}
+
+// Stats are the RTT and congestion control statistics of a connection.
+type Stats struct {
+	MinRTT        time.Duration
+	LatestRTT     time.Duration
+	SmoothedRTT   time.Duration
+	MeanDeviation time.Duration
+
+	CongestionWindow protocol.ByteCount
+	BytesInFlight    protocol.ByteCount
+	PacketsLost      uint64
+	// PTOCount is the number of consecutive PTOs that fired without an acknowledgement being received.
+	PTOCount uint32
+	// InitialPacketsRetransmitted is the number of Initial packets
+	// that were declared lost or whose frames were retransmitted in a PTO probe packet.
+	InitialPacketsRetransmitted uint64
+	// HandshakePacketsRetransmitted is the number of Handshake packets
+	// that were declared lost or whose frames were retransmitted in a PTO probe packet.
+	HandshakePacketsRetransmitted uint64
+
+	// DeliveryRate is the delivery rate estimated by the congestion controller, in bytes/s.
+	// It is only valid if HasDeliveryRate is set.
+	DeliveryRate    uint64
+	HasDeliveryRate bool
+
+	// SentECT0 and SentECT1 are the number of packets sent with the respective ECN marking.
+	SentECT0, SentECT1 uint64
+	// AckedECT0, AckedECT1 and AckedECNCE are the ECN counts reported in the most recent 1-RTT ACK frame.
+	AckedECT0, AckedECT1, AckedECNCE uint64
 }
 
 type sentPacketTracker interface {
@@ -47,7 +84,22 @@ type ReceivedPacketHandler interface {
 	IsPotentiallyDuplicate(protocol.PacketNumber, protocol.EncryptionLevel) bool
 	ReceivedPacket(pn protocol.PacketNumber, ecn protocol.ECN, encLevel protocol.EncryptionLevel, rcvTime time.Time, ackEliciting bool) error
 	DropPackets(protocol.EncryptionLevel)
+	ReceivedAckFrequencyFrame(*wire.AckFrequencyFrame)
+	ReceivedImmediateAck()
 
 	GetAlarmTimeout() time.Time
 	GetAckFrame(encLevel protocol.EncryptionLevel, onlyIfQueued bool) *wire.AckFrame
+
+	// Stats returns statistics about the received packets.
+	// It is safe to call from any goroutine.
+	Stats() ReceivedStats
+}
+
+// ReceivedStats are statistics about the packets received on a connection.
+type ReceivedStats struct {
+	// ReorderedPackets is the number of application data packets that were received
+	// after a packet with a higher packet number.
+	ReorderedPackets uint64
+	// DuplicatePackets is the number of packets that were dropped as (potential) duplicates.
+	DuplicatePackets uint64
 }
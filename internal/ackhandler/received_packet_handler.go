@@ -2,6 +2,7 @@ package ackhandler
 
 import (
 	"fmt"
+	"sync/atomic"
 	"time"
 
 	"github.com/quic-go/quic-go/internal/protocol"
@@ -17,16 +18,20 @@ type receivedPacketHandler struct {
 	appDataPackets   appDataReceivedPacketTracker
 
 	lowest1RTTPacket protocol.PacketNumber
+
+	// accessed atomically, since Stats can be called from any goroutine
+	reorderedPackets atomic.Uint64
+	duplicatePackets atomic.Uint64
 }
 
 var _ ReceivedPacketHandler = &receivedPacketHandler{}
 
-func newReceivedPacketHandler(sentPackets sentPacketTracker, logger utils.Logger) ReceivedPacketHandler {
+func newReceivedPacketHandler(sentPackets sentPacketTracker, maxAckDelay time.Duration, clock utils.Clock, logger utils.Logger) ReceivedPacketHandler {
 	return &receivedPacketHandler{
 		sentPackets:      sentPackets,
 		initialPackets:   newReceivedPacketTracker(),
 		handshakePackets: newReceivedPacketTracker(),
-		appDataPackets:   *newAppDataReceivedPacketTracker(logger),
+		appDataPackets:   *newAppDataReceivedPacketTracker(maxAckDelay, clock, logger),
 		lowest1RTTPacket: protocol.InvalidPacketNumber,
 	}
 }
@@ -53,11 +58,13 @@ func (h *receivedPacketHandler) ReceivedPacket(
 		if h.lowest1RTTPacket != protocol.InvalidPacketNumber && pn > h.lowest1RTTPacket {
 			return fmt.Errorf("received packet number %d on a 0-RTT packet after receiving %d on a 1-RTT packet", pn, h.lowest1RTTPacket)
 		}
+		h.countReordered(pn)
 		return h.appDataPackets.ReceivedPacket(pn, ecn, rcvTime, ackEliciting)
 	case protocol.Encryption1RTT:
 		if h.lowest1RTTPacket == protocol.InvalidPacketNumber || pn < h.lowest1RTTPacket {
 			h.lowest1RTTPacket = pn
 		}
+		h.countReordered(pn)
 		if err := h.appDataPackets.ReceivedPacket(pn, ecn, rcvTime, ackEliciting); err != nil {
 			return err
 		}
@@ -68,6 +75,13 @@ func (h *receivedPacketHandler) ReceivedPacket(
 	}
 }
 
+// countReordered counts application data packets that arrive after a packet with a higher packet number.
+func (h *receivedPacketHandler) countReordered(pn protocol.PacketNumber) {
+	if pn < h.appDataPackets.largestObserved {
+		h.reorderedPackets.Add(1)
+	}
+}
+
 func (h *receivedPacketHandler) DropPackets(encLevel protocol.EncryptionLevel) {
 	//nolint:exhaustive // 1-RTT packet number space is never dropped.
 	switch encLevel {
@@ -83,6 +97,14 @@ func (h *receivedPacketHandler) DropPackets(encLevel protocol.EncryptionLevel) {
 	}
 }
 
+func (h *receivedPacketHandler) ReceivedAckFrequencyFrame(f *wire.AckFrequencyFrame) {
+	h.appDataPackets.ReceivedAckFrequencyFrame(f)
+}
+
+func (h *receivedPacketHandler) ReceivedImmediateAck() {
+	h.appDataPackets.ReceivedImmediateAck()
+}
+
 func (h *receivedPacketHandler) GetAlarmTimeout() time.Time {
 	return h.appDataPackets.GetAlarmTimeout()
 }
@@ -108,7 +130,17 @@ func (h *receivedPacketHandler) GetAckFrame(encLevel protocol.EncryptionLevel, o
 	}
 }
 
+// IsPotentiallyDuplicate says if a packet might be a duplicate.
+// Such packets are dropped, and counted as duplicates.
 func (h *receivedPacketHandler) IsPotentiallyDuplicate(pn protocol.PacketNumber, encLevel protocol.EncryptionLevel) bool {
+	if h.isPotentiallyDuplicate(pn, encLevel) {
+		h.duplicatePackets.Add(1)
+		return true
+	}
+	return false
+}
+
+func (h *receivedPacketHandler) isPotentiallyDuplicate(pn protocol.PacketNumber, encLevel protocol.EncryptionLevel) bool {
 	switch encLevel {
 	case protocol.EncryptionInitial:
 		if h.initialPackets != nil {
@@ -123,3 +155,10 @@ func (h *receivedPacketHandler) IsPotentiallyDuplicate(pn protocol.PacketNumber,
 	}
 	panic("unexpected encryption level")
 }
+
+func (h *receivedPacketHandler) Stats() ReceivedStats {
+	return ReceivedStats{
+		ReorderedPackets: h.reorderedPackets.Load(),
+		DuplicatePackets: h.duplicatePackets.Load(),
+	}
+}
@@ -18,7 +18,7 @@ var _ = Describe("Received Packet Handler", func() {
 
 	BeforeEach(func() {
 		sentPackets = NewMockSentPacketTracker(mockCtrl)
-		handler = newReceivedPacketHandler(sentPackets, utils.DefaultLogger)
+		handler = newReceivedPacketHandler(sentPackets, protocol.MaxAckDelay, utils.DefaultClock{}, utils.DefaultLogger)
 	})
 
 	It("generates ACKs for different packet number spaces", func() {
@@ -158,5 +158,26 @@ var _ = Describe("Received Packet Handler", func() {
 		Expect(handler.IsPotentiallyDuplicate(4, protocol.Encryption1RTT)).To(BeFalse())
 		Expect(handler.ReceivedPacket(4, protocol.ECNNon, protocol.Encryption1RTT, sendTime, true)).To(Succeed())
 		Expect(handler.IsPotentiallyDuplicate(4, protocol.Encryption1RTT)).To(BeTrue())
+		Expect(handler.Stats().DuplicatePackets).To(BeEquivalentTo(5))
+	})
+
+	It("counts reordered packets", func() {
+		sendTime := time.Now()
+		sentPackets.EXPECT().ReceivedPacket(gomock.Any()).AnyTimes()
+		sentPackets.EXPECT().GetLowestPacketNotConfirmedAcked().AnyTimes()
+		// reordering in the Initial packet number space is not counted
+		Expect(handler.ReceivedPacket(5, protocol.ECNNon, protocol.EncryptionInitial, sendTime, true)).To(Succeed())
+		Expect(handler.ReceivedPacket(4, protocol.ECNNon, protocol.EncryptionInitial, sendTime, true)).To(Succeed())
+		for _, pn := range []protocol.PacketNumber{0, 1, 4, 2, 5, 3, 6} {
+			Expect(handler.IsPotentiallyDuplicate(pn, protocol.Encryption1RTT)).To(BeFalse())
+			Expect(handler.ReceivedPacket(pn, protocol.ECNNon, protocol.Encryption1RTT, sendTime, true)).To(Succeed())
+		}
+		// duplicates are dropped, and therefore not counted as reordered
+		Expect(handler.IsPotentiallyDuplicate(2, protocol.Encryption1RTT)).To(BeTrue())
+		Expect(handler.Stats()).To(Equal(ReceivedStats{ReorderedPackets: 2, DuplicatePackets: 1}))
+		// the ACK frame reports all packets
+		ack := handler.GetAckFrame(protocol.Encryption1RTT, false)
+		Expect(ack).ToNot(BeNil())
+		Expect(ack.AckRanges).To(Equal([]wire.AckRange{{Smallest: 0, Largest: 6}}))
 	})
 })
@@ -75,6 +75,7 @@ const packetsBeforeAck = 2
 
 // The appDataReceivedPacketTracker tracks packets received in the Application Data packet number space.
 // It waits until at least 2 packets were received before queueing an ACK, or until the max_ack_delay was reached.
+// The peer can change these values by sending an ACK_FREQUENCY frame.
 type appDataReceivedPacketTracker struct {
 	receivedPacketTracker
 
@@ -86,16 +87,25 @@ type appDataReceivedPacketTracker struct {
 	maxAckDelay time.Duration
 	ackQueued   bool // true if we need send a new ACK
 
+	// the values requested by the peer in the most recent ACK_FREQUENCY frame
+	receivedAckFrequency   bool
+	ackFrequencySeqNum     uint64
+	ackElicitingThreshold  uint64
+	ignoreReorderedPackets bool
+
 	ackElicitingPacketsReceivedSinceLastAck int
 	ackAlarm                                time.Time
 
+	clock  utils.Clock
 	logger utils.Logger
 }
 
-func newAppDataReceivedPacketTracker(logger utils.Logger) *appDataReceivedPacketTracker {
+func newAppDataReceivedPacketTracker(maxAckDelay time.Duration, clock utils.Clock, logger utils.Logger) *appDataReceivedPacketTracker {
 	h := &appDataReceivedPacketTracker{
 		receivedPacketTracker: *newReceivedPacketTracker(),
-		maxAckDelay:           protocol.MaxAckDelay,
+		maxAckDelay:           maxAckDelay,
+		ackElicitingThreshold: packetsBeforeAck - 1,
+		clock:                 clock,
 		logger:                logger,
 	}
 	return h
@@ -128,6 +138,32 @@ func (h *appDataReceivedPacketTracker) ReceivedPacket(pn protocol.PacketNumber,
 	return nil
 }
 
+// ReceivedAckFrequencyFrame applies the values requested by the peer in an ACK_FREQUENCY frame.
+// Frames that are older than the most recent frame are ignored.
+// A reordering threshold of 0 means that reordered packets don't cause an ACK to be sent immediately.
+// Any other value is treated like the default value of 1.
+func (h *appDataReceivedPacketTracker) ReceivedAckFrequencyFrame(f *wire.AckFrequencyFrame) {
+	if h.receivedAckFrequency && f.SequenceNumber <= h.ackFrequencySeqNum {
+		return
+	}
+	h.receivedAckFrequency = true
+	h.ackFrequencySeqNum = f.SequenceNumber
+	h.ackElicitingThreshold = f.AckElicitingThreshold
+	// The requested max ack delay includes the timer granularity, see the max_ack_delay we send.
+	h.maxAckDelay = max(0, f.RequestMaxAckDelay-protocol.TimerGranularity)
+	h.ignoreReorderedPackets = f.ReorderingThreshold == 0
+	if h.logger.Debug() {
+		h.logger.Debugf("\tUpdating ACK frequency: ack-eliciting threshold %d, max ack delay %s, ignore reordering: %t", h.ackElicitingThreshold, h.maxAckDelay, h.ignoreReorderedPackets)
+	}
+}
+
+// ReceivedImmediateAck queues an ACK, as requested by the peer in an IMMEDIATE_ACK frame.
+func (h *appDataReceivedPacketTracker) ReceivedImmediateAck() {
+	h.logger.Debugf("\tQueueing ACK because an IMMEDIATE_ACK frame was received.")
+	h.ackQueued = true
+	h.ackAlarm = time.Time{}
+}
+
 // IgnoreBelow sets a lower limit for acknowledging packets.
 // Packets with packet numbers smaller than p will not be acked.
 func (h *appDataReceivedPacketTracker) IgnoreBelow(pn protocol.PacketNumber) {
@@ -167,23 +203,23 @@ func (h *appDataReceivedPacketTracker) shouldQueueACK(pn protocol.PacketNumber,
 	// Send an ACK if this packet was reported missing in an ACK sent before.
 	// Ack decimation with reordering relies on the timer to send an ACK, but if
 	// missing packets we reported in the previous ACK, send an ACK immediately.
-	if wasMissing {
+	if wasMissing && !h.ignoreReorderedPackets {
 		if h.logger.Debug() {
 			h.logger.Debugf("\tQueueing ACK because packet %d was missing before.", pn)
 		}
 		return true
 	}
 
-	// send an ACK every 2 ack-eliciting packets
-	if h.ackElicitingPacketsReceivedSinceLastAck >= packetsBeforeAck {
+	// send an ACK every 2 ack-eliciting packets, unless the peer requested a different threshold
+	if uint64(h.ackElicitingPacketsReceivedSinceLastAck) > h.ackElicitingThreshold {
 		if h.logger.Debug() {
-			h.logger.Debugf("\tQueueing ACK because packet %d packets were received after the last ACK (using initial threshold: %d).", h.ackElicitingPacketsReceivedSinceLastAck, packetsBeforeAck)
+			h.logger.Debugf("\tQueueing ACK because packet %d packets were received after the last ACK (using threshold: %d).", h.ackElicitingPacketsReceivedSinceLastAck, h.ackElicitingThreshold)
 		}
 		return true
 	}
 
 	// queue an ACK if there are new missing packets to report
-	if h.hasNewMissingPackets() {
+	if !h.ignoreReorderedPackets && h.hasNewMissingPackets() {
 		h.logger.Debugf("\tQueuing ACK because there's a new missing packet to report.")
 		return true
 	}
@@ -197,7 +233,7 @@ func (h *appDataReceivedPacketTracker) shouldQueueACK(pn protocol.PacketNumber,
 }
 
 func (h *appDataReceivedPacketTracker) GetAckFrame(onlyIfQueued bool) *wire.AckFrame {
-	now := time.Now()
+	now := h.clock.Now()
 	if onlyIfQueued && !h.ackQueued {
 		if h.ackAlarm.IsZero() || h.ackAlarm.After(now) {
 			return nil
@@ -68,7 +68,7 @@ var _ = Describe("Application Data Received Packet Tracker", func() {
 	var tracker *appDataReceivedPacketTracker
 
 	BeforeEach(func() {
-		tracker = newAppDataReceivedPacketTracker(utils.DefaultLogger)
+		tracker = newAppDataReceivedPacketTracker(protocol.MaxAckDelay, utils.DefaultClock{}, utils.DefaultLogger)
 	})
 
 	Context("accepting packets", func() {
@@ -177,6 +177,15 @@ var _ = Describe("Application Data Received Packet Tracker", func() {
 				Expect(tracker.GetAlarmTimeout()).To(Equal(rcvTime.Add(protocol.MaxAckDelay)))
 			})
 
+			It("uses the configured max ack delay", func() {
+				tracker = newAppDataReceivedPacketTracker(5*time.Millisecond, utils.DefaultClock{}, utils.DefaultLogger)
+				receiveAndAck10Packets()
+				rcvTime := time.Now()
+				Expect(tracker.ReceivedPacket(11, protocol.ECNNon, rcvTime, true)).To(Succeed())
+				Expect(tracker.ackQueued).To(BeFalse())
+				Expect(tracker.GetAlarmTimeout()).To(Equal(rcvTime.Add(5 * time.Millisecond)))
+			})
+
 			It("queues an ACK if the packet was ECN-CE marked", func() {
 				receiveAndAck10Packets()
 				Expect(tracker.ReceivedPacket(11, protocol.ECNCE, time.Now(), true)).To(Succeed())
@@ -236,6 +245,90 @@ var _ = Describe("Application Data Received Packet Tracker", func() {
 				Expect(tracker.ReceivedPacket(11, protocol.ECNNon, time.Now(), true)).To(Succeed())
 				Expect(tracker.GetAckFrame(true)).To(BeNil())
 			})
+
+			Context("ACK frequency", func() {
+				It("queues an ACK after the ack-eliciting threshold requested by the peer", func() {
+					receiveAndAck10Packets()
+					tracker.ReceivedAckFrequencyFrame(&wire.AckFrequencyFrame{
+						SequenceNumber:        1,
+						AckElicitingThreshold: 4,
+						RequestMaxAckDelay:    50 * time.Millisecond,
+						ReorderingThreshold:   1,
+					})
+					p := protocol.PacketNumber(11)
+					for i := 0; i < 3; i++ {
+						for j := 0; j < 4; j++ {
+							Expect(tracker.ReceivedPacket(p, protocol.ECNNon, time.Time{}, true)).To(Succeed())
+							Expect(tracker.ackQueued).To(BeFalse())
+							p++
+						}
+						Expect(tracker.ReceivedPacket(p, protocol.ECNNon, time.Time{}, true)).To(Succeed())
+						Expect(tracker.ackQueued).To(BeTrue())
+						p++
+						Expect(tracker.GetAckFrame(true)).ToNot(BeNil())
+					}
+				})
+
+				It("uses the max ack delay requested by the peer", func() {
+					receiveAndAck10Packets()
+					tracker.ReceivedAckFrequencyFrame(&wire.AckFrequencyFrame{
+						SequenceNumber:        1,
+						AckElicitingThreshold: 10,
+						RequestMaxAckDelay:    50 * time.Millisecond,
+						ReorderingThreshold:   1,
+					})
+					rcvTime := time.Now()
+					Expect(tracker.ReceivedPacket(11, protocol.ECNNon, rcvTime, true)).To(Succeed())
+					Expect(tracker.ackQueued).To(BeFalse())
+					Expect(tracker.GetAlarmTimeout()).To(Equal(rcvTime.Add(50*time.Millisecond - protocol.TimerGranularity)))
+				})
+
+				It("ignores ACK_FREQUENCY frames with old sequence numbers", func() {
+					tracker.ReceivedAckFrequencyFrame(&wire.AckFrequencyFrame{
+						SequenceNumber:        5,
+						AckElicitingThreshold: 10,
+						RequestMaxAckDelay:    50 * time.Millisecond,
+						ReorderingThreshold:   1,
+					})
+					tracker.ReceivedAckFrequencyFrame(&wire.AckFrequencyFrame{
+						SequenceNumber:        4,
+						AckElicitingThreshold: 2,
+						RequestMaxAckDelay:    10 * time.Millisecond,
+						ReorderingThreshold:   1,
+					})
+					Expect(tracker.ackElicitingThreshold).To(BeEquivalentTo(10))
+					Expect(tracker.maxAckDelay).To(Equal(50*time.Millisecond - protocol.TimerGranularity))
+				})
+
+				It("doesn't queue an ACK for reordered packets if the reordering threshold is 0", func() {
+					receiveAndAck10Packets()
+					tracker.ReceivedAckFrequencyFrame(&wire.AckFrequencyFrame{
+						SequenceNumber:        1,
+						AckElicitingThreshold: 10,
+						RequestMaxAckDelay:    50 * time.Millisecond,
+						ReorderingThreshold:   0,
+					})
+					Expect(tracker.ReceivedPacket(11, protocol.ECNNon, time.Now(), true)).To(Succeed())
+					Expect(tracker.ReceivedPacket(13, protocol.ECNNon, time.Now(), true)).To(Succeed())
+					Expect(tracker.ackQueued).To(BeFalse())
+					Expect(tracker.GetAckFrame(false)).ToNot(BeNil()) // ACK: 1-11 and 13, missing: 12
+					Expect(tracker.ReceivedPacket(12, protocol.ECNNon, time.Now(), true)).To(Succeed())
+					Expect(tracker.ackQueued).To(BeFalse())
+				})
+
+				It("queues an ACK when receiving an IMMEDIATE_ACK frame", func() {
+					receiveAndAck10Packets()
+					Expect(tracker.ReceivedPacket(11, protocol.ECNNon, time.Now(), true)).To(Succeed())
+					Expect(tracker.ackQueued).To(BeFalse())
+					Expect(tracker.GetAlarmTimeout()).ToNot(BeZero())
+					tracker.ReceivedImmediateAck()
+					Expect(tracker.ackQueued).To(BeTrue())
+					Expect(tracker.GetAlarmTimeout()).To(BeZero())
+					ack := tracker.GetAckFrame(true)
+					Expect(ack).ToNot(BeNil())
+					Expect(ack.LargestAcked()).To(Equal(protocol.PacketNumber(11)))
+				})
+			})
 		})
 
 		Context("ACK generation", func() {
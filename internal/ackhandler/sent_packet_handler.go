@@ -3,6 +3,7 @@ package ackhandler
 import (
 	"errors"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/quic-go/quic-go/internal/congestion"
@@ -66,6 +67,8 @@ type sentPacketHandler struct {
 	// Have we validated the peer's address yet?
 	// Always true for the client.
 	peerAddressValidated bool
+	// Set when the tracer was informed that we're amplification limited, until we're unblocked.
+	reportedAmplificationLimit bool
 
 	handshakeConfirmed bool
 
@@ -78,10 +81,19 @@ type sentPacketHandler struct {
 	ackedPackets []*packet // to avoid allocations in detectAndRemoveAckedPackets
 
 	bytesInFlight protocol.ByteCount
+	lostPackets   uint64
+	// The number of Initial and Handshake packets whose frames were retransmitted,
+	// either because the packet was declared lost or because it was retransmitted in a PTO probe.
+	numRetransmittedInitial, numRetransmittedHandshake uint64
 
 	congestion congestion.SendAlgorithmWithDebugInfos
 	rttStats   *utils.RTTStats
 
+	// A snapshot of the stats, updated whenever they might have changed.
+	// It allows reading the stats without synchronizing with the connection's run loop.
+	statsMutex sync.Mutex
+	stats      Stats
+
 	// The number of times a PTO has been sent without receiving an ack.
 	ptoCount uint32
 	ptoMode  SendMode
@@ -94,10 +106,17 @@ type sentPacketHandler struct {
 
 	enableECN  bool
 	ecnTracker ecnHandler
+	// The number of packets sent with an ECT(0) and ECT(1) marking,
+	// and the ECN counts of the most recent 1-RTT ACK frame received from the peer.
+	numSentECT0, numSentECT1                  uint64
+	numAckedECT0, numAckedECT1, numAckedECNCE uint64
+
+	disablePacing bool
 
 	perspective protocol.Perspective
 
 	tracer *logging.ConnectionTracer
+	clock  utils.Clock
 	logger utils.Logger
 }
 
@@ -112,19 +131,25 @@ func newSentPacketHandler(
 	initialPN protocol.PacketNumber,
 	initialMaxDatagramSize protocol.ByteCount,
 	rttStats *utils.RTTStats,
+	cong congestion.SendAlgorithmWithDebugInfos,
 	clientAddressValidated bool,
 	enableECN bool,
+	disablePacing bool,
 	pers protocol.Perspective,
 	tracer *logging.ConnectionTracer,
+	clock utils.Clock,
 	logger utils.Logger,
 ) *sentPacketHandler {
-	congestion := congestion.NewCubicSender(
-		congestion.DefaultClock{},
-		rttStats,
-		initialMaxDatagramSize,
-		true, // use Reno
-		tracer,
-	)
+	if cong == nil {
+		cong = congestion.NewCubicSender(
+			clock,
+			rttStats,
+			initialMaxDatagramSize,
+			protocol.InitialCongestionWindowPackets*initialMaxDatagramSize,
+			true, // use Reno
+			tracer,
+		)
+	}
 
 	h := &sentPacketHandler{
 		peerCompletedAddressValidation: pers == protocol.PerspectiveServer,
@@ -133,15 +158,18 @@ func newSentPacketHandler(
 		handshakePackets:               newPacketNumberSpace(0, false),
 		appDataPackets:                 newPacketNumberSpace(0, true),
 		rttStats:                       rttStats,
-		congestion:                     congestion,
+		congestion:                     cong,
+		disablePacing:                  disablePacing,
 		perspective:                    pers,
 		tracer:                         tracer,
+		clock:                          clock,
 		logger:                         logger,
 	}
 	if enableECN {
 		h.enableECN = true
 		h.ecnTracker = newECNTracker(logger, tracer)
 	}
+	h.updateStats()
 	return h
 }
 
@@ -156,6 +184,7 @@ func (h *sentPacketHandler) removeFromBytesInFlight(p *packet) {
 }
 
 func (h *sentPacketHandler) DropPackets(encLevel protocol.EncryptionLevel) {
+	defer h.updateStats()
 	// The server won't await address validation after the handshake is confirmed.
 	// This applies even if we didn't receive an ACK for a Handshake packet.
 	if h.perspective == protocol.PerspectiveClient && encLevel == protocol.EncryptionHandshake {
@@ -209,6 +238,7 @@ func (h *sentPacketHandler) ReceivedBytes(n protocol.ByteCount) {
 	wasAmplificationLimit := h.isAmplificationLimited()
 	h.bytesReceived += n
 	if wasAmplificationLimit && !h.isAmplificationLimited() {
+		h.reportedAmplificationLimit = false
 		h.setLossDetectionTimer()
 	}
 }
@@ -241,6 +271,7 @@ func (h *sentPacketHandler) SentPacket(
 	size protocol.ByteCount,
 	isPathMTUProbePacket bool,
 ) {
+	defer h.updateStats()
 	h.bytesSent += size
 
 	pnSpace := h.getPacketNumberSpace(encLevel)
@@ -262,6 +293,13 @@ func (h *sentPacketHandler) SentPacket(
 	}
 	h.congestion.OnPacketSent(t, h.bytesInFlight, pn, size, isAckEliciting)
 
+	//nolint:exhaustive // We never send packets marked with ECN-CE.
+	switch ecn {
+	case protocol.ECT0:
+		h.numSentECT0++
+	case protocol.ECT1:
+		h.numSentECT1++
+	}
 	if encLevel == protocol.Encryption1RTT && h.ecnTracker != nil {
 		h.ecnTracker.SentPacket(pn, ecn)
 	}
@@ -292,6 +330,37 @@ func (h *sentPacketHandler) SentPacket(
 	h.setLossDetectionTimer()
 }
 
+func (h *sentPacketHandler) updateStats() {
+	h.statsMutex.Lock()
+	h.stats = Stats{
+		MinRTT:                        h.rttStats.MinRTT(),
+		LatestRTT:                     h.rttStats.LatestRTT(),
+		SmoothedRTT:                   h.rttStats.SmoothedRTT(),
+		MeanDeviation:                 h.rttStats.MeanDeviation(),
+		CongestionWindow:              h.congestion.GetCongestionWindow(),
+		BytesInFlight:                 h.bytesInFlight,
+		PacketsLost:                   h.lostPackets,
+		PTOCount:                      h.ptoCount,
+		InitialPacketsRetransmitted:   h.numRetransmittedInitial,
+		HandshakePacketsRetransmitted: h.numRetransmittedHandshake,
+		SentECT0:                      h.numSentECT0,
+		SentECT1:                      h.numSentECT1,
+		AckedECT0:                     h.numAckedECT0,
+		AckedECT1:                     h.numAckedECT1,
+		AckedECNCE:                    h.numAckedECNCE,
+	}
+	if e, ok := h.congestion.(congestion.DeliveryRateEstimator); ok {
+		h.stats.DeliveryRate, h.stats.HasDeliveryRate = e.DeliveryRate()
+	}
+	h.statsMutex.Unlock()
+}
+
+func (h *sentPacketHandler) Stats() Stats {
+	h.statsMutex.Lock()
+	defer h.statsMutex.Unlock()
+	return h.stats
+}
+
 func (h *sentPacketHandler) getPacketNumberSpace(encLevel protocol.EncryptionLevel) *packetNumberSpace {
 	switch encLevel {
 	case protocol.EncryptionInitial:
@@ -306,6 +375,7 @@ func (h *sentPacketHandler) getPacketNumberSpace(encLevel protocol.EncryptionLev
 }
 
 func (h *sentPacketHandler) ReceivedAck(ack *wire.AckFrame, encLevel protocol.EncryptionLevel, rcvTime time.Time) (bool /* contained 1-RTT packet */, error) {
+	defer h.updateStats()
 	pnSpace := h.getPacketNumberSpace(encLevel)
 
 	largestAcked := ack.LargestAcked()
@@ -346,6 +416,10 @@ func (h *sentPacketHandler) ReceivedAck(ack *wire.AckFrame, encLevel protocol.En
 		}
 	}
 
+	// Reordered ACKs carry stale ECN counts.
+	if encLevel == protocol.Encryption1RTT && largestAcked > pnSpace.largestAcked {
+		h.numAckedECT0, h.numAckedECT1, h.numAckedECNCE = ack.ECT0, ack.ECT1, ack.ECNCE
+	}
 	// Only inform the ECN tracker about new 1-RTT ACKs if the ACK increases the largest acked.
 	if encLevel == protocol.Encryption1RTT && h.ecnTracker != nil && largestAcked > pnSpace.largestAcked {
 		congested := h.ecnTracker.HandleNewlyAcked(ackedPackets, int64(ack.ECT0), int64(ack.ECT1), int64(ack.ECNCE))
@@ -505,7 +579,7 @@ func (h *sentPacketHandler) getPTOTimeAndSpace() (pto time.Time, encLevel protoc
 		if h.peerCompletedAddressValidation {
 			return
 		}
-		t := time.Now().Add(h.getScaledPTO(false))
+		t := h.clock.Now().Add(h.getScaledPTO(false))
 		if h.initialPackets != nil {
 			return t, protocol.EncryptionInitial, true
 		}
@@ -654,9 +728,11 @@ func (h *sentPacketHandler) detectLostPackets(now time.Time, encLevel protocol.E
 		if packetLost {
 			pnSpace.history.DeclareLost(p.PacketNumber)
 			if !p.skippedPacket {
+				h.lostPackets++
 				// the bytes in flight need to be reduced no matter if the frames in this packet will be retransmitted
 				h.removeFromBytesInFlight(p)
 				h.queueFramesForRetransmission(p)
+				h.countRetransmission(encLevel)
 				if !p.IsPathMTUProbePacket {
 					h.congestion.OnCongestionEvent(p.PacketNumber, p.Length, priorInFlight)
 				}
@@ -670,6 +746,7 @@ func (h *sentPacketHandler) detectLostPackets(now time.Time, encLevel protocol.E
 }
 
 func (h *sentPacketHandler) OnLossDetectionTimeout() error {
+	defer h.updateStats()
 	defer h.setLossDetectionTimer()
 	earliestLossTime, encLevel := h.getLossTimeAndSpace()
 	if !earliestLossTime.IsZero() {
@@ -680,7 +757,7 @@ func (h *sentPacketHandler) OnLossDetectionTimeout() error {
 			h.tracer.LossTimerExpired(logging.TimerTypeACK, encLevel)
 		}
 		// Early retransmit or time loss detection
-		return h.detectLostPackets(time.Now(), encLevel)
+		return h.detectLostPackets(h.clock.Now(), encLevel)
 	}
 
 	// PTO
@@ -783,6 +860,12 @@ func (h *sentPacketHandler) SendMode(now time.Time) SendMode {
 
 	if h.isAmplificationLimited() {
 		h.logger.Debugf("Amplification window limited. Received %d bytes, already sent out %d bytes", h.bytesReceived, h.bytesSent)
+		if !h.reportedAmplificationLimit {
+			h.reportedAmplificationLimit = true
+			if h.tracer != nil && h.tracer.AmplificationLimited != nil {
+				h.tracer.AmplificationLimited(h.bytesReceived, h.bytesSent)
+			}
+		}
 		return SendNone
 	}
 	// Don't send any packets if we're keeping track of the maximum number of packets.
@@ -811,7 +894,8 @@ func (h *sentPacketHandler) SendMode(now time.Time) SendMode {
 		}
 		return SendAck
 	}
-	if !h.congestion.HasPacingBudget(now) {
+	// With pacing disabled, bursts are only limited by the congestion window.
+	if !h.disablePacing && !h.congestion.HasPacingBudget(now) {
 		return SendPacingLimited
 	}
 	return SendAny
@@ -823,6 +907,16 @@ func (h *sentPacketHandler) TimeUntilSend() time.Time {
 
 func (h *sentPacketHandler) SetMaxDatagramSize(s protocol.ByteCount) {
 	h.congestion.SetMaxDatagramSize(s)
+	h.updateStats()
+}
+
+func (h *sentPacketHandler) MigratedPath() {
+	h.rttStats.OnConnectionMigration()
+	// Custom congestion controllers might not support resetting their state.
+	if c, ok := h.congestion.(interface{ OnConnectionMigration() }); ok {
+		c.OnConnectionMigration()
+	}
+	h.updateStats()
 }
 
 func (h *sentPacketHandler) isAmplificationLimited() bool {
@@ -839,13 +933,25 @@ func (h *sentPacketHandler) QueueProbePacket(encLevel protocol.EncryptionLevel)
 		return false
 	}
 	h.queueFramesForRetransmission(p)
+	h.countRetransmission(encLevel)
 	// TODO: don't declare the packet lost here.
 	// Keep track of acknowledged frames instead.
 	h.removeFromBytesInFlight(p)
 	pnSpace.history.DeclareLost(p.PacketNumber)
+	h.updateStats()
 	return true
 }
 
+func (h *sentPacketHandler) countRetransmission(encLevel protocol.EncryptionLevel) {
+	//nolint:exhaustive // Only Initial and Handshake retransmissions are counted.
+	switch encLevel {
+	case protocol.EncryptionInitial:
+		h.numRetransmittedInitial++
+	case protocol.EncryptionHandshake:
+		h.numRetransmittedHandshake++
+	}
+}
+
 func (h *sentPacketHandler) queueFramesForRetransmission(p *packet) {
 	if len(p.Frames) == 0 && len(p.StreamFrames) == 0 {
 		panic("no frames")
@@ -865,6 +971,7 @@ func (h *sentPacketHandler) queueFramesForRetransmission(p *packet) {
 }
 
 func (h *sentPacketHandler) ResetForRetry(now time.Time) error {
+	defer h.updateStats()
 	h.bytesInFlight = 0
 	var firstPacketSendTime time.Time
 	h.initialPackets.history.Iterate(func(p *packet) (bool, error) {
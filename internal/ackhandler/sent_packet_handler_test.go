@@ -4,11 +4,14 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/quic-go/quic-go/internal/congestion"
 	"github.com/quic-go/quic-go/internal/mocks"
+	mocklogging "github.com/quic-go/quic-go/internal/mocks/logging"
 	"github.com/quic-go/quic-go/internal/protocol"
 	"github.com/quic-go/quic-go/internal/qerr"
 	"github.com/quic-go/quic-go/internal/utils"
 	"github.com/quic-go/quic-go/internal/wire"
+	"github.com/quic-go/quic-go/logging"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -31,6 +34,33 @@ func (h *customFrameHandler) OnAcked(f wire.Frame) {
 	}
 }
 
+// fixedWindowController is a congestion controller that uses a fixed congestion window.
+type fixedWindowController struct {
+	window     protocol.ByteCount
+	ackedBytes protocol.ByteCount
+}
+
+var _ congestion.SendAlgorithmWithDebugInfos = &fixedWindowController{}
+
+func (c *fixedWindowController) TimeUntilSend(protocol.ByteCount) time.Time { return time.Time{} }
+func (c *fixedWindowController) HasPacingBudget(time.Time) bool             { return true }
+func (c *fixedWindowController) OnPacketSent(time.Time, protocol.ByteCount, protocol.PacketNumber, protocol.ByteCount, bool) {
+}
+func (c *fixedWindowController) CanSend(bytesInFlight protocol.ByteCount) bool {
+	return bytesInFlight < c.window
+}
+func (c *fixedWindowController) MaybeExitSlowStart() {}
+func (c *fixedWindowController) OnPacketAcked(_ protocol.PacketNumber, ackedBytes, _ protocol.ByteCount, _ time.Time) {
+	c.ackedBytes += ackedBytes
+}
+func (c *fixedWindowController) OnCongestionEvent(protocol.PacketNumber, protocol.ByteCount, protocol.ByteCount) {
+}
+func (c *fixedWindowController) OnRetransmissionTimeout(bool)            {}
+func (c *fixedWindowController) SetMaxDatagramSize(protocol.ByteCount)   {}
+func (c *fixedWindowController) InSlowStart() bool                       { return false }
+func (c *fixedWindowController) InRecovery() bool                        { return false }
+func (c *fixedWindowController) GetCongestionWindow() protocol.ByteCount { return c.window }
+
 var _ = Describe("SentPacketHandler", func() {
 	var (
 		handler     *sentPacketHandler
@@ -44,7 +74,7 @@ var _ = Describe("SentPacketHandler", func() {
 	JustBeforeEach(func() {
 		lostPackets = nil
 		rttStats := utils.NewRTTStats()
-		handler = newSentPacketHandler(42, protocol.InitialPacketSize, rttStats, false, false, perspective, nil, utils.DefaultLogger)
+		handler = newSentPacketHandler(42, protocol.InitialPacketSize, rttStats, nil, false, false, false, perspective, nil, utils.DefaultClock{}, utils.DefaultLogger)
 		streamFrame = wire.StreamFrame{
 			StreamID: 5,
 			Data:     []byte{0x13, 0x37},
@@ -521,6 +551,8 @@ var _ = Describe("SentPacketHandler", func() {
 
 		JustBeforeEach(func() {
 			cong = mocks.NewMockSendAlgorithmWithDebugInfos(mockCtrl)
+			// used for updating the stats
+			cong.EXPECT().GetCongestionWindow().AnyTimes()
 			handler.congestion = cong
 		})
 
@@ -672,6 +704,16 @@ var _ = Describe("SentPacketHandler", func() {
 			Expect(handler.SendMode(time.Now())).To(Equal(SendPTOHandshake))
 		})
 
+		It("doesn't pace packets if pacing is disabled", func() {
+			handler.disablePacing = true
+			handler.ReceivedPacket(protocol.EncryptionHandshake)
+			// note that we don't EXPECT a call to HasPacingBudget
+			cong.EXPECT().CanSend(gomock.Any()).Return(true)
+			Expect(handler.SendMode(time.Now())).To(Equal(SendAny))
+			cong.EXPECT().CanSend(gomock.Any()).Return(false)
+			Expect(handler.SendMode(time.Now())).To(Equal(SendAck))
+		})
+
 		It("returns the pacing delay", func() {
 			t := time.Now()
 			cong.EXPECT().TimeUntilSend(gomock.Any()).Return(t)
@@ -695,6 +737,56 @@ var _ = Describe("SentPacketHandler", func() {
 		Expect(handler.SendMode(time.Now())).To(Equal(SendAny))
 	})
 
+	Context("custom congestion control", func() {
+		It("uses a custom congestion controller", func() {
+			cong := &fixedWindowController{window: 3000}
+			handler = newSentPacketHandler(0, protocol.InitialPacketSize, utils.NewRTTStats(), cong, false, false, false, protocol.PerspectiveClient, nil, utils.DefaultClock{}, utils.DefaultLogger)
+			Expect(handler.Stats().CongestionWindow).To(BeEquivalentTo(3000))
+			for i := protocol.PacketNumber(0); i < 3; i++ {
+				Expect(handler.SendMode(time.Now())).To(Equal(SendAny))
+				sentPacket(ackElicitingPacket(&packet{PacketNumber: i, Length: 1000}))
+			}
+			Expect(handler.SendMode(time.Now())).To(Equal(SendAck))
+			ack := &wire.AckFrame{AckRanges: []wire.AckRange{{Smallest: 1, Largest: 1}}}
+			_, err := handler.ReceivedAck(ack, protocol.Encryption1RTT, time.Now())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(cong.ackedBytes).To(BeEquivalentTo(1000))
+			Expect(handler.SendMode(time.Now())).To(Equal(SendAny))
+		})
+	})
+
+	Context("path migration", func() {
+		It("resets the RTT estimate and the congestion controller", func() {
+			initialWindow := handler.congestion.GetCongestionWindow()
+			now := time.Now()
+			for i := protocol.PacketNumber(1); i <= 5; i++ {
+				sentPacket(ackElicitingPacket(&packet{PacketNumber: i, Length: 1000, SendTime: now.Add(-time.Second)}))
+			}
+			// packets 1 and 2 are declared lost, which reduces the congestion window
+			ack := &wire.AckFrame{AckRanges: []wire.AckRange{{Smallest: 5, Largest: 5}}}
+			_, err := handler.ReceivedAck(ack, protocol.Encryption1RTT, now)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(handler.congestion.GetCongestionWindow()).To(BeNumerically("<", initialWindow))
+			Expect(handler.Stats().SmoothedRTT).To(Equal(time.Second))
+
+			handler.MigratedPath()
+			Expect(handler.congestion.GetCongestionWindow()).To(Equal(initialWindow))
+			Expect(handler.rttStats.SmoothedRTT()).To(BeZero())
+			Expect(handler.Stats().SmoothedRTT).To(BeZero())
+			Expect(handler.Stats().CongestionWindow).To(Equal(initialWindow))
+		})
+
+		It("resets the RTT estimate when using a custom congestion controller", func() {
+			cong := &fixedWindowController{window: 3000}
+			rttStats := utils.NewRTTStats()
+			handler = newSentPacketHandler(0, protocol.InitialPacketSize, rttStats, cong, false, false, false, protocol.PerspectiveClient, nil, utils.DefaultClock{}, utils.DefaultLogger)
+			rttStats.UpdateRTT(time.Second, 0, time.Now())
+			handler.MigratedPath()
+			Expect(rttStats.SmoothedRTT()).To(BeZero())
+			Expect(handler.Stats().CongestionWindow).To(BeEquivalentTo(3000))
+		})
+	})
+
 	Context("probe packets", func() {
 		It("queues a probe packet", func() {
 			sentPacket(ackElicitingPacket(&packet{PacketNumber: 10}))
@@ -743,9 +835,11 @@ var _ = Describe("SentPacketHandler", func() {
 			Expect(handler.OnLossDetectionTimeout()).To(Succeed())
 			Expect(handler.SendMode(time.Now())).To(Equal(SendPTOAppData))
 			Expect(handler.ptoCount).To(BeEquivalentTo(1))
+			Expect(handler.Stats().PTOCount).To(BeEquivalentTo(1))
 			_, err := handler.ReceivedAck(&wire.AckFrame{AckRanges: []wire.AckRange{{Smallest: 1, Largest: 1}}}, protocol.Encryption1RTT, time.Now())
 			Expect(err).ToNot(HaveOccurred())
 			Expect(handler.ptoCount).To(BeZero())
+			Expect(handler.Stats().PTOCount).To(BeZero())
 		})
 
 		It("resets the PTO mode and PTO count when a packet number space is dropped", func() {
@@ -938,6 +1032,37 @@ var _ = Describe("SentPacketHandler", func() {
 			Expect(handler.SendMode(time.Now())).To(Equal(SendNone))
 		})
 
+		It("traces when it becomes amplification limited", func() {
+			tr, tracer := mocklogging.NewMockConnectionTracer(mockCtrl)
+			handler.tracer = tr
+			tracer.EXPECT().UpdatedMetrics(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+			tracer.EXPECT().SetLossTimer(gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+			tracer.EXPECT().LossTimerCanceled().AnyTimes()
+			handler.ReceivedBytes(200)
+			sentPacket(&packet{
+				PacketNumber:    1,
+				Length:          600,
+				EncryptionLevel: protocol.EncryptionInitial,
+				Frames:          []Frame{{Frame: &wire.PingFrame{}}},
+				SendTime:        time.Now(),
+			})
+			tracer.EXPECT().AmplificationLimited(protocol.ByteCount(200), protocol.ByteCount(600))
+			Expect(handler.SendMode(time.Now())).To(Equal(SendNone))
+			// only traced once, until unblocked
+			Expect(handler.SendMode(time.Now())).To(Equal(SendNone))
+			handler.ReceivedBytes(100)
+			Expect(handler.SendMode(time.Now())).To(Equal(SendAny))
+			sentPacket(&packet{
+				PacketNumber:    2,
+				Length:          300,
+				EncryptionLevel: protocol.EncryptionInitial,
+				Frames:          []Frame{{Frame: &wire.PingFrame{}}},
+				SendTime:        time.Now(),
+			})
+			tracer.EXPECT().AmplificationLimited(protocol.ByteCount(300), protocol.ByteCount(900))
+			Expect(handler.SendMode(time.Now())).To(Equal(SendNone))
+		})
+
 		It("cancels the loss detection timer when it is amplification limited, and resets it when becoming unblocked", func() {
 			handler.ReceivedBytes(300)
 			sentPacket(&packet{
@@ -984,7 +1109,7 @@ var _ = Describe("SentPacketHandler", func() {
 	Context("amplification limit, for the server, with validated address", func() {
 		JustBeforeEach(func() {
 			rttStats := utils.NewRTTStats()
-			handler = newSentPacketHandler(42, protocol.InitialPacketSize, rttStats, true, false, perspective, nil, utils.DefaultLogger)
+			handler = newSentPacketHandler(42, protocol.InitialPacketSize, rttStats, nil, true, false, false, perspective, nil, utils.DefaultClock{}, utils.DefaultLogger)
 		})
 
 		It("do not limits the window", func() {
@@ -1118,6 +1243,134 @@ var _ = Describe("SentPacketHandler", func() {
 			expectInPacketHistory([]protocol.PacketNumber{4, 5}, protocol.Encryption1RTT)
 			Expect(lostPackets).To(Equal([]protocol.PacketNumber{1, 2, 3}))
 		})
+
+		It("traces packets lost due to the packet threshold", func() {
+			tr, tracer := mocklogging.NewMockConnectionTracer(mockCtrl)
+			handler.tracer = tr
+			tracer.EXPECT().UpdatedMetrics(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+			tracer.EXPECT().SetLossTimer(gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+			tracer.EXPECT().AcknowledgedPacket(gomock.Any(), gomock.Any()).AnyTimes()
+			now := time.Now()
+			for i := protocol.PacketNumber(1); i <= 6; i++ {
+				sentPacket(ackElicitingPacket(&packet{PacketNumber: i, SendTime: now}))
+			}
+			gomock.InOrder(
+				tracer.EXPECT().LostPacket(protocol.Encryption1RTT, protocol.PacketNumber(1), logging.PacketLossReorderingThreshold),
+				tracer.EXPECT().LostPacket(protocol.Encryption1RTT, protocol.PacketNumber(2), logging.PacketLossReorderingThreshold),
+				tracer.EXPECT().LostPacket(protocol.Encryption1RTT, protocol.PacketNumber(3), logging.PacketLossReorderingThreshold),
+			)
+			ack := &wire.AckFrame{AckRanges: []wire.AckRange{{Smallest: 6, Largest: 6}}}
+			_, err := handler.ReceivedAck(ack, protocol.Encryption1RTT, now)
+			Expect(err).ToNot(HaveOccurred())
+			expectInPacketHistory([]protocol.PacketNumber{4, 5}, protocol.Encryption1RTT)
+		})
+	})
+
+	Context("stats", func() {
+		It("updates the stats", func() {
+			stats := handler.Stats()
+			Expect(stats.CongestionWindow).To(Equal(handler.congestion.GetCongestionWindow()))
+			Expect(stats.BytesInFlight).To(BeZero())
+			Expect(stats.SmoothedRTT).To(BeZero())
+
+			now := time.Now()
+			for i := protocol.PacketNumber(1); i <= 6; i++ {
+				sentPacket(ackElicitingPacket(&packet{PacketNumber: i, Length: 100, SendTime: now.Add(-time.Second)}))
+			}
+			Expect(handler.Stats().BytesInFlight).To(BeEquivalentTo(600))
+			ack := &wire.AckFrame{AckRanges: []wire.AckRange{{Smallest: 6, Largest: 6}}}
+			_, err := handler.ReceivedAck(ack, protocol.Encryption1RTT, now)
+			Expect(err).ToNot(HaveOccurred())
+			stats = handler.Stats()
+			// packets 1, 2 and 3 are lost, packets 4 and 5 are still in flight
+			Expect(stats.PacketsLost).To(BeEquivalentTo(3))
+			Expect(stats.BytesInFlight).To(BeEquivalentTo(200))
+			Expect(stats.LatestRTT).To(Equal(time.Second))
+			Expect(stats.MinRTT).To(Equal(time.Second))
+			Expect(stats.SmoothedRTT).To(Equal(handler.rttStats.SmoothedRTT()))
+			Expect(stats.MeanDeviation).To(Equal(handler.rttStats.MeanDeviation()))
+			Expect(stats.CongestionWindow).To(Equal(handler.congestion.GetCongestionWindow()))
+		})
+
+		It("counts retransmitted Initial and Handshake packets", func() {
+			now := time.Now()
+			for i := protocol.PacketNumber(1); i <= 6; i++ {
+				sentPacket(initialPacket(&packet{PacketNumber: i, SendTime: now.Add(-time.Second)}))
+			}
+			sentPacket(handshakePacket(&packet{PacketNumber: 1, SendTime: now}))
+			sentPacket(handshakePacket(&packet{PacketNumber: 2, SendTime: now}))
+			sentPacket(ackElicitingPacket(&packet{PacketNumber: 1, SendTime: now}))
+			Expect(handler.Stats().InitialPacketsRetransmitted).To(BeZero())
+			Expect(handler.Stats().HandshakePacketsRetransmitted).To(BeZero())
+
+			ack := &wire.AckFrame{AckRanges: []wire.AckRange{{Smallest: 6, Largest: 6}}}
+			_, err := handler.ReceivedAck(ack, protocol.EncryptionInitial, now)
+			Expect(err).ToNot(HaveOccurred())
+			// packets 1, 2 and 3 are lost
+			Expect(handler.Stats().InitialPacketsRetransmitted).To(BeEquivalentTo(3))
+			Expect(handler.Stats().HandshakePacketsRetransmitted).To(BeZero())
+
+			Expect(handler.QueueProbePacket(protocol.EncryptionHandshake)).To(BeTrue())
+			Expect(handler.QueueProbePacket(protocol.Encryption1RTT)).To(BeTrue())
+			stats := handler.Stats()
+			Expect(stats.InitialPacketsRetransmitted).To(BeEquivalentTo(3))
+			Expect(stats.HandshakePacketsRetransmitted).To(BeEquivalentTo(1))
+			Expect(stats.PacketsLost).To(BeEquivalentTo(3))
+		})
+
+		It("includes the delivery rate estimate", func() {
+			Expect(handler.Stats().HasDeliveryRate).To(BeFalse())
+			now := time.Now()
+			sentPacket(ackElicitingPacket(&packet{PacketNumber: 1, Length: 1000, SendTime: now.Add(-time.Second)}))
+			sentPacket(ackElicitingPacket(&packet{PacketNumber: 2, Length: 1000, SendTime: now}))
+			sentPacket(ackElicitingPacket(&packet{PacketNumber: 3, Length: 1000, SendTime: now}))
+			_, err := handler.ReceivedAck(&wire.AckFrame{AckRanges: []wire.AckRange{{Smallest: 1, Largest: 1}}}, protocol.Encryption1RTT, now)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(handler.Stats().HasDeliveryRate).To(BeFalse())
+			_, err = handler.ReceivedAck(&wire.AckFrame{AckRanges: []wire.AckRange{{Smallest: 2, Largest: 3}}}, protocol.Encryption1RTT, now.Add(2*time.Second))
+			Expect(err).ToNot(HaveOccurred())
+			stats := handler.Stats()
+			Expect(stats.HasDeliveryRate).To(BeTrue())
+			Expect(stats.DeliveryRate).To(BeEquivalentTo(1000))
+		})
+
+		It("doesn't report a delivery rate for congestion controllers that don't estimate it", func() {
+			handler = newSentPacketHandler(0, protocol.InitialPacketSize, utils.NewRTTStats(), &fixedWindowController{window: 3000}, false, false, false, protocol.PerspectiveClient, nil, utils.DefaultClock{}, utils.DefaultLogger)
+			sentPacket(ackElicitingPacket(&packet{PacketNumber: 1, Length: 1000}))
+			_, err := handler.ReceivedAck(&wire.AckFrame{AckRanges: []wire.AckRange{{Smallest: 1, Largest: 1}}}, protocol.Encryption1RTT, time.Now())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(handler.Stats().HasDeliveryRate).To(BeFalse())
+		})
+	})
+
+	Context("pacing", func() {
+		// sendBurst sends back-to-back packets, until the handler doesn't allow sending any more
+		sendBurst := func() (numPackets int, mode SendMode) {
+			now := time.Now()
+			for {
+				mode = handler.SendMode(now)
+				if mode != SendAny {
+					return
+				}
+				sentPacket(ackElicitingPacket(&packet{PacketNumber: protocol.PacketNumber(numPackets), Length: 1200, SendTime: now}))
+				numPackets++
+			}
+		}
+
+		It("limits bursts using the pacer", func() {
+			handler = newSentPacketHandler(0, 1200, utils.NewRTTStats(), nil, false, false, false, protocol.PerspectiveClient, nil, utils.DefaultClock{}, utils.DefaultLogger)
+			numPackets, mode := sendBurst()
+			Expect(mode).To(Equal(SendPacingLimited))
+			Expect(numPackets).To(BeNumerically("<", protocol.InitialCongestionWindowPackets))
+		})
+
+		It("sends back-to-back packets up to the congestion window if pacing is disabled", func() {
+			handler = newSentPacketHandler(0, 1200, utils.NewRTTStats(), nil, false, false, true, protocol.PerspectiveClient, nil, utils.DefaultClock{}, utils.DefaultLogger)
+			numPackets, mode := sendBurst()
+			Expect(mode).To(Equal(SendAck))
+			Expect(numPackets).To(BeEquivalentTo(protocol.InitialCongestionWindowPackets))
+			Expect(handler.Stats().BytesInFlight).To(BeEquivalentTo(protocol.InitialCongestionWindowPackets * 1200))
+		})
 	})
 
 	Context("Delay-based loss detection", func() {
@@ -1135,6 +1388,22 @@ var _ = Describe("SentPacketHandler", func() {
 			Expect(handler.bytesInFlight).To(BeZero())
 		})
 
+		It("traces packets lost due to the time threshold", func() {
+			tr, tracer := mocklogging.NewMockConnectionTracer(mockCtrl)
+			handler.tracer = tr
+			tracer.EXPECT().UpdatedMetrics(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+			tracer.EXPECT().SetLossTimer(gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+			tracer.EXPECT().LossTimerCanceled().AnyTimes()
+			tracer.EXPECT().AcknowledgedPacket(gomock.Any(), gomock.Any()).AnyTimes()
+			now := time.Now()
+			sentPacket(ackElicitingPacket(&packet{PacketNumber: 1, SendTime: now.Add(-time.Hour)}))
+			sentPacket(ackElicitingPacket(&packet{PacketNumber: 2, SendTime: now.Add(-time.Second)}))
+			tracer.EXPECT().LostPacket(protocol.Encryption1RTT, protocol.PacketNumber(1), logging.PacketLossTimeThreshold)
+			ack := &wire.AckFrame{AckRanges: []wire.AckRange{{Smallest: 2, Largest: 2}}}
+			_, err := handler.ReceivedAck(ack, protocol.Encryption1RTT, now)
+			Expect(err).ToNot(HaveOccurred())
+		})
+
 		It("sets the early retransmit alarm", func() {
 			handler.ReceivedPacket(protocol.EncryptionHandshake)
 			handler.handshakeConfirmed = true
@@ -1439,11 +1708,12 @@ var _ = Describe("SentPacketHandler", func() {
 			cong.EXPECT().OnPacketSent(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
 			cong.EXPECT().OnPacketAcked(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
 			cong.EXPECT().MaybeExitSlowStart().AnyTimes()
+			cong.EXPECT().GetCongestionWindow().AnyTimes()
 			ecnHandler = NewMockECNHandler(mockCtrl)
 			lostPackets = nil
 			rttStats := utils.NewRTTStats()
 			rttStats.UpdateRTT(time.Hour, 0, time.Now())
-			handler = newSentPacketHandler(42, protocol.InitialPacketSize, rttStats, false, false, perspective, nil, utils.DefaultLogger)
+			handler = newSentPacketHandler(42, protocol.InitialPacketSize, rttStats, nil, false, false, false, perspective, nil, utils.DefaultClock{}, utils.DefaultLogger)
 			handler.ecnTracker = ecnHandler
 			handler.congestion = cong
 		})
@@ -1575,5 +1845,73 @@ var _ = Describe("SentPacketHandler", func() {
 			_, err := handler.ReceivedAck(&wire.AckFrame{AckRanges: []wire.AckRange{{Largest: 15, Smallest: 10}}}, protocol.Encryption1RTT, time.Now())
 			Expect(err).ToNot(HaveOccurred())
 		})
+
+		It("counts ECN markings", func() {
+			ecnHandler.EXPECT().SentPacket(gomock.Any(), gomock.Any()).AnyTimes()
+			handler.SentPacket(time.Now(), 100, -1, nil, nil, protocol.EncryptionInitial, protocol.ECT1, 1200, false)
+			for i := 10; i < 20; i++ {
+				handler.SentPacket(time.Now(), protocol.PacketNumber(i), -1, []StreamFrame{{Frame: &streamFrame}}, nil, protocol.Encryption1RTT, protocol.ECT0, 1200, false)
+			}
+			handler.SentPacket(time.Now(), 20, -1, nil, nil, protocol.Encryption1RTT, protocol.ECNNon, 1200, false)
+			Expect(handler.Stats().SentECT0).To(BeEquivalentTo(10))
+			Expect(handler.Stats().SentECT1).To(BeEquivalentTo(1))
+
+			ecnHandler.EXPECT().HandleNewlyAcked(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(2)
+			_, err := handler.ReceivedAck(&wire.AckFrame{AckRanges: []wire.AckRange{{Largest: 15, Smallest: 10}}, ECT0: 4, ECNCE: 2}, protocol.Encryption1RTT, time.Now())
+			Expect(err).ToNot(HaveOccurred())
+			stats := handler.Stats()
+			Expect(stats.AckedECT0).To(BeEquivalentTo(4))
+			Expect(stats.AckedECT1).To(BeZero())
+			Expect(stats.AckedECNCE).To(BeEquivalentTo(2))
+			// reordered ACKs are ignored
+			_, err = handler.ReceivedAck(&wire.AckFrame{AckRanges: []wire.AckRange{{Largest: 14, Smallest: 10}}, ECT0: 3, ECNCE: 2}, protocol.Encryption1RTT, time.Now())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(handler.Stats().AckedECT0).To(BeEquivalentTo(4))
+			_, err = handler.ReceivedAck(&wire.AckFrame{AckRanges: []wire.AckRange{{Largest: 19, Smallest: 10}}, ECT0: 7, ECNCE: 3}, protocol.Encryption1RTT, time.Now())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(handler.Stats().AckedECT0).To(BeEquivalentTo(7))
+			Expect(handler.Stats().AckedECNCE).To(BeEquivalentTo(3))
+		})
+	})
+
+	Context("ECN-triggered congestion response", func() {
+		JustBeforeEach(func() {
+			rttStats := utils.NewRTTStats()
+			rttStats.UpdateRTT(100*time.Millisecond, 0, time.Now())
+			handler = newSentPacketHandler(0, protocol.InitialPacketSize, rttStats, nil, false, true, false, perspective, nil, utils.DefaultClock{}, utils.DefaultLogger)
+		})
+
+		sendPackets := func(from, to protocol.PacketNumber) {
+			for pn := from; pn <= to; pn++ {
+				ecn := handler.ECNMode(true)
+				Expect(ecn).To(Equal(protocol.ECT0))
+				handler.SentPacket(time.Now(), pn, -1, []StreamFrame{{Frame: &streamFrame}}, nil, protocol.Encryption1RTT, ecn, 1200, false)
+			}
+		}
+
+		It("reduces the congestion window when the peer reports CE-marked packets", func() {
+			sendPackets(0, 9)
+			_, err := handler.ReceivedAck(&wire.AckFrame{AckRanges: []wire.AckRange{{Largest: 9, Smallest: 0}}, ECT0: 10}, protocol.Encryption1RTT, time.Now())
+			Expect(err).ToNot(HaveOccurred())
+			sendPackets(10, 14)
+			cwnd := handler.Stats().CongestionWindow
+			_, err = handler.ReceivedAck(&wire.AckFrame{AckRanges: []wire.AckRange{{Largest: 14, Smallest: 0}}, ECT0: 13, ECNCE: 2}, protocol.Encryption1RTT, time.Now())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(handler.Stats().CongestionWindow).To(BeNumerically("<", cwnd))
+			Expect(handler.Stats().AckedECNCE).To(BeEquivalentTo(2))
+			Expect(handler.Stats().PacketsLost).To(BeZero())
+		})
+
+		It("doesn't mark packets if ECN is disabled", func() {
+			handler = newSentPacketHandler(0, protocol.InitialPacketSize, utils.NewRTTStats(), nil, false, false, false, perspective, nil, utils.DefaultClock{}, utils.DefaultLogger)
+			Expect(handler.ECNMode(true)).To(Equal(protocol.ECNUnsupported))
+			handler.SentPacket(time.Now(), 0, -1, []StreamFrame{{Frame: &streamFrame}}, nil, protocol.Encryption1RTT, protocol.ECNUnsupported, 1200, false)
+			cwnd := handler.Stats().CongestionWindow
+			// CE counts reported by the peer are ignored
+			_, err := handler.ReceivedAck(&wire.AckFrame{AckRanges: []wire.AckRange{{Largest: 0, Smallest: 0}}, ECNCE: 1}, protocol.Encryption1RTT, time.Now())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(handler.Stats().CongestionWindow).To(BeNumerically(">=", cwnd))
+			Expect(handler.Stats().SentECT0).To(BeZero())
+		})
 	})
 })
@@ -16,7 +16,6 @@ const (
 	maxBurstPackets            = 3
 	renoBeta                   = 0.7 // Reno backoff factor.
 	minCongestionWindowPackets = 2
-	initialCongestionWindow    = 32
 )
 
 type cubicSender struct {
@@ -24,6 +23,7 @@ type cubicSender struct {
 	rttStats        *utils.RTTStats
 	cubic           *Cubic
 	pacer           *pacer
+	deliveryRate    *deliveryRateSampler
 	clock           Clock
 
 	reno bool
@@ -53,6 +53,10 @@ type cubicSender struct {
 	initialCongestionWindow    protocol.ByteCount
 	initialMaxCongestionWindow protocol.ByteCount
 
+	// Limits for the congestion window, in bytes.
+	// If 0, the limits are derived from the maximum datagram size.
+	minCwnd, maxCwnd protocol.ByteCount
+
 	maxDatagramSize protocol.ByteCount
 
 	lastState logging.CongestionState
@@ -62,13 +66,16 @@ type cubicSender struct {
 var (
 	_ SendAlgorithm               = &cubicSender{}
 	_ SendAlgorithmWithDebugInfos = &cubicSender{}
+	_ DeliveryRateEstimator       = &cubicSender{}
 )
 
-// NewCubicSender makes a new cubic sender
+// NewCubicSender makes a new cubic sender.
+// The initial congestion window is given in bytes.
 func NewCubicSender(
 	clock Clock,
 	rttStats *utils.RTTStats,
 	initialMaxDatagramSize protocol.ByteCount,
+	initialCongestionWindow protocol.ByteCount,
 	reno bool,
 	tracer *logging.ConnectionTracer,
 ) *cubicSender {
@@ -77,12 +84,36 @@ func NewCubicSender(
 		rttStats,
 		reno,
 		initialMaxDatagramSize,
-		initialCongestionWindow*initialMaxDatagramSize,
+		initialCongestionWindow,
 		protocol.MaxCongestionWindowPackets*initialMaxDatagramSize,
 		tracer,
 	)
 }
 
+// NewCubicSenderWithLimits makes a new cubic sender that keeps the congestion window
+// between minCongestionWindow and maxCongestionWindow, given in bytes.
+// A limit of 0 means that the default limit is used.
+// The minimum congestion window is never smaller than 2 packets.
+func NewCubicSenderWithLimits(
+	clock Clock,
+	rttStats *utils.RTTStats,
+	initialMaxDatagramSize protocol.ByteCount,
+	initialCongestionWindow protocol.ByteCount,
+	minCongestionWindow, maxCongestionWindow protocol.ByteCount,
+	reno bool,
+	tracer *logging.ConnectionTracer,
+) *cubicSender {
+	c := NewCubicSender(clock, rttStats, initialMaxDatagramSize, initialCongestionWindow, reno, tracer)
+	c.minCwnd = minCongestionWindow
+	c.maxCwnd = maxCongestionWindow
+	if maxCongestionWindow > 0 {
+		c.initialMaxCongestionWindow = c.maxCongestionWindow()
+	}
+	c.initialCongestionWindow = min(max(initialCongestionWindow, c.minCongestionWindow()), c.maxCongestionWindow())
+	c.congestionWindow = c.initialCongestionWindow
+	return c
+}
+
 func newCubicSender(
 	clock Clock,
 	rttStats *utils.RTTStats,
@@ -102,6 +133,7 @@ func newCubicSender(
 		congestionWindow:           initialCongestionWindow,
 		slowStartThreshold:         protocol.MaxByteCount,
 		cubic:                      NewCubic(clock),
+		deliveryRate:               newDeliveryRateSampler(rttStats),
 		clock:                      clock,
 		reno:                       reno,
 		tracer:                     tracer,
@@ -125,11 +157,14 @@ func (c *cubicSender) HasPacingBudget(now time.Time) bool {
 }
 
 func (c *cubicSender) maxCongestionWindow() protocol.ByteCount {
+	if c.maxCwnd > 0 {
+		return max(c.maxCwnd, c.minCongestionWindow())
+	}
 	return c.maxDatagramSize * protocol.MaxCongestionWindowPackets
 }
 
 func (c *cubicSender) minCongestionWindow() protocol.ByteCount {
-	return c.maxDatagramSize * minCongestionWindowPackets
+	return max(c.minCwnd, c.maxDatagramSize*minCongestionWindowPackets)
 }
 
 func (c *cubicSender) OnPacketSent(
@@ -179,6 +214,7 @@ func (c *cubicSender) OnPacketAcked(
 	eventTime time.Time,
 ) {
 	c.largestAckedPacketNumber = max(ackedPacketNumber, c.largestAckedPacketNumber)
+	c.deliveryRate.OnPacketAcked(ackedBytes, eventTime)
 	if c.InRecovery() {
 		return
 	}
@@ -232,7 +268,7 @@ func (c *cubicSender) maybeIncreaseCwnd(
 	}
 	if c.InSlowStart() {
 		// TCP slow start, exponential growth, increase by one for each ACK.
-		c.congestionWindow += c.maxDatagramSize
+		c.congestionWindow = min(c.congestionWindow+c.maxDatagramSize, c.maxCongestionWindow())
 		c.maybeTraceStateChange(logging.CongestionStateSlowStart)
 		return
 	}
@@ -242,7 +278,7 @@ func (c *cubicSender) maybeIncreaseCwnd(
 		// Classic Reno congestion avoidance.
 		c.numAckedPackets++
 		if c.numAckedPackets >= uint64(c.congestionWindow/c.maxDatagramSize) {
-			c.congestionWindow += c.maxDatagramSize
+			c.congestionWindow = min(c.congestionWindow+c.maxDatagramSize, c.maxCongestionWindow())
 			c.numAckedPackets = 0
 		}
 	} else {
@@ -260,6 +296,11 @@ func (c *cubicSender) isCwndLimited(bytesInFlight protocol.ByteCount) bool {
 	return slowStartLimited || availableBytes <= maxBurstPackets*c.maxDatagramSize
 }
 
+// DeliveryRate returns the current estimate of the delivery rate, in bytes/s
+func (c *cubicSender) DeliveryRate() (uint64, bool) {
+	return c.deliveryRate.DeliveryRate()
+}
+
 // BandwidthEstimate returns the current bandwidth estimate
 func (c *cubicSender) BandwidthEstimate() Bandwidth {
 	srtt := c.rttStats.SmoothedRTT()
@@ -108,6 +108,14 @@ var _ = Describe("Cubic Sender", func() {
 		Expect(sender.CanSend(bytesInFlight)).To(BeFalse())
 	})
 
+	It("uses the configured initial congestion window", func() {
+		sender = NewCubicSender(&clock, rttStats, maxDatagramSize, 100*maxDatagramSize, true, nil)
+		Expect(sender.GetCongestionWindow()).To(Equal(100 * maxDatagramSize))
+		// The whole first flight can be sent before receiving an ACK.
+		Expect(SendAvailableSendWindow()).To(Equal(100))
+		Expect(sender.CanSend(bytesInFlight)).To(BeFalse())
+	})
+
 	It("paces", func() {
 		rttStats.UpdateRTT(10*time.Millisecond, 0, time.Now())
 		clock.Advance(time.Hour)
@@ -482,10 +490,72 @@ var _ = Describe("Cubic Sender", func() {
 			sender.OnPacketAcked(protocol.PacketNumber(i), packetSize, sender.GetCongestionWindow(), clock.Now())
 		}
 		const maxCwnd = protocol.MaxCongestionWindowPackets * packetSize
-		Expect(sender.GetCongestionWindow()).To(And(
-			BeNumerically(">", maxCwnd),
-			BeNumerically("<=", maxCwnd+packetSize),
-		))
+		Expect(sender.GetCongestionWindow()).To(Equal(maxCwnd))
+	})
+
+	Context("congestion window limits", func() {
+		const (
+			minCwnd = 10 * maxDatagramSize
+			maxCwnd = 50 * maxDatagramSize
+		)
+
+		BeforeEach(func() {
+			sender = NewCubicSenderWithLimits(&clock, rttStats, maxDatagramSize, initialCongestionWindowPackets*maxDatagramSize, minCwnd, maxCwnd, true, nil)
+		})
+
+		It("clamps the initial congestion window", func() {
+			sender = NewCubicSenderWithLimits(&clock, rttStats, maxDatagramSize, 100*maxDatagramSize, minCwnd, maxCwnd, true, nil)
+			Expect(sender.GetCongestionWindow()).To(Equal(maxCwnd))
+			sender = NewCubicSenderWithLimits(&clock, rttStats, maxDatagramSize, 5*maxDatagramSize, minCwnd, maxCwnd, true, nil)
+			Expect(sender.GetCongestionWindow()).To(Equal(minCwnd))
+		})
+
+		It("doesn't exceed the maximum congestion window in slow start", func() {
+			for i := 0; i < 10; i++ {
+				SendAvailableSendWindow()
+				AckNPackets(2 * int(sender.GetCongestionWindow()/maxDatagramSize))
+				Expect(sender.GetCongestionWindow()).To(BeNumerically("<=", maxCwnd))
+			}
+			Expect(sender.InSlowStart()).To(BeTrue())
+			Expect(sender.GetCongestionWindow()).To(Equal(maxCwnd))
+		})
+
+		It("doesn't exceed the maximum congestion window in congestion avoidance", func() {
+			sender = NewCubicSenderWithLimits(&clock, rttStats, maxDatagramSize, initialCongestionWindowPackets*maxDatagramSize, minCwnd, maxCwnd, false, nil)
+			SendAvailableSendWindow()
+			LoseNPackets(1)
+			Expect(sender.InSlowStart()).To(BeFalse())
+			for i := 0; i < 100; i++ {
+				clock.Advance(100 * time.Millisecond)
+				SendAvailableSendWindow()
+				AckNPackets(int(bytesInFlight / maxDatagramSize))
+				Expect(sender.GetCongestionWindow()).To(BeNumerically("<=", maxCwnd))
+			}
+			Expect(sender.InSlowStart()).To(BeFalse())
+			Expect(sender.GetCongestionWindow()).To(Equal(maxCwnd))
+		})
+
+		It("doesn't reduce the congestion window below the minimum in recovery", func() {
+			SendAvailableSendWindow()
+			for i := 0; i < 10; i++ {
+				LoseNPackets(1)
+				// a new loss event is only detected for packets sent after the last cutback
+				SendAvailableSendWindow()
+				Expect(sender.GetCongestionWindow()).To(BeNumerically(">=", minCwnd))
+			}
+			Expect(sender.GetCongestionWindow()).To(Equal(minCwnd))
+		})
+
+		It("doesn't reduce the congestion window below the minimum on a retransmission timeout", func() {
+			sender.OnRetransmissionTimeout(true)
+			Expect(sender.GetCongestionWindow()).To(Equal(minCwnd))
+		})
+
+		It("never reduces the minimum below 2 packets", func() {
+			sender = NewCubicSenderWithLimits(&clock, rttStats, maxDatagramSize, initialCongestionWindowPackets*maxDatagramSize, 1, maxCwnd, true, nil)
+			sender.OnRetransmissionTimeout(true)
+			Expect(sender.GetCongestionWindow()).To(Equal(2 * maxDatagramSize))
+		})
 	})
 
 	It("limit cwnd increase in congestion avoidance", func() {
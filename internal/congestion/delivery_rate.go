@@ -0,0 +1,70 @@
+package congestion
+
+import (
+	"time"
+
+	"github.com/quic-go/quic-go/internal/protocol"
+	"github.com/quic-go/quic-go/internal/utils"
+)
+
+// minDeliveryRateInterval is the minimum duration over which acknowledged bytes are accumulated
+// before a delivery rate sample is taken.
+// This prevents bursts of ACKs from producing inflated samples early in the connection.
+const minDeliveryRateInterval = 10 * time.Millisecond
+
+// deliveryRateSampler estimates the delivery rate from the number of bytes acknowledged
+// in the most recent sampling interval.
+// Each interval lasts (at least) one smoothed RTT.
+type deliveryRateSampler struct {
+	rttStats *utils.RTTStats
+
+	intervalStart time.Time
+	bytesAcked    protocol.ByteCount
+
+	// the bytes acknowledged in the last completed interval, and its duration
+	sampleBytes    protocol.ByteCount
+	sampleDuration time.Duration
+	rate           Bandwidth
+	hasRate        bool
+}
+
+func newDeliveryRateSampler(rttStats *utils.RTTStats) *deliveryRateSampler {
+	return &deliveryRateSampler{rttStats: rttStats}
+}
+
+func (s *deliveryRateSampler) OnPacketAcked(ackedBytes protocol.ByteCount, eventTime time.Time) {
+	// The first ACK frame only starts the sampling interval:
+	// We don't know when the bytes it acknowledges were sent.
+	if s.intervalStart.IsZero() {
+		s.intervalStart = eventTime
+		return
+	}
+	// All packets acknowledged by a single ACK frame are reported with the same event time.
+	// If that ACK frame completed the last interval, the remaining packets belong to that interval as well.
+	if eventTime.Equal(s.intervalStart) {
+		if s.hasRate {
+			s.sampleBytes += ackedBytes
+			s.rate = BandwidthFromDelta(s.sampleBytes, s.sampleDuration)
+		}
+		return
+	}
+	s.bytesAcked += ackedBytes
+	elapsed := eventTime.Sub(s.intervalStart)
+	if elapsed < max(s.rttStats.SmoothedRTT(), minDeliveryRateInterval) {
+		return
+	}
+	s.sampleBytes = s.bytesAcked
+	s.sampleDuration = elapsed
+	s.rate = BandwidthFromDelta(s.sampleBytes, s.sampleDuration)
+	s.hasRate = true
+	s.intervalStart = eventTime
+	s.bytesAcked = 0
+}
+
+// DeliveryRate returns the delivery rate measured in the last completed sampling interval, in bytes/s.
+func (s *deliveryRateSampler) DeliveryRate() (uint64, bool) {
+	if !s.hasRate {
+		return 0, false
+	}
+	return uint64(s.rate / BytesPerSecond), true
+}
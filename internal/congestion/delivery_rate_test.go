@@ -0,0 +1,87 @@
+package congestion
+
+import (
+	"time"
+
+	"github.com/quic-go/quic-go/internal/utils"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Delivery Rate Sampler", func() {
+	var (
+		s        *deliveryRateSampler
+		rttStats *utils.RTTStats
+	)
+
+	BeforeEach(func() {
+		rttStats = utils.NewRTTStats()
+		s = newDeliveryRateSampler(rttStats)
+	})
+
+	It("doesn't have an estimate before the first interval is completed", func() {
+		now := time.Now()
+		_, ok := s.DeliveryRate()
+		Expect(ok).To(BeFalse())
+		s.OnPacketAcked(1000, now)
+		s.OnPacketAcked(1000, now.Add(time.Millisecond))
+		_, ok = s.DeliveryRate()
+		Expect(ok).To(BeFalse())
+	})
+
+	It("estimates the delivery rate over one RTT", func() {
+		rttStats.UpdateRTT(100*time.Millisecond, 0, time.Now())
+		now := time.Now()
+		s.OnPacketAcked(1000, now)
+		for i := 1; i <= 10; i++ {
+			s.OnPacketAcked(1000, now.Add(time.Duration(i)*10*time.Millisecond))
+		}
+		// 10 kB acknowledged in 100ms
+		rate, ok := s.DeliveryRate()
+		Expect(ok).To(BeTrue())
+		Expect(rate).To(BeEquivalentTo(100000))
+	})
+
+	It("only uses the most recent interval", func() {
+		rttStats.UpdateRTT(100*time.Millisecond, 0, time.Now())
+		now := time.Now()
+		s.OnPacketAcked(1000, now)
+		now = now.Add(100 * time.Millisecond)
+		s.OnPacketAcked(10000, now)
+		rate, ok := s.DeliveryRate()
+		Expect(ok).To(BeTrue())
+		Expect(rate).To(BeEquivalentTo(100000))
+		// the delivery rate drops
+		now = now.Add(100 * time.Millisecond)
+		s.OnPacketAcked(1000, now)
+		rate, ok = s.DeliveryRate()
+		Expect(ok).To(BeTrue())
+		Expect(rate).To(BeEquivalentTo(10000))
+	})
+
+	It("attributes all packets acknowledged by the same ACK frame to the same interval", func() {
+		rttStats.UpdateRTT(100*time.Millisecond, 0, time.Now())
+		now := time.Now()
+		s.OnPacketAcked(1000, now)
+		s.OnPacketAcked(1000, now) // acknowledged by the first ACK frame, ignored
+		now = now.Add(100 * time.Millisecond)
+		s.OnPacketAcked(1000, now)
+		s.OnPacketAcked(1000, now)
+		rate, ok := s.DeliveryRate()
+		Expect(ok).To(BeTrue())
+		Expect(rate).To(BeEquivalentTo(20000))
+	})
+
+	It("uses a minimum sampling interval", func() {
+		now := time.Now()
+		s.OnPacketAcked(1000, now)
+		s.OnPacketAcked(1000, now.Add(minDeliveryRateInterval/2))
+		_, ok := s.DeliveryRate()
+		Expect(ok).To(BeFalse())
+		s.OnPacketAcked(1000, now.Add(minDeliveryRateInterval))
+		rate, ok := s.DeliveryRate()
+		Expect(ok).To(BeTrue())
+		Expect(rate).To(BeEquivalentTo(uint64(2000 * time.Second / minDeliveryRateInterval)))
+	})
+})
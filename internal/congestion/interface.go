@@ -26,3 +26,10 @@ type SendAlgorithmWithDebugInfos interface {
 	InRecovery() bool
 	GetCongestionWindow() protocol.ByteCount
 }
+
+// A DeliveryRateEstimator is a SendAlgorithm that estimates the rate at which data is delivered to the peer
+type DeliveryRateEstimator interface {
+	// DeliveryRate returns the current delivery rate estimate, in bytes/s.
+	// It returns false if no estimate is available (yet).
+	DeliveryRate() (bytesPerSecond uint64, ok bool)
+}
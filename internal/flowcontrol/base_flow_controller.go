@@ -29,6 +29,7 @@ type baseFlowController struct {
 	epochStartOffset protocol.ByteCount
 	rttStats         *utils.RTTStats
 
+	clock  utils.Clock
 	logger utils.Logger
 }
 
@@ -69,7 +70,7 @@ func (c *baseFlowController) addBytesRead(n protocol.ByteCount) {
 	// pretend we sent a WindowUpdate when reading the first byte
 	// this way auto-tuning of the window size already works for the first WindowUpdate
 	if c.bytesRead == 0 {
-		c.startNewAutoTuningEpoch(time.Now())
+		c.startNewAutoTuningEpoch(c.clock.Now())
 	}
 	c.bytesRead += n
 }
@@ -88,10 +89,15 @@ func (c *baseFlowController) getWindowUpdate() protocol.ByteCount {
 	}
 
 	c.maybeAdjustWindowSize()
-	c.receiveWindow = c.bytesRead + c.receiveWindowSize
+	c.receiveWindow = min(c.bytesRead+c.receiveWindowSize, protocol.MaxByteCount)
 	return c.receiveWindow
 }
 
+// clampWindowSize limits the window size, such that the resulting offset can still be encoded as a varint.
+func (c *baseFlowController) clampWindowSize(size protocol.ByteCount) protocol.ByteCount {
+	return min(size, protocol.MaxByteCount-c.bytesRead)
+}
+
 // maybeAdjustWindowSize increases the receiveWindowSize if we're sending updates too often.
 // For details about auto-tuning, see https://docs.google.com/document/d/1SExkMmGiz8VYzV3s9E35JQlJ73vhzCekKkDi85F1qCE/edit?usp=sharing.
 func (c *baseFlowController) maybeAdjustWindowSize() {
@@ -106,7 +112,7 @@ func (c *baseFlowController) maybeAdjustWindowSize() {
 	}
 
 	fraction := float64(bytesReadInEpoch) / float64(c.receiveWindowSize)
-	now := time.Now()
+	now := c.clock.Now()
 	if now.Sub(c.epochStartTime) < time.Duration(4*fraction*float64(rtt)) {
 		// window is consumed too fast, try to increase the window size
 		newSize := min(2*c.receiveWindowSize, c.maxReceiveWindowSize)
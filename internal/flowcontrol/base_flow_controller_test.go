@@ -30,6 +30,7 @@ var _ = Describe("Base Flow controller", func() {
 	BeforeEach(func() {
 		controller = &baseFlowController{}
 		controller.rttStats = &utils.RTTStats{}
+		controller.clock = utils.DefaultClock{}
 	})
 
 	Context("send flow control", func() {
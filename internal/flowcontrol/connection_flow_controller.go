@@ -3,7 +3,6 @@ package flowcontrol
 import (
 	"errors"
 	"fmt"
-	"time"
 
 	"github.com/quic-go/quic-go/internal/protocol"
 	"github.com/quic-go/quic-go/internal/qerr"
@@ -26,6 +25,7 @@ func NewConnectionFlowController(
 	queueWindowUpdate func(),
 	allowWindowIncrease func(size protocol.ByteCount) bool,
 	rttStats *utils.RTTStats,
+	clock utils.Clock,
 	logger utils.Logger,
 ) ConnectionFlowController {
 	return &connectionFlowController{
@@ -35,6 +35,7 @@ func NewConnectionFlowController(
 			receiveWindowSize:    receiveWindow,
 			maxReceiveWindowSize: maxReceiveWindow,
 			allowWindowIncrease:  allowWindowIncrease,
+			clock:                clock,
 			logger:               logger,
 		},
 		queueWindowUpdate: queueWindowUpdate,
@@ -81,6 +82,33 @@ func (c *connectionFlowController) GetWindowUpdate() protocol.ByteCount {
 	return offset
 }
 
+func (c *connectionFlowController) UnreadBytes() protocol.ByteCount {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.highestReceived - c.bytesRead
+}
+
+// SetReceiveWindowSize raises the receive window size, and with it the floor for auto-tuning.
+// If the window size exceeds the configured maximum, auto-tuning may grow the window up to the new size.
+// If the larger window size warrants it, a window update is queued right away.
+func (c *connectionFlowController) SetReceiveWindowSize(size protocol.ByteCount) {
+	c.mutex.Lock()
+	size = c.clampWindowSize(size)
+	if size <= c.receiveWindowSize {
+		c.mutex.Unlock()
+		return
+	}
+	c.logger.Debugf("Setting receive flow control window for the connection to %d kB", size/(1<<10))
+	c.receiveWindowSize = size
+	c.maxReceiveWindowSize = max(c.maxReceiveWindowSize, size)
+	shouldQueueWindowUpdate := c.hasWindowUpdate()
+	c.mutex.Unlock()
+	if shouldQueueWindowUpdate {
+		c.queueWindowUpdate()
+	}
+}
+
 // EnsureMinimumWindowSize sets a minimum window size
 // it should make sure that the connection-level window is increased when a stream-level window grows
 func (c *connectionFlowController) EnsureMinimumWindowSize(inc protocol.ByteCount) {
@@ -91,7 +119,7 @@ func (c *connectionFlowController) EnsureMinimumWindowSize(inc protocol.ByteCoun
 		if delta := newSize - c.receiveWindowSize; delta > 0 && c.allowWindowIncrease(delta) {
 			c.receiveWindowSize = newSize
 		}
-		c.startNewAutoTuningEpoch(time.Now())
+		c.startNewAutoTuningEpoch(c.clock.Now())
 	}
 	c.mutex.Unlock()
 }
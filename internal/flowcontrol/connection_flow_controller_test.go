@@ -26,6 +26,7 @@ var _ = Describe("Connection Flow controller", func() {
 		queuedWindowUpdate = false
 		controller = &connectionFlowController{}
 		controller.rttStats = &utils.RTTStats{}
+		controller.clock = utils.DefaultClock{}
 		controller.logger = utils.DefaultLogger
 		controller.queueWindowUpdate = func() { queuedWindowUpdate = true }
 		controller.allowWindowIncrease = func(protocol.ByteCount) bool { return true }
@@ -44,6 +45,7 @@ var _ = Describe("Connection Flow controller", func() {
 				nil,
 				func(protocol.ByteCount) bool { return true },
 				rttStats,
+				utils.DefaultClock{},
 				utils.DefaultLogger).(*connectionFlowController)
 			Expect(fc.receiveWindow).To(Equal(receiveWindow))
 			Expect(fc.maxReceiveWindowSize).To(Equal(maxReceiveWindow))
@@ -57,6 +59,18 @@ var _ = Describe("Connection Flow controller", func() {
 			Expect(controller.highestReceived).To(Equal(protocol.ByteCount(1337 + 123)))
 		})
 
+		It("tracks the number of unread bytes", func() {
+			controller.receiveWindow = 1000
+			controller.receiveWindowSize = 1000
+			Expect(controller.UnreadBytes()).To(BeZero())
+			Expect(controller.IncrementHighestReceived(600)).To(Succeed())
+			Expect(controller.UnreadBytes()).To(Equal(protocol.ByteCount(600)))
+			controller.AddBytesRead(200)
+			Expect(controller.UnreadBytes()).To(Equal(protocol.ByteCount(400)))
+			controller.AddBytesRead(400)
+			Expect(controller.UnreadBytes()).To(BeZero())
+		})
+
 		Context("getting window updates", func() {
 			BeforeEach(func() {
 				controller.receiveWindow = 100
@@ -85,6 +99,35 @@ var _ = Describe("Connection Flow controller", func() {
 				Expect(offset).To(Equal(oldOffset + dataRead + 60))
 			})
 
+			It("increases the window size, and queues a window update", func() {
+				controller.SetReceiveWindowSize(500)
+				Expect(queuedWindowUpdate).To(BeTrue())
+				Expect(controller.receiveWindowSize).To(BeEquivalentTo(500))
+				Expect(controller.GetWindowUpdate()).To(BeEquivalentTo(40 + 500))
+			})
+
+			It("never decreases the window size", func() {
+				controller.SetReceiveWindowSize(50)
+				Expect(queuedWindowUpdate).To(BeFalse())
+				Expect(controller.receiveWindowSize).To(BeEquivalentTo(60))
+				Expect(controller.GetWindowUpdate()).To(BeZero())
+			})
+
+			It("allows auto-tuning up to a window size larger than the configured maximum", func() {
+				controller.SetReceiveWindowSize(20000)
+				Expect(controller.receiveWindowSize).To(BeEquivalentTo(20000))
+				Expect(controller.maxReceiveWindowSize).To(BeEquivalentTo(20000))
+			})
+
+			It("limits the window size to the maximum offset", func() {
+				controller.SetReceiveWindowSize(protocol.MaxByteCount)
+				Expect(controller.receiveWindowSize).To(Equal(protocol.MaxByteCount - 40))
+				Expect(controller.GetWindowUpdate()).To(Equal(protocol.MaxByteCount))
+				// the window can't grow beyond the maximum offset when more data is read
+				controller.AddBytesRead(10)
+				Expect(controller.GetWindowUpdate()).To(BeZero())
+			})
+
 			It("auto-tunes the window", func() {
 				var allowed protocol.ByteCount
 				controller.allowWindowIncrease = func(size protocol.ByteCount) bool {
@@ -20,6 +20,9 @@ type StreamFlowController interface {
 	// final has to be to true if this is the final offset of the stream,
 	// as contained in a STREAM frame with FIN bit, and the RESET_STREAM frame
 	UpdateHighestReceived(offset protocol.ByteCount, final bool) error
+	// SetReceiveWindowSize raises the receive window size to at least the given size.
+	// The window size is never decreased.
+	SetReceiveWindowSize(protocol.ByteCount)
 	// Abandon is called when reading from the stream is aborted early,
 	// and there won't be any further calls to AddBytesRead.
 	Abandon()
@@ -28,6 +31,13 @@ type StreamFlowController interface {
 // The ConnectionFlowController is the flow controller for the connection.
 type ConnectionFlowController interface {
 	flowController
+	// UnreadBytes returns the number of bytes received on all streams that haven't been read by the application yet.
+	// Data that was skipped because the application canceled reading, or because the peer reset the stream,
+	// is not included.
+	UnreadBytes() protocol.ByteCount
+	// SetReceiveWindowSize raises the receive window size to at least the given size.
+	// The window size is never decreased.
+	SetReceiveWindowSize(protocol.ByteCount)
 	Reset() error
 }
 
@@ -31,6 +31,7 @@ func NewStreamFlowController(
 	initialSendWindow protocol.ByteCount,
 	queueWindowUpdate func(protocol.StreamID),
 	rttStats *utils.RTTStats,
+	clock utils.Clock,
 	logger utils.Logger,
 ) StreamFlowController {
 	return &streamFlowController{
@@ -43,6 +44,7 @@ func NewStreamFlowController(
 			receiveWindowSize:    receiveWindow,
 			maxReceiveWindowSize: maxReceiveWindow,
 			sendWindow:           initialSendWindow,
+			clock:                clock,
 			logger:               logger,
 		},
 	}
@@ -118,6 +120,27 @@ func (c *streamFlowController) Abandon() {
 	}
 }
 
+// SetReceiveWindowSize raises the receive window size, and with it the floor for auto-tuning.
+// If the window size exceeds the configured maximum, auto-tuning may grow the window up to the new size.
+// If the larger window size warrants it, a window update is queued right away.
+func (c *streamFlowController) SetReceiveWindowSize(size protocol.ByteCount) {
+	c.mutex.Lock()
+	size = c.clampWindowSize(size)
+	if size <= c.receiveWindowSize {
+		c.mutex.Unlock()
+		return
+	}
+	c.logger.Debugf("Setting receive flow control window for stream %d to %d kB", c.streamID, size/(1<<10))
+	c.receiveWindowSize = size
+	c.maxReceiveWindowSize = max(c.maxReceiveWindowSize, size)
+	shouldQueueWindowUpdate := c.shouldQueueWindowUpdate()
+	c.mutex.Unlock()
+	c.connection.EnsureMinimumWindowSize(protocol.ByteCount(float64(size) * protocol.ConnectionFlowControlMultiplier))
+	if shouldQueueWindowUpdate {
+		c.queueWindowUpdate()
+	}
+}
+
 func (c *streamFlowController) AddBytesSent(n protocol.ByteCount) {
 	c.baseFlowController.AddBytesSent(n)
 	c.connection.AddBytesSent(n)
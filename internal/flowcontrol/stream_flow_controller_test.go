@@ -28,11 +28,13 @@ var _ = Describe("Stream Flow controller", func() {
 				func() {},
 				func(protocol.ByteCount) bool { return true },
 				rttStats,
+				utils.DefaultClock{},
 				utils.DefaultLogger,
 			).(*connectionFlowController),
 		}
 		controller.maxReceiveWindowSize = 10000
 		controller.rttStats = rttStats
+		controller.clock = utils.DefaultClock{}
 		controller.logger = utils.DefaultLogger
 		controller.queueWindowUpdate = func() { queuedWindowUpdate = true }
 	})
@@ -44,8 +46,8 @@ var _ = Describe("Stream Flow controller", func() {
 		const sendWindow protocol.ByteCount = 4000
 
 		It("sets the send and receive windows", func() {
-			cc := NewConnectionFlowController(0, 0, nil, func(protocol.ByteCount) bool { return true }, nil, utils.DefaultLogger)
-			fc := NewStreamFlowController(5, cc, receiveWindow, maxReceiveWindow, sendWindow, nil, rttStats, utils.DefaultLogger).(*streamFlowController)
+			cc := NewConnectionFlowController(0, 0, nil, func(protocol.ByteCount) bool { return true }, nil, utils.DefaultClock{}, utils.DefaultLogger)
+			fc := NewStreamFlowController(5, cc, receiveWindow, maxReceiveWindow, sendWindow, nil, rttStats, utils.DefaultClock{}, utils.DefaultLogger).(*streamFlowController)
 			Expect(fc.streamID).To(Equal(protocol.StreamID(5)))
 			Expect(fc.receiveWindow).To(Equal(receiveWindow))
 			Expect(fc.maxReceiveWindowSize).To(Equal(maxReceiveWindow))
@@ -59,8 +61,8 @@ var _ = Describe("Stream Flow controller", func() {
 				queued = true
 			}
 
-			cc := NewConnectionFlowController(receiveWindow, maxReceiveWindow, func() {}, func(protocol.ByteCount) bool { return true }, nil, utils.DefaultLogger)
-			fc := NewStreamFlowController(5, cc, receiveWindow, maxReceiveWindow, sendWindow, queueWindowUpdate, rttStats, utils.DefaultLogger).(*streamFlowController)
+			cc := NewConnectionFlowController(receiveWindow, maxReceiveWindow, func() {}, func(protocol.ByteCount) bool { return true }, nil, utils.DefaultClock{}, utils.DefaultLogger)
+			fc := NewStreamFlowController(5, cc, receiveWindow, maxReceiveWindow, sendWindow, queueWindowUpdate, rttStats, utils.DefaultClock{}, utils.DefaultLogger).(*streamFlowController)
 			fc.AddBytesRead(receiveWindow)
 			Expect(queued).To(BeTrue())
 		})
@@ -252,6 +254,52 @@ var _ = Describe("Stream Flow controller", func() {
 				Expect(offset).To(BeZero())
 			})
 		})
+
+		Context("setting the receive window size", func() {
+			BeforeEach(func() {
+				controller.receiveWindow = 100
+				controller.receiveWindowSize = 60
+				controller.bytesRead = 100 - 60
+				controller.connection.(*connectionFlowController).receiveWindowSize = 120
+			})
+
+			It("increases the window size, and queues a window update", func() {
+				controller.SetReceiveWindowSize(500)
+				Expect(queuedWindowUpdate).To(BeTrue())
+				Expect(controller.receiveWindowSize).To(BeEquivalentTo(500))
+				Expect(controller.GetWindowUpdate()).To(BeEquivalentTo(40 + 500))
+				Expect(controller.connection.(*connectionFlowController).receiveWindowSize).To(Equal(protocol.ByteCount(500 * protocol.ConnectionFlowControlMultiplier)))
+			})
+
+			It("never decreases the window size", func() {
+				controller.SetReceiveWindowSize(50)
+				Expect(queuedWindowUpdate).To(BeFalse())
+				Expect(controller.receiveWindowSize).To(BeEquivalentTo(60))
+				Expect(controller.GetWindowUpdate()).To(BeZero())
+			})
+
+			It("allows auto-tuning up to a window size larger than the configured maximum", func() {
+				controller.SetReceiveWindowSize(20000)
+				Expect(controller.receiveWindowSize).To(BeEquivalentTo(20000))
+				Expect(controller.maxReceiveWindowSize).To(BeEquivalentTo(20000))
+			})
+
+			It("limits the window size to the maximum offset", func() {
+				controller.SetReceiveWindowSize(protocol.MaxByteCount)
+				Expect(controller.receiveWindowSize).To(Equal(protocol.MaxByteCount - 40))
+				Expect(controller.GetWindowUpdate()).To(Equal(protocol.MaxByteCount))
+				// the window can't grow beyond the maximum offset when more data is read
+				controller.AddBytesRead(10)
+				Expect(controller.GetWindowUpdate()).To(BeZero())
+			})
+
+			It("doesn't queue a window update after a final offset was received", func() {
+				Expect(controller.UpdateHighestReceived(90, true)).To(Succeed())
+				controller.SetReceiveWindowSize(500)
+				Expect(queuedWindowUpdate).To(BeFalse())
+				Expect(controller.GetWindowUpdate()).To(BeZero())
+			})
+		})
 	})
 
 	Context("sending data", func() {
@@ -23,7 +23,7 @@ type quicVersionContextKey struct{}
 
 var QUICVersionContextKey = &quicVersionContextKey{}
 
-const clientSessionStateRevision = 4
+const clientSessionStateRevision = 5
 
 type cryptoSetup struct {
 	tlsConf *tls.Config
@@ -37,11 +37,15 @@ type cryptoSetup struct {
 	peerParams *wire.TransportParameters
 
 	zeroRTTParameters *wire.TransportParameters
+	zeroRTTALPN       string // the ALPN negotiated on the connection the session ticket was issued on
 	allow0RTT         bool
 
+	negotiatedALPN string // only set for the client, once the handshake completes
+
 	rttStats *utils.RTTStats
 
 	tracer *logging.ConnectionTracer
+	clock  utils.Clock
 	logger utils.Logger
 
 	perspective protocol.Perspective
@@ -74,6 +78,7 @@ func NewCryptoSetupClient(
 	enable0RTT bool,
 	rttStats *utils.RTTStats,
 	tracer *logging.ConnectionTracer,
+	clock utils.Clock,
 	logger utils.Logger,
 	version protocol.Version,
 ) CryptoSetup {
@@ -82,6 +87,7 @@ func NewCryptoSetupClient(
 		tp,
 		rttStats,
 		tracer,
+		clock,
 		logger,
 		protocol.PerspectiveClient,
 		version,
@@ -109,6 +115,7 @@ func NewCryptoSetupServer(
 	allow0RTT bool,
 	rttStats *utils.RTTStats,
 	tracer *logging.ConnectionTracer,
+	clock utils.Clock,
 	logger utils.Logger,
 	version protocol.Version,
 ) CryptoSetup {
@@ -117,6 +124,7 @@ func NewCryptoSetupServer(
 		tp,
 		rttStats,
 		tracer,
+		clock,
 		logger,
 		protocol.PerspectiveServer,
 		version,
@@ -134,6 +142,7 @@ func newCryptoSetup(
 	tp *wire.TransportParameters,
 	rttStats *utils.RTTStats,
 	tracer *logging.ConnectionTracer,
+	clock utils.Clock,
 	logger utils.Logger,
 	perspective protocol.Perspective,
 	version protocol.Version,
@@ -151,6 +160,7 @@ func newCryptoSetup(
 		ourParams:     tp,
 		rttStats:      rttStats,
 		tracer:        tracer,
+		clock:         clock,
 		logger:        logger,
 		perspective:   perspective,
 		version:       version,
@@ -171,6 +181,14 @@ func (h *cryptoSetup) SetLargest1RTTAcked(pn protocol.PacketNumber) error {
 	return h.aead.SetLargestAcked(pn)
 }
 
+func (h *cryptoSetup) InitiateKeyUpdate() error {
+	return h.aead.InitiateKeyUpdate()
+}
+
+func (h *cryptoSetup) CurrentKeyPhase() protocol.KeyPhase {
+	return h.aead.CurrentKeyPhase()
+}
+
 func (h *cryptoSetup) StartHandshake(ctx context.Context) error {
 	err := h.conn.Start(context.WithValue(ctx, QUICVersionContextKey, h.version))
 	if err != nil {
@@ -282,14 +300,16 @@ func (h *cryptoSetup) marshalDataForSessionState(earlyData bool) []byte {
 	b = quicvarint.Append(b, clientSessionStateRevision)
 	b = quicvarint.Append(b, uint64(h.rttStats.SmoothedRTT().Microseconds()))
 	if earlyData {
-		// only save the transport parameters for 0-RTT enabled session tickets
+		// only save the ALPN and the transport parameters for 0-RTT enabled session tickets
+		b = quicvarint.Append(b, uint64(len(h.negotiatedALPN)))
+		b = append(b, h.negotiatedALPN...)
 		return h.peerParams.MarshalForSessionTicket(b)
 	}
 	return b
 }
 
 func (h *cryptoSetup) handleDataFromSessionState(data []byte, earlyData bool) (allowEarlyData bool) {
-	rtt, tp, err := decodeDataFromSessionState(data, earlyData)
+	rtt, alpn, tp, err := decodeDataFromSessionState(data, earlyData)
 	if err != nil {
 		h.logger.Debugf("Restoring of transport parameters from session ticket failed: %s", err.Error())
 		return
@@ -300,34 +320,52 @@ func (h *cryptoSetup) handleDataFromSessionState(data []byte, earlyData bool) (a
 	// Only use them if 0-RTT is actually used on the new connection.
 	if tp != nil && h.allow0RTT {
 		h.zeroRTTParameters = tp
+		h.zeroRTTALPN = alpn
 		return true
 	}
 	return false
 }
 
-func decodeDataFromSessionState(b []byte, earlyData bool) (time.Duration, *wire.TransportParameters, error) {
+// ValidateDataFromSessionState checks that the data saved in a client's session state can be restored.
+// For 0-RTT enabled session states, this includes the transport parameters sent by the server.
+func ValidateDataFromSessionState(data []byte, earlyData bool) error {
+	_, _, _, err := decodeDataFromSessionState(data, earlyData)
+	return err
+}
+
+func decodeDataFromSessionState(b []byte, earlyData bool) (time.Duration, string, *wire.TransportParameters, error) {
 	ver, l, err := quicvarint.Parse(b)
 	if err != nil {
-		return 0, nil, err
+		return 0, "", nil, err
 	}
 	b = b[l:]
 	if ver != clientSessionStateRevision {
-		return 0, nil, fmt.Errorf("mismatching version. Got %d, expected %d", ver, clientSessionStateRevision)
+		return 0, "", nil, fmt.Errorf("mismatching version. Got %d, expected %d", ver, clientSessionStateRevision)
 	}
 	rttEncoded, l, err := quicvarint.Parse(b)
 	if err != nil {
-		return 0, nil, err
+		return 0, "", nil, err
 	}
 	b = b[l:]
 	rtt := time.Duration(rttEncoded) * time.Microsecond
 	if !earlyData {
-		return rtt, nil, nil
+		return rtt, "", nil, nil
+	}
+	alpnLen, l, err := quicvarint.Parse(b)
+	if err != nil {
+		return 0, "", nil, err
+	}
+	b = b[l:]
+	if uint64(len(b)) < alpnLen {
+		return 0, "", nil, errors.New("invalid ALPN length")
 	}
+	alpn := string(b[:alpnLen])
+	b = b[alpnLen:]
 	var tp wire.TransportParameters
 	if err := tp.UnmarshalFromSessionTicket(b); err != nil {
-		return 0, nil, err
+		return 0, "", nil, err
 	}
-	return rtt, &tp, nil
+	return rtt, alpn, &tp, nil
 }
 
 func (h *cryptoSetup) getDataForSessionTicket() []byte {
@@ -395,14 +433,36 @@ func (h *cryptoSetup) handleSessionTicket(sessionTicketData []byte, using0RTT bo
 
 // rejected0RTT is called for the client when the server rejects 0-RTT.
 func (h *cryptoSetup) rejected0RTT() {
-	h.logger.Debugf("0-RTT was rejected. Dropping 0-RTT keys.")
-
 	had0RTTKeys := h.zeroRTTSealer != nil
 	h.zeroRTTSealer = nil
 
 	if had0RTTKeys {
-		h.events = append(h.events, Event{Kind: EventDiscard0RTTKeys})
+		reason := h.zeroRTTRejectionReason()
+		h.logger.Debugf("0-RTT was rejected (%s). Dropping 0-RTT keys.", reason)
+		h.events = append(h.events, Event{Kind: EventDiscard0RTTKeys, ZeroRTTRejectionReason: reason})
+	}
+}
+
+// zeroRTTRejectionReason infers why the server rejected 0-RTT.
+// The server doesn't tell us, so we compare the server's response to the state restored from the session ticket.
+func (h *cryptoSetup) zeroRTTRejectionReason() ZeroRTTRejectionReason {
+	// When 0-RTT is rejected in response to a HelloRetryRequest,
+	// we haven't received the server's EncryptedExtensions yet.
+	if h.peerParams == nil || h.zeroRTTParameters == nil {
+		return ZeroRTTRejectionReasonUnknown
+	}
+	// The TLS stack is blocked waiting for more handshake data, so it's safe to access the connection state.
+	state := h.conn.ConnectionState()
+	if !state.DidResume {
+		return ZeroRTTRejectionReasonSessionTicket
 	}
+	if state.NegotiatedProtocol != h.zeroRTTALPN {
+		return ZeroRTTRejectionReasonALPN
+	}
+	if !h.peerParams.ValidFor0RTT(h.zeroRTTParameters) {
+		return ZeroRTTRejectionReasonTransportParameters
+	}
+	return ZeroRTTRejectionReasonUnknown
 }
 
 func (h *cryptoSetup) setReadKey(el tls.QUICEncryptionLevel, suiteID uint16, trafficSecret []byte) {
@@ -520,7 +580,11 @@ func (h *cryptoSetup) DiscardInitialKeys() {
 }
 
 func (h *cryptoSetup) handshakeComplete() {
-	h.handshakeCompleteTime = time.Now()
+	h.handshakeCompleteTime = h.clock.Now()
+	if h.perspective == protocol.PerspectiveClient {
+		// remember the ALPN, so it can be saved in the session ticket
+		h.negotiatedALPN = h.conn.ConnectionState().NegotiatedProtocol
+	}
 	h.events = append(h.events, Event{Kind: EventHandshakeComplete})
 }
 
@@ -599,7 +663,7 @@ func (h *cryptoSetup) GetHandshakeOpener() (LongHeaderOpener, error) {
 }
 
 func (h *cryptoSetup) Get1RTTOpener() (ShortHeaderOpener, error) {
-	if h.zeroRTTOpener != nil && time.Since(h.handshakeCompleteTime) > 3*h.rttStats.PTO(true) {
+	if h.zeroRTTOpener != nil && h.clock.Now().Sub(h.handshakeCompleteTime) > 3*h.rttStats.PTO(true) {
 		h.zeroRTTOpener = nil
 		h.logger.Debugf("Dropping 0-RTT keys.")
 		if h.tracer != nil && h.tracer.DroppedEncryptionLevel != nil {
@@ -625,5 +689,9 @@ func wrapError(err error) error {
 	if alertErr := tls.AlertError(0); errors.As(err, &alertErr) && alertErr != 80 {
 		return qerr.NewLocalCryptoError(uint8(alertErr), err)
 	}
+	// Errors returned from callbacks might already be transport errors.
+	if transportErr := (&qerr.TransportError{}); errors.As(err, &transportErr) {
+		return transportErr
+	}
 	return &qerr.TransportError{ErrorCode: qerr.InternalError, ErrorMessage: err.Error()}
 }
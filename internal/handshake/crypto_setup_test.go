@@ -71,6 +71,7 @@ var _ = Describe("Crypto Setup TLS", func() {
 			false,
 			&utils.RTTStats{},
 			nil,
+			utils.DefaultClock{},
 			utils.DefaultLogger.WithPrefix("client"),
 			protocol.Version1,
 		)
@@ -92,6 +93,7 @@ var _ = Describe("Crypto Setup TLS", func() {
 			false,
 			&utils.RTTStats{},
 			nil,
+			utils.DefaultClock{},
 			utils.DefaultLogger.WithPrefix("server"),
 			protocol.Version1,
 		)
@@ -195,6 +197,7 @@ var _ = Describe("Crypto Setup TLS", func() {
 				enable0RTT,
 				clientRTTStats,
 				nil,
+				utils.DefaultClock{},
 				utils.DefaultLogger.WithPrefix("client"),
 				protocol.Version1,
 			)
@@ -212,6 +215,7 @@ var _ = Describe("Crypto Setup TLS", func() {
 				enable0RTT,
 				serverRTTStats,
 				nil,
+				utils.DefaultClock{},
 				utils.DefaultLogger.WithPrefix("server"),
 				protocol.Version1,
 			)
@@ -264,6 +268,7 @@ var _ = Describe("Crypto Setup TLS", func() {
 				false,
 				&utils.RTTStats{},
 				nil,
+				utils.DefaultClock{},
 				utils.DefaultLogger.WithPrefix("client"),
 				protocol.Version1,
 			)
@@ -283,6 +288,7 @@ var _ = Describe("Crypto Setup TLS", func() {
 				false,
 				&utils.RTTStats{},
 				nil,
+				utils.DefaultClock{},
 				utils.DefaultLogger.WithPrefix("server"),
 				protocol.Version1,
 			)
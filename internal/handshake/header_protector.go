@@ -25,17 +25,62 @@ func hkdfHeaderProtectionLabel(v protocol.Version) string {
 }
 
 func newHeaderProtector(suite *cipherSuite, trafficSecret []byte, isLongHeader bool, v protocol.Version) headerProtector {
-	hkdfLabel := hkdfHeaderProtectionLabel(v)
+	return newHeaderProtectorFromKey(suite, deriveHeaderProtectionKey(suite, trafficSecret, v), isLongHeader)
+}
+
+func deriveHeaderProtectionKey(suite *cipherSuite, trafficSecret []byte, v protocol.Version) []byte {
+	return hkdfExpandLabel(suite.Hash, trafficSecret, []byte{}, hkdfHeaderProtectionLabel(v), suite.KeyLen)
+}
+
+func newHeaderProtectorFromKey(suite *cipherSuite, hpKey []byte, isLongHeader bool) headerProtector {
 	switch suite.ID {
 	case tls.TLS_AES_128_GCM_SHA256, tls.TLS_AES_256_GCM_SHA384:
-		return newAESHeaderProtector(suite, trafficSecret, isLongHeader, hkdfLabel)
+		return newAESHeaderProtector(hpKey, isLongHeader)
 	case tls.TLS_CHACHA20_POLY1305_SHA256:
-		return newChaChaHeaderProtector(suite, trafficSecret, isLongHeader, hkdfLabel)
+		return newChaChaHeaderProtector(hpKey, isLongHeader)
 	default:
 		panic(fmt.Sprintf("Invalid cipher suite id: %d", suite.ID))
 	}
 }
 
+// HeaderProtector applies and removes header protection.
+// It is only exposed for testing and fuzzing, see the testutils package.
+type HeaderProtector interface {
+	headerProtector
+}
+
+// NewHeaderProtector creates a HeaderProtector from a header protection key.
+// It must not be used by the connection, which derives the header protection key from the traffic secret.
+func NewHeaderProtector(cipherSuiteID uint16, hpKey []byte, isLongHeader bool) (HeaderProtector, error) {
+	suite, err := lookupCipherSuite(cipherSuiteID)
+	if err != nil {
+		return nil, err
+	}
+	if len(hpKey) != suite.KeyLen {
+		return nil, fmt.Errorf("invalid header protection key length: %d (expected %d)", len(hpKey), suite.KeyLen)
+	}
+	return newHeaderProtectorFromKey(suite, hpKey, isLongHeader), nil
+}
+
+// DeriveHeaderProtectionKey derives the header protection key from a traffic secret.
+// It is only exposed for testing and fuzzing, see the testutils package.
+func DeriveHeaderProtectionKey(cipherSuiteID uint16, trafficSecret []byte, v protocol.Version) ([]byte, error) {
+	suite, err := lookupCipherSuite(cipherSuiteID)
+	if err != nil {
+		return nil, err
+	}
+	return deriveHeaderProtectionKey(suite, trafficSecret, v), nil
+}
+
+func lookupCipherSuite(id uint16) (*cipherSuite, error) {
+	switch id {
+	case tls.TLS_AES_128_GCM_SHA256, tls.TLS_AES_256_GCM_SHA384, tls.TLS_CHACHA20_POLY1305_SHA256:
+		return getCipherSuite(id), nil
+	default:
+		return nil, fmt.Errorf("unsupported cipher suite: %#x", id)
+	}
+}
+
 type aesHeaderProtector struct {
 	mask         [16]byte // AES always has a 16 byte block size
 	block        cipher.Block
@@ -44,8 +89,7 @@ type aesHeaderProtector struct {
 
 var _ headerProtector = &aesHeaderProtector{}
 
-func newAESHeaderProtector(suite *cipherSuite, trafficSecret []byte, isLongHeader bool, hkdfLabel string) headerProtector {
-	hpKey := hkdfExpandLabel(suite.Hash, trafficSecret, []byte{}, hkdfLabel, suite.KeyLen)
+func newAESHeaderProtector(hpKey []byte, isLongHeader bool) headerProtector {
 	block, err := aes.NewCipher(hpKey)
 	if err != nil {
 		panic(fmt.Sprintf("error creating new AES cipher: %s", err))
@@ -88,9 +132,7 @@ type chachaHeaderProtector struct {
 
 var _ headerProtector = &chachaHeaderProtector{}
 
-func newChaChaHeaderProtector(suite *cipherSuite, trafficSecret []byte, isLongHeader bool, hkdfLabel string) headerProtector {
-	hpKey := hkdfExpandLabel(suite.Hash, trafficSecret, []byte{}, hkdfLabel, suite.KeyLen)
-
+func newChaChaHeaderProtector(hpKey []byte, isLongHeader bool) headerProtector {
 	p := &chachaHeaderProtector{
 		isLongHeader: isLongHeader,
 	}
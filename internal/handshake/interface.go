@@ -4,6 +4,7 @@ import (
 	"context"
 	"crypto/tls"
 	"errors"
+	"fmt"
 	"io"
 	"time"
 
@@ -83,11 +84,46 @@ const (
 	EventHandshakeComplete
 )
 
+// ZeroRTTRejectionReason is the reason why the server rejected 0-RTT, as inferred by the client.
+type ZeroRTTRejectionReason uint8
+
+const (
+	// ZeroRTTRejectionReasonUnknown is used when the client can't infer why 0-RTT was rejected.
+	// For example, the server might not allow 0-RTT at the moment.
+	ZeroRTTRejectionReasonUnknown ZeroRTTRejectionReason = iota
+	// ZeroRTTRejectionReasonSessionTicket is used when the server didn't resume the session,
+	// for example because the session ticket expired or the server rotated its session ticket keys.
+	ZeroRTTRejectionReasonSessionTicket
+	// ZeroRTTRejectionReasonALPN is used when the server selected a different application protocol
+	// than on the connection that the session ticket was issued on.
+	ZeroRTTRejectionReasonALPN
+	// ZeroRTTRejectionReasonTransportParameters is used when the server reduced one of the limits
+	// of the transport parameters remembered from the connection that the session ticket was issued on.
+	ZeroRTTRejectionReasonTransportParameters
+)
+
+func (r ZeroRTTRejectionReason) String() string {
+	switch r {
+	case ZeroRTTRejectionReasonUnknown:
+		return "unknown"
+	case ZeroRTTRejectionReasonSessionTicket:
+		return "session ticket not accepted"
+	case ZeroRTTRejectionReasonALPN:
+		return "ALPN changed"
+	case ZeroRTTRejectionReasonTransportParameters:
+		return "transport parameters changed"
+	default:
+		return fmt.Sprintf("unknown reason: %d", r)
+	}
+}
+
 // Event is a handshake event.
 type Event struct {
 	Kind                EventKind
 	Data                []byte
 	TransportParameters *wire.TransportParameters
+	// ZeroRTTRejectionReason is only set for EventDiscard0RTTKeys.
+	ZeroRTTRejectionReason ZeroRTTRejectionReason
 }
 
 // CryptoSetup handles the handshake and protecting / unprotecting packets
@@ -104,6 +140,8 @@ type CryptoSetup interface {
 	DiscardInitialKeys()
 	SetHandshakeConfirmed()
 	ConnectionState() ConnectionState
+	InitiateKeyUpdate() error
+	CurrentKeyPhase() protocol.KeyPhase
 
 	GetInitialOpener() (LongHeaderOpener, error)
 	GetHandshakeOpener() (LongHeaderOpener, error)
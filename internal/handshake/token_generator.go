@@ -49,18 +49,20 @@ func NewTokenGenerator(key TokenProtectorKey) *TokenGenerator {
 	return &TokenGenerator{tokenProtector: newTokenProtector(key)}
 }
 
-// NewRetryToken generates a new token for a Retry for a given source address
+// NewRetryToken generates a new token for a Retry for a given source address.
+// now is the time the token is issued at.
 func (g *TokenGenerator) NewRetryToken(
 	raddr net.Addr,
 	origDestConnID protocol.ConnectionID,
 	retrySrcConnID protocol.ConnectionID,
+	now time.Time,
 ) ([]byte, error) {
 	data, err := asn1.Marshal(token{
 		IsRetryToken:             true,
 		RemoteAddr:               encodeRemoteAddr(raddr),
 		OriginalDestConnectionID: origDestConnID.Bytes(),
 		RetrySrcConnectionID:     retrySrcConnID.Bytes(),
-		Timestamp:                time.Now().UnixNano(),
+		Timestamp:                now.UnixNano(),
 	})
 	if err != nil {
 		return nil, err
@@ -68,11 +70,12 @@ func (g *TokenGenerator) NewRetryToken(
 	return g.tokenProtector.NewToken(data)
 }
 
-// NewToken generates a new token to be sent in a NEW_TOKEN frame
-func (g *TokenGenerator) NewToken(raddr net.Addr) ([]byte, error) {
+// NewToken generates a new token to be sent in a NEW_TOKEN frame.
+// now is the time the token is issued at.
+func (g *TokenGenerator) NewToken(raddr net.Addr, now time.Time) ([]byte, error) {
 	data, err := asn1.Marshal(token{
 		RemoteAddr: encodeRemoteAddr(raddr),
-		Timestamp:  time.Now().UnixNano(),
+		Timestamp:  now.UnixNano(),
 	})
 	if err != nil {
 		return nil, err
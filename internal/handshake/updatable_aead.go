@@ -5,7 +5,9 @@ import (
 	"crypto/cipher"
 	"crypto/tls"
 	"encoding/binary"
+	"errors"
 	"fmt"
+	"sync/atomic"
 	"time"
 
 	"github.com/quic-go/quic-go/internal/protocol"
@@ -63,6 +65,12 @@ type updatableAEAD struct {
 
 	// use a single slice to avoid allocations
 	nonceBuf []byte
+
+	// These are accessed from outside the connection's run loop,
+	// using InitiateKeyUpdate and CurrentKeyPhase.
+	keyUpdateRequested atomic.Bool
+	keyUpdateAllowed   atomic.Bool
+	currentKeyPhase    atomic.Uint64
 }
 
 var (
@@ -105,6 +113,10 @@ func (a *updatableAEAD) rollKeys() {
 	a.nextSendTrafficSecret = a.getNextTrafficSecret(a.suite.Hash, a.nextSendTrafficSecret)
 	a.nextRcvAEAD = createAEAD(a.suite, a.nextRcvTrafficSecret, a.version)
 	a.nextSendAEAD = createAEAD(a.suite, a.nextSendTrafficSecret, a.version)
+
+	a.currentKeyPhase.Store(uint64(a.keyPhase))
+	a.keyUpdateRequested.Store(false)
+	a.keyUpdateAllowed.Store(a.updateAllowed())
 }
 
 func (a *updatableAEAD) startKeyDropTimer(now time.Time) {
@@ -264,11 +276,36 @@ func (a *updatableAEAD) SetLargestAcked(pn protocol.PacketNumber) error {
 		}
 	}
 	a.largestAcked = pn
+	a.keyUpdateAllowed.Store(a.updateAllowed())
 	return nil
 }
 
 func (a *updatableAEAD) SetHandshakeConfirmed() {
 	a.handshakeConfirmed = true
+	a.keyUpdateAllowed.Store(a.updateAllowed())
+}
+
+// InitiateKeyUpdate requests a key update, which is performed when the next packet is sent.
+// It is safe to call from any goroutine.
+// A key update is only allowed after the handshake was confirmed,
+// and after a packet sent with the current key phase was acknowledged.
+func (a *updatableAEAD) InitiateKeyUpdate() error {
+	if !a.keyUpdateAllowed.Load() {
+		if a.currentKeyPhase.Load() == 0 {
+			return errors.New("key update not allowed before the handshake is confirmed")
+		}
+		return errors.New("key update not allowed before the previous key update was acknowledged")
+	}
+	if !a.keyUpdateRequested.CompareAndSwap(false, true) {
+		return errors.New("key update already requested")
+	}
+	return nil
+}
+
+// CurrentKeyPhase returns the current key phase, i.e. the number of key updates performed so far.
+// It is safe to call from any goroutine.
+func (a *updatableAEAD) CurrentKeyPhase() protocol.KeyPhase {
+	return protocol.KeyPhase(a.currentKeyPhase.Load())
 }
 
 func (a *updatableAEAD) updateAllowed() bool {
@@ -287,6 +324,10 @@ func (a *updatableAEAD) shouldInitiateKeyUpdate() bool {
 	if !a.updateAllowed() {
 		return false
 	}
+	if a.keyUpdateRequested.Load() {
+		a.logger.Debugf("Key update requested by the application. Initiating key update to the next key phase: %d", a.keyPhase+1)
+		return true
+	}
 	// Initiate the first key update shortly after the handshake, in order to exercise the key update mechanism.
 	if a.keyPhase == 0 {
 		if a.numRcvdWithCurrentKey >= FirstKeyUpdateInterval || a.numSentWithCurrentKey >= FirstKeyUpdateInterval {
@@ -529,6 +529,37 @@ var _ = Describe("Updatable AEAD", func() {
 									Expect(err).ToNot(HaveOccurred())
 								})
 							})
+
+							Context("application-initiated key updates", func() {
+								It("doesn't allow key updates before the handshake is confirmed", func() {
+									Expect(server.InitiateKeyUpdate()).To(MatchError("key update not allowed before the handshake is confirmed"))
+									Expect(server.KeyPhase()).To(Equal(protocol.KeyPhaseZero))
+								})
+
+								It("updates keys when the next packet is sent", func() {
+									server.SetHandshakeConfirmed()
+									Expect(server.CurrentKeyPhase()).To(BeZero())
+									Expect(server.InitiateKeyUpdate()).To(Succeed())
+									Expect(server.InitiateKeyUpdate()).To(MatchError("key update already requested"))
+									serverTracer.EXPECT().UpdatedKey(protocol.KeyPhase(1), false)
+									Expect(server.KeyPhase()).To(Equal(protocol.KeyPhaseOne))
+									Expect(server.CurrentKeyPhase()).To(BeEquivalentTo(1))
+									// the next key update is only allowed once a packet sent in the new key phase was acknowledged
+									server.Seal(nil, msg, 1, ad)
+									Expect(server.InitiateKeyUpdate()).To(MatchError("key update not allowed before the previous key update was acknowledged"))
+									Expect(server.KeyPhase()).To(Equal(protocol.KeyPhaseOne))
+									client.rollKeys()
+									b := client.Seal(nil, []byte("foobar"), 1, []byte("ad"))
+									_, err := server.Open(nil, b, time.Now(), 1, protocol.KeyPhaseOne, []byte("ad"))
+									Expect(err).ToNot(HaveOccurred())
+									Expect(server.SetLargestAcked(1)).To(Succeed())
+									Expect(server.InitiateKeyUpdate()).To(Succeed())
+									serverTracer.EXPECT().DroppedKey(protocol.KeyPhase(0))
+									serverTracer.EXPECT().UpdatedKey(protocol.KeyPhase(2), false)
+									Expect(server.KeyPhase()).To(Equal(protocol.KeyPhaseZero))
+									Expect(server.CurrentKeyPhase()).To(BeEquivalentTo(2))
+								})
+							})
 						})
 					})
 				})
@@ -8,7 +8,7 @@ import (
 
 // ConvertFrame converts a wire.Frame into a logging.Frame.
 // This makes it possible for external packages to access the frames.
-// Furthermore, it removes the data slices from CRYPTO and STREAM frames.
+// Furthermore, it removes the data slices from CRYPTO, STREAM, DATAGRAM and custom frames.
 func ConvertFrame(frame wire.Frame) logging.Frame {
 	switch f := frame.(type) {
 	case *wire.AckFrame:
@@ -31,6 +31,11 @@ func ConvertFrame(frame wire.Frame) logging.Frame {
 		return &logging.DatagramFrame{
 			Length: logging.ByteCount(len(f.Data)),
 		}
+	case *wire.CustomFrame:
+		return &logging.CustomFrame{
+			Type:   f.Type,
+			Length: logging.ByteCount(len(f.Data)),
+		}
 	default:
 		return logging.Frame(frame)
 	}
@@ -42,6 +42,14 @@ var _ = Describe("CRYPTO frame", func() {
 		Expect(df.Length).To(Equal(logging.ByteCount(6)))
 	})
 
+	It("converts custom frames", func() {
+		f := ConvertFrame(&wire.CustomFrame{Type: 0x1337, Data: []byte("foobar")})
+		Expect(f).To(BeAssignableToTypeOf(&logging.CustomFrame{}))
+		cf := f.(*logging.CustomFrame)
+		Expect(cf.Type).To(BeEquivalentTo(0x1337))
+		Expect(cf.Length).To(Equal(logging.ByteCount(6)))
+	})
+
 	It("converts other frames", func() {
 		f := ConvertFrame(&wire.MaxDataFrame{MaximumData: 1234})
 		Expect(f).To(BeAssignableToTypeOf(&logging.MaxDataFrame{}))
@@ -13,6 +13,7 @@ import (
 	reflect "reflect"
 	time "time"
 
+	ackhandler "github.com/quic-go/quic-go/internal/ackhandler"
 	protocol "github.com/quic-go/quic-go/internal/protocol"
 	wire "github.com/quic-go/quic-go/internal/wire"
 	gomock "go.uber.org/mock/gomock"
@@ -191,6 +192,78 @@ func (c *MockReceivedPacketHandlerIsPotentiallyDuplicateCall) DoAndReturn(f func
 	return c
 }
 
+// ReceivedAckFrequencyFrame mocks base method.
+func (m *MockReceivedPacketHandler) ReceivedAckFrequencyFrame(arg0 *wire.AckFrequencyFrame) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "ReceivedAckFrequencyFrame", arg0)
+}
+
+// ReceivedAckFrequencyFrame indicates an expected call of ReceivedAckFrequencyFrame.
+func (mr *MockReceivedPacketHandlerMockRecorder) ReceivedAckFrequencyFrame(arg0 any) *MockReceivedPacketHandlerReceivedAckFrequencyFrameCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReceivedAckFrequencyFrame", reflect.TypeOf((*MockReceivedPacketHandler)(nil).ReceivedAckFrequencyFrame), arg0)
+	return &MockReceivedPacketHandlerReceivedAckFrequencyFrameCall{Call: call}
+}
+
+// MockReceivedPacketHandlerReceivedAckFrequencyFrameCall wrap *gomock.Call
+type MockReceivedPacketHandlerReceivedAckFrequencyFrameCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockReceivedPacketHandlerReceivedAckFrequencyFrameCall) Return() *MockReceivedPacketHandlerReceivedAckFrequencyFrameCall {
+	c.Call = c.Call.Return()
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockReceivedPacketHandlerReceivedAckFrequencyFrameCall) Do(f func(*wire.AckFrequencyFrame)) *MockReceivedPacketHandlerReceivedAckFrequencyFrameCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockReceivedPacketHandlerReceivedAckFrequencyFrameCall) DoAndReturn(f func(*wire.AckFrequencyFrame)) *MockReceivedPacketHandlerReceivedAckFrequencyFrameCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// ReceivedImmediateAck mocks base method.
+func (m *MockReceivedPacketHandler) ReceivedImmediateAck() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "ReceivedImmediateAck")
+}
+
+// ReceivedImmediateAck indicates an expected call of ReceivedImmediateAck.
+func (mr *MockReceivedPacketHandlerMockRecorder) ReceivedImmediateAck() *MockReceivedPacketHandlerReceivedImmediateAckCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReceivedImmediateAck", reflect.TypeOf((*MockReceivedPacketHandler)(nil).ReceivedImmediateAck))
+	return &MockReceivedPacketHandlerReceivedImmediateAckCall{Call: call}
+}
+
+// MockReceivedPacketHandlerReceivedImmediateAckCall wrap *gomock.Call
+type MockReceivedPacketHandlerReceivedImmediateAckCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockReceivedPacketHandlerReceivedImmediateAckCall) Return() *MockReceivedPacketHandlerReceivedImmediateAckCall {
+	c.Call = c.Call.Return()
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockReceivedPacketHandlerReceivedImmediateAckCall) Do(f func()) *MockReceivedPacketHandlerReceivedImmediateAckCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockReceivedPacketHandlerReceivedImmediateAckCall) DoAndReturn(f func()) *MockReceivedPacketHandlerReceivedImmediateAckCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // ReceivedPacket mocks base method.
 func (m *MockReceivedPacketHandler) ReceivedPacket(arg0 protocol.PacketNumber, arg1 protocol.ECN, arg2 protocol.EncryptionLevel, arg3 time.Time, arg4 bool) error {
 	m.ctrl.T.Helper()
@@ -228,3 +301,41 @@ func (c *MockReceivedPacketHandlerReceivedPacketCall) DoAndReturn(f func(protoco
 	c.Call = c.Call.DoAndReturn(f)
 	return c
 }
+
+// Stats mocks base method.
+func (m *MockReceivedPacketHandler) Stats() ackhandler.ReceivedStats {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Stats")
+	ret0, _ := ret[0].(ackhandler.ReceivedStats)
+	return ret0
+}
+
+// Stats indicates an expected call of Stats.
+func (mr *MockReceivedPacketHandlerMockRecorder) Stats() *MockReceivedPacketHandlerStatsCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Stats", reflect.TypeOf((*MockReceivedPacketHandler)(nil).Stats))
+	return &MockReceivedPacketHandlerStatsCall{Call: call}
+}
+
+// MockReceivedPacketHandlerStatsCall wrap *gomock.Call
+type MockReceivedPacketHandlerStatsCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockReceivedPacketHandlerStatsCall) Return(arg0 ackhandler.ReceivedStats) *MockReceivedPacketHandlerStatsCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockReceivedPacketHandlerStatsCall) Do(f func() ackhandler.ReceivedStats) *MockReceivedPacketHandlerStatsCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockReceivedPacketHandlerStatsCall) DoAndReturn(f func() ackhandler.ReceivedStats) *MockReceivedPacketHandlerStatsCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
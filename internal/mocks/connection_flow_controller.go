@@ -264,6 +264,80 @@ func (c *MockConnectionFlowControllerSendWindowSizeCall) DoAndReturn(f func() pr
 	return c
 }
 
+// SetReceiveWindowSize mocks base method.
+func (m *MockConnectionFlowController) SetReceiveWindowSize(arg0 protocol.ByteCount) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetReceiveWindowSize", arg0)
+}
+
+// SetReceiveWindowSize indicates an expected call of SetReceiveWindowSize.
+func (mr *MockConnectionFlowControllerMockRecorder) SetReceiveWindowSize(arg0 any) *MockConnectionFlowControllerSetReceiveWindowSizeCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetReceiveWindowSize", reflect.TypeOf((*MockConnectionFlowController)(nil).SetReceiveWindowSize), arg0)
+	return &MockConnectionFlowControllerSetReceiveWindowSizeCall{Call: call}
+}
+
+// MockConnectionFlowControllerSetReceiveWindowSizeCall wrap *gomock.Call
+type MockConnectionFlowControllerSetReceiveWindowSizeCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockConnectionFlowControllerSetReceiveWindowSizeCall) Return() *MockConnectionFlowControllerSetReceiveWindowSizeCall {
+	c.Call = c.Call.Return()
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockConnectionFlowControllerSetReceiveWindowSizeCall) Do(f func(protocol.ByteCount)) *MockConnectionFlowControllerSetReceiveWindowSizeCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockConnectionFlowControllerSetReceiveWindowSizeCall) DoAndReturn(f func(protocol.ByteCount)) *MockConnectionFlowControllerSetReceiveWindowSizeCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// UnreadBytes mocks base method.
+func (m *MockConnectionFlowController) UnreadBytes() protocol.ByteCount {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UnreadBytes")
+	ret0, _ := ret[0].(protocol.ByteCount)
+	return ret0
+}
+
+// UnreadBytes indicates an expected call of UnreadBytes.
+func (mr *MockConnectionFlowControllerMockRecorder) UnreadBytes() *MockConnectionFlowControllerUnreadBytesCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnreadBytes", reflect.TypeOf((*MockConnectionFlowController)(nil).UnreadBytes))
+	return &MockConnectionFlowControllerUnreadBytesCall{Call: call}
+}
+
+// MockConnectionFlowControllerUnreadBytesCall wrap *gomock.Call
+type MockConnectionFlowControllerUnreadBytesCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockConnectionFlowControllerUnreadBytesCall) Return(arg0 protocol.ByteCount) *MockConnectionFlowControllerUnreadBytesCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockConnectionFlowControllerUnreadBytesCall) Do(f func() protocol.ByteCount) *MockConnectionFlowControllerUnreadBytesCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockConnectionFlowControllerUnreadBytesCall) DoAndReturn(f func() protocol.ByteCount) *MockConnectionFlowControllerUnreadBytesCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // UpdateSendWindow mocks base method.
 func (m *MockConnectionFlowController) UpdateSendWindow(arg0 protocol.ByteCount) bool {
 	m.ctrl.T.Helper()
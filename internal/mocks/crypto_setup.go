@@ -153,6 +153,44 @@ func (c *MockCryptoSetupConnectionStateCall) DoAndReturn(f func() handshake.Conn
 	return c
 }
 
+// CurrentKeyPhase mocks base method.
+func (m *MockCryptoSetup) CurrentKeyPhase() protocol.KeyPhase {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CurrentKeyPhase")
+	ret0, _ := ret[0].(protocol.KeyPhase)
+	return ret0
+}
+
+// CurrentKeyPhase indicates an expected call of CurrentKeyPhase.
+func (mr *MockCryptoSetupMockRecorder) CurrentKeyPhase() *MockCryptoSetupCurrentKeyPhaseCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CurrentKeyPhase", reflect.TypeOf((*MockCryptoSetup)(nil).CurrentKeyPhase))
+	return &MockCryptoSetupCurrentKeyPhaseCall{Call: call}
+}
+
+// MockCryptoSetupCurrentKeyPhaseCall wrap *gomock.Call
+type MockCryptoSetupCurrentKeyPhaseCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockCryptoSetupCurrentKeyPhaseCall) Return(arg0 protocol.KeyPhase) *MockCryptoSetupCurrentKeyPhaseCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockCryptoSetupCurrentKeyPhaseCall) Do(f func() protocol.KeyPhase) *MockCryptoSetupCurrentKeyPhaseCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockCryptoSetupCurrentKeyPhaseCall) DoAndReturn(f func() protocol.KeyPhase) *MockCryptoSetupCurrentKeyPhaseCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // DiscardInitialKeys mocks base method.
 func (m *MockCryptoSetup) DiscardInitialKeys() {
 	m.ctrl.T.Helper()
@@ -578,6 +616,44 @@ func (c *MockCryptoSetupHandleMessageCall) DoAndReturn(f func([]byte, protocol.E
 	return c
 }
 
+// InitiateKeyUpdate mocks base method.
+func (m *MockCryptoSetup) InitiateKeyUpdate() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InitiateKeyUpdate")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// InitiateKeyUpdate indicates an expected call of InitiateKeyUpdate.
+func (mr *MockCryptoSetupMockRecorder) InitiateKeyUpdate() *MockCryptoSetupInitiateKeyUpdateCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InitiateKeyUpdate", reflect.TypeOf((*MockCryptoSetup)(nil).InitiateKeyUpdate))
+	return &MockCryptoSetupInitiateKeyUpdateCall{Call: call}
+}
+
+// MockCryptoSetupInitiateKeyUpdateCall wrap *gomock.Call
+type MockCryptoSetupInitiateKeyUpdateCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockCryptoSetupInitiateKeyUpdateCall) Return(arg0 error) *MockCryptoSetupInitiateKeyUpdateCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockCryptoSetupInitiateKeyUpdateCall) Do(f func() error) *MockCryptoSetupInitiateKeyUpdateCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockCryptoSetupInitiateKeyUpdateCall) DoAndReturn(f func() error) *MockCryptoSetupInitiateKeyUpdateCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // NextEvent mocks base method.
 func (m *MockCryptoSetup) NextEvent() handshake.Event {
 	m.ctrl.T.Helper()
@@ -41,6 +41,9 @@ func NewMockConnectionTracer(ctrl *gomock.Controller) (*logging.ConnectionTracer
 		SentShortHeaderPacket: func(hdr *logging.ShortHeader, size logging.ByteCount, ecn logging.ECN, ack *logging.AckFrame, frames []logging.Frame) {
 			t.SentShortHeaderPacket(hdr, size, ecn, ack, frames)
 		},
+		SentUDPDatagram: func(size logging.ByteCount, numPackets int) {
+			t.SentUDPDatagram(size, numPackets)
+		},
 		ReceivedVersionNegotiationPacket: func(dest, src logging.ArbitraryLenConnectionID, versions []logging.VersionNumber) {
 			t.ReceivedVersionNegotiationPacket(dest, src, versions)
 		},
@@ -104,6 +107,12 @@ func NewMockConnectionTracer(ctrl *gomock.Controller) (*logging.ConnectionTracer
 		ChoseALPN: func(protocol string) {
 			t.ChoseALPN(protocol)
 		},
+		MigratedConnection: func(local, remote net.Addr) {
+			t.MigratedConnection(local, remote)
+		},
+		AmplificationLimited: func(bytesReceived, bytesSent logging.ByteCount) {
+			t.AmplificationLimited(bytesReceived, bytesSent)
+		},
 		Close: func() {
 			t.Close()
 		},
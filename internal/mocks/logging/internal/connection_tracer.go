@@ -80,6 +80,42 @@ func (c *MockConnectionTracerAcknowledgedPacketCall) DoAndReturn(f func(protocol
 	return c
 }
 
+// AmplificationLimited mocks base method.
+func (m *MockConnectionTracer) AmplificationLimited(arg0, arg1 protocol.ByteCount) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "AmplificationLimited", arg0, arg1)
+}
+
+// AmplificationLimited indicates an expected call of AmplificationLimited.
+func (mr *MockConnectionTracerMockRecorder) AmplificationLimited(arg0, arg1 any) *MockConnectionTracerAmplificationLimitedCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AmplificationLimited", reflect.TypeOf((*MockConnectionTracer)(nil).AmplificationLimited), arg0, arg1)
+	return &MockConnectionTracerAmplificationLimitedCall{Call: call}
+}
+
+// MockConnectionTracerAmplificationLimitedCall wrap *gomock.Call
+type MockConnectionTracerAmplificationLimitedCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockConnectionTracerAmplificationLimitedCall) Return() *MockConnectionTracerAmplificationLimitedCall {
+	c.Call = c.Call.Return()
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockConnectionTracerAmplificationLimitedCall) Do(f func(protocol.ByteCount, protocol.ByteCount)) *MockConnectionTracerAmplificationLimitedCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockConnectionTracerAmplificationLimitedCall) DoAndReturn(f func(protocol.ByteCount, protocol.ByteCount)) *MockConnectionTracerAmplificationLimitedCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // BufferedPacket mocks base method.
 func (m *MockConnectionTracer) BufferedPacket(arg0 logging.PacketType, arg1 protocol.ByteCount) {
 	m.ctrl.T.Helper()
@@ -512,6 +548,42 @@ func (c *MockConnectionTracerLostPacketCall) DoAndReturn(f func(protocol.Encrypt
 	return c
 }
 
+// MigratedConnection mocks base method.
+func (m *MockConnectionTracer) MigratedConnection(arg0, arg1 net.Addr) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "MigratedConnection", arg0, arg1)
+}
+
+// MigratedConnection indicates an expected call of MigratedConnection.
+func (mr *MockConnectionTracerMockRecorder) MigratedConnection(arg0, arg1 any) *MockConnectionTracerMigratedConnectionCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MigratedConnection", reflect.TypeOf((*MockConnectionTracer)(nil).MigratedConnection), arg0, arg1)
+	return &MockConnectionTracerMigratedConnectionCall{Call: call}
+}
+
+// MockConnectionTracerMigratedConnectionCall wrap *gomock.Call
+type MockConnectionTracerMigratedConnectionCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockConnectionTracerMigratedConnectionCall) Return() *MockConnectionTracerMigratedConnectionCall {
+	c.Call = c.Call.Return()
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockConnectionTracerMigratedConnectionCall) Do(f func(net.Addr, net.Addr)) *MockConnectionTracerMigratedConnectionCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockConnectionTracerMigratedConnectionCall) DoAndReturn(f func(net.Addr, net.Addr)) *MockConnectionTracerMigratedConnectionCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // NegotiatedVersion mocks base method.
 func (m *MockConnectionTracer) NegotiatedVersion(arg0 protocol.Version, arg1, arg2 []protocol.Version) {
 	m.ctrl.T.Helper()
@@ -872,6 +944,42 @@ func (c *MockConnectionTracerSentTransportParametersCall) DoAndReturn(f func(*wi
 	return c
 }
 
+// SentUDPDatagram mocks base method.
+func (m *MockConnectionTracer) SentUDPDatagram(arg0 protocol.ByteCount, arg1 int) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SentUDPDatagram", arg0, arg1)
+}
+
+// SentUDPDatagram indicates an expected call of SentUDPDatagram.
+func (mr *MockConnectionTracerMockRecorder) SentUDPDatagram(arg0, arg1 any) *MockConnectionTracerSentUDPDatagramCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SentUDPDatagram", reflect.TypeOf((*MockConnectionTracer)(nil).SentUDPDatagram), arg0, arg1)
+	return &MockConnectionTracerSentUDPDatagramCall{Call: call}
+}
+
+// MockConnectionTracerSentUDPDatagramCall wrap *gomock.Call
+type MockConnectionTracerSentUDPDatagramCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockConnectionTracerSentUDPDatagramCall) Return() *MockConnectionTracerSentUDPDatagramCall {
+	c.Call = c.Call.Return()
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockConnectionTracerSentUDPDatagramCall) Do(f func(protocol.ByteCount, int)) *MockConnectionTracerSentUDPDatagramCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockConnectionTracerSentUDPDatagramCall) DoAndReturn(f func(protocol.ByteCount, int)) *MockConnectionTracerSentUDPDatagramCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // SetLossTimer mocks base method.
 func (m *MockConnectionTracer) SetLossTimer(arg0 logging.TimerType, arg1 protocol.EncryptionLevel, arg2 time.Time) {
 	m.ctrl.T.Helper()
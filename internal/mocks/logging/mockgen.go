@@ -28,6 +28,7 @@ type ConnectionTracer interface {
 	RestoredTransportParameters(parameters *logging.TransportParameters) // for 0-RTT
 	SentLongHeaderPacket(*logging.ExtendedHeader, logging.ByteCount, logging.ECN, *logging.AckFrame, []logging.Frame)
 	SentShortHeaderPacket(*logging.ShortHeader, logging.ByteCount, logging.ECN, *logging.AckFrame, []logging.Frame)
+	SentUDPDatagram(size logging.ByteCount, numPackets int)
 	ReceivedVersionNegotiationPacket(dest, src logging.ArbitraryLenConnectionID, _ []logging.VersionNumber)
 	ReceivedRetry(*logging.Header)
 	ReceivedLongHeaderPacket(*logging.ExtendedHeader, logging.ByteCount, logging.ECN, []logging.Frame)
@@ -49,6 +50,8 @@ type ConnectionTracer interface {
 	LossTimerCanceled()
 	ECNStateUpdated(state logging.ECNState, trigger logging.ECNStateTrigger)
 	ChoseALPN(protocol string)
+	MigratedConnection(local, remote net.Addr)
+	AmplificationLimited(bytesReceived, bytesSent logging.ByteCount)
 	// Close is called when the connection is closed.
 	Close()
 	Debug(name, msg string)
@@ -13,8 +13,10 @@ import (
 	context "context"
 	net "net"
 	reflect "reflect"
+	time "time"
 
 	quic "github.com/quic-go/quic-go"
+	protocol "github.com/quic-go/quic-go/internal/protocol"
 	qerr "github.com/quic-go/quic-go/internal/qerr"
 	gomock "go.uber.org/mock/gomock"
 )
@@ -42,6 +44,45 @@ func (m *MockEarlyConnection) EXPECT() *MockEarlyConnectionMockRecorder {
 	return m.recorder
 }
 
+// AcceptMessage mocks base method.
+func (m *MockEarlyConnection) AcceptMessage(arg0 context.Context) ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AcceptMessage", arg0)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AcceptMessage indicates an expected call of AcceptMessage.
+func (mr *MockEarlyConnectionMockRecorder) AcceptMessage(arg0 any) *MockEarlyConnectionAcceptMessageCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AcceptMessage", reflect.TypeOf((*MockEarlyConnection)(nil).AcceptMessage), arg0)
+	return &MockEarlyConnectionAcceptMessageCall{Call: call}
+}
+
+// MockEarlyConnectionAcceptMessageCall wrap *gomock.Call
+type MockEarlyConnectionAcceptMessageCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockEarlyConnectionAcceptMessageCall) Return(arg0 []byte, arg1 error) *MockEarlyConnectionAcceptMessageCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockEarlyConnectionAcceptMessageCall) Do(f func(context.Context) ([]byte, error)) *MockEarlyConnectionAcceptMessageCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockEarlyConnectionAcceptMessageCall) DoAndReturn(f func(context.Context) ([]byte, error)) *MockEarlyConnectionAcceptMessageCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // AcceptStream mocks base method.
 func (m *MockEarlyConnection) AcceptStream(arg0 context.Context) (quic.Stream, error) {
 	m.ctrl.T.Helper()
@@ -120,502 +161,2065 @@ func (c *MockEarlyConnectionAcceptUniStreamCall) DoAndReturn(f func(context.Cont
 	return c
 }
 
-// CloseWithError mocks base method.
-func (m *MockEarlyConnection) CloseWithError(arg0 qerr.ApplicationErrorCode, arg1 string) error {
+// ActiveStreams mocks base method.
+func (m *MockEarlyConnection) ActiveStreams() []protocol.StreamID {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CloseWithError", arg0, arg1)
-	ret0, _ := ret[0].(error)
+	ret := m.ctrl.Call(m, "ActiveStreams")
+	ret0, _ := ret[0].([]protocol.StreamID)
 	return ret0
 }
 
-// CloseWithError indicates an expected call of CloseWithError.
-func (mr *MockEarlyConnectionMockRecorder) CloseWithError(arg0, arg1 any) *MockEarlyConnectionCloseWithErrorCall {
+// ActiveStreams indicates an expected call of ActiveStreams.
+func (mr *MockEarlyConnectionMockRecorder) ActiveStreams() *MockEarlyConnectionActiveStreamsCall {
 	mr.mock.ctrl.T.Helper()
-	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CloseWithError", reflect.TypeOf((*MockEarlyConnection)(nil).CloseWithError), arg0, arg1)
-	return &MockEarlyConnectionCloseWithErrorCall{Call: call}
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ActiveStreams", reflect.TypeOf((*MockEarlyConnection)(nil).ActiveStreams))
+	return &MockEarlyConnectionActiveStreamsCall{Call: call}
 }
 
-// MockEarlyConnectionCloseWithErrorCall wrap *gomock.Call
-type MockEarlyConnectionCloseWithErrorCall struct {
+// MockEarlyConnectionActiveStreamsCall wrap *gomock.Call
+type MockEarlyConnectionActiveStreamsCall struct {
 	*gomock.Call
 }
 
 // Return rewrite *gomock.Call.Return
-func (c *MockEarlyConnectionCloseWithErrorCall) Return(arg0 error) *MockEarlyConnectionCloseWithErrorCall {
+func (c *MockEarlyConnectionActiveStreamsCall) Return(arg0 []protocol.StreamID) *MockEarlyConnectionActiveStreamsCall {
 	c.Call = c.Call.Return(arg0)
 	return c
 }
 
 // Do rewrite *gomock.Call.Do
-func (c *MockEarlyConnectionCloseWithErrorCall) Do(f func(qerr.ApplicationErrorCode, string) error) *MockEarlyConnectionCloseWithErrorCall {
+func (c *MockEarlyConnectionActiveStreamsCall) Do(f func() []protocol.StreamID) *MockEarlyConnectionActiveStreamsCall {
 	c.Call = c.Call.Do(f)
 	return c
 }
 
 // DoAndReturn rewrite *gomock.Call.DoAndReturn
-func (c *MockEarlyConnectionCloseWithErrorCall) DoAndReturn(f func(qerr.ApplicationErrorCode, string) error) *MockEarlyConnectionCloseWithErrorCall {
+func (c *MockEarlyConnectionActiveStreamsCall) DoAndReturn(f func() []protocol.StreamID) *MockEarlyConnectionActiveStreamsCall {
 	c.Call = c.Call.DoAndReturn(f)
 	return c
 }
 
-// ConnectionState mocks base method.
-func (m *MockEarlyConnection) ConnectionState() quic.ConnectionState {
+// AddPath mocks base method.
+func (m *MockEarlyConnection) AddPath(arg0, arg1 net.Addr) (quic.PathID, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ConnectionState")
-	ret0, _ := ret[0].(quic.ConnectionState)
-	return ret0
+	ret := m.ctrl.Call(m, "AddPath", arg0, arg1)
+	ret0, _ := ret[0].(quic.PathID)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
-// ConnectionState indicates an expected call of ConnectionState.
-func (mr *MockEarlyConnectionMockRecorder) ConnectionState() *MockEarlyConnectionConnectionStateCall {
+// AddPath indicates an expected call of AddPath.
+func (mr *MockEarlyConnectionMockRecorder) AddPath(arg0, arg1 any) *MockEarlyConnectionAddPathCall {
 	mr.mock.ctrl.T.Helper()
-	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConnectionState", reflect.TypeOf((*MockEarlyConnection)(nil).ConnectionState))
-	return &MockEarlyConnectionConnectionStateCall{Call: call}
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddPath", reflect.TypeOf((*MockEarlyConnection)(nil).AddPath), arg0, arg1)
+	return &MockEarlyConnectionAddPathCall{Call: call}
 }
 
-// MockEarlyConnectionConnectionStateCall wrap *gomock.Call
-type MockEarlyConnectionConnectionStateCall struct {
+// MockEarlyConnectionAddPathCall wrap *gomock.Call
+type MockEarlyConnectionAddPathCall struct {
 	*gomock.Call
 }
 
 // Return rewrite *gomock.Call.Return
-func (c *MockEarlyConnectionConnectionStateCall) Return(arg0 quic.ConnectionState) *MockEarlyConnectionConnectionStateCall {
-	c.Call = c.Call.Return(arg0)
+func (c *MockEarlyConnectionAddPathCall) Return(arg0 quic.PathID, arg1 error) *MockEarlyConnectionAddPathCall {
+	c.Call = c.Call.Return(arg0, arg1)
 	return c
 }
 
 // Do rewrite *gomock.Call.Do
-func (c *MockEarlyConnectionConnectionStateCall) Do(f func() quic.ConnectionState) *MockEarlyConnectionConnectionStateCall {
+func (c *MockEarlyConnectionAddPathCall) Do(f func(net.Addr, net.Addr) (quic.PathID, error)) *MockEarlyConnectionAddPathCall {
 	c.Call = c.Call.Do(f)
 	return c
 }
 
 // DoAndReturn rewrite *gomock.Call.DoAndReturn
-func (c *MockEarlyConnectionConnectionStateCall) DoAndReturn(f func() quic.ConnectionState) *MockEarlyConnectionConnectionStateCall {
+func (c *MockEarlyConnectionAddPathCall) DoAndReturn(f func(net.Addr, net.Addr) (quic.PathID, error)) *MockEarlyConnectionAddPathCall {
 	c.Call = c.Call.DoAndReturn(f)
 	return c
 }
 
-// Context mocks base method.
-func (m *MockEarlyConnection) Context() context.Context {
+// BytesReceived mocks base method.
+func (m *MockEarlyConnection) BytesReceived() uint64 {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Context")
-	ret0, _ := ret[0].(context.Context)
+	ret := m.ctrl.Call(m, "BytesReceived")
+	ret0, _ := ret[0].(uint64)
 	return ret0
 }
 
-// Context indicates an expected call of Context.
-func (mr *MockEarlyConnectionMockRecorder) Context() *MockEarlyConnectionContextCall {
+// BytesReceived indicates an expected call of BytesReceived.
+func (mr *MockEarlyConnectionMockRecorder) BytesReceived() *MockEarlyConnectionBytesReceivedCall {
 	mr.mock.ctrl.T.Helper()
-	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Context", reflect.TypeOf((*MockEarlyConnection)(nil).Context))
-	return &MockEarlyConnectionContextCall{Call: call}
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BytesReceived", reflect.TypeOf((*MockEarlyConnection)(nil).BytesReceived))
+	return &MockEarlyConnectionBytesReceivedCall{Call: call}
 }
 
-// MockEarlyConnectionContextCall wrap *gomock.Call
-type MockEarlyConnectionContextCall struct {
+// MockEarlyConnectionBytesReceivedCall wrap *gomock.Call
+type MockEarlyConnectionBytesReceivedCall struct {
 	*gomock.Call
 }
 
 // Return rewrite *gomock.Call.Return
-func (c *MockEarlyConnectionContextCall) Return(arg0 context.Context) *MockEarlyConnectionContextCall {
+func (c *MockEarlyConnectionBytesReceivedCall) Return(arg0 uint64) *MockEarlyConnectionBytesReceivedCall {
 	c.Call = c.Call.Return(arg0)
 	return c
 }
 
 // Do rewrite *gomock.Call.Do
-func (c *MockEarlyConnectionContextCall) Do(f func() context.Context) *MockEarlyConnectionContextCall {
+func (c *MockEarlyConnectionBytesReceivedCall) Do(f func() uint64) *MockEarlyConnectionBytesReceivedCall {
 	c.Call = c.Call.Do(f)
 	return c
 }
 
 // DoAndReturn rewrite *gomock.Call.DoAndReturn
-func (c *MockEarlyConnectionContextCall) DoAndReturn(f func() context.Context) *MockEarlyConnectionContextCall {
+func (c *MockEarlyConnectionBytesReceivedCall) DoAndReturn(f func() uint64) *MockEarlyConnectionBytesReceivedCall {
 	c.Call = c.Call.DoAndReturn(f)
 	return c
 }
 
-// HandshakeComplete mocks base method.
-func (m *MockEarlyConnection) HandshakeComplete() <-chan struct{} {
+// BytesSent mocks base method.
+func (m *MockEarlyConnection) BytesSent() uint64 {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "HandshakeComplete")
-	ret0, _ := ret[0].(<-chan struct{})
+	ret := m.ctrl.Call(m, "BytesSent")
+	ret0, _ := ret[0].(uint64)
 	return ret0
 }
 
-// HandshakeComplete indicates an expected call of HandshakeComplete.
-func (mr *MockEarlyConnectionMockRecorder) HandshakeComplete() *MockEarlyConnectionHandshakeCompleteCall {
+// BytesSent indicates an expected call of BytesSent.
+func (mr *MockEarlyConnectionMockRecorder) BytesSent() *MockEarlyConnectionBytesSentCall {
 	mr.mock.ctrl.T.Helper()
-	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HandshakeComplete", reflect.TypeOf((*MockEarlyConnection)(nil).HandshakeComplete))
-	return &MockEarlyConnectionHandshakeCompleteCall{Call: call}
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BytesSent", reflect.TypeOf((*MockEarlyConnection)(nil).BytesSent))
+	return &MockEarlyConnectionBytesSentCall{Call: call}
 }
 
-// MockEarlyConnectionHandshakeCompleteCall wrap *gomock.Call
-type MockEarlyConnectionHandshakeCompleteCall struct {
+// MockEarlyConnectionBytesSentCall wrap *gomock.Call
+type MockEarlyConnectionBytesSentCall struct {
 	*gomock.Call
 }
 
 // Return rewrite *gomock.Call.Return
-func (c *MockEarlyConnectionHandshakeCompleteCall) Return(arg0 <-chan struct{}) *MockEarlyConnectionHandshakeCompleteCall {
+func (c *MockEarlyConnectionBytesSentCall) Return(arg0 uint64) *MockEarlyConnectionBytesSentCall {
 	c.Call = c.Call.Return(arg0)
 	return c
 }
 
 // Do rewrite *gomock.Call.Do
-func (c *MockEarlyConnectionHandshakeCompleteCall) Do(f func() <-chan struct{}) *MockEarlyConnectionHandshakeCompleteCall {
+func (c *MockEarlyConnectionBytesSentCall) Do(f func() uint64) *MockEarlyConnectionBytesSentCall {
 	c.Call = c.Call.Do(f)
 	return c
 }
 
 // DoAndReturn rewrite *gomock.Call.DoAndReturn
-func (c *MockEarlyConnectionHandshakeCompleteCall) DoAndReturn(f func() <-chan struct{}) *MockEarlyConnectionHandshakeCompleteCall {
+func (c *MockEarlyConnectionBytesSentCall) DoAndReturn(f func() uint64) *MockEarlyConnectionBytesSentCall {
 	c.Call = c.Call.DoAndReturn(f)
 	return c
 }
 
-// LocalAddr mocks base method.
-func (m *MockEarlyConnection) LocalAddr() net.Addr {
+// CloseWithError mocks base method.
+func (m *MockEarlyConnection) CloseWithError(arg0 qerr.ApplicationErrorCode, arg1 string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "LocalAddr")
-	ret0, _ := ret[0].(net.Addr)
+	ret := m.ctrl.Call(m, "CloseWithError", arg0, arg1)
+	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// LocalAddr indicates an expected call of LocalAddr.
-func (mr *MockEarlyConnectionMockRecorder) LocalAddr() *MockEarlyConnectionLocalAddrCall {
+// CloseWithError indicates an expected call of CloseWithError.
+func (mr *MockEarlyConnectionMockRecorder) CloseWithError(arg0, arg1 any) *MockEarlyConnectionCloseWithErrorCall {
 	mr.mock.ctrl.T.Helper()
-	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LocalAddr", reflect.TypeOf((*MockEarlyConnection)(nil).LocalAddr))
-	return &MockEarlyConnectionLocalAddrCall{Call: call}
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CloseWithError", reflect.TypeOf((*MockEarlyConnection)(nil).CloseWithError), arg0, arg1)
+	return &MockEarlyConnectionCloseWithErrorCall{Call: call}
 }
 
-// MockEarlyConnectionLocalAddrCall wrap *gomock.Call
-type MockEarlyConnectionLocalAddrCall struct {
+// MockEarlyConnectionCloseWithErrorCall wrap *gomock.Call
+type MockEarlyConnectionCloseWithErrorCall struct {
 	*gomock.Call
 }
 
 // Return rewrite *gomock.Call.Return
-func (c *MockEarlyConnectionLocalAddrCall) Return(arg0 net.Addr) *MockEarlyConnectionLocalAddrCall {
+func (c *MockEarlyConnectionCloseWithErrorCall) Return(arg0 error) *MockEarlyConnectionCloseWithErrorCall {
 	c.Call = c.Call.Return(arg0)
 	return c
 }
 
 // Do rewrite *gomock.Call.Do
-func (c *MockEarlyConnectionLocalAddrCall) Do(f func() net.Addr) *MockEarlyConnectionLocalAddrCall {
+func (c *MockEarlyConnectionCloseWithErrorCall) Do(f func(qerr.ApplicationErrorCode, string) error) *MockEarlyConnectionCloseWithErrorCall {
 	c.Call = c.Call.Do(f)
 	return c
 }
 
 // DoAndReturn rewrite *gomock.Call.DoAndReturn
-func (c *MockEarlyConnectionLocalAddrCall) DoAndReturn(f func() net.Addr) *MockEarlyConnectionLocalAddrCall {
+func (c *MockEarlyConnectionCloseWithErrorCall) DoAndReturn(f func(qerr.ApplicationErrorCode, string) error) *MockEarlyConnectionCloseWithErrorCall {
 	c.Call = c.Call.DoAndReturn(f)
 	return c
 }
 
-// NextConnection mocks base method.
-func (m *MockEarlyConnection) NextConnection(arg0 context.Context) (quic.Connection, error) {
+// ConnectionState mocks base method.
+func (m *MockEarlyConnection) ConnectionState() quic.ConnectionState {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "NextConnection", arg0)
-	ret0, _ := ret[0].(quic.Connection)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
+	ret := m.ctrl.Call(m, "ConnectionState")
+	ret0, _ := ret[0].(quic.ConnectionState)
+	return ret0
 }
 
-// NextConnection indicates an expected call of NextConnection.
-func (mr *MockEarlyConnectionMockRecorder) NextConnection(arg0 any) *MockEarlyConnectionNextConnectionCall {
+// ConnectionState indicates an expected call of ConnectionState.
+func (mr *MockEarlyConnectionMockRecorder) ConnectionState() *MockEarlyConnectionConnectionStateCall {
 	mr.mock.ctrl.T.Helper()
-	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NextConnection", reflect.TypeOf((*MockEarlyConnection)(nil).NextConnection), arg0)
-	return &MockEarlyConnectionNextConnectionCall{Call: call}
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConnectionState", reflect.TypeOf((*MockEarlyConnection)(nil).ConnectionState))
+	return &MockEarlyConnectionConnectionStateCall{Call: call}
 }
 
-// MockEarlyConnectionNextConnectionCall wrap *gomock.Call
-type MockEarlyConnectionNextConnectionCall struct {
+// MockEarlyConnectionConnectionStateCall wrap *gomock.Call
+type MockEarlyConnectionConnectionStateCall struct {
 	*gomock.Call
 }
 
 // Return rewrite *gomock.Call.Return
-func (c *MockEarlyConnectionNextConnectionCall) Return(arg0 quic.Connection, arg1 error) *MockEarlyConnectionNextConnectionCall {
-	c.Call = c.Call.Return(arg0, arg1)
+func (c *MockEarlyConnectionConnectionStateCall) Return(arg0 quic.ConnectionState) *MockEarlyConnectionConnectionStateCall {
+	c.Call = c.Call.Return(arg0)
 	return c
 }
 
 // Do rewrite *gomock.Call.Do
-func (c *MockEarlyConnectionNextConnectionCall) Do(f func(context.Context) (quic.Connection, error)) *MockEarlyConnectionNextConnectionCall {
+func (c *MockEarlyConnectionConnectionStateCall) Do(f func() quic.ConnectionState) *MockEarlyConnectionConnectionStateCall {
 	c.Call = c.Call.Do(f)
 	return c
 }
 
 // DoAndReturn rewrite *gomock.Call.DoAndReturn
-func (c *MockEarlyConnectionNextConnectionCall) DoAndReturn(f func(context.Context) (quic.Connection, error)) *MockEarlyConnectionNextConnectionCall {
+func (c *MockEarlyConnectionConnectionStateCall) DoAndReturn(f func() quic.ConnectionState) *MockEarlyConnectionConnectionStateCall {
 	c.Call = c.Call.DoAndReturn(f)
 	return c
 }
 
-// OpenStream mocks base method.
-func (m *MockEarlyConnection) OpenStream() (quic.Stream, error) {
+// Context mocks base method.
+func (m *MockEarlyConnection) Context() context.Context {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "OpenStream")
-	ret0, _ := ret[0].(quic.Stream)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
+	ret := m.ctrl.Call(m, "Context")
+	ret0, _ := ret[0].(context.Context)
+	return ret0
 }
 
-// OpenStream indicates an expected call of OpenStream.
-func (mr *MockEarlyConnectionMockRecorder) OpenStream() *MockEarlyConnectionOpenStreamCall {
+// Context indicates an expected call of Context.
+func (mr *MockEarlyConnectionMockRecorder) Context() *MockEarlyConnectionContextCall {
 	mr.mock.ctrl.T.Helper()
-	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OpenStream", reflect.TypeOf((*MockEarlyConnection)(nil).OpenStream))
-	return &MockEarlyConnectionOpenStreamCall{Call: call}
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Context", reflect.TypeOf((*MockEarlyConnection)(nil).Context))
+	return &MockEarlyConnectionContextCall{Call: call}
 }
 
-// MockEarlyConnectionOpenStreamCall wrap *gomock.Call
-type MockEarlyConnectionOpenStreamCall struct {
+// MockEarlyConnectionContextCall wrap *gomock.Call
+type MockEarlyConnectionContextCall struct {
 	*gomock.Call
 }
 
 // Return rewrite *gomock.Call.Return
-func (c *MockEarlyConnectionOpenStreamCall) Return(arg0 quic.Stream, arg1 error) *MockEarlyConnectionOpenStreamCall {
-	c.Call = c.Call.Return(arg0, arg1)
+func (c *MockEarlyConnectionContextCall) Return(arg0 context.Context) *MockEarlyConnectionContextCall {
+	c.Call = c.Call.Return(arg0)
 	return c
 }
 
 // Do rewrite *gomock.Call.Do
-func (c *MockEarlyConnectionOpenStreamCall) Do(f func() (quic.Stream, error)) *MockEarlyConnectionOpenStreamCall {
+func (c *MockEarlyConnectionContextCall) Do(f func() context.Context) *MockEarlyConnectionContextCall {
 	c.Call = c.Call.Do(f)
 	return c
 }
 
 // DoAndReturn rewrite *gomock.Call.DoAndReturn
-func (c *MockEarlyConnectionOpenStreamCall) DoAndReturn(f func() (quic.Stream, error)) *MockEarlyConnectionOpenStreamCall {
+func (c *MockEarlyConnectionContextCall) DoAndReturn(f func() context.Context) *MockEarlyConnectionContextCall {
 	c.Call = c.Call.DoAndReturn(f)
 	return c
 }
 
-// OpenStreamSync mocks base method.
-func (m *MockEarlyConnection) OpenStreamSync(arg0 context.Context) (quic.Stream, error) {
+// CurrentMTU mocks base method.
+func (m *MockEarlyConnection) CurrentMTU() protocol.ByteCount {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "OpenStreamSync", arg0)
-	ret0, _ := ret[0].(quic.Stream)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
+	ret := m.ctrl.Call(m, "CurrentMTU")
+	ret0, _ := ret[0].(protocol.ByteCount)
+	return ret0
 }
 
-// OpenStreamSync indicates an expected call of OpenStreamSync.
-func (mr *MockEarlyConnectionMockRecorder) OpenStreamSync(arg0 any) *MockEarlyConnectionOpenStreamSyncCall {
+// CurrentMTU indicates an expected call of CurrentMTU.
+func (mr *MockEarlyConnectionMockRecorder) CurrentMTU() *MockEarlyConnectionCurrentMTUCall {
 	mr.mock.ctrl.T.Helper()
-	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OpenStreamSync", reflect.TypeOf((*MockEarlyConnection)(nil).OpenStreamSync), arg0)
-	return &MockEarlyConnectionOpenStreamSyncCall{Call: call}
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CurrentMTU", reflect.TypeOf((*MockEarlyConnection)(nil).CurrentMTU))
+	return &MockEarlyConnectionCurrentMTUCall{Call: call}
 }
 
-// MockEarlyConnectionOpenStreamSyncCall wrap *gomock.Call
-type MockEarlyConnectionOpenStreamSyncCall struct {
+// MockEarlyConnectionCurrentMTUCall wrap *gomock.Call
+type MockEarlyConnectionCurrentMTUCall struct {
 	*gomock.Call
 }
 
 // Return rewrite *gomock.Call.Return
-func (c *MockEarlyConnectionOpenStreamSyncCall) Return(arg0 quic.Stream, arg1 error) *MockEarlyConnectionOpenStreamSyncCall {
-	c.Call = c.Call.Return(arg0, arg1)
+func (c *MockEarlyConnectionCurrentMTUCall) Return(arg0 protocol.ByteCount) *MockEarlyConnectionCurrentMTUCall {
+	c.Call = c.Call.Return(arg0)
 	return c
 }
 
 // Do rewrite *gomock.Call.Do
-func (c *MockEarlyConnectionOpenStreamSyncCall) Do(f func(context.Context) (quic.Stream, error)) *MockEarlyConnectionOpenStreamSyncCall {
+func (c *MockEarlyConnectionCurrentMTUCall) Do(f func() protocol.ByteCount) *MockEarlyConnectionCurrentMTUCall {
 	c.Call = c.Call.Do(f)
 	return c
 }
 
 // DoAndReturn rewrite *gomock.Call.DoAndReturn
-func (c *MockEarlyConnectionOpenStreamSyncCall) DoAndReturn(f func(context.Context) (quic.Stream, error)) *MockEarlyConnectionOpenStreamSyncCall {
+func (c *MockEarlyConnectionCurrentMTUCall) DoAndReturn(f func() protocol.ByteCount) *MockEarlyConnectionCurrentMTUCall {
 	c.Call = c.Call.DoAndReturn(f)
 	return c
 }
 
-// OpenUniStream mocks base method.
-func (m *MockEarlyConnection) OpenUniStream() (quic.SendStream, error) {
+// DatagramsSupported mocks base method.
+func (m *MockEarlyConnection) DatagramsSupported() bool {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "OpenUniStream")
-	ret0, _ := ret[0].(quic.SendStream)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
+	ret := m.ctrl.Call(m, "DatagramsSupported")
+	ret0, _ := ret[0].(bool)
+	return ret0
 }
 
-// OpenUniStream indicates an expected call of OpenUniStream.
-func (mr *MockEarlyConnectionMockRecorder) OpenUniStream() *MockEarlyConnectionOpenUniStreamCall {
+// DatagramsSupported indicates an expected call of DatagramsSupported.
+func (mr *MockEarlyConnectionMockRecorder) DatagramsSupported() *MockEarlyConnectionDatagramsSupportedCall {
 	mr.mock.ctrl.T.Helper()
-	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OpenUniStream", reflect.TypeOf((*MockEarlyConnection)(nil).OpenUniStream))
-	return &MockEarlyConnectionOpenUniStreamCall{Call: call}
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DatagramsSupported", reflect.TypeOf((*MockEarlyConnection)(nil).DatagramsSupported))
+	return &MockEarlyConnectionDatagramsSupportedCall{Call: call}
 }
 
-// MockEarlyConnectionOpenUniStreamCall wrap *gomock.Call
-type MockEarlyConnectionOpenUniStreamCall struct {
+// MockEarlyConnectionDatagramsSupportedCall wrap *gomock.Call
+type MockEarlyConnectionDatagramsSupportedCall struct {
 	*gomock.Call
 }
 
 // Return rewrite *gomock.Call.Return
-func (c *MockEarlyConnectionOpenUniStreamCall) Return(arg0 quic.SendStream, arg1 error) *MockEarlyConnectionOpenUniStreamCall {
-	c.Call = c.Call.Return(arg0, arg1)
+func (c *MockEarlyConnectionDatagramsSupportedCall) Return(arg0 bool) *MockEarlyConnectionDatagramsSupportedCall {
+	c.Call = c.Call.Return(arg0)
 	return c
 }
 
 // Do rewrite *gomock.Call.Do
-func (c *MockEarlyConnectionOpenUniStreamCall) Do(f func() (quic.SendStream, error)) *MockEarlyConnectionOpenUniStreamCall {
+func (c *MockEarlyConnectionDatagramsSupportedCall) Do(f func() bool) *MockEarlyConnectionDatagramsSupportedCall {
 	c.Call = c.Call.Do(f)
 	return c
 }
 
 // DoAndReturn rewrite *gomock.Call.DoAndReturn
-func (c *MockEarlyConnectionOpenUniStreamCall) DoAndReturn(f func() (quic.SendStream, error)) *MockEarlyConnectionOpenUniStreamCall {
+func (c *MockEarlyConnectionDatagramsSupportedCall) DoAndReturn(f func() bool) *MockEarlyConnectionDatagramsSupportedCall {
 	c.Call = c.Call.DoAndReturn(f)
 	return c
 }
 
-// OpenUniStreamSync mocks base method.
-func (m *MockEarlyConnection) OpenUniStreamSync(arg0 context.Context) (quic.SendStream, error) {
+// ECNStats mocks base method.
+func (m *MockEarlyConnection) ECNStats() quic.ECNStats {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "OpenUniStreamSync", arg0)
-	ret0, _ := ret[0].(quic.SendStream)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
+	ret := m.ctrl.Call(m, "ECNStats")
+	ret0, _ := ret[0].(quic.ECNStats)
+	return ret0
 }
 
-// OpenUniStreamSync indicates an expected call of OpenUniStreamSync.
-func (mr *MockEarlyConnectionMockRecorder) OpenUniStreamSync(arg0 any) *MockEarlyConnectionOpenUniStreamSyncCall {
+// ECNStats indicates an expected call of ECNStats.
+func (mr *MockEarlyConnectionMockRecorder) ECNStats() *MockEarlyConnectionECNStatsCall {
 	mr.mock.ctrl.T.Helper()
-	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OpenUniStreamSync", reflect.TypeOf((*MockEarlyConnection)(nil).OpenUniStreamSync), arg0)
-	return &MockEarlyConnectionOpenUniStreamSyncCall{Call: call}
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ECNStats", reflect.TypeOf((*MockEarlyConnection)(nil).ECNStats))
+	return &MockEarlyConnectionECNStatsCall{Call: call}
 }
 
-// MockEarlyConnectionOpenUniStreamSyncCall wrap *gomock.Call
-type MockEarlyConnectionOpenUniStreamSyncCall struct {
+// MockEarlyConnectionECNStatsCall wrap *gomock.Call
+type MockEarlyConnectionECNStatsCall struct {
 	*gomock.Call
 }
 
 // Return rewrite *gomock.Call.Return
-func (c *MockEarlyConnectionOpenUniStreamSyncCall) Return(arg0 quic.SendStream, arg1 error) *MockEarlyConnectionOpenUniStreamSyncCall {
-	c.Call = c.Call.Return(arg0, arg1)
+func (c *MockEarlyConnectionECNStatsCall) Return(arg0 quic.ECNStats) *MockEarlyConnectionECNStatsCall {
+	c.Call = c.Call.Return(arg0)
 	return c
 }
 
 // Do rewrite *gomock.Call.Do
-func (c *MockEarlyConnectionOpenUniStreamSyncCall) Do(f func(context.Context) (quic.SendStream, error)) *MockEarlyConnectionOpenUniStreamSyncCall {
+func (c *MockEarlyConnectionECNStatsCall) Do(f func() quic.ECNStats) *MockEarlyConnectionECNStatsCall {
 	c.Call = c.Call.Do(f)
 	return c
 }
 
 // DoAndReturn rewrite *gomock.Call.DoAndReturn
-func (c *MockEarlyConnectionOpenUniStreamSyncCall) DoAndReturn(f func(context.Context) (quic.SendStream, error)) *MockEarlyConnectionOpenUniStreamSyncCall {
+func (c *MockEarlyConnectionECNStatsCall) DoAndReturn(f func() quic.ECNStats) *MockEarlyConnectionECNStatsCall {
 	c.Call = c.Call.DoAndReturn(f)
 	return c
 }
 
-// ReceiveDatagram mocks base method.
-func (m *MockEarlyConnection) ReceiveDatagram(arg0 context.Context) ([]byte, error) {
+// EstimatedBandwidth mocks base method.
+func (m *MockEarlyConnection) EstimatedBandwidth() (uint64, bool) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ReceiveDatagram", arg0)
-	ret0, _ := ret[0].([]byte)
-	ret1, _ := ret[1].(error)
+	ret := m.ctrl.Call(m, "EstimatedBandwidth")
+	ret0, _ := ret[0].(uint64)
+	ret1, _ := ret[1].(bool)
 	return ret0, ret1
 }
 
-// ReceiveDatagram indicates an expected call of ReceiveDatagram.
-func (mr *MockEarlyConnectionMockRecorder) ReceiveDatagram(arg0 any) *MockEarlyConnectionReceiveDatagramCall {
+// EstimatedBandwidth indicates an expected call of EstimatedBandwidth.
+func (mr *MockEarlyConnectionMockRecorder) EstimatedBandwidth() *MockEarlyConnectionEstimatedBandwidthCall {
 	mr.mock.ctrl.T.Helper()
-	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReceiveDatagram", reflect.TypeOf((*MockEarlyConnection)(nil).ReceiveDatagram), arg0)
-	return &MockEarlyConnectionReceiveDatagramCall{Call: call}
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EstimatedBandwidth", reflect.TypeOf((*MockEarlyConnection)(nil).EstimatedBandwidth))
+	return &MockEarlyConnectionEstimatedBandwidthCall{Call: call}
 }
 
-// MockEarlyConnectionReceiveDatagramCall wrap *gomock.Call
-type MockEarlyConnectionReceiveDatagramCall struct {
+// MockEarlyConnectionEstimatedBandwidthCall wrap *gomock.Call
+type MockEarlyConnectionEstimatedBandwidthCall struct {
 	*gomock.Call
 }
 
 // Return rewrite *gomock.Call.Return
-func (c *MockEarlyConnectionReceiveDatagramCall) Return(arg0 []byte, arg1 error) *MockEarlyConnectionReceiveDatagramCall {
+func (c *MockEarlyConnectionEstimatedBandwidthCall) Return(arg0 uint64, arg1 bool) *MockEarlyConnectionEstimatedBandwidthCall {
 	c.Call = c.Call.Return(arg0, arg1)
 	return c
 }
 
 // Do rewrite *gomock.Call.Do
-func (c *MockEarlyConnectionReceiveDatagramCall) Do(f func(context.Context) ([]byte, error)) *MockEarlyConnectionReceiveDatagramCall {
+func (c *MockEarlyConnectionEstimatedBandwidthCall) Do(f func() (uint64, bool)) *MockEarlyConnectionEstimatedBandwidthCall {
 	c.Call = c.Call.Do(f)
 	return c
 }
 
 // DoAndReturn rewrite *gomock.Call.DoAndReturn
-func (c *MockEarlyConnectionReceiveDatagramCall) DoAndReturn(f func(context.Context) ([]byte, error)) *MockEarlyConnectionReceiveDatagramCall {
+func (c *MockEarlyConnectionEstimatedBandwidthCall) DoAndReturn(f func() (uint64, bool)) *MockEarlyConnectionEstimatedBandwidthCall {
 	c.Call = c.Call.DoAndReturn(f)
 	return c
 }
 
-// RemoteAddr mocks base method.
-func (m *MockEarlyConnection) RemoteAddr() net.Addr {
+// Flush mocks base method.
+func (m *MockEarlyConnection) Flush() error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "RemoteAddr")
-	ret0, _ := ret[0].(net.Addr)
+	ret := m.ctrl.Call(m, "Flush")
+	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// RemoteAddr indicates an expected call of RemoteAddr.
-func (mr *MockEarlyConnectionMockRecorder) RemoteAddr() *MockEarlyConnectionRemoteAddrCall {
+// Flush indicates an expected call of Flush.
+func (mr *MockEarlyConnectionMockRecorder) Flush() *MockEarlyConnectionFlushCall {
 	mr.mock.ctrl.T.Helper()
-	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoteAddr", reflect.TypeOf((*MockEarlyConnection)(nil).RemoteAddr))
-	return &MockEarlyConnectionRemoteAddrCall{Call: call}
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Flush", reflect.TypeOf((*MockEarlyConnection)(nil).Flush))
+	return &MockEarlyConnectionFlushCall{Call: call}
 }
 
-// MockEarlyConnectionRemoteAddrCall wrap *gomock.Call
-type MockEarlyConnectionRemoteAddrCall struct {
+// MockEarlyConnectionFlushCall wrap *gomock.Call
+type MockEarlyConnectionFlushCall struct {
 	*gomock.Call
 }
 
 // Return rewrite *gomock.Call.Return
-func (c *MockEarlyConnectionRemoteAddrCall) Return(arg0 net.Addr) *MockEarlyConnectionRemoteAddrCall {
+func (c *MockEarlyConnectionFlushCall) Return(arg0 error) *MockEarlyConnectionFlushCall {
 	c.Call = c.Call.Return(arg0)
 	return c
 }
 
 // Do rewrite *gomock.Call.Do
-func (c *MockEarlyConnectionRemoteAddrCall) Do(f func() net.Addr) *MockEarlyConnectionRemoteAddrCall {
+func (c *MockEarlyConnectionFlushCall) Do(f func() error) *MockEarlyConnectionFlushCall {
 	c.Call = c.Call.Do(f)
 	return c
 }
 
 // DoAndReturn rewrite *gomock.Call.DoAndReturn
-func (c *MockEarlyConnectionRemoteAddrCall) DoAndReturn(f func() net.Addr) *MockEarlyConnectionRemoteAddrCall {
+func (c *MockEarlyConnectionFlushCall) DoAndReturn(f func() error) *MockEarlyConnectionFlushCall {
 	c.Call = c.Call.DoAndReturn(f)
 	return c
 }
 
-// SendDatagram mocks base method.
-func (m *MockEarlyConnection) SendDatagram(arg0 []byte) error {
+// GetConfig mocks base method.
+func (m *MockEarlyConnection) GetConfig() *quic.Config {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetConfig")
+	ret0, _ := ret[0].(*quic.Config)
+	return ret0
+}
+
+// GetConfig indicates an expected call of GetConfig.
+func (mr *MockEarlyConnectionMockRecorder) GetConfig() *MockEarlyConnectionGetConfigCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetConfig", reflect.TypeOf((*MockEarlyConnection)(nil).GetConfig))
+	return &MockEarlyConnectionGetConfigCall{Call: call}
+}
+
+// MockEarlyConnectionGetConfigCall wrap *gomock.Call
+type MockEarlyConnectionGetConfigCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockEarlyConnectionGetConfigCall) Return(arg0 *quic.Config) *MockEarlyConnectionGetConfigCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockEarlyConnectionGetConfigCall) Do(f func() *quic.Config) *MockEarlyConnectionGetConfigCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockEarlyConnectionGetConfigCall) DoAndReturn(f func() *quic.Config) *MockEarlyConnectionGetConfigCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// HandshakeComplete mocks base method.
+func (m *MockEarlyConnection) HandshakeComplete() <-chan struct{} {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HandshakeComplete")
+	ret0, _ := ret[0].(<-chan struct{})
+	return ret0
+}
+
+// HandshakeComplete indicates an expected call of HandshakeComplete.
+func (mr *MockEarlyConnectionMockRecorder) HandshakeComplete() *MockEarlyConnectionHandshakeCompleteCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HandshakeComplete", reflect.TypeOf((*MockEarlyConnection)(nil).HandshakeComplete))
+	return &MockEarlyConnectionHandshakeCompleteCall{Call: call}
+}
+
+// MockEarlyConnectionHandshakeCompleteCall wrap *gomock.Call
+type MockEarlyConnectionHandshakeCompleteCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockEarlyConnectionHandshakeCompleteCall) Return(arg0 <-chan struct{}) *MockEarlyConnectionHandshakeCompleteCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockEarlyConnectionHandshakeCompleteCall) Do(f func() <-chan struct{}) *MockEarlyConnectionHandshakeCompleteCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockEarlyConnectionHandshakeCompleteCall) DoAndReturn(f func() <-chan struct{}) *MockEarlyConnectionHandshakeCompleteCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// InitiateKeyUpdate mocks base method.
+func (m *MockEarlyConnection) InitiateKeyUpdate() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InitiateKeyUpdate")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// InitiateKeyUpdate indicates an expected call of InitiateKeyUpdate.
+func (mr *MockEarlyConnectionMockRecorder) InitiateKeyUpdate() *MockEarlyConnectionInitiateKeyUpdateCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InitiateKeyUpdate", reflect.TypeOf((*MockEarlyConnection)(nil).InitiateKeyUpdate))
+	return &MockEarlyConnectionInitiateKeyUpdateCall{Call: call}
+}
+
+// MockEarlyConnectionInitiateKeyUpdateCall wrap *gomock.Call
+type MockEarlyConnectionInitiateKeyUpdateCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockEarlyConnectionInitiateKeyUpdateCall) Return(arg0 error) *MockEarlyConnectionInitiateKeyUpdateCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockEarlyConnectionInitiateKeyUpdateCall) Do(f func() error) *MockEarlyConnectionInitiateKeyUpdateCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockEarlyConnectionInitiateKeyUpdateCall) DoAndReturn(f func() error) *MockEarlyConnectionInitiateKeyUpdateCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// KeyPhase mocks base method.
+func (m *MockEarlyConnection) KeyPhase() (byte, uint64) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "KeyPhase")
+	ret0, _ := ret[0].(byte)
+	ret1, _ := ret[1].(uint64)
+	return ret0, ret1
+}
+
+// KeyPhase indicates an expected call of KeyPhase.
+func (mr *MockEarlyConnectionMockRecorder) KeyPhase() *MockEarlyConnectionKeyPhaseCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "KeyPhase", reflect.TypeOf((*MockEarlyConnection)(nil).KeyPhase))
+	return &MockEarlyConnectionKeyPhaseCall{Call: call}
+}
+
+// MockEarlyConnectionKeyPhaseCall wrap *gomock.Call
+type MockEarlyConnectionKeyPhaseCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockEarlyConnectionKeyPhaseCall) Return(arg0 byte, arg1 uint64) *MockEarlyConnectionKeyPhaseCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockEarlyConnectionKeyPhaseCall) Do(f func() (byte, uint64)) *MockEarlyConnectionKeyPhaseCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockEarlyConnectionKeyPhaseCall) DoAndReturn(f func() (byte, uint64)) *MockEarlyConnectionKeyPhaseCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// LocalAddr mocks base method.
+func (m *MockEarlyConnection) LocalAddr() net.Addr {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LocalAddr")
+	ret0, _ := ret[0].(net.Addr)
+	return ret0
+}
+
+// LocalAddr indicates an expected call of LocalAddr.
+func (mr *MockEarlyConnectionMockRecorder) LocalAddr() *MockEarlyConnectionLocalAddrCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LocalAddr", reflect.TypeOf((*MockEarlyConnection)(nil).LocalAddr))
+	return &MockEarlyConnectionLocalAddrCall{Call: call}
+}
+
+// MockEarlyConnectionLocalAddrCall wrap *gomock.Call
+type MockEarlyConnectionLocalAddrCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockEarlyConnectionLocalAddrCall) Return(arg0 net.Addr) *MockEarlyConnectionLocalAddrCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockEarlyConnectionLocalAddrCall) Do(f func() net.Addr) *MockEarlyConnectionLocalAddrCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockEarlyConnectionLocalAddrCall) DoAndReturn(f func() net.Addr) *MockEarlyConnectionLocalAddrCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// MaxDatagramSize mocks base method.
+func (m *MockEarlyConnection) MaxDatagramSize() int {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MaxDatagramSize")
+	ret0, _ := ret[0].(int)
+	return ret0
+}
+
+// MaxDatagramSize indicates an expected call of MaxDatagramSize.
+func (mr *MockEarlyConnectionMockRecorder) MaxDatagramSize() *MockEarlyConnectionMaxDatagramSizeCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MaxDatagramSize", reflect.TypeOf((*MockEarlyConnection)(nil).MaxDatagramSize))
+	return &MockEarlyConnectionMaxDatagramSizeCall{Call: call}
+}
+
+// MockEarlyConnectionMaxDatagramSizeCall wrap *gomock.Call
+type MockEarlyConnectionMaxDatagramSizeCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockEarlyConnectionMaxDatagramSizeCall) Return(arg0 int) *MockEarlyConnectionMaxDatagramSizeCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockEarlyConnectionMaxDatagramSizeCall) Do(f func() int) *MockEarlyConnectionMaxDatagramSizeCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockEarlyConnectionMaxDatagramSizeCall) DoAndReturn(f func() int) *MockEarlyConnectionMaxDatagramSizeCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// MigrateTo mocks base method.
+func (m *MockEarlyConnection) MigrateTo(arg0 net.Addr) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MigrateTo", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MigrateTo indicates an expected call of MigrateTo.
+func (mr *MockEarlyConnectionMockRecorder) MigrateTo(arg0 any) *MockEarlyConnectionMigrateToCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MigrateTo", reflect.TypeOf((*MockEarlyConnection)(nil).MigrateTo), arg0)
+	return &MockEarlyConnectionMigrateToCall{Call: call}
+}
+
+// MockEarlyConnectionMigrateToCall wrap *gomock.Call
+type MockEarlyConnectionMigrateToCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockEarlyConnectionMigrateToCall) Return(arg0 error) *MockEarlyConnectionMigrateToCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockEarlyConnectionMigrateToCall) Do(f func(net.Addr) error) *MockEarlyConnectionMigrateToCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockEarlyConnectionMigrateToCall) DoAndReturn(f func(net.Addr) error) *MockEarlyConnectionMigrateToCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// MigrationAllowed mocks base method.
+func (m *MockEarlyConnection) MigrationAllowed() bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MigrationAllowed")
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// MigrationAllowed indicates an expected call of MigrationAllowed.
+func (mr *MockEarlyConnectionMockRecorder) MigrationAllowed() *MockEarlyConnectionMigrationAllowedCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MigrationAllowed", reflect.TypeOf((*MockEarlyConnection)(nil).MigrationAllowed))
+	return &MockEarlyConnectionMigrationAllowedCall{Call: call}
+}
+
+// MockEarlyConnectionMigrationAllowedCall wrap *gomock.Call
+type MockEarlyConnectionMigrationAllowedCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockEarlyConnectionMigrationAllowedCall) Return(arg0 bool) *MockEarlyConnectionMigrationAllowedCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockEarlyConnectionMigrationAllowedCall) Do(f func() bool) *MockEarlyConnectionMigrationAllowedCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockEarlyConnectionMigrationAllowedCall) DoAndReturn(f func() bool) *MockEarlyConnectionMigrationAllowedCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// NextConnection mocks base method.
+func (m *MockEarlyConnection) NextConnection(arg0 context.Context) (quic.Connection, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "NextConnection", arg0)
+	ret0, _ := ret[0].(quic.Connection)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// NextConnection indicates an expected call of NextConnection.
+func (mr *MockEarlyConnectionMockRecorder) NextConnection(arg0 any) *MockEarlyConnectionNextConnectionCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NextConnection", reflect.TypeOf((*MockEarlyConnection)(nil).NextConnection), arg0)
+	return &MockEarlyConnectionNextConnectionCall{Call: call}
+}
+
+// MockEarlyConnectionNextConnectionCall wrap *gomock.Call
+type MockEarlyConnectionNextConnectionCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockEarlyConnectionNextConnectionCall) Return(arg0 quic.Connection, arg1 error) *MockEarlyConnectionNextConnectionCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockEarlyConnectionNextConnectionCall) Do(f func(context.Context) (quic.Connection, error)) *MockEarlyConnectionNextConnectionCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockEarlyConnectionNextConnectionCall) DoAndReturn(f func(context.Context) (quic.Connection, error)) *MockEarlyConnectionNextConnectionCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// OpenStream mocks base method.
+func (m *MockEarlyConnection) OpenStream() (quic.Stream, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "OpenStream")
+	ret0, _ := ret[0].(quic.Stream)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// OpenStream indicates an expected call of OpenStream.
+func (mr *MockEarlyConnectionMockRecorder) OpenStream() *MockEarlyConnectionOpenStreamCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OpenStream", reflect.TypeOf((*MockEarlyConnection)(nil).OpenStream))
+	return &MockEarlyConnectionOpenStreamCall{Call: call}
+}
+
+// MockEarlyConnectionOpenStreamCall wrap *gomock.Call
+type MockEarlyConnectionOpenStreamCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockEarlyConnectionOpenStreamCall) Return(arg0 quic.Stream, arg1 error) *MockEarlyConnectionOpenStreamCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockEarlyConnectionOpenStreamCall) Do(f func() (quic.Stream, error)) *MockEarlyConnectionOpenStreamCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockEarlyConnectionOpenStreamCall) DoAndReturn(f func() (quic.Stream, error)) *MockEarlyConnectionOpenStreamCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// OpenStreamSync mocks base method.
+func (m *MockEarlyConnection) OpenStreamSync(arg0 context.Context) (quic.Stream, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "OpenStreamSync", arg0)
+	ret0, _ := ret[0].(quic.Stream)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// OpenStreamSync indicates an expected call of OpenStreamSync.
+func (mr *MockEarlyConnectionMockRecorder) OpenStreamSync(arg0 any) *MockEarlyConnectionOpenStreamSyncCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OpenStreamSync", reflect.TypeOf((*MockEarlyConnection)(nil).OpenStreamSync), arg0)
+	return &MockEarlyConnectionOpenStreamSyncCall{Call: call}
+}
+
+// MockEarlyConnectionOpenStreamSyncCall wrap *gomock.Call
+type MockEarlyConnectionOpenStreamSyncCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockEarlyConnectionOpenStreamSyncCall) Return(arg0 quic.Stream, arg1 error) *MockEarlyConnectionOpenStreamSyncCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockEarlyConnectionOpenStreamSyncCall) Do(f func(context.Context) (quic.Stream, error)) *MockEarlyConnectionOpenStreamSyncCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockEarlyConnectionOpenStreamSyncCall) DoAndReturn(f func(context.Context) (quic.Stream, error)) *MockEarlyConnectionOpenStreamSyncCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// OpenUniStream mocks base method.
+func (m *MockEarlyConnection) OpenUniStream() (quic.SendStream, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "OpenUniStream")
+	ret0, _ := ret[0].(quic.SendStream)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// OpenUniStream indicates an expected call of OpenUniStream.
+func (mr *MockEarlyConnectionMockRecorder) OpenUniStream() *MockEarlyConnectionOpenUniStreamCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OpenUniStream", reflect.TypeOf((*MockEarlyConnection)(nil).OpenUniStream))
+	return &MockEarlyConnectionOpenUniStreamCall{Call: call}
+}
+
+// MockEarlyConnectionOpenUniStreamCall wrap *gomock.Call
+type MockEarlyConnectionOpenUniStreamCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockEarlyConnectionOpenUniStreamCall) Return(arg0 quic.SendStream, arg1 error) *MockEarlyConnectionOpenUniStreamCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockEarlyConnectionOpenUniStreamCall) Do(f func() (quic.SendStream, error)) *MockEarlyConnectionOpenUniStreamCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockEarlyConnectionOpenUniStreamCall) DoAndReturn(f func() (quic.SendStream, error)) *MockEarlyConnectionOpenUniStreamCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// OpenUniStreamSync mocks base method.
+func (m *MockEarlyConnection) OpenUniStreamSync(arg0 context.Context) (quic.SendStream, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "OpenUniStreamSync", arg0)
+	ret0, _ := ret[0].(quic.SendStream)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// OpenUniStreamSync indicates an expected call of OpenUniStreamSync.
+func (mr *MockEarlyConnectionMockRecorder) OpenUniStreamSync(arg0 any) *MockEarlyConnectionOpenUniStreamSyncCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OpenUniStreamSync", reflect.TypeOf((*MockEarlyConnection)(nil).OpenUniStreamSync), arg0)
+	return &MockEarlyConnectionOpenUniStreamSyncCall{Call: call}
+}
+
+// MockEarlyConnectionOpenUniStreamSyncCall wrap *gomock.Call
+type MockEarlyConnectionOpenUniStreamSyncCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockEarlyConnectionOpenUniStreamSyncCall) Return(arg0 quic.SendStream, arg1 error) *MockEarlyConnectionOpenUniStreamSyncCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockEarlyConnectionOpenUniStreamSyncCall) Do(f func(context.Context) (quic.SendStream, error)) *MockEarlyConnectionOpenUniStreamSyncCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockEarlyConnectionOpenUniStreamSyncCall) DoAndReturn(f func(context.Context) (quic.SendStream, error)) *MockEarlyConnectionOpenUniStreamSyncCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// PathStatus mocks base method.
+func (m *MockEarlyConnection) PathStatus(arg0 quic.PathID) (quic.PathInfo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PathStatus", arg0)
+	ret0, _ := ret[0].(quic.PathInfo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PathStatus indicates an expected call of PathStatus.
+func (mr *MockEarlyConnectionMockRecorder) PathStatus(arg0 any) *MockEarlyConnectionPathStatusCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PathStatus", reflect.TypeOf((*MockEarlyConnection)(nil).PathStatus), arg0)
+	return &MockEarlyConnectionPathStatusCall{Call: call}
+}
+
+// MockEarlyConnectionPathStatusCall wrap *gomock.Call
+type MockEarlyConnectionPathStatusCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockEarlyConnectionPathStatusCall) Return(arg0 quic.PathInfo, arg1 error) *MockEarlyConnectionPathStatusCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockEarlyConnectionPathStatusCall) Do(f func(quic.PathID) (quic.PathInfo, error)) *MockEarlyConnectionPathStatusCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockEarlyConnectionPathStatusCall) DoAndReturn(f func(quic.PathID) (quic.PathInfo, error)) *MockEarlyConnectionPathStatusCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// PeerPreferredAddress mocks base method.
+func (m *MockEarlyConnection) PeerPreferredAddress() *quic.PreferredAddress {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PeerPreferredAddress")
+	ret0, _ := ret[0].(*quic.PreferredAddress)
+	return ret0
+}
+
+// PeerPreferredAddress indicates an expected call of PeerPreferredAddress.
+func (mr *MockEarlyConnectionMockRecorder) PeerPreferredAddress() *MockEarlyConnectionPeerPreferredAddressCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PeerPreferredAddress", reflect.TypeOf((*MockEarlyConnection)(nil).PeerPreferredAddress))
+	return &MockEarlyConnectionPeerPreferredAddressCall{Call: call}
+}
+
+// MockEarlyConnectionPeerPreferredAddressCall wrap *gomock.Call
+type MockEarlyConnectionPeerPreferredAddressCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockEarlyConnectionPeerPreferredAddressCall) Return(arg0 *quic.PreferredAddress) *MockEarlyConnectionPeerPreferredAddressCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockEarlyConnectionPeerPreferredAddressCall) Do(f func() *quic.PreferredAddress) *MockEarlyConnectionPeerPreferredAddressCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockEarlyConnectionPeerPreferredAddressCall) DoAndReturn(f func() *quic.PreferredAddress) *MockEarlyConnectionPeerPreferredAddressCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// PeerTransportParameters mocks base method.
+func (m *MockEarlyConnection) PeerTransportParameters() *quic.TransportParameters {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PeerTransportParameters")
+	ret0, _ := ret[0].(*quic.TransportParameters)
+	return ret0
+}
+
+// PeerTransportParameters indicates an expected call of PeerTransportParameters.
+func (mr *MockEarlyConnectionMockRecorder) PeerTransportParameters() *MockEarlyConnectionPeerTransportParametersCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PeerTransportParameters", reflect.TypeOf((*MockEarlyConnection)(nil).PeerTransportParameters))
+	return &MockEarlyConnectionPeerTransportParametersCall{Call: call}
+}
+
+// MockEarlyConnectionPeerTransportParametersCall wrap *gomock.Call
+type MockEarlyConnectionPeerTransportParametersCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockEarlyConnectionPeerTransportParametersCall) Return(arg0 *quic.TransportParameters) *MockEarlyConnectionPeerTransportParametersCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockEarlyConnectionPeerTransportParametersCall) Do(f func() *quic.TransportParameters) *MockEarlyConnectionPeerTransportParametersCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockEarlyConnectionPeerTransportParametersCall) DoAndReturn(f func() *quic.TransportParameters) *MockEarlyConnectionPeerTransportParametersCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// Ping mocks base method.
+func (m *MockEarlyConnection) Ping(arg0 context.Context) (time.Duration, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Ping", arg0)
+	ret0, _ := ret[0].(time.Duration)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Ping indicates an expected call of Ping.
+func (mr *MockEarlyConnectionMockRecorder) Ping(arg0 any) *MockEarlyConnectionPingCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Ping", reflect.TypeOf((*MockEarlyConnection)(nil).Ping), arg0)
+	return &MockEarlyConnectionPingCall{Call: call}
+}
+
+// MockEarlyConnectionPingCall wrap *gomock.Call
+type MockEarlyConnectionPingCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockEarlyConnectionPingCall) Return(arg0 time.Duration, arg1 error) *MockEarlyConnectionPingCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockEarlyConnectionPingCall) Do(f func(context.Context) (time.Duration, error)) *MockEarlyConnectionPingCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockEarlyConnectionPingCall) DoAndReturn(f func(context.Context) (time.Duration, error)) *MockEarlyConnectionPingCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// ProbePath mocks base method.
+func (m *MockEarlyConnection) ProbePath(arg0, arg1 net.Addr) (quic.PathInfo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ProbePath", arg0, arg1)
+	ret0, _ := ret[0].(quic.PathInfo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ProbePath indicates an expected call of ProbePath.
+func (mr *MockEarlyConnectionMockRecorder) ProbePath(arg0, arg1 any) *MockEarlyConnectionProbePathCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ProbePath", reflect.TypeOf((*MockEarlyConnection)(nil).ProbePath), arg0, arg1)
+	return &MockEarlyConnectionProbePathCall{Call: call}
+}
+
+// MockEarlyConnectionProbePathCall wrap *gomock.Call
+type MockEarlyConnectionProbePathCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockEarlyConnectionProbePathCall) Return(arg0 quic.PathInfo, arg1 error) *MockEarlyConnectionProbePathCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockEarlyConnectionProbePathCall) Do(f func(net.Addr, net.Addr) (quic.PathInfo, error)) *MockEarlyConnectionProbePathCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockEarlyConnectionProbePathCall) DoAndReturn(f func(net.Addr, net.Addr) (quic.PathInfo, error)) *MockEarlyConnectionProbePathCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// ReceiveDatagram mocks base method.
+func (m *MockEarlyConnection) ReceiveDatagram(arg0 context.Context) ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReceiveDatagram", arg0)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReceiveDatagram indicates an expected call of ReceiveDatagram.
+func (mr *MockEarlyConnectionMockRecorder) ReceiveDatagram(arg0 any) *MockEarlyConnectionReceiveDatagramCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReceiveDatagram", reflect.TypeOf((*MockEarlyConnection)(nil).ReceiveDatagram), arg0)
+	return &MockEarlyConnectionReceiveDatagramCall{Call: call}
+}
+
+// MockEarlyConnectionReceiveDatagramCall wrap *gomock.Call
+type MockEarlyConnectionReceiveDatagramCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockEarlyConnectionReceiveDatagramCall) Return(arg0 []byte, arg1 error) *MockEarlyConnectionReceiveDatagramCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockEarlyConnectionReceiveDatagramCall) Do(f func(context.Context) ([]byte, error)) *MockEarlyConnectionReceiveDatagramCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockEarlyConnectionReceiveDatagramCall) DoAndReturn(f func(context.Context) ([]byte, error)) *MockEarlyConnectionReceiveDatagramCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// ReceiveDatagramBuffer mocks base method.
+func (m *MockEarlyConnection) ReceiveDatagramBuffer(arg0 context.Context) (*quic.DatagramBuffer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReceiveDatagramBuffer", arg0)
+	ret0, _ := ret[0].(*quic.DatagramBuffer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReceiveDatagramBuffer indicates an expected call of ReceiveDatagramBuffer.
+func (mr *MockEarlyConnectionMockRecorder) ReceiveDatagramBuffer(arg0 any) *MockEarlyConnectionReceiveDatagramBufferCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReceiveDatagramBuffer", reflect.TypeOf((*MockEarlyConnection)(nil).ReceiveDatagramBuffer), arg0)
+	return &MockEarlyConnectionReceiveDatagramBufferCall{Call: call}
+}
+
+// MockEarlyConnectionReceiveDatagramBufferCall wrap *gomock.Call
+type MockEarlyConnectionReceiveDatagramBufferCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockEarlyConnectionReceiveDatagramBufferCall) Return(arg0 *quic.DatagramBuffer, arg1 error) *MockEarlyConnectionReceiveDatagramBufferCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockEarlyConnectionReceiveDatagramBufferCall) Do(f func(context.Context) (*quic.DatagramBuffer, error)) *MockEarlyConnectionReceiveDatagramBufferCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockEarlyConnectionReceiveDatagramBufferCall) DoAndReturn(f func(context.Context) (*quic.DatagramBuffer, error)) *MockEarlyConnectionReceiveDatagramBufferCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// ReceiveDatagramWithTime mocks base method.
+func (m *MockEarlyConnection) ReceiveDatagramWithTime(arg0 context.Context) ([]byte, time.Time, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReceiveDatagramWithTime", arg0)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(time.Time)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ReceiveDatagramWithTime indicates an expected call of ReceiveDatagramWithTime.
+func (mr *MockEarlyConnectionMockRecorder) ReceiveDatagramWithTime(arg0 any) *MockEarlyConnectionReceiveDatagramWithTimeCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReceiveDatagramWithTime", reflect.TypeOf((*MockEarlyConnection)(nil).ReceiveDatagramWithTime), arg0)
+	return &MockEarlyConnectionReceiveDatagramWithTimeCall{Call: call}
+}
+
+// MockEarlyConnectionReceiveDatagramWithTimeCall wrap *gomock.Call
+type MockEarlyConnectionReceiveDatagramWithTimeCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockEarlyConnectionReceiveDatagramWithTimeCall) Return(arg0 []byte, arg1 time.Time, arg2 error) *MockEarlyConnectionReceiveDatagramWithTimeCall {
+	c.Call = c.Call.Return(arg0, arg1, arg2)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockEarlyConnectionReceiveDatagramWithTimeCall) Do(f func(context.Context) ([]byte, time.Time, error)) *MockEarlyConnectionReceiveDatagramWithTimeCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockEarlyConnectionReceiveDatagramWithTimeCall) DoAndReturn(f func(context.Context) ([]byte, time.Time, error)) *MockEarlyConnectionReceiveDatagramWithTimeCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// ReceiveLargeDatagram mocks base method.
+func (m *MockEarlyConnection) ReceiveLargeDatagram(arg0 context.Context) ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReceiveLargeDatagram", arg0)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReceiveLargeDatagram indicates an expected call of ReceiveLargeDatagram.
+func (mr *MockEarlyConnectionMockRecorder) ReceiveLargeDatagram(arg0 any) *MockEarlyConnectionReceiveLargeDatagramCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReceiveLargeDatagram", reflect.TypeOf((*MockEarlyConnection)(nil).ReceiveLargeDatagram), arg0)
+	return &MockEarlyConnectionReceiveLargeDatagramCall{Call: call}
+}
+
+// MockEarlyConnectionReceiveLargeDatagramCall wrap *gomock.Call
+type MockEarlyConnectionReceiveLargeDatagramCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockEarlyConnectionReceiveLargeDatagramCall) Return(arg0 []byte, arg1 error) *MockEarlyConnectionReceiveLargeDatagramCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockEarlyConnectionReceiveLargeDatagramCall) Do(f func(context.Context) ([]byte, error)) *MockEarlyConnectionReceiveLargeDatagramCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockEarlyConnectionReceiveLargeDatagramCall) DoAndReturn(f func(context.Context) ([]byte, error)) *MockEarlyConnectionReceiveLargeDatagramCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// RemoteAddr mocks base method.
+func (m *MockEarlyConnection) RemoteAddr() net.Addr {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoteAddr")
+	ret0, _ := ret[0].(net.Addr)
+	return ret0
+}
+
+// RemoteAddr indicates an expected call of RemoteAddr.
+func (mr *MockEarlyConnectionMockRecorder) RemoteAddr() *MockEarlyConnectionRemoteAddrCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoteAddr", reflect.TypeOf((*MockEarlyConnection)(nil).RemoteAddr))
+	return &MockEarlyConnectionRemoteAddrCall{Call: call}
+}
+
+// MockEarlyConnectionRemoteAddrCall wrap *gomock.Call
+type MockEarlyConnectionRemoteAddrCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockEarlyConnectionRemoteAddrCall) Return(arg0 net.Addr) *MockEarlyConnectionRemoteAddrCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockEarlyConnectionRemoteAddrCall) Do(f func() net.Addr) *MockEarlyConnectionRemoteAddrCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockEarlyConnectionRemoteAddrCall) DoAndReturn(f func() net.Addr) *MockEarlyConnectionRemoteAddrCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// RemovePath mocks base method.
+func (m *MockEarlyConnection) RemovePath(arg0 quic.PathID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemovePath", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemovePath indicates an expected call of RemovePath.
+func (mr *MockEarlyConnectionMockRecorder) RemovePath(arg0 any) *MockEarlyConnectionRemovePathCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemovePath", reflect.TypeOf((*MockEarlyConnection)(nil).RemovePath), arg0)
+	return &MockEarlyConnectionRemovePathCall{Call: call}
+}
+
+// MockEarlyConnectionRemovePathCall wrap *gomock.Call
+type MockEarlyConnectionRemovePathCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockEarlyConnectionRemovePathCall) Return(arg0 error) *MockEarlyConnectionRemovePathCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockEarlyConnectionRemovePathCall) Do(f func(quic.PathID) error) *MockEarlyConnectionRemovePathCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockEarlyConnectionRemovePathCall) DoAndReturn(f func(quic.PathID) error) *MockEarlyConnectionRemovePathCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// RequestAckFrequency mocks base method.
+func (m *MockEarlyConnection) RequestAckFrequency(arg0 uint64, arg1 time.Duration) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RequestAckFrequency", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RequestAckFrequency indicates an expected call of RequestAckFrequency.
+func (mr *MockEarlyConnectionMockRecorder) RequestAckFrequency(arg0, arg1 any) *MockEarlyConnectionRequestAckFrequencyCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RequestAckFrequency", reflect.TypeOf((*MockEarlyConnection)(nil).RequestAckFrequency), arg0, arg1)
+	return &MockEarlyConnectionRequestAckFrequencyCall{Call: call}
+}
+
+// MockEarlyConnectionRequestAckFrequencyCall wrap *gomock.Call
+type MockEarlyConnectionRequestAckFrequencyCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockEarlyConnectionRequestAckFrequencyCall) Return(arg0 error) *MockEarlyConnectionRequestAckFrequencyCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockEarlyConnectionRequestAckFrequencyCall) Do(f func(uint64, time.Duration) error) *MockEarlyConnectionRequestAckFrequencyCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockEarlyConnectionRequestAckFrequencyCall) DoAndReturn(f func(uint64, time.Duration) error) *MockEarlyConnectionRequestAckFrequencyCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// RequestImmediateAck mocks base method.
+func (m *MockEarlyConnection) RequestImmediateAck() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RequestImmediateAck")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RequestImmediateAck indicates an expected call of RequestImmediateAck.
+func (mr *MockEarlyConnectionMockRecorder) RequestImmediateAck() *MockEarlyConnectionRequestImmediateAckCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RequestImmediateAck", reflect.TypeOf((*MockEarlyConnection)(nil).RequestImmediateAck))
+	return &MockEarlyConnectionRequestImmediateAckCall{Call: call}
+}
+
+// MockEarlyConnectionRequestImmediateAckCall wrap *gomock.Call
+type MockEarlyConnectionRequestImmediateAckCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockEarlyConnectionRequestImmediateAckCall) Return(arg0 error) *MockEarlyConnectionRequestImmediateAckCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockEarlyConnectionRequestImmediateAckCall) Do(f func() error) *MockEarlyConnectionRequestImmediateAckCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockEarlyConnectionRequestImmediateAckCall) DoAndReturn(f func() error) *MockEarlyConnectionRequestImmediateAckCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// SendCustomFrame mocks base method.
+func (m *MockEarlyConnection) SendCustomFrame(arg0 uint64, arg1 []byte) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendCustomFrame", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendCustomFrame indicates an expected call of SendCustomFrame.
+func (mr *MockEarlyConnectionMockRecorder) SendCustomFrame(arg0, arg1 any) *MockEarlyConnectionSendCustomFrameCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendCustomFrame", reflect.TypeOf((*MockEarlyConnection)(nil).SendCustomFrame), arg0, arg1)
+	return &MockEarlyConnectionSendCustomFrameCall{Call: call}
+}
+
+// MockEarlyConnectionSendCustomFrameCall wrap *gomock.Call
+type MockEarlyConnectionSendCustomFrameCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockEarlyConnectionSendCustomFrameCall) Return(arg0 error) *MockEarlyConnectionSendCustomFrameCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockEarlyConnectionSendCustomFrameCall) Do(f func(uint64, []byte) error) *MockEarlyConnectionSendCustomFrameCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockEarlyConnectionSendCustomFrameCall) DoAndReturn(f func(uint64, []byte) error) *MockEarlyConnectionSendCustomFrameCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// SendDatagram mocks base method.
+func (m *MockEarlyConnection) SendDatagram(arg0 []byte) error {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "SendDatagram", arg0)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// SendDatagram indicates an expected call of SendDatagram.
-func (mr *MockEarlyConnectionMockRecorder) SendDatagram(arg0 any) *MockEarlyConnectionSendDatagramCall {
+// SendDatagram indicates an expected call of SendDatagram.
+func (mr *MockEarlyConnectionMockRecorder) SendDatagram(arg0 any) *MockEarlyConnectionSendDatagramCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendDatagram", reflect.TypeOf((*MockEarlyConnection)(nil).SendDatagram), arg0)
+	return &MockEarlyConnectionSendDatagramCall{Call: call}
+}
+
+// MockEarlyConnectionSendDatagramCall wrap *gomock.Call
+type MockEarlyConnectionSendDatagramCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockEarlyConnectionSendDatagramCall) Return(arg0 error) *MockEarlyConnectionSendDatagramCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockEarlyConnectionSendDatagramCall) Do(f func([]byte) error) *MockEarlyConnectionSendDatagramCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockEarlyConnectionSendDatagramCall) DoAndReturn(f func([]byte) error) *MockEarlyConnectionSendDatagramCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// SendKeepAlive mocks base method.
+func (m *MockEarlyConnection) SendKeepAlive() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendKeepAlive")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendKeepAlive indicates an expected call of SendKeepAlive.
+func (mr *MockEarlyConnectionMockRecorder) SendKeepAlive() *MockEarlyConnectionSendKeepAliveCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendKeepAlive", reflect.TypeOf((*MockEarlyConnection)(nil).SendKeepAlive))
+	return &MockEarlyConnectionSendKeepAliveCall{Call: call}
+}
+
+// MockEarlyConnectionSendKeepAliveCall wrap *gomock.Call
+type MockEarlyConnectionSendKeepAliveCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockEarlyConnectionSendKeepAliveCall) Return(arg0 error) *MockEarlyConnectionSendKeepAliveCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockEarlyConnectionSendKeepAliveCall) Do(f func() error) *MockEarlyConnectionSendKeepAliveCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockEarlyConnectionSendKeepAliveCall) DoAndReturn(f func() error) *MockEarlyConnectionSendKeepAliveCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// SendLargeDatagram mocks base method.
+func (m *MockEarlyConnection) SendLargeDatagram(arg0 []byte) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendLargeDatagram", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendLargeDatagram indicates an expected call of SendLargeDatagram.
+func (mr *MockEarlyConnectionMockRecorder) SendLargeDatagram(arg0 any) *MockEarlyConnectionSendLargeDatagramCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendLargeDatagram", reflect.TypeOf((*MockEarlyConnection)(nil).SendLargeDatagram), arg0)
+	return &MockEarlyConnectionSendLargeDatagramCall{Call: call}
+}
+
+// MockEarlyConnectionSendLargeDatagramCall wrap *gomock.Call
+type MockEarlyConnectionSendLargeDatagramCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockEarlyConnectionSendLargeDatagramCall) Return(arg0 error) *MockEarlyConnectionSendLargeDatagramCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockEarlyConnectionSendLargeDatagramCall) Do(f func([]byte) error) *MockEarlyConnectionSendLargeDatagramCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockEarlyConnectionSendLargeDatagramCall) DoAndReturn(f func([]byte) error) *MockEarlyConnectionSendLargeDatagramCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// SendMessage mocks base method.
+func (m *MockEarlyConnection) SendMessage(arg0 context.Context, arg1 []byte) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendMessage", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendMessage indicates an expected call of SendMessage.
+func (mr *MockEarlyConnectionMockRecorder) SendMessage(arg0, arg1 any) *MockEarlyConnectionSendMessageCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendMessage", reflect.TypeOf((*MockEarlyConnection)(nil).SendMessage), arg0, arg1)
+	return &MockEarlyConnectionSendMessageCall{Call: call}
+}
+
+// MockEarlyConnectionSendMessageCall wrap *gomock.Call
+type MockEarlyConnectionSendMessageCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockEarlyConnectionSendMessageCall) Return(arg0 error) *MockEarlyConnectionSendMessageCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockEarlyConnectionSendMessageCall) Do(f func(context.Context, []byte) error) *MockEarlyConnectionSendMessageCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockEarlyConnectionSendMessageCall) DoAndReturn(f func(context.Context, []byte) error) *MockEarlyConnectionSendMessageCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// SetDatagramSendTimeout mocks base method.
+func (m *MockEarlyConnection) SetDatagramSendTimeout(arg0 time.Duration) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetDatagramSendTimeout", arg0)
+}
+
+// SetDatagramSendTimeout indicates an expected call of SetDatagramSendTimeout.
+func (mr *MockEarlyConnectionMockRecorder) SetDatagramSendTimeout(arg0 any) *MockEarlyConnectionSetDatagramSendTimeoutCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetDatagramSendTimeout", reflect.TypeOf((*MockEarlyConnection)(nil).SetDatagramSendTimeout), arg0)
+	return &MockEarlyConnectionSetDatagramSendTimeoutCall{Call: call}
+}
+
+// MockEarlyConnectionSetDatagramSendTimeoutCall wrap *gomock.Call
+type MockEarlyConnectionSetDatagramSendTimeoutCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockEarlyConnectionSetDatagramSendTimeoutCall) Return() *MockEarlyConnectionSetDatagramSendTimeoutCall {
+	c.Call = c.Call.Return()
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockEarlyConnectionSetDatagramSendTimeoutCall) Do(f func(time.Duration)) *MockEarlyConnectionSetDatagramSendTimeoutCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockEarlyConnectionSetDatagramSendTimeoutCall) DoAndReturn(f func(time.Duration)) *MockEarlyConnectionSetDatagramSendTimeoutCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// SetMaxIncomingStreams mocks base method.
+func (m *MockEarlyConnection) SetMaxIncomingStreams(arg0 int64) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetMaxIncomingStreams", arg0)
+}
+
+// SetMaxIncomingStreams indicates an expected call of SetMaxIncomingStreams.
+func (mr *MockEarlyConnectionMockRecorder) SetMaxIncomingStreams(arg0 any) *MockEarlyConnectionSetMaxIncomingStreamsCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetMaxIncomingStreams", reflect.TypeOf((*MockEarlyConnection)(nil).SetMaxIncomingStreams), arg0)
+	return &MockEarlyConnectionSetMaxIncomingStreamsCall{Call: call}
+}
+
+// MockEarlyConnectionSetMaxIncomingStreamsCall wrap *gomock.Call
+type MockEarlyConnectionSetMaxIncomingStreamsCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockEarlyConnectionSetMaxIncomingStreamsCall) Return() *MockEarlyConnectionSetMaxIncomingStreamsCall {
+	c.Call = c.Call.Return()
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockEarlyConnectionSetMaxIncomingStreamsCall) Do(f func(int64)) *MockEarlyConnectionSetMaxIncomingStreamsCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockEarlyConnectionSetMaxIncomingStreamsCall) DoAndReturn(f func(int64)) *MockEarlyConnectionSetMaxIncomingStreamsCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// SetMaxIncomingUniStreams mocks base method.
+func (m *MockEarlyConnection) SetMaxIncomingUniStreams(arg0 int64) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetMaxIncomingUniStreams", arg0)
+}
+
+// SetMaxIncomingUniStreams indicates an expected call of SetMaxIncomingUniStreams.
+func (mr *MockEarlyConnectionMockRecorder) SetMaxIncomingUniStreams(arg0 any) *MockEarlyConnectionSetMaxIncomingUniStreamsCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetMaxIncomingUniStreams", reflect.TypeOf((*MockEarlyConnection)(nil).SetMaxIncomingUniStreams), arg0)
+	return &MockEarlyConnectionSetMaxIncomingUniStreamsCall{Call: call}
+}
+
+// MockEarlyConnectionSetMaxIncomingUniStreamsCall wrap *gomock.Call
+type MockEarlyConnectionSetMaxIncomingUniStreamsCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockEarlyConnectionSetMaxIncomingUniStreamsCall) Return() *MockEarlyConnectionSetMaxIncomingUniStreamsCall {
+	c.Call = c.Call.Return()
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockEarlyConnectionSetMaxIncomingUniStreamsCall) Do(f func(int64)) *MockEarlyConnectionSetMaxIncomingUniStreamsCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockEarlyConnectionSetMaxIncomingUniStreamsCall) DoAndReturn(f func(int64)) *MockEarlyConnectionSetMaxIncomingUniStreamsCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// SetReceiveWindow mocks base method.
+func (m *MockEarlyConnection) SetReceiveWindow(arg0 protocol.ByteCount) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetReceiveWindow", arg0)
+}
+
+// SetReceiveWindow indicates an expected call of SetReceiveWindow.
+func (mr *MockEarlyConnectionMockRecorder) SetReceiveWindow(arg0 any) *MockEarlyConnectionSetReceiveWindowCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetReceiveWindow", reflect.TypeOf((*MockEarlyConnection)(nil).SetReceiveWindow), arg0)
+	return &MockEarlyConnectionSetReceiveWindowCall{Call: call}
+}
+
+// MockEarlyConnectionSetReceiveWindowCall wrap *gomock.Call
+type MockEarlyConnectionSetReceiveWindowCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockEarlyConnectionSetReceiveWindowCall) Return() *MockEarlyConnectionSetReceiveWindowCall {
+	c.Call = c.Call.Return()
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockEarlyConnectionSetReceiveWindowCall) Do(f func(protocol.ByteCount)) *MockEarlyConnectionSetReceiveWindowCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockEarlyConnectionSetReceiveWindowCall) DoAndReturn(f func(protocol.ByteCount)) *MockEarlyConnectionSetReceiveWindowCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// SpinBit mocks base method.
+func (m *MockEarlyConnection) SpinBit() byte {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SpinBit")
+	ret0, _ := ret[0].(byte)
+	return ret0
+}
+
+// SpinBit indicates an expected call of SpinBit.
+func (mr *MockEarlyConnectionMockRecorder) SpinBit() *MockEarlyConnectionSpinBitCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SpinBit", reflect.TypeOf((*MockEarlyConnection)(nil).SpinBit))
+	return &MockEarlyConnectionSpinBitCall{Call: call}
+}
+
+// MockEarlyConnectionSpinBitCall wrap *gomock.Call
+type MockEarlyConnectionSpinBitCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockEarlyConnectionSpinBitCall) Return(arg0 byte) *MockEarlyConnectionSpinBitCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockEarlyConnectionSpinBitCall) Do(f func() byte) *MockEarlyConnectionSpinBitCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockEarlyConnectionSpinBitCall) DoAndReturn(f func() byte) *MockEarlyConnectionSpinBitCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// StatelessResetToken mocks base method.
+func (m *MockEarlyConnection) StatelessResetToken() (protocol.StatelessResetToken, bool) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StatelessResetToken")
+	ret0, _ := ret[0].(protocol.StatelessResetToken)
+	ret1, _ := ret[1].(bool)
+	return ret0, ret1
+}
+
+// StatelessResetToken indicates an expected call of StatelessResetToken.
+func (mr *MockEarlyConnectionMockRecorder) StatelessResetToken() *MockEarlyConnectionStatelessResetTokenCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StatelessResetToken", reflect.TypeOf((*MockEarlyConnection)(nil).StatelessResetToken))
+	return &MockEarlyConnectionStatelessResetTokenCall{Call: call}
+}
+
+// MockEarlyConnectionStatelessResetTokenCall wrap *gomock.Call
+type MockEarlyConnectionStatelessResetTokenCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockEarlyConnectionStatelessResetTokenCall) Return(arg0 protocol.StatelessResetToken, arg1 bool) *MockEarlyConnectionStatelessResetTokenCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockEarlyConnectionStatelessResetTokenCall) Do(f func() (protocol.StatelessResetToken, bool)) *MockEarlyConnectionStatelessResetTokenCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockEarlyConnectionStatelessResetTokenCall) DoAndReturn(f func() (protocol.StatelessResetToken, bool)) *MockEarlyConnectionStatelessResetTokenCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// Stats mocks base method.
+func (m *MockEarlyConnection) Stats() quic.ConnectionStats {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Stats")
+	ret0, _ := ret[0].(quic.ConnectionStats)
+	return ret0
+}
+
+// Stats indicates an expected call of Stats.
+func (mr *MockEarlyConnectionMockRecorder) Stats() *MockEarlyConnectionStatsCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Stats", reflect.TypeOf((*MockEarlyConnection)(nil).Stats))
+	return &MockEarlyConnectionStatsCall{Call: call}
+}
+
+// MockEarlyConnectionStatsCall wrap *gomock.Call
+type MockEarlyConnectionStatsCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockEarlyConnectionStatsCall) Return(arg0 quic.ConnectionStats) *MockEarlyConnectionStatsCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockEarlyConnectionStatsCall) Do(f func() quic.ConnectionStats) *MockEarlyConnectionStatsCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockEarlyConnectionStatsCall) DoAndReturn(f func() quic.ConnectionStats) *MockEarlyConnectionStatsCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// StreamsAvailable mocks base method.
+func (m *MockEarlyConnection) StreamsAvailable() (int64, int64) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StreamsAvailable")
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(int64)
+	return ret0, ret1
+}
+
+// StreamsAvailable indicates an expected call of StreamsAvailable.
+func (mr *MockEarlyConnectionMockRecorder) StreamsAvailable() *MockEarlyConnectionStreamsAvailableCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StreamsAvailable", reflect.TypeOf((*MockEarlyConnection)(nil).StreamsAvailable))
+	return &MockEarlyConnectionStreamsAvailableCall{Call: call}
+}
+
+// MockEarlyConnectionStreamsAvailableCall wrap *gomock.Call
+type MockEarlyConnectionStreamsAvailableCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockEarlyConnectionStreamsAvailableCall) Return(arg0, arg1 int64) *MockEarlyConnectionStreamsAvailableCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockEarlyConnectionStreamsAvailableCall) Do(f func() (int64, int64)) *MockEarlyConnectionStreamsAvailableCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockEarlyConnectionStreamsAvailableCall) DoAndReturn(f func() (int64, int64)) *MockEarlyConnectionStreamsAvailableCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// SwitchToPath mocks base method.
+func (m *MockEarlyConnection) SwitchToPath(arg0 quic.PathID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SwitchToPath", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SwitchToPath indicates an expected call of SwitchToPath.
+func (mr *MockEarlyConnectionMockRecorder) SwitchToPath(arg0 any) *MockEarlyConnectionSwitchToPathCall {
 	mr.mock.ctrl.T.Helper()
-	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendDatagram", reflect.TypeOf((*MockEarlyConnection)(nil).SendDatagram), arg0)
-	return &MockEarlyConnectionSendDatagramCall{Call: call}
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SwitchToPath", reflect.TypeOf((*MockEarlyConnection)(nil).SwitchToPath), arg0)
+	return &MockEarlyConnectionSwitchToPathCall{Call: call}
 }
 
-// MockEarlyConnectionSendDatagramCall wrap *gomock.Call
-type MockEarlyConnectionSendDatagramCall struct {
+// MockEarlyConnectionSwitchToPathCall wrap *gomock.Call
+type MockEarlyConnectionSwitchToPathCall struct {
 	*gomock.Call
 }
 
 // Return rewrite *gomock.Call.Return
-func (c *MockEarlyConnectionSendDatagramCall) Return(arg0 error) *MockEarlyConnectionSendDatagramCall {
+func (c *MockEarlyConnectionSwitchToPathCall) Return(arg0 error) *MockEarlyConnectionSwitchToPathCall {
 	c.Call = c.Call.Return(arg0)
 	return c
 }
 
 // Do rewrite *gomock.Call.Do
-func (c *MockEarlyConnectionSendDatagramCall) Do(f func([]byte) error) *MockEarlyConnectionSendDatagramCall {
+func (c *MockEarlyConnectionSwitchToPathCall) Do(f func(quic.PathID) error) *MockEarlyConnectionSwitchToPathCall {
 	c.Call = c.Call.Do(f)
 	return c
 }
 
 // DoAndReturn rewrite *gomock.Call.DoAndReturn
-func (c *MockEarlyConnectionSendDatagramCall) DoAndReturn(f func([]byte) error) *MockEarlyConnectionSendDatagramCall {
+func (c *MockEarlyConnectionSwitchToPathCall) DoAndReturn(f func(quic.PathID) error) *MockEarlyConnectionSwitchToPathCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// TryReceiveDatagram mocks base method.
+func (m *MockEarlyConnection) TryReceiveDatagram() ([]byte, bool) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TryReceiveDatagram")
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(bool)
+	return ret0, ret1
+}
+
+// TryReceiveDatagram indicates an expected call of TryReceiveDatagram.
+func (mr *MockEarlyConnectionMockRecorder) TryReceiveDatagram() *MockEarlyConnectionTryReceiveDatagramCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TryReceiveDatagram", reflect.TypeOf((*MockEarlyConnection)(nil).TryReceiveDatagram))
+	return &MockEarlyConnectionTryReceiveDatagramCall{Call: call}
+}
+
+// MockEarlyConnectionTryReceiveDatagramCall wrap *gomock.Call
+type MockEarlyConnectionTryReceiveDatagramCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockEarlyConnectionTryReceiveDatagramCall) Return(arg0 []byte, arg1 bool) *MockEarlyConnectionTryReceiveDatagramCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockEarlyConnectionTryReceiveDatagramCall) Do(f func() ([]byte, bool)) *MockEarlyConnectionTryReceiveDatagramCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockEarlyConnectionTryReceiveDatagramCall) DoAndReturn(f func() ([]byte, bool)) *MockEarlyConnectionTryReceiveDatagramCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// Used0RTT mocks base method.
+func (m *MockEarlyConnection) Used0RTT() bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Used0RTT")
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// Used0RTT indicates an expected call of Used0RTT.
+func (mr *MockEarlyConnectionMockRecorder) Used0RTT() *MockEarlyConnectionUsed0RTTCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Used0RTT", reflect.TypeOf((*MockEarlyConnection)(nil).Used0RTT))
+	return &MockEarlyConnectionUsed0RTTCall{Call: call}
+}
+
+// MockEarlyConnectionUsed0RTTCall wrap *gomock.Call
+type MockEarlyConnectionUsed0RTTCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockEarlyConnectionUsed0RTTCall) Return(arg0 bool) *MockEarlyConnectionUsed0RTTCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockEarlyConnectionUsed0RTTCall) Do(f func() bool) *MockEarlyConnectionUsed0RTTCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockEarlyConnectionUsed0RTTCall) DoAndReturn(f func() bool) *MockEarlyConnectionUsed0RTTCall {
 	c.Call = c.Call.DoAndReturn(f)
 	return c
 }
@@ -14,6 +14,7 @@ import (
 	reflect "reflect"
 	time "time"
 
+	quic "github.com/quic-go/quic-go"
 	protocol "github.com/quic-go/quic-go/internal/protocol"
 	qerr "github.com/quic-go/quic-go/internal/qerr"
 	gomock "go.uber.org/mock/gomock"
@@ -42,6 +43,44 @@ func (m *MockStream) EXPECT() *MockStreamMockRecorder {
 	return m.recorder
 }
 
+// BytesAcked mocks base method.
+func (m *MockStream) BytesAcked() protocol.ByteCount {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BytesAcked")
+	ret0, _ := ret[0].(protocol.ByteCount)
+	return ret0
+}
+
+// BytesAcked indicates an expected call of BytesAcked.
+func (mr *MockStreamMockRecorder) BytesAcked() *MockStreamBytesAckedCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BytesAcked", reflect.TypeOf((*MockStream)(nil).BytesAcked))
+	return &MockStreamBytesAckedCall{Call: call}
+}
+
+// MockStreamBytesAckedCall wrap *gomock.Call
+type MockStreamBytesAckedCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockStreamBytesAckedCall) Return(arg0 protocol.ByteCount) *MockStreamBytesAckedCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockStreamBytesAckedCall) Do(f func() protocol.ByteCount) *MockStreamBytesAckedCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockStreamBytesAckedCall) DoAndReturn(f func() protocol.ByteCount) *MockStreamBytesAckedCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // CancelRead mocks base method.
 func (m *MockStream) CancelRead(arg0 qerr.StreamErrorCode) {
 	m.ctrl.T.Helper()
@@ -152,6 +191,116 @@ func (c *MockStreamCloseCall) DoAndReturn(f func() error) *MockStreamCloseCall {
 	return c
 }
 
+// CloseRead mocks base method.
+func (m *MockStream) CloseRead(arg0 qerr.StreamErrorCode) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "CloseRead", arg0)
+}
+
+// CloseRead indicates an expected call of CloseRead.
+func (mr *MockStreamMockRecorder) CloseRead(arg0 any) *MockStreamCloseReadCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CloseRead", reflect.TypeOf((*MockStream)(nil).CloseRead), arg0)
+	return &MockStreamCloseReadCall{Call: call}
+}
+
+// MockStreamCloseReadCall wrap *gomock.Call
+type MockStreamCloseReadCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockStreamCloseReadCall) Return() *MockStreamCloseReadCall {
+	c.Call = c.Call.Return()
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockStreamCloseReadCall) Do(f func(qerr.StreamErrorCode)) *MockStreamCloseReadCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockStreamCloseReadCall) DoAndReturn(f func(qerr.StreamErrorCode)) *MockStreamCloseReadCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// CloseThenReset mocks base method.
+func (m *MockStream) CloseThenReset(arg0 qerr.StreamErrorCode) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CloseThenReset", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CloseThenReset indicates an expected call of CloseThenReset.
+func (mr *MockStreamMockRecorder) CloseThenReset(arg0 any) *MockStreamCloseThenResetCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CloseThenReset", reflect.TypeOf((*MockStream)(nil).CloseThenReset), arg0)
+	return &MockStreamCloseThenResetCall{Call: call}
+}
+
+// MockStreamCloseThenResetCall wrap *gomock.Call
+type MockStreamCloseThenResetCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockStreamCloseThenResetCall) Return(arg0 error) *MockStreamCloseThenResetCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockStreamCloseThenResetCall) Do(f func(qerr.StreamErrorCode) error) *MockStreamCloseThenResetCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockStreamCloseThenResetCall) DoAndReturn(f func(qerr.StreamErrorCode) error) *MockStreamCloseThenResetCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// CloseWrite mocks base method.
+func (m *MockStream) CloseWrite() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "CloseWrite")
+}
+
+// CloseWrite indicates an expected call of CloseWrite.
+func (mr *MockStreamMockRecorder) CloseWrite() *MockStreamCloseWriteCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CloseWrite", reflect.TypeOf((*MockStream)(nil).CloseWrite))
+	return &MockStreamCloseWriteCall{Call: call}
+}
+
+// MockStreamCloseWriteCall wrap *gomock.Call
+type MockStreamCloseWriteCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockStreamCloseWriteCall) Return() *MockStreamCloseWriteCall {
+	c.Call = c.Call.Return()
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockStreamCloseWriteCall) Do(f func()) *MockStreamCloseWriteCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockStreamCloseWriteCall) DoAndReturn(f func()) *MockStreamCloseWriteCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // Context mocks base method.
 func (m *MockStream) Context() context.Context {
 	m.ctrl.T.Helper()
@@ -229,6 +378,81 @@ func (c *MockStreamReadCall) DoAndReturn(f func([]byte) (int, error)) *MockStrea
 	return c
 }
 
+// ResetAt mocks base method.
+func (m *MockStream) ResetAt(arg0 qerr.StreamErrorCode, arg1 protocol.ByteCount) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "ResetAt", arg0, arg1)
+}
+
+// ResetAt indicates an expected call of ResetAt.
+func (mr *MockStreamMockRecorder) ResetAt(arg0, arg1 any) *MockStreamResetAtCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResetAt", reflect.TypeOf((*MockStream)(nil).ResetAt), arg0, arg1)
+	return &MockStreamResetAtCall{Call: call}
+}
+
+// MockStreamResetAtCall wrap *gomock.Call
+type MockStreamResetAtCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockStreamResetAtCall) Return() *MockStreamResetAtCall {
+	c.Call = c.Call.Return()
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockStreamResetAtCall) Do(f func(qerr.StreamErrorCode, protocol.ByteCount)) *MockStreamResetAtCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockStreamResetAtCall) DoAndReturn(f func(qerr.StreamErrorCode, protocol.ByteCount)) *MockStreamResetAtCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// SafeWrite mocks base method.
+func (m *MockStream) SafeWrite(arg0 []byte) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SafeWrite", arg0)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SafeWrite indicates an expected call of SafeWrite.
+func (mr *MockStreamMockRecorder) SafeWrite(arg0 any) *MockStreamSafeWriteCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SafeWrite", reflect.TypeOf((*MockStream)(nil).SafeWrite), arg0)
+	return &MockStreamSafeWriteCall{Call: call}
+}
+
+// MockStreamSafeWriteCall wrap *gomock.Call
+type MockStreamSafeWriteCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockStreamSafeWriteCall) Return(arg0 int, arg1 error) *MockStreamSafeWriteCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockStreamSafeWriteCall) Do(f func([]byte) (int, error)) *MockStreamSafeWriteCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockStreamSafeWriteCall) DoAndReturn(f func([]byte) (int, error)) *MockStreamSafeWriteCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // SetDeadline mocks base method.
 func (m *MockStream) SetDeadline(arg0 time.Time) error {
 	m.ctrl.T.Helper()
@@ -267,6 +491,78 @@ func (c *MockStreamSetDeadlineCall) DoAndReturn(f func(time.Time) error) *MockSt
 	return c
 }
 
+// SetDrainOnReset mocks base method.
+func (m *MockStream) SetDrainOnReset(arg0 bool) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetDrainOnReset", arg0)
+}
+
+// SetDrainOnReset indicates an expected call of SetDrainOnReset.
+func (mr *MockStreamMockRecorder) SetDrainOnReset(arg0 any) *MockStreamSetDrainOnResetCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetDrainOnReset", reflect.TypeOf((*MockStream)(nil).SetDrainOnReset), arg0)
+	return &MockStreamSetDrainOnResetCall{Call: call}
+}
+
+// MockStreamSetDrainOnResetCall wrap *gomock.Call
+type MockStreamSetDrainOnResetCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockStreamSetDrainOnResetCall) Return() *MockStreamSetDrainOnResetCall {
+	c.Call = c.Call.Return()
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockStreamSetDrainOnResetCall) Do(f func(bool)) *MockStreamSetDrainOnResetCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockStreamSetDrainOnResetCall) DoAndReturn(f func(bool)) *MockStreamSetDrainOnResetCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// SetPriority mocks base method.
+func (m *MockStream) SetPriority(arg0 quic.StreamPriority) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetPriority", arg0)
+}
+
+// SetPriority indicates an expected call of SetPriority.
+func (mr *MockStreamMockRecorder) SetPriority(arg0 any) *MockStreamSetPriorityCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetPriority", reflect.TypeOf((*MockStream)(nil).SetPriority), arg0)
+	return &MockStreamSetPriorityCall{Call: call}
+}
+
+// MockStreamSetPriorityCall wrap *gomock.Call
+type MockStreamSetPriorityCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockStreamSetPriorityCall) Return() *MockStreamSetPriorityCall {
+	c.Call = c.Call.Return()
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockStreamSetPriorityCall) Do(f func(quic.StreamPriority)) *MockStreamSetPriorityCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockStreamSetPriorityCall) DoAndReturn(f func(quic.StreamPriority)) *MockStreamSetPriorityCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // SetReadDeadline mocks base method.
 func (m *MockStream) SetReadDeadline(arg0 time.Time) error {
 	m.ctrl.T.Helper()
@@ -305,6 +601,78 @@ func (c *MockStreamSetReadDeadlineCall) DoAndReturn(f func(time.Time) error) *Mo
 	return c
 }
 
+// SetReceiveWindow mocks base method.
+func (m *MockStream) SetReceiveWindow(arg0 protocol.ByteCount) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetReceiveWindow", arg0)
+}
+
+// SetReceiveWindow indicates an expected call of SetReceiveWindow.
+func (mr *MockStreamMockRecorder) SetReceiveWindow(arg0 any) *MockStreamSetReceiveWindowCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetReceiveWindow", reflect.TypeOf((*MockStream)(nil).SetReceiveWindow), arg0)
+	return &MockStreamSetReceiveWindowCall{Call: call}
+}
+
+// MockStreamSetReceiveWindowCall wrap *gomock.Call
+type MockStreamSetReceiveWindowCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockStreamSetReceiveWindowCall) Return() *MockStreamSetReceiveWindowCall {
+	c.Call = c.Call.Return()
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockStreamSetReceiveWindowCall) Do(f func(protocol.ByteCount)) *MockStreamSetReceiveWindowCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockStreamSetReceiveWindowCall) DoAndReturn(f func(protocol.ByteCount)) *MockStreamSetReceiveWindowCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// SetWriteBufferLimit mocks base method.
+func (m *MockStream) SetWriteBufferLimit(arg0 protocol.ByteCount) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetWriteBufferLimit", arg0)
+}
+
+// SetWriteBufferLimit indicates an expected call of SetWriteBufferLimit.
+func (mr *MockStreamMockRecorder) SetWriteBufferLimit(arg0 any) *MockStreamSetWriteBufferLimitCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetWriteBufferLimit", reflect.TypeOf((*MockStream)(nil).SetWriteBufferLimit), arg0)
+	return &MockStreamSetWriteBufferLimitCall{Call: call}
+}
+
+// MockStreamSetWriteBufferLimitCall wrap *gomock.Call
+type MockStreamSetWriteBufferLimitCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockStreamSetWriteBufferLimitCall) Return() *MockStreamSetWriteBufferLimitCall {
+	c.Call = c.Call.Return()
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockStreamSetWriteBufferLimitCall) Do(f func(protocol.ByteCount)) *MockStreamSetWriteBufferLimitCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockStreamSetWriteBufferLimitCall) DoAndReturn(f func(protocol.ByteCount)) *MockStreamSetWriteBufferLimitCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // SetWriteDeadline mocks base method.
 func (m *MockStream) SetWriteDeadline(arg0 time.Time) error {
 	m.ctrl.T.Helper()
@@ -343,6 +711,42 @@ func (c *MockStreamSetWriteDeadlineCall) DoAndReturn(f func(time.Time) error) *M
 	return c
 }
 
+// StopSending mocks base method.
+func (m *MockStream) StopSending(arg0 qerr.StreamErrorCode) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "StopSending", arg0)
+}
+
+// StopSending indicates an expected call of StopSending.
+func (mr *MockStreamMockRecorder) StopSending(arg0 any) *MockStreamStopSendingCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StopSending", reflect.TypeOf((*MockStream)(nil).StopSending), arg0)
+	return &MockStreamStopSendingCall{Call: call}
+}
+
+// MockStreamStopSendingCall wrap *gomock.Call
+type MockStreamStopSendingCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockStreamStopSendingCall) Return() *MockStreamStopSendingCall {
+	c.Call = c.Call.Return()
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockStreamStopSendingCall) Do(f func(qerr.StreamErrorCode)) *MockStreamStopSendingCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockStreamStopSendingCall) DoAndReturn(f func(qerr.StreamErrorCode)) *MockStreamStopSendingCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // StreamID mocks base method.
 func (m *MockStream) StreamID() protocol.StreamID {
 	m.ctrl.T.Helper()
@@ -381,6 +785,44 @@ func (c *MockStreamStreamIDCall) DoAndReturn(f func() protocol.StreamID) *MockSt
 	return c
 }
 
+// WaitForAck mocks base method.
+func (m *MockStream) WaitForAck(arg0 context.Context, arg1 protocol.ByteCount) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WaitForAck", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// WaitForAck indicates an expected call of WaitForAck.
+func (mr *MockStreamMockRecorder) WaitForAck(arg0, arg1 any) *MockStreamWaitForAckCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WaitForAck", reflect.TypeOf((*MockStream)(nil).WaitForAck), arg0, arg1)
+	return &MockStreamWaitForAckCall{Call: call}
+}
+
+// MockStreamWaitForAckCall wrap *gomock.Call
+type MockStreamWaitForAckCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockStreamWaitForAckCall) Return(arg0 error) *MockStreamWaitForAckCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockStreamWaitForAckCall) Do(f func(context.Context, protocol.ByteCount) error) *MockStreamWaitForAckCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockStreamWaitForAckCall) DoAndReturn(f func(context.Context, protocol.ByteCount) error) *MockStreamWaitForAckCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // Write mocks base method.
 func (m *MockStream) Write(arg0 []byte) (int, error) {
 	m.ctrl.T.Helper()
@@ -262,6 +262,42 @@ func (c *MockStreamFlowControllerSendWindowSizeCall) DoAndReturn(f func() protoc
 	return c
 }
 
+// SetReceiveWindowSize mocks base method.
+func (m *MockStreamFlowController) SetReceiveWindowSize(arg0 protocol.ByteCount) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetReceiveWindowSize", arg0)
+}
+
+// SetReceiveWindowSize indicates an expected call of SetReceiveWindowSize.
+func (mr *MockStreamFlowControllerMockRecorder) SetReceiveWindowSize(arg0 any) *MockStreamFlowControllerSetReceiveWindowSizeCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetReceiveWindowSize", reflect.TypeOf((*MockStreamFlowController)(nil).SetReceiveWindowSize), arg0)
+	return &MockStreamFlowControllerSetReceiveWindowSizeCall{Call: call}
+}
+
+// MockStreamFlowControllerSetReceiveWindowSizeCall wrap *gomock.Call
+type MockStreamFlowControllerSetReceiveWindowSizeCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockStreamFlowControllerSetReceiveWindowSizeCall) Return() *MockStreamFlowControllerSetReceiveWindowSizeCall {
+	c.Call = c.Call.Return()
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockStreamFlowControllerSetReceiveWindowSizeCall) Do(f func(protocol.ByteCount)) *MockStreamFlowControllerSetReceiveWindowSizeCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockStreamFlowControllerSetReceiveWindowSizeCall) DoAndReturn(f func(protocol.ByteCount)) *MockStreamFlowControllerSetReceiveWindowSizeCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // UpdateHighestReceived mocks base method.
 func (m *MockStreamFlowController) UpdateHighestReceived(arg0 protocol.ByteCount, arg1 bool) error {
 	m.ctrl.T.Helper()
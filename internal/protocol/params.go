@@ -14,7 +14,11 @@ const InitialPacketSize = 1280
 // MaxCongestionWindowPackets is the maximum congestion window in packet.
 const MaxCongestionWindowPackets = 10000
 
-// MaxUndecryptablePackets limits the number of undecryptable packets that are queued in the connection.
+// InitialCongestionWindowPackets is the default initial congestion window in packets.
+const InitialCongestionWindowPackets = 32
+
+// MaxUndecryptablePackets limits the number of undecryptable packets that are queued in the connection,
+// unless a different limit is configured.
 const MaxUndecryptablePackets = 32
 
 // ConnectionFlowControlMultiplier determines how much larger the connection flow control windows needs to be relative to any stream's flow control window
@@ -42,6 +46,14 @@ const DefaultMaxIncomingStreams = 100
 // DefaultMaxIncomingUniStreams is the maximum number of unidirectional streams that a peer may open
 const DefaultMaxIncomingUniStreams = 100
 
+// MaxReasonPhraseLength is the maximum length of the reason phrase sent in a CONNECTION_CLOSE frame.
+// Longer reason phrases are truncated, such that the CONNECTION_CLOSE frames fit into a single packet,
+// even if a copy of the frame is sent at multiple encryption levels.
+const MaxReasonPhraseLength = 256
+
+// DefaultMaxMessageSize is the default maximum size of a message received using Connection.AcceptMessage
+const DefaultMaxMessageSize = 1 << 20 // 1 MB
+
 // MaxServerUnprocessedPackets is the max number of packets stored in the server that are not yet processed.
 const MaxServerUnprocessedPackets = 1024
 
@@ -157,12 +169,16 @@ const AckDelayExponent = 3
 // The loss detection timer will not be set to a value smaller than granularity.
 const TimerGranularity = time.Millisecond
 
-// MaxAckDelay is the maximum time by which we delay sending ACKs.
+// MaxAckDelay is the default maximum time by which we delay sending ACKs.
+// It can be configured using Config.MaxAckDelay.
 const MaxAckDelay = 25 * time.Millisecond
 
-// MaxAckDelayInclGranularity is the max_ack_delay including the timer granularity.
-// This is the value that should be advertised to the peer.
-const MaxAckDelayInclGranularity = MaxAckDelay + TimerGranularity
+// MaxInitialRTT is the largest initial RTT estimate that can be configured.
+const MaxInitialRTT = 10 * time.Second
+
+// MinAckDelay is the min_ack_delay advertised to the peer when the ACK Frequency extension is enabled.
+// The peer can't request a max ack delay smaller than this value.
+const MinAckDelay = TimerGranularity
 
 // KeyUpdateInterval is the maximum number of packets we send or receive before initiating a key update.
 const KeyUpdateInterval = 100 * 1000
@@ -10,6 +10,7 @@ import (
 var (
 	ErrHandshakeTimeout = &HandshakeTimeoutError{}
 	ErrIdleTimeout      = &IdleTimeoutError{}
+	ErrPTOLimitReached  = &PTOLimitReachedError{}
 )
 
 type TransportError struct {
@@ -90,6 +91,16 @@ func (e *IdleTimeoutError) Temporary() bool      { return false }
 func (e *IdleTimeoutError) Error() string        { return "timeout: no recent network activity" }
 func (e *IdleTimeoutError) Is(target error) bool { return target == net.ErrClosed }
 
+// A PTOLimitReachedError occurs when the maximum number of consecutive PTOs was exceeded.
+type PTOLimitReachedError struct{}
+
+var _ error = &PTOLimitReachedError{}
+
+func (e *PTOLimitReachedError) Timeout() bool        { return true }
+func (e *PTOLimitReachedError) Temporary() bool      { return false }
+func (e *PTOLimitReachedError) Error() string        { return "timeout: PTO limit reached" }
+func (e *PTOLimitReachedError) Is(target error) bool { return target == net.ErrClosed }
+
 type HandshakeTimeoutError struct{}
 
 var _ error = &HandshakeTimeoutError{}
@@ -138,6 +138,11 @@ func addExtraPrefix(b []byte) []byte {
 	return append([]byte(extraPrefix), b...)
 }
 
+// FindQUICData returns the data that quic-go saved in the session state, or nil if there is none.
+func FindQUICData(state *tls.SessionState) []byte {
+	return findExtraData(state.Extra)
+}
+
 func findExtraData(extras [][]byte) []byte {
 	prefix := []byte(extraPrefix)
 	for _, extra := range extras {
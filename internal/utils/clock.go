@@ -0,0 +1,32 @@
+package utils
+
+import "time"
+
+// A Clock is a source of time.
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+	// NewTimer creates a new timer that fires after duration d.
+	NewTimer(d time.Duration) ClockTimer
+}
+
+// A ClockTimer is a timer created by a Clock.
+// It has the same semantics as a time.Timer.
+type ClockTimer interface {
+	// Chan returns the channel on which the time is sent when the timer fires.
+	Chan() <-chan time.Time
+	Stop() bool
+	Reset(d time.Duration) bool
+}
+
+// DefaultClock implements the Clock interface using the Go stdlib clock.
+type DefaultClock struct{}
+
+var _ Clock = DefaultClock{}
+
+func (DefaultClock) Now() time.Time                      { return time.Now() }
+func (DefaultClock) NewTimer(d time.Duration) ClockTimer { return &stdlibTimer{time.NewTimer(d)} }
+
+type stdlibTimer struct{ *time.Timer }
+
+func (t *stdlibTimer) Chan() <-chan time.Time { return t.C }
@@ -25,6 +25,9 @@ type RTTStats struct {
 	meanDeviation time.Duration
 
 	maxAckDelay time.Duration
+	// The RTT estimate used before an RTT sample is taken.
+	// If zero, defaultInitialRTT is used.
+	initialRTT time.Duration
 }
 
 // NewRTTStats makes a properly initialized RTTStats object
@@ -53,6 +56,9 @@ func (r *RTTStats) MaxAckDelay() time.Duration { return r.maxAckDelay }
 // PTO gets the probe timeout duration.
 func (r *RTTStats) PTO(includeMaxAckDelay bool) time.Duration {
 	if r.SmoothedRTT() == 0 {
+		if r.initialRTT > 0 {
+			return 2 * r.initialRTT
+		}
 		return 2 * defaultInitialRTT
 	}
 	pto := r.SmoothedRTT() + max(4*r.MeanDeviation(), protocol.TimerGranularity)
@@ -114,6 +120,12 @@ func (r *RTTStats) SetInitialRTT(t time.Duration) {
 	r.latestRTT = t
 }
 
+// SetInitialRTTEstimate sets the RTT estimate that is used before an RTT sample is taken,
+// replacing the default of 100ms. Unlike SetInitialRTT, it doesn't set the smoothed RTT.
+func (r *RTTStats) SetInitialRTTEstimate(t time.Duration) {
+	r.initialRTT = t
+}
+
 // OnConnectionMigration is called when connection migrates and rtt measurement needs to be reset.
 func (r *RTTStats) OnConnectionMigration() {
 	r.latestRTT = 0
@@ -68,6 +68,15 @@ var _ = Describe("RTT stats", func() {
 		Expect(rttStats.PTO(true)).To(Equal(rtt + 4*(rtt/2) + maxAckDelay))
 	})
 
+	It("uses the initial RTT estimate for the PTO before an RTT sample is taken", func() {
+		Expect(rttStats.PTO(true)).To(Equal(2 * defaultInitialRTT))
+		rttStats.SetInitialRTTEstimate(time.Second)
+		Expect(rttStats.PTO(true)).To(Equal(2 * time.Second))
+		Expect(rttStats.SmoothedRTT()).To(BeZero())
+		rttStats.UpdateRTT(50*time.Millisecond, 0, time.Time{})
+		Expect(rttStats.PTO(false)).To(Equal(50*time.Millisecond + 4*25*time.Millisecond))
+	})
+
 	It("uses the granularity for computing the PTO for short RTTs", func() {
 		rtt := time.Microsecond
 		rttStats.UpdateRTT(rtt, 0, time.Time{})
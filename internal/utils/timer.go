@@ -7,19 +7,26 @@ import (
 
 // A Timer wrapper that behaves correctly when resetting
 type Timer struct {
-	t        *time.Timer
+	t        ClockTimer
+	clock    Clock
 	read     bool
 	deadline time.Time
 }
 
 // NewTimer creates a new timer that is not set
 func NewTimer() *Timer {
-	return &Timer{t: time.NewTimer(time.Duration(math.MaxInt64))}
+	return NewTimerWithClock(DefaultClock{})
+}
+
+// NewTimerWithClock creates a new timer that is not set.
+// Deadlines are interpreted relative to the given clock.
+func NewTimerWithClock(clock Clock) *Timer {
+	return &Timer{t: clock.NewTimer(time.Duration(math.MaxInt64)), clock: clock}
 }
 
 // Chan returns the channel of the wrapped timer
 func (t *Timer) Chan() <-chan time.Time {
-	return t.t.C
+	return t.t.Chan()
 }
 
 // Reset the timer, no matter whether the value was read or not
@@ -32,14 +39,17 @@ func (t *Timer) Reset(deadline time.Time) {
 	// We need to drain the timer if the value from its channel was not read yet.
 	// See https://groups.google.com/forum/#!topic/golang-dev/c9UUfASVPoU
 	if !t.t.Stop() && !t.read {
-		<-t.t.C
+		<-t.t.Chan()
 	}
-	if !deadline.IsZero() {
-		t.t.Reset(time.Until(deadline))
+	t.deadline = deadline
+	if deadline.IsZero() {
+		// The timer is stopped and its channel is empty.
+		// Mark it as read, so that the next call to Reset doesn't block trying to drain it.
+		t.read = true
+		return
 	}
-
+	t.t.Reset(deadline.Sub(t.clock.Now()))
 	t.read = false
-	t.deadline = deadline
 }
 
 // SetRead should be called after the value from the chan was read
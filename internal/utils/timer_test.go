@@ -68,6 +68,24 @@ var _ = Describe("Timer", func() {
 		Consistently(t.Chan()).ShouldNot(Receive())
 	})
 
+	It("can be reset after the deadline was cleared", func() {
+		t := NewTimer()
+		t.Reset(time.Now().Add(time.Hour))
+		t.Reset(time.Time{})
+		t.Reset(time.Time{})
+		Consistently(t.Chan()).ShouldNot(Receive())
+		t.Reset(time.Now().Add(d))
+		Eventually(t.Chan()).Should(Receive())
+	})
+
+	It("doesn't fire an expired timer after the deadline was cleared", func() {
+		t := NewTimer()
+		t.Reset(time.Now().Add(-time.Second))
+		time.Sleep(d)
+		t.Reset(time.Time{})
+		Consistently(t.Chan()).ShouldNot(Receive())
+	})
+
 	It("fires the timer twice, if reset to the same deadline", func() {
 		deadline := time.Now().Add(-time.Millisecond)
 		t := NewTimer()
@@ -0,0 +1,69 @@
+package wire
+
+import (
+	"time"
+
+	"github.com/quic-go/quic-go/internal/protocol"
+	"github.com/quic-go/quic-go/quicvarint"
+)
+
+// An AckFrequencyFrame is an ACK_FREQUENCY frame, as defined in the ACK Frequency extension.
+// It is used to request that the peer changes the rate at which it sends acknowledgments.
+type AckFrequencyFrame struct {
+	SequenceNumber        uint64
+	AckElicitingThreshold uint64
+	RequestMaxAckDelay    time.Duration
+	ReorderingThreshold   uint64
+}
+
+func parseAckFrequencyFrame(b []byte, _ protocol.Version) (*AckFrequencyFrame, int, error) {
+	startLen := len(b)
+	seq, l, err := quicvarint.Parse(b)
+	if err != nil {
+		return nil, 0, replaceUnexpectedEOF(err)
+	}
+	b = b[l:]
+	threshold, l, err := quicvarint.Parse(b)
+	if err != nil {
+		return nil, 0, replaceUnexpectedEOF(err)
+	}
+	b = b[l:]
+	mad, l, err := quicvarint.Parse(b)
+	if err != nil {
+		return nil, 0, replaceUnexpectedEOF(err)
+	}
+	b = b[l:]
+	reorderingThreshold, l, err := quicvarint.Parse(b)
+	if err != nil {
+		return nil, 0, replaceUnexpectedEOF(err)
+	}
+	// prevent overflows when converting to a time.Duration
+	if mad > uint64(protocol.MaxMaxAckDelay/time.Microsecond) {
+		mad = uint64(protocol.MaxMaxAckDelay / time.Microsecond)
+	}
+
+	return &AckFrequencyFrame{
+		SequenceNumber:        seq,
+		AckElicitingThreshold: threshold,
+		RequestMaxAckDelay:    time.Duration(mad) * time.Microsecond,
+		ReorderingThreshold:   reorderingThreshold,
+	}, startLen - len(b) + l, nil
+}
+
+func (f *AckFrequencyFrame) Append(b []byte, _ protocol.Version) ([]byte, error) {
+	b = quicvarint.Append(b, ackFrequencyFrameType)
+	b = quicvarint.Append(b, f.SequenceNumber)
+	b = quicvarint.Append(b, f.AckElicitingThreshold)
+	b = quicvarint.Append(b, uint64(f.RequestMaxAckDelay/time.Microsecond))
+	b = quicvarint.Append(b, f.ReorderingThreshold)
+	return b, nil
+}
+
+// Length of a written frame
+func (f *AckFrequencyFrame) Length(protocol.Version) protocol.ByteCount {
+	return protocol.ByteCount(quicvarint.Len(ackFrequencyFrameType) +
+		quicvarint.Len(f.SequenceNumber) +
+		quicvarint.Len(f.AckElicitingThreshold) +
+		quicvarint.Len(uint64(f.RequestMaxAckDelay/time.Microsecond)) +
+		quicvarint.Len(f.ReorderingThreshold))
+}
@@ -0,0 +1,87 @@
+package wire
+
+import (
+	"time"
+
+	"github.com/quic-go/quic-go/internal/protocol"
+	"github.com/quic-go/quic-go/quicvarint"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ACK_FREQUENCY frame", func() {
+	Context("when parsing", func() {
+		It("accepts sample frame", func() {
+			data := encodeVarInt(0xdeadbeef)             // sequence number
+			data = append(data, encodeVarInt(0xcafe)...) // ack-eliciting threshold
+			data = append(data, encodeVarInt(1337)...)   // request max ack delay
+			data = append(data, encodeVarInt(42)...)     // reordering threshold
+			frame, l, err := parseAckFrequencyFrame(data, protocol.Version1)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(frame.SequenceNumber).To(Equal(uint64(0xdeadbeef)))
+			Expect(frame.AckElicitingThreshold).To(Equal(uint64(0xcafe)))
+			Expect(frame.RequestMaxAckDelay).To(Equal(1337 * time.Microsecond))
+			Expect(frame.ReorderingThreshold).To(Equal(uint64(42)))
+			Expect(l).To(Equal(len(data)))
+		})
+
+		It("limits the request max ack delay", func() {
+			data := encodeVarInt(1)                       // sequence number
+			data = append(data, encodeVarInt(2)...)       // ack-eliciting threshold
+			data = append(data, encodeVarInt(1<<62-1)...) // request max ack delay
+			data = append(data, encodeVarInt(3)...)       // reordering threshold
+			frame, l, err := parseAckFrequencyFrame(data, protocol.Version1)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(frame.RequestMaxAckDelay).To(Equal(protocol.MaxMaxAckDelay))
+			Expect(l).To(Equal(len(data)))
+		})
+
+		It("errors on EOFs", func() {
+			data := encodeVarInt(0xdeadbeef)             // sequence number
+			data = append(data, encodeVarInt(0xcafe)...) // ack-eliciting threshold
+			data = append(data, encodeVarInt(1337)...)   // request max ack delay
+			data = append(data, encodeVarInt(42)...)     // reordering threshold
+			_, l, err := parseAckFrequencyFrame(data, protocol.Version1)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(l).To(Equal(len(data)))
+			for i := range data {
+				_, _, err := parseAckFrequencyFrame(data[:i], protocol.Version1)
+				Expect(err).To(HaveOccurred())
+			}
+		})
+	})
+
+	Context("when writing", func() {
+		It("writes a sample frame", func() {
+			frame := AckFrequencyFrame{
+				SequenceNumber:        0xdecafbad,
+				AckElicitingThreshold: 0xcafe,
+				RequestMaxAckDelay:    1337 * time.Microsecond,
+				ReorderingThreshold:   42,
+			}
+			b, err := frame.Append(nil, protocol.Version1)
+			Expect(err).ToNot(HaveOccurred())
+			expected := encodeVarInt(ackFrequencyFrameType)
+			expected = append(expected, encodeVarInt(0xdecafbad)...)
+			expected = append(expected, encodeVarInt(0xcafe)...)
+			expected = append(expected, encodeVarInt(1337)...)
+			expected = append(expected, encodeVarInt(42)...)
+			Expect(b).To(Equal(expected))
+		})
+
+		It("has the correct length", func() {
+			frame := AckFrequencyFrame{
+				SequenceNumber:        0xdecafbad,
+				AckElicitingThreshold: 0xcafe,
+				RequestMaxAckDelay:    1337 * time.Microsecond,
+				ReorderingThreshold:   42,
+			}
+			expectedLen := quicvarint.Len(ackFrequencyFrameType) + quicvarint.Len(0xdecafbad) + quicvarint.Len(0xcafe) + quicvarint.Len(1337) + quicvarint.Len(42)
+			Expect(frame.Length(protocol.Version1)).To(BeEquivalentTo(expectedLen))
+			b, err := frame.Append(nil, protocol.Version1)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(b).To(HaveLen(expectedLen))
+		})
+	})
+})
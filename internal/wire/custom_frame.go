@@ -0,0 +1,52 @@
+package wire
+
+import (
+	"io"
+
+	"github.com/quic-go/quic-go/internal/protocol"
+	"github.com/quic-go/quic-go/quicvarint"
+)
+
+// A CustomFrame is a frame of an application-defined frame type.
+// On the wire, it consists of the frame type, followed by the length of the payload and the payload.
+type CustomFrame struct {
+	Type uint64
+	Data []byte
+}
+
+// IsStandardFrameType says if the frame type is used by a frame defined in RFC 9000,
+// or by one of the QUIC extensions implemented by quic-go.
+func IsStandardFrameType(typ uint64) bool {
+	switch typ {
+	case resetStreamAtFrameType, ackFrequencyFrameType, maxDatagramsFrameType, 0x30, 0x31:
+		return true
+	}
+	return typ <= immediateAckFrameType
+}
+
+func parseCustomFrame(b []byte, typ uint64, _ protocol.Version) (*CustomFrame, int, error) {
+	startLen := len(b)
+	length, l, err := quicvarint.Parse(b)
+	if err != nil {
+		return nil, 0, replaceUnexpectedEOF(err)
+	}
+	b = b[l:]
+	if length > uint64(len(b)) {
+		return nil, 0, io.EOF
+	}
+	// The payload is passed to the application, so it must not reference the packet buffer.
+	f := &CustomFrame{Type: typ, Data: make([]byte, length)}
+	copy(f.Data, b)
+	return f, startLen - len(b) + int(length), nil
+}
+
+func (f *CustomFrame) Append(b []byte, _ protocol.Version) ([]byte, error) {
+	b = quicvarint.Append(b, f.Type)
+	b = quicvarint.Append(b, uint64(len(f.Data)))
+	return append(b, f.Data...), nil
+}
+
+// Length of a written frame
+func (f *CustomFrame) Length(_ protocol.Version) protocol.ByteCount {
+	return protocol.ByteCount(quicvarint.Len(f.Type)+quicvarint.Len(uint64(len(f.Data)))) + protocol.ByteCount(len(f.Data))
+}
@@ -0,0 +1,76 @@
+package wire
+
+import (
+	"io"
+
+	"github.com/quic-go/quic-go/internal/protocol"
+	"github.com/quic-go/quic-go/quicvarint"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("custom frame", func() {
+	Context("when parsing", func() {
+		It("parses a frame", func() {
+			data := encodeVarInt(6) // length
+			data = append(data, []byte("foobar")...)
+			frame, l, err := parseCustomFrame(data, 0x1337, protocol.Version1)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(frame.Type).To(BeEquivalentTo(0x1337))
+			Expect(frame.Data).To(Equal([]byte("foobar")))
+			Expect(l).To(Equal(len(data)))
+		})
+
+		It("copies the payload", func() {
+			data := encodeVarInt(3) // length
+			data = append(data, []byte("foo")...)
+			frame, _, err := parseCustomFrame(data, 0x1337, protocol.Version1)
+			Expect(err).ToNot(HaveOccurred())
+			data[len(data)-1] = 'x'
+			Expect(frame.Data).To(Equal([]byte("foo")))
+		})
+
+		It("errors on EOFs", func() {
+			data := encodeVarInt(6) // length
+			data = append(data, []byte("foobar")...)
+			_, l, err := parseCustomFrame(data, 0x1337, protocol.Version1)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(l).To(Equal(len(data)))
+			for i := range data {
+				_, _, err = parseCustomFrame(data[0:i], 0x1337, protocol.Version1)
+				Expect(err).To(MatchError(io.EOF))
+			}
+		})
+	})
+
+	Context("when writing", func() {
+		It("writes a frame", func() {
+			f := &CustomFrame{Type: 0x1337, Data: []byte("foobar")}
+			b, err := f.Append(nil, protocol.Version1)
+			Expect(err).ToNot(HaveOccurred())
+			expected := encodeVarInt(0x1337)
+			expected = append(expected, encodeVarInt(6)...)
+			expected = append(expected, []byte("foobar")...)
+			Expect(b).To(Equal(expected))
+			Expect(f.Length(protocol.Version1)).To(BeEquivalentTo(len(b)))
+		})
+
+		It("has the correct length for large frame types and payloads", func() {
+			f := &CustomFrame{Type: quicvarint.Max, Data: make([]byte, 1000)}
+			b, err := f.Append(nil, protocol.Version1)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(f.Length(protocol.Version1)).To(BeEquivalentTo(len(b)))
+			Expect(b).To(HaveLen(8 + 2 + 1000))
+		})
+	})
+
+	It("identifies standard frame types", func() {
+		for _, typ := range []uint64{0x0, 0x1, 0x8, 0xf, 0x1e, 0x1f, 0x24, 0x30, 0x31, 0xaf, 0x3f3d9c7e} {
+			Expect(IsStandardFrameType(typ)).To(BeTrue())
+		}
+		for _, typ := range []uint64{0x20, 0x40, 0x1337, quicvarint.Max} {
+			Expect(IsStandardFrameType(typ)).To(BeFalse())
+		}
+	})
+})
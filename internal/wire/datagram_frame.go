@@ -39,8 +39,8 @@ func parseDatagramFrame(b []byte, typ uint64, _ protocol.Version) (*DatagramFram
 	} else {
 		length = uint64(len(b))
 	}
-	f.Data = make([]byte, length)
-	copy(f.Data, b)
+	// The payload is not copied. It references the packet that is being parsed.
+	f.Data = b[:length]
 	return f, startLen - len(b) + int(length), nil
 }
 
@@ -34,12 +34,24 @@ const (
 	connectionCloseFrameType    = 0x1c
 	applicationCloseFrameType   = 0x1d
 	handshakeDoneFrameType      = 0x1e
+	immediateAckFrameType       = 0x1f
+	resetStreamAtFrameType      = 0x24
+	ackFrequencyFrameType       = 0xaf
 )
 
+// The MAX_DATAGRAMS frame is used by an experimental extension for datagram flow control.
+// Its frame type is not registered with IANA. It was selected at random, as recommended
+// for experiments by RFC 9000, Section 22.1.3.
+const maxDatagramsFrameType = 0x3f3d9c7e
+
 // The FrameParser parses QUIC frames, one by one.
 type FrameParser struct {
-	ackDelayExponent  uint8
-	supportsDatagrams bool
+	ackDelayExponent            uint8
+	supportsDatagrams           bool
+	supportsResetStreamAt       bool
+	supportsAckFrequency        bool
+	supportsDatagramFlowControl bool
+	customFrameTypes            map[uint64]struct{}
 
 	// To avoid allocating when parsing, keep a single ACK frame struct.
 	// It is used over and over again.
@@ -47,10 +59,12 @@ type FrameParser struct {
 }
 
 // NewFrameParser creates a new frame parser.
-func NewFrameParser(supportsDatagrams bool) *FrameParser {
+func NewFrameParser(supportsDatagrams, supportsResetStreamAt, supportsAckFrequency bool) *FrameParser {
 	return &FrameParser{
-		supportsDatagrams: supportsDatagrams,
-		ackFrame:          &AckFrame{},
+		supportsDatagrams:     supportsDatagrams,
+		supportsResetStreamAt: supportsResetStreamAt,
+		supportsAckFrequency:  supportsAckFrequency,
+		ackFrame:              &AckFrame{},
 	}
 }
 
@@ -80,6 +94,10 @@ func (p *FrameParser) parseNext(b []byte, encLevel protocol.EncryptionLevel, v p
 		f, l, err := p.parseFrame(b, typ, encLevel, v)
 		parsed += l
 		if err != nil {
+			if transportErr, ok := err.(*qerr.TransportError); ok {
+				transportErr.FrameType = typ
+				return nil, parsed, transportErr
+			}
 			return nil, parsed, &qerr.TransportError{
 				FrameType:    typ,
 				ErrorCode:    qerr.FrameEncodingError,
@@ -141,13 +159,45 @@ func (p *FrameParser) parseFrame(b []byte, typ uint64, encLevel protocol.Encrypt
 			frame, l, err = parseConnectionCloseFrame(b, typ, v)
 		case handshakeDoneFrameType:
 			frame = &HandshakeDoneFrame{}
+		case resetStreamAtFrameType:
+			if p.supportsResetStreamAt {
+				frame, l, err = parseResetStreamAtFrame(b, v)
+				break
+			}
+			err = errors.New("unknown frame type")
+		case ackFrequencyFrameType:
+			if p.supportsAckFrequency {
+				frame, l, err = parseAckFrequencyFrame(b, v)
+				break
+			}
+			err = errors.New("unknown frame type")
+		case immediateAckFrameType:
+			if p.supportsAckFrequency {
+				frame = &ImmediateAckFrame{}
+				break
+			}
+			err = errors.New("unknown frame type")
+		case maxDatagramsFrameType:
+			if p.supportsDatagramFlowControl {
+				frame, l, err = parseMaxDatagramsFrame(b, v)
+				break
+			}
+			err = errors.New("unknown frame type")
 		case 0x30, 0x31:
 			if p.supportsDatagrams {
 				frame, l, err = parseDatagramFrame(b, typ, v)
 				break
 			}
-			fallthrough
+			// RFC 9221, section 3: receiving a DATAGRAM frame without having advertised support is a protocol violation
+			err = &qerr.TransportError{
+				ErrorCode:    qerr.ProtocolViolation,
+				ErrorMessage: "received DATAGRAM frame, but datagram support was not negotiated",
+			}
 		default:
+			if _, ok := p.customFrameTypes[typ]; ok {
+				frame, l, err = parseCustomFrame(b, typ, v)
+				break
+			}
 			err = errors.New("unknown frame type")
 		}
 	}
@@ -189,6 +239,20 @@ func (p *FrameParser) SetAckDelayExponent(exp uint8) {
 	p.ackDelayExponent = exp
 }
 
+// EnableDatagramFlowControl enables parsing of MAX_DATAGRAMS frames.
+func (p *FrameParser) EnableDatagramFlowControl() {
+	p.supportsDatagramFlowControl = true
+}
+
+// RegisterCustomFrameType enables parsing of frames of the given type as a CustomFrame.
+// It must not be called with a standard frame type.
+func (p *FrameParser) RegisterCustomFrameType(typ uint64) {
+	if p.customFrameTypes == nil {
+		p.customFrameTypes = make(map[uint64]struct{})
+	}
+	p.customFrameTypes[typ] = struct{}{}
+}
+
 func replaceUnexpectedEOF(e error) error {
 	if e == io.ErrUnexpectedEOF {
 		return io.EOF
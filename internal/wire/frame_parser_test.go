@@ -18,7 +18,7 @@ var _ = Describe("Frame parsing", func() {
 	var parser FrameParser
 
 	BeforeEach(func() {
-		parser = *NewFrameParser(true)
+		parser = *NewFrameParser(true, true, true)
 	})
 
 	It("returns nil if there's nothing more to read", func() {
@@ -319,14 +319,132 @@ var _ = Describe("Frame parsing", func() {
 	})
 
 	It("errors when DATAGRAM frames are not supported", func() {
-		parser = *NewFrameParser(false)
+		parser = *NewFrameParser(false, false, false)
 		f := &DatagramFrame{Data: []byte("foobar")}
 		b, err := f.Append(nil, protocol.Version1)
 		Expect(err).ToNot(HaveOccurred())
 		_, _, err = parser.ParseNext(b, protocol.Encryption1RTT, protocol.Version1)
 		Expect(err).To(MatchError(&qerr.TransportError{
-			ErrorCode:    qerr.FrameEncodingError,
+			ErrorCode:    qerr.ProtocolViolation,
 			FrameType:    0x30,
+			ErrorMessage: "received DATAGRAM frame, but datagram support was not negotiated",
+		}))
+	})
+
+	It("unpacks RESET_STREAM_AT frames", func() {
+		f := &ResetStreamAtFrame{
+			StreamID:     0x1337,
+			ErrorCode:    0x42,
+			FinalSize:    0xdeadbeef,
+			ReliableSize: 0xcafe,
+		}
+		b, err := f.Append(nil, protocol.Version1)
+		Expect(err).ToNot(HaveOccurred())
+		l, frame, err := parser.ParseNext(b, protocol.Encryption1RTT, protocol.Version1)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(frame).To(Equal(f))
+		Expect(l).To(Equal(len(b)))
+	})
+
+	It("errors when RESET_STREAM_AT frames are not supported", func() {
+		parser = *NewFrameParser(true, false, false)
+		f := &ResetStreamAtFrame{StreamID: 0x1337, FinalSize: 0x42}
+		b, err := f.Append(nil, protocol.Version1)
+		Expect(err).ToNot(HaveOccurred())
+		_, _, err = parser.ParseNext(b, protocol.Encryption1RTT, protocol.Version1)
+		Expect(err).To(MatchError(&qerr.TransportError{
+			ErrorCode:    qerr.FrameEncodingError,
+			FrameType:    0x24,
+			ErrorMessage: "unknown frame type",
+		}))
+	})
+
+	It("unpacks ACK_FREQUENCY frames", func() {
+		f := &AckFrequencyFrame{
+			SequenceNumber:        1337,
+			AckElicitingThreshold: 10,
+			RequestMaxAckDelay:    42 * time.Millisecond,
+			ReorderingThreshold:   3,
+		}
+		b, err := f.Append(nil, protocol.Version1)
+		Expect(err).ToNot(HaveOccurred())
+		l, frame, err := parser.ParseNext(b, protocol.Encryption1RTT, protocol.Version1)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(frame).To(Equal(f))
+		Expect(l).To(Equal(len(b)))
+	})
+
+	It("unpacks IMMEDIATE_ACK frames", func() {
+		f := &ImmediateAckFrame{}
+		b, err := f.Append(nil, protocol.Version1)
+		Expect(err).ToNot(HaveOccurred())
+		l, frame, err := parser.ParseNext(b, protocol.Encryption1RTT, protocol.Version1)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(frame).To(Equal(f))
+		Expect(l).To(Equal(len(b)))
+	})
+
+	It("unpacks MAX_DATAGRAMS frames", func() {
+		parser.EnableDatagramFlowControl()
+		f := &MaxDatagramsFrame{MaximumDatagrams: 1337}
+		b, err := f.Append(nil, protocol.Version1)
+		Expect(err).ToNot(HaveOccurred())
+		l, frame, err := parser.ParseNext(b, protocol.Encryption1RTT, protocol.Version1)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(frame).To(Equal(f))
+		Expect(l).To(Equal(len(b)))
+	})
+
+	It("errors when MAX_DATAGRAMS frames are not supported", func() {
+		b, err := (&MaxDatagramsFrame{}).Append(nil, protocol.Version1)
+		Expect(err).ToNot(HaveOccurred())
+		_, _, err = parser.ParseNext(b, protocol.Encryption1RTT, protocol.Version1)
+		Expect(err).To(MatchError(&qerr.TransportError{
+			ErrorCode:    qerr.FrameEncodingError,
+			FrameType:    0x3f3d9c7e,
+			ErrorMessage: "unknown frame type",
+		}))
+	})
+
+	It("errors when ACK_FREQUENCY and IMMEDIATE_ACK frames are not supported", func() {
+		parser = *NewFrameParser(true, true, false)
+		b, err := (&AckFrequencyFrame{}).Append(nil, protocol.Version1)
+		Expect(err).ToNot(HaveOccurred())
+		_, _, err = parser.ParseNext(b, protocol.Encryption1RTT, protocol.Version1)
+		Expect(err).To(MatchError(&qerr.TransportError{
+			ErrorCode:    qerr.FrameEncodingError,
+			FrameType:    0xaf,
+			ErrorMessage: "unknown frame type",
+		}))
+		b, err = (&ImmediateAckFrame{}).Append(nil, protocol.Version1)
+		Expect(err).ToNot(HaveOccurred())
+		_, _, err = parser.ParseNext(b, protocol.Encryption1RTT, protocol.Version1)
+		Expect(err).To(MatchError(&qerr.TransportError{
+			ErrorCode:    qerr.FrameEncodingError,
+			FrameType:    0x1f,
+			ErrorMessage: "unknown frame type",
+		}))
+	})
+
+	It("unpacks custom frames of registered types", func() {
+		parser.RegisterCustomFrameType(0x1337)
+		f := &CustomFrame{Type: 0x1337, Data: []byte("foobar")}
+		b, err := f.Append(nil, protocol.Version1)
+		Expect(err).ToNot(HaveOccurred())
+		l, frame, err := parser.ParseNext(b, protocol.Encryption1RTT, protocol.Version1)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(frame).To(Equal(f))
+		Expect(l).To(Equal(len(b)))
+	})
+
+	It("errors on custom frames of unregistered types", func() {
+		parser.RegisterCustomFrameType(0x1337)
+		b, err := (&CustomFrame{Type: 0x1338, Data: []byte("foobar")}).Append(nil, protocol.Version1)
+		Expect(err).ToNot(HaveOccurred())
+		_, _, err = parser.ParseNext(b, protocol.Encryption1RTT, protocol.Version1)
+		Expect(err).To(MatchError(&qerr.TransportError{
+			ErrorCode:    qerr.FrameEncodingError,
+			FrameType:    0x1338,
 			ErrorMessage: "unknown frame type",
 		}))
 	})
@@ -374,11 +492,18 @@ var _ = Describe("Frame parsing", func() {
 			&ConnectionCloseFrame{},
 			&HandshakeDoneFrame{},
 			&DatagramFrame{},
+			&ResetStreamAtFrame{},
+			&AckFrequencyFrame{},
+			&ImmediateAckFrame{},
+			&MaxDatagramsFrame{},
+			&CustomFrame{Type: 0x1337},
 		}
 
 		var framesSerialized [][]byte
 
 		BeforeEach(func() {
+			parser.RegisterCustomFrameType(0x1337)
+			parser.EnableDatagramFlowControl()
 			framesSerialized = nil
 			for _, frame := range frames {
 				b, err := frame.Append(nil, protocol.Version1)
@@ -467,7 +592,7 @@ func BenchmarkParseStreamAndACK(b *testing.B) {
 		b.Fatal(err)
 	}
 
-	parser := NewFrameParser(false)
+	parser := NewFrameParser(false, false, false)
 	parser.SetAckDelayExponent(3)
 
 	b.ResetTimer()
@@ -525,7 +650,7 @@ func BenchmarkParseOtherFrames(b *testing.B) {
 		}
 	}
 
-	parser := NewFrameParser(false)
+	parser := NewFrameParser(false, false, false)
 
 	b.ResetTimer()
 	b.ReportAllocs()
@@ -46,6 +46,16 @@ var _ = Describe("Frame logging", func() {
 		Expect(buf.String()).To(ContainSubstring("\t<- &wire.ResetStreamFrame{StreamID: 0, ErrorCode: 0x0, FinalSize: 0}\n"))
 	})
 
+	It("logs RESET_STREAM_AT frames", func() {
+		LogFrame(logger, &ResetStreamAtFrame{StreamID: 42, ErrorCode: 0x1337, FinalSize: 1000, ReliableSize: 100}, false)
+		Expect(buf.String()).To(ContainSubstring("\t<- &wire.ResetStreamAtFrame{StreamID: 42, ErrorCode: 0x1337, FinalSize: 1000, ReliableSize: 100}\n"))
+	})
+
+	It("logs ACK_FREQUENCY frames", func() {
+		LogFrame(logger, &AckFrequencyFrame{SequenceNumber: 1, AckElicitingThreshold: 10, RequestMaxAckDelay: 50 * time.Millisecond, ReorderingThreshold: 3}, true)
+		Expect(buf.String()).To(ContainSubstring("\t-> &wire.AckFrequencyFrame{SequenceNumber: 1, AckElicitingThreshold: 10, RequestMaxAckDelay: 50ms, ReorderingThreshold: 3}\n"))
+	})
+
 	It("logs CRYPTO frames", func() {
 		frame := &CryptoFrame{
 			Offset: 42,
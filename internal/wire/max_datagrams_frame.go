@@ -0,0 +1,32 @@
+package wire
+
+import (
+	"github.com/quic-go/quic-go/internal/protocol"
+	"github.com/quic-go/quic-go/quicvarint"
+)
+
+// A MaxDatagramsFrame carries flow control information for DATAGRAM frames.
+// It is not part of any standard, and only used if both endpoints enabled datagram flow control.
+type MaxDatagramsFrame struct {
+	MaximumDatagrams uint64
+}
+
+// parseMaxDatagramsFrame parses a MAX_DATAGRAMS frame
+func parseMaxDatagramsFrame(b []byte, _ protocol.Version) (*MaxDatagramsFrame, int, error) {
+	maxDatagrams, l, err := quicvarint.Parse(b)
+	if err != nil {
+		return nil, 0, replaceUnexpectedEOF(err)
+	}
+	return &MaxDatagramsFrame{MaximumDatagrams: maxDatagrams}, l, nil
+}
+
+func (f *MaxDatagramsFrame) Append(b []byte, _ protocol.Version) ([]byte, error) {
+	b = quicvarint.Append(b, maxDatagramsFrameType)
+	b = quicvarint.Append(b, f.MaximumDatagrams)
+	return b, nil
+}
+
+// Length of a written frame
+func (f *MaxDatagramsFrame) Length(_ protocol.Version) protocol.ByteCount {
+	return protocol.ByteCount(quicvarint.Len(maxDatagramsFrameType) + quicvarint.Len(f.MaximumDatagrams))
+}
@@ -0,0 +1,43 @@
+package wire
+
+import (
+	"io"
+
+	"github.com/quic-go/quic-go/internal/protocol"
+	"github.com/quic-go/quic-go/quicvarint"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("MAX_DATAGRAMS frame", func() {
+	Context("when parsing", func() {
+		It("accepts sample frame", func() {
+			data := encodeVarInt(0xdecafbad)
+			frame, l, err := parseMaxDatagramsFrame(data, protocol.Version1)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(frame.MaximumDatagrams).To(Equal(uint64(0xdecafbad)))
+			Expect(l).To(Equal(len(data)))
+		})
+
+		It("errors on EOFs", func() {
+			data := encodeVarInt(0xdecafbad1234567)
+			for i := range data {
+				_, _, err := parseMaxDatagramsFrame(data[:i], protocol.Version1)
+				Expect(err).To(MatchError(io.EOF))
+			}
+		})
+	})
+
+	Context("writing", func() {
+		It("writes a MAX_DATAGRAMS frame", func() {
+			f := &MaxDatagramsFrame{MaximumDatagrams: 0xdeadbeef}
+			b, err := f.Append(nil, protocol.Version1)
+			Expect(err).ToNot(HaveOccurred())
+			expected := quicvarint.Append(nil, maxDatagramsFrameType)
+			expected = append(expected, encodeVarInt(0xdeadbeef)...)
+			Expect(b).To(Equal(expected))
+			Expect(f.Length(protocol.Version1)).To(BeEquivalentTo(len(b)))
+		})
+	})
+})
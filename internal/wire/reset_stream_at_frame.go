@@ -0,0 +1,65 @@
+package wire
+
+import (
+	"errors"
+
+	"github.com/quic-go/quic-go/internal/protocol"
+	"github.com/quic-go/quic-go/internal/qerr"
+	"github.com/quic-go/quic-go/quicvarint"
+)
+
+// A ResetStreamAtFrame is a RESET_STREAM_AT frame, as defined in the reliable stream reset extension.
+// Data up to the ReliableSize is delivered reliably, even though the stream is reset.
+type ResetStreamAtFrame struct {
+	StreamID     protocol.StreamID
+	ErrorCode    qerr.StreamErrorCode
+	FinalSize    protocol.ByteCount
+	ReliableSize protocol.ByteCount
+}
+
+func parseResetStreamAtFrame(b []byte, _ protocol.Version) (*ResetStreamAtFrame, int, error) {
+	startLen := len(b)
+	sid, l, err := quicvarint.Parse(b)
+	if err != nil {
+		return nil, 0, replaceUnexpectedEOF(err)
+	}
+	b = b[l:]
+	errorCode, l, err := quicvarint.Parse(b)
+	if err != nil {
+		return nil, 0, replaceUnexpectedEOF(err)
+	}
+	b = b[l:]
+	finalSize, l, err := quicvarint.Parse(b)
+	if err != nil {
+		return nil, 0, replaceUnexpectedEOF(err)
+	}
+	b = b[l:]
+	reliableSize, l, err := quicvarint.Parse(b)
+	if err != nil {
+		return nil, 0, replaceUnexpectedEOF(err)
+	}
+	if reliableSize > finalSize {
+		return nil, 0, errors.New("RESET_STREAM_AT: reliable size can't be larger than the final size")
+	}
+
+	return &ResetStreamAtFrame{
+		StreamID:     protocol.StreamID(sid),
+		ErrorCode:    qerr.StreamErrorCode(errorCode),
+		FinalSize:    protocol.ByteCount(finalSize),
+		ReliableSize: protocol.ByteCount(reliableSize),
+	}, startLen - len(b) + l, nil
+}
+
+func (f *ResetStreamAtFrame) Append(b []byte, _ protocol.Version) ([]byte, error) {
+	b = append(b, resetStreamAtFrameType)
+	b = quicvarint.Append(b, uint64(f.StreamID))
+	b = quicvarint.Append(b, uint64(f.ErrorCode))
+	b = quicvarint.Append(b, uint64(f.FinalSize))
+	b = quicvarint.Append(b, uint64(f.ReliableSize))
+	return b, nil
+}
+
+// Length of a written frame
+func (f *ResetStreamAtFrame) Length(protocol.Version) protocol.ByteCount {
+	return 1 + protocol.ByteCount(quicvarint.Len(uint64(f.StreamID))+quicvarint.Len(uint64(f.ErrorCode))+quicvarint.Len(uint64(f.FinalSize))+quicvarint.Len(uint64(f.ReliableSize)))
+}
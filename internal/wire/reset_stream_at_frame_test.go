@@ -0,0 +1,81 @@
+package wire
+
+import (
+	"github.com/quic-go/quic-go/internal/protocol"
+	"github.com/quic-go/quic-go/internal/qerr"
+	"github.com/quic-go/quic-go/quicvarint"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("RESET_STREAM_AT frame", func() {
+	Context("when parsing", func() {
+		It("accepts sample frame", func() {
+			data := encodeVarInt(0xdeadbeef)                  // stream ID
+			data = append(data, encodeVarInt(0x1337)...)      // error code
+			data = append(data, encodeVarInt(0x987654321)...) // final size
+			data = append(data, encodeVarInt(0x123456)...)    // reliable size
+			frame, l, err := parseResetStreamAtFrame(data, protocol.Version1)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(frame.StreamID).To(Equal(protocol.StreamID(0xdeadbeef)))
+			Expect(frame.FinalSize).To(Equal(protocol.ByteCount(0x987654321)))
+			Expect(frame.ReliableSize).To(Equal(protocol.ByteCount(0x123456)))
+			Expect(frame.ErrorCode).To(Equal(qerr.StreamErrorCode(0x1337)))
+			Expect(l).To(Equal(len(data)))
+		})
+
+		It("errors when the reliable size is larger than the final size", func() {
+			data := encodeVarInt(0xdeadbeef)             // stream ID
+			data = append(data, encodeVarInt(0x1337)...) // error code
+			data = append(data, encodeVarInt(1000)...)   // final size
+			data = append(data, encodeVarInt(1001)...)   // reliable size
+			_, _, err := parseResetStreamAtFrame(data, protocol.Version1)
+			Expect(err).To(MatchError("RESET_STREAM_AT: reliable size can't be larger than the final size"))
+		})
+
+		It("errors on EOFs", func() {
+			data := encodeVarInt(0xdeadbeef)                  // stream ID
+			data = append(data, encodeVarInt(0x1337)...)      // error code
+			data = append(data, encodeVarInt(0x987654321)...) // final size
+			data = append(data, encodeVarInt(0x123456)...)    // reliable size
+			_, l, err := parseResetStreamAtFrame(data, protocol.Version1)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(l).To(Equal(len(data)))
+			for i := range data {
+				_, _, err := parseResetStreamAtFrame(data[:i], protocol.Version1)
+				Expect(err).To(HaveOccurred())
+			}
+		})
+	})
+
+	Context("when writing", func() {
+		It("writes a sample frame", func() {
+			frame := ResetStreamAtFrame{
+				StreamID:     0x1337,
+				FinalSize:    0x11223344decafbad,
+				ReliableSize: 0x1234,
+				ErrorCode:    0xcafe,
+			}
+			b, err := frame.Append(nil, protocol.Version1)
+			Expect(err).ToNot(HaveOccurred())
+			expected := []byte{resetStreamAtFrameType}
+			expected = append(expected, encodeVarInt(0x1337)...)
+			expected = append(expected, encodeVarInt(0xcafe)...)
+			expected = append(expected, encodeVarInt(0x11223344decafbad)...)
+			expected = append(expected, encodeVarInt(0x1234)...)
+			Expect(b).To(Equal(expected))
+		})
+
+		It("has the correct length", func() {
+			rst := ResetStreamAtFrame{
+				StreamID:     0x1337,
+				FinalSize:    0x1234567,
+				ReliableSize: 0x1234,
+				ErrorCode:    0xde,
+			}
+			expectedLen := 1 + quicvarint.Len(0x1337) + quicvarint.Len(0x1234567) + quicvarint.Len(0x1234) + 2
+			Expect(rst.Length(protocol.Version1)).To(BeEquivalentTo(expectedLen))
+		})
+	})
+})
@@ -12,6 +12,7 @@ import (
 // ParseShortHeader parses a short header packet.
 // It must be called after header protection was removed.
 // Otherwise, the check for the reserved bits will (most likely) fail.
+// The QUIC bit is not checked, since it might have been greased by the peer (RFC 9287).
 func ParseShortHeader(data []byte, connIDLen int) (length int, _ protocol.PacketNumber, _ protocol.PacketNumberLen, _ protocol.KeyPhaseBit, _ error) {
 	if len(data) == 0 {
 		return 0, 0, 0, 0, io.EOF
@@ -19,9 +20,6 @@ func ParseShortHeader(data []byte, connIDLen int) (length int, _ protocol.Packet
 	if data[0]&0x80 > 0 {
 		return 0, 0, 0, 0, errors.New("not a short header packet")
 	}
-	if data[0]&0x40 == 0 {
-		return 0, 0, 0, 0, errors.New("not a QUIC packet")
-	}
 	pnLen := protocol.PacketNumberLen(data[0]&0b11) + 1
 	if len(data) < 1+int(pnLen)+connIDLen {
 		return 0, 0, 0, 0, io.EOF
@@ -30,14 +30,18 @@ var _ = Describe("Short Header", func() {
 			Expect(pnLen).To(Equal(protocol.PacketNumberLen3))
 		})
 
-		It("errors when the QUIC bit is not set", func() {
+		It("parses packets with a greased QUIC bit", func() {
 			data := []byte{
 				0b00000101,
 				0xde, 0xad, 0xbe, 0xef,
 				0x13, 0x37,
 			}
-			_, _, _, _, err := ParseShortHeader(data, 4)
-			Expect(err).To(MatchError("not a QUIC packet"))
+			l, pn, pnLen, kp, err := ParseShortHeader(data, 4)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(l).To(Equal(len(data)))
+			Expect(kp).To(Equal(protocol.KeyPhaseOne))
+			Expect(pn).To(Equal(protocol.PacketNumber(0x1337)))
+			Expect(pnLen).To(Equal(protocol.PacketNumberLen2))
 		})
 
 		It("errors, but returns the header, when the reserved bits are set", func() {
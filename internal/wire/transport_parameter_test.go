@@ -44,6 +44,7 @@ var _ = Describe("Transport Parameters", func() {
 
 	It("has a string representation", func() {
 		rcid := protocol.ParseConnectionID([]byte{0xde, 0xad, 0xc0, 0xde})
+		minAckDelay := 1500 * time.Microsecond
 		p := &TransportParameters{
 			InitialMaxStreamDataBidiLocal:   1234,
 			InitialMaxStreamDataBidiRemote:  2345,
@@ -60,8 +61,12 @@ var _ = Describe("Transport Parameters", func() {
 			StatelessResetToken:             &protocol.StatelessResetToken{0x11, 0x22, 0x33, 0x44, 0x55, 0x66, 0x77, 0x88, 0x99, 0xaa, 0xbb, 0xcc, 0xdd, 0xee, 0xff, 0x00},
 			ActiveConnectionIDLimit:         123,
 			MaxDatagramFrameSize:            876,
+			InitialMaxDatagrams:             128,
+			EnableResetStreamAt:             true,
+			MinAckDelay:                     &minAckDelay,
+			GreaseQUICBit:                   true,
 		}
-		Expect(p.String()).To(Equal("&wire.TransportParameters{OriginalDestinationConnectionID: deadbeef, InitialSourceConnectionID: decafbad, RetrySourceConnectionID: deadc0de, InitialMaxStreamDataBidiLocal: 1234, InitialMaxStreamDataBidiRemote: 2345, InitialMaxStreamDataUni: 3456, InitialMaxData: 4567, MaxBidiStreamNum: 1337, MaxUniStreamNum: 7331, MaxIdleTimeout: 42s, AckDelayExponent: 14, MaxAckDelay: 37ms, ActiveConnectionIDLimit: 123, StatelessResetToken: 0x112233445566778899aabbccddeeff00, MaxDatagramFrameSize: 876}"))
+		Expect(p.String()).To(Equal("&wire.TransportParameters{OriginalDestinationConnectionID: deadbeef, InitialSourceConnectionID: decafbad, RetrySourceConnectionID: deadc0de, InitialMaxStreamDataBidiLocal: 1234, InitialMaxStreamDataBidiRemote: 2345, InitialMaxStreamDataUni: 3456, InitialMaxData: 4567, MaxBidiStreamNum: 1337, MaxUniStreamNum: 7331, MaxIdleTimeout: 42s, AckDelayExponent: 14, MaxAckDelay: 37ms, ActiveConnectionIDLimit: 123, StatelessResetToken: 0x112233445566778899aabbccddeeff00, MaxDatagramFrameSize: 876, InitialMaxDatagrams: 128, EnableResetStreamAt: true, MinAckDelay: 1.5ms, GreaseQUICBit: true}"))
 	})
 
 	It("has a string representation, if there's no stateless reset token, no Retry source connection id and no datagram support", func() {
@@ -87,6 +92,7 @@ var _ = Describe("Transport Parameters", func() {
 		var token protocol.StatelessResetToken
 		rand.Read(token[:])
 		rcid := protocol.ParseConnectionID([]byte{0xde, 0xad, 0xc0, 0xde})
+		minAckDelay := time.Duration(getRandomValueUpTo(int64(42*time.Millisecond/time.Microsecond))) * time.Microsecond
 		params := &TransportParameters{
 			InitialMaxStreamDataBidiLocal:   protocol.ByteCount(getRandomValue()),
 			InitialMaxStreamDataBidiRemote:  protocol.ByteCount(getRandomValue()),
@@ -105,6 +111,10 @@ var _ = Describe("Transport Parameters", func() {
 			ActiveConnectionIDLimit:         2 + getRandomValueUpTo(quicvarint.Max-2),
 			MaxUDPPayloadSize:               1200 + protocol.ByteCount(getRandomValueUpTo(quicvarint.Max-1200)),
 			MaxDatagramFrameSize:            protocol.ByteCount(getRandomValue()),
+			InitialMaxDatagrams:             getRandomValue(),
+			EnableResetStreamAt:             getRandomValue()%2 == 0,
+			MinAckDelay:                     &minAckDelay,
+			GreaseQUICBit:                   getRandomValue()%2 == 0,
 		}
 		data := params.Marshal(protocol.PerspectiveServer)
 
@@ -127,6 +137,10 @@ var _ = Describe("Transport Parameters", func() {
 		Expect(p.ActiveConnectionIDLimit).To(Equal(params.ActiveConnectionIDLimit))
 		Expect(p.MaxUDPPayloadSize).To(Equal(params.MaxUDPPayloadSize))
 		Expect(p.MaxDatagramFrameSize).To(Equal(params.MaxDatagramFrameSize))
+		Expect(p.InitialMaxDatagrams).To(Equal(params.InitialMaxDatagrams))
+		Expect(p.EnableResetStreamAt).To(Equal(params.EnableResetStreamAt))
+		Expect(p.MinAckDelay).To(Equal(params.MinAckDelay))
+		Expect(p.GreaseQUICBit).To(Equal(params.GreaseQUICBit))
 	})
 
 	It("marshals additional transport parameters (used for testing large ClientHellos)", func() {
@@ -198,6 +212,26 @@ var _ = Describe("Transport Parameters", func() {
 		}))
 	})
 
+	It("errors when reset_stream_at has content", func() {
+		b := quicvarint.Append(nil, uint64(resetStreamAtParameterID))
+		b = quicvarint.Append(b, 6)
+		b = append(b, []byte("foobar")...)
+		Expect((&TransportParameters{}).Unmarshal(b, protocol.PerspectiveServer)).To(MatchError(&qerr.TransportError{
+			ErrorCode:    qerr.TransportParameterError,
+			ErrorMessage: "wrong length for reset_stream_at: 6 (expected empty)",
+		}))
+	})
+
+	It("errors when grease_quic_bit has content", func() {
+		b := quicvarint.Append(nil, uint64(greaseQUICBitParameterID))
+		b = quicvarint.Append(b, 6)
+		b = append(b, []byte("foobar")...)
+		Expect((&TransportParameters{}).Unmarshal(b, protocol.PerspectiveServer)).To(MatchError(&qerr.TransportError{
+			ErrorCode:    qerr.TransportParameterError,
+			ErrorMessage: "wrong length for grease_quic_bit: 6 (expected empty)",
+		}))
+	})
+
 	It("errors when the server doesn't set the original_destination_connection_id", func() {
 		b := quicvarint.Append(nil, uint64(statelessResetTokenParameterID))
 		b = quicvarint.Append(b, 16)
@@ -228,6 +262,32 @@ var _ = Describe("Transport Parameters", func() {
 		}))
 	})
 
+	It("errors when the min_ack_delay is larger than the max_ack_delay", func() {
+		minAckDelay := 43 * time.Millisecond
+		data := (&TransportParameters{
+			MaxAckDelay:             42 * time.Millisecond,
+			MinAckDelay:             &minAckDelay,
+			ActiveConnectionIDLimit: protocol.DefaultActiveConnectionIDLimit,
+			StatelessResetToken:     &protocol.StatelessResetToken{},
+		}).Marshal(protocol.PerspectiveServer)
+		p := &TransportParameters{}
+		Expect(p.Unmarshal(data, protocol.PerspectiveServer)).To(MatchError(&qerr.TransportError{
+			ErrorCode:    qerr.TransportParameterError,
+			ErrorMessage: "min_ack_delay (43ms) is larger than max_ack_delay (42ms)",
+		}))
+	})
+
+	It("doesn't send the min_ack_delay, if the ACK Frequency extension is not supported", func() {
+		data := (&TransportParameters{
+			InitialSourceConnectionID: protocol.ParseConnectionID([]byte{0xde, 0xca, 0xfb, 0xad}),
+			ActiveConnectionIDLimit:   protocol.DefaultActiveConnectionIDLimit,
+			MaxAckDelay:               protocol.DefaultMaxAckDelay,
+		}).Marshal(protocol.PerspectiveClient)
+		p := &TransportParameters{}
+		Expect(p.Unmarshal(data, protocol.PerspectiveClient)).To(Succeed())
+		Expect(p.MinAckDelay).To(BeNil())
+	})
+
 	It("doesn't send the max_ack_delay, if it has the default value", func() {
 		const num = 1000
 		var defaultLen, dataLen int
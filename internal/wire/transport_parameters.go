@@ -45,6 +45,14 @@ const (
 	retrySourceConnectionIDParameterID         transportParameterID = 0x10
 	// RFC 9221
 	maxDatagramFrameSizeParameterID transportParameterID = 0x20
+	// https://datatracker.ietf.org/doc/draft-ietf-quic-reliable-stream-reset/
+	resetStreamAtParameterID transportParameterID = 0x17f7586d2cb571
+	// https://datatracker.ietf.org/doc/draft-ietf-quic-ack-frequency/
+	minAckDelayParameterID transportParameterID = 0xff04de1b
+	// RFC 9287
+	greaseQUICBitParameterID transportParameterID = 0x2ab2
+	// experimental datagram flow control, not registered with IANA, selected at random (RFC 9000, Section 22.1.3)
+	initialMaxDatagramsParameterID transportParameterID = 0x1d6e2a43
 )
 
 // PreferredAddress is the value encoding in the preferred_address transport parameter
@@ -63,6 +71,8 @@ type TransportParameters struct {
 
 	MaxAckDelay      time.Duration
 	AckDelayExponent uint8
+	// MinAckDelay is only set if the peer supports the ACK Frequency extension
+	MinAckDelay *time.Duration
 
 	DisableActiveMigration bool
 
@@ -83,6 +93,12 @@ type TransportParameters struct {
 	ActiveConnectionIDLimit uint64
 
 	MaxDatagramFrameSize protocol.ByteCount
+	// InitialMaxDatagrams is only set if the peer supports datagram flow control
+	InitialMaxDatagrams uint64
+
+	EnableResetStreamAt bool
+
+	GreaseQUICBit bool
 }
 
 // Unmarshal the transport parameters
@@ -139,7 +155,9 @@ func (p *TransportParameters) unmarshal(b []byte, sentBy protocol.Perspective, f
 			initialMaxStreamsBidiParameterID,
 			initialMaxStreamsUniParameterID,
 			maxAckDelayParameterID,
+			minAckDelayParameterID,
 			maxDatagramFrameSizeParameterID,
+			initialMaxDatagramsParameterID,
 			ackDelayExponentParameterID:
 			if err := p.readNumericTransportParameter(b, paramID, int(paramLen)); err != nil {
 				return err
@@ -158,6 +176,16 @@ func (p *TransportParameters) unmarshal(b []byte, sentBy protocol.Perspective, f
 				return fmt.Errorf("wrong length for disable_active_migration: %d (expected empty)", paramLen)
 			}
 			p.DisableActiveMigration = true
+		case resetStreamAtParameterID:
+			if paramLen != 0 {
+				return fmt.Errorf("wrong length for reset_stream_at: %d (expected empty)", paramLen)
+			}
+			p.EnableResetStreamAt = true
+		case greaseQUICBitParameterID:
+			if paramLen != 0 {
+				return fmt.Errorf("wrong length for grease_quic_bit: %d (expected empty)", paramLen)
+			}
+			p.GreaseQUICBit = true
 		case statelessResetTokenParameterID:
 			if sentBy == protocol.PerspectiveClient {
 				return errors.New("client sent a stateless_reset_token")
@@ -204,6 +232,10 @@ func (p *TransportParameters) unmarshal(b []byte, sentBy protocol.Perspective, f
 		}
 	}
 
+	if p.MinAckDelay != nil && *p.MinAckDelay > p.MaxAckDelay {
+		return fmt.Errorf("min_ack_delay (%s) is larger than max_ack_delay (%s)", *p.MinAckDelay, p.MaxAckDelay)
+	}
+
 	if !readActiveConnectionIDLimit {
 		p.ActiveConnectionIDLimit = protocol.DefaultActiveConnectionIDLimit
 	}
@@ -315,6 +347,12 @@ func (p *TransportParameters) readNumericTransportParameter(b []byte, paramID tr
 			return fmt.Errorf("invalid value for max_ack_delay: %dms (maximum %dms)", val, protocol.MaxMaxAckDelay/time.Millisecond)
 		}
 		p.MaxAckDelay = time.Duration(val) * time.Millisecond
+	case minAckDelayParameterID:
+		if val > uint64(protocol.MaxMaxAckDelay/time.Microsecond) {
+			return fmt.Errorf("invalid value for min_ack_delay: %dus (maximum %dus)", val, protocol.MaxMaxAckDelay/time.Microsecond)
+		}
+		minAckDelay := time.Duration(val) * time.Microsecond
+		p.MinAckDelay = &minAckDelay
 	case activeConnectionIDLimitParameterID:
 		if val < 2 {
 			return fmt.Errorf("invalid value for active_connection_id_limit: %d (minimum 2)", val)
@@ -322,6 +360,8 @@ func (p *TransportParameters) readNumericTransportParameter(b []byte, paramID tr
 		p.ActiveConnectionIDLimit = val
 	case maxDatagramFrameSizeParameterID:
 		p.MaxDatagramFrameSize = protocol.ByteCount(val)
+	case initialMaxDatagramsParameterID:
+		p.InitialMaxDatagrams = val
 	default:
 		return fmt.Errorf("TransportParameter BUG: transport parameter %d not found", paramID)
 	}
@@ -366,6 +406,10 @@ func (p *TransportParameters) Marshal(pers protocol.Perspective) []byte {
 	if p.MaxAckDelay != protocol.DefaultMaxAckDelay {
 		b = p.marshalVarintParam(b, maxAckDelayParameterID, uint64(p.MaxAckDelay/time.Millisecond))
 	}
+	// min_ack_delay
+	if p.MinAckDelay != nil {
+		b = p.marshalVarintParam(b, minAckDelayParameterID, uint64(*p.MinAckDelay/time.Microsecond))
+	}
 	// ack_delay_exponent
 	// Only send it if is different from the default value.
 	if p.AckDelayExponent != protocol.DefaultAckDelayExponent {
@@ -419,6 +463,20 @@ func (p *TransportParameters) Marshal(pers protocol.Perspective) []byte {
 	if p.MaxDatagramFrameSize != protocol.InvalidByteCount {
 		b = p.marshalVarintParam(b, maxDatagramFrameSizeParameterID, uint64(p.MaxDatagramFrameSize))
 	}
+	// initial_max_datagrams
+	if p.InitialMaxDatagrams > 0 {
+		b = p.marshalVarintParam(b, initialMaxDatagramsParameterID, p.InitialMaxDatagrams)
+	}
+	// reset_stream_at
+	if p.EnableResetStreamAt {
+		b = quicvarint.Append(b, uint64(resetStreamAtParameterID))
+		b = quicvarint.Append(b, 0)
+	}
+	// grease_quic_bit
+	if p.GreaseQUICBit {
+		b = quicvarint.Append(b, uint64(greaseQUICBitParameterID))
+		b = quicvarint.Append(b, 0)
+	}
 
 	if pers == protocol.PerspectiveClient && len(AdditionalTransportParametersClient) > 0 {
 		for k, v := range AdditionalTransportParametersClient {
@@ -527,6 +585,20 @@ func (p *TransportParameters) String() string {
 		logString += ", MaxDatagramFrameSize: %d"
 		logParams = append(logParams, p.MaxDatagramFrameSize)
 	}
+	if p.InitialMaxDatagrams > 0 {
+		logString += ", InitialMaxDatagrams: %d"
+		logParams = append(logParams, p.InitialMaxDatagrams)
+	}
+	if p.EnableResetStreamAt {
+		logString += ", EnableResetStreamAt: true"
+	}
+	if p.MinAckDelay != nil {
+		logString += ", MinAckDelay: %s"
+		logParams = append(logParams, *p.MinAckDelay)
+	}
+	if p.GreaseQUICBit {
+		logString += ", GreaseQUICBit: true"
+	}
 	logString += "}"
 	return fmt.Sprintf(logString, logParams...)
 }
@@ -15,6 +15,7 @@ type ConnectionTracer struct {
 	RestoredTransportParameters      func(parameters *TransportParameters) // for 0-RTT
 	SentLongHeaderPacket             func(*ExtendedHeader, ByteCount, ECN, *AckFrame, []Frame)
 	SentShortHeaderPacket            func(*ShortHeader, ByteCount, ECN, *AckFrame, []Frame)
+	SentUDPDatagram                  func(size ByteCount, numPackets int) // once per datagram (GSO segment), numPackets is the number of coalesced packets
 	ReceivedVersionNegotiationPacket func(dest, src ArbitraryLenConnectionID, _ []VersionNumber)
 	ReceivedRetry                    func(*Header)
 	ReceivedLongHeaderPacket         func(*ExtendedHeader, ByteCount, ECN, []Frame)
@@ -36,6 +37,12 @@ type ConnectionTracer struct {
 	LossTimerCanceled                func()
 	ECNStateUpdated                  func(state ECNState, trigger ECNStateTrigger)
 	ChoseALPN                        func(protocol string)
+	MigratedConnection               func(local, remote net.Addr)
+	// AmplificationLimited is called when the server withholds a packet due to the anti-amplification limit
+	// (RFC 9000, section 8.1): until the client's address is validated, the server sends at most 3x the number of
+	// bytes received. The remaining budget is 3*bytesReceived - bytesSent, and grows as more bytes are received.
+	// It is called once every time the server becomes blocked.
+	AmplificationLimited func(bytesReceived, bytesSent ByteCount)
 	// Close is called when the connection is closed.
 	Close func()
 	Debug func(name, msg string)
@@ -106,6 +113,13 @@ func NewMultiplexedConnectionTracer(tracers ...*ConnectionTracer) *ConnectionTra
 				}
 			}
 		},
+		SentUDPDatagram: func(size ByteCount, numPackets int) {
+			for _, t := range tracers {
+				if t.SentUDPDatagram != nil {
+					t.SentUDPDatagram(size, numPackets)
+				}
+			}
+		},
 		ReceivedVersionNegotiationPacket: func(dest, src ArbitraryLenConnectionID, versions []VersionNumber) {
 			for _, t := range tracers {
 				if t.ReceivedVersionNegotiationPacket != nil {
@@ -253,6 +267,20 @@ func NewMultiplexedConnectionTracer(tracers ...*ConnectionTracer) *ConnectionTra
 				}
 			}
 		},
+		MigratedConnection: func(local, remote net.Addr) {
+			for _, t := range tracers {
+				if t.MigratedConnection != nil {
+					t.MigratedConnection(local, remote)
+				}
+			}
+		},
+		AmplificationLimited: func(bytesReceived, bytesSent ByteCount) {
+			for _, t := range tracers {
+				if t.AmplificationLimited != nil {
+					t.AmplificationLimited(bytesReceived, bytesSent)
+				}
+			}
+		},
 		Close: func() {
 			for _, t := range tracers {
 				if t.Close != nil {
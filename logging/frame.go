@@ -12,14 +12,20 @@ type AckRange = wire.AckRange
 type (
 	// An AckFrame is an ACK frame.
 	AckFrame = wire.AckFrame
+	// An AckFrequencyFrame is an ACK_FREQUENCY frame.
+	AckFrequencyFrame = wire.AckFrequencyFrame
 	// A ConnectionCloseFrame is a CONNECTION_CLOSE frame.
 	ConnectionCloseFrame = wire.ConnectionCloseFrame
 	// A DataBlockedFrame is a DATA_BLOCKED frame.
 	DataBlockedFrame = wire.DataBlockedFrame
 	// A HandshakeDoneFrame is a HANDSHAKE_DONE frame.
 	HandshakeDoneFrame = wire.HandshakeDoneFrame
+	// An ImmediateAckFrame is an IMMEDIATE_ACK frame.
+	ImmediateAckFrame = wire.ImmediateAckFrame
 	// A MaxDataFrame is a MAX_DATA frame.
 	MaxDataFrame = wire.MaxDataFrame
+	// A MaxDatagramsFrame is a MAX_DATAGRAMS frame.
+	MaxDatagramsFrame = wire.MaxDatagramsFrame
 	// A MaxStreamDataFrame is a MAX_STREAM_DATA frame.
 	MaxStreamDataFrame = wire.MaxStreamDataFrame
 	// A MaxStreamsFrame is a MAX_STREAMS_FRAME.
@@ -36,6 +42,8 @@ type (
 	PingFrame = wire.PingFrame
 	// A ResetStreamFrame is a RESET_STREAM frame.
 	ResetStreamFrame = wire.ResetStreamFrame
+	// A ResetStreamAtFrame is a RESET_STREAM_AT frame.
+	ResetStreamAtFrame = wire.ResetStreamAtFrame
 	// A RetireConnectionIDFrame is a RETIRE_CONNECTION_ID frame.
 	RetireConnectionIDFrame = wire.RetireConnectionIDFrame
 	// A StopSendingFrame is a STOP_SENDING frame.
@@ -64,3 +72,9 @@ type StreamFrame struct {
 type DatagramFrame struct {
 	Length ByteCount
 }
+
+// A CustomFrame is a frame of an application-defined frame type.
+type CustomFrame struct {
+	Type   uint64
+	Length ByteCount
+}
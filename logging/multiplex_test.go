@@ -158,6 +158,12 @@ var _ = Describe("Tracing", func() {
 			tracer.SentShortHeaderPacket(hdr, 1337, ECNCE, ack, []Frame{ping})
 		})
 
+		It("traces the SentUDPDatagram event", func() {
+			tr1.EXPECT().SentUDPDatagram(ByteCount(1337), 3)
+			tr2.EXPECT().SentUDPDatagram(ByteCount(1337), 3)
+			tracer.SentUDPDatagram(1337, 3)
+		})
+
 		It("traces the ReceivedVersionNegotiationPacket event", func() {
 			src := ArbitraryLenConnectionID{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13}
 			dest := ArbitraryLenConnectionID{1, 2, 3, 4}
@@ -282,6 +288,20 @@ var _ = Describe("Tracing", func() {
 			tracer.LossTimerCanceled()
 		})
 
+		It("traces the MigratedConnection event", func() {
+			local := &net.UDPAddr{IP: net.IPv4(1, 2, 3, 4)}
+			remote := &net.UDPAddr{IP: net.IPv4(4, 3, 2, 1)}
+			tr1.EXPECT().MigratedConnection(local, remote)
+			tr2.EXPECT().MigratedConnection(local, remote)
+			tracer.MigratedConnection(local, remote)
+		})
+
+		It("traces the AmplificationLimited event", func() {
+			tr1.EXPECT().AmplificationLimited(ByteCount(400), ByteCount(1200))
+			tr2.EXPECT().AmplificationLimited(ByteCount(400), ByteCount(1200))
+			tracer.AmplificationLimited(400, 1200)
+		})
+
 		It("traces the Close event", func() {
 			tr1.EXPECT().Close()
 			tr2.EXPECT().Close()
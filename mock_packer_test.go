@@ -80,6 +80,42 @@ func (c *MockPackerAppendPacketCall) DoAndReturn(f func(*packetBuffer, protocol.
 	return c
 }
 
+// EnableQUICBitGreasing mocks base method.
+func (m *MockPacker) EnableQUICBitGreasing() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "EnableQUICBitGreasing")
+}
+
+// EnableQUICBitGreasing indicates an expected call of EnableQUICBitGreasing.
+func (mr *MockPackerMockRecorder) EnableQUICBitGreasing() *MockPackerEnableQUICBitGreasingCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnableQUICBitGreasing", reflect.TypeOf((*MockPacker)(nil).EnableQUICBitGreasing))
+	return &MockPackerEnableQUICBitGreasingCall{Call: call}
+}
+
+// MockPackerEnableQUICBitGreasingCall wrap *gomock.Call
+type MockPackerEnableQUICBitGreasingCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockPackerEnableQUICBitGreasingCall) Return() *MockPackerEnableQUICBitGreasingCall {
+	c.Call = c.Call.Return()
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockPackerEnableQUICBitGreasingCall) Do(f func()) *MockPackerEnableQUICBitGreasingCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockPackerEnableQUICBitGreasingCall) DoAndReturn(f func()) *MockPackerEnableQUICBitGreasingCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // MaybePackProbePacket mocks base method.
 func (m *MockPacker) MaybePackProbePacket(arg0 protocol.EncryptionLevel, arg1 protocol.ByteCount, arg2 protocol.Version) (*coalescedPacket, error) {
 	m.ctrl.T.Helper()
@@ -316,6 +352,82 @@ func (c *MockPackerPackMTUProbePacketCall) DoAndReturn(f func(ackhandler.Frame,
 	return c
 }
 
+// PackPathProbePacket mocks base method.
+func (m *MockPacker) PackPathProbePacket(arg0 protocol.ConnectionID, arg1 ackhandler.Frame, arg2 protocol.ByteCount, arg3 protocol.Version) (shortHeaderPacket, *packetBuffer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PackPathProbePacket", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(shortHeaderPacket)
+	ret1, _ := ret[1].(*packetBuffer)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// PackPathProbePacket indicates an expected call of PackPathProbePacket.
+func (mr *MockPackerMockRecorder) PackPathProbePacket(arg0, arg1, arg2, arg3 any) *MockPackerPackPathProbePacketCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PackPathProbePacket", reflect.TypeOf((*MockPacker)(nil).PackPathProbePacket), arg0, arg1, arg2, arg3)
+	return &MockPackerPackPathProbePacketCall{Call: call}
+}
+
+// MockPackerPackPathProbePacketCall wrap *gomock.Call
+type MockPackerPackPathProbePacketCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockPackerPackPathProbePacketCall) Return(arg0 shortHeaderPacket, arg1 *packetBuffer, arg2 error) *MockPackerPackPathProbePacketCall {
+	c.Call = c.Call.Return(arg0, arg1, arg2)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockPackerPackPathProbePacketCall) Do(f func(protocol.ConnectionID, ackhandler.Frame, protocol.ByteCount, protocol.Version) (shortHeaderPacket, *packetBuffer, error)) *MockPackerPackPathProbePacketCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockPackerPackPathProbePacketCall) DoAndReturn(f func(protocol.ConnectionID, ackhandler.Frame, protocol.ByteCount, protocol.Version) (shortHeaderPacket, *packetBuffer, error)) *MockPackerPackPathProbePacketCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// SetSpinBit mocks base method.
+func (m *MockPacker) SetSpinBit(arg0 bool) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetSpinBit", arg0)
+}
+
+// SetSpinBit indicates an expected call of SetSpinBit.
+func (mr *MockPackerMockRecorder) SetSpinBit(arg0 any) *MockPackerSetSpinBitCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetSpinBit", reflect.TypeOf((*MockPacker)(nil).SetSpinBit), arg0)
+	return &MockPackerSetSpinBitCall{Call: call}
+}
+
+// MockPackerSetSpinBitCall wrap *gomock.Call
+type MockPackerSetSpinBitCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockPackerSetSpinBitCall) Return() *MockPackerSetSpinBitCall {
+	c.Call = c.Call.Return()
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockPackerSetSpinBitCall) Do(f func(bool)) *MockPackerSetSpinBitCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockPackerSetSpinBitCall) DoAndReturn(f func(bool)) *MockPackerSetSpinBitCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // SetToken mocks base method.
 func (m *MockPacker) SetToken(arg0 []byte) {
 	m.ctrl.T.Helper()
@@ -39,6 +39,44 @@ func (m *MockPacketHandler) EXPECT() *MockPacketHandlerMockRecorder {
 	return m.recorder
 }
 
+// acceptsGreasedQUICBit mocks base method.
+func (m *MockPacketHandler) acceptsGreasedQUICBit() bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "acceptsGreasedQUICBit")
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// acceptsGreasedQUICBit indicates an expected call of acceptsGreasedQUICBit.
+func (mr *MockPacketHandlerMockRecorder) acceptsGreasedQUICBit() *MockPacketHandleracceptsGreasedQUICBitCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "acceptsGreasedQUICBit", reflect.TypeOf((*MockPacketHandler)(nil).acceptsGreasedQUICBit))
+	return &MockPacketHandleracceptsGreasedQUICBitCall{Call: call}
+}
+
+// MockPacketHandleracceptsGreasedQUICBitCall wrap *gomock.Call
+type MockPacketHandleracceptsGreasedQUICBitCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockPacketHandleracceptsGreasedQUICBitCall) Return(arg0 bool) *MockPacketHandleracceptsGreasedQUICBitCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockPacketHandleracceptsGreasedQUICBitCall) Do(f func() bool) *MockPacketHandleracceptsGreasedQUICBitCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockPacketHandleracceptsGreasedQUICBitCall) DoAndReturn(f func() bool) *MockPacketHandleracceptsGreasedQUICBitCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // closeWithTransportError mocks base method.
 func (m *MockPacketHandler) closeWithTransportError(arg0 qerr.TransportErrorCode) {
 	m.ctrl.T.Helper()
@@ -13,7 +13,9 @@ import (
 	context "context"
 	net "net"
 	reflect "reflect"
+	time "time"
 
+	protocol "github.com/quic-go/quic-go/internal/protocol"
 	qerr "github.com/quic-go/quic-go/internal/qerr"
 	gomock "go.uber.org/mock/gomock"
 )
@@ -41,6 +43,45 @@ func (m *MockQUICConn) EXPECT() *MockQUICConnMockRecorder {
 	return m.recorder
 }
 
+// AcceptMessage mocks base method.
+func (m *MockQUICConn) AcceptMessage(arg0 context.Context) ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AcceptMessage", arg0)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AcceptMessage indicates an expected call of AcceptMessage.
+func (mr *MockQUICConnMockRecorder) AcceptMessage(arg0 any) *MockQUICConnAcceptMessageCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AcceptMessage", reflect.TypeOf((*MockQUICConn)(nil).AcceptMessage), arg0)
+	return &MockQUICConnAcceptMessageCall{Call: call}
+}
+
+// MockQUICConnAcceptMessageCall wrap *gomock.Call
+type MockQUICConnAcceptMessageCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockQUICConnAcceptMessageCall) Return(arg0 []byte, arg1 error) *MockQUICConnAcceptMessageCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockQUICConnAcceptMessageCall) Do(f func(context.Context) ([]byte, error)) *MockQUICConnAcceptMessageCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockQUICConnAcceptMessageCall) DoAndReturn(f func(context.Context) ([]byte, error)) *MockQUICConnAcceptMessageCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // AcceptStream mocks base method.
 func (m *MockQUICConn) AcceptStream(arg0 context.Context) (Stream, error) {
 	m.ctrl.T.Helper()
@@ -119,502 +160,2103 @@ func (c *MockQUICConnAcceptUniStreamCall) DoAndReturn(f func(context.Context) (R
 	return c
 }
 
-// CloseWithError mocks base method.
-func (m *MockQUICConn) CloseWithError(arg0 qerr.ApplicationErrorCode, arg1 string) error {
+// ActiveStreams mocks base method.
+func (m *MockQUICConn) ActiveStreams() []protocol.StreamID {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CloseWithError", arg0, arg1)
-	ret0, _ := ret[0].(error)
+	ret := m.ctrl.Call(m, "ActiveStreams")
+	ret0, _ := ret[0].([]protocol.StreamID)
 	return ret0
 }
 
-// CloseWithError indicates an expected call of CloseWithError.
-func (mr *MockQUICConnMockRecorder) CloseWithError(arg0, arg1 any) *MockQUICConnCloseWithErrorCall {
+// ActiveStreams indicates an expected call of ActiveStreams.
+func (mr *MockQUICConnMockRecorder) ActiveStreams() *MockQUICConnActiveStreamsCall {
 	mr.mock.ctrl.T.Helper()
-	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CloseWithError", reflect.TypeOf((*MockQUICConn)(nil).CloseWithError), arg0, arg1)
-	return &MockQUICConnCloseWithErrorCall{Call: call}
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ActiveStreams", reflect.TypeOf((*MockQUICConn)(nil).ActiveStreams))
+	return &MockQUICConnActiveStreamsCall{Call: call}
 }
 
-// MockQUICConnCloseWithErrorCall wrap *gomock.Call
-type MockQUICConnCloseWithErrorCall struct {
+// MockQUICConnActiveStreamsCall wrap *gomock.Call
+type MockQUICConnActiveStreamsCall struct {
 	*gomock.Call
 }
 
 // Return rewrite *gomock.Call.Return
-func (c *MockQUICConnCloseWithErrorCall) Return(arg0 error) *MockQUICConnCloseWithErrorCall {
+func (c *MockQUICConnActiveStreamsCall) Return(arg0 []protocol.StreamID) *MockQUICConnActiveStreamsCall {
 	c.Call = c.Call.Return(arg0)
 	return c
 }
 
 // Do rewrite *gomock.Call.Do
-func (c *MockQUICConnCloseWithErrorCall) Do(f func(qerr.ApplicationErrorCode, string) error) *MockQUICConnCloseWithErrorCall {
+func (c *MockQUICConnActiveStreamsCall) Do(f func() []protocol.StreamID) *MockQUICConnActiveStreamsCall {
 	c.Call = c.Call.Do(f)
 	return c
 }
 
 // DoAndReturn rewrite *gomock.Call.DoAndReturn
-func (c *MockQUICConnCloseWithErrorCall) DoAndReturn(f func(qerr.ApplicationErrorCode, string) error) *MockQUICConnCloseWithErrorCall {
+func (c *MockQUICConnActiveStreamsCall) DoAndReturn(f func() []protocol.StreamID) *MockQUICConnActiveStreamsCall {
 	c.Call = c.Call.DoAndReturn(f)
 	return c
 }
 
-// ConnectionState mocks base method.
-func (m *MockQUICConn) ConnectionState() ConnectionState {
+// AddPath mocks base method.
+func (m *MockQUICConn) AddPath(arg0, arg1 net.Addr) (PathID, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ConnectionState")
-	ret0, _ := ret[0].(ConnectionState)
-	return ret0
+	ret := m.ctrl.Call(m, "AddPath", arg0, arg1)
+	ret0, _ := ret[0].(PathID)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
-// ConnectionState indicates an expected call of ConnectionState.
-func (mr *MockQUICConnMockRecorder) ConnectionState() *MockQUICConnConnectionStateCall {
+// AddPath indicates an expected call of AddPath.
+func (mr *MockQUICConnMockRecorder) AddPath(arg0, arg1 any) *MockQUICConnAddPathCall {
 	mr.mock.ctrl.T.Helper()
-	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConnectionState", reflect.TypeOf((*MockQUICConn)(nil).ConnectionState))
-	return &MockQUICConnConnectionStateCall{Call: call}
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddPath", reflect.TypeOf((*MockQUICConn)(nil).AddPath), arg0, arg1)
+	return &MockQUICConnAddPathCall{Call: call}
 }
 
-// MockQUICConnConnectionStateCall wrap *gomock.Call
-type MockQUICConnConnectionStateCall struct {
+// MockQUICConnAddPathCall wrap *gomock.Call
+type MockQUICConnAddPathCall struct {
 	*gomock.Call
 }
 
 // Return rewrite *gomock.Call.Return
-func (c *MockQUICConnConnectionStateCall) Return(arg0 ConnectionState) *MockQUICConnConnectionStateCall {
-	c.Call = c.Call.Return(arg0)
+func (c *MockQUICConnAddPathCall) Return(arg0 PathID, arg1 error) *MockQUICConnAddPathCall {
+	c.Call = c.Call.Return(arg0, arg1)
 	return c
 }
 
 // Do rewrite *gomock.Call.Do
-func (c *MockQUICConnConnectionStateCall) Do(f func() ConnectionState) *MockQUICConnConnectionStateCall {
+func (c *MockQUICConnAddPathCall) Do(f func(net.Addr, net.Addr) (PathID, error)) *MockQUICConnAddPathCall {
 	c.Call = c.Call.Do(f)
 	return c
 }
 
 // DoAndReturn rewrite *gomock.Call.DoAndReturn
-func (c *MockQUICConnConnectionStateCall) DoAndReturn(f func() ConnectionState) *MockQUICConnConnectionStateCall {
+func (c *MockQUICConnAddPathCall) DoAndReturn(f func(net.Addr, net.Addr) (PathID, error)) *MockQUICConnAddPathCall {
 	c.Call = c.Call.DoAndReturn(f)
 	return c
 }
 
-// Context mocks base method.
-func (m *MockQUICConn) Context() context.Context {
+// BytesReceived mocks base method.
+func (m *MockQUICConn) BytesReceived() uint64 {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Context")
-	ret0, _ := ret[0].(context.Context)
+	ret := m.ctrl.Call(m, "BytesReceived")
+	ret0, _ := ret[0].(uint64)
 	return ret0
 }
 
-// Context indicates an expected call of Context.
-func (mr *MockQUICConnMockRecorder) Context() *MockQUICConnContextCall {
+// BytesReceived indicates an expected call of BytesReceived.
+func (mr *MockQUICConnMockRecorder) BytesReceived() *MockQUICConnBytesReceivedCall {
 	mr.mock.ctrl.T.Helper()
-	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Context", reflect.TypeOf((*MockQUICConn)(nil).Context))
-	return &MockQUICConnContextCall{Call: call}
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BytesReceived", reflect.TypeOf((*MockQUICConn)(nil).BytesReceived))
+	return &MockQUICConnBytesReceivedCall{Call: call}
 }
 
-// MockQUICConnContextCall wrap *gomock.Call
-type MockQUICConnContextCall struct {
+// MockQUICConnBytesReceivedCall wrap *gomock.Call
+type MockQUICConnBytesReceivedCall struct {
 	*gomock.Call
 }
 
 // Return rewrite *gomock.Call.Return
-func (c *MockQUICConnContextCall) Return(arg0 context.Context) *MockQUICConnContextCall {
+func (c *MockQUICConnBytesReceivedCall) Return(arg0 uint64) *MockQUICConnBytesReceivedCall {
 	c.Call = c.Call.Return(arg0)
 	return c
 }
 
 // Do rewrite *gomock.Call.Do
-func (c *MockQUICConnContextCall) Do(f func() context.Context) *MockQUICConnContextCall {
+func (c *MockQUICConnBytesReceivedCall) Do(f func() uint64) *MockQUICConnBytesReceivedCall {
 	c.Call = c.Call.Do(f)
 	return c
 }
 
 // DoAndReturn rewrite *gomock.Call.DoAndReturn
-func (c *MockQUICConnContextCall) DoAndReturn(f func() context.Context) *MockQUICConnContextCall {
+func (c *MockQUICConnBytesReceivedCall) DoAndReturn(f func() uint64) *MockQUICConnBytesReceivedCall {
 	c.Call = c.Call.DoAndReturn(f)
 	return c
 }
 
-// HandshakeComplete mocks base method.
-func (m *MockQUICConn) HandshakeComplete() <-chan struct{} {
+// BytesSent mocks base method.
+func (m *MockQUICConn) BytesSent() uint64 {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "HandshakeComplete")
-	ret0, _ := ret[0].(<-chan struct{})
+	ret := m.ctrl.Call(m, "BytesSent")
+	ret0, _ := ret[0].(uint64)
 	return ret0
 }
 
-// HandshakeComplete indicates an expected call of HandshakeComplete.
-func (mr *MockQUICConnMockRecorder) HandshakeComplete() *MockQUICConnHandshakeCompleteCall {
+// BytesSent indicates an expected call of BytesSent.
+func (mr *MockQUICConnMockRecorder) BytesSent() *MockQUICConnBytesSentCall {
 	mr.mock.ctrl.T.Helper()
-	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HandshakeComplete", reflect.TypeOf((*MockQUICConn)(nil).HandshakeComplete))
-	return &MockQUICConnHandshakeCompleteCall{Call: call}
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BytesSent", reflect.TypeOf((*MockQUICConn)(nil).BytesSent))
+	return &MockQUICConnBytesSentCall{Call: call}
 }
 
-// MockQUICConnHandshakeCompleteCall wrap *gomock.Call
-type MockQUICConnHandshakeCompleteCall struct {
+// MockQUICConnBytesSentCall wrap *gomock.Call
+type MockQUICConnBytesSentCall struct {
 	*gomock.Call
 }
 
 // Return rewrite *gomock.Call.Return
-func (c *MockQUICConnHandshakeCompleteCall) Return(arg0 <-chan struct{}) *MockQUICConnHandshakeCompleteCall {
+func (c *MockQUICConnBytesSentCall) Return(arg0 uint64) *MockQUICConnBytesSentCall {
 	c.Call = c.Call.Return(arg0)
 	return c
 }
 
 // Do rewrite *gomock.Call.Do
-func (c *MockQUICConnHandshakeCompleteCall) Do(f func() <-chan struct{}) *MockQUICConnHandshakeCompleteCall {
+func (c *MockQUICConnBytesSentCall) Do(f func() uint64) *MockQUICConnBytesSentCall {
 	c.Call = c.Call.Do(f)
 	return c
 }
 
 // DoAndReturn rewrite *gomock.Call.DoAndReturn
-func (c *MockQUICConnHandshakeCompleteCall) DoAndReturn(f func() <-chan struct{}) *MockQUICConnHandshakeCompleteCall {
+func (c *MockQUICConnBytesSentCall) DoAndReturn(f func() uint64) *MockQUICConnBytesSentCall {
 	c.Call = c.Call.DoAndReturn(f)
 	return c
 }
 
-// LocalAddr mocks base method.
-func (m *MockQUICConn) LocalAddr() net.Addr {
+// CloseWithError mocks base method.
+func (m *MockQUICConn) CloseWithError(arg0 qerr.ApplicationErrorCode, arg1 string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "LocalAddr")
-	ret0, _ := ret[0].(net.Addr)
+	ret := m.ctrl.Call(m, "CloseWithError", arg0, arg1)
+	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// LocalAddr indicates an expected call of LocalAddr.
-func (mr *MockQUICConnMockRecorder) LocalAddr() *MockQUICConnLocalAddrCall {
+// CloseWithError indicates an expected call of CloseWithError.
+func (mr *MockQUICConnMockRecorder) CloseWithError(arg0, arg1 any) *MockQUICConnCloseWithErrorCall {
 	mr.mock.ctrl.T.Helper()
-	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LocalAddr", reflect.TypeOf((*MockQUICConn)(nil).LocalAddr))
-	return &MockQUICConnLocalAddrCall{Call: call}
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CloseWithError", reflect.TypeOf((*MockQUICConn)(nil).CloseWithError), arg0, arg1)
+	return &MockQUICConnCloseWithErrorCall{Call: call}
 }
 
-// MockQUICConnLocalAddrCall wrap *gomock.Call
-type MockQUICConnLocalAddrCall struct {
+// MockQUICConnCloseWithErrorCall wrap *gomock.Call
+type MockQUICConnCloseWithErrorCall struct {
 	*gomock.Call
 }
 
 // Return rewrite *gomock.Call.Return
-func (c *MockQUICConnLocalAddrCall) Return(arg0 net.Addr) *MockQUICConnLocalAddrCall {
+func (c *MockQUICConnCloseWithErrorCall) Return(arg0 error) *MockQUICConnCloseWithErrorCall {
 	c.Call = c.Call.Return(arg0)
 	return c
 }
 
 // Do rewrite *gomock.Call.Do
-func (c *MockQUICConnLocalAddrCall) Do(f func() net.Addr) *MockQUICConnLocalAddrCall {
+func (c *MockQUICConnCloseWithErrorCall) Do(f func(qerr.ApplicationErrorCode, string) error) *MockQUICConnCloseWithErrorCall {
 	c.Call = c.Call.Do(f)
 	return c
 }
 
 // DoAndReturn rewrite *gomock.Call.DoAndReturn
-func (c *MockQUICConnLocalAddrCall) DoAndReturn(f func() net.Addr) *MockQUICConnLocalAddrCall {
+func (c *MockQUICConnCloseWithErrorCall) DoAndReturn(f func(qerr.ApplicationErrorCode, string) error) *MockQUICConnCloseWithErrorCall {
 	c.Call = c.Call.DoAndReturn(f)
 	return c
 }
 
-// NextConnection mocks base method.
-func (m *MockQUICConn) NextConnection(arg0 context.Context) (Connection, error) {
+// ConnectionState mocks base method.
+func (m *MockQUICConn) ConnectionState() ConnectionState {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "NextConnection", arg0)
-	ret0, _ := ret[0].(Connection)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
+	ret := m.ctrl.Call(m, "ConnectionState")
+	ret0, _ := ret[0].(ConnectionState)
+	return ret0
 }
 
-// NextConnection indicates an expected call of NextConnection.
-func (mr *MockQUICConnMockRecorder) NextConnection(arg0 any) *MockQUICConnNextConnectionCall {
+// ConnectionState indicates an expected call of ConnectionState.
+func (mr *MockQUICConnMockRecorder) ConnectionState() *MockQUICConnConnectionStateCall {
 	mr.mock.ctrl.T.Helper()
-	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NextConnection", reflect.TypeOf((*MockQUICConn)(nil).NextConnection), arg0)
-	return &MockQUICConnNextConnectionCall{Call: call}
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConnectionState", reflect.TypeOf((*MockQUICConn)(nil).ConnectionState))
+	return &MockQUICConnConnectionStateCall{Call: call}
 }
 
-// MockQUICConnNextConnectionCall wrap *gomock.Call
-type MockQUICConnNextConnectionCall struct {
+// MockQUICConnConnectionStateCall wrap *gomock.Call
+type MockQUICConnConnectionStateCall struct {
 	*gomock.Call
 }
 
 // Return rewrite *gomock.Call.Return
-func (c *MockQUICConnNextConnectionCall) Return(arg0 Connection, arg1 error) *MockQUICConnNextConnectionCall {
-	c.Call = c.Call.Return(arg0, arg1)
+func (c *MockQUICConnConnectionStateCall) Return(arg0 ConnectionState) *MockQUICConnConnectionStateCall {
+	c.Call = c.Call.Return(arg0)
 	return c
 }
 
 // Do rewrite *gomock.Call.Do
-func (c *MockQUICConnNextConnectionCall) Do(f func(context.Context) (Connection, error)) *MockQUICConnNextConnectionCall {
+func (c *MockQUICConnConnectionStateCall) Do(f func() ConnectionState) *MockQUICConnConnectionStateCall {
 	c.Call = c.Call.Do(f)
 	return c
 }
 
 // DoAndReturn rewrite *gomock.Call.DoAndReturn
-func (c *MockQUICConnNextConnectionCall) DoAndReturn(f func(context.Context) (Connection, error)) *MockQUICConnNextConnectionCall {
+func (c *MockQUICConnConnectionStateCall) DoAndReturn(f func() ConnectionState) *MockQUICConnConnectionStateCall {
 	c.Call = c.Call.DoAndReturn(f)
 	return c
 }
 
-// OpenStream mocks base method.
-func (m *MockQUICConn) OpenStream() (Stream, error) {
+// Context mocks base method.
+func (m *MockQUICConn) Context() context.Context {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "OpenStream")
-	ret0, _ := ret[0].(Stream)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
+	ret := m.ctrl.Call(m, "Context")
+	ret0, _ := ret[0].(context.Context)
+	return ret0
 }
 
-// OpenStream indicates an expected call of OpenStream.
-func (mr *MockQUICConnMockRecorder) OpenStream() *MockQUICConnOpenStreamCall {
+// Context indicates an expected call of Context.
+func (mr *MockQUICConnMockRecorder) Context() *MockQUICConnContextCall {
 	mr.mock.ctrl.T.Helper()
-	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OpenStream", reflect.TypeOf((*MockQUICConn)(nil).OpenStream))
-	return &MockQUICConnOpenStreamCall{Call: call}
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Context", reflect.TypeOf((*MockQUICConn)(nil).Context))
+	return &MockQUICConnContextCall{Call: call}
 }
 
-// MockQUICConnOpenStreamCall wrap *gomock.Call
-type MockQUICConnOpenStreamCall struct {
+// MockQUICConnContextCall wrap *gomock.Call
+type MockQUICConnContextCall struct {
 	*gomock.Call
 }
 
 // Return rewrite *gomock.Call.Return
-func (c *MockQUICConnOpenStreamCall) Return(arg0 Stream, arg1 error) *MockQUICConnOpenStreamCall {
-	c.Call = c.Call.Return(arg0, arg1)
+func (c *MockQUICConnContextCall) Return(arg0 context.Context) *MockQUICConnContextCall {
+	c.Call = c.Call.Return(arg0)
 	return c
 }
 
 // Do rewrite *gomock.Call.Do
-func (c *MockQUICConnOpenStreamCall) Do(f func() (Stream, error)) *MockQUICConnOpenStreamCall {
+func (c *MockQUICConnContextCall) Do(f func() context.Context) *MockQUICConnContextCall {
 	c.Call = c.Call.Do(f)
 	return c
 }
 
 // DoAndReturn rewrite *gomock.Call.DoAndReturn
-func (c *MockQUICConnOpenStreamCall) DoAndReturn(f func() (Stream, error)) *MockQUICConnOpenStreamCall {
+func (c *MockQUICConnContextCall) DoAndReturn(f func() context.Context) *MockQUICConnContextCall {
 	c.Call = c.Call.DoAndReturn(f)
 	return c
 }
 
-// OpenStreamSync mocks base method.
-func (m *MockQUICConn) OpenStreamSync(arg0 context.Context) (Stream, error) {
+// CurrentMTU mocks base method.
+func (m *MockQUICConn) CurrentMTU() protocol.ByteCount {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "OpenStreamSync", arg0)
-	ret0, _ := ret[0].(Stream)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
+	ret := m.ctrl.Call(m, "CurrentMTU")
+	ret0, _ := ret[0].(protocol.ByteCount)
+	return ret0
 }
 
-// OpenStreamSync indicates an expected call of OpenStreamSync.
-func (mr *MockQUICConnMockRecorder) OpenStreamSync(arg0 any) *MockQUICConnOpenStreamSyncCall {
+// CurrentMTU indicates an expected call of CurrentMTU.
+func (mr *MockQUICConnMockRecorder) CurrentMTU() *MockQUICConnCurrentMTUCall {
 	mr.mock.ctrl.T.Helper()
-	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OpenStreamSync", reflect.TypeOf((*MockQUICConn)(nil).OpenStreamSync), arg0)
-	return &MockQUICConnOpenStreamSyncCall{Call: call}
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CurrentMTU", reflect.TypeOf((*MockQUICConn)(nil).CurrentMTU))
+	return &MockQUICConnCurrentMTUCall{Call: call}
 }
 
-// MockQUICConnOpenStreamSyncCall wrap *gomock.Call
-type MockQUICConnOpenStreamSyncCall struct {
+// MockQUICConnCurrentMTUCall wrap *gomock.Call
+type MockQUICConnCurrentMTUCall struct {
 	*gomock.Call
 }
 
 // Return rewrite *gomock.Call.Return
-func (c *MockQUICConnOpenStreamSyncCall) Return(arg0 Stream, arg1 error) *MockQUICConnOpenStreamSyncCall {
-	c.Call = c.Call.Return(arg0, arg1)
+func (c *MockQUICConnCurrentMTUCall) Return(arg0 protocol.ByteCount) *MockQUICConnCurrentMTUCall {
+	c.Call = c.Call.Return(arg0)
 	return c
 }
 
 // Do rewrite *gomock.Call.Do
-func (c *MockQUICConnOpenStreamSyncCall) Do(f func(context.Context) (Stream, error)) *MockQUICConnOpenStreamSyncCall {
+func (c *MockQUICConnCurrentMTUCall) Do(f func() protocol.ByteCount) *MockQUICConnCurrentMTUCall {
 	c.Call = c.Call.Do(f)
 	return c
 }
 
 // DoAndReturn rewrite *gomock.Call.DoAndReturn
-func (c *MockQUICConnOpenStreamSyncCall) DoAndReturn(f func(context.Context) (Stream, error)) *MockQUICConnOpenStreamSyncCall {
+func (c *MockQUICConnCurrentMTUCall) DoAndReturn(f func() protocol.ByteCount) *MockQUICConnCurrentMTUCall {
 	c.Call = c.Call.DoAndReturn(f)
 	return c
 }
 
-// OpenUniStream mocks base method.
-func (m *MockQUICConn) OpenUniStream() (SendStream, error) {
+// DatagramsSupported mocks base method.
+func (m *MockQUICConn) DatagramsSupported() bool {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "OpenUniStream")
-	ret0, _ := ret[0].(SendStream)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
+	ret := m.ctrl.Call(m, "DatagramsSupported")
+	ret0, _ := ret[0].(bool)
+	return ret0
 }
 
-// OpenUniStream indicates an expected call of OpenUniStream.
-func (mr *MockQUICConnMockRecorder) OpenUniStream() *MockQUICConnOpenUniStreamCall {
+// DatagramsSupported indicates an expected call of DatagramsSupported.
+func (mr *MockQUICConnMockRecorder) DatagramsSupported() *MockQUICConnDatagramsSupportedCall {
 	mr.mock.ctrl.T.Helper()
-	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OpenUniStream", reflect.TypeOf((*MockQUICConn)(nil).OpenUniStream))
-	return &MockQUICConnOpenUniStreamCall{Call: call}
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DatagramsSupported", reflect.TypeOf((*MockQUICConn)(nil).DatagramsSupported))
+	return &MockQUICConnDatagramsSupportedCall{Call: call}
 }
 
-// MockQUICConnOpenUniStreamCall wrap *gomock.Call
-type MockQUICConnOpenUniStreamCall struct {
+// MockQUICConnDatagramsSupportedCall wrap *gomock.Call
+type MockQUICConnDatagramsSupportedCall struct {
 	*gomock.Call
 }
 
 // Return rewrite *gomock.Call.Return
-func (c *MockQUICConnOpenUniStreamCall) Return(arg0 SendStream, arg1 error) *MockQUICConnOpenUniStreamCall {
-	c.Call = c.Call.Return(arg0, arg1)
+func (c *MockQUICConnDatagramsSupportedCall) Return(arg0 bool) *MockQUICConnDatagramsSupportedCall {
+	c.Call = c.Call.Return(arg0)
 	return c
 }
 
 // Do rewrite *gomock.Call.Do
-func (c *MockQUICConnOpenUniStreamCall) Do(f func() (SendStream, error)) *MockQUICConnOpenUniStreamCall {
+func (c *MockQUICConnDatagramsSupportedCall) Do(f func() bool) *MockQUICConnDatagramsSupportedCall {
 	c.Call = c.Call.Do(f)
 	return c
 }
 
 // DoAndReturn rewrite *gomock.Call.DoAndReturn
-func (c *MockQUICConnOpenUniStreamCall) DoAndReturn(f func() (SendStream, error)) *MockQUICConnOpenUniStreamCall {
+func (c *MockQUICConnDatagramsSupportedCall) DoAndReturn(f func() bool) *MockQUICConnDatagramsSupportedCall {
 	c.Call = c.Call.DoAndReturn(f)
 	return c
 }
 
-// OpenUniStreamSync mocks base method.
-func (m *MockQUICConn) OpenUniStreamSync(arg0 context.Context) (SendStream, error) {
+// ECNStats mocks base method.
+func (m *MockQUICConn) ECNStats() ECNStats {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "OpenUniStreamSync", arg0)
-	ret0, _ := ret[0].(SendStream)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
+	ret := m.ctrl.Call(m, "ECNStats")
+	ret0, _ := ret[0].(ECNStats)
+	return ret0
 }
 
-// OpenUniStreamSync indicates an expected call of OpenUniStreamSync.
-func (mr *MockQUICConnMockRecorder) OpenUniStreamSync(arg0 any) *MockQUICConnOpenUniStreamSyncCall {
+// ECNStats indicates an expected call of ECNStats.
+func (mr *MockQUICConnMockRecorder) ECNStats() *MockQUICConnECNStatsCall {
 	mr.mock.ctrl.T.Helper()
-	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OpenUniStreamSync", reflect.TypeOf((*MockQUICConn)(nil).OpenUniStreamSync), arg0)
-	return &MockQUICConnOpenUniStreamSyncCall{Call: call}
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ECNStats", reflect.TypeOf((*MockQUICConn)(nil).ECNStats))
+	return &MockQUICConnECNStatsCall{Call: call}
 }
 
-// MockQUICConnOpenUniStreamSyncCall wrap *gomock.Call
-type MockQUICConnOpenUniStreamSyncCall struct {
+// MockQUICConnECNStatsCall wrap *gomock.Call
+type MockQUICConnECNStatsCall struct {
 	*gomock.Call
 }
 
 // Return rewrite *gomock.Call.Return
-func (c *MockQUICConnOpenUniStreamSyncCall) Return(arg0 SendStream, arg1 error) *MockQUICConnOpenUniStreamSyncCall {
-	c.Call = c.Call.Return(arg0, arg1)
+func (c *MockQUICConnECNStatsCall) Return(arg0 ECNStats) *MockQUICConnECNStatsCall {
+	c.Call = c.Call.Return(arg0)
 	return c
 }
 
 // Do rewrite *gomock.Call.Do
-func (c *MockQUICConnOpenUniStreamSyncCall) Do(f func(context.Context) (SendStream, error)) *MockQUICConnOpenUniStreamSyncCall {
+func (c *MockQUICConnECNStatsCall) Do(f func() ECNStats) *MockQUICConnECNStatsCall {
 	c.Call = c.Call.Do(f)
 	return c
 }
 
 // DoAndReturn rewrite *gomock.Call.DoAndReturn
-func (c *MockQUICConnOpenUniStreamSyncCall) DoAndReturn(f func(context.Context) (SendStream, error)) *MockQUICConnOpenUniStreamSyncCall {
+func (c *MockQUICConnECNStatsCall) DoAndReturn(f func() ECNStats) *MockQUICConnECNStatsCall {
 	c.Call = c.Call.DoAndReturn(f)
 	return c
 }
 
-// ReceiveDatagram mocks base method.
-func (m *MockQUICConn) ReceiveDatagram(arg0 context.Context) ([]byte, error) {
+// EstimatedBandwidth mocks base method.
+func (m *MockQUICConn) EstimatedBandwidth() (uint64, bool) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ReceiveDatagram", arg0)
-	ret0, _ := ret[0].([]byte)
-	ret1, _ := ret[1].(error)
+	ret := m.ctrl.Call(m, "EstimatedBandwidth")
+	ret0, _ := ret[0].(uint64)
+	ret1, _ := ret[1].(bool)
 	return ret0, ret1
 }
 
-// ReceiveDatagram indicates an expected call of ReceiveDatagram.
-func (mr *MockQUICConnMockRecorder) ReceiveDatagram(arg0 any) *MockQUICConnReceiveDatagramCall {
+// EstimatedBandwidth indicates an expected call of EstimatedBandwidth.
+func (mr *MockQUICConnMockRecorder) EstimatedBandwidth() *MockQUICConnEstimatedBandwidthCall {
 	mr.mock.ctrl.T.Helper()
-	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReceiveDatagram", reflect.TypeOf((*MockQUICConn)(nil).ReceiveDatagram), arg0)
-	return &MockQUICConnReceiveDatagramCall{Call: call}
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EstimatedBandwidth", reflect.TypeOf((*MockQUICConn)(nil).EstimatedBandwidth))
+	return &MockQUICConnEstimatedBandwidthCall{Call: call}
 }
 
-// MockQUICConnReceiveDatagramCall wrap *gomock.Call
-type MockQUICConnReceiveDatagramCall struct {
+// MockQUICConnEstimatedBandwidthCall wrap *gomock.Call
+type MockQUICConnEstimatedBandwidthCall struct {
 	*gomock.Call
 }
 
 // Return rewrite *gomock.Call.Return
-func (c *MockQUICConnReceiveDatagramCall) Return(arg0 []byte, arg1 error) *MockQUICConnReceiveDatagramCall {
+func (c *MockQUICConnEstimatedBandwidthCall) Return(arg0 uint64, arg1 bool) *MockQUICConnEstimatedBandwidthCall {
 	c.Call = c.Call.Return(arg0, arg1)
 	return c
 }
 
 // Do rewrite *gomock.Call.Do
-func (c *MockQUICConnReceiveDatagramCall) Do(f func(context.Context) ([]byte, error)) *MockQUICConnReceiveDatagramCall {
+func (c *MockQUICConnEstimatedBandwidthCall) Do(f func() (uint64, bool)) *MockQUICConnEstimatedBandwidthCall {
 	c.Call = c.Call.Do(f)
 	return c
 }
 
 // DoAndReturn rewrite *gomock.Call.DoAndReturn
-func (c *MockQUICConnReceiveDatagramCall) DoAndReturn(f func(context.Context) ([]byte, error)) *MockQUICConnReceiveDatagramCall {
+func (c *MockQUICConnEstimatedBandwidthCall) DoAndReturn(f func() (uint64, bool)) *MockQUICConnEstimatedBandwidthCall {
 	c.Call = c.Call.DoAndReturn(f)
 	return c
 }
 
-// RemoteAddr mocks base method.
-func (m *MockQUICConn) RemoteAddr() net.Addr {
+// Flush mocks base method.
+func (m *MockQUICConn) Flush() error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "RemoteAddr")
-	ret0, _ := ret[0].(net.Addr)
+	ret := m.ctrl.Call(m, "Flush")
+	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// RemoteAddr indicates an expected call of RemoteAddr.
-func (mr *MockQUICConnMockRecorder) RemoteAddr() *MockQUICConnRemoteAddrCall {
+// Flush indicates an expected call of Flush.
+func (mr *MockQUICConnMockRecorder) Flush() *MockQUICConnFlushCall {
 	mr.mock.ctrl.T.Helper()
-	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoteAddr", reflect.TypeOf((*MockQUICConn)(nil).RemoteAddr))
-	return &MockQUICConnRemoteAddrCall{Call: call}
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Flush", reflect.TypeOf((*MockQUICConn)(nil).Flush))
+	return &MockQUICConnFlushCall{Call: call}
 }
 
-// MockQUICConnRemoteAddrCall wrap *gomock.Call
-type MockQUICConnRemoteAddrCall struct {
+// MockQUICConnFlushCall wrap *gomock.Call
+type MockQUICConnFlushCall struct {
 	*gomock.Call
 }
 
 // Return rewrite *gomock.Call.Return
-func (c *MockQUICConnRemoteAddrCall) Return(arg0 net.Addr) *MockQUICConnRemoteAddrCall {
+func (c *MockQUICConnFlushCall) Return(arg0 error) *MockQUICConnFlushCall {
 	c.Call = c.Call.Return(arg0)
 	return c
 }
 
 // Do rewrite *gomock.Call.Do
-func (c *MockQUICConnRemoteAddrCall) Do(f func() net.Addr) *MockQUICConnRemoteAddrCall {
+func (c *MockQUICConnFlushCall) Do(f func() error) *MockQUICConnFlushCall {
 	c.Call = c.Call.Do(f)
 	return c
 }
 
 // DoAndReturn rewrite *gomock.Call.DoAndReturn
-func (c *MockQUICConnRemoteAddrCall) DoAndReturn(f func() net.Addr) *MockQUICConnRemoteAddrCall {
+func (c *MockQUICConnFlushCall) DoAndReturn(f func() error) *MockQUICConnFlushCall {
 	c.Call = c.Call.DoAndReturn(f)
 	return c
 }
 
-// SendDatagram mocks base method.
-func (m *MockQUICConn) SendDatagram(arg0 []byte) error {
+// GetConfig mocks base method.
+func (m *MockQUICConn) GetConfig() *Config {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetConfig")
+	ret0, _ := ret[0].(*Config)
+	return ret0
+}
+
+// GetConfig indicates an expected call of GetConfig.
+func (mr *MockQUICConnMockRecorder) GetConfig() *MockQUICConnGetConfigCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetConfig", reflect.TypeOf((*MockQUICConn)(nil).GetConfig))
+	return &MockQUICConnGetConfigCall{Call: call}
+}
+
+// MockQUICConnGetConfigCall wrap *gomock.Call
+type MockQUICConnGetConfigCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockQUICConnGetConfigCall) Return(arg0 *Config) *MockQUICConnGetConfigCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockQUICConnGetConfigCall) Do(f func() *Config) *MockQUICConnGetConfigCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockQUICConnGetConfigCall) DoAndReturn(f func() *Config) *MockQUICConnGetConfigCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// HandshakeComplete mocks base method.
+func (m *MockQUICConn) HandshakeComplete() <-chan struct{} {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HandshakeComplete")
+	ret0, _ := ret[0].(<-chan struct{})
+	return ret0
+}
+
+// HandshakeComplete indicates an expected call of HandshakeComplete.
+func (mr *MockQUICConnMockRecorder) HandshakeComplete() *MockQUICConnHandshakeCompleteCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HandshakeComplete", reflect.TypeOf((*MockQUICConn)(nil).HandshakeComplete))
+	return &MockQUICConnHandshakeCompleteCall{Call: call}
+}
+
+// MockQUICConnHandshakeCompleteCall wrap *gomock.Call
+type MockQUICConnHandshakeCompleteCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockQUICConnHandshakeCompleteCall) Return(arg0 <-chan struct{}) *MockQUICConnHandshakeCompleteCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockQUICConnHandshakeCompleteCall) Do(f func() <-chan struct{}) *MockQUICConnHandshakeCompleteCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockQUICConnHandshakeCompleteCall) DoAndReturn(f func() <-chan struct{}) *MockQUICConnHandshakeCompleteCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// InitiateKeyUpdate mocks base method.
+func (m *MockQUICConn) InitiateKeyUpdate() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InitiateKeyUpdate")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// InitiateKeyUpdate indicates an expected call of InitiateKeyUpdate.
+func (mr *MockQUICConnMockRecorder) InitiateKeyUpdate() *MockQUICConnInitiateKeyUpdateCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InitiateKeyUpdate", reflect.TypeOf((*MockQUICConn)(nil).InitiateKeyUpdate))
+	return &MockQUICConnInitiateKeyUpdateCall{Call: call}
+}
+
+// MockQUICConnInitiateKeyUpdateCall wrap *gomock.Call
+type MockQUICConnInitiateKeyUpdateCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockQUICConnInitiateKeyUpdateCall) Return(arg0 error) *MockQUICConnInitiateKeyUpdateCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockQUICConnInitiateKeyUpdateCall) Do(f func() error) *MockQUICConnInitiateKeyUpdateCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockQUICConnInitiateKeyUpdateCall) DoAndReturn(f func() error) *MockQUICConnInitiateKeyUpdateCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// KeyPhase mocks base method.
+func (m *MockQUICConn) KeyPhase() (byte, uint64) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "KeyPhase")
+	ret0, _ := ret[0].(byte)
+	ret1, _ := ret[1].(uint64)
+	return ret0, ret1
+}
+
+// KeyPhase indicates an expected call of KeyPhase.
+func (mr *MockQUICConnMockRecorder) KeyPhase() *MockQUICConnKeyPhaseCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "KeyPhase", reflect.TypeOf((*MockQUICConn)(nil).KeyPhase))
+	return &MockQUICConnKeyPhaseCall{Call: call}
+}
+
+// MockQUICConnKeyPhaseCall wrap *gomock.Call
+type MockQUICConnKeyPhaseCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockQUICConnKeyPhaseCall) Return(arg0 byte, arg1 uint64) *MockQUICConnKeyPhaseCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockQUICConnKeyPhaseCall) Do(f func() (byte, uint64)) *MockQUICConnKeyPhaseCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockQUICConnKeyPhaseCall) DoAndReturn(f func() (byte, uint64)) *MockQUICConnKeyPhaseCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// LocalAddr mocks base method.
+func (m *MockQUICConn) LocalAddr() net.Addr {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LocalAddr")
+	ret0, _ := ret[0].(net.Addr)
+	return ret0
+}
+
+// LocalAddr indicates an expected call of LocalAddr.
+func (mr *MockQUICConnMockRecorder) LocalAddr() *MockQUICConnLocalAddrCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LocalAddr", reflect.TypeOf((*MockQUICConn)(nil).LocalAddr))
+	return &MockQUICConnLocalAddrCall{Call: call}
+}
+
+// MockQUICConnLocalAddrCall wrap *gomock.Call
+type MockQUICConnLocalAddrCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockQUICConnLocalAddrCall) Return(arg0 net.Addr) *MockQUICConnLocalAddrCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockQUICConnLocalAddrCall) Do(f func() net.Addr) *MockQUICConnLocalAddrCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockQUICConnLocalAddrCall) DoAndReturn(f func() net.Addr) *MockQUICConnLocalAddrCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// MaxDatagramSize mocks base method.
+func (m *MockQUICConn) MaxDatagramSize() int {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MaxDatagramSize")
+	ret0, _ := ret[0].(int)
+	return ret0
+}
+
+// MaxDatagramSize indicates an expected call of MaxDatagramSize.
+func (mr *MockQUICConnMockRecorder) MaxDatagramSize() *MockQUICConnMaxDatagramSizeCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MaxDatagramSize", reflect.TypeOf((*MockQUICConn)(nil).MaxDatagramSize))
+	return &MockQUICConnMaxDatagramSizeCall{Call: call}
+}
+
+// MockQUICConnMaxDatagramSizeCall wrap *gomock.Call
+type MockQUICConnMaxDatagramSizeCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockQUICConnMaxDatagramSizeCall) Return(arg0 int) *MockQUICConnMaxDatagramSizeCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockQUICConnMaxDatagramSizeCall) Do(f func() int) *MockQUICConnMaxDatagramSizeCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockQUICConnMaxDatagramSizeCall) DoAndReturn(f func() int) *MockQUICConnMaxDatagramSizeCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// MigrateTo mocks base method.
+func (m *MockQUICConn) MigrateTo(arg0 net.Addr) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MigrateTo", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MigrateTo indicates an expected call of MigrateTo.
+func (mr *MockQUICConnMockRecorder) MigrateTo(arg0 any) *MockQUICConnMigrateToCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MigrateTo", reflect.TypeOf((*MockQUICConn)(nil).MigrateTo), arg0)
+	return &MockQUICConnMigrateToCall{Call: call}
+}
+
+// MockQUICConnMigrateToCall wrap *gomock.Call
+type MockQUICConnMigrateToCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockQUICConnMigrateToCall) Return(arg0 error) *MockQUICConnMigrateToCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockQUICConnMigrateToCall) Do(f func(net.Addr) error) *MockQUICConnMigrateToCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockQUICConnMigrateToCall) DoAndReturn(f func(net.Addr) error) *MockQUICConnMigrateToCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// MigrationAllowed mocks base method.
+func (m *MockQUICConn) MigrationAllowed() bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MigrationAllowed")
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// MigrationAllowed indicates an expected call of MigrationAllowed.
+func (mr *MockQUICConnMockRecorder) MigrationAllowed() *MockQUICConnMigrationAllowedCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MigrationAllowed", reflect.TypeOf((*MockQUICConn)(nil).MigrationAllowed))
+	return &MockQUICConnMigrationAllowedCall{Call: call}
+}
+
+// MockQUICConnMigrationAllowedCall wrap *gomock.Call
+type MockQUICConnMigrationAllowedCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockQUICConnMigrationAllowedCall) Return(arg0 bool) *MockQUICConnMigrationAllowedCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockQUICConnMigrationAllowedCall) Do(f func() bool) *MockQUICConnMigrationAllowedCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockQUICConnMigrationAllowedCall) DoAndReturn(f func() bool) *MockQUICConnMigrationAllowedCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// NextConnection mocks base method.
+func (m *MockQUICConn) NextConnection(arg0 context.Context) (Connection, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "NextConnection", arg0)
+	ret0, _ := ret[0].(Connection)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// NextConnection indicates an expected call of NextConnection.
+func (mr *MockQUICConnMockRecorder) NextConnection(arg0 any) *MockQUICConnNextConnectionCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NextConnection", reflect.TypeOf((*MockQUICConn)(nil).NextConnection), arg0)
+	return &MockQUICConnNextConnectionCall{Call: call}
+}
+
+// MockQUICConnNextConnectionCall wrap *gomock.Call
+type MockQUICConnNextConnectionCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockQUICConnNextConnectionCall) Return(arg0 Connection, arg1 error) *MockQUICConnNextConnectionCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockQUICConnNextConnectionCall) Do(f func(context.Context) (Connection, error)) *MockQUICConnNextConnectionCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockQUICConnNextConnectionCall) DoAndReturn(f func(context.Context) (Connection, error)) *MockQUICConnNextConnectionCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// OpenStream mocks base method.
+func (m *MockQUICConn) OpenStream() (Stream, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "OpenStream")
+	ret0, _ := ret[0].(Stream)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// OpenStream indicates an expected call of OpenStream.
+func (mr *MockQUICConnMockRecorder) OpenStream() *MockQUICConnOpenStreamCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OpenStream", reflect.TypeOf((*MockQUICConn)(nil).OpenStream))
+	return &MockQUICConnOpenStreamCall{Call: call}
+}
+
+// MockQUICConnOpenStreamCall wrap *gomock.Call
+type MockQUICConnOpenStreamCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockQUICConnOpenStreamCall) Return(arg0 Stream, arg1 error) *MockQUICConnOpenStreamCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockQUICConnOpenStreamCall) Do(f func() (Stream, error)) *MockQUICConnOpenStreamCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockQUICConnOpenStreamCall) DoAndReturn(f func() (Stream, error)) *MockQUICConnOpenStreamCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// OpenStreamSync mocks base method.
+func (m *MockQUICConn) OpenStreamSync(arg0 context.Context) (Stream, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "OpenStreamSync", arg0)
+	ret0, _ := ret[0].(Stream)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// OpenStreamSync indicates an expected call of OpenStreamSync.
+func (mr *MockQUICConnMockRecorder) OpenStreamSync(arg0 any) *MockQUICConnOpenStreamSyncCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OpenStreamSync", reflect.TypeOf((*MockQUICConn)(nil).OpenStreamSync), arg0)
+	return &MockQUICConnOpenStreamSyncCall{Call: call}
+}
+
+// MockQUICConnOpenStreamSyncCall wrap *gomock.Call
+type MockQUICConnOpenStreamSyncCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockQUICConnOpenStreamSyncCall) Return(arg0 Stream, arg1 error) *MockQUICConnOpenStreamSyncCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockQUICConnOpenStreamSyncCall) Do(f func(context.Context) (Stream, error)) *MockQUICConnOpenStreamSyncCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockQUICConnOpenStreamSyncCall) DoAndReturn(f func(context.Context) (Stream, error)) *MockQUICConnOpenStreamSyncCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// OpenUniStream mocks base method.
+func (m *MockQUICConn) OpenUniStream() (SendStream, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "OpenUniStream")
+	ret0, _ := ret[0].(SendStream)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// OpenUniStream indicates an expected call of OpenUniStream.
+func (mr *MockQUICConnMockRecorder) OpenUniStream() *MockQUICConnOpenUniStreamCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OpenUniStream", reflect.TypeOf((*MockQUICConn)(nil).OpenUniStream))
+	return &MockQUICConnOpenUniStreamCall{Call: call}
+}
+
+// MockQUICConnOpenUniStreamCall wrap *gomock.Call
+type MockQUICConnOpenUniStreamCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockQUICConnOpenUniStreamCall) Return(arg0 SendStream, arg1 error) *MockQUICConnOpenUniStreamCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockQUICConnOpenUniStreamCall) Do(f func() (SendStream, error)) *MockQUICConnOpenUniStreamCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockQUICConnOpenUniStreamCall) DoAndReturn(f func() (SendStream, error)) *MockQUICConnOpenUniStreamCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// OpenUniStreamSync mocks base method.
+func (m *MockQUICConn) OpenUniStreamSync(arg0 context.Context) (SendStream, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "OpenUniStreamSync", arg0)
+	ret0, _ := ret[0].(SendStream)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// OpenUniStreamSync indicates an expected call of OpenUniStreamSync.
+func (mr *MockQUICConnMockRecorder) OpenUniStreamSync(arg0 any) *MockQUICConnOpenUniStreamSyncCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OpenUniStreamSync", reflect.TypeOf((*MockQUICConn)(nil).OpenUniStreamSync), arg0)
+	return &MockQUICConnOpenUniStreamSyncCall{Call: call}
+}
+
+// MockQUICConnOpenUniStreamSyncCall wrap *gomock.Call
+type MockQUICConnOpenUniStreamSyncCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockQUICConnOpenUniStreamSyncCall) Return(arg0 SendStream, arg1 error) *MockQUICConnOpenUniStreamSyncCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockQUICConnOpenUniStreamSyncCall) Do(f func(context.Context) (SendStream, error)) *MockQUICConnOpenUniStreamSyncCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockQUICConnOpenUniStreamSyncCall) DoAndReturn(f func(context.Context) (SendStream, error)) *MockQUICConnOpenUniStreamSyncCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// PathStatus mocks base method.
+func (m *MockQUICConn) PathStatus(arg0 PathID) (PathInfo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PathStatus", arg0)
+	ret0, _ := ret[0].(PathInfo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PathStatus indicates an expected call of PathStatus.
+func (mr *MockQUICConnMockRecorder) PathStatus(arg0 any) *MockQUICConnPathStatusCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PathStatus", reflect.TypeOf((*MockQUICConn)(nil).PathStatus), arg0)
+	return &MockQUICConnPathStatusCall{Call: call}
+}
+
+// MockQUICConnPathStatusCall wrap *gomock.Call
+type MockQUICConnPathStatusCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockQUICConnPathStatusCall) Return(arg0 PathInfo, arg1 error) *MockQUICConnPathStatusCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockQUICConnPathStatusCall) Do(f func(PathID) (PathInfo, error)) *MockQUICConnPathStatusCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockQUICConnPathStatusCall) DoAndReturn(f func(PathID) (PathInfo, error)) *MockQUICConnPathStatusCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// PeerPreferredAddress mocks base method.
+func (m *MockQUICConn) PeerPreferredAddress() *PreferredAddress {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PeerPreferredAddress")
+	ret0, _ := ret[0].(*PreferredAddress)
+	return ret0
+}
+
+// PeerPreferredAddress indicates an expected call of PeerPreferredAddress.
+func (mr *MockQUICConnMockRecorder) PeerPreferredAddress() *MockQUICConnPeerPreferredAddressCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PeerPreferredAddress", reflect.TypeOf((*MockQUICConn)(nil).PeerPreferredAddress))
+	return &MockQUICConnPeerPreferredAddressCall{Call: call}
+}
+
+// MockQUICConnPeerPreferredAddressCall wrap *gomock.Call
+type MockQUICConnPeerPreferredAddressCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockQUICConnPeerPreferredAddressCall) Return(arg0 *PreferredAddress) *MockQUICConnPeerPreferredAddressCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockQUICConnPeerPreferredAddressCall) Do(f func() *PreferredAddress) *MockQUICConnPeerPreferredAddressCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockQUICConnPeerPreferredAddressCall) DoAndReturn(f func() *PreferredAddress) *MockQUICConnPeerPreferredAddressCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// PeerTransportParameters mocks base method.
+func (m *MockQUICConn) PeerTransportParameters() *TransportParameters {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PeerTransportParameters")
+	ret0, _ := ret[0].(*TransportParameters)
+	return ret0
+}
+
+// PeerTransportParameters indicates an expected call of PeerTransportParameters.
+func (mr *MockQUICConnMockRecorder) PeerTransportParameters() *MockQUICConnPeerTransportParametersCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PeerTransportParameters", reflect.TypeOf((*MockQUICConn)(nil).PeerTransportParameters))
+	return &MockQUICConnPeerTransportParametersCall{Call: call}
+}
+
+// MockQUICConnPeerTransportParametersCall wrap *gomock.Call
+type MockQUICConnPeerTransportParametersCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockQUICConnPeerTransportParametersCall) Return(arg0 *TransportParameters) *MockQUICConnPeerTransportParametersCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockQUICConnPeerTransportParametersCall) Do(f func() *TransportParameters) *MockQUICConnPeerTransportParametersCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockQUICConnPeerTransportParametersCall) DoAndReturn(f func() *TransportParameters) *MockQUICConnPeerTransportParametersCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// Ping mocks base method.
+func (m *MockQUICConn) Ping(arg0 context.Context) (time.Duration, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Ping", arg0)
+	ret0, _ := ret[0].(time.Duration)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Ping indicates an expected call of Ping.
+func (mr *MockQUICConnMockRecorder) Ping(arg0 any) *MockQUICConnPingCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Ping", reflect.TypeOf((*MockQUICConn)(nil).Ping), arg0)
+	return &MockQUICConnPingCall{Call: call}
+}
+
+// MockQUICConnPingCall wrap *gomock.Call
+type MockQUICConnPingCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockQUICConnPingCall) Return(arg0 time.Duration, arg1 error) *MockQUICConnPingCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockQUICConnPingCall) Do(f func(context.Context) (time.Duration, error)) *MockQUICConnPingCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockQUICConnPingCall) DoAndReturn(f func(context.Context) (time.Duration, error)) *MockQUICConnPingCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// ProbePath mocks base method.
+func (m *MockQUICConn) ProbePath(arg0, arg1 net.Addr) (PathInfo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ProbePath", arg0, arg1)
+	ret0, _ := ret[0].(PathInfo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ProbePath indicates an expected call of ProbePath.
+func (mr *MockQUICConnMockRecorder) ProbePath(arg0, arg1 any) *MockQUICConnProbePathCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ProbePath", reflect.TypeOf((*MockQUICConn)(nil).ProbePath), arg0, arg1)
+	return &MockQUICConnProbePathCall{Call: call}
+}
+
+// MockQUICConnProbePathCall wrap *gomock.Call
+type MockQUICConnProbePathCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockQUICConnProbePathCall) Return(arg0 PathInfo, arg1 error) *MockQUICConnProbePathCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockQUICConnProbePathCall) Do(f func(net.Addr, net.Addr) (PathInfo, error)) *MockQUICConnProbePathCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockQUICConnProbePathCall) DoAndReturn(f func(net.Addr, net.Addr) (PathInfo, error)) *MockQUICConnProbePathCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// ReceiveDatagram mocks base method.
+func (m *MockQUICConn) ReceiveDatagram(arg0 context.Context) ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReceiveDatagram", arg0)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReceiveDatagram indicates an expected call of ReceiveDatagram.
+func (mr *MockQUICConnMockRecorder) ReceiveDatagram(arg0 any) *MockQUICConnReceiveDatagramCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReceiveDatagram", reflect.TypeOf((*MockQUICConn)(nil).ReceiveDatagram), arg0)
+	return &MockQUICConnReceiveDatagramCall{Call: call}
+}
+
+// MockQUICConnReceiveDatagramCall wrap *gomock.Call
+type MockQUICConnReceiveDatagramCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockQUICConnReceiveDatagramCall) Return(arg0 []byte, arg1 error) *MockQUICConnReceiveDatagramCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockQUICConnReceiveDatagramCall) Do(f func(context.Context) ([]byte, error)) *MockQUICConnReceiveDatagramCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockQUICConnReceiveDatagramCall) DoAndReturn(f func(context.Context) ([]byte, error)) *MockQUICConnReceiveDatagramCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// ReceiveDatagramBuffer mocks base method.
+func (m *MockQUICConn) ReceiveDatagramBuffer(arg0 context.Context) (*DatagramBuffer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReceiveDatagramBuffer", arg0)
+	ret0, _ := ret[0].(*DatagramBuffer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReceiveDatagramBuffer indicates an expected call of ReceiveDatagramBuffer.
+func (mr *MockQUICConnMockRecorder) ReceiveDatagramBuffer(arg0 any) *MockQUICConnReceiveDatagramBufferCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReceiveDatagramBuffer", reflect.TypeOf((*MockQUICConn)(nil).ReceiveDatagramBuffer), arg0)
+	return &MockQUICConnReceiveDatagramBufferCall{Call: call}
+}
+
+// MockQUICConnReceiveDatagramBufferCall wrap *gomock.Call
+type MockQUICConnReceiveDatagramBufferCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockQUICConnReceiveDatagramBufferCall) Return(arg0 *DatagramBuffer, arg1 error) *MockQUICConnReceiveDatagramBufferCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockQUICConnReceiveDatagramBufferCall) Do(f func(context.Context) (*DatagramBuffer, error)) *MockQUICConnReceiveDatagramBufferCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockQUICConnReceiveDatagramBufferCall) DoAndReturn(f func(context.Context) (*DatagramBuffer, error)) *MockQUICConnReceiveDatagramBufferCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// ReceiveDatagramWithTime mocks base method.
+func (m *MockQUICConn) ReceiveDatagramWithTime(arg0 context.Context) ([]byte, time.Time, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReceiveDatagramWithTime", arg0)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(time.Time)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ReceiveDatagramWithTime indicates an expected call of ReceiveDatagramWithTime.
+func (mr *MockQUICConnMockRecorder) ReceiveDatagramWithTime(arg0 any) *MockQUICConnReceiveDatagramWithTimeCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReceiveDatagramWithTime", reflect.TypeOf((*MockQUICConn)(nil).ReceiveDatagramWithTime), arg0)
+	return &MockQUICConnReceiveDatagramWithTimeCall{Call: call}
+}
+
+// MockQUICConnReceiveDatagramWithTimeCall wrap *gomock.Call
+type MockQUICConnReceiveDatagramWithTimeCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockQUICConnReceiveDatagramWithTimeCall) Return(arg0 []byte, arg1 time.Time, arg2 error) *MockQUICConnReceiveDatagramWithTimeCall {
+	c.Call = c.Call.Return(arg0, arg1, arg2)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockQUICConnReceiveDatagramWithTimeCall) Do(f func(context.Context) ([]byte, time.Time, error)) *MockQUICConnReceiveDatagramWithTimeCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockQUICConnReceiveDatagramWithTimeCall) DoAndReturn(f func(context.Context) ([]byte, time.Time, error)) *MockQUICConnReceiveDatagramWithTimeCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// ReceiveLargeDatagram mocks base method.
+func (m *MockQUICConn) ReceiveLargeDatagram(arg0 context.Context) ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReceiveLargeDatagram", arg0)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReceiveLargeDatagram indicates an expected call of ReceiveLargeDatagram.
+func (mr *MockQUICConnMockRecorder) ReceiveLargeDatagram(arg0 any) *MockQUICConnReceiveLargeDatagramCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReceiveLargeDatagram", reflect.TypeOf((*MockQUICConn)(nil).ReceiveLargeDatagram), arg0)
+	return &MockQUICConnReceiveLargeDatagramCall{Call: call}
+}
+
+// MockQUICConnReceiveLargeDatagramCall wrap *gomock.Call
+type MockQUICConnReceiveLargeDatagramCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockQUICConnReceiveLargeDatagramCall) Return(arg0 []byte, arg1 error) *MockQUICConnReceiveLargeDatagramCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockQUICConnReceiveLargeDatagramCall) Do(f func(context.Context) ([]byte, error)) *MockQUICConnReceiveLargeDatagramCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockQUICConnReceiveLargeDatagramCall) DoAndReturn(f func(context.Context) ([]byte, error)) *MockQUICConnReceiveLargeDatagramCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// RemoteAddr mocks base method.
+func (m *MockQUICConn) RemoteAddr() net.Addr {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoteAddr")
+	ret0, _ := ret[0].(net.Addr)
+	return ret0
+}
+
+// RemoteAddr indicates an expected call of RemoteAddr.
+func (mr *MockQUICConnMockRecorder) RemoteAddr() *MockQUICConnRemoteAddrCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoteAddr", reflect.TypeOf((*MockQUICConn)(nil).RemoteAddr))
+	return &MockQUICConnRemoteAddrCall{Call: call}
+}
+
+// MockQUICConnRemoteAddrCall wrap *gomock.Call
+type MockQUICConnRemoteAddrCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockQUICConnRemoteAddrCall) Return(arg0 net.Addr) *MockQUICConnRemoteAddrCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockQUICConnRemoteAddrCall) Do(f func() net.Addr) *MockQUICConnRemoteAddrCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockQUICConnRemoteAddrCall) DoAndReturn(f func() net.Addr) *MockQUICConnRemoteAddrCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// RemovePath mocks base method.
+func (m *MockQUICConn) RemovePath(arg0 PathID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemovePath", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemovePath indicates an expected call of RemovePath.
+func (mr *MockQUICConnMockRecorder) RemovePath(arg0 any) *MockQUICConnRemovePathCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemovePath", reflect.TypeOf((*MockQUICConn)(nil).RemovePath), arg0)
+	return &MockQUICConnRemovePathCall{Call: call}
+}
+
+// MockQUICConnRemovePathCall wrap *gomock.Call
+type MockQUICConnRemovePathCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockQUICConnRemovePathCall) Return(arg0 error) *MockQUICConnRemovePathCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockQUICConnRemovePathCall) Do(f func(PathID) error) *MockQUICConnRemovePathCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockQUICConnRemovePathCall) DoAndReturn(f func(PathID) error) *MockQUICConnRemovePathCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// RequestAckFrequency mocks base method.
+func (m *MockQUICConn) RequestAckFrequency(arg0 uint64, arg1 time.Duration) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RequestAckFrequency", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RequestAckFrequency indicates an expected call of RequestAckFrequency.
+func (mr *MockQUICConnMockRecorder) RequestAckFrequency(arg0, arg1 any) *MockQUICConnRequestAckFrequencyCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RequestAckFrequency", reflect.TypeOf((*MockQUICConn)(nil).RequestAckFrequency), arg0, arg1)
+	return &MockQUICConnRequestAckFrequencyCall{Call: call}
+}
+
+// MockQUICConnRequestAckFrequencyCall wrap *gomock.Call
+type MockQUICConnRequestAckFrequencyCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockQUICConnRequestAckFrequencyCall) Return(arg0 error) *MockQUICConnRequestAckFrequencyCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockQUICConnRequestAckFrequencyCall) Do(f func(uint64, time.Duration) error) *MockQUICConnRequestAckFrequencyCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockQUICConnRequestAckFrequencyCall) DoAndReturn(f func(uint64, time.Duration) error) *MockQUICConnRequestAckFrequencyCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// RequestImmediateAck mocks base method.
+func (m *MockQUICConn) RequestImmediateAck() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RequestImmediateAck")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RequestImmediateAck indicates an expected call of RequestImmediateAck.
+func (mr *MockQUICConnMockRecorder) RequestImmediateAck() *MockQUICConnRequestImmediateAckCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RequestImmediateAck", reflect.TypeOf((*MockQUICConn)(nil).RequestImmediateAck))
+	return &MockQUICConnRequestImmediateAckCall{Call: call}
+}
+
+// MockQUICConnRequestImmediateAckCall wrap *gomock.Call
+type MockQUICConnRequestImmediateAckCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockQUICConnRequestImmediateAckCall) Return(arg0 error) *MockQUICConnRequestImmediateAckCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockQUICConnRequestImmediateAckCall) Do(f func() error) *MockQUICConnRequestImmediateAckCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockQUICConnRequestImmediateAckCall) DoAndReturn(f func() error) *MockQUICConnRequestImmediateAckCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// SendCustomFrame mocks base method.
+func (m *MockQUICConn) SendCustomFrame(arg0 uint64, arg1 []byte) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendCustomFrame", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendCustomFrame indicates an expected call of SendCustomFrame.
+func (mr *MockQUICConnMockRecorder) SendCustomFrame(arg0, arg1 any) *MockQUICConnSendCustomFrameCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendCustomFrame", reflect.TypeOf((*MockQUICConn)(nil).SendCustomFrame), arg0, arg1)
+	return &MockQUICConnSendCustomFrameCall{Call: call}
+}
+
+// MockQUICConnSendCustomFrameCall wrap *gomock.Call
+type MockQUICConnSendCustomFrameCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockQUICConnSendCustomFrameCall) Return(arg0 error) *MockQUICConnSendCustomFrameCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockQUICConnSendCustomFrameCall) Do(f func(uint64, []byte) error) *MockQUICConnSendCustomFrameCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockQUICConnSendCustomFrameCall) DoAndReturn(f func(uint64, []byte) error) *MockQUICConnSendCustomFrameCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// SendDatagram mocks base method.
+func (m *MockQUICConn) SendDatagram(arg0 []byte) error {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "SendDatagram", arg0)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// SendDatagram indicates an expected call of SendDatagram.
-func (mr *MockQUICConnMockRecorder) SendDatagram(arg0 any) *MockQUICConnSendDatagramCall {
+// SendDatagram indicates an expected call of SendDatagram.
+func (mr *MockQUICConnMockRecorder) SendDatagram(arg0 any) *MockQUICConnSendDatagramCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendDatagram", reflect.TypeOf((*MockQUICConn)(nil).SendDatagram), arg0)
+	return &MockQUICConnSendDatagramCall{Call: call}
+}
+
+// MockQUICConnSendDatagramCall wrap *gomock.Call
+type MockQUICConnSendDatagramCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockQUICConnSendDatagramCall) Return(arg0 error) *MockQUICConnSendDatagramCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockQUICConnSendDatagramCall) Do(f func([]byte) error) *MockQUICConnSendDatagramCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockQUICConnSendDatagramCall) DoAndReturn(f func([]byte) error) *MockQUICConnSendDatagramCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// SendKeepAlive mocks base method.
+func (m *MockQUICConn) SendKeepAlive() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendKeepAlive")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendKeepAlive indicates an expected call of SendKeepAlive.
+func (mr *MockQUICConnMockRecorder) SendKeepAlive() *MockQUICConnSendKeepAliveCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendKeepAlive", reflect.TypeOf((*MockQUICConn)(nil).SendKeepAlive))
+	return &MockQUICConnSendKeepAliveCall{Call: call}
+}
+
+// MockQUICConnSendKeepAliveCall wrap *gomock.Call
+type MockQUICConnSendKeepAliveCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockQUICConnSendKeepAliveCall) Return(arg0 error) *MockQUICConnSendKeepAliveCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockQUICConnSendKeepAliveCall) Do(f func() error) *MockQUICConnSendKeepAliveCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockQUICConnSendKeepAliveCall) DoAndReturn(f func() error) *MockQUICConnSendKeepAliveCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// SendLargeDatagram mocks base method.
+func (m *MockQUICConn) SendLargeDatagram(arg0 []byte) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendLargeDatagram", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendLargeDatagram indicates an expected call of SendLargeDatagram.
+func (mr *MockQUICConnMockRecorder) SendLargeDatagram(arg0 any) *MockQUICConnSendLargeDatagramCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendLargeDatagram", reflect.TypeOf((*MockQUICConn)(nil).SendLargeDatagram), arg0)
+	return &MockQUICConnSendLargeDatagramCall{Call: call}
+}
+
+// MockQUICConnSendLargeDatagramCall wrap *gomock.Call
+type MockQUICConnSendLargeDatagramCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockQUICConnSendLargeDatagramCall) Return(arg0 error) *MockQUICConnSendLargeDatagramCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockQUICConnSendLargeDatagramCall) Do(f func([]byte) error) *MockQUICConnSendLargeDatagramCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockQUICConnSendLargeDatagramCall) DoAndReturn(f func([]byte) error) *MockQUICConnSendLargeDatagramCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// SendMessage mocks base method.
+func (m *MockQUICConn) SendMessage(arg0 context.Context, arg1 []byte) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendMessage", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendMessage indicates an expected call of SendMessage.
+func (mr *MockQUICConnMockRecorder) SendMessage(arg0, arg1 any) *MockQUICConnSendMessageCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendMessage", reflect.TypeOf((*MockQUICConn)(nil).SendMessage), arg0, arg1)
+	return &MockQUICConnSendMessageCall{Call: call}
+}
+
+// MockQUICConnSendMessageCall wrap *gomock.Call
+type MockQUICConnSendMessageCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockQUICConnSendMessageCall) Return(arg0 error) *MockQUICConnSendMessageCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockQUICConnSendMessageCall) Do(f func(context.Context, []byte) error) *MockQUICConnSendMessageCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockQUICConnSendMessageCall) DoAndReturn(f func(context.Context, []byte) error) *MockQUICConnSendMessageCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// SetDatagramSendTimeout mocks base method.
+func (m *MockQUICConn) SetDatagramSendTimeout(arg0 time.Duration) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetDatagramSendTimeout", arg0)
+}
+
+// SetDatagramSendTimeout indicates an expected call of SetDatagramSendTimeout.
+func (mr *MockQUICConnMockRecorder) SetDatagramSendTimeout(arg0 any) *MockQUICConnSetDatagramSendTimeoutCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetDatagramSendTimeout", reflect.TypeOf((*MockQUICConn)(nil).SetDatagramSendTimeout), arg0)
+	return &MockQUICConnSetDatagramSendTimeoutCall{Call: call}
+}
+
+// MockQUICConnSetDatagramSendTimeoutCall wrap *gomock.Call
+type MockQUICConnSetDatagramSendTimeoutCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockQUICConnSetDatagramSendTimeoutCall) Return() *MockQUICConnSetDatagramSendTimeoutCall {
+	c.Call = c.Call.Return()
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockQUICConnSetDatagramSendTimeoutCall) Do(f func(time.Duration)) *MockQUICConnSetDatagramSendTimeoutCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockQUICConnSetDatagramSendTimeoutCall) DoAndReturn(f func(time.Duration)) *MockQUICConnSetDatagramSendTimeoutCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// SetMaxIncomingStreams mocks base method.
+func (m *MockQUICConn) SetMaxIncomingStreams(arg0 int64) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetMaxIncomingStreams", arg0)
+}
+
+// SetMaxIncomingStreams indicates an expected call of SetMaxIncomingStreams.
+func (mr *MockQUICConnMockRecorder) SetMaxIncomingStreams(arg0 any) *MockQUICConnSetMaxIncomingStreamsCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetMaxIncomingStreams", reflect.TypeOf((*MockQUICConn)(nil).SetMaxIncomingStreams), arg0)
+	return &MockQUICConnSetMaxIncomingStreamsCall{Call: call}
+}
+
+// MockQUICConnSetMaxIncomingStreamsCall wrap *gomock.Call
+type MockQUICConnSetMaxIncomingStreamsCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockQUICConnSetMaxIncomingStreamsCall) Return() *MockQUICConnSetMaxIncomingStreamsCall {
+	c.Call = c.Call.Return()
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockQUICConnSetMaxIncomingStreamsCall) Do(f func(int64)) *MockQUICConnSetMaxIncomingStreamsCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockQUICConnSetMaxIncomingStreamsCall) DoAndReturn(f func(int64)) *MockQUICConnSetMaxIncomingStreamsCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// SetMaxIncomingUniStreams mocks base method.
+func (m *MockQUICConn) SetMaxIncomingUniStreams(arg0 int64) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetMaxIncomingUniStreams", arg0)
+}
+
+// SetMaxIncomingUniStreams indicates an expected call of SetMaxIncomingUniStreams.
+func (mr *MockQUICConnMockRecorder) SetMaxIncomingUniStreams(arg0 any) *MockQUICConnSetMaxIncomingUniStreamsCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetMaxIncomingUniStreams", reflect.TypeOf((*MockQUICConn)(nil).SetMaxIncomingUniStreams), arg0)
+	return &MockQUICConnSetMaxIncomingUniStreamsCall{Call: call}
+}
+
+// MockQUICConnSetMaxIncomingUniStreamsCall wrap *gomock.Call
+type MockQUICConnSetMaxIncomingUniStreamsCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockQUICConnSetMaxIncomingUniStreamsCall) Return() *MockQUICConnSetMaxIncomingUniStreamsCall {
+	c.Call = c.Call.Return()
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockQUICConnSetMaxIncomingUniStreamsCall) Do(f func(int64)) *MockQUICConnSetMaxIncomingUniStreamsCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockQUICConnSetMaxIncomingUniStreamsCall) DoAndReturn(f func(int64)) *MockQUICConnSetMaxIncomingUniStreamsCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// SetReceiveWindow mocks base method.
+func (m *MockQUICConn) SetReceiveWindow(arg0 protocol.ByteCount) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetReceiveWindow", arg0)
+}
+
+// SetReceiveWindow indicates an expected call of SetReceiveWindow.
+func (mr *MockQUICConnMockRecorder) SetReceiveWindow(arg0 any) *MockQUICConnSetReceiveWindowCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetReceiveWindow", reflect.TypeOf((*MockQUICConn)(nil).SetReceiveWindow), arg0)
+	return &MockQUICConnSetReceiveWindowCall{Call: call}
+}
+
+// MockQUICConnSetReceiveWindowCall wrap *gomock.Call
+type MockQUICConnSetReceiveWindowCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockQUICConnSetReceiveWindowCall) Return() *MockQUICConnSetReceiveWindowCall {
+	c.Call = c.Call.Return()
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockQUICConnSetReceiveWindowCall) Do(f func(protocol.ByteCount)) *MockQUICConnSetReceiveWindowCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockQUICConnSetReceiveWindowCall) DoAndReturn(f func(protocol.ByteCount)) *MockQUICConnSetReceiveWindowCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// SpinBit mocks base method.
+func (m *MockQUICConn) SpinBit() byte {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SpinBit")
+	ret0, _ := ret[0].(byte)
+	return ret0
+}
+
+// SpinBit indicates an expected call of SpinBit.
+func (mr *MockQUICConnMockRecorder) SpinBit() *MockQUICConnSpinBitCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SpinBit", reflect.TypeOf((*MockQUICConn)(nil).SpinBit))
+	return &MockQUICConnSpinBitCall{Call: call}
+}
+
+// MockQUICConnSpinBitCall wrap *gomock.Call
+type MockQUICConnSpinBitCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockQUICConnSpinBitCall) Return(arg0 byte) *MockQUICConnSpinBitCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockQUICConnSpinBitCall) Do(f func() byte) *MockQUICConnSpinBitCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockQUICConnSpinBitCall) DoAndReturn(f func() byte) *MockQUICConnSpinBitCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// StatelessResetToken mocks base method.
+func (m *MockQUICConn) StatelessResetToken() (protocol.StatelessResetToken, bool) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StatelessResetToken")
+	ret0, _ := ret[0].(protocol.StatelessResetToken)
+	ret1, _ := ret[1].(bool)
+	return ret0, ret1
+}
+
+// StatelessResetToken indicates an expected call of StatelessResetToken.
+func (mr *MockQUICConnMockRecorder) StatelessResetToken() *MockQUICConnStatelessResetTokenCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StatelessResetToken", reflect.TypeOf((*MockQUICConn)(nil).StatelessResetToken))
+	return &MockQUICConnStatelessResetTokenCall{Call: call}
+}
+
+// MockQUICConnStatelessResetTokenCall wrap *gomock.Call
+type MockQUICConnStatelessResetTokenCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockQUICConnStatelessResetTokenCall) Return(arg0 protocol.StatelessResetToken, arg1 bool) *MockQUICConnStatelessResetTokenCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockQUICConnStatelessResetTokenCall) Do(f func() (protocol.StatelessResetToken, bool)) *MockQUICConnStatelessResetTokenCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockQUICConnStatelessResetTokenCall) DoAndReturn(f func() (protocol.StatelessResetToken, bool)) *MockQUICConnStatelessResetTokenCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// Stats mocks base method.
+func (m *MockQUICConn) Stats() ConnectionStats {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Stats")
+	ret0, _ := ret[0].(ConnectionStats)
+	return ret0
+}
+
+// Stats indicates an expected call of Stats.
+func (mr *MockQUICConnMockRecorder) Stats() *MockQUICConnStatsCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Stats", reflect.TypeOf((*MockQUICConn)(nil).Stats))
+	return &MockQUICConnStatsCall{Call: call}
+}
+
+// MockQUICConnStatsCall wrap *gomock.Call
+type MockQUICConnStatsCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockQUICConnStatsCall) Return(arg0 ConnectionStats) *MockQUICConnStatsCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockQUICConnStatsCall) Do(f func() ConnectionStats) *MockQUICConnStatsCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockQUICConnStatsCall) DoAndReturn(f func() ConnectionStats) *MockQUICConnStatsCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// StreamsAvailable mocks base method.
+func (m *MockQUICConn) StreamsAvailable() (int64, int64) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StreamsAvailable")
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(int64)
+	return ret0, ret1
+}
+
+// StreamsAvailable indicates an expected call of StreamsAvailable.
+func (mr *MockQUICConnMockRecorder) StreamsAvailable() *MockQUICConnStreamsAvailableCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StreamsAvailable", reflect.TypeOf((*MockQUICConn)(nil).StreamsAvailable))
+	return &MockQUICConnStreamsAvailableCall{Call: call}
+}
+
+// MockQUICConnStreamsAvailableCall wrap *gomock.Call
+type MockQUICConnStreamsAvailableCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockQUICConnStreamsAvailableCall) Return(arg0, arg1 int64) *MockQUICConnStreamsAvailableCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockQUICConnStreamsAvailableCall) Do(f func() (int64, int64)) *MockQUICConnStreamsAvailableCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockQUICConnStreamsAvailableCall) DoAndReturn(f func() (int64, int64)) *MockQUICConnStreamsAvailableCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// SwitchToPath mocks base method.
+func (m *MockQUICConn) SwitchToPath(arg0 PathID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SwitchToPath", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SwitchToPath indicates an expected call of SwitchToPath.
+func (mr *MockQUICConnMockRecorder) SwitchToPath(arg0 any) *MockQUICConnSwitchToPathCall {
 	mr.mock.ctrl.T.Helper()
-	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendDatagram", reflect.TypeOf((*MockQUICConn)(nil).SendDatagram), arg0)
-	return &MockQUICConnSendDatagramCall{Call: call}
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SwitchToPath", reflect.TypeOf((*MockQUICConn)(nil).SwitchToPath), arg0)
+	return &MockQUICConnSwitchToPathCall{Call: call}
 }
 
-// MockQUICConnSendDatagramCall wrap *gomock.Call
-type MockQUICConnSendDatagramCall struct {
+// MockQUICConnSwitchToPathCall wrap *gomock.Call
+type MockQUICConnSwitchToPathCall struct {
 	*gomock.Call
 }
 
 // Return rewrite *gomock.Call.Return
-func (c *MockQUICConnSendDatagramCall) Return(arg0 error) *MockQUICConnSendDatagramCall {
+func (c *MockQUICConnSwitchToPathCall) Return(arg0 error) *MockQUICConnSwitchToPathCall {
 	c.Call = c.Call.Return(arg0)
 	return c
 }
 
 // Do rewrite *gomock.Call.Do
-func (c *MockQUICConnSendDatagramCall) Do(f func([]byte) error) *MockQUICConnSendDatagramCall {
+func (c *MockQUICConnSwitchToPathCall) Do(f func(PathID) error) *MockQUICConnSwitchToPathCall {
 	c.Call = c.Call.Do(f)
 	return c
 }
 
 // DoAndReturn rewrite *gomock.Call.DoAndReturn
-func (c *MockQUICConnSendDatagramCall) DoAndReturn(f func([]byte) error) *MockQUICConnSendDatagramCall {
+func (c *MockQUICConnSwitchToPathCall) DoAndReturn(f func(PathID) error) *MockQUICConnSwitchToPathCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// TryReceiveDatagram mocks base method.
+func (m *MockQUICConn) TryReceiveDatagram() ([]byte, bool) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TryReceiveDatagram")
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(bool)
+	return ret0, ret1
+}
+
+// TryReceiveDatagram indicates an expected call of TryReceiveDatagram.
+func (mr *MockQUICConnMockRecorder) TryReceiveDatagram() *MockQUICConnTryReceiveDatagramCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TryReceiveDatagram", reflect.TypeOf((*MockQUICConn)(nil).TryReceiveDatagram))
+	return &MockQUICConnTryReceiveDatagramCall{Call: call}
+}
+
+// MockQUICConnTryReceiveDatagramCall wrap *gomock.Call
+type MockQUICConnTryReceiveDatagramCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockQUICConnTryReceiveDatagramCall) Return(arg0 []byte, arg1 bool) *MockQUICConnTryReceiveDatagramCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockQUICConnTryReceiveDatagramCall) Do(f func() ([]byte, bool)) *MockQUICConnTryReceiveDatagramCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockQUICConnTryReceiveDatagramCall) DoAndReturn(f func() ([]byte, bool)) *MockQUICConnTryReceiveDatagramCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// Used0RTT mocks base method.
+func (m *MockQUICConn) Used0RTT() bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Used0RTT")
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// Used0RTT indicates an expected call of Used0RTT.
+func (mr *MockQUICConnMockRecorder) Used0RTT() *MockQUICConnUsed0RTTCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Used0RTT", reflect.TypeOf((*MockQUICConn)(nil).Used0RTT))
+	return &MockQUICConnUsed0RTTCall{Call: call}
+}
+
+// MockQUICConnUsed0RTTCall wrap *gomock.Call
+type MockQUICConnUsed0RTTCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockQUICConnUsed0RTTCall) Return(arg0 bool) *MockQUICConnUsed0RTTCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockQUICConnUsed0RTTCall) Do(f func() bool) *MockQUICConnUsed0RTTCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockQUICConnUsed0RTTCall) DoAndReturn(f func() bool) *MockQUICConnUsed0RTTCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// acceptsGreasedQUICBit mocks base method.
+func (m *MockQUICConn) acceptsGreasedQUICBit() bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "acceptsGreasedQUICBit")
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// acceptsGreasedQUICBit indicates an expected call of acceptsGreasedQUICBit.
+func (mr *MockQUICConnMockRecorder) acceptsGreasedQUICBit() *MockQUICConnacceptsGreasedQUICBitCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "acceptsGreasedQUICBit", reflect.TypeOf((*MockQUICConn)(nil).acceptsGreasedQUICBit))
+	return &MockQUICConnacceptsGreasedQUICBitCall{Call: call}
+}
+
+// MockQUICConnacceptsGreasedQUICBitCall wrap *gomock.Call
+type MockQUICConnacceptsGreasedQUICBitCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockQUICConnacceptsGreasedQUICBitCall) Return(arg0 bool) *MockQUICConnacceptsGreasedQUICBitCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockQUICConnacceptsGreasedQUICBitCall) Do(f func() bool) *MockQUICConnacceptsGreasedQUICBitCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockQUICConnacceptsGreasedQUICBitCall) DoAndReturn(f func() bool) *MockQUICConnacceptsGreasedQUICBitCall {
 	c.Call = c.Call.DoAndReturn(f)
 	return c
 }
@@ -117,6 +117,42 @@ func (c *MockReceiveStreamIReadCall) DoAndReturn(f func([]byte) (int, error)) *M
 	return c
 }
 
+// SetDrainOnReset mocks base method.
+func (m *MockReceiveStreamI) SetDrainOnReset(arg0 bool) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetDrainOnReset", arg0)
+}
+
+// SetDrainOnReset indicates an expected call of SetDrainOnReset.
+func (mr *MockReceiveStreamIMockRecorder) SetDrainOnReset(arg0 any) *MockReceiveStreamISetDrainOnResetCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetDrainOnReset", reflect.TypeOf((*MockReceiveStreamI)(nil).SetDrainOnReset), arg0)
+	return &MockReceiveStreamISetDrainOnResetCall{Call: call}
+}
+
+// MockReceiveStreamISetDrainOnResetCall wrap *gomock.Call
+type MockReceiveStreamISetDrainOnResetCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockReceiveStreamISetDrainOnResetCall) Return() *MockReceiveStreamISetDrainOnResetCall {
+	c.Call = c.Call.Return()
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockReceiveStreamISetDrainOnResetCall) Do(f func(bool)) *MockReceiveStreamISetDrainOnResetCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockReceiveStreamISetDrainOnResetCall) DoAndReturn(f func(bool)) *MockReceiveStreamISetDrainOnResetCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // SetReadDeadline mocks base method.
 func (m *MockReceiveStreamI) SetReadDeadline(arg0 time.Time) error {
 	m.ctrl.T.Helper()
@@ -155,6 +191,78 @@ func (c *MockReceiveStreamISetReadDeadlineCall) DoAndReturn(f func(time.Time) er
 	return c
 }
 
+// SetReceiveWindow mocks base method.
+func (m *MockReceiveStreamI) SetReceiveWindow(arg0 protocol.ByteCount) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetReceiveWindow", arg0)
+}
+
+// SetReceiveWindow indicates an expected call of SetReceiveWindow.
+func (mr *MockReceiveStreamIMockRecorder) SetReceiveWindow(arg0 any) *MockReceiveStreamISetReceiveWindowCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetReceiveWindow", reflect.TypeOf((*MockReceiveStreamI)(nil).SetReceiveWindow), arg0)
+	return &MockReceiveStreamISetReceiveWindowCall{Call: call}
+}
+
+// MockReceiveStreamISetReceiveWindowCall wrap *gomock.Call
+type MockReceiveStreamISetReceiveWindowCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockReceiveStreamISetReceiveWindowCall) Return() *MockReceiveStreamISetReceiveWindowCall {
+	c.Call = c.Call.Return()
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockReceiveStreamISetReceiveWindowCall) Do(f func(protocol.ByteCount)) *MockReceiveStreamISetReceiveWindowCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockReceiveStreamISetReceiveWindowCall) DoAndReturn(f func(protocol.ByteCount)) *MockReceiveStreamISetReceiveWindowCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// StopSending mocks base method.
+func (m *MockReceiveStreamI) StopSending(arg0 qerr.StreamErrorCode) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "StopSending", arg0)
+}
+
+// StopSending indicates an expected call of StopSending.
+func (mr *MockReceiveStreamIMockRecorder) StopSending(arg0 any) *MockReceiveStreamIStopSendingCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StopSending", reflect.TypeOf((*MockReceiveStreamI)(nil).StopSending), arg0)
+	return &MockReceiveStreamIStopSendingCall{Call: call}
+}
+
+// MockReceiveStreamIStopSendingCall wrap *gomock.Call
+type MockReceiveStreamIStopSendingCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockReceiveStreamIStopSendingCall) Return() *MockReceiveStreamIStopSendingCall {
+	c.Call = c.Call.Return()
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockReceiveStreamIStopSendingCall) Do(f func(qerr.StreamErrorCode)) *MockReceiveStreamIStopSendingCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockReceiveStreamIStopSendingCall) DoAndReturn(f func(qerr.StreamErrorCode)) *MockReceiveStreamIStopSendingCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // StreamID mocks base method.
 func (m *MockReceiveStreamI) StreamID() protocol.StreamID {
 	m.ctrl.T.Helper()
@@ -267,6 +375,44 @@ func (c *MockReceiveStreamIgetWindowUpdateCall) DoAndReturn(f func() protocol.By
 	return c
 }
 
+// handleResetStreamAtFrame mocks base method.
+func (m *MockReceiveStreamI) handleResetStreamAtFrame(arg0 *wire.ResetStreamAtFrame) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "handleResetStreamAtFrame", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// handleResetStreamAtFrame indicates an expected call of handleResetStreamAtFrame.
+func (mr *MockReceiveStreamIMockRecorder) handleResetStreamAtFrame(arg0 any) *MockReceiveStreamIhandleResetStreamAtFrameCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "handleResetStreamAtFrame", reflect.TypeOf((*MockReceiveStreamI)(nil).handleResetStreamAtFrame), arg0)
+	return &MockReceiveStreamIhandleResetStreamAtFrameCall{Call: call}
+}
+
+// MockReceiveStreamIhandleResetStreamAtFrameCall wrap *gomock.Call
+type MockReceiveStreamIhandleResetStreamAtFrameCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockReceiveStreamIhandleResetStreamAtFrameCall) Return(arg0 error) *MockReceiveStreamIhandleResetStreamAtFrameCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockReceiveStreamIhandleResetStreamAtFrameCall) Do(f func(*wire.ResetStreamAtFrame) error) *MockReceiveStreamIhandleResetStreamAtFrameCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockReceiveStreamIhandleResetStreamAtFrameCall) DoAndReturn(f func(*wire.ResetStreamAtFrame) error) *MockReceiveStreamIhandleResetStreamAtFrameCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // handleResetStreamFrame mocks base method.
 func (m *MockReceiveStreamI) handleResetStreamFrame(arg0 *wire.ResetStreamFrame) error {
 	m.ctrl.T.Helper()
@@ -229,3 +229,41 @@ func (c *MockSendConncapabilitiesCall) DoAndReturn(f func() connCapabilities) *M
 	c.Call = c.Call.DoAndReturn(f)
 	return c
 }
+
+// withRemoteAddr mocks base method.
+func (m *MockSendConn) withRemoteAddr(arg0 net.Addr, arg1 packetInfo) sendConn {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "withRemoteAddr", arg0, arg1)
+	ret0, _ := ret[0].(sendConn)
+	return ret0
+}
+
+// withRemoteAddr indicates an expected call of withRemoteAddr.
+func (mr *MockSendConnMockRecorder) withRemoteAddr(arg0, arg1 any) *MockSendConnwithRemoteAddrCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "withRemoteAddr", reflect.TypeOf((*MockSendConn)(nil).withRemoteAddr), arg0, arg1)
+	return &MockSendConnwithRemoteAddrCall{Call: call}
+}
+
+// MockSendConnwithRemoteAddrCall wrap *gomock.Call
+type MockSendConnwithRemoteAddrCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockSendConnwithRemoteAddrCall) Return(arg0 sendConn) *MockSendConnwithRemoteAddrCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockSendConnwithRemoteAddrCall) Do(f func(net.Addr, packetInfo) sendConn) *MockSendConnwithRemoteAddrCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockSendConnwithRemoteAddrCall) DoAndReturn(f func(net.Addr, packetInfo) sendConn) *MockSendConnwithRemoteAddrCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
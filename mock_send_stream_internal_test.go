@@ -44,6 +44,44 @@ func (m *MockSendStreamI) EXPECT() *MockSendStreamIMockRecorder {
 	return m.recorder
 }
 
+// BytesAcked mocks base method.
+func (m *MockSendStreamI) BytesAcked() protocol.ByteCount {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BytesAcked")
+	ret0, _ := ret[0].(protocol.ByteCount)
+	return ret0
+}
+
+// BytesAcked indicates an expected call of BytesAcked.
+func (mr *MockSendStreamIMockRecorder) BytesAcked() *MockSendStreamIBytesAckedCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BytesAcked", reflect.TypeOf((*MockSendStreamI)(nil).BytesAcked))
+	return &MockSendStreamIBytesAckedCall{Call: call}
+}
+
+// MockSendStreamIBytesAckedCall wrap *gomock.Call
+type MockSendStreamIBytesAckedCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockSendStreamIBytesAckedCall) Return(arg0 protocol.ByteCount) *MockSendStreamIBytesAckedCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockSendStreamIBytesAckedCall) Do(f func() protocol.ByteCount) *MockSendStreamIBytesAckedCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockSendStreamIBytesAckedCall) DoAndReturn(f func() protocol.ByteCount) *MockSendStreamIBytesAckedCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // CancelWrite mocks base method.
 func (m *MockSendStreamI) CancelWrite(arg0 qerr.StreamErrorCode) {
 	m.ctrl.T.Helper()
@@ -118,6 +156,44 @@ func (c *MockSendStreamICloseCall) DoAndReturn(f func() error) *MockSendStreamIC
 	return c
 }
 
+// CloseThenReset mocks base method.
+func (m *MockSendStreamI) CloseThenReset(arg0 qerr.StreamErrorCode) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CloseThenReset", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CloseThenReset indicates an expected call of CloseThenReset.
+func (mr *MockSendStreamIMockRecorder) CloseThenReset(arg0 any) *MockSendStreamICloseThenResetCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CloseThenReset", reflect.TypeOf((*MockSendStreamI)(nil).CloseThenReset), arg0)
+	return &MockSendStreamICloseThenResetCall{Call: call}
+}
+
+// MockSendStreamICloseThenResetCall wrap *gomock.Call
+type MockSendStreamICloseThenResetCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockSendStreamICloseThenResetCall) Return(arg0 error) *MockSendStreamICloseThenResetCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockSendStreamICloseThenResetCall) Do(f func(qerr.StreamErrorCode) error) *MockSendStreamICloseThenResetCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockSendStreamICloseThenResetCall) DoAndReturn(f func(qerr.StreamErrorCode) error) *MockSendStreamICloseThenResetCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // Context mocks base method.
 func (m *MockSendStreamI) Context() context.Context {
 	m.ctrl.T.Helper()
@@ -156,6 +232,153 @@ func (c *MockSendStreamIContextCall) DoAndReturn(f func() context.Context) *Mock
 	return c
 }
 
+// ResetAt mocks base method.
+func (m *MockSendStreamI) ResetAt(arg0 qerr.StreamErrorCode, arg1 protocol.ByteCount) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "ResetAt", arg0, arg1)
+}
+
+// ResetAt indicates an expected call of ResetAt.
+func (mr *MockSendStreamIMockRecorder) ResetAt(arg0, arg1 any) *MockSendStreamIResetAtCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResetAt", reflect.TypeOf((*MockSendStreamI)(nil).ResetAt), arg0, arg1)
+	return &MockSendStreamIResetAtCall{Call: call}
+}
+
+// MockSendStreamIResetAtCall wrap *gomock.Call
+type MockSendStreamIResetAtCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockSendStreamIResetAtCall) Return() *MockSendStreamIResetAtCall {
+	c.Call = c.Call.Return()
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockSendStreamIResetAtCall) Do(f func(qerr.StreamErrorCode, protocol.ByteCount)) *MockSendStreamIResetAtCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockSendStreamIResetAtCall) DoAndReturn(f func(qerr.StreamErrorCode, protocol.ByteCount)) *MockSendStreamIResetAtCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// SafeWrite mocks base method.
+func (m *MockSendStreamI) SafeWrite(arg0 []byte) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SafeWrite", arg0)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SafeWrite indicates an expected call of SafeWrite.
+func (mr *MockSendStreamIMockRecorder) SafeWrite(arg0 any) *MockSendStreamISafeWriteCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SafeWrite", reflect.TypeOf((*MockSendStreamI)(nil).SafeWrite), arg0)
+	return &MockSendStreamISafeWriteCall{Call: call}
+}
+
+// MockSendStreamISafeWriteCall wrap *gomock.Call
+type MockSendStreamISafeWriteCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockSendStreamISafeWriteCall) Return(arg0 int, arg1 error) *MockSendStreamISafeWriteCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockSendStreamISafeWriteCall) Do(f func([]byte) (int, error)) *MockSendStreamISafeWriteCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockSendStreamISafeWriteCall) DoAndReturn(f func([]byte) (int, error)) *MockSendStreamISafeWriteCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// SetPriority mocks base method.
+func (m *MockSendStreamI) SetPriority(arg0 StreamPriority) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetPriority", arg0)
+}
+
+// SetPriority indicates an expected call of SetPriority.
+func (mr *MockSendStreamIMockRecorder) SetPriority(arg0 any) *MockSendStreamISetPriorityCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetPriority", reflect.TypeOf((*MockSendStreamI)(nil).SetPriority), arg0)
+	return &MockSendStreamISetPriorityCall{Call: call}
+}
+
+// MockSendStreamISetPriorityCall wrap *gomock.Call
+type MockSendStreamISetPriorityCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockSendStreamISetPriorityCall) Return() *MockSendStreamISetPriorityCall {
+	c.Call = c.Call.Return()
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockSendStreamISetPriorityCall) Do(f func(StreamPriority)) *MockSendStreamISetPriorityCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockSendStreamISetPriorityCall) DoAndReturn(f func(StreamPriority)) *MockSendStreamISetPriorityCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// SetWriteBufferLimit mocks base method.
+func (m *MockSendStreamI) SetWriteBufferLimit(arg0 protocol.ByteCount) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetWriteBufferLimit", arg0)
+}
+
+// SetWriteBufferLimit indicates an expected call of SetWriteBufferLimit.
+func (mr *MockSendStreamIMockRecorder) SetWriteBufferLimit(arg0 any) *MockSendStreamISetWriteBufferLimitCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetWriteBufferLimit", reflect.TypeOf((*MockSendStreamI)(nil).SetWriteBufferLimit), arg0)
+	return &MockSendStreamISetWriteBufferLimitCall{Call: call}
+}
+
+// MockSendStreamISetWriteBufferLimitCall wrap *gomock.Call
+type MockSendStreamISetWriteBufferLimitCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockSendStreamISetWriteBufferLimitCall) Return() *MockSendStreamISetWriteBufferLimitCall {
+	c.Call = c.Call.Return()
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockSendStreamISetWriteBufferLimitCall) Do(f func(protocol.ByteCount)) *MockSendStreamISetWriteBufferLimitCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockSendStreamISetWriteBufferLimitCall) DoAndReturn(f func(protocol.ByteCount)) *MockSendStreamISetWriteBufferLimitCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // SetWriteDeadline mocks base method.
 func (m *MockSendStreamI) SetWriteDeadline(arg0 time.Time) error {
 	m.ctrl.T.Helper()
@@ -232,6 +455,44 @@ func (c *MockSendStreamIStreamIDCall) DoAndReturn(f func() protocol.StreamID) *M
 	return c
 }
 
+// WaitForAck mocks base method.
+func (m *MockSendStreamI) WaitForAck(arg0 context.Context, arg1 protocol.ByteCount) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WaitForAck", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// WaitForAck indicates an expected call of WaitForAck.
+func (mr *MockSendStreamIMockRecorder) WaitForAck(arg0, arg1 any) *MockSendStreamIWaitForAckCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WaitForAck", reflect.TypeOf((*MockSendStreamI)(nil).WaitForAck), arg0, arg1)
+	return &MockSendStreamIWaitForAckCall{Call: call}
+}
+
+// MockSendStreamIWaitForAckCall wrap *gomock.Call
+type MockSendStreamIWaitForAckCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockSendStreamIWaitForAckCall) Return(arg0 error) *MockSendStreamIWaitForAckCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockSendStreamIWaitForAckCall) Do(f func(context.Context, protocol.ByteCount) error) *MockSendStreamIWaitForAckCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockSendStreamIWaitForAckCall) DoAndReturn(f func(context.Context, protocol.ByteCount) error) *MockSendStreamIWaitForAckCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // Write mocks base method.
 func (m *MockSendStreamI) Write(arg0 []byte) (int, error) {
 	m.ctrl.T.Helper()
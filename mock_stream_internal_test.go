@@ -44,6 +44,44 @@ func (m *MockStreamI) EXPECT() *MockStreamIMockRecorder {
 	return m.recorder
 }
 
+// BytesAcked mocks base method.
+func (m *MockStreamI) BytesAcked() protocol.ByteCount {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BytesAcked")
+	ret0, _ := ret[0].(protocol.ByteCount)
+	return ret0
+}
+
+// BytesAcked indicates an expected call of BytesAcked.
+func (mr *MockStreamIMockRecorder) BytesAcked() *MockStreamIBytesAckedCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BytesAcked", reflect.TypeOf((*MockStreamI)(nil).BytesAcked))
+	return &MockStreamIBytesAckedCall{Call: call}
+}
+
+// MockStreamIBytesAckedCall wrap *gomock.Call
+type MockStreamIBytesAckedCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockStreamIBytesAckedCall) Return(arg0 protocol.ByteCount) *MockStreamIBytesAckedCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockStreamIBytesAckedCall) Do(f func() protocol.ByteCount) *MockStreamIBytesAckedCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockStreamIBytesAckedCall) DoAndReturn(f func() protocol.ByteCount) *MockStreamIBytesAckedCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // CancelRead mocks base method.
 func (m *MockStreamI) CancelRead(arg0 qerr.StreamErrorCode) {
 	m.ctrl.T.Helper()
@@ -154,6 +192,116 @@ func (c *MockStreamICloseCall) DoAndReturn(f func() error) *MockStreamICloseCall
 	return c
 }
 
+// CloseRead mocks base method.
+func (m *MockStreamI) CloseRead(arg0 qerr.StreamErrorCode) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "CloseRead", arg0)
+}
+
+// CloseRead indicates an expected call of CloseRead.
+func (mr *MockStreamIMockRecorder) CloseRead(arg0 any) *MockStreamICloseReadCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CloseRead", reflect.TypeOf((*MockStreamI)(nil).CloseRead), arg0)
+	return &MockStreamICloseReadCall{Call: call}
+}
+
+// MockStreamICloseReadCall wrap *gomock.Call
+type MockStreamICloseReadCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockStreamICloseReadCall) Return() *MockStreamICloseReadCall {
+	c.Call = c.Call.Return()
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockStreamICloseReadCall) Do(f func(qerr.StreamErrorCode)) *MockStreamICloseReadCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockStreamICloseReadCall) DoAndReturn(f func(qerr.StreamErrorCode)) *MockStreamICloseReadCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// CloseThenReset mocks base method.
+func (m *MockStreamI) CloseThenReset(arg0 qerr.StreamErrorCode) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CloseThenReset", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CloseThenReset indicates an expected call of CloseThenReset.
+func (mr *MockStreamIMockRecorder) CloseThenReset(arg0 any) *MockStreamICloseThenResetCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CloseThenReset", reflect.TypeOf((*MockStreamI)(nil).CloseThenReset), arg0)
+	return &MockStreamICloseThenResetCall{Call: call}
+}
+
+// MockStreamICloseThenResetCall wrap *gomock.Call
+type MockStreamICloseThenResetCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockStreamICloseThenResetCall) Return(arg0 error) *MockStreamICloseThenResetCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockStreamICloseThenResetCall) Do(f func(qerr.StreamErrorCode) error) *MockStreamICloseThenResetCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockStreamICloseThenResetCall) DoAndReturn(f func(qerr.StreamErrorCode) error) *MockStreamICloseThenResetCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// CloseWrite mocks base method.
+func (m *MockStreamI) CloseWrite() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "CloseWrite")
+}
+
+// CloseWrite indicates an expected call of CloseWrite.
+func (mr *MockStreamIMockRecorder) CloseWrite() *MockStreamICloseWriteCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CloseWrite", reflect.TypeOf((*MockStreamI)(nil).CloseWrite))
+	return &MockStreamICloseWriteCall{Call: call}
+}
+
+// MockStreamICloseWriteCall wrap *gomock.Call
+type MockStreamICloseWriteCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockStreamICloseWriteCall) Return() *MockStreamICloseWriteCall {
+	c.Call = c.Call.Return()
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockStreamICloseWriteCall) Do(f func()) *MockStreamICloseWriteCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockStreamICloseWriteCall) DoAndReturn(f func()) *MockStreamICloseWriteCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // Context mocks base method.
 func (m *MockStreamI) Context() context.Context {
 	m.ctrl.T.Helper()
@@ -231,6 +379,81 @@ func (c *MockStreamIReadCall) DoAndReturn(f func([]byte) (int, error)) *MockStre
 	return c
 }
 
+// ResetAt mocks base method.
+func (m *MockStreamI) ResetAt(arg0 qerr.StreamErrorCode, arg1 protocol.ByteCount) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "ResetAt", arg0, arg1)
+}
+
+// ResetAt indicates an expected call of ResetAt.
+func (mr *MockStreamIMockRecorder) ResetAt(arg0, arg1 any) *MockStreamIResetAtCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResetAt", reflect.TypeOf((*MockStreamI)(nil).ResetAt), arg0, arg1)
+	return &MockStreamIResetAtCall{Call: call}
+}
+
+// MockStreamIResetAtCall wrap *gomock.Call
+type MockStreamIResetAtCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockStreamIResetAtCall) Return() *MockStreamIResetAtCall {
+	c.Call = c.Call.Return()
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockStreamIResetAtCall) Do(f func(qerr.StreamErrorCode, protocol.ByteCount)) *MockStreamIResetAtCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockStreamIResetAtCall) DoAndReturn(f func(qerr.StreamErrorCode, protocol.ByteCount)) *MockStreamIResetAtCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// SafeWrite mocks base method.
+func (m *MockStreamI) SafeWrite(arg0 []byte) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SafeWrite", arg0)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SafeWrite indicates an expected call of SafeWrite.
+func (mr *MockStreamIMockRecorder) SafeWrite(arg0 any) *MockStreamISafeWriteCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SafeWrite", reflect.TypeOf((*MockStreamI)(nil).SafeWrite), arg0)
+	return &MockStreamISafeWriteCall{Call: call}
+}
+
+// MockStreamISafeWriteCall wrap *gomock.Call
+type MockStreamISafeWriteCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockStreamISafeWriteCall) Return(arg0 int, arg1 error) *MockStreamISafeWriteCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockStreamISafeWriteCall) Do(f func([]byte) (int, error)) *MockStreamISafeWriteCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockStreamISafeWriteCall) DoAndReturn(f func([]byte) (int, error)) *MockStreamISafeWriteCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // SetDeadline mocks base method.
 func (m *MockStreamI) SetDeadline(arg0 time.Time) error {
 	m.ctrl.T.Helper()
@@ -269,6 +492,78 @@ func (c *MockStreamISetDeadlineCall) DoAndReturn(f func(time.Time) error) *MockS
 	return c
 }
 
+// SetDrainOnReset mocks base method.
+func (m *MockStreamI) SetDrainOnReset(arg0 bool) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetDrainOnReset", arg0)
+}
+
+// SetDrainOnReset indicates an expected call of SetDrainOnReset.
+func (mr *MockStreamIMockRecorder) SetDrainOnReset(arg0 any) *MockStreamISetDrainOnResetCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetDrainOnReset", reflect.TypeOf((*MockStreamI)(nil).SetDrainOnReset), arg0)
+	return &MockStreamISetDrainOnResetCall{Call: call}
+}
+
+// MockStreamISetDrainOnResetCall wrap *gomock.Call
+type MockStreamISetDrainOnResetCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockStreamISetDrainOnResetCall) Return() *MockStreamISetDrainOnResetCall {
+	c.Call = c.Call.Return()
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockStreamISetDrainOnResetCall) Do(f func(bool)) *MockStreamISetDrainOnResetCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockStreamISetDrainOnResetCall) DoAndReturn(f func(bool)) *MockStreamISetDrainOnResetCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// SetPriority mocks base method.
+func (m *MockStreamI) SetPriority(arg0 StreamPriority) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetPriority", arg0)
+}
+
+// SetPriority indicates an expected call of SetPriority.
+func (mr *MockStreamIMockRecorder) SetPriority(arg0 any) *MockStreamISetPriorityCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetPriority", reflect.TypeOf((*MockStreamI)(nil).SetPriority), arg0)
+	return &MockStreamISetPriorityCall{Call: call}
+}
+
+// MockStreamISetPriorityCall wrap *gomock.Call
+type MockStreamISetPriorityCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockStreamISetPriorityCall) Return() *MockStreamISetPriorityCall {
+	c.Call = c.Call.Return()
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockStreamISetPriorityCall) Do(f func(StreamPriority)) *MockStreamISetPriorityCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockStreamISetPriorityCall) DoAndReturn(f func(StreamPriority)) *MockStreamISetPriorityCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // SetReadDeadline mocks base method.
 func (m *MockStreamI) SetReadDeadline(arg0 time.Time) error {
 	m.ctrl.T.Helper()
@@ -307,6 +602,78 @@ func (c *MockStreamISetReadDeadlineCall) DoAndReturn(f func(time.Time) error) *M
 	return c
 }
 
+// SetReceiveWindow mocks base method.
+func (m *MockStreamI) SetReceiveWindow(arg0 protocol.ByteCount) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetReceiveWindow", arg0)
+}
+
+// SetReceiveWindow indicates an expected call of SetReceiveWindow.
+func (mr *MockStreamIMockRecorder) SetReceiveWindow(arg0 any) *MockStreamISetReceiveWindowCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetReceiveWindow", reflect.TypeOf((*MockStreamI)(nil).SetReceiveWindow), arg0)
+	return &MockStreamISetReceiveWindowCall{Call: call}
+}
+
+// MockStreamISetReceiveWindowCall wrap *gomock.Call
+type MockStreamISetReceiveWindowCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockStreamISetReceiveWindowCall) Return() *MockStreamISetReceiveWindowCall {
+	c.Call = c.Call.Return()
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockStreamISetReceiveWindowCall) Do(f func(protocol.ByteCount)) *MockStreamISetReceiveWindowCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockStreamISetReceiveWindowCall) DoAndReturn(f func(protocol.ByteCount)) *MockStreamISetReceiveWindowCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// SetWriteBufferLimit mocks base method.
+func (m *MockStreamI) SetWriteBufferLimit(arg0 protocol.ByteCount) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetWriteBufferLimit", arg0)
+}
+
+// SetWriteBufferLimit indicates an expected call of SetWriteBufferLimit.
+func (mr *MockStreamIMockRecorder) SetWriteBufferLimit(arg0 any) *MockStreamISetWriteBufferLimitCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetWriteBufferLimit", reflect.TypeOf((*MockStreamI)(nil).SetWriteBufferLimit), arg0)
+	return &MockStreamISetWriteBufferLimitCall{Call: call}
+}
+
+// MockStreamISetWriteBufferLimitCall wrap *gomock.Call
+type MockStreamISetWriteBufferLimitCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockStreamISetWriteBufferLimitCall) Return() *MockStreamISetWriteBufferLimitCall {
+	c.Call = c.Call.Return()
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockStreamISetWriteBufferLimitCall) Do(f func(protocol.ByteCount)) *MockStreamISetWriteBufferLimitCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockStreamISetWriteBufferLimitCall) DoAndReturn(f func(protocol.ByteCount)) *MockStreamISetWriteBufferLimitCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // SetWriteDeadline mocks base method.
 func (m *MockStreamI) SetWriteDeadline(arg0 time.Time) error {
 	m.ctrl.T.Helper()
@@ -345,6 +712,42 @@ func (c *MockStreamISetWriteDeadlineCall) DoAndReturn(f func(time.Time) error) *
 	return c
 }
 
+// StopSending mocks base method.
+func (m *MockStreamI) StopSending(arg0 qerr.StreamErrorCode) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "StopSending", arg0)
+}
+
+// StopSending indicates an expected call of StopSending.
+func (mr *MockStreamIMockRecorder) StopSending(arg0 any) *MockStreamIStopSendingCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StopSending", reflect.TypeOf((*MockStreamI)(nil).StopSending), arg0)
+	return &MockStreamIStopSendingCall{Call: call}
+}
+
+// MockStreamIStopSendingCall wrap *gomock.Call
+type MockStreamIStopSendingCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockStreamIStopSendingCall) Return() *MockStreamIStopSendingCall {
+	c.Call = c.Call.Return()
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockStreamIStopSendingCall) Do(f func(qerr.StreamErrorCode)) *MockStreamIStopSendingCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockStreamIStopSendingCall) DoAndReturn(f func(qerr.StreamErrorCode)) *MockStreamIStopSendingCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // StreamID mocks base method.
 func (m *MockStreamI) StreamID() protocol.StreamID {
 	m.ctrl.T.Helper()
@@ -383,6 +786,44 @@ func (c *MockStreamIStreamIDCall) DoAndReturn(f func() protocol.StreamID) *MockS
 	return c
 }
 
+// WaitForAck mocks base method.
+func (m *MockStreamI) WaitForAck(arg0 context.Context, arg1 protocol.ByteCount) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WaitForAck", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// WaitForAck indicates an expected call of WaitForAck.
+func (mr *MockStreamIMockRecorder) WaitForAck(arg0, arg1 any) *MockStreamIWaitForAckCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WaitForAck", reflect.TypeOf((*MockStreamI)(nil).WaitForAck), arg0, arg1)
+	return &MockStreamIWaitForAckCall{Call: call}
+}
+
+// MockStreamIWaitForAckCall wrap *gomock.Call
+type MockStreamIWaitForAckCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockStreamIWaitForAckCall) Return(arg0 error) *MockStreamIWaitForAckCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockStreamIWaitForAckCall) Do(f func(context.Context, protocol.ByteCount) error) *MockStreamIWaitForAckCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockStreamIWaitForAckCall) DoAndReturn(f func(context.Context, protocol.ByteCount) error) *MockStreamIWaitForAckCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // Write mocks base method.
 func (m *MockStreamI) Write(arg0 []byte) (int, error) {
 	m.ctrl.T.Helper()
@@ -496,6 +937,44 @@ func (c *MockStreamIgetWindowUpdateCall) DoAndReturn(f func() protocol.ByteCount
 	return c
 }
 
+// handleResetStreamAtFrame mocks base method.
+func (m *MockStreamI) handleResetStreamAtFrame(arg0 *wire.ResetStreamAtFrame) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "handleResetStreamAtFrame", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// handleResetStreamAtFrame indicates an expected call of handleResetStreamAtFrame.
+func (mr *MockStreamIMockRecorder) handleResetStreamAtFrame(arg0 any) *MockStreamIhandleResetStreamAtFrameCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "handleResetStreamAtFrame", reflect.TypeOf((*MockStreamI)(nil).handleResetStreamAtFrame), arg0)
+	return &MockStreamIhandleResetStreamAtFrameCall{Call: call}
+}
+
+// MockStreamIhandleResetStreamAtFrameCall wrap *gomock.Call
+type MockStreamIhandleResetStreamAtFrameCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockStreamIhandleResetStreamAtFrameCall) Return(arg0 error) *MockStreamIhandleResetStreamAtFrameCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockStreamIhandleResetStreamAtFrameCall) Do(f func(*wire.ResetStreamAtFrame) error) *MockStreamIhandleResetStreamAtFrameCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockStreamIhandleResetStreamAtFrameCall) DoAndReturn(f func(*wire.ResetStreamAtFrame) error) *MockStreamIhandleResetStreamAtFrameCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // handleResetStreamFrame mocks base method.
 func (m *MockStreamI) handleResetStreamFrame(arg0 *wire.ResetStreamFrame) error {
 	m.ctrl.T.Helper()
@@ -119,6 +119,44 @@ func (c *MockStreamManagerAcceptUniStreamCall) DoAndReturn(f func(context.Contex
 	return c
 }
 
+// ActiveStreams mocks base method.
+func (m *MockStreamManager) ActiveStreams() []protocol.StreamID {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ActiveStreams")
+	ret0, _ := ret[0].([]protocol.StreamID)
+	return ret0
+}
+
+// ActiveStreams indicates an expected call of ActiveStreams.
+func (mr *MockStreamManagerMockRecorder) ActiveStreams() *MockStreamManagerActiveStreamsCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ActiveStreams", reflect.TypeOf((*MockStreamManager)(nil).ActiveStreams))
+	return &MockStreamManagerActiveStreamsCall{Call: call}
+}
+
+// MockStreamManagerActiveStreamsCall wrap *gomock.Call
+type MockStreamManagerActiveStreamsCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockStreamManagerActiveStreamsCall) Return(arg0 []protocol.StreamID) *MockStreamManagerActiveStreamsCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockStreamManagerActiveStreamsCall) Do(f func() []protocol.StreamID) *MockStreamManagerActiveStreamsCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockStreamManagerActiveStreamsCall) DoAndReturn(f func() []protocol.StreamID) *MockStreamManagerActiveStreamsCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // CloseWithError mocks base method.
 func (m *MockStreamManager) CloseWithError(arg0 error) {
 	m.ctrl.T.Helper()
@@ -464,15 +502,15 @@ func (c *MockStreamManagerOpenUniStreamSyncCall) DoAndReturn(f func(context.Cont
 }
 
 // ResetFor0RTT mocks base method.
-func (m *MockStreamManager) ResetFor0RTT() {
+func (m *MockStreamManager) ResetFor0RTT(arg0 error) {
 	m.ctrl.T.Helper()
-	m.ctrl.Call(m, "ResetFor0RTT")
+	m.ctrl.Call(m, "ResetFor0RTT", arg0)
 }
 
 // ResetFor0RTT indicates an expected call of ResetFor0RTT.
-func (mr *MockStreamManagerMockRecorder) ResetFor0RTT() *MockStreamManagerResetFor0RTTCall {
+func (mr *MockStreamManagerMockRecorder) ResetFor0RTT(arg0 any) *MockStreamManagerResetFor0RTTCall {
 	mr.mock.ctrl.T.Helper()
-	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResetFor0RTT", reflect.TypeOf((*MockStreamManager)(nil).ResetFor0RTT))
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResetFor0RTT", reflect.TypeOf((*MockStreamManager)(nil).ResetFor0RTT), arg0)
 	return &MockStreamManagerResetFor0RTTCall{Call: call}
 }
 
@@ -488,13 +526,160 @@ func (c *MockStreamManagerResetFor0RTTCall) Return() *MockStreamManagerResetFor0
 }
 
 // Do rewrite *gomock.Call.Do
-func (c *MockStreamManagerResetFor0RTTCall) Do(f func()) *MockStreamManagerResetFor0RTTCall {
+func (c *MockStreamManagerResetFor0RTTCall) Do(f func(error)) *MockStreamManagerResetFor0RTTCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockStreamManagerResetFor0RTTCall) DoAndReturn(f func(error)) *MockStreamManagerResetFor0RTTCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// SetInitialMaxIncomingStreams mocks base method.
+func (m *MockStreamManager) SetInitialMaxIncomingStreams(arg0, arg1 uint64) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetInitialMaxIncomingStreams", arg0, arg1)
+}
+
+// SetInitialMaxIncomingStreams indicates an expected call of SetInitialMaxIncomingStreams.
+func (mr *MockStreamManagerMockRecorder) SetInitialMaxIncomingStreams(arg0, arg1 any) *MockStreamManagerSetInitialMaxIncomingStreamsCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetInitialMaxIncomingStreams", reflect.TypeOf((*MockStreamManager)(nil).SetInitialMaxIncomingStreams), arg0, arg1)
+	return &MockStreamManagerSetInitialMaxIncomingStreamsCall{Call: call}
+}
+
+// MockStreamManagerSetInitialMaxIncomingStreamsCall wrap *gomock.Call
+type MockStreamManagerSetInitialMaxIncomingStreamsCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockStreamManagerSetInitialMaxIncomingStreamsCall) Return() *MockStreamManagerSetInitialMaxIncomingStreamsCall {
+	c.Call = c.Call.Return()
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockStreamManagerSetInitialMaxIncomingStreamsCall) Do(f func(uint64, uint64)) *MockStreamManagerSetInitialMaxIncomingStreamsCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockStreamManagerSetInitialMaxIncomingStreamsCall) DoAndReturn(f func(uint64, uint64)) *MockStreamManagerSetInitialMaxIncomingStreamsCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// SetMaxIncomingStreams mocks base method.
+func (m *MockStreamManager) SetMaxIncomingStreams(arg0 uint64) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetMaxIncomingStreams", arg0)
+}
+
+// SetMaxIncomingStreams indicates an expected call of SetMaxIncomingStreams.
+func (mr *MockStreamManagerMockRecorder) SetMaxIncomingStreams(arg0 any) *MockStreamManagerSetMaxIncomingStreamsCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetMaxIncomingStreams", reflect.TypeOf((*MockStreamManager)(nil).SetMaxIncomingStreams), arg0)
+	return &MockStreamManagerSetMaxIncomingStreamsCall{Call: call}
+}
+
+// MockStreamManagerSetMaxIncomingStreamsCall wrap *gomock.Call
+type MockStreamManagerSetMaxIncomingStreamsCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockStreamManagerSetMaxIncomingStreamsCall) Return() *MockStreamManagerSetMaxIncomingStreamsCall {
+	c.Call = c.Call.Return()
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockStreamManagerSetMaxIncomingStreamsCall) Do(f func(uint64)) *MockStreamManagerSetMaxIncomingStreamsCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockStreamManagerSetMaxIncomingStreamsCall) DoAndReturn(f func(uint64)) *MockStreamManagerSetMaxIncomingStreamsCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// SetMaxIncomingUniStreams mocks base method.
+func (m *MockStreamManager) SetMaxIncomingUniStreams(arg0 uint64) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetMaxIncomingUniStreams", arg0)
+}
+
+// SetMaxIncomingUniStreams indicates an expected call of SetMaxIncomingUniStreams.
+func (mr *MockStreamManagerMockRecorder) SetMaxIncomingUniStreams(arg0 any) *MockStreamManagerSetMaxIncomingUniStreamsCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetMaxIncomingUniStreams", reflect.TypeOf((*MockStreamManager)(nil).SetMaxIncomingUniStreams), arg0)
+	return &MockStreamManagerSetMaxIncomingUniStreamsCall{Call: call}
+}
+
+// MockStreamManagerSetMaxIncomingUniStreamsCall wrap *gomock.Call
+type MockStreamManagerSetMaxIncomingUniStreamsCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockStreamManagerSetMaxIncomingUniStreamsCall) Return() *MockStreamManagerSetMaxIncomingUniStreamsCall {
+	c.Call = c.Call.Return()
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockStreamManagerSetMaxIncomingUniStreamsCall) Do(f func(uint64)) *MockStreamManagerSetMaxIncomingUniStreamsCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockStreamManagerSetMaxIncomingUniStreamsCall) DoAndReturn(f func(uint64)) *MockStreamManagerSetMaxIncomingUniStreamsCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// StreamsAvailable mocks base method.
+func (m *MockStreamManager) StreamsAvailable() (int64, int64) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StreamsAvailable")
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(int64)
+	return ret0, ret1
+}
+
+// StreamsAvailable indicates an expected call of StreamsAvailable.
+func (mr *MockStreamManagerMockRecorder) StreamsAvailable() *MockStreamManagerStreamsAvailableCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StreamsAvailable", reflect.TypeOf((*MockStreamManager)(nil).StreamsAvailable))
+	return &MockStreamManagerStreamsAvailableCall{Call: call}
+}
+
+// MockStreamManagerStreamsAvailableCall wrap *gomock.Call
+type MockStreamManagerStreamsAvailableCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockStreamManagerStreamsAvailableCall) Return(arg0, arg1 int64) *MockStreamManagerStreamsAvailableCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockStreamManagerStreamsAvailableCall) Do(f func() (int64, int64)) *MockStreamManagerStreamsAvailableCall {
 	c.Call = c.Call.Do(f)
 	return c
 }
 
 // DoAndReturn rewrite *gomock.Call.DoAndReturn
-func (c *MockStreamManagerResetFor0RTTCall) DoAndReturn(f func()) *MockStreamManagerResetFor0RTTCall {
+func (c *MockStreamManagerStreamsAvailableCall) DoAndReturn(f func() (int64, int64)) *MockStreamManagerStreamsAvailableCall {
 	c.Call = c.Call.DoAndReturn(f)
 	return c
 }
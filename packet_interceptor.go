@@ -0,0 +1,71 @@
+package quic
+
+import (
+	"net"
+	"time"
+
+	"github.com/quic-go/quic-go/internal/protocol"
+)
+
+// A PacketAction determines what happens to a packet intercepted by a PacketInterceptor.
+type PacketAction uint8
+
+const (
+	// PacketActionSend sends the packet.
+	PacketActionSend PacketAction = iota
+	// PacketActionDrop drops the packet.
+	PacketActionDrop
+	// PacketActionDelay sends the packet after a delay.
+	// Delaying some packets, but not others, can be used to reorder packets.
+	PacketActionDelay
+)
+
+// A PacketInterceptor intercepts the packets sent by a Transport.
+// It is intended for testing, e.g. for injecting packet loss and reordering into real connections.
+// It should not be used in production.
+// InterceptPacket is called synchronously when a packet is written, and must not block:
+// this would hold up the sending of all other packets.
+type PacketInterceptor interface {
+	// InterceptPacket is called for every UDP datagram sent, including packets that don't belong to any
+	// connection (e.g. stateless resets and Version Negotiation packets).
+	// The datagram may contain multiple coalesced QUIC packets.
+	// The data must not be modified, and must not be retained after the call returns.
+	// The delay is only used for PacketActionDelay.
+	InterceptPacket(data []byte, remoteAddr net.Addr) (action PacketAction, delay time.Duration)
+}
+
+// interceptingConn passes every packet written to the PacketInterceptor.
+// It disables GSO, such that every call to WritePacket writes a single UDP datagram.
+type interceptingConn struct {
+	rawConn
+	interceptor PacketInterceptor
+}
+
+var _ rawConn = &interceptingConn{}
+
+func newInterceptingConn(c rawConn, interceptor PacketInterceptor) *interceptingConn {
+	return &interceptingConn{rawConn: c, interceptor: interceptor}
+}
+
+func (c *interceptingConn) WritePacket(b []byte, addr net.Addr, packetInfoOOB []byte, gsoSize uint16, ecn protocol.ECN) (int, error) {
+	action, delay := c.interceptor.InterceptPacket(b, addr)
+	switch action {
+	case PacketActionDrop:
+		return len(b), nil
+	case PacketActionDelay:
+		data := make([]byte, len(b))
+		copy(data, b)
+		oob := make([]byte, len(packetInfoOOB))
+		copy(oob, packetInfoOOB)
+		time.AfterFunc(delay, func() { c.rawConn.WritePacket(data, addr, oob, 0, ecn) })
+		return len(b), nil
+	default:
+		return c.rawConn.WritePacket(b, addr, packetInfoOOB, 0, ecn)
+	}
+}
+
+func (c *interceptingConn) capabilities() connCapabilities {
+	caps := c.rawConn.capabilities()
+	caps.GSO = false
+	return caps
+}
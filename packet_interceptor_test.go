@@ -0,0 +1,106 @@
+package quic
+
+import (
+	"net"
+	"time"
+
+	"github.com/quic-go/quic-go/internal/protocol"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type packetInterceptorFunc func([]byte, net.Addr) (PacketAction, time.Duration)
+
+func (f packetInterceptorFunc) InterceptPacket(b []byte, addr net.Addr) (PacketAction, time.Duration) {
+	return f(b, addr)
+}
+
+var _ = Describe("Packet Interceptor", func() {
+	remoteAddr := &net.UDPAddr{IP: net.IPv4(192, 168, 100, 200), Port: 1337}
+
+	newUDPConnLocalhost := func() *net.UDPConn {
+		conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 0})
+		Expect(err).ToNot(HaveOccurred())
+		return conn
+	}
+
+	It("disables GSO", func() {
+		rawConn := NewMockRawConn(mockCtrl)
+		rawConn.EXPECT().capabilities().Return(connCapabilities{DF: true, GSO: true, ECN: true})
+		c := newInterceptingConn(rawConn, packetInterceptorFunc(func([]byte, net.Addr) (PacketAction, time.Duration) {
+			return PacketActionSend, 0
+		}))
+		Expect(c.capabilities()).To(Equal(connCapabilities{DF: true, ECN: true}))
+	})
+
+	It("sends, drops and delays packets", func() {
+		rawConn := NewMockRawConn(mockCtrl)
+		c := newInterceptingConn(rawConn, packetInterceptorFunc(func(b []byte, addr net.Addr) (PacketAction, time.Duration) {
+			Expect(addr).To(Equal(remoteAddr))
+			switch string(b) {
+			case "drop":
+				return PacketActionDrop, 0
+			case "delay":
+				return PacketActionDelay, scaleDuration(25 * time.Millisecond)
+			default:
+				return PacketActionSend, 0
+			}
+		}))
+
+		rawConn.EXPECT().WritePacket([]byte("send"), remoteAddr, []byte("oob"), uint16(0), protocol.ECT1).Return(4, nil)
+		n, err := c.WritePacket([]byte("send"), remoteAddr, []byte("oob"), 0, protocol.ECT1)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(n).To(Equal(4))
+
+		n, err = c.WritePacket([]byte("drop"), remoteAddr, nil, 0, protocol.ECNNon)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(n).To(Equal(4))
+
+		written := make(chan struct{})
+		rawConn.EXPECT().WritePacket([]byte("delay"), remoteAddr, []byte("oob"), uint16(0), protocol.ECT0).DoAndReturn(
+			func([]byte, net.Addr, []byte, uint16, protocol.ECN) (int, error) {
+				close(written)
+				return 5, nil
+			},
+		)
+		b := []byte("delay")
+		oob := []byte("oob")
+		start := time.Now()
+		n, err = c.WritePacket(b, remoteAddr, oob, 0, protocol.ECT0)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(n).To(Equal(5))
+		// the packet buffer can be reused after WritePacket returns
+		copy(b, "xxxxx")
+		copy(oob, "xxx")
+		Eventually(written).Should(BeClosed())
+		Expect(time.Since(start)).To(BeNumerically(">=", scaleDuration(25*time.Millisecond)))
+	})
+
+	It("is used by the Transport", func() {
+		var intercepted []net.Addr
+		tr := &Transport{
+			Conn: newUDPConnLocalhost(),
+			PacketInterceptor: packetInterceptorFunc(func(b []byte, addr net.Addr) (PacketAction, time.Duration) {
+				intercepted = append(intercepted, addr)
+				return PacketActionDrop, 0
+			}),
+		}
+		defer tr.Close()
+		Expect(tr.init(true)).To(Succeed())
+		_, ok := tr.conn.(*interceptingConn)
+		Expect(ok).To(BeTrue())
+		Expect(tr.conn.capabilities().GSO).To(BeFalse())
+		_, err := tr.WriteTo([]byte("foobar"), remoteAddr)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(intercepted).To(Equal([]net.Addr{remoteAddr}))
+	})
+
+	It("doesn't intercept packets if not set", func() {
+		tr := &Transport{Conn: newUDPConnLocalhost()}
+		defer tr.Close()
+		Expect(tr.init(true)).To(Succeed())
+		_, ok := tr.conn.(*interceptingConn)
+		Expect(ok).To(BeFalse())
+	})
+})
@@ -5,6 +5,7 @@ import (
 	"encoding/binary"
 	"errors"
 	"fmt"
+	"unicode/utf8"
 
 	"golang.org/x/exp/rand"
 
@@ -25,8 +26,11 @@ type packer interface {
 	PackConnectionClose(*qerr.TransportError, protocol.ByteCount, protocol.Version) (*coalescedPacket, error)
 	PackApplicationClose(*qerr.ApplicationError, protocol.ByteCount, protocol.Version) (*coalescedPacket, error)
 	PackMTUProbePacket(ping ackhandler.Frame, size protocol.ByteCount, v protocol.Version) (shortHeaderPacket, *packetBuffer, error)
+	PackPathProbePacket(connID protocol.ConnectionID, challenge ackhandler.Frame, maxSize protocol.ByteCount, v protocol.Version) (shortHeaderPacket, *packetBuffer, error)
 
 	SetToken([]byte)
+	EnableQUICBitGreasing()
+	SetSpinBit(bool)
 }
 
 type sealer interface {
@@ -126,6 +130,9 @@ type packetPacker struct {
 
 	token []byte
 
+	greaseQUICBit bool
+	spinBit       bool
+
 	pnManager           packetNumberManager
 	framer              frameSource
 	acks                ackFrameSource
@@ -179,6 +186,19 @@ func (p *packetPacker) PackConnectionClose(e *qerr.TransportError, maxPacketSize
 	return p.packConnectionClose(false, uint64(e.ErrorCode), e.FrameType, reason, maxPacketSize, v)
 }
 
+// truncateReasonPhrase truncates the reason phrase to protocol.MaxReasonPhraseLength.
+// If the reason phrase is valid UTF-8, it is only cut at a character boundary.
+func truncateReasonPhrase(reason string) string {
+	if len(reason) <= protocol.MaxReasonPhraseLength {
+		return reason
+	}
+	n := protocol.MaxReasonPhraseLength
+	for i := 0; i < utf8.UTFMax-1 && !utf8.RuneStart(reason[n]); i++ {
+		n--
+	}
+	return reason[:n]
+}
+
 // PackApplicationClose packs a packet that closes the connection with an application error.
 func (p *packetPacker) PackApplicationClose(e *qerr.ApplicationError, maxPacketSize protocol.ByteCount, v protocol.Version) (*coalescedPacket, error) {
 	return p.packConnectionClose(true, uint64(e.ErrorCode), 0, e.ErrorMessage, maxPacketSize, v)
@@ -192,6 +212,7 @@ func (p *packetPacker) packConnectionClose(
 	maxPacketSize protocol.ByteCount,
 	v protocol.Version,
 ) (*coalescedPacket, error) {
+	reason = truncateReasonPhrase(reason)
 	var sealers [4]sealer
 	var hdrs [3]*wire.ExtendedHeader
 	var payloads [4]payload
@@ -606,14 +627,14 @@ func (p *packetPacker) composeNextPacket(maxFrameSize protocol.ByteCount, onlyAc
 		if f := p.datagramQueue.Peek(); f != nil {
 			size := f.Length(v)
 			if size <= maxFrameSize-pl.length { // DATAGRAM frame fits
-				pl.frames = append(pl.frames, ackhandler.Frame{Frame: f})
+				pl.frames = append(pl.frames, ackhandler.Frame{Frame: f, Handler: p.datagramQueue.AckHandler()})
 				pl.length += size
 				p.datagramQueue.Pop()
 			} else if !hasAck {
 				// The DATAGRAM frame doesn't fit, and the packet doesn't contain an ACK.
 				// Discard this frame. There's no point in retrying this in the next packet,
 				// as it's unlikely that the available packet size will increase.
-				p.datagramQueue.Pop()
+				p.datagramQueue.Discard(&DatagramTooLargeError{MaxDatagramPayloadSize: int64(f.MaxDataLen(maxFrameSize, v))})
 			}
 			// If the DATAGRAM frame was too large and the packet contained an ACK, we'll try to send it out later.
 		}
@@ -645,6 +666,10 @@ func (p *packetPacker) composeNextPacket(maxFrameSize protocol.ByteCount, onlyAc
 		pl.length += lengthAdded
 		// add handlers for the control frames that were added
 		for i := startLen; i < len(pl.frames); i++ {
+			if pl.frames[i].Handler != nil {
+				// the handler was set when the frame was queued
+				continue
+			}
 			switch pl.frames[i].Frame.(type) {
 			case *wire.PathChallengeFrame, *wire.PathResponseFrame:
 				// Path probing is currently not supported, therefore we don't need to set the OnAcked callback yet.
@@ -744,6 +769,31 @@ func (p *packetPacker) PackMTUProbePacket(ping ackhandler.Frame, size protocol.B
 	return packet, buffer, err
 }
 
+// PackPathProbePacket packs a packet probing a new path, using the connection ID for that path.
+// The packet is padded to 1200 bytes, as required for path validation (see section 8.2.1 of RFC 9000),
+// unless the anti-amplification limit only allows sending a smaller packet (maxSize).
+// If the packet doesn't fit into maxSize, errNothingToPack is returned.
+func (p *packetPacker) PackPathProbePacket(connID protocol.ConnectionID, challenge ackhandler.Frame, maxSize protocol.ByteCount, v protocol.Version) (shortHeaderPacket, *packetBuffer, error) {
+	pl := payload{
+		frames: []ackhandler.Frame{challenge},
+		length: challenge.Frame.Length(v),
+	}
+	s, err := p.cryptoSetup.Get1RTTSealer()
+	if err != nil {
+		return shortHeaderPacket{}, nil, err
+	}
+	pn, pnLen := p.pnManager.PeekPacketNumber(protocol.Encryption1RTT)
+	size := min(maxSize, protocol.MinInitialPacketSize)
+	length := p.shortHeaderPacketLength(connID, pnLen, pl) + protocol.ByteCount(s.Overhead())
+	if length > size {
+		return shortHeaderPacket{}, nil, errNothingToPack
+	}
+	buffer := getPacketBuffer()
+	kp := s.KeyPhase()
+	packet, err := p.appendShortHeaderPacket(buffer, connID, pn, pnLen, kp, pl, size-length, size, s, false, v)
+	return packet, buffer, err
+}
+
 func (p *packetPacker) getLongHeader(encLevel protocol.EncryptionLevel, v protocol.Version) *wire.ExtendedHeader {
 	pn, pnLen := p.pnManager.PeekPacketNumber(encLevel)
 	hdr := &wire.ExtendedHeader{
@@ -827,6 +877,12 @@ func (p *packetPacker) appendShortHeaderPacket(
 	if err != nil {
 		return shortHeaderPacket{}, err
 	}
+	if p.greaseQUICBit && p.rand.Uint32()%2 == 0 {
+		raw[0] &^= 0x40
+	}
+	if p.spinBit {
+		raw[0] |= 0x20
+	}
 	payloadOffset := protocol.ByteCount(len(raw))
 
 	raw, err = p.appendPacketPayload(raw, pl, paddingLen, v)
@@ -909,3 +965,13 @@ func (p *packetPacker) encryptPacket(raw []byte, sealer sealer, pn protocol.Pack
 func (p *packetPacker) SetToken(token []byte) {
 	p.token = token
 }
+
+// SetSpinBit sets the value of the latency spin bit on short header packets.
+func (p *packetPacker) SetSpinBit(spin bool) {
+	p.spinBit = spin
+}
+
+// EnableQUICBitGreasing enables greasing of the QUIC bit on short header packets (RFC 9287).
+func (p *packetPacker) EnableQUICBitGreasing() {
+	p.greaseQUICBit = true
+}
@@ -4,7 +4,9 @@ import (
 	"bytes"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
+	"unicode/utf8"
 
 	"golang.org/x/exp/rand"
 
@@ -94,7 +96,7 @@ var _ = Describe("Packet packer", func() {
 		ackFramer = NewMockAckFrameSource(mockCtrl)
 		sealingManager = NewMockSealingManager(mockCtrl)
 		pnManager = mockackhandler.NewMockSentPacketHandler(mockCtrl)
-		datagramQueue = newDatagramQueue(func() {}, utils.DefaultLogger)
+		datagramQueue = newDatagramQueue(func() {}, maxDatagramSendQueueLen, 0, 0, nil, false, false, utils.DefaultClock{}, utils.DefaultLogger)
 
 		packer = newPacketPacker(protocol.ParseConnectionID([]byte{1, 2, 3, 4, 5, 6, 7, 8}), func() protocol.ConnectionID { return connID }, initialStream, handshakeStream, pnManager, retransmissionQueue, sealingManager, framer, ackFramer, datagramQueue, protocol.PerspectiveServer)
 	})
@@ -352,6 +354,41 @@ var _ = Describe("Packet packer", func() {
 				Expect(ccf.ReasonPhrase).To(Equal("test error"))
 			})
 
+			It("truncates long reason phrases", func() {
+				pnManager.EXPECT().PeekPacketNumber(protocol.Encryption1RTT).Return(protocol.PacketNumber(0x42), protocol.PacketNumberLen2)
+				pnManager.EXPECT().PopPacketNumber(protocol.Encryption1RTT).Return(protocol.PacketNumber(0x42))
+				sealingManager.EXPECT().GetInitialSealer().Return(nil, handshake.ErrKeysDropped)
+				sealingManager.EXPECT().GetHandshakeSealer().Return(nil, handshake.ErrKeysDropped)
+				sealingManager.EXPECT().Get1RTTSealer().Return(getSealer(), nil)
+				reason := strings.Repeat("a", 1000)
+				p, err := packer.PackApplicationClose(&qerr.ApplicationError{
+					ErrorCode:    0x1337,
+					ErrorMessage: reason,
+				}, maxPacketSize, protocol.Version1)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(p.shortHdrPacket.Frames).To(HaveLen(1))
+				ccf := p.shortHdrPacket.Frames[0].Frame.(*wire.ConnectionCloseFrame)
+				Expect(ccf.ReasonPhrase).To(Equal(reason[:protocol.MaxReasonPhraseLength]))
+			})
+
+			It("truncates reason phrases at a UTF-8 character boundary", func() {
+				// a 3-byte character
+				Expect(truncateReasonPhrase(strings.Repeat("€", 100))).To(Equal(strings.Repeat("€", protocol.MaxReasonPhraseLength/3)))
+				// a 4-byte character
+				Expect(truncateReasonPhrase(strings.Repeat("😀", 100))).To(Equal(strings.Repeat("😀", protocol.MaxReasonPhraseLength/4)))
+				// a 2-byte character, preceded by a single ASCII character
+				reason := "a" + strings.Repeat("ä", 200)
+				truncated := truncateReasonPhrase(reason)
+				Expect(truncated).To(HaveLen(protocol.MaxReasonPhraseLength - 1))
+				Expect(utf8.ValidString(truncated)).To(BeTrue())
+				Expect(reason).To(HavePrefix(truncated))
+				// short reason phrases are not modified
+				Expect(truncateReasonPhrase("foobar")).To(Equal("foobar"))
+				// invalid UTF-8 is cut after at most 3 bytes
+				invalid := strings.Repeat("\x80", 1000)
+				Expect(truncateReasonPhrase(invalid)).To(HaveLen(protocol.MaxReasonPhraseLength - 3))
+			})
+
 			It("packs a CONNECTION_CLOSE in all available encryption levels, and replaces application errors in Initial and Handshake", func() {
 				pnManager.EXPECT().PeekPacketNumber(protocol.EncryptionInitial).Return(protocol.PacketNumber(1), protocol.PacketNumberLen2)
 				pnManager.EXPECT().PopPacketNumber(protocol.EncryptionInitial).Return(protocol.PacketNumber(1))
@@ -519,6 +556,52 @@ var _ = Describe("Packet packer", func() {
 				Expect(p.Ack).To(Equal(ack))
 			})
 
+			It("greases the QUIC bit, if enabled", func() {
+				countUnsetQUICBits := func(n int) (unset int) {
+					for i := 0; i < n; i++ {
+						pnManager.EXPECT().PeekPacketNumber(protocol.Encryption1RTT).Return(protocol.PacketNumber(0x42), protocol.PacketNumberLen2)
+						pnManager.EXPECT().PopPacketNumber(protocol.Encryption1RTT).Return(protocol.PacketNumber(0x42))
+						framer.EXPECT().HasData()
+						ackFramer.EXPECT().GetAckFrame(protocol.Encryption1RTT, true).Return(&wire.AckFrame{AckRanges: []wire.AckRange{{Largest: 42, Smallest: 1}}})
+						sealingManager.EXPECT().Get1RTTSealer().Return(getSealer(), nil)
+						buffer := getPacketBuffer()
+						_, err := packer.AppendPacket(buffer, maxPacketSize, protocol.Version1)
+						Expect(err).NotTo(HaveOccurred())
+						Expect(wire.IsLongHeaderPacket(buffer.Data[0])).To(BeFalse())
+						if buffer.Data[0]&0x40 == 0 {
+							unset++
+						}
+					}
+					return unset
+				}
+
+				const num = 100
+				Expect(countUnsetQUICBits(num)).To(BeZero())
+				packer.EnableQUICBitGreasing()
+				Expect(countUnsetQUICBits(num)).To(And(BeNumerically(">", num/10), BeNumerically("<", num*9/10)))
+			})
+
+			It("sets the spin bit", func() {
+				packShortHeaderPacket := func() byte {
+					pnManager.EXPECT().PeekPacketNumber(protocol.Encryption1RTT).Return(protocol.PacketNumber(0x42), protocol.PacketNumberLen2)
+					pnManager.EXPECT().PopPacketNumber(protocol.Encryption1RTT).Return(protocol.PacketNumber(0x42))
+					framer.EXPECT().HasData()
+					ackFramer.EXPECT().GetAckFrame(protocol.Encryption1RTT, true).Return(&wire.AckFrame{AckRanges: []wire.AckRange{{Largest: 42, Smallest: 1}}})
+					sealingManager.EXPECT().Get1RTTSealer().Return(getSealer(), nil)
+					buffer := getPacketBuffer()
+					_, err := packer.AppendPacket(buffer, maxPacketSize, protocol.Version1)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(wire.IsLongHeaderPacket(buffer.Data[0])).To(BeFalse())
+					return buffer.Data[0]
+				}
+
+				Expect(packShortHeaderPacket() & 0x20).To(BeZero())
+				packer.SetSpinBit(true)
+				Expect(packShortHeaderPacket() & 0x20).ToNot(BeZero())
+				packer.SetSpinBit(false)
+				Expect(packShortHeaderPacket() & 0x20).To(BeZero())
+			})
+
 			It("packs control frames", func() {
 				pnManager.EXPECT().PeekPacketNumber(protocol.Encryption1RTT).Return(protocol.PacketNumber(0x42), protocol.PacketNumberLen2)
 				pnManager.EXPECT().PopPacketNumber(protocol.Encryption1RTT).Return(protocol.PacketNumber(0x42))
@@ -541,6 +624,21 @@ var _ = Describe("Packet packer", func() {
 				Expect(buffer.Len()).ToNot(BeZero())
 			})
 
+			It("keeps the handler of control frames that were queued with a handler", func() {
+				pnManager.EXPECT().PeekPacketNumber(protocol.Encryption1RTT).Return(protocol.PacketNumber(0x42), protocol.PacketNumberLen2)
+				pnManager.EXPECT().PopPacketNumber(protocol.Encryption1RTT).Return(protocol.PacketNumber(0x42))
+				sealingManager.EXPECT().Get1RTTSealer().Return(getSealer(), nil)
+				framer.EXPECT().HasData().Return(true)
+				ackFramer.EXPECT().GetAckFrame(protocol.Encryption1RTT, false)
+				handler := &pingAckHandler{}
+				expectAppendControlFrames(ackhandler.Frame{Frame: &wire.PingFrame{}, Handler: handler})
+				expectAppendStreamFrames()
+				p, err := packer.AppendPacket(getPacketBuffer(), maxPacketSize, protocol.Version1)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(p.Frames).To(HaveLen(1))
+				Expect(p.Frames[0].Handler).To(Equal(handler))
+			})
+
 			It("packs PATH_CHALLENGE and PATH_RESPONSE frames", func() {
 				pnManager.EXPECT().PeekPacketNumber(protocol.Encryption1RTT).Return(protocol.PacketNumber(0x42), protocol.PacketNumberLen2)
 				pnManager.EXPECT().PopPacketNumber(protocol.Encryption1RTT).Return(protocol.PacketNumber(0x42))
@@ -584,7 +682,7 @@ var _ = Describe("Packet packer", func() {
 				go func() {
 					defer GinkgoRecover()
 					defer close(done)
-					datagramQueue.Add(f)
+					datagramQueue.Add(f, nil)
 				}()
 				// make sure the DATAGRAM has actually been queued
 				time.Sleep(scaleDuration(20 * time.Millisecond))
@@ -612,7 +710,7 @@ var _ = Describe("Packet packer", func() {
 				go func() {
 					defer GinkgoRecover()
 					defer close(done)
-					datagramQueue.Add(f)
+					datagramQueue.Add(f, nil)
 				}()
 				// make sure the DATAGRAM has actually been queued
 				time.Sleep(scaleDuration(20 * time.Millisecond))
@@ -638,10 +736,11 @@ var _ = Describe("Packet packer", func() {
 					Data:           make([]byte, maxPacketSize+10), // won't fit
 				}
 				done := make(chan struct{})
+				sentErr := make(chan error, 1)
 				go func() {
 					defer GinkgoRecover()
 					defer close(done)
-					datagramQueue.Add(f)
+					datagramQueue.Add(f, func(err error) { sentErr <- err })
 				}()
 				// make sure the DATAGRAM has actually been queued
 				time.Sleep(scaleDuration(20 * time.Millisecond))
@@ -654,6 +753,9 @@ var _ = Describe("Packet packer", func() {
 				Expect(p.Ack).To(BeNil())
 				Expect(datagramQueue.Peek()).To(BeNil())
 				Eventually(done).Should(BeClosed())
+				Expect(sentErr).To(Receive(&err))
+				Expect(err).To(BeAssignableToTypeOf(&DatagramTooLargeError{}))
+				Expect(err.(*DatagramTooLargeError).MaxDatagramPayloadSize).To(BeNumerically("<", maxPacketSize))
 			})
 
 			It("accounts for the space consumed by control frames", func() {
@@ -709,7 +811,7 @@ var _ = Describe("Packet packer", func() {
 				Expect(err).ToNot(HaveOccurred())
 				Expect(secondPayloadByte).To(Equal(byte(0)))
 				// ... followed by the PING
-				frameParser := wire.NewFrameParser(false)
+				frameParser := wire.NewFrameParser(false, false, false)
 				l, frame, err := frameParser.ParseNext(data[len(data)-r.Len():], protocol.Encryption1RTT, protocol.Version1)
 				Expect(err).ToNot(HaveOccurred())
 				Expect(frame).To(BeAssignableToTypeOf(&wire.PingFrame{}))
@@ -746,7 +848,7 @@ var _ = Describe("Packet packer", func() {
 				Expect(err).ToNot(HaveOccurred())
 				Expect(firstPayloadByte).To(Equal(byte(0)))
 				// ... followed by the STREAM frame
-				frameParser := wire.NewFrameParser(true)
+				frameParser := wire.NewFrameParser(true, false, false)
 				l, frame, err := frameParser.ParseNext(buffer.Data[len(data)-r.Len():], protocol.Encryption1RTT, protocol.Version1)
 				Expect(err).ToNot(HaveOccurred())
 				Expect(frame).To(BeAssignableToTypeOf(&wire.StreamFrame{}))
@@ -1226,7 +1328,7 @@ var _ = Describe("Packet packer", func() {
 				Expect(err).ToNot(HaveOccurred())
 				Expect(secondPayloadByte).To(Equal(byte(0)))
 				// ... followed by the PING
-				frameParser := wire.NewFrameParser(false)
+				frameParser := wire.NewFrameParser(false, false, false)
 				l, frame, err := frameParser.ParseNext(data[len(data)-r.Len():], protocol.Encryption1RTT, protocol.Version1)
 				Expect(err).ToNot(HaveOccurred())
 				Expect(frame).To(BeAssignableToTypeOf(&wire.PingFrame{}))
@@ -1515,6 +1617,38 @@ var _ = Describe("Packet packer", func() {
 				Expect(buffer.Data).To(HaveLen(int(probePacketSize)))
 				Expect(p.IsPathMTUProbePacket).To(BeTrue())
 			})
+
+			It("packs a path probe packet", func() {
+				sealingManager.EXPECT().Get1RTTSealer().Return(getSealer(), nil)
+				pnManager.EXPECT().PeekPacketNumber(protocol.Encryption1RTT).Return(protocol.PacketNumber(0x43), protocol.PacketNumberLen2)
+				pnManager.EXPECT().PopPacketNumber(protocol.Encryption1RTT).Return(protocol.PacketNumber(0x43))
+				connID := protocol.ParseConnectionID([]byte{0xde, 0xca, 0xfb, 0xad})
+				challenge := ackhandler.Frame{Frame: &wire.PathChallengeFrame{Data: [8]byte{1, 2, 3, 4, 5, 6, 7, 8}}}
+				p, buffer, err := packer.PackPathProbePacket(connID, challenge, protocol.MinInitialPacketSize, protocol.Version1)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(p.Length).To(BeEquivalentTo(protocol.MinInitialPacketSize))
+				Expect(p.PacketNumber).To(Equal(protocol.PacketNumber(0x43)))
+				Expect(p.DestConnID).To(Equal(connID))
+				Expect(p.Frames).To(Equal([]ackhandler.Frame{challenge}))
+				Expect(buffer.Data).To(HaveLen(protocol.MinInitialPacketSize))
+				Expect(p.IsPathMTUProbePacket).To(BeFalse())
+				Expect(buffer.Data[1 : 1+connID.Len()]).To(Equal(connID.Bytes()))
+			})
+
+			It("packs a smaller path probe packet, if limited by the anti-amplification limit", func() {
+				sealingManager.EXPECT().Get1RTTSealer().Return(getSealer(), nil).Times(2)
+				pnManager.EXPECT().PeekPacketNumber(protocol.Encryption1RTT).Return(protocol.PacketNumber(0x43), protocol.PacketNumberLen2).Times(2)
+				pnManager.EXPECT().PopPacketNumber(protocol.Encryption1RTT).Return(protocol.PacketNumber(0x43))
+				connID := protocol.ParseConnectionID([]byte{0xde, 0xca, 0xfb, 0xad})
+				challenge := ackhandler.Frame{Frame: &wire.PathChallengeFrame{Data: [8]byte{1, 2, 3, 4, 5, 6, 7, 8}}}
+				p, buffer, err := packer.PackPathProbePacket(connID, challenge, 100, protocol.Version1)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(p.Length).To(BeEquivalentTo(100))
+				Expect(buffer.Data).To(HaveLen(100))
+				// the packet doesn't fit
+				_, _, err = packer.PackPathProbePacket(connID, challenge, 20, protocol.Version1)
+				Expect(err).To(MatchError(errNothingToPack))
+			})
 		})
 	})
 })
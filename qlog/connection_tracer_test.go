@@ -179,6 +179,17 @@ var _ = Describe("Tracing", func() {
 			Expect(ev).To(HaveKeyWithValue("trigger", "handshake_timeout"))
 		})
 
+		It("records PTO limit timeouts", func() {
+			tracer.ClosedConnection(&quic.PTOLimitReachedError{})
+			tracer.Close()
+			entry := exportAndParseSingle(buf)
+			Expect(entry.Name).To(Equal("transport:connection_closed"))
+			ev := entry.Event
+			Expect(ev).To(HaveLen(2))
+			Expect(ev).To(HaveKeyWithValue("owner", "local"))
+			Expect(ev).To(HaveKeyWithValue("trigger", "error"))
+		})
+
 		It("records a received stateless reset packet", func() {
 			tracer.ClosedConnection(&quic.StatelessResetError{
 				Token: protocol.StatelessResetToken{0x00, 0x11, 0x22, 0x33, 0x44, 0x55, 0x66, 0x77, 0x88, 0x99, 0xaa, 0xbb, 0xcc, 0xdd, 0xee, 0xff},
@@ -117,6 +117,7 @@ func (e eventConnectionClosed) MarshalJSONObject(enc *gojay.Encoder) {
 		statelessResetErr     *quic.StatelessResetError
 		handshakeTimeoutErr   *quic.HandshakeTimeoutError
 		idleTimeoutErr        *quic.IdleTimeoutError
+		ptoLimitReachedErr    *quic.PTOLimitReachedError
 		applicationErr        *quic.ApplicationError
 		transportErr          *quic.TransportError
 		versionNegotiationErr *quic.VersionNegotiationError
@@ -132,6 +133,9 @@ func (e eventConnectionClosed) MarshalJSONObject(enc *gojay.Encoder) {
 	case errors.As(e.e, &idleTimeoutErr):
 		enc.StringKey("owner", ownerLocal.String())
 		enc.StringKey("trigger", "idle_timeout")
+	case errors.As(e.e, &ptoLimitReachedErr):
+		enc.StringKey("owner", ownerLocal.String())
+		enc.StringKey("trigger", "error")
 	case errors.As(e.e, &applicationErr):
 		owner := ownerLocal
 		if applicationErr.Remote {
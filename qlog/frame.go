@@ -25,6 +25,8 @@ func (f frame) MarshalJSONObject(enc *gojay.Encoder) {
 		marshalAckFrame(enc, frame)
 	case *logging.ResetStreamFrame:
 		marshalResetStreamFrame(enc, frame)
+	case *logging.ResetStreamAtFrame:
+		marshalResetStreamAtFrame(enc, frame)
 	case *logging.StopSendingFrame:
 		marshalStopSendingFrame(enc, frame)
 	case *logging.CryptoFrame:
@@ -59,6 +61,14 @@ func (f frame) MarshalJSONObject(enc *gojay.Encoder) {
 		marshalHandshakeDoneFrame(enc, frame)
 	case *logging.DatagramFrame:
 		marshalDatagramFrame(enc, frame)
+	case *logging.AckFrequencyFrame:
+		marshalAckFrequencyFrame(enc, frame)
+	case *logging.ImmediateAckFrame:
+		marshalImmediateAckFrame(enc, frame)
+	case *logging.MaxDatagramsFrame:
+		marshalMaxDatagramsFrame(enc, frame)
+	case *logging.CustomFrame:
+		marshalCustomFrame(enc, frame)
 	default:
 		panic("unknown frame type")
 	}
@@ -118,6 +128,14 @@ func marshalResetStreamFrame(enc *gojay.Encoder, f *logging.ResetStreamFrame) {
 	enc.Int64Key("final_size", int64(f.FinalSize))
 }
 
+func marshalResetStreamAtFrame(enc *gojay.Encoder, f *logging.ResetStreamAtFrame) {
+	enc.StringKey("frame_type", "reset_stream_at")
+	enc.Int64Key("stream_id", int64(f.StreamID))
+	enc.Int64Key("error_code", int64(f.ErrorCode))
+	enc.Int64Key("final_size", int64(f.FinalSize))
+	enc.Int64Key("reliable_size", int64(f.ReliableSize))
+}
+
 func marshalStopSendingFrame(enc *gojay.Encoder, f *logging.StopSendingFrame) {
 	enc.StringKey("frame_type", "stop_sending")
 	enc.Int64Key("stream_id", int64(f.StreamID))
@@ -225,3 +243,26 @@ func marshalDatagramFrame(enc *gojay.Encoder, f *logging.DatagramFrame) {
 	enc.StringKey("frame_type", "datagram")
 	enc.Int64Key("length", int64(f.Length))
 }
+
+func marshalAckFrequencyFrame(enc *gojay.Encoder, f *logging.AckFrequencyFrame) {
+	enc.StringKey("frame_type", "ack_frequency")
+	enc.Uint64Key("sequence_number", f.SequenceNumber)
+	enc.Uint64Key("ack_eliciting_threshold", f.AckElicitingThreshold)
+	enc.Float64Key("request_max_ack_delay", milliseconds(f.RequestMaxAckDelay))
+	enc.Uint64Key("reordering_threshold", f.ReorderingThreshold)
+}
+
+func marshalImmediateAckFrame(enc *gojay.Encoder, _ *logging.ImmediateAckFrame) {
+	enc.StringKey("frame_type", "immediate_ack")
+}
+
+func marshalMaxDatagramsFrame(enc *gojay.Encoder, f *logging.MaxDatagramsFrame) {
+	enc.StringKey("frame_type", "max_datagrams")
+	enc.Uint64Key("maximum", f.MaximumDatagrams)
+}
+
+func marshalCustomFrame(enc *gojay.Encoder, f *logging.CustomFrame) {
+	enc.StringKey("frame_type", "unknown")
+	enc.Uint64Key("raw_frame_type", f.Type)
+	enc.Int64Key("length", int64(f.Length))
+}
@@ -113,6 +113,24 @@ var _ = Describe("Frames", func() {
 		)
 	})
 
+	It("marshals RESET_STREAM_AT frames", func() {
+		check(
+			&logging.ResetStreamAtFrame{
+				StreamID:     987,
+				FinalSize:    1234,
+				ReliableSize: 42,
+				ErrorCode:    42,
+			},
+			map[string]interface{}{
+				"frame_type":    "reset_stream_at",
+				"stream_id":     987,
+				"error_code":    42,
+				"final_size":    1234,
+				"reliable_size": 42,
+			},
+		)
+	})
+
 	It("marshals STOP_SENDING frames", func() {
 		check(
 			&logging.StopSendingFrame{
@@ -364,6 +382,43 @@ var _ = Describe("Frames", func() {
 		)
 	})
 
+	It("marshals ACK_FREQUENCY frames", func() {
+		check(
+			&logging.AckFrequencyFrame{
+				SequenceNumber:        3,
+				AckElicitingThreshold: 10,
+				RequestMaxAckDelay:    25 * time.Millisecond,
+				ReorderingThreshold:   1,
+			},
+			map[string]interface{}{
+				"frame_type":              "ack_frequency",
+				"sequence_number":         3,
+				"ack_eliciting_threshold": 10,
+				"request_max_ack_delay":   25,
+				"reordering_threshold":    1,
+			},
+		)
+	})
+
+	It("marshals IMMEDIATE_ACK frames", func() {
+		check(
+			&logging.ImmediateAckFrame{},
+			map[string]interface{}{
+				"frame_type": "immediate_ack",
+			},
+		)
+	})
+
+	It("marshals MAX_DATAGRAMS frames", func() {
+		check(
+			&logging.MaxDatagramsFrame{MaximumDatagrams: 1337},
+			map[string]interface{}{
+				"frame_type": "max_datagrams",
+				"maximum":    1337,
+			},
+		)
+	})
+
 	It("marshals DATAGRAM frames", func() {
 		check(
 			&logging.DatagramFrame{Length: 1337},
@@ -373,4 +428,15 @@ var _ = Describe("Frames", func() {
 			},
 		)
 	})
+
+	It("marshals custom frames", func() {
+		check(
+			&logging.CustomFrame{Type: 0x1337, Length: 42},
+			map[string]interface{}{
+				"frame_type":     "unknown",
+				"raw_frame_type": 0x1337,
+				"length":         42,
+			},
+		)
+	})
 })
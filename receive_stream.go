@@ -11,6 +11,7 @@ import (
 	"github.com/quic-go/quic-go/internal/qerr"
 	"github.com/quic-go/quic-go/internal/utils"
 	"github.com/quic-go/quic-go/internal/wire"
+	"github.com/quic-go/quic-go/quicvarint"
 )
 
 type receiveStreamI interface {
@@ -18,6 +19,7 @@ type receiveStreamI interface {
 
 	handleStreamFrame(*wire.StreamFrame) error
 	handleResetStreamFrame(*wire.ResetStreamFrame) error
+	handleResetStreamAtFrame(*wire.ResetStreamAtFrame) error
 	closeForShutdown(error)
 	getWindowUpdate() protocol.ByteCount
 }
@@ -31,6 +33,22 @@ type receiveStream struct {
 
 	frameQueue  *frameSorter
 	finalOffset protocol.ByteCount
+	readOffset  protocol.ByteCount // number of bytes returned by Read so far
+	// Set when a RESET_STREAM_AT frame was received, and reset to 0 once the reset error is returned.
+	// Data up to this offset is still delivered to the application.
+	reliableSize protocol.ByteCount
+	// If set, the contiguous data received before a RESET_STREAM frame is delivered to the application.
+	drainOnReset bool
+	// Set when StopSending was called, until the data received before that was read.
+	// The data is delivered up to the reliableSize.
+	stopSending bool
+
+	// If set, the stream type (the varint at the beginning of the stream) is checked before
+	// the stream can be accepted. It is reset to nil once the check was completed.
+	acceptStreamType      func(streamType uint64) bool
+	streamTypeCheckedChan chan struct{}        // closed once the stream type check was completed
+	rejectErrorCode       qerr.StreamErrorCode // the error code used for STOP_SENDING if the stream type is rejected
+	streamTypeRejected    bool
 
 	currentFrame       []byte
 	currentFrameDone   func()
@@ -49,6 +67,7 @@ type receiveStream struct {
 	readChan chan struct{}
 	readOnce chan struct{} // cap: 1, to protect against concurrent use of Read
 	deadline time.Time
+	clock    utils.Clock // used to evaluate the deadline
 
 	flowController flowcontrol.StreamFlowController
 }
@@ -62,11 +81,13 @@ func newReceiveStream(
 	streamID protocol.StreamID,
 	sender streamSender,
 	flowController flowcontrol.StreamFlowController,
+	clock utils.Clock,
 ) *receiveStream {
 	return &receiveStream{
 		streamID:       streamID,
 		sender:         sender,
 		flowController: flowController,
+		clock:          clock,
 		frameQueue:     newFrameSorter(),
 		readChan:       make(chan struct{}, 1),
 		readOnce:       make(chan struct{}, 1),
@@ -74,6 +95,58 @@ func newReceiveStream(
 	}
 }
 
+// setStreamTypeCheck makes the stream wait for the stream type before it can be accepted.
+// It must be called before any frames are handled.
+func (s *receiveStream) setStreamTypeCheck(accept func(streamType uint64) bool, rejectErrorCode qerr.StreamErrorCode) {
+	s.acceptStreamType = accept
+	s.rejectErrorCode = rejectErrorCode
+	s.streamTypeCheckedChan = make(chan struct{})
+}
+
+// streamTypeChecked says if the stream can be accepted by the application.
+// If the stream type wasn't checked yet, it returns a channel that is closed once the check completed.
+func (s *receiveStream) streamTypeChecked() (<-chan struct{}, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.acceptStreamType != nil {
+		return s.streamTypeCheckedChan, false
+	}
+	return nil, !s.streamTypeRejected
+}
+
+func (s *receiveStream) checkStreamType() {
+	if s.acceptStreamType == nil {
+		return
+	}
+	b := s.frameQueue.Peek(8)
+	if len(b) == 0 || len(b) < 1<<(b[0]>>6) {
+		// If the stream ended before the stream type was received, let the application deal with it.
+		if s.finalOffset != protocol.MaxByteCount && s.frameQueue.ContiguousEnd() >= s.finalOffset {
+			s.completeStreamTypeCheck()
+		}
+		return
+	}
+	streamType, _, err := quicvarint.Parse(b)
+	if err != nil { // can't happen, we checked the length above
+		return
+	}
+	accept := s.acceptStreamType(streamType)
+	s.completeStreamTypeCheck()
+	if !accept {
+		s.streamTypeRejected = true
+		s.cancelReadImpl(s.rejectErrorCode)
+	}
+}
+
+func (s *receiveStream) completeStreamTypeCheck() {
+	if s.acceptStreamType == nil {
+		return
+	}
+	s.acceptStreamType = nil
+	close(s.streamTypeCheckedChan)
+}
+
 func (s *receiveStream) StreamID() protocol.StreamID {
 	return s.streamID
 }
@@ -153,14 +226,17 @@ func (s *receiveStream) readImpl(p []byte) (int, error) {
 
 			deadline := s.deadline
 			if !deadline.IsZero() {
-				if !time.Now().Before(deadline) {
+				if !s.clock.Now().Before(deadline) {
 					return bytesRead, errDeadline
 				}
 				if deadlineTimer == nil {
-					deadlineTimer = utils.NewTimer()
+					deadlineTimer = utils.NewTimerWithClock(s.clock)
 					defer deadlineTimer.Stop()
 				}
 				deadlineTimer.Reset(deadline)
+			} else if deadlineTimer != nil {
+				// the deadline was cleared, make sure that a previously set deadline doesn't fire
+				deadlineTimer.Reset(time.Time{})
 			}
 
 			if s.currentFrame != nil || s.currentFrameIsLast {
@@ -190,8 +266,14 @@ func (s *receiveStream) readImpl(p []byte) (int, error) {
 			return bytesRead, fmt.Errorf("BUG: readPosInFrame (%d) > frame.DataLen (%d) in stream.Read", s.readPosInFrame, len(s.currentFrame))
 		}
 
-		m := copy(p[bytesRead:], s.currentFrame[s.readPosInFrame:])
+		data := s.currentFrame[s.readPosInFrame:]
+		// after receiving a RESET_STREAM_AT frame, only data up to the reliable size is delivered
+		if s.reliableSize > 0 && protocol.ByteCount(len(data)) > s.reliableSize-s.readOffset {
+			data = data[:s.reliableSize-s.readOffset]
+		}
+		m := copy(p[bytesRead:], data)
 		s.readPosInFrame += m
+		s.readOffset += protocol.ByteCount(m)
 		bytesRead += m
 
 		// when a RESET_STREAM was received, the flow controller was already
@@ -200,6 +282,19 @@ func (s *receiveStream) readImpl(p []byte) (int, error) {
 			s.flowController.AddBytesRead(protocol.ByteCount(m))
 		}
 
+		if s.reliableSize > 0 && s.readOffset >= s.reliableSize {
+			s.reliableSize = 0
+			if s.stopSending {
+				s.stopSending = false
+				s.cancelledLocally = true
+			} else {
+				s.cancelledRemotely = true
+			}
+			s.flowController.Abandon()
+			s.errorRead = true
+			return bytesRead, s.cancelErr
+		}
+
 		if s.readPosInFrame >= len(s.currentFrame) && s.currentFrameIsLast {
 			s.currentFrame = nil
 			if s.currentFrameDone != nil {
@@ -243,6 +338,13 @@ func (s *receiveStream) cancelReadImpl(errorCode qerr.StreamErrorCode) {
 	if s.errorRead || s.cancelledRemotely {
 		return
 	}
+	// STOP_SENDING was already sent, discard the data that was received before
+	if s.stopSending {
+		s.stopSending = false
+		s.reliableSize = 0
+		s.signalRead()
+		return
+	}
 	s.cancelErr = &StreamError{StreamID: s.streamID, ErrorCode: errorCode, Remote: false}
 	s.signalRead()
 	s.sender.queueControlFrame(&wire.StopSendingFrame{
@@ -251,6 +353,41 @@ func (s *receiveStream) cancelReadImpl(errorCode qerr.StreamErrorCode) {
 	})
 }
 
+func (s *receiveStream) StopSending(errorCode StreamErrorCode) {
+	s.mutex.Lock()
+	s.stopSendingImpl(errorCode)
+	completed := s.isNewlyCompleted()
+	s.mutex.Unlock()
+
+	if completed {
+		s.flowController.Abandon()
+		s.sender.onStreamCompleted(s.streamID)
+	}
+}
+
+func (s *receiveStream) stopSendingImpl(errorCode qerr.StreamErrorCode) {
+	// Nothing to do if the stream was already cancelled or reset.
+	if s.stopSending || s.cancelledLocally || s.errorRead || s.cancelledRemotely || s.reliableSize > 0 {
+		return
+	}
+	end := s.frameQueue.ContiguousEnd()
+	// All data (including the FIN) was already received. There's no need to ask the peer to stop sending.
+	if end >= s.finalOffset {
+		return
+	}
+	if end <= s.readOffset {
+		s.cancelReadImpl(errorCode)
+		return
+	}
+	s.stopSending = true
+	s.reliableSize = end
+	s.cancelErr = &StreamError{StreamID: s.streamID, ErrorCode: errorCode, Remote: false}
+	s.sender.queueControlFrame(&wire.StopSendingFrame{
+		StreamID:  s.streamID,
+		ErrorCode: errorCode,
+	})
+}
+
 func (s *receiveStream) handleStreamFrame(frame *wire.StreamFrame) error {
 	s.mutex.Lock()
 	err := s.handleStreamFrameImpl(frame)
@@ -278,6 +415,7 @@ func (s *receiveStream) handleStreamFrameImpl(frame *wire.StreamFrame) error {
 	if err := s.frameQueue.Push(frame.Data, frame.Offset, frame.PutBack); err != nil {
 		return err
 	}
+	s.checkStreamType()
 	s.signalRead()
 	return nil
 }
@@ -285,6 +423,8 @@ func (s *receiveStream) handleStreamFrameImpl(frame *wire.StreamFrame) error {
 func (s *receiveStream) handleResetStreamFrame(frame *wire.ResetStreamFrame) error {
 	s.mutex.Lock()
 	err := s.handleResetStreamFrameImpl(frame)
+	// the stream won't receive any more data, so the stream type can't be checked
+	s.completeStreamTypeCheck()
 	completed := s.isNewlyCompleted()
 	s.mutex.Unlock()
 
@@ -307,6 +447,24 @@ func (s *receiveStream) handleResetStreamFrameImpl(frame *wire.ResetStreamFrame)
 	if s.cancelledRemotely {
 		return nil
 	}
+	// Deliver the data received so far, as if the peer had sent a RESET_STREAM_AT frame.
+	if (s.drainOnReset || s.stopSending) && !s.cancelledLocally {
+		reliableSize := s.frameQueue.ContiguousEnd()
+		// the reliable size can only be reduced by subsequent RESET_STREAM(_AT) frames
+		if s.reliableSize > 0 {
+			reliableSize = min(reliableSize, s.reliableSize)
+		}
+		if reliableSize > s.readOffset {
+			s.reliableSize = reliableSize
+			// after StopSending, the local error is returned once the data was read
+			if !s.stopSending {
+				s.cancelErr = &StreamError{StreamID: s.streamID, ErrorCode: frame.ErrorCode, Remote: true}
+			}
+			s.signalRead()
+			return nil
+		}
+		s.reliableSize = 0
+	}
 	s.flowController.Abandon()
 	// don't save the error if the RESET_STREAM frames was received after CancelRead was called
 	if s.cancelledLocally {
@@ -318,6 +476,50 @@ func (s *receiveStream) handleResetStreamFrameImpl(frame *wire.ResetStreamFrame)
 	return nil
 }
 
+func (s *receiveStream) handleResetStreamAtFrame(frame *wire.ResetStreamAtFrame) error {
+	s.mutex.Lock()
+	err := s.handleResetStreamAtFrameImpl(frame)
+	s.completeStreamTypeCheck()
+	completed := s.isNewlyCompleted()
+	s.mutex.Unlock()
+
+	if completed {
+		s.sender.onStreamCompleted(s.streamID)
+	}
+	return err
+}
+
+func (s *receiveStream) handleResetStreamAtFrameImpl(frame *wire.ResetStreamAtFrame) error {
+	if s.closeForShutdownErr != nil {
+		return nil
+	}
+	// If all data up to the reliable size was already read, the frame is handled like a RESET_STREAM frame.
+	if frame.ReliableSize <= s.readOffset || s.cancelledLocally {
+		s.reliableSize = 0
+		return s.handleResetStreamFrameImpl(&wire.ResetStreamFrame{
+			StreamID:  frame.StreamID,
+			ErrorCode: frame.ErrorCode,
+			FinalSize: frame.FinalSize,
+		})
+	}
+	if err := s.flowController.UpdateHighestReceived(frame.FinalSize, true); err != nil {
+		return err
+	}
+	s.finalOffset = frame.FinalSize
+
+	// Ignore RESET_STREAM_AT frames received after the stream was reset.
+	// The reliable size can only be reduced by subsequent RESET_STREAM_AT frames.
+	if s.cancelledRemotely || (s.reliableSize > 0 && frame.ReliableSize >= s.reliableSize) {
+		return nil
+	}
+	s.reliableSize = frame.ReliableSize
+	if !s.stopSending {
+		s.cancelErr = &StreamError{StreamID: s.streamID, ErrorCode: frame.ErrorCode, Remote: true}
+	}
+	s.signalRead()
+	return nil
+}
+
 func (s *receiveStream) SetReadDeadline(t time.Time) error {
 	s.mutex.Lock()
 	s.deadline = t
@@ -326,12 +528,23 @@ func (s *receiveStream) SetReadDeadline(t time.Time) error {
 	return nil
 }
 
+func (s *receiveStream) SetReceiveWindow(size protocol.ByteCount) {
+	s.flowController.SetReceiveWindowSize(size)
+}
+
+func (s *receiveStream) SetDrainOnReset(drain bool) {
+	s.mutex.Lock()
+	s.drainOnReset = drain
+	s.mutex.Unlock()
+}
+
 // CloseForShutdown closes a stream abruptly.
 // It makes Read unblock (and return the error) immediately.
 // The peer will NOT be informed about this: the stream is closed without sending a FIN or RESET.
 func (s *receiveStream) closeForShutdown(err error) {
 	s.mutex.Lock()
 	s.closeForShutdownErr = err
+	s.completeStreamTypeCheck()
 	s.mutex.Unlock()
 	s.signalRead()
 }
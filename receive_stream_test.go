@@ -10,6 +10,7 @@ import (
 
 	"github.com/quic-go/quic-go/internal/mocks"
 	"github.com/quic-go/quic-go/internal/protocol"
+	"github.com/quic-go/quic-go/internal/utils"
 	"github.com/quic-go/quic-go/internal/wire"
 
 	. "github.com/onsi/ginkgo/v2"
@@ -31,7 +32,7 @@ var _ = Describe("Receive Stream", func() {
 	BeforeEach(func() {
 		mockSender = NewMockStreamSender(mockCtrl)
 		mockFC = mocks.NewMockStreamFlowController(mockCtrl)
-		str = newReceiveStream(streamID, mockSender, mockFC)
+		str = newReceiveStream(streamID, mockSender, mockFC, utils.DefaultClock{})
 
 		timeout := scaleDuration(250 * time.Millisecond)
 		strWithTimeout = gbytes.TimeoutReader(str, timeout)
@@ -258,6 +259,22 @@ var _ = Describe("Receive Stream", func() {
 				Expect(time.Now()).To(BeTemporally("~", deadline, scaleDuration(20*time.Millisecond)))
 			})
 
+			It("evaluates the deadline using the clock", func() {
+				clock := &fakeClock{now: time.Now()}
+				str.clock = clock
+				str.SetReadDeadline(clock.Now().Add(time.Hour))
+				done := make(chan struct{})
+				go func() {
+					defer GinkgoRecover()
+					_, err := str.Read(make([]byte, 6))
+					Expect(err).To(MatchError(errDeadline))
+					close(done)
+				}()
+				Consistently(done).ShouldNot(BeClosed())
+				clock.Advance(time.Hour)
+				Eventually(done).Should(BeClosed())
+			})
+
 			It("doesn't unblock if the deadline is changed before the first one expires", func() {
 				deadline1 := time.Now().Add(scaleDuration(50 * time.Millisecond))
 				deadline2 := time.Now().Add(scaleDuration(100 * time.Millisecond))
@@ -295,6 +312,50 @@ var _ = Describe("Receive Stream", func() {
 				Expect(time.Now()).To(BeTemporally("~", deadline2, scaleDuration(25*time.Millisecond)))
 			})
 
+			It("reads after a blocked Read was interrupted and the deadline was cleared", func() {
+				str.SetReadDeadline(time.Now().Add(time.Hour))
+				errChan := make(chan error, 1)
+				go func() {
+					defer GinkgoRecover()
+					_, err := str.Read(make([]byte, 6))
+					errChan <- err
+				}()
+				Consistently(errChan).ShouldNot(Receive())
+				str.SetReadDeadline(time.Now().Add(-time.Hour))
+				Eventually(errChan).Should(Receive(MatchError(errDeadline)))
+
+				str.SetReadDeadline(time.Time{})
+				done := make(chan struct{})
+				go func() {
+					defer GinkgoRecover()
+					defer close(done)
+					b := make([]byte, 6)
+					n, err := str.Read(b)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(b[:n]).To(Equal([]byte("foobar")))
+				}()
+				Consistently(done, scaleDuration(50*time.Millisecond)).ShouldNot(BeClosed())
+				mockFC.EXPECT().UpdateHighestReceived(protocol.ByteCount(6), false)
+				mockFC.EXPECT().AddBytesRead(protocol.ByteCount(6))
+				Expect(str.handleStreamFrame(&wire.StreamFrame{Data: []byte("foobar")})).To(Succeed())
+				Eventually(done).Should(BeClosed())
+			})
+
+			It("sets a new deadline after the deadline was cleared", func() {
+				str.SetReadDeadline(time.Now().Add(time.Hour))
+				errChan := make(chan error, 1)
+				go func() {
+					defer GinkgoRecover()
+					_, err := str.Read(make([]byte, 6))
+					errChan <- err
+				}()
+				Consistently(errChan, scaleDuration(20*time.Millisecond)).ShouldNot(Receive())
+				str.SetReadDeadline(time.Time{})
+				Consistently(errChan, scaleDuration(20*time.Millisecond)).ShouldNot(Receive())
+				str.SetReadDeadline(time.Now().Add(scaleDuration(20 * time.Millisecond)))
+				Eventually(errChan).Should(Receive(MatchError(errDeadline)))
+			})
+
 			It("doesn't unblock if the deadline is removed", func() {
 				deadline := time.Now().Add(scaleDuration(50 * time.Millisecond))
 				str.SetReadDeadline(deadline)
@@ -608,6 +669,110 @@ var _ = Describe("Receive Stream", func() {
 			})
 		})
 
+		Context("stopping sending", func() {
+			It("delivers the data received before, then returns the error", func() {
+				mockFC.EXPECT().UpdateHighestReceived(protocol.ByteCount(6), false)
+				Expect(str.handleStreamFrame(&wire.StreamFrame{Data: []byte("foobar")})).To(Succeed())
+				mockSender.EXPECT().queueControlFrame(&wire.StopSendingFrame{StreamID: streamID, ErrorCode: 1234})
+				str.StopSending(1234)
+				// this frame was sent by the peer before it received the STOP_SENDING frame
+				mockFC.EXPECT().UpdateHighestReceived(protocol.ByteCount(9), false)
+				Expect(str.handleStreamFrame(&wire.StreamFrame{Offset: 6, Data: []byte("baz")})).To(Succeed())
+				gomock.InOrder(
+					mockFC.EXPECT().AddBytesRead(protocol.ByteCount(6)),
+					mockFC.EXPECT().Abandon(),
+				)
+				b := make([]byte, 100)
+				n, err := strWithTimeout.Read(b)
+				Expect(err).To(Equal(&StreamError{
+					StreamID:  streamID,
+					ErrorCode: 1234,
+					Remote:    false,
+				}))
+				Expect(b[:n]).To(Equal([]byte("foobar")))
+				// further calls to Read return the error
+				_, err = strWithTimeout.Read(b)
+				Expect(err).To(MatchError(&StreamError{StreamID: streamID, ErrorCode: 1234}))
+				// the stream is completed once the peer resets the stream
+				gomock.InOrder(
+					mockFC.EXPECT().UpdateHighestReceived(protocol.ByteCount(42), true),
+					mockFC.EXPECT().Abandon(),
+					mockSender.EXPECT().onStreamCompleted(streamID),
+				)
+				Expect(str.handleResetStreamFrame(&wire.ResetStreamFrame{StreamID: streamID, FinalSize: 42, ErrorCode: 4321})).To(Succeed())
+			})
+
+			It("delivers the data received before if the peer resets the stream", func() {
+				mockFC.EXPECT().UpdateHighestReceived(protocol.ByteCount(6), false)
+				Expect(str.handleStreamFrame(&wire.StreamFrame{Data: []byte("foobar")})).To(Succeed())
+				mockSender.EXPECT().queueControlFrame(gomock.Any())
+				str.StopSending(1234)
+				mockFC.EXPECT().UpdateHighestReceived(protocol.ByteCount(6), true)
+				Expect(str.handleResetStreamFrame(&wire.ResetStreamFrame{StreamID: streamID, FinalSize: 6, ErrorCode: 4321})).To(Succeed())
+				mockFC.EXPECT().AddBytesRead(protocol.ByteCount(4))
+				b := make([]byte, 4)
+				n, err := strWithTimeout.Read(b)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(b[:n]).To(Equal([]byte("foob")))
+				gomock.InOrder(
+					mockFC.EXPECT().AddBytesRead(protocol.ByteCount(2)),
+					mockFC.EXPECT().Abandon(),
+					mockSender.EXPECT().onStreamCompleted(streamID),
+				)
+				n, err = strWithTimeout.Read(b)
+				// the error is the local error, not the peer's reset error
+				Expect(err).To(Equal(&StreamError{
+					StreamID:  streamID,
+					ErrorCode: 1234,
+					Remote:    false,
+				}))
+				Expect(b[:n]).To(Equal([]byte("ar")))
+			})
+
+			It("behaves like CancelRead if no data is buffered", func() {
+				mockSender.EXPECT().queueControlFrame(&wire.StopSendingFrame{StreamID: streamID, ErrorCode: 1234})
+				done := make(chan struct{})
+				go func() {
+					defer GinkgoRecover()
+					_, err := strWithTimeout.Read([]byte{0})
+					Expect(err).To(Equal(&StreamError{
+						StreamID:  streamID,
+						ErrorCode: 1234,
+						Remote:    false,
+					}))
+					close(done)
+				}()
+				Consistently(done).ShouldNot(BeClosed())
+				str.StopSending(1234)
+				Eventually(done).Should(BeClosed())
+			})
+
+			It("doesn't send STOP_SENDING if all data was already received", func() {
+				mockFC.EXPECT().UpdateHighestReceived(protocol.ByteCount(6), true)
+				Expect(str.handleStreamFrame(&wire.StreamFrame{Data: []byte("foobar"), Fin: true})).To(Succeed())
+				str.StopSending(1234)
+				mockFC.EXPECT().AddBytesRead(protocol.ByteCount(6))
+				mockSender.EXPECT().onStreamCompleted(streamID)
+				b := make([]byte, 100)
+				n, err := strWithTimeout.Read(b)
+				Expect(err).To(MatchError(io.EOF))
+				Expect(b[:n]).To(Equal([]byte("foobar")))
+			})
+
+			It("doesn't send STOP_SENDING again when CancelRead is called", func() {
+				mockFC.EXPECT().UpdateHighestReceived(protocol.ByteCount(6), false)
+				Expect(str.handleStreamFrame(&wire.StreamFrame{Data: []byte("foobar")})).To(Succeed())
+				mockSender.EXPECT().queueControlFrame(gomock.Any())
+				str.StopSending(1234)
+				str.StopSending(1234)
+				// CancelRead discards the buffered data
+				str.CancelRead(4321)
+				n, err := strWithTimeout.Read(make([]byte, 100))
+				Expect(err).To(MatchError(&StreamError{StreamID: streamID, ErrorCode: 1234}))
+				Expect(n).To(BeZero())
+			})
+		})
+
 		Context("receiving RESET_STREAM frames", func() {
 			rst := &wire.ResetStreamFrame{
 				StreamID:  streamID,
@@ -687,6 +852,110 @@ var _ = Describe("Receive Stream", func() {
 				Expect(err).ToNot(HaveOccurred())
 			})
 
+			Context("draining on reset", func() {
+				BeforeEach(func() {
+					str.SetDrainOnReset(true)
+				})
+
+				It("delivers the contiguous data received before the RESET_STREAM, then returns the error", func() {
+					mockFC.EXPECT().UpdateHighestReceived(protocol.ByteCount(6), false)
+					mockFC.EXPECT().UpdateHighestReceived(protocol.ByteCount(20), false)
+					mockFC.EXPECT().UpdateHighestReceived(protocol.ByteCount(42), true)
+					Expect(str.handleStreamFrame(&wire.StreamFrame{Data: []byte("foobar")})).To(Succeed())
+					// this frame is not contiguous with the data received so far
+					Expect(str.handleStreamFrame(&wire.StreamFrame{Offset: 10, Data: []byte("0123456789")})).To(Succeed())
+					Expect(str.handleResetStreamFrame(rst)).To(Succeed())
+					gomock.InOrder(
+						mockFC.EXPECT().AddBytesRead(protocol.ByteCount(6)),
+						mockFC.EXPECT().Abandon(),
+						mockSender.EXPECT().onStreamCompleted(streamID),
+					)
+					b := make([]byte, 100)
+					n, err := strWithTimeout.Read(b)
+					Expect(err).To(Equal(&StreamError{
+						StreamID:  streamID,
+						ErrorCode: 1234,
+						Remote:    true,
+					}))
+					Expect(b[:n]).To(Equal([]byte("foobar")))
+					// further calls to Read return the error
+					_, err = strWithTimeout.Read(b)
+					Expect(err).To(MatchError(&StreamError{StreamID: streamID, ErrorCode: 1234}))
+				})
+
+				It("delivers data in multiple calls to Read", func() {
+					mockFC.EXPECT().UpdateHighestReceived(protocol.ByteCount(6), false)
+					mockFC.EXPECT().UpdateHighestReceived(protocol.ByteCount(42), true)
+					Expect(str.handleStreamFrame(&wire.StreamFrame{Data: []byte("foobar")})).To(Succeed())
+					Expect(str.handleResetStreamFrame(rst)).To(Succeed())
+					mockFC.EXPECT().AddBytesRead(protocol.ByteCount(4))
+					b := make([]byte, 4)
+					n, err := strWithTimeout.Read(b)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(b[:n]).To(Equal([]byte("foob")))
+					gomock.InOrder(
+						mockFC.EXPECT().AddBytesRead(protocol.ByteCount(2)),
+						mockFC.EXPECT().Abandon(),
+						mockSender.EXPECT().onStreamCompleted(streamID),
+					)
+					n, err = strWithTimeout.Read(b)
+					Expect(err).To(MatchError(&StreamError{StreamID: streamID, ErrorCode: 1234}))
+					Expect(b[:n]).To(Equal([]byte("ar")))
+				})
+
+				It("doesn't deliver data received after the RESET_STREAM", func() {
+					mockFC.EXPECT().UpdateHighestReceived(protocol.ByteCount(3), false)
+					mockFC.EXPECT().UpdateHighestReceived(protocol.ByteCount(42), true)
+					mockFC.EXPECT().UpdateHighestReceived(protocol.ByteCount(6), false)
+					Expect(str.handleStreamFrame(&wire.StreamFrame{Data: []byte("foo")})).To(Succeed())
+					Expect(str.handleResetStreamFrame(rst)).To(Succeed())
+					Expect(str.handleStreamFrame(&wire.StreamFrame{Offset: 3, Data: []byte("bar")})).To(Succeed())
+					gomock.InOrder(
+						mockFC.EXPECT().AddBytesRead(protocol.ByteCount(3)),
+						mockFC.EXPECT().Abandon(),
+						mockSender.EXPECT().onStreamCompleted(streamID),
+					)
+					b := make([]byte, 100)
+					n, err := strWithTimeout.Read(b)
+					Expect(err).To(MatchError(&StreamError{StreamID: streamID, ErrorCode: 1234}))
+					Expect(b[:n]).To(Equal([]byte("foo")))
+				})
+
+				It("returns the error right away if there's no data to deliver", func() {
+					mockFC.EXPECT().UpdateHighestReceived(protocol.ByteCount(6), false)
+					mockFC.EXPECT().AddBytesRead(protocol.ByteCount(6))
+					Expect(str.handleStreamFrame(&wire.StreamFrame{Data: []byte("foobar")})).To(Succeed())
+					_, err := strWithTimeout.Read(make([]byte, 6))
+					Expect(err).ToNot(HaveOccurred())
+					gomock.InOrder(
+						mockFC.EXPECT().UpdateHighestReceived(protocol.ByteCount(42), true),
+						mockFC.EXPECT().Abandon(),
+						mockSender.EXPECT().onStreamCompleted(streamID),
+					)
+					Expect(str.handleResetStreamFrame(rst)).To(Succeed())
+					n, err := strWithTimeout.Read(make([]byte, 6))
+					Expect(err).To(MatchError(&StreamError{StreamID: streamID, ErrorCode: 1234}))
+					Expect(n).To(BeZero())
+				})
+
+				It("doesn't extend the reliable size of a previous RESET_STREAM_AT", func() {
+					mockFC.EXPECT().UpdateHighestReceived(protocol.ByteCount(6), false)
+					mockFC.EXPECT().UpdateHighestReceived(protocol.ByteCount(42), true).Times(2)
+					Expect(str.handleStreamFrame(&wire.StreamFrame{Data: []byte("foobar")})).To(Succeed())
+					Expect(str.handleResetStreamAtFrame(&wire.ResetStreamAtFrame{StreamID: streamID, FinalSize: 42, ReliableSize: 3, ErrorCode: 1234})).To(Succeed())
+					Expect(str.handleResetStreamFrame(rst)).To(Succeed())
+					gomock.InOrder(
+						mockFC.EXPECT().AddBytesRead(protocol.ByteCount(3)),
+						mockFC.EXPECT().Abandon(),
+						mockSender.EXPECT().onStreamCompleted(streamID),
+					)
+					b := make([]byte, 100)
+					n, err := strWithTimeout.Read(b)
+					Expect(err).To(MatchError(&StreamError{StreamID: streamID, ErrorCode: 1234}))
+					Expect(b[:n]).To(Equal([]byte("foo")))
+				})
+			})
+
 			It("handles RESET_STREAM after CancelRead", func() {
 				mockFC.EXPECT().Abandon()
 				mockSender.EXPECT().queueControlFrame(gomock.Any())
@@ -703,6 +972,193 @@ var _ = Describe("Receive Stream", func() {
 				Expect(streamErr.Remote).To(BeFalse())
 			})
 		})
+
+		Context("receiving RESET_STREAM_AT frames", func() {
+			rst := &wire.ResetStreamAtFrame{
+				StreamID:     streamID,
+				FinalSize:    20,
+				ReliableSize: 6,
+				ErrorCode:    1234,
+			}
+
+			It("delivers data up to the reliable size, then returns the error", func() {
+				mockFC.EXPECT().UpdateHighestReceived(protocol.ByteCount(10), false)
+				mockFC.EXPECT().UpdateHighestReceived(protocol.ByteCount(20), true)
+				Expect(str.handleStreamFrame(&wire.StreamFrame{Data: []byte("foobarbaz!")})).To(Succeed())
+				Expect(str.handleResetStreamAtFrame(rst)).To(Succeed())
+				gomock.InOrder(
+					mockFC.EXPECT().AddBytesRead(protocol.ByteCount(6)),
+					mockFC.EXPECT().Abandon(),
+					mockSender.EXPECT().onStreamCompleted(streamID),
+				)
+				b := make([]byte, 100)
+				n, err := strWithTimeout.Read(b)
+				Expect(err).To(Equal(&StreamError{
+					StreamID:  streamID,
+					ErrorCode: 1234,
+					Remote:    true,
+				}))
+				Expect(b[:n]).To(Equal([]byte("foobar")))
+				// further calls to Read return the error
+				_, err = strWithTimeout.Read(b)
+				Expect(err).To(MatchError(&StreamError{StreamID: streamID, ErrorCode: 1234}))
+			})
+
+			It("waits for the data up to the reliable size", func() {
+				mockFC.EXPECT().UpdateHighestReceived(protocol.ByteCount(20), true)
+				Expect(str.handleResetStreamAtFrame(rst)).To(Succeed())
+				done := make(chan struct{})
+				go func() {
+					defer GinkgoRecover()
+					b := make([]byte, 100)
+					n, err := strWithTimeout.Read(b)
+					Expect(err).To(MatchError(&StreamError{StreamID: streamID, ErrorCode: 1234}))
+					Expect(b[:n]).To(Equal([]byte("foobar")))
+					close(done)
+				}()
+				Consistently(done).ShouldNot(BeClosed())
+				mockFC.EXPECT().UpdateHighestReceived(protocol.ByteCount(10), false)
+				mockFC.EXPECT().AddBytesRead(protocol.ByteCount(6))
+				mockFC.EXPECT().Abandon()
+				mockSender.EXPECT().onStreamCompleted(streamID)
+				Expect(str.handleStreamFrame(&wire.StreamFrame{Data: []byte("foobarbaz!")})).To(Succeed())
+				Eventually(done).Should(BeClosed())
+			})
+
+			It("handles RESET_STREAM_AT like a RESET_STREAM, if the data up to the reliable size was already read", func() {
+				mockFC.EXPECT().UpdateHighestReceived(protocol.ByteCount(10), false)
+				mockFC.EXPECT().AddBytesRead(protocol.ByteCount(8))
+				Expect(str.handleStreamFrame(&wire.StreamFrame{Data: []byte("foobarbaz!")})).To(Succeed())
+				_, err := strWithTimeout.Read(make([]byte, 8))
+				Expect(err).ToNot(HaveOccurred())
+				gomock.InOrder(
+					mockFC.EXPECT().UpdateHighestReceived(protocol.ByteCount(20), true),
+					mockFC.EXPECT().Abandon(),
+				)
+				Expect(str.handleResetStreamAtFrame(rst)).To(Succeed())
+				mockSender.EXPECT().onStreamCompleted(streamID)
+				n, err := strWithTimeout.Read(make([]byte, 8))
+				Expect(err).To(MatchError(&StreamError{StreamID: streamID, ErrorCode: 1234}))
+				Expect(n).To(BeZero())
+			})
+
+			It("only allows reducing the reliable size", func() {
+				mockFC.EXPECT().UpdateHighestReceived(protocol.ByteCount(10), false)
+				mockFC.EXPECT().UpdateHighestReceived(protocol.ByteCount(20), true).Times(3)
+				Expect(str.handleStreamFrame(&wire.StreamFrame{Data: []byte("foobarbaz!")})).To(Succeed())
+				Expect(str.handleResetStreamAtFrame(rst)).To(Succeed())
+				Expect(str.handleResetStreamAtFrame(&wire.ResetStreamAtFrame{StreamID: streamID, FinalSize: 20, ReliableSize: 3, ErrorCode: 1234})).To(Succeed())
+				Expect(str.handleResetStreamAtFrame(&wire.ResetStreamAtFrame{StreamID: streamID, FinalSize: 20, ReliableSize: 5, ErrorCode: 1234})).To(Succeed())
+				mockFC.EXPECT().AddBytesRead(protocol.ByteCount(3))
+				mockFC.EXPECT().Abandon()
+				mockSender.EXPECT().onStreamCompleted(streamID)
+				b := make([]byte, 100)
+				n, err := strWithTimeout.Read(b)
+				Expect(err).To(MatchError(&StreamError{StreamID: streamID, ErrorCode: 1234}))
+				Expect(b[:n]).To(Equal([]byte("foo")))
+			})
+
+			It("handles a RESET_STREAM_AT with a reliable size of 0 like a RESET_STREAM", func() {
+				gomock.InOrder(
+					mockFC.EXPECT().UpdateHighestReceived(protocol.ByteCount(20), true),
+					mockFC.EXPECT().Abandon(),
+					mockSender.EXPECT().onStreamCompleted(streamID),
+				)
+				Expect(str.handleResetStreamAtFrame(&wire.ResetStreamAtFrame{StreamID: streamID, FinalSize: 20, ErrorCode: 1234})).To(Succeed())
+				_, err := strWithTimeout.Read([]byte{0})
+				Expect(err).To(MatchError(&StreamError{StreamID: streamID, ErrorCode: 1234}))
+			})
+
+			It("ignores RESET_STREAM_AT frames after a RESET_STREAM", func() {
+				mockFC.EXPECT().UpdateHighestReceived(protocol.ByteCount(20), true).Times(2)
+				mockFC.EXPECT().Abandon()
+				Expect(str.handleResetStreamFrame(&wire.ResetStreamFrame{StreamID: streamID, FinalSize: 20, ErrorCode: 1234})).To(Succeed())
+				Expect(str.handleResetStreamAtFrame(rst)).To(Succeed())
+			})
+
+			It("errors when receiving a RESET_STREAM_AT with an inconsistent offset", func() {
+				testErr := errors.New("already received a different final offset before")
+				mockFC.EXPECT().UpdateHighestReceived(protocol.ByteCount(20), true).Return(testErr)
+				Expect(str.handleResetStreamAtFrame(rst)).To(MatchError(testErr))
+			})
+		})
+	})
+
+	Context("checking the stream type", func() {
+		var streamTypes []uint64
+
+		BeforeEach(func() {
+			streamTypes = nil
+			str.setStreamTypeCheck(func(streamType uint64) bool {
+				streamTypes = append(streamTypes, streamType)
+				return streamType != 0x21
+			}, 0x103)
+		})
+
+		It("accepts the stream once the stream type was received", func() {
+			checked, accept := str.streamTypeChecked()
+			Expect(checked).ToNot(BeNil())
+			Expect(accept).To(BeFalse())
+			// 0x4042 is a 2 byte varint (0x42), split across two STREAM frames
+			mockFC.EXPECT().UpdateHighestReceived(protocol.ByteCount(1), false)
+			Expect(str.handleStreamFrame(&wire.StreamFrame{Data: []byte{0x40}})).To(Succeed())
+			Expect(checked).ToNot(BeClosed())
+			Expect(streamTypes).To(BeEmpty())
+			mockFC.EXPECT().UpdateHighestReceived(protocol.ByteCount(5), false)
+			Expect(str.handleStreamFrame(&wire.StreamFrame{Offset: 1, Data: []byte{0x42, 'f', 'o', 'o'}})).To(Succeed())
+			Expect(checked).To(BeClosed())
+			Expect(streamTypes).To(Equal([]uint64{0x42}))
+			checked, accept = str.streamTypeChecked()
+			Expect(checked).To(BeNil())
+			Expect(accept).To(BeTrue())
+			// the stream type is not consumed
+			mockFC.EXPECT().AddBytesRead(gomock.Any()).AnyTimes()
+			b := make([]byte, 5)
+			_, err := io.ReadFull(strWithTimeout, b)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(b).To(Equal([]byte{0x40, 0x42, 'f', 'o', 'o'}))
+		})
+
+		It("rejects the stream", func() {
+			mockFC.EXPECT().UpdateHighestReceived(protocol.ByteCount(4), false)
+			mockSender.EXPECT().queueControlFrame(&wire.StopSendingFrame{StreamID: streamID, ErrorCode: 0x103})
+			Expect(str.handleStreamFrame(&wire.StreamFrame{Data: []byte{0x21, 'f', 'o', 'o'}})).To(Succeed())
+			checked, accept := str.streamTypeChecked()
+			Expect(checked).To(BeNil())
+			Expect(accept).To(BeFalse())
+			// data received after that is discarded, and the stream is completed once the final size is known
+			gomock.InOrder(
+				mockFC.EXPECT().UpdateHighestReceived(protocol.ByteCount(10), true),
+				mockFC.EXPECT().Abandon(),
+				mockSender.EXPECT().onStreamCompleted(streamID),
+			)
+			Expect(str.handleStreamFrame(&wire.StreamFrame{Offset: 4, Data: []byte("barbaz"), Fin: true})).To(Succeed())
+			Expect(streamTypes).To(Equal([]uint64{0x21}))
+		})
+
+		It("accepts the stream if it is reset before the stream type was received", func() {
+			checked, _ := str.streamTypeChecked()
+			mockFC.EXPECT().UpdateHighestReceived(protocol.ByteCount(1), false)
+			Expect(str.handleStreamFrame(&wire.StreamFrame{Data: []byte{0x80}})).To(Succeed())
+			mockFC.EXPECT().UpdateHighestReceived(protocol.ByteCount(3), true)
+			mockFC.EXPECT().Abandon()
+			mockSender.EXPECT().onStreamCompleted(streamID).MaxTimes(1)
+			Expect(str.handleResetStreamFrame(&wire.ResetStreamFrame{StreamID: streamID, FinalSize: 3, ErrorCode: 42})).To(Succeed())
+			Expect(checked).To(BeClosed())
+			_, accept := str.streamTypeChecked()
+			Expect(accept).To(BeTrue())
+			Expect(streamTypes).To(BeEmpty())
+		})
+
+		It("accepts the stream if it ends before the stream type was received", func() {
+			checked, _ := str.streamTypeChecked()
+			mockFC.EXPECT().UpdateHighestReceived(protocol.ByteCount(1), true)
+			Expect(str.handleStreamFrame(&wire.StreamFrame{Data: []byte{0x40}, Fin: true})).To(Succeed())
+			Expect(checked).To(BeClosed())
+			_, accept := str.streamTypeChecked()
+			Expect(accept).To(BeTrue())
+			Expect(streamTypes).To(BeEmpty())
+		})
 	})
 
 	Context("flow control", func() {
@@ -721,5 +1177,10 @@ var _ = Describe("Receive Stream", func() {
 			mockFC.EXPECT().GetWindowUpdate().Return(protocol.ByteCount(0x100))
 			Expect(str.getWindowUpdate()).To(Equal(protocol.ByteCount(0x100)))
 		})
+
+		It("sets the receive window", func() {
+			mockFC.EXPECT().SetReceiveWindowSize(protocol.ByteCount(1 << 20))
+			str.SetReceiveWindow(1 << 20)
+		})
 	})
 })
@@ -37,6 +37,19 @@ var _ = Describe("Connection (for sending packets)", func() {
 		Expect(c.LocalAddr().String()).To(Equal("127.0.0.42:1234"))
 	})
 
+	It("sends to a different remote address using the same socket", func() {
+		rawConn := NewMockRawConn(mockCtrl)
+		rawConn.EXPECT().LocalAddr().Times(2)
+		rawConn.EXPECT().capabilities().AnyTimes()
+		c := newSendConn(rawConn, remoteAddr, packetInfo{}, utils.DefaultLogger)
+		newRemoteAddr := &net.UDPAddr{IP: net.IPv4(192, 168, 100, 201), Port: 4242}
+		c2 := c.withRemoteAddr(newRemoteAddr, packetInfo{})
+		Expect(c2.RemoteAddr()).To(Equal(newRemoteAddr))
+		rawConn.EXPECT().WritePacket([]byte("foobar"), newRemoteAddr, gomock.Any(), uint16(0), protocol.ECNNon)
+		Expect(c2.Write([]byte("foobar"), 0, protocol.ECNNon)).To(Succeed())
+		Expect(c.RemoteAddr()).To(Equal(remoteAddr))
+	})
+
 	// We're not using an OOB conn on windows, and packetInfo.OOB() always returns an empty slice.
 	if runtime.GOOS != "windows" {
 		It("sets the OOB", func() {
@@ -1,9 +1,12 @@
 package quic
 
 import (
+	"cmp"
 	"context"
 	"fmt"
+	"slices"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/quic-go/quic-go/internal/ackhandler"
@@ -37,8 +40,22 @@ type sendStream struct {
 
 	writeOffset protocol.ByteCount
 
+	// All data below ackedOffset has been acknowledged by the peer.
+	ackedOffset protocol.ByteCount
+	// bytesAcked mirrors ackedOffset, such that BytesAcked can be called without acquiring the mutex.
+	bytesAcked atomic.Int64
+	// ackedRanges are the acknowledged byte ranges above ackedOffset, sorted by offset.
+	ackedRanges []byteRange
+	// ackChan is closed when ackedOffset is increased, or when the stream is canceled.
+	// It is created lazily by WaitForAck.
+	ackChan chan struct{}
+
 	cancelWriteErr      error
 	closeForShutdownErr error
+	closeThenResetErr   error // returned from Write after CloseThenReset was called
+	// Set when the stream was reset using ResetAt, and reset to 0 when the stream is canceled using a RESET_STREAM.
+	// Data up to this offset is still sent (and retransmitted).
+	reliableSize protocol.ByteCount
 
 	finishedWriting bool // set once Close() is called
 	finSent         bool // set when a STREAM_FRAME with FIN bit has been sent
@@ -54,10 +71,23 @@ type sendStream struct {
 	writeChan chan struct{}
 	writeOnce chan struct{}
 	deadline  time.Time
+	clock     utils.Clock // used to evaluate the deadline
+	// writeBufferLimit is the maximum number of bytes that were written, but not yet acknowledged.
+	// 0 means no limit.
+	writeBufferLimit protocol.ByteCount
+
+	safeWriteMutex   sync.Mutex
+	safeWriteActive  bool            // set while a SafeWrite call is writing
+	safeWriteWaiters []chan struct{} // SafeWrite calls waiting for their turn, in call order
 
 	flowController flowcontrol.StreamFlowController
 }
 
+// byteRange is the byte range [start, end) of a stream.
+type byteRange struct {
+	start, end protocol.ByteCount
+}
+
 var (
 	_ SendStream  = &sendStream{}
 	_ sendStreamI = &sendStream{}
@@ -68,11 +98,13 @@ func newSendStream(
 	streamID protocol.StreamID,
 	sender streamSender,
 	flowController flowcontrol.StreamFlowController,
+	clock utils.Clock,
 ) *sendStream {
 	s := &sendStream{
 		streamID:       streamID,
 		sender:         sender,
 		flowController: flowController,
+		clock:          clock,
 		writeChan:      make(chan struct{}, 1),
 		writeOnce:      make(chan struct{}, 1), // cap: 1, to protect against concurrent use of Write
 	}
@@ -98,11 +130,44 @@ func (s *sendStream) Write(p []byte) (int, error) {
 	return n, err
 }
 
+func (s *sendStream) SafeWrite(p []byte) (int, error) {
+	s.safeWriteMutex.Lock()
+	if s.safeWriteActive {
+		turn := make(chan struct{})
+		s.safeWriteWaiters = append(s.safeWriteWaiters, turn)
+		s.safeWriteMutex.Unlock()
+		<-turn
+	} else {
+		s.safeWriteActive = true
+		s.safeWriteMutex.Unlock()
+	}
+	defer s.nextSafeWrite()
+
+	return s.Write(p)
+}
+
+// nextSafeWrite hands over to the SafeWrite call that has been waiting the longest.
+func (s *sendStream) nextSafeWrite() {
+	s.safeWriteMutex.Lock()
+	defer s.safeWriteMutex.Unlock()
+
+	if len(s.safeWriteWaiters) == 0 {
+		s.safeWriteActive = false
+		return
+	}
+	close(s.safeWriteWaiters[0])
+	s.safeWriteWaiters[0] = nil
+	s.safeWriteWaiters = s.safeWriteWaiters[1:]
+}
+
 func (s *sendStream) write(p []byte) (bool /* is newly completed */, int, error) {
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
 
 	if s.finishedWriting {
+		if s.closeThenResetErr != nil {
+			return false, 0, s.closeThenResetErr
+		}
 		return false, 0, fmt.Errorf("write on closed stream %d", s.streamID)
 	}
 	if s.cancelWriteErr != nil {
@@ -112,13 +177,98 @@ func (s *sendStream) write(p []byte) (bool /* is newly completed */, int, error)
 	if s.closeForShutdownErr != nil {
 		return false, 0, s.closeForShutdownErr
 	}
-	if !s.deadline.IsZero() && !time.Now().Before(s.deadline) {
+	if !s.deadline.IsZero() && !s.clock.Now().Before(s.deadline) {
 		return false, 0, errDeadline
 	}
 	if len(p) == 0 {
 		return false, 0, nil
 	}
+	if s.writeBufferLimit == 0 {
+		return s.writeLocked(p)
+	}
+
+	var bytesWritten int
+	for bytesWritten < len(p) {
+		isNewlyCompleted, err := s.waitForWriteBuffer()
+		if err != nil {
+			return isNewlyCompleted, bytesWritten, err
+		}
+		// The limit might have been removed while we were waiting.
+		chunk := p[bytesWritten:]
+		if s.writeBufferLimit > 0 {
+			if available := s.writeBufferLimit - s.bufferedBytes(); protocol.ByteCount(len(chunk)) > available {
+				chunk = chunk[:available]
+			}
+		}
+		isNewlyCompleted, n, err := s.writeLocked(chunk)
+		bytesWritten += n
+		if err != nil {
+			return isNewlyCompleted, bytesWritten, err
+		}
+	}
+	return false, bytesWritten, nil
+}
+
+// bufferedBytes returns the number of bytes that were written, but not yet acknowledged.
+// It must be called with the mutex held.
+func (s *sendStream) bufferedBytes() protocol.ByteCount {
+	written := s.writeOffset
+	if s.nextFrame != nil {
+		written += s.nextFrame.DataLen()
+	}
+	return written - s.ackedOffset
+}
 
+// waitForWriteBuffer blocks until the number of buffered bytes is below the write buffer limit.
+// It must be called with the mutex held.
+func (s *sendStream) waitForWriteBuffer() (bool /* is newly completed */, error) {
+	var deadlineTimer *utils.Timer
+	defer func() {
+		if deadlineTimer != nil {
+			deadlineTimer.Stop()
+		}
+	}()
+	for {
+		if s.cancelWriteErr != nil {
+			s.cancellationFlagged = true
+			return s.isNewlyCompleted(), s.cancelWriteErr
+		}
+		if s.closeForShutdownErr != nil {
+			return false, s.closeForShutdownErr
+		}
+		if s.writeBufferLimit == 0 || s.bufferedBytes() < s.writeBufferLimit {
+			return false, nil
+		}
+		deadline := s.deadline
+		if !deadline.IsZero() {
+			if !s.clock.Now().Before(deadline) {
+				return false, errDeadline
+			}
+			if deadlineTimer == nil {
+				deadlineTimer = utils.NewTimerWithClock(s.clock)
+			}
+			deadlineTimer.Reset(deadline)
+		} else if deadlineTimer != nil {
+			deadlineTimer.Reset(time.Time{})
+		}
+
+		s.mutex.Unlock()
+		if deadline.IsZero() {
+			<-s.writeChan
+		} else {
+			select {
+			case <-s.writeChan:
+			case <-deadlineTimer.Chan():
+				deadlineTimer.SetRead()
+			}
+		}
+		s.mutex.Lock()
+	}
+}
+
+// writeLocked writes p, blocking until all but a small amount of data has been sent out.
+// It must be called with the mutex held.
+func (s *sendStream) writeLocked(p []byte) (bool /* is newly completed */, int, error) {
 	s.dataForWriting = p
 
 	var (
@@ -134,7 +284,7 @@ func (s *sendStream) write(p []byte) (bool /* is newly completed */, int, error)
 		// This allows us to return Write() when all data but x bytes have been sent out.
 		// When the user now calls Close(), this is much more likely to happen before we popped that last STREAM frame,
 		// allowing us to set the FIN bit on that frame (instead of sending an empty STREAM frame with FIN).
-		if s.canBufferStreamFrame() && len(s.dataForWriting) > 0 {
+		if s.cancelWriteErr == nil && s.canBufferStreamFrame() && len(s.dataForWriting) > 0 {
 			if s.nextFrame == nil {
 				f := wire.GetStreamFrame()
 				f.Offset = s.writeOffset
@@ -155,15 +305,18 @@ func (s *sendStream) write(p []byte) (bool /* is newly completed */, int, error)
 			bytesWritten = len(p) - len(s.dataForWriting)
 			deadline = s.deadline
 			if !deadline.IsZero() {
-				if !time.Now().Before(deadline) {
+				if !s.clock.Now().Before(deadline) {
 					s.dataForWriting = nil
 					return false, bytesWritten, errDeadline
 				}
 				if deadlineTimer == nil {
-					deadlineTimer = utils.NewTimer()
+					deadlineTimer = utils.NewTimerWithClock(s.clock)
 					defer deadlineTimer.Stop()
 				}
 				deadlineTimer.Reset(deadline)
+			} else if deadlineTimer != nil {
+				// the deadline was cleared, make sure that a previously set deadline doesn't fire
+				deadlineTimer.Reset(time.Time{})
 			}
 			if s.dataForWriting == nil || s.cancelWriteErr != nil || s.closeForShutdownErr != nil {
 				break
@@ -231,7 +384,7 @@ func (s *sendStream) popStreamFrame(maxBytes protocol.ByteCount, v protocol.Vers
 }
 
 func (s *sendStream) popNewOrRetransmittedStreamFrame(maxBytes protocol.ByteCount, v protocol.Version) (*wire.StreamFrame, bool /* has more data to send */) {
-	if s.cancelWriteErr != nil || s.closeForShutdownErr != nil {
+	if (s.cancelWriteErr != nil && s.reliableSize == 0) || s.closeForShutdownErr != nil {
 		return nil, false
 	}
 
@@ -247,6 +400,11 @@ func (s *sendStream) popNewOrRetransmittedStreamFrame(maxBytes protocol.ByteCoun
 		}
 	}
 
+	// After a reliable reset, only the data up to the reliable size is sent, without a FIN.
+	if s.reliableSize > 0 && s.nextFrame == nil {
+		return nil, false
+	}
+
 	if len(s.dataForWriting) == 0 && s.nextFrame == nil {
 		if s.finishedWriting && !s.finSent {
 			s.finSent = true
@@ -277,7 +435,7 @@ func (s *sendStream) popNewOrRetransmittedStreamFrame(maxBytes protocol.ByteCoun
 		s.writeOffset += f.DataLen()
 		s.flowController.AddBytesSent(f.DataLen())
 	}
-	f.Fin = s.finishedWriting && s.dataForWriting == nil && s.nextFrame == nil && !s.finSent
+	f.Fin = s.finishedWriting && s.dataForWriting == nil && s.nextFrame == nil && !s.finSent && s.reliableSize == 0
 	if f.Fin {
 		s.finSent = true
 	}
@@ -370,6 +528,10 @@ func (s *sendStream) isNewlyCompleted() bool {
 	if s.numOutstandingFrames > 0 || len(s.retransmissionQueue) > 0 {
 		return false
 	}
+	// After a reliable reset, we also need to send all data up to the reliable size.
+	if s.reliableSize > 0 && s.nextFrame != nil {
+		return false
+	}
 	// The stream is completed if we sent the FIN.
 	if s.finSent {
 		s.completed = true
@@ -413,6 +575,25 @@ func (s *sendStream) Close() error {
 	return nil
 }
 
+// closeWrite is like Close, but it is a no-op if the stream was already closed or canceled.
+func (s *sendStream) closeWrite() {
+	s.mutex.Lock()
+	done := s.finishedWriting || s.cancelWriteErr != nil || s.closeForShutdownErr != nil
+	s.mutex.Unlock()
+	if !done {
+		s.Close()
+	}
+}
+
+func (s *sendStream) CloseThenReset(errorCode StreamErrorCode) error {
+	s.mutex.Lock()
+	if !s.finishedWriting && s.cancelWriteErr == nil && s.closeForShutdownErr == nil {
+		s.closeThenResetErr = &StreamError{StreamID: s.streamID, ErrorCode: errorCode, Remote: false}
+	}
+	s.mutex.Unlock()
+	return s.Close()
+}
+
 func (s *sendStream) CancelWrite(errorCode StreamErrorCode) {
 	s.cancelWriteImpl(errorCode, false)
 }
@@ -422,12 +603,22 @@ func (s *sendStream) cancelWriteImpl(errorCode qerr.StreamErrorCode, remote bool
 	if !remote {
 		s.cancellationFlagged = true
 	}
+	finalSize := s.writeOffset
 	if s.cancelWriteErr != nil {
-		s.mutex.Unlock()
-		return
-	}
-	s.cancelWriteErr = &StreamError{StreamID: s.streamID, ErrorCode: errorCode, Remote: remote}
-	s.ctxCancel(s.cancelWriteErr)
+		// After a reliable reset, the stream can still be reset without delivering the remaining data.
+		if s.reliableSize == 0 {
+			s.mutex.Unlock()
+			return
+		}
+		// The RESET_STREAM frame needs to use the same error code and final size as the RESET_STREAM_AT frame.
+		errorCode = s.cancelWriteErr.(*StreamError).ErrorCode
+		finalSize = max(s.writeOffset, s.reliableSize)
+		s.reliableSize = 0
+	} else {
+		s.cancelWriteErr = &StreamError{StreamID: s.streamID, ErrorCode: errorCode, Remote: remote}
+		s.ctxCancel(s.cancelWriteErr)
+	}
+	s.signalAckWaiters()
 	s.numOutstandingFrames = 0
 	s.retransmissionQueue = nil
 	newlyCompleted := s.isNewlyCompleted()
@@ -436,7 +627,7 @@ func (s *sendStream) cancelWriteImpl(errorCode qerr.StreamErrorCode, remote bool
 	s.signalWrite()
 	s.sender.queueControlFrame(&wire.ResetStreamFrame{
 		StreamID:  s.streamID,
-		FinalSize: s.writeOffset,
+		FinalSize: finalSize,
 		ErrorCode: errorCode,
 	})
 	if newlyCompleted {
@@ -444,6 +635,79 @@ func (s *sendStream) cancelWriteImpl(errorCode qerr.StreamErrorCode, remote bool
 	}
 }
 
+func (s *sendStream) ResetAt(errorCode StreamErrorCode, reliableOffset protocol.ByteCount) {
+	if !s.sender.supportsResetStreamAt() {
+		s.CancelWrite(errorCode)
+		return
+	}
+
+	s.mutex.Lock()
+	s.cancellationFlagged = true
+	if s.cancelWriteErr != nil || s.closeForShutdownErr != nil {
+		s.mutex.Unlock()
+		return
+	}
+	written := s.writeOffset
+	if s.nextFrame != nil {
+		written += s.nextFrame.DataLen()
+	}
+	reliableOffset = min(reliableOffset, written)
+	if reliableOffset == 0 {
+		s.mutex.Unlock()
+		s.CancelWrite(errorCode)
+		return
+	}
+	s.cancelWriteErr = &StreamError{StreamID: s.streamID, ErrorCode: errorCode, Remote: false}
+	s.ctxCancel(s.cancelWriteErr)
+	s.reliableSize = reliableOffset
+	s.signalAckWaiters()
+	// drop all data beyond the reliable size
+	if s.nextFrame != nil && !s.truncateToReliableSize(s.nextFrame) {
+		s.nextFrame.PutBack()
+		s.nextFrame = nil
+	}
+	retransmissionQueue := s.retransmissionQueue[:0]
+	for _, f := range s.retransmissionQueue {
+		if s.truncateToReliableSize(f) {
+			retransmissionQueue = append(retransmissionQueue, f)
+		} else {
+			f.PutBack()
+		}
+	}
+	s.retransmissionQueue = retransmissionQueue
+	hasStreamData := s.nextFrame != nil || len(s.retransmissionQueue) > 0
+	newlyCompleted := s.isNewlyCompleted()
+	finalSize := max(s.writeOffset, reliableOffset)
+	s.mutex.Unlock()
+
+	s.signalWrite()
+	s.sender.queueControlFrame(&wire.ResetStreamAtFrame{
+		StreamID:     s.streamID,
+		ErrorCode:    errorCode,
+		FinalSize:    finalSize,
+		ReliableSize: reliableOffset,
+	})
+	if hasStreamData {
+		s.sender.onHasStreamData(s.streamID)
+	}
+	if newlyCompleted {
+		s.sender.onStreamCompleted(s.streamID)
+	}
+}
+
+// truncateToReliableSize removes all data beyond the reliable size from a STREAM frame.
+// It returns false if the frame doesn't contain any data below the reliable size.
+func (s *sendStream) truncateToReliableSize(f *wire.StreamFrame) bool {
+	if f.Offset >= s.reliableSize {
+		return false
+	}
+	if f.Offset+f.DataLen() > s.reliableSize {
+		f.Data = f.Data[:s.reliableSize-f.Offset]
+		f.Fin = false
+	}
+	return true
+}
+
 func (s *sendStream) updateSendWindow(limit protocol.ByteCount) {
 	updated := s.flowController.UpdateSendWindow(limit)
 	if !updated { // duplicate or reordered MAX_STREAM_DATA frame
@@ -473,12 +737,127 @@ func (s *sendStream) SetWriteDeadline(t time.Time) error {
 	return nil
 }
 
+func (s *sendStream) SetWriteBufferLimit(limit protocol.ByteCount) {
+	s.mutex.Lock()
+	s.writeBufferLimit = limit
+	s.mutex.Unlock()
+	s.signalWrite()
+}
+
+func (s *sendStream) SetPriority(p StreamPriority) {
+	if s.isCompleted() {
+		return
+	}
+	s.sender.onStreamPriorityChanged(s.streamID, p)
+	// If the stream completed concurrently, the framer might already have removed it.
+	// Reset the priority, so that the framer doesn't keep it around.
+	if s.isCompleted() && p != DefaultStreamPriority {
+		s.sender.onStreamPriorityChanged(s.streamID, DefaultStreamPriority)
+	}
+}
+
+func (s *sendStream) isCompleted() bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.completed
+}
+
+func (s *sendStream) WaitForAck(ctx context.Context, offset protocol.ByteCount) error {
+	s.mutex.Lock()
+	for {
+		if s.ackedOffset >= offset {
+			s.mutex.Unlock()
+			return nil
+		}
+		if s.closeForShutdownErr != nil {
+			s.mutex.Unlock()
+			return s.closeForShutdownErr
+		}
+		// After a reliable reset, data up to the reliable size is still delivered.
+		if s.cancelWriteErr != nil && offset > s.reliableSize {
+			s.mutex.Unlock()
+			return s.cancelWriteErr
+		}
+		if s.finishedWriting {
+			written := s.writeOffset
+			if s.nextFrame != nil {
+				written += s.nextFrame.DataLen()
+			}
+			if offset > written {
+				s.mutex.Unlock()
+				return fmt.Errorf("offset %d is larger than the final size of stream %d (%d)", offset, s.streamID, written)
+			}
+		}
+		if s.ackChan == nil {
+			s.ackChan = make(chan struct{})
+		}
+		ackChan := s.ackChan
+		s.mutex.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ackChan:
+		}
+		s.mutex.Lock()
+	}
+}
+
+func (s *sendStream) BytesAcked() protocol.ByteCount {
+	return protocol.ByteCount(s.bytesAcked.Load())
+}
+
+// onDataAcked records that the stream data in the range [start, end) was acknowledged.
+// It must be called with the mutex held.
+func (s *sendStream) onDataAcked(start, end protocol.ByteCount) {
+	if end <= s.ackedOffset {
+		return
+	}
+	if start > s.ackedOffset {
+		i, _ := slices.BinarySearchFunc(s.ackedRanges, start, func(r byteRange, start protocol.ByteCount) int {
+			return cmp.Compare(r.start, start)
+		})
+		s.ackedRanges = slices.Insert(s.ackedRanges, i, byteRange{start: start, end: end})
+		// merge overlapping and adjacent ranges
+		merged := s.ackedRanges[:1]
+		for _, r := range s.ackedRanges[1:] {
+			if last := &merged[len(merged)-1]; r.start <= last.end {
+				last.end = max(last.end, r.end)
+				continue
+			}
+			merged = append(merged, r)
+		}
+		s.ackedRanges = merged
+		return
+	}
+	s.ackedOffset = end
+	for len(s.ackedRanges) > 0 && s.ackedRanges[0].start <= s.ackedOffset {
+		s.ackedOffset = max(s.ackedOffset, s.ackedRanges[0].end)
+		s.ackedRanges = s.ackedRanges[1:]
+	}
+	s.bytesAcked.Store(int64(s.ackedOffset))
+	s.signalAckWaiters()
+	if s.writeBufferLimit > 0 {
+		s.signalWrite()
+	}
+}
+
+// signalAckWaiters unblocks all calls to WaitForAck.
+// It must be called with the mutex held.
+func (s *sendStream) signalAckWaiters() {
+	if s.ackChan != nil {
+		close(s.ackChan)
+		s.ackChan = nil
+	}
+}
+
 // CloseForShutdown closes a stream abruptly.
 // It makes Write unblock (and return the error) immediately.
 // The peer will NOT be informed about this: the stream is closed without sending a FIN or RST.
 func (s *sendStream) closeForShutdown(err error) {
 	s.mutex.Lock()
 	s.closeForShutdownErr = err
+	s.signalAckWaiters()
 	s.mutex.Unlock()
 	s.signalWrite()
 }
@@ -497,12 +876,14 @@ var _ ackhandler.FrameHandler = &sendStreamAckHandler{}
 
 func (s *sendStreamAckHandler) OnAcked(f wire.Frame) {
 	sf := f.(*wire.StreamFrame)
+	start, end := sf.Offset, sf.Offset+sf.DataLen()
 	sf.PutBack()
 	s.mutex.Lock()
-	if s.cancelWriteErr != nil {
+	if s.cancelWriteErr != nil && s.reliableSize == 0 {
 		s.mutex.Unlock()
 		return
 	}
+	(*sendStream)(s).onDataAcked(start, end)
 	s.numOutstandingFrames--
 	if s.numOutstandingFrames < 0 {
 		panic("numOutStandingFrames negative")
@@ -518,16 +899,27 @@ func (s *sendStreamAckHandler) OnAcked(f wire.Frame) {
 func (s *sendStreamAckHandler) OnLost(f wire.Frame) {
 	sf := f.(*wire.StreamFrame)
 	s.mutex.Lock()
-	if s.cancelWriteErr != nil {
+	if s.cancelWriteErr != nil && s.reliableSize == 0 {
 		s.mutex.Unlock()
 		return
 	}
-	sf.DataLenPresent = true
-	s.retransmissionQueue = append(s.retransmissionQueue, sf)
 	s.numOutstandingFrames--
 	if s.numOutstandingFrames < 0 {
 		panic("numOutStandingFrames negative")
 	}
+	// after a reliable reset, data beyond the reliable size is not retransmitted
+	if s.reliableSize > 0 && !(*sendStream)(s).truncateToReliableSize(sf) {
+		sf.PutBack()
+		newlyCompleted := (*sendStream)(s).isNewlyCompleted()
+		s.mutex.Unlock()
+
+		if newlyCompleted {
+			s.sender.onStreamCompleted(s.streamID)
+		}
+		return
+	}
+	sf.DataLenPresent = true
+	s.retransmissionQueue = append(s.retransmissionQueue, sf)
 	s.mutex.Unlock()
 
 	s.sender.onHasStreamData(s.streamID)
@@ -14,6 +14,7 @@ import (
 	"github.com/quic-go/quic-go/internal/ackhandler"
 	"github.com/quic-go/quic-go/internal/mocks"
 	"github.com/quic-go/quic-go/internal/protocol"
+	"github.com/quic-go/quic-go/internal/utils"
 	"github.com/quic-go/quic-go/internal/wire"
 
 	. "github.com/onsi/ginkgo/v2"
@@ -35,7 +36,7 @@ var _ = Describe("Send Stream", func() {
 	BeforeEach(func() {
 		mockSender = NewMockStreamSender(mockCtrl)
 		mockFC = mocks.NewMockStreamFlowController(mockCtrl)
-		str = newSendStream(context.Background(), streamID, mockSender, mockFC)
+		str = newSendStream(context.Background(), streamID, mockSender, mockFC, utils.DefaultClock{})
 
 		timeout := scaleDuration(250 * time.Millisecond)
 		strWithTimeout = gbytes.TimeoutWriter(str, timeout)
@@ -74,6 +75,30 @@ var _ = Describe("Send Stream", func() {
 		Expect(str.StreamID()).To(Equal(protocol.StreamID(1337)))
 	})
 
+	It("sets the priority", func() {
+		p := StreamPriority{Urgency: 1, Incremental: true}
+		mockSender.EXPECT().onStreamPriorityChanged(streamID, p)
+		str.SetPriority(p)
+		// the priority of completed streams doesn't matter anymore
+		mockSender.EXPECT().queueControlFrame(gomock.Any())
+		mockSender.EXPECT().onStreamCompleted(streamID)
+		str.CancelWrite(1234)
+		str.SetPriority(StreamPriority{Urgency: 2})
+	})
+
+	It("resets the priority if the stream completes while the priority is set", func() {
+		p := StreamPriority{Urgency: 1, Incremental: true}
+		gomock.InOrder(
+			mockSender.EXPECT().onStreamPriorityChanged(streamID, p).Do(func(protocol.StreamID, StreamPriority) {
+				mockSender.EXPECT().queueControlFrame(gomock.Any())
+				mockSender.EXPECT().onStreamCompleted(streamID)
+				str.CancelWrite(1234)
+			}),
+			mockSender.EXPECT().onStreamPriorityChanged(streamID, DefaultStreamPriority),
+		)
+		str.SetPriority(p)
+	})
+
 	Context("writing", func() {
 		It("writes and gets all data at once", func() {
 			done := make(chan struct{})
@@ -383,6 +408,124 @@ var _ = Describe("Send Stream", func() {
 			})
 		})
 
+		Context("write buffer limit", func() {
+			ackFrame := func(f ackhandler.StreamFrame) {
+				f.Handler.OnAcked(f.Frame)
+			}
+
+			It("blocks Write at the limit and unblocks as data is acknowledged", func() {
+				str.SetWriteBufferLimit(100)
+				mockSender.EXPECT().onHasStreamData(streamID).AnyTimes()
+				mockFC.EXPECT().SendWindowSize().Return(protocol.MaxByteCount).AnyTimes()
+				mockFC.EXPECT().AddBytesSent(gomock.Any()).AnyTimes()
+				done := make(chan struct{})
+				go func() {
+					defer GinkgoRecover()
+					defer close(done)
+					n, err := str.Write(getData(250))
+					Expect(err).ToNot(HaveOccurred())
+					Expect(n).To(Equal(250))
+				}()
+				waitForWrite()
+				f1, ok, _ := str.popStreamFrame(protocol.MaxByteCount, protocol.Version1)
+				Expect(ok).To(BeTrue())
+				Expect(f1.Frame.DataLen()).To(BeEquivalentTo(100))
+				// the data was sent, but it's not acknowledged yet
+				Consistently(done).ShouldNot(BeClosed())
+				_, ok, _ = str.popStreamFrame(protocol.MaxByteCount, protocol.Version1)
+				Expect(ok).To(BeFalse())
+				ackFrame(f1)
+				waitForWrite()
+				f2, ok, _ := str.popStreamFrame(protocol.MaxByteCount, protocol.Version1)
+				Expect(ok).To(BeTrue())
+				Expect(f2.Frame.Offset).To(BeEquivalentTo(100))
+				Expect(f2.Frame.DataLen()).To(BeEquivalentTo(100))
+				Consistently(done).ShouldNot(BeClosed())
+				ackFrame(f2)
+				Eventually(done).Should(BeClosed())
+				f3, ok, _ := str.popStreamFrame(protocol.MaxByteCount, protocol.Version1)
+				Expect(ok).To(BeTrue())
+				Expect(f3.Frame.Offset).To(BeEquivalentTo(200))
+				Expect(f3.Frame.Data).To(Equal(getData(250)[200:]))
+			})
+
+			It("counts data blocked by flow control", func() {
+				str.SetWriteBufferLimit(10)
+				mockSender.EXPECT().onHasStreamData(streamID).AnyTimes()
+				mockFC.EXPECT().SendWindowSize().Return(protocol.ByteCount(0))
+				mockFC.EXPECT().IsNewlyBlocked()
+				done := make(chan struct{})
+				go func() {
+					defer GinkgoRecover()
+					defer close(done)
+					n, err := str.Write(getData(20))
+					Expect(err).ToNot(HaveOccurred())
+					Expect(n).To(Equal(20))
+				}()
+				waitForWrite()
+				_, ok, _ := str.popStreamFrame(protocol.MaxByteCount, protocol.Version1)
+				Expect(ok).To(BeFalse())
+				Consistently(done).ShouldNot(BeClosed())
+				mockFC.EXPECT().SendWindowSize().Return(protocol.ByteCount(1000))
+				mockFC.EXPECT().AddBytesSent(protocol.ByteCount(10))
+				f, ok, _ := str.popStreamFrame(protocol.MaxByteCount, protocol.Version1)
+				Expect(ok).To(BeTrue())
+				Expect(f.Frame.DataLen()).To(BeEquivalentTo(10))
+				Consistently(done).ShouldNot(BeClosed())
+				ackFrame(f)
+				Eventually(done).Should(BeClosed())
+			})
+
+			It("unblocks when the limit is removed", func() {
+				str.SetWriteBufferLimit(10)
+				mockSender.EXPECT().onHasStreamData(streamID).AnyTimes()
+				done := make(chan struct{})
+				go func() {
+					defer GinkgoRecover()
+					defer close(done)
+					n, err := str.Write(getData(20))
+					Expect(err).ToNot(HaveOccurred())
+					Expect(n).To(Equal(20))
+				}()
+				waitForWrite()
+				Consistently(done).ShouldNot(BeClosed())
+				str.SetWriteBufferLimit(0)
+				Eventually(done).Should(BeClosed())
+				Expect(str.nextFrame.Data).To(Equal(getData(20)))
+			})
+
+			It("returns the number of bytes written, when the deadline expires", func() {
+				str.SetWriteBufferLimit(10)
+				mockSender.EXPECT().onHasStreamData(streamID)
+				deadline := time.Now().Add(scaleDuration(50 * time.Millisecond))
+				str.SetWriteDeadline(deadline)
+				n, err := strWithTimeout.Write(getData(20))
+				Expect(err).To(MatchError(errDeadline))
+				Expect(n).To(Equal(10))
+				Expect(time.Now()).To(BeTemporally("~", deadline, scaleDuration(20*time.Millisecond)))
+			})
+
+			It("unblocks when the stream is canceled", func() {
+				str.SetWriteBufferLimit(10)
+				mockSender.EXPECT().onHasStreamData(streamID)
+				errChan := make(chan error, 1)
+				go func() {
+					_, err := str.Write(getData(20))
+					errChan <- err
+				}()
+				waitForWrite()
+				Consistently(errChan).ShouldNot(Receive())
+				mockSender.EXPECT().queueControlFrame(gomock.Any())
+				mockSender.EXPECT().onStreamCompleted(streamID)
+				str.CancelWrite(1234)
+				var err error
+				Eventually(errChan).Should(Receive(&err))
+				var streamErr *StreamError
+				Expect(errors.As(err, &streamErr)).To(BeTrue())
+				Expect(streamErr.ErrorCode).To(BeEquivalentTo(1234))
+			})
+		})
+
 		Context("deadlines", func() {
 			It("returns an error when Write is called after the deadline", func() {
 				str.SetWriteDeadline(time.Now().Add(-time.Second))
@@ -508,6 +651,36 @@ var _ = Describe("Send Stream", func() {
 				Eventually(done).Should(BeClosed())
 			})
 
+			It("writes after a blocked Write was interrupted and the deadline was cleared", func() {
+				mockSender.EXPECT().onHasStreamData(streamID).Times(2)
+				str.SetWriteDeadline(time.Now().Add(time.Hour))
+				errChan := make(chan error, 1)
+				go func() {
+					defer GinkgoRecover()
+					_, err := str.Write(getData(5000))
+					errChan <- err
+				}()
+				Consistently(errChan).ShouldNot(Receive())
+				str.SetWriteDeadline(time.Now().Add(-time.Hour))
+				Eventually(errChan).Should(Receive(MatchError(errDeadline)))
+
+				str.SetWriteDeadline(time.Time{})
+				done := make(chan struct{})
+				go func() {
+					defer GinkgoRecover()
+					defer close(done)
+					n, err := str.Write([]byte("foobar"))
+					Expect(err).ToNot(HaveOccurred())
+					Expect(n).To(Equal(6))
+				}()
+				Eventually(done).Should(BeClosed())
+				mockFC.EXPECT().SendWindowSize().Return(protocol.MaxByteCount)
+				mockFC.EXPECT().AddBytesSent(protocol.ByteCount(6))
+				frame, ok, _ := str.popStreamFrame(protocol.MaxByteCount, protocol.Version1)
+				Expect(ok).To(BeTrue())
+				Expect(frame.Frame.Data).To(Equal([]byte("foobar")))
+			})
+
 			It("doesn't unblock if the deadline is removed", func() {
 				mockSender.EXPECT().onHasStreamData(streamID)
 				deadline := time.Now().Add(scaleDuration(50 * time.Millisecond))
@@ -558,6 +731,49 @@ var _ = Describe("Send Stream", func() {
 				Expect(hasMoreData).To(BeFalse())
 			})
 
+			It("delivers buffered data before the FIN when closing, then resetting", func() {
+				const frameHeaderLen protocol.ByteCount = 4
+				mockSender.EXPECT().onHasStreamData(streamID).Times(2)
+				_, err := strWithTimeout.Write([]byte("foobar"))
+				Expect(err).ToNot(HaveOccurred())
+				// no RESET_STREAM frame is queued
+				Expect(str.CloseThenReset(1234)).To(Succeed())
+				mockFC.EXPECT().SendWindowSize().Return(protocol.MaxByteCount).Times(2)
+				mockFC.EXPECT().AddBytesSent(protocol.ByteCount(3)).Times(2)
+				frame, ok, _ := str.popStreamFrame(3+frameHeaderLen, protocol.Version1)
+				Expect(ok).To(BeTrue())
+				Expect(frame.Frame.Data).To(Equal([]byte("foo")))
+				Expect(frame.Frame.Fin).To(BeFalse())
+				frame, ok, _ = str.popStreamFrame(protocol.MaxByteCount, protocol.Version1)
+				Expect(ok).To(BeTrue())
+				Expect(frame.Frame.Offset).To(Equal(protocol.ByteCount(3)))
+				Expect(frame.Frame.Data).To(Equal([]byte("bar")))
+				Expect(frame.Frame.Fin).To(BeTrue())
+				Expect(str.Context().Done()).To(BeClosed())
+			})
+
+			It("returns the error code for writes after closing, then resetting", func() {
+				mockSender.EXPECT().onHasStreamData(streamID)
+				Expect(str.CloseThenReset(1234)).To(Succeed())
+				_, err := strWithTimeout.Write([]byte("foobar"))
+				Expect(err).To(Equal(&StreamError{StreamID: streamID, ErrorCode: 1234, Remote: false}))
+			})
+
+			It("doesn't change the error when closing, then resetting an already closed stream", func() {
+				mockSender.EXPECT().onHasStreamData(streamID).Times(2)
+				Expect(str.Close()).To(Succeed())
+				Expect(str.CloseThenReset(1234)).To(Succeed())
+				_, err := strWithTimeout.Write([]byte("foobar"))
+				Expect(err).To(MatchError("write on closed stream 1337"))
+			})
+
+			It("errors when closing, then resetting a canceled stream", func() {
+				mockSender.EXPECT().queueControlFrame(gomock.Any())
+				mockSender.EXPECT().onStreamCompleted(streamID)
+				str.CancelWrite(1234)
+				Expect(str.CloseThenReset(42)).To(MatchError("close called for canceled stream 1337"))
+			})
+
 			It("doesn't send a FIN when there's still data", func() {
 				const frameHeaderLen protocol.ByteCount = 4
 				mockSender.EXPECT().onHasStreamData(streamID).Times(2)
@@ -668,6 +884,98 @@ var _ = Describe("Send Stream", func() {
 		})
 	})
 
+	Context("concurrent writes", func() {
+		BeforeEach(func() {
+			mockSender.EXPECT().onHasStreamData(streamID).AnyTimes()
+			mockFC.EXPECT().SendWindowSize().Return(protocol.MaxByteCount).AnyTimes()
+			mockFC.EXPECT().AddBytesSent(gomock.Any()).AnyTimes()
+		})
+
+		// popData pops STREAM frames until n bytes were received
+		popData := func(n int) []byte {
+			var data []byte
+			for len(data) < n {
+				frame, ok, _ := str.popStreamFrame(1000, protocol.Version1)
+				if !ok {
+					runtime.Gosched()
+					continue
+				}
+				data = append(data, frame.Frame.Data...)
+			}
+			return data
+		}
+
+		It("serializes calls to SafeWrite in call order", func() {
+			const numWriters = 5
+			first := getData(5000)
+			expected := append([]byte{}, first...)
+			done := make(chan struct{}, numWriters+1)
+			go func() {
+				defer GinkgoRecover()
+				n, err := str.SafeWrite(first)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(n).To(Equal(len(first)))
+				done <- struct{}{}
+			}()
+			waitForWrite()
+			for i := 0; i < numWriters; i++ {
+				data := bytes.Repeat([]byte{byte('a' + i)}, 100+i)
+				expected = append(expected, data...)
+				go func() {
+					defer GinkgoRecover()
+					n, err := str.SafeWrite(data)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(n).To(Equal(len(data)))
+					done <- struct{}{}
+				}()
+				// wait until this writer is queued, such that the call order is well-defined
+				Eventually(func() int {
+					str.safeWriteMutex.Lock()
+					defer str.safeWriteMutex.Unlock()
+					return len(str.safeWriteWaiters)
+				}).Should(Equal(i + 1))
+			}
+			Expect(popData(len(expected))).To(Equal(expected))
+			for i := 0; i < numWriters+1; i++ {
+				Eventually(done).Should(Receive())
+			}
+			str.safeWriteMutex.Lock()
+			defer str.safeWriteMutex.Unlock()
+			Expect(str.safeWriteActive).To(BeFalse())
+			Expect(str.safeWriteWaiters).To(BeEmpty())
+		})
+
+		It("doesn't interleave the data of concurrent calls to SafeWrite", func() {
+			const (
+				numWriters = 10
+				numWrites  = 50
+				recordLen  = 500
+			)
+			for i := 0; i < numWriters; i++ {
+				go func(writer byte) {
+					defer GinkgoRecover()
+					for j := 0; j < numWrites; j++ {
+						record := bytes.Repeat([]byte{writer}, recordLen)
+						record[0] = byte(j)
+						_, err := str.SafeWrite(record)
+						Expect(err).ToNot(HaveOccurred())
+					}
+				}(byte(i))
+			}
+			data := popData(numWriters * numWrites * recordLen)
+			Expect(data).To(HaveLen(numWriters * numWrites * recordLen))
+			next := make([]byte, numWriters) // the next record number expected from every writer
+			for len(data) > 0 {
+				record := data[:recordLen]
+				data = data[recordLen:]
+				writer := record[1]
+				Expect(record[1:]).To(Equal(bytes.Repeat([]byte{writer}, recordLen-1)))
+				Expect(record[0]).To(Equal(next[writer]))
+				next[writer]++
+			}
+		})
+	})
+
 	Context("handling MAX_STREAM_DATA frames", func() {
 		It("informs the flow controller", func() {
 			mockFC.EXPECT().UpdateSendWindow(protocol.ByteCount(0x1337))
@@ -895,6 +1203,173 @@ var _ = Describe("Send Stream", func() {
 			})
 		})
 
+		Context("resetting reliably", func() {
+			It("falls back to a RESET_STREAM frame if the peer doesn't support reliable resets", func() {
+				mockSender.EXPECT().supportsResetStreamAt().Return(false)
+				gomock.InOrder(
+					mockSender.EXPECT().queueControlFrame(&wire.ResetStreamFrame{
+						StreamID:  streamID,
+						FinalSize: 1234,
+						ErrorCode: 9876,
+					}),
+					mockSender.EXPECT().onStreamCompleted(streamID),
+				)
+				str.writeOffset = 1234
+				str.ResetAt(9876, 1000)
+			})
+
+			It("sends a RESET_STREAM frame if the reliable offset is 0", func() {
+				mockSender.EXPECT().supportsResetStreamAt().Return(true)
+				gomock.InOrder(
+					mockSender.EXPECT().queueControlFrame(&wire.ResetStreamFrame{
+						StreamID:  streamID,
+						FinalSize: 1234,
+						ErrorCode: 9876,
+					}),
+					mockSender.EXPECT().onStreamCompleted(streamID),
+				)
+				str.writeOffset = 1234
+				str.ResetAt(9876, 0)
+			})
+
+			It("queues a RESET_STREAM_AT frame and sends the data up to the reliable offset", func() {
+				mockSender.EXPECT().onHasStreamData(streamID)
+				_, err := strWithTimeout.Write(getData(100))
+				Expect(err).ToNot(HaveOccurred())
+				mockFC.EXPECT().SendWindowSize().Return(protocol.MaxByteCount).Times(2)
+				mockFC.EXPECT().AddBytesSent(protocol.ByteCount(40))
+				frame1, ok, _ := str.popStreamFrame(expectedFrameHeaderLen(0)+40, protocol.Version1)
+				Expect(ok).To(BeTrue())
+				Expect(frame1.Frame.Data).To(Equal(getData(40)))
+
+				mockSender.EXPECT().supportsResetStreamAt().Return(true)
+				gomock.InOrder(
+					mockSender.EXPECT().queueControlFrame(&wire.ResetStreamAtFrame{
+						StreamID:     streamID,
+						ErrorCode:    1337,
+						FinalSize:    60,
+						ReliableSize: 60,
+					}),
+					mockSender.EXPECT().onHasStreamData(streamID),
+				)
+				str.ResetAt(1337, 60)
+				Expect(str.Context().Done()).To(BeClosed())
+				_, err = strWithTimeout.Write([]byte("foobar"))
+				Expect(err).To(MatchError(&StreamError{StreamID: streamID, ErrorCode: 1337}))
+
+				mockFC.EXPECT().AddBytesSent(protocol.ByteCount(20))
+				frame2, ok, hasMoreData := str.popStreamFrame(protocol.MaxByteCount, protocol.Version1)
+				Expect(ok).To(BeTrue())
+				Expect(hasMoreData).To(BeFalse())
+				Expect(frame2.Frame.Offset).To(Equal(protocol.ByteCount(40)))
+				Expect(frame2.Frame.Data).To(Equal(getDataAtOffset(40, 20)))
+				Expect(frame2.Frame.Fin).To(BeFalse())
+				_, ok, _ = str.popStreamFrame(protocol.MaxByteCount, protocol.Version1)
+				Expect(ok).To(BeFalse())
+
+				// the stream is completed once all data up to the reliable offset has been acknowledged
+				frame1.Handler.OnAcked(frame1.Frame)
+				mockSender.EXPECT().onStreamCompleted(streamID)
+				frame2.Handler.OnAcked(frame2.Frame)
+			})
+
+			It("reduces the reliable offset to the amount of data written", func() {
+				mockSender.EXPECT().onHasStreamData(streamID).Times(2)
+				_, err := strWithTimeout.Write(getData(10))
+				Expect(err).ToNot(HaveOccurred())
+				mockSender.EXPECT().supportsResetStreamAt().Return(true)
+				mockSender.EXPECT().queueControlFrame(&wire.ResetStreamAtFrame{
+					StreamID:     streamID,
+					ErrorCode:    1337,
+					FinalSize:    10,
+					ReliableSize: 10,
+				})
+				str.ResetAt(1337, 1000)
+			})
+
+			It("doesn't send a FIN after a reliable reset", func() {
+				mockSender.EXPECT().onHasStreamData(streamID).Times(3)
+				_, err := strWithTimeout.Write(getData(100))
+				Expect(err).ToNot(HaveOccurred())
+				Expect(str.Close()).To(Succeed())
+				mockSender.EXPECT().supportsResetStreamAt().Return(true)
+				mockSender.EXPECT().queueControlFrame(gomock.Any())
+				str.ResetAt(1337, 50)
+				mockFC.EXPECT().SendWindowSize().Return(protocol.MaxByteCount)
+				mockFC.EXPECT().AddBytesSent(protocol.ByteCount(50))
+				frame, ok, hasMoreData := str.popStreamFrame(protocol.MaxByteCount, protocol.Version1)
+				Expect(ok).To(BeTrue())
+				Expect(hasMoreData).To(BeFalse())
+				Expect(frame.Frame.Data).To(Equal(getData(50)))
+				Expect(frame.Frame.Fin).To(BeFalse())
+				_, ok, _ = str.popStreamFrame(protocol.MaxByteCount, protocol.Version1)
+				Expect(ok).To(BeFalse())
+			})
+
+			It("only retransmits data up to the reliable offset", func() {
+				mockSender.EXPECT().onHasStreamData(streamID)
+				_, err := strWithTimeout.Write(getData(100))
+				Expect(err).ToNot(HaveOccurred())
+				mockFC.EXPECT().SendWindowSize().Return(protocol.MaxByteCount).Times(2)
+				mockFC.EXPECT().AddBytesSent(protocol.ByteCount(30))
+				mockFC.EXPECT().AddBytesSent(protocol.ByteCount(70))
+				frame1, ok, _ := str.popStreamFrame(expectedFrameHeaderLen(0)+30, protocol.Version1)
+				Expect(ok).To(BeTrue())
+				frame2, ok, _ := str.popStreamFrame(protocol.MaxByteCount, protocol.Version1)
+				Expect(ok).To(BeTrue())
+				Expect(frame2.Frame.Offset).To(Equal(protocol.ByteCount(30)))
+
+				mockSender.EXPECT().supportsResetStreamAt().Return(true)
+				mockSender.EXPECT().queueControlFrame(&wire.ResetStreamAtFrame{
+					StreamID:     streamID,
+					ErrorCode:    1337,
+					FinalSize:    100,
+					ReliableSize: 20,
+				})
+				str.ResetAt(1337, 20)
+				// the second frame is beyond the reliable offset, and is not retransmitted
+				frame2.Handler.OnLost(frame2.Frame)
+				mockSender.EXPECT().onHasStreamData(streamID)
+				frame1.Handler.OnLost(frame1.Frame)
+				frame, ok, _ := str.popStreamFrame(protocol.MaxByteCount, protocol.Version1)
+				Expect(ok).To(BeTrue())
+				Expect(frame.Frame.Offset).To(BeZero())
+				Expect(frame.Frame.Data).To(Equal(getData(20)))
+				_, ok, _ = str.popStreamFrame(protocol.MaxByteCount, protocol.Version1)
+				Expect(ok).To(BeFalse())
+				mockSender.EXPECT().onStreamCompleted(streamID)
+				frame.Handler.OnAcked(frame.Frame)
+			})
+
+			It("sends a RESET_STREAM frame when CancelWrite is called after ResetAt", func() {
+				mockSender.EXPECT().onHasStreamData(streamID).Times(2)
+				_, err := strWithTimeout.Write(getData(100))
+				Expect(err).ToNot(HaveOccurred())
+				mockSender.EXPECT().supportsResetStreamAt().Return(true)
+				mockSender.EXPECT().queueControlFrame(&wire.ResetStreamAtFrame{
+					StreamID:     streamID,
+					ErrorCode:    1337,
+					FinalSize:    50,
+					ReliableSize: 50,
+				})
+				str.ResetAt(1337, 50)
+				gomock.InOrder(
+					mockSender.EXPECT().queueControlFrame(&wire.ResetStreamFrame{
+						StreamID:  streamID,
+						ErrorCode: 1337,
+						FinalSize: 50,
+					}),
+					mockSender.EXPECT().onStreamCompleted(streamID),
+				)
+				str.CancelWrite(42)
+				_, ok, hasMoreData := str.popStreamFrame(protocol.MaxByteCount, protocol.Version1)
+				Expect(ok).To(BeFalse())
+				Expect(hasMoreData).To(BeFalse())
+				// further calls are ignored
+				str.CancelWrite(42)
+			})
+		})
+
 		Context("receiving STOP_SENDING frames", func() {
 			It("queues a RESET_STREAM frames, and copies the error code from the STOP_SENDING frame", func() {
 				mockSender.EXPECT().queueControlFrame(&wire.ResetStreamFrame{
@@ -1100,6 +1575,98 @@ var _ = Describe("Send Stream", func() {
 		})
 	})
 
+	Context("waiting for acknowledgments", func() {
+		ackData := func(offset protocol.ByteCount, data []byte) {
+			str.mutex.Lock()
+			str.numOutstandingFrames++
+			str.mutex.Unlock()
+			(*sendStreamAckHandler)(str).OnAcked(&wire.StreamFrame{Offset: offset, Data: data})
+		}
+
+		It("returns immediately if the data was already acknowledged", func() {
+			Expect(str.WaitForAck(context.Background(), 0)).To(Succeed())
+			ackData(0, make([]byte, 10))
+			Expect(str.WaitForAck(context.Background(), 10)).To(Succeed())
+		})
+
+		It("blocks until the data is acknowledged, in case acknowledgments arrive out of order", func() {
+			done := make(chan struct{})
+			go func() {
+				defer GinkgoRecover()
+				defer close(done)
+				Expect(str.WaitForAck(context.Background(), 30)).To(Succeed())
+			}()
+			ackData(20, make([]byte, 10))
+			ackData(10, make([]byte, 5))
+			Consistently(done).ShouldNot(BeClosed())
+			ackData(0, make([]byte, 10))
+			Consistently(done).ShouldNot(BeClosed())
+			ackData(15, make([]byte, 5))
+			Eventually(done).Should(BeClosed())
+		})
+
+		It("merges acknowledged ranges", func() {
+			ackData(50, make([]byte, 10))
+			ackData(20, make([]byte, 10))
+			ackData(30, make([]byte, 5))
+			ackData(25, make([]byte, 20))
+			Expect(str.ackedRanges).To(Equal([]byteRange{{start: 20, end: 45}, {start: 50, end: 60}}))
+			ackData(0, make([]byte, 20))
+			Expect(str.ackedOffset).To(BeEquivalentTo(45))
+			Expect(str.ackedRanges).To(Equal([]byteRange{{start: 50, end: 60}}))
+		})
+
+		It("reports the number of bytes acknowledged", func() {
+			Expect(str.BytesAcked()).To(BeZero())
+			ackData(0, make([]byte, 10))
+			Expect(str.BytesAcked()).To(BeEquivalentTo(10))
+			// data acknowledged out of order is counted once the gap is filled
+			ackData(20, make([]byte, 10))
+			Expect(str.BytesAcked()).To(BeEquivalentTo(10))
+			ackData(10, make([]byte, 10))
+			Expect(str.BytesAcked()).To(BeEquivalentTo(30))
+		})
+
+		It("stops waiting when the context is canceled", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			errChan := make(chan error, 1)
+			go func() { errChan <- str.WaitForAck(ctx, 10) }()
+			Consistently(errChan).ShouldNot(Receive())
+			cancel()
+			Eventually(errChan).Should(Receive(MatchError(context.Canceled)))
+		})
+
+		It("returns an error when the stream is canceled", func() {
+			errChan := make(chan error, 1)
+			go func() { errChan <- str.WaitForAck(context.Background(), 10) }()
+			Consistently(errChan).ShouldNot(Receive())
+			mockSender.EXPECT().queueControlFrame(gomock.Any())
+			mockSender.EXPECT().onStreamCompleted(streamID)
+			str.CancelWrite(1234)
+			var err error
+			Eventually(errChan).Should(Receive(&err))
+			var streamErr *StreamError
+			Expect(errors.As(err, &streamErr)).To(BeTrue())
+			Expect(streamErr.ErrorCode).To(BeEquivalentTo(1234))
+		})
+
+		It("returns an error when the connection is closed", func() {
+			errChan := make(chan error, 1)
+			go func() { errChan <- str.WaitForAck(context.Background(), 10) }()
+			Consistently(errChan).ShouldNot(Receive())
+			testErr := errors.New("test error")
+			str.closeForShutdown(testErr)
+			Eventually(errChan).Should(Receive(MatchError(testErr)))
+		})
+
+		It("returns an error when waiting for data beyond the final size", func() {
+			mockSender.EXPECT().onHasStreamData(streamID)
+			Expect(str.Close()).To(Succeed())
+			Expect(str.WaitForAck(context.Background(), 1)).To(MatchError("offset 1 is larger than the final size of stream 1337 (0)"))
+			Expect(str.WaitForAck(context.Background(), 0)).To(Succeed())
+		})
+	})
+
 	Context("determining when a stream is completed", func() {
 		BeforeEach(func() {
 			mockFC.EXPECT().SendWindowSize().Return(protocol.MaxByteCount).AnyTimes()
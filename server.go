@@ -15,14 +15,18 @@ import (
 	"github.com/quic-go/quic-go/internal/utils"
 	"github.com/quic-go/quic-go/internal/wire"
 	"github.com/quic-go/quic-go/logging"
+
+	"golang.org/x/time/rate"
 )
 
-// ErrServerClosed is returned by the Listener or EarlyListener's Accept method after a call to Close.
+// ErrServerClosed is returned by the Listener or EarlyListener's Accept method after a call to Close or Drain.
 var ErrServerClosed = errors.New("quic: server closed")
 
 // packetHandler handles packets
 type packetHandler interface {
 	handlePacket(receivedPacket)
+	// acceptsGreasedQUICBit says if packets with an unset QUIC bit (RFC 9287) are passed to the handler.
+	acceptsGreasedQUICBit() bool
 	destroy(error)
 	closeWithTransportError(qerr.TransportErrorCode)
 }
@@ -39,6 +43,7 @@ type quicConn interface {
 	EarlyConnection
 	earlyConnReady() <-chan struct{}
 	handlePacket(receivedPacket)
+	acceptsGreasedQUICBit() bool
 	run() error
 	destroy(error)
 	closeWithTransportError(TransportErrorCode)
@@ -66,10 +71,21 @@ type baseServer struct {
 
 	tokenGenerator *handshake.TokenGenerator
 	maxTokenAge    time.Duration
+	// used to determine the age of tokens
+	clock utils.Clock
+	// If set, used to generate and validate Retry tokens.
+	retryTokenGenerator RetryTokenGenerator
 
 	connIDGenerator ConnectionIDGenerator
 	connHandler     packetHandlerManager
 	onClose         func()
+	// called when a new connection is started, and when it is closed
+	onNewConn    func()
+	onConnClosed func()
+	// tracks the connections created by this server, so they can be drained
+	conns sync.WaitGroup
+	// tracks the goroutines waiting for the handshake of a connection to complete, before queueing it for Accept
+	handshakingConns sync.WaitGroup
 
 	receivedPackets chan receivedPacket
 
@@ -104,6 +120,9 @@ type baseServer struct {
 	errorChan chan struct{} // is closed when the server is closed
 	closeErr  error
 	running   chan struct{} // closed as soon as run() returns
+	// only set while the server is being drained, closed when Close is called
+	drainAborted  chan struct{}
+	onCloseCalled sync.Once
 
 	versionNegotiationQueue chan receivedPacket
 	invalidTokenQueue       chan rejectedPacket
@@ -111,6 +130,12 @@ type baseServer struct {
 	retryQueue              chan rejectedPacket
 
 	verifySourceAddress func(net.Addr) bool
+	acceptFilter        func(net.Addr, *wire.Header) bool
+	// admitConn decides if a new connection can be accepted, see Transport.MaxConnections.
+	// It is nil if the number of connections is not limited.
+	admitConn func(net.Addr) bool
+	// only set if Config.MaxHandshakeRate is set
+	handshakeLimiter *rate.Limiter
 
 	connQueue chan quicConn
 
@@ -140,6 +165,18 @@ func (l *Listener) Close() error {
 	return l.baseServer.Close()
 }
 
+// Drain gracefully shuts down the listener.
+// Accept returns ErrServerClosed immediately, and new connection attempts are not accepted anymore.
+// QUIC handshakes that are still in flight, as well as connections in the accept queue, are rejected
+// with a CONNECTION_REFUSED error.
+// Already established connections are unaffected. Drain blocks until all of them are closed,
+// or until the context is canceled, in which case the context's error is returned.
+// After that, the listener is closed just like it would by Close.
+// Calling Close while Drain is blocked closes the listener immediately.
+func (l *Listener) Drain(ctx context.Context) error {
+	return l.baseServer.Drain(ctx)
+}
+
 // Addr returns the local network address that the server is listening on.
 func (l *Listener) Addr() net.Addr {
 	return l.baseServer.Addr()
@@ -165,6 +202,12 @@ func (l *EarlyListener) Close() error {
 	return l.baseServer.Close()
 }
 
+// Drain gracefully shuts down the listener.
+// It works like Listener.Drain.
+func (l *EarlyListener) Drain(ctx context.Context) error {
+	return l.baseServer.Drain(ctx)
+}
+
 // Addr returns the local network addr that the server is listening on.
 func (l *EarlyListener) Addr() net.Addr {
 	return l.baseServer.Addr()
@@ -239,9 +282,14 @@ func newServer(
 	config *Config,
 	tracer *logging.Tracer,
 	onClose func(),
+	onNewConn func(),
+	onConnClosed func(),
 	tokenGeneratorKey TokenGeneratorKey,
 	maxTokenAge time.Duration,
+	retryTokenGenerator RetryTokenGenerator,
 	verifySourceAddress func(net.Addr) bool,
+	acceptFilter func(net.Addr, *wire.Header) bool,
+	admitConn func(net.Addr) bool,
 	disableVersionNegotiation bool,
 	acceptEarly bool,
 ) *baseServer {
@@ -252,7 +300,10 @@ func newServer(
 		config:                    config,
 		tokenGenerator:            handshake.NewTokenGenerator(tokenGeneratorKey),
 		maxTokenAge:               maxTokenAge,
+		retryTokenGenerator:       retryTokenGenerator,
 		verifySourceAddress:       verifySourceAddress,
+		acceptFilter:              acceptFilter,
+		admitConn:                 admitConn,
 		connIDGenerator:           connIDGenerator,
 		connHandler:               connHandler,
 		connQueue:                 make(chan quicConn, protocol.MaxAcceptQueueSize),
@@ -269,10 +320,19 @@ func newServer(
 		acceptEarlyConns:          acceptEarly,
 		disableVersionNegotiation: disableVersionNegotiation,
 		onClose:                   onClose,
+		onNewConn:                 onNewConn,
+		onConnClosed:              onConnClosed,
+	}
+	s.clock = config.Clock
+	if s.clock == nil {
+		s.clock = utils.DefaultClock{}
 	}
 	if acceptEarly {
 		s.zeroRTTQueues = map[protocol.ConnectionID]*zeroRTTQueue{}
 	}
+	if config.MaxHandshakeRate > 0 {
+		s.handshakeLimiter = rate.NewLimiter(rate.Limit(config.MaxHandshakeRate), config.MaxHandshakeRate)
+	}
 	go s.run()
 	go s.runSendQueue()
 	s.logger.Debugf("Listening for %s connections on %s", conn.LocalAddr().Network(), conn.LocalAddr().String())
@@ -340,7 +400,14 @@ func (s *baseServer) Close() error {
 func (s *baseServer) close(e error, notifyOnClose bool) {
 	s.closeMx.Lock()
 	if s.closeErr != nil {
+		drainAborted := s.drainAborted
+		s.drainAborted = nil
 		s.closeMx.Unlock()
+		// Close was called while the server is being drained
+		if drainAborted != nil && notifyOnClose {
+			close(drainAborted)
+			s.onCloseCalled.Do(s.onClose)
+		}
 		return
 	}
 	s.closeErr = e
@@ -349,8 +416,55 @@ func (s *baseServer) close(e error, notifyOnClose bool) {
 	s.closeMx.Unlock()
 
 	if notifyOnClose {
-		s.onClose()
+		s.onCloseCalled.Do(s.onClose)
+	}
+}
+
+func (s *baseServer) Drain(ctx context.Context) error {
+	s.closeMx.Lock()
+	if s.closeErr != nil {
+		s.closeMx.Unlock()
+		return ErrServerClosed
+	}
+	s.closeErr = ErrServerClosed
+	drainAborted := make(chan struct{})
+	s.drainAborted = drainAborted
+	close(s.errorChan)
+	<-s.running
+	s.closeMx.Unlock()
+
+	// Since the run loop has returned, no new connections are added.
+	// Wait for connections that just completed the handshake to be queued,
+	// such that they are rejected below.
+	s.handshakingConns.Wait()
+	// Reject the connections that completed the handshake, but weren't accepted yet.
+	for done := false; !done; {
+		select {
+		case conn := <-s.connQueue:
+			conn.closeWithTransportError(ConnectionRefused)
+		default:
+			done = true
+		}
+	}
+
+	drained := make(chan struct{})
+	go func() {
+		s.conns.Wait()
+		close(drained)
+	}()
+	var err error
+	select {
+	case <-drained:
+	case <-drainAborted:
+		return ErrServerClosed
+	case <-ctx.Done():
+		err = ctx.Err()
 	}
+	s.closeMx.Lock()
+	s.drainAborted = nil
+	s.closeMx.Unlock()
+	s.onCloseCalled.Do(s.onClose)
+	return err
 }
 
 // Addr returns the server's network address
@@ -538,10 +652,10 @@ func (s *baseServer) validateToken(token *handshake.Token, addr net.Addr) bool {
 	if !token.ValidateRemoteAddr(addr) {
 		return false
 	}
-	if !token.IsRetryToken && time.Since(token.SentTime) > s.maxTokenAge {
+	if !token.IsRetryToken && s.clock.Now().Sub(token.SentTime) > s.maxTokenAge {
 		return false
 	}
-	if token.IsRetryToken && time.Since(token.SentTime) > s.config.maxRetryTokenAge() {
+	if token.IsRetryToken && s.clock.Now().Sub(token.SentTime) > s.config.maxRetryTokenAge() {
 		return false
 	}
 	return true
@@ -564,6 +678,16 @@ func (s *baseServer) handleInitialImpl(p receivedPacket, hdr *wire.Header) error
 		return nil
 	}
 
+	if s.acceptFilter != nil && !s.acceptFilter(p.remoteAddr, hdr) {
+		s.logger.Debugf("Dropping Initial packet from %s (%d bytes). Rejected by the accept filter.", p.remoteAddr, p.Size())
+		delete(s.zeroRTTQueues, hdr.DestConnectionID)
+		if s.tracer != nil && s.tracer.DroppedPacket != nil {
+			s.tracer.DroppedPacket(p.remoteAddr, logging.PacketTypeInitial, p.Size(), logging.PacketDropDOSPrevention)
+		}
+		p.buffer.Release()
+		return nil
+	}
+
 	var (
 		token              *handshake.Token
 		retrySrcConnID     *protocol.ConnectionID
@@ -578,6 +702,23 @@ func (s *baseServer) handleInitialImpl(p receivedPacket, hdr *wire.Header) error
 				retrySrcConnID = &tok.RetrySrcConnectionID
 			}
 			token = tok
+		} else if s.retryTokenGenerator != nil && s.retryTokenGenerator.IsRetryToken(hdr.Token) {
+			// The token wasn't issued by us, but it is a Retry token issued by the application.
+			// Any other token is ignored, and we act as if there was no token on this packet at all.
+			odcid, rscid, err := s.retryTokenGenerator.ValidateRetryToken(hdr.Token, p.remoteAddr)
+			if err != nil {
+				s.logger.Debugf("Invalid Retry token: %s", err)
+				select {
+				case s.invalidTokenQueue <- rejectedPacket{receivedPacket: p, hdr: hdr}:
+				default:
+					// drop packet if we can't send out the INVALID_TOKEN packets fast enough
+					p.buffer.Release()
+				}
+				return nil
+			}
+			origDestConnID = odcid
+			retrySrcConnID = &rscid
+			clientAddrVerified = true
 		}
 	}
 	if token != nil {
@@ -595,7 +736,7 @@ func (s *baseServer) handleInitialImpl(p receivedPacket, hdr *wire.Header) error
 				select {
 				case s.invalidTokenQueue <- rejectedPacket{receivedPacket: p, hdr: hdr}:
 				default:
-					// drop packet if we can't send out the  INVALID_TOKEN packets fast enough
+					// drop packet if we can't send out the INVALID_TOKEN packets fast enough
 					p.buffer.Release()
 				}
 				return nil
@@ -603,13 +744,38 @@ func (s *baseServer) handleInitialImpl(p receivedPacket, hdr *wire.Header) error
 		}
 	}
 
-	if token == nil && s.verifySourceAddress != nil && s.verifySourceAddress(p.remoteAddr) {
-		// Retry invalidates all 0-RTT packets sent.
+	if token == nil && !clientAddrVerified && s.verifySourceAddress != nil && s.verifySourceAddress(p.remoteAddr) {
+		s.queueRetry(p, hdr)
+		return nil
+	}
+
+	if s.handshakeLimiter != nil && !s.handshakeLimiter.AllowN(p.rcvTime, 1) {
+		switch s.config.HandshakeOverflowPolicy {
+		case HandshakeOverflowRetry:
+			// Connection attempts from validated addresses are accepted.
+			if !clientAddrVerified {
+				s.logger.Debugf("Sending Retry to %s. The handshake rate limit was exceeded.", p.remoteAddr)
+				s.queueRetry(p, hdr)
+				return nil
+			}
+		default:
+			s.logger.Debugf("Dropping Initial packet from %s (%d bytes). The handshake rate limit was exceeded.", p.remoteAddr, p.Size())
+			delete(s.zeroRTTQueues, hdr.DestConnectionID)
+			if s.tracer != nil && s.tracer.DroppedPacket != nil {
+				s.tracer.DroppedPacket(p.remoteAddr, logging.PacketTypeInitial, p.Size(), logging.PacketDropDOSPrevention)
+			}
+			p.buffer.Release()
+			return nil
+		}
+	}
+
+	if s.admitConn != nil && !s.admitConn(p.remoteAddr) {
+		s.logger.Debugf("Rejecting new connection from %s. The connection limit was reached.", p.remoteAddr)
 		delete(s.zeroRTTQueues, hdr.DestConnectionID)
 		select {
-		case s.retryQueue <- rejectedPacket{receivedPacket: p, hdr: hdr}:
+		case s.connectionRefusedQueue <- rejectedPacket{receivedPacket: p, hdr: hdr}:
 		default:
-			// drop packet if we can't send out Retry packets fast enough
+			// drop packet if we can't send out the CONNECTION_REFUSED fast enough
 			p.buffer.Release()
 		}
 		return nil
@@ -708,8 +874,16 @@ func (s *baseServer) handleInitialImpl(p receivedPacket, hdr *wire.Header) error
 		delete(s.zeroRTTQueues, hdr.DestConnectionID)
 	}
 
-	go conn.run()
+	s.onNewConn()
+	s.conns.Add(1)
+	go func() {
+		conn.run()
+		s.onConnClosed()
+		s.conns.Done()
+	}()
+	s.handshakingConns.Add(1)
 	go func() {
+		defer s.handshakingConns.Done()
 		if completed := s.handleNewConn(conn); !completed {
 			return
 		}
@@ -748,6 +922,17 @@ func (s *baseServer) handleNewConn(conn quicConn) bool {
 	}
 }
 
+func (s *baseServer) queueRetry(p receivedPacket, hdr *wire.Header) {
+	// Retry invalidates all 0-RTT packets sent.
+	delete(s.zeroRTTQueues, hdr.DestConnectionID)
+	select {
+	case s.retryQueue <- rejectedPacket{receivedPacket: p, hdr: hdr}:
+	default:
+		// drop packet if we can't send out Retry packets fast enough
+		p.buffer.Release()
+	}
+}
+
 func (s *baseServer) sendRetry(p rejectedPacket) {
 	if err := s.sendRetryPacket(p); err != nil {
 		s.logger.Debugf("Error sending Retry packet: %s", err)
@@ -763,7 +948,12 @@ func (s *baseServer) sendRetryPacket(p rejectedPacket) error {
 	if err != nil {
 		return err
 	}
-	token, err := s.tokenGenerator.NewRetryToken(p.remoteAddr, hdr.DestConnectionID, srcConnID)
+	var token []byte
+	if s.retryTokenGenerator != nil {
+		token, err = s.retryTokenGenerator.NewRetryToken(p.remoteAddr, hdr.DestConnectionID, srcConnID)
+	} else {
+		token, err = s.tokenGenerator.NewRetryToken(p.remoteAddr, hdr.DestConnectionID, srcConnID, s.clock.Now())
+	}
 	if err != nil {
 		return err
 	}
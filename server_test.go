@@ -1,10 +1,15 @@
 package quic
 
 import (
+	"bytes"
 	"context"
+	"crypto/hmac"
 	"crypto/rand"
+	"crypto/sha256"
 	"crypto/tls"
+	"encoding/binary"
 	"errors"
+	"fmt"
 	"net"
 	"sync"
 	"sync/atomic"
@@ -95,7 +100,7 @@ var _ = Describe("Server", func() {
 		Expect(err).ToNot(HaveOccurred())
 		data, err := opener.Open(nil, b[extHdr.ParsedLen():], extHdr.PacketNumber, b[:extHdr.ParsedLen()])
 		Expect(err).ToNot(HaveOccurred())
-		_, f, err := wire.NewFrameParser(false).ParseNext(data, protocol.EncryptionInitial, origHdr.Version)
+		_, f, err := wire.NewFrameParser(false, false, false).ParseNext(data, protocol.EncryptionInitial, origHdr.Version)
 		Expect(err).ToNot(HaveOccurred())
 		Expect(f).To(BeAssignableToTypeOf(&wire.ConnectionCloseFrame{}))
 		ccf := f.(*wire.ConnectionCloseFrame)
@@ -165,6 +170,16 @@ var _ = Describe("Server", func() {
 		Expect(ln.Close()).To(Succeed())
 	})
 
+	It("limits the handshake rate", func() {
+		ln, err := Listen(conn, tlsConf, &Config{MaxHandshakeRate: 42})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(ln.baseServer.handshakeLimiter).ToNot(BeNil())
+		Expect(ln.baseServer.handshakeLimiter.Limit()).To(Equal(rate.Limit(42)))
+		Expect(ln.baseServer.handshakeLimiter.Burst()).To(Equal(42))
+		// stop the listener
+		Expect(ln.Close()).To(Succeed())
+	})
+
 	It("listens on a given address", func() {
 		addr := "127.0.0.1:13579"
 		ln, err := ListenAddr(addr, tlsConf, &Config{})
@@ -268,6 +283,7 @@ var _ = Describe("Server", func() {
 					raddr,
 					protocol.ParseConnectionID([]byte{0xde, 0xad, 0xc0, 0xde}),
 					protocol.ParseConnectionID([]byte{0xde, 0xca, 0xfb, 0xad}),
+					time.Now(),
 				)
 				Expect(err).ToNot(HaveOccurred())
 				connID := protocol.ParseConnectionID([]byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10})
@@ -474,6 +490,56 @@ var _ = Describe("Server", func() {
 				Expect(called).To(BeTrue())
 			})
 
+			It("drops Initial packets rejected by the accept filter", func() {
+				connID := protocol.ParseConnectionID([]byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10})
+				raddr := &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 1337}
+				serv.acceptFilter = func(addr net.Addr, hdr *wire.Header) bool {
+					Expect(addr).To(Equal(raddr))
+					Expect(hdr.Type).To(Equal(protocol.PacketTypeInitial))
+					Expect(hdr.DestConnectionID).To(Equal(connID))
+					return false
+				}
+				serv.newConn = func(context.Context, context.CancelCauseFunc, sendConn, connRunner, protocol.ConnectionID, *protocol.ConnectionID, protocol.ConnectionID, protocol.ConnectionID, protocol.ConnectionID, ConnectionIDGenerator, protocol.StatelessResetToken, *Config, *tls.Config, *handshake.TokenGenerator, bool, *logging.ConnectionTracer, utils.Logger, protocol.Version) quicConn {
+					Fail("didn't expect a connection to be created")
+					return nil
+				}
+				packet := getPacket(&wire.Header{
+					Type:             protocol.PacketTypeInitial,
+					SrcConnectionID:  protocol.ParseConnectionID([]byte{5, 4, 3, 2, 1}),
+					DestConnectionID: connID,
+					Version:          protocol.Version1,
+				}, make([]byte, protocol.MinInitialPacketSize))
+				packet.remoteAddr = raddr
+				done := make(chan struct{})
+				phm.EXPECT().Get(connID)
+				tracer.EXPECT().DroppedPacket(raddr, logging.PacketTypeInitial, packet.Size(), logging.PacketDropDOSPrevention).Do(func(net.Addr, logging.PacketType, protocol.ByteCount, logging.PacketDropReason) {
+					close(done)
+				})
+				serv.handlePacket(packet)
+				Eventually(done).Should(BeClosed())
+				// make sure there are no Write calls on the packet conn
+				time.Sleep(50 * time.Millisecond)
+			})
+
+			It("doesn't apply the accept filter to packets for existing connections", func() {
+				serv.acceptFilter = func(net.Addr, *wire.Header) bool {
+					Fail("didn't expect the accept filter to be called")
+					return false
+				}
+				connID := protocol.ParseConnectionID([]byte{1, 2, 3, 4, 5, 6, 7, 8})
+				p := getPacket(&wire.Header{
+					Type:             protocol.PacketTypeInitial,
+					DestConnectionID: connID,
+					Version:          serv.config.Versions[0],
+				}, make([]byte, protocol.MinInitialPacketSize))
+				conn := NewMockPacketHandler(mockCtrl)
+				phm.EXPECT().Get(connID).Return(conn, true)
+				handled := make(chan struct{})
+				conn.EXPECT().handlePacket(p).Do(func(receivedPacket) { close(handled) })
+				serv.handlePacket(p)
+				Eventually(handled).Should(BeClosed())
+			})
+
 			It("creates a connection, if no token is required", func() {
 				connID := protocol.ParseConnectionID([]byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10})
 				hdr := &wire.Header{
@@ -722,6 +788,83 @@ var _ = Describe("Server", func() {
 				}
 				wg.Wait()
 			})
+
+			Context("limiting the handshake rate", func() {
+				const limit = 3
+
+				var (
+					conns   chan *MockQUICConn
+					started chan struct{}
+				)
+
+				BeforeEach(func() {
+					serv.handshakeLimiter = rate.NewLimiter(0, limit)
+					phm.EXPECT().Get(gomock.Any()).AnyTimes()
+					phm.EXPECT().GetStatelessResetToken(gomock.Any()).AnyTimes()
+					phm.EXPECT().AddWithConnID(gomock.Any(), gomock.Any(), gomock.Any()).Return(true).AnyTimes()
+
+					conns = make(chan *MockQUICConn, limit)
+					started = make(chan struct{}, limit)
+					for i := 0; i < limit; i++ {
+						conn := NewMockQUICConn(mockCtrl)
+						conn.EXPECT().handlePacket(gomock.Any())
+						conn.EXPECT().run().MaxTimes(1)
+						conn.EXPECT().Context().Return(context.Background()).MaxTimes(1)
+						conn.EXPECT().HandshakeComplete().Return(make(chan struct{})).MaxTimes(1)
+						conn.EXPECT().closeWithTransportError(ConnectionRefused).MaxTimes(1)
+						conns <- conn
+					}
+					serv.newConn = func(context.Context, context.CancelCauseFunc, sendConn, connRunner, protocol.ConnectionID, *protocol.ConnectionID, protocol.ConnectionID, protocol.ConnectionID, protocol.ConnectionID, ConnectionIDGenerator, protocol.StatelessResetToken, *Config, *tls.Config, *handshake.TokenGenerator, bool, *logging.ConnectionTracer, utils.Logger, protocol.Version) quicConn {
+						started <- struct{}{}
+						select {
+						case conn := <-conns:
+							return conn
+						default:
+							Fail("didn't expect a connection to be created")
+							return nil
+						}
+					}
+				})
+
+				It("drops Initial packets exceeding the handshake rate", func() {
+					const flood = 10
+					var dropped atomic.Int32
+					tracer.EXPECT().DroppedPacket(gomock.Any(), logging.PacketTypeInitial, gomock.Any(), logging.PacketDropDOSPrevention).Do(
+						func(net.Addr, logging.PacketType, protocol.ByteCount, logging.PacketDropReason) { dropped.Add(1) },
+					).Times(flood)
+					for i := 0; i < limit+flood; i++ {
+						serv.handlePacket(getInitialWithRandomDestConnID())
+					}
+					Eventually(func() int32 { return dropped.Load() }).Should(BeEquivalentTo(flood))
+					Expect(started).To(HaveLen(limit))
+				})
+
+				It("sends a Retry for Initial packets exceeding the handshake rate", func() {
+					serv.config.HandshakeOverflowPolicy = HandshakeOverflowRetry
+					for i := 0; i < limit; i++ {
+						serv.handlePacket(getInitialWithRandomDestConnID())
+					}
+					Eventually(started).Should(HaveLen(limit))
+
+					p := getInitialWithRandomDestConnID()
+					done := make(chan struct{})
+					tracer.EXPECT().SentPacket(p.remoteAddr, gomock.Any(), gomock.Any(), gomock.Any()).Do(func(_ net.Addr, replyHdr *logging.Header, _ logging.ByteCount, _ []logging.Frame) {
+						defer GinkgoRecover()
+						Expect(replyHdr.Type).To(Equal(protocol.PacketTypeRetry))
+					})
+					conn.EXPECT().WriteTo(gomock.Any(), p.remoteAddr).DoAndReturn(func(b []byte, _ net.Addr) (int, error) {
+						defer GinkgoRecover()
+						defer close(done)
+						hdr, _, _, err := wire.ParsePacket(b)
+						Expect(err).ToNot(HaveOccurred())
+						Expect(hdr.Type).To(Equal(protocol.PacketTypeRetry))
+						return len(b), nil
+					})
+					serv.handlePacket(p)
+					Eventually(done).Should(BeClosed())
+					Expect(started).To(HaveLen(limit))
+				})
+			})
 		})
 
 		Context("token validation", func() {
@@ -756,7 +899,7 @@ var _ = Describe("Server", func() {
 					return c
 				}
 				raddr := &net.UDPAddr{IP: net.IPv4(192, 168, 13, 37), Port: 1337}
-				token, err := serv.tokenGenerator.NewRetryToken(raddr, protocol.ConnectionID{}, protocol.ConnectionID{})
+				token, err := serv.tokenGenerator.NewRetryToken(raddr, protocol.ConnectionID{}, protocol.ConnectionID{}, time.Now())
 				Expect(err).ToNot(HaveOccurred())
 				packet := getPacket(&wire.Header{
 					Type:    protocol.PacketTypeInitial,
@@ -779,9 +922,180 @@ var _ = Describe("Server", func() {
 				Eventually(done).Should(BeClosed())
 			})
 
+			Context("using a custom Retry token generator", func() {
+				var gen *hmacRetryTokenGenerator
+
+				BeforeEach(func() {
+					gen = &hmacRetryTokenGenerator{key: []byte("secret"), ttl: time.Hour}
+					serv.retryTokenGenerator = gen
+					serv.verifySourceAddress = func(net.Addr) bool { return true }
+				})
+
+				It("encodes and decodes tokens", func() {
+					raddr := &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 1337}
+					odcid := protocol.ParseConnectionID([]byte{1, 2, 3, 4, 5, 6, 7, 8})
+					rscid := protocol.ParseConnectionID([]byte{0xde, 0xca, 0xfb, 0xad})
+					token, err := gen.NewRetryToken(raddr, odcid, rscid)
+					Expect(err).ToNot(HaveOccurred())
+					origDestConnID, retrySrcConnID, err := gen.ValidateRetryToken(token, raddr)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(origDestConnID).To(Equal(odcid))
+					Expect(retrySrcConnID).To(Equal(rscid))
+					_, _, err = gen.ValidateRetryToken(token, &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 1338})
+					Expect(err).To(MatchError("invalid token"))
+				})
+
+				It("sends a Retry packet with a token generated by the custom generator", func() {
+					connID := protocol.ParseConnectionID([]byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10})
+					raddr := &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 1337}
+					hdr := &wire.Header{
+						Type:             protocol.PacketTypeInitial,
+						SrcConnectionID:  protocol.ParseConnectionID([]byte{5, 4, 3, 2, 1}),
+						DestConnectionID: connID,
+						Version:          protocol.Version1,
+					}
+					packet := getPacket(hdr, make([]byte, protocol.MinInitialPacketSize))
+					packet.remoteAddr = raddr
+					tracer.EXPECT().SentPacket(packet.remoteAddr, gomock.Any(), gomock.Any(), nil)
+					done := make(chan struct{})
+					conn.EXPECT().WriteTo(gomock.Any(), raddr).DoAndReturn(func(b []byte, _ net.Addr) (int, error) {
+						defer close(done)
+						replyHdr := parseHeader(b)
+						Expect(replyHdr.Type).To(Equal(protocol.PacketTypeRetry))
+						origDestConnID, retrySrcConnID, err := gen.ValidateRetryToken(replyHdr.Token, raddr)
+						Expect(err).ToNot(HaveOccurred())
+						Expect(origDestConnID).To(Equal(connID))
+						Expect(retrySrcConnID).To(Equal(replyHdr.SrcConnectionID))
+						return len(b), nil
+					})
+					phm.EXPECT().Get(connID)
+					serv.handlePacket(packet)
+					Eventually(done).Should(BeClosed())
+				})
+
+				It("ignores tokens that are not Retry tokens", func() {
+					connID := protocol.ParseConnectionID([]byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10})
+					raddr := &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 1337}
+					hdr := &wire.Header{
+						Type:             protocol.PacketTypeInitial,
+						SrcConnectionID:  protocol.ParseConnectionID([]byte{5, 4, 3, 2, 1}),
+						DestConnectionID: connID,
+						Version:          protocol.Version1,
+						// e.g. a token issued in a NEW_TOKEN frame by a different server
+						Token: []byte("foobar"),
+					}
+					packet := getPacket(hdr, make([]byte, protocol.MinInitialPacketSize))
+					packet.remoteAddr = raddr
+					tracer.EXPECT().SentPacket(packet.remoteAddr, gomock.Any(), gomock.Any(), nil)
+					done := make(chan struct{})
+					// the packet is treated as if it didn't contain a token, so a Retry is sent
+					conn.EXPECT().WriteTo(gomock.Any(), raddr).DoAndReturn(func(b []byte, _ net.Addr) (int, error) {
+						defer close(done)
+						Expect(parseHeader(b).Type).To(Equal(protocol.PacketTypeRetry))
+						return len(b), nil
+					})
+					phm.EXPECT().Get(connID)
+					serv.handlePacket(packet)
+					Eventually(done).Should(BeClosed())
+				})
+
+				It("creates a connection when the token is accepted", func() {
+					raddr := &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 1337}
+					retryToken, err := gen.NewRetryToken(
+						raddr,
+						protocol.ParseConnectionID([]byte{0xde, 0xad, 0xc0, 0xde}),
+						protocol.ParseConnectionID([]byte{0xde, 0xca, 0xfb, 0xad}),
+					)
+					Expect(err).ToNot(HaveOccurred())
+					connID := protocol.ParseConnectionID([]byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10})
+					hdr := &wire.Header{
+						Type:             protocol.PacketTypeInitial,
+						SrcConnectionID:  protocol.ParseConnectionID([]byte{5, 4, 3, 2, 1}),
+						DestConnectionID: connID,
+						Version:          protocol.Version1,
+						Token:            retryToken,
+					}
+					p := getPacket(hdr, make([]byte, protocol.MinInitialPacketSize))
+					p.remoteAddr = raddr
+					run := make(chan struct{})
+					conn := NewMockQUICConn(mockCtrl)
+					serv.newConn = func(
+						_ context.Context,
+						_ context.CancelCauseFunc,
+						_ sendConn,
+						_ connRunner,
+						origDestConnID protocol.ConnectionID,
+						retrySrcConnID *protocol.ConnectionID,
+						_ protocol.ConnectionID,
+						_ protocol.ConnectionID,
+						_ protocol.ConnectionID,
+						_ ConnectionIDGenerator,
+						_ protocol.StatelessResetToken,
+						_ *Config,
+						_ *tls.Config,
+						_ *handshake.TokenGenerator,
+						clientAddressValidated bool,
+						_ *logging.ConnectionTracer,
+						_ utils.Logger,
+						_ protocol.Version,
+					) quicConn {
+						Expect(origDestConnID).To(Equal(protocol.ParseConnectionID([]byte{0xde, 0xad, 0xc0, 0xde})))
+						Expect(*retrySrcConnID).To(Equal(protocol.ParseConnectionID([]byte{0xde, 0xca, 0xfb, 0xad})))
+						Expect(clientAddressValidated).To(BeTrue())
+						conn.EXPECT().handlePacket(p)
+						conn.EXPECT().run().Do(func() error { close(run); return nil })
+						conn.EXPECT().Context().Return(context.Background())
+						conn.EXPECT().HandshakeComplete().Return(make(chan struct{}))
+						return conn
+					}
+					phm.EXPECT().Get(connID)
+					phm.EXPECT().GetStatelessResetToken(gomock.Any())
+					phm.EXPECT().AddWithConnID(connID, gomock.Any(), gomock.Any()).Return(true)
+					serv.handlePacket(p)
+					Eventually(run).Should(BeClosed())
+					// shutdown
+					conn.EXPECT().closeWithTransportError(gomock.Any())
+				})
+
+				for _, tc := range []struct {
+					name   string
+					modify func(token []byte) []byte
+				}{
+					{name: "forged", modify: func(token []byte) []byte { token[len(token)-1] ^= 0xff; return token }},
+					{name: "expired", modify: func(token []byte) []byte { gen.ttl = -time.Second; return token }},
+				} {
+					tc := tc
+
+					It(fmt.Sprintf("sends an INVALID_TOKEN error, if a %s token is received", tc.name), func() {
+						raddr := &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 1337}
+						token, err := gen.NewRetryToken(raddr, protocol.ConnectionID{}, protocol.ConnectionID{})
+						Expect(err).ToNot(HaveOccurred())
+						hdr := &wire.Header{
+							Type:             protocol.PacketTypeInitial,
+							SrcConnectionID:  protocol.ParseConnectionID([]byte{5, 4, 3, 2, 1}),
+							DestConnectionID: protocol.ParseConnectionID([]byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}),
+							Token:            tc.modify(token),
+							Version:          protocol.Version1,
+						}
+						packet := getPacket(hdr, make([]byte, protocol.MinInitialPacketSize))
+						packet.remoteAddr = raddr
+						tracer.EXPECT().SentPacket(packet.remoteAddr, gomock.Any(), gomock.Any(), gomock.Any())
+						done := make(chan struct{})
+						conn.EXPECT().WriteTo(gomock.Any(), raddr).DoAndReturn(func(b []byte, _ net.Addr) (int, error) {
+							defer close(done)
+							checkConnectionCloseError(b, hdr, qerr.InvalidToken)
+							return len(b), nil
+						})
+						phm.EXPECT().Get(gomock.Any())
+						serv.handlePacket(packet)
+						Eventually(done).Should(BeClosed())
+					})
+				}
+			})
+
 			It("sends an INVALID_TOKEN error, if an invalid retry token is received", func() {
 				serv.verifySourceAddress = func(net.Addr) bool { return true }
-				token, err := serv.tokenGenerator.NewRetryToken(&net.UDPAddr{}, protocol.ConnectionID{}, protocol.ConnectionID{})
+				token, err := serv.tokenGenerator.NewRetryToken(&net.UDPAddr{}, protocol.ConnectionID{}, protocol.ConnectionID{}, time.Now())
 				Expect(err).ToNot(HaveOccurred())
 				hdr := &wire.Header{
 					Type:             protocol.PacketTypeInitial,
@@ -820,7 +1134,7 @@ var _ = Describe("Server", func() {
 				serv.config.HandshakeIdleTimeout = time.Millisecond / 2 // the maximum retry token age is equivalent to the handshake timeout
 				Expect(serv.config.maxRetryTokenAge()).To(Equal(time.Millisecond))
 				raddr := &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 1337}
-				token, err := serv.tokenGenerator.NewRetryToken(raddr, protocol.ConnectionID{}, protocol.ConnectionID{})
+				token, err := serv.tokenGenerator.NewRetryToken(raddr, protocol.ConnectionID{}, protocol.ConnectionID{}, time.Now())
 				Expect(err).ToNot(HaveOccurred())
 				time.Sleep(2 * time.Millisecond) // make sure the token is expired
 				hdr := &wire.Header{
@@ -855,7 +1169,7 @@ var _ = Describe("Server", func() {
 
 			It("doesn't send an INVALID_TOKEN error, if an invalid non-retry token is received", func() {
 				serv.verifySourceAddress = func(net.Addr) bool { return true }
-				token, err := serv.tokenGenerator.NewToken(&net.UDPAddr{IP: net.IPv4(192, 168, 0, 1), Port: 1337})
+				token, err := serv.tokenGenerator.NewToken(&net.UDPAddr{IP: net.IPv4(192, 168, 0, 1), Port: 1337}, time.Now())
 				Expect(err).ToNot(HaveOccurred())
 				hdr := &wire.Header{
 					Type:             protocol.PacketTypeInitial,
@@ -886,7 +1200,7 @@ var _ = Describe("Server", func() {
 				serv.verifySourceAddress = func(net.Addr) bool { return true }
 				serv.maxTokenAge = time.Millisecond
 				raddr := &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 1337}
-				token, err := serv.tokenGenerator.NewToken(raddr)
+				token, err := serv.tokenGenerator.NewToken(raddr, time.Now())
 				Expect(err).ToNot(HaveOccurred())
 				time.Sleep(2 * time.Millisecond) // make sure the token is expired
 				hdr := &wire.Header{
@@ -912,7 +1226,7 @@ var _ = Describe("Server", func() {
 			})
 
 			It("doesn't send an INVALID_TOKEN error, if the packet is corrupted", func() {
-				token, err := serv.tokenGenerator.NewRetryToken(&net.UDPAddr{}, protocol.ConnectionID{}, protocol.ConnectionID{})
+				token, err := serv.tokenGenerator.NewRetryToken(&net.UDPAddr{}, protocol.ConnectionID{}, protocol.ConnectionID{}, time.Now())
 				Expect(err).ToNot(HaveOccurred())
 				hdr := &wire.Header{
 					Type:             protocol.PacketTypeInitial,
@@ -1086,6 +1400,41 @@ var _ = Describe("Server", func() {
 				Eventually(done).Should(BeClosed())
 			})
 
+			It("rejects a connection attempt when the connection limit is reached", func() {
+				raddr := &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 1337}
+				var admitted net.Addr
+				serv.admitConn = func(addr net.Addr) bool {
+					admitted = addr
+					return false
+				}
+				serv.newConn = func(context.Context, context.CancelCauseFunc, sendConn, connRunner, protocol.ConnectionID, *protocol.ConnectionID, protocol.ConnectionID, protocol.ConnectionID, protocol.ConnectionID, ConnectionIDGenerator, protocol.StatelessResetToken, *Config, *tls.Config, *handshake.TokenGenerator, bool, *logging.ConnectionTracer, utils.Logger, protocol.Version) quicConn {
+					Fail("didn't expect a connection to be created")
+					return nil
+				}
+
+				phm.EXPECT().Get(gomock.Any())
+				done := make(chan struct{})
+				tracer.EXPECT().SentPacket(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Do(func(_ net.Addr, _ *logging.Header, _ logging.ByteCount, frames []logging.Frame) {
+					Expect(frames).To(HaveLen(1))
+					Expect(frames[0]).To(BeAssignableToTypeOf(&logging.ConnectionCloseFrame{}))
+					ccf := frames[0].(*logging.ConnectionCloseFrame)
+					Expect(ccf.IsApplicationError).To(BeFalse())
+					Expect(ccf.ErrorCode).To(BeEquivalentTo(qerr.ConnectionRefused))
+				})
+				conn.EXPECT().WriteTo(gomock.Any(), raddr).DoAndReturn(func(b []byte, _ net.Addr) (int, error) {
+					defer close(done)
+					rejectHdr := parseHeader(b)
+					Expect(rejectHdr.Type).To(Equal(protocol.PacketTypeInitial))
+					return len(b), nil
+				})
+				serv.handleInitialImpl(
+					receivedPacket{remoteAddr: raddr, buffer: getPacketBuffer()},
+					&wire.Header{DestConnectionID: protocol.ParseConnectionID([]byte{1, 2, 3, 4, 5, 6, 7, 8}), Version: protocol.Version1},
+				)
+				Eventually(done).Should(BeClosed())
+				Expect(admitted).To(Equal(raddr))
+			})
+
 			It("accepts new connections when the handshake completes", func() {
 				conn := NewMockQUICConn(mockCtrl)
 
@@ -1137,6 +1486,127 @@ var _ = Describe("Server", func() {
 				Eventually(done).Should(BeClosed())
 			})
 		})
+
+		Context("draining", func() {
+			var runDone chan struct{}
+
+			// newConn creates a new connection, which completes its handshake when handshakeComplete returns
+			newConn := func(handshakeComplete func() <-chan struct{}) *MockQUICConn {
+				conn := NewMockQUICConn(mockCtrl)
+				done := runDone
+				serv.newConn = func(
+					_ context.Context,
+					_ context.CancelCauseFunc,
+					_ sendConn,
+					runner connRunner,
+					_ protocol.ConnectionID,
+					_ *protocol.ConnectionID,
+					_ protocol.ConnectionID,
+					_ protocol.ConnectionID,
+					_ protocol.ConnectionID,
+					_ ConnectionIDGenerator,
+					_ protocol.StatelessResetToken,
+					_ *Config,
+					_ *tls.Config,
+					_ *handshake.TokenGenerator,
+					_ bool,
+					_ *logging.ConnectionTracer,
+					_ utils.Logger,
+					_ protocol.Version,
+				) quicConn {
+					conn.EXPECT().handlePacket(gomock.Any())
+					conn.EXPECT().HandshakeComplete().DoAndReturn(handshakeComplete)
+					conn.EXPECT().run().Do(func() error { <-done; return nil })
+					conn.EXPECT().Context().Return(context.Background())
+					return conn
+				}
+				phm.EXPECT().Get(gomock.Any())
+				phm.EXPECT().GetStatelessResetToken(gomock.Any())
+				phm.EXPECT().AddWithConnID(gomock.Any(), gomock.Any(), gomock.Any()).Return(true)
+				serv.handleInitialImpl(
+					receivedPacket{buffer: getPacketBuffer()},
+					&wire.Header{DestConnectionID: protocol.ParseConnectionID([]byte{1, 2, 3, 4, 5, 6, 7, 8})},
+				)
+				return conn
+			}
+
+			// establishConn creates a new connection, completes its handshake and accepts it
+			establishConn := func() *MockQUICConn {
+				handshakeChan := make(chan struct{})
+				close(handshakeChan)
+				conn := newConn(func() <-chan struct{} { return handshakeChan })
+				c, err := serv.Accept(context.Background())
+				Expect(err).ToNot(HaveOccurred())
+				Expect(c).To(Equal(conn))
+				return conn
+			}
+
+			BeforeEach(func() {
+				runDone = make(chan struct{})
+			})
+
+			It("returns immediately if there are no connections", func() {
+				Expect(serv.Drain(context.Background())).To(Succeed())
+				_, err := serv.Accept(context.Background())
+				Expect(err).To(MatchError(ErrServerClosed))
+				Expect(serv.Drain(context.Background())).To(MatchError(ErrServerClosed))
+			})
+
+			It("waits until all connections are closed", func() {
+				establishConn()
+				drained := make(chan error, 1)
+				go func() { drained <- serv.Drain(context.Background()) }()
+				// Accept returns immediately
+				_, err := serv.Accept(context.Background())
+				Expect(err).To(MatchError(ErrServerClosed))
+				Consistently(drained).ShouldNot(Receive())
+				close(runDone)
+				Eventually(drained).Should(Receive(BeNil()))
+			})
+
+			It("returns when the context is canceled", func() {
+				establishConn()
+				defer close(runDone)
+				ctx, cancel := context.WithTimeout(context.Background(), scaleDuration(25*time.Millisecond))
+				defer cancel()
+				Expect(serv.Drain(ctx)).To(MatchError(context.DeadlineExceeded))
+			})
+
+			It("stops draining when closed", func() {
+				establishConn()
+				defer close(runDone)
+				drained := make(chan error, 1)
+				go func() { drained <- serv.Drain(context.Background()) }()
+				Consistently(drained).ShouldNot(Receive())
+				Expect(serv.Close()).To(Succeed())
+				Eventually(drained).Should(Receive(MatchError(ErrServerClosed)))
+			})
+
+			It("rejects connections that complete the handshake while draining", func() {
+				handshakeChan := make(chan struct{})
+				close(handshakeChan)
+				unblock := make(chan struct{})
+				conn := newConn(func() <-chan struct{} {
+					<-unblock
+					return handshakeChan
+				})
+				conn.EXPECT().closeWithTransportError(ConnectionRefused).Do(func(TransportErrorCode) { close(runDone) })
+				drained := make(chan error, 1)
+				go func() { drained <- serv.Drain(context.Background()) }()
+				Consistently(drained).ShouldNot(Receive())
+				// the handshake completes after Drain was called
+				close(unblock)
+				Eventually(drained).Should(Receive(BeNil()))
+			})
+
+			It("rejects connections that weren't accepted yet", func() {
+				conn := NewMockQUICConn(mockCtrl)
+				serv.connQueue <- conn
+				conn.EXPECT().closeWithTransportError(ConnectionRefused)
+				Expect(serv.Drain(context.Background())).To(Succeed())
+			})
+		})
+
 	})
 
 	Context("server accepting connections that haven't completed the handshake", func() {
@@ -1538,3 +2008,56 @@ var _ = Describe("Server", func() {
 		})
 	})
 })
+
+// hmacRetryTokenGenerator is a RetryTokenGenerator that authenticates (but doesn't encrypt) tokens.
+// Token format: "retry" || timestamp (8 bytes) || len(odcid) || odcid || len(rscid) || rscid || HMAC
+type hmacRetryTokenGenerator struct {
+	key []byte
+	ttl time.Duration
+}
+
+var _ RetryTokenGenerator = &hmacRetryTokenGenerator{}
+
+const hmacRetryTokenPrefix = "retry"
+
+func (g *hmacRetryTokenGenerator) IsRetryToken(token []byte) bool {
+	return bytes.HasPrefix(token, []byte(hmacRetryTokenPrefix))
+}
+
+func (g *hmacRetryTokenGenerator) mac(data []byte, remoteAddr net.Addr) []byte {
+	h := hmac.New(sha256.New, g.key)
+	h.Write(data)
+	h.Write([]byte(remoteAddr.String()))
+	return h.Sum(nil)
+}
+
+func (g *hmacRetryTokenGenerator) NewRetryToken(remoteAddr net.Addr, origDestConnID, retrySrcConnID ConnectionID) ([]byte, error) {
+	b := binary.BigEndian.AppendUint64([]byte(hmacRetryTokenPrefix), uint64(time.Now().UnixNano()))
+	b = append(b, uint8(origDestConnID.Len()))
+	b = append(b, origDestConnID.Bytes()...)
+	b = append(b, uint8(retrySrcConnID.Len()))
+	b = append(b, retrySrcConnID.Bytes()...)
+	return append(b, g.mac(b, remoteAddr)...), nil
+}
+
+func (g *hmacRetryTokenGenerator) ValidateRetryToken(token []byte, remoteAddr net.Addr) (ConnectionID, ConnectionID, error) {
+	if len(token) < len(hmacRetryTokenPrefix)+8+2+sha256.Size {
+		return ConnectionID{}, ConnectionID{}, errors.New("token too short")
+	}
+	data, mac := token[:len(token)-sha256.Size], token[len(token)-sha256.Size:]
+	if !hmac.Equal(mac, g.mac(data, remoteAddr)) {
+		return ConnectionID{}, ConnectionID{}, errors.New("invalid token")
+	}
+	data = data[len(hmacRetryTokenPrefix):]
+	if time.Since(time.Unix(0, int64(binary.BigEndian.Uint64(data)))) > g.ttl {
+		return ConnectionID{}, ConnectionID{}, errors.New("token expired")
+	}
+	r := bytes.NewReader(data[8:])
+	odcidLen, _ := r.ReadByte()
+	odcid := make([]byte, odcidLen)
+	r.Read(odcid)
+	rscidLen, _ := r.ReadByte()
+	rscid := make([]byte, rscidLen)
+	r.Read(rscid)
+	return protocol.ParseConnectionID(odcid), protocol.ParseConnectionID(rscid), nil
+}
@@ -0,0 +1,80 @@
+package quic
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+
+	"github.com/quic-go/quic-go/internal/handshake"
+	"github.com/quic-go/quic-go/internal/qtls"
+	"github.com/quic-go/quic-go/quicvarint"
+)
+
+const clientSessionStateSerializationRevision = 1
+
+// MarshalClientSessionState serializes a session obtained from the tls.ClientSessionCache of a QUIC client.
+// This allows persisting session tickets, e.g. to disk, and using them for session resumption and 0-RTT
+// from a different process.
+// In addition to the TLS session, the serialized session contains the QUIC-specific resumption state,
+// i.e. the RTT estimate and (for 0-RTT enabled sessions) the transport parameters of the server.
+func MarshalClientSessionState(cs *tls.ClientSessionState) ([]byte, error) {
+	if cs == nil {
+		return nil, errors.New("nil session state")
+	}
+	ticket, state, err := cs.ResumptionState()
+	if err != nil {
+		return nil, err
+	}
+	if state == nil {
+		return nil, errors.New("session state is not resumable")
+	}
+	stateBytes, err := state.Bytes()
+	if err != nil {
+		return nil, err
+	}
+	b := make([]byte, 0, 16+len(ticket)+len(stateBytes))
+	b = quicvarint.Append(b, clientSessionStateSerializationRevision)
+	b = quicvarint.Append(b, uint64(len(ticket)))
+	b = append(b, ticket...)
+	return append(b, stateBytes...), nil
+}
+
+// UnmarshalClientSessionState parses a session serialized using MarshalClientSessionState.
+// The returned session can be returned from the tls.ClientSessionCache of a QUIC client.
+// It returns an error if the QUIC-specific resumption state can't be restored,
+// for example if it was saved by an incompatible version of quic-go, or if the transport parameters
+// required for 0-RTT are invalid.
+// When the session is used, the transport parameters are validated once more, and 0-RTT is only used if
+// it is enabled on the quic.Config of the new connection.
+func UnmarshalClientSessionState(b []byte) (*tls.ClientSessionState, error) {
+	rev, l, err := quicvarint.Parse(b)
+	if err != nil {
+		return nil, errors.New("failed to read session state revision")
+	}
+	b = b[l:]
+	if rev != clientSessionStateSerializationRevision {
+		return nil, fmt.Errorf("unknown session state revision: %d", rev)
+	}
+	ticketLen, l, err := quicvarint.Parse(b)
+	if err != nil {
+		return nil, errors.New("failed to read session ticket length")
+	}
+	b = b[l:]
+	if uint64(len(b)) < ticketLen {
+		return nil, errors.New("session ticket too short")
+	}
+	ticket := make([]byte, ticketLen)
+	copy(ticket, b)
+	state, err := tls.ParseSessionState(b[ticketLen:])
+	if err != nil {
+		return nil, err
+	}
+	data := qtls.FindQUICData(state)
+	if data == nil {
+		return nil, errors.New("session state doesn't contain QUIC resumption state")
+	}
+	if err := handshake.ValidateDataFromSessionState(data, state.EarlyData); err != nil {
+		return nil, fmt.Errorf("invalid QUIC resumption state: %w", err)
+	}
+	return tls.NewResumptionState(ticket, state)
+}
@@ -0,0 +1,42 @@
+package quic
+
+import (
+	"github.com/quic-go/quic-go/quicvarint"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Client Session State", func() {
+	It("refuses to marshal a nil session", func() {
+		_, err := MarshalClientSessionState(nil)
+		Expect(err).To(MatchError("nil session state"))
+	})
+
+	It("errors on an empty session state", func() {
+		_, err := UnmarshalClientSessionState(nil)
+		Expect(err).To(MatchError("failed to read session state revision"))
+	})
+
+	It("errors on an unknown revision", func() {
+		b := quicvarint.Append(nil, clientSessionStateSerializationRevision+1)
+		_, err := UnmarshalClientSessionState(b)
+		Expect(err).To(MatchError("unknown session state revision: 2"))
+	})
+
+	It("errors when the session ticket is too short", func() {
+		b := quicvarint.Append(nil, clientSessionStateSerializationRevision)
+		b = quicvarint.Append(b, 10)
+		b = append(b, []byte("foobar")...)
+		_, err := UnmarshalClientSessionState(b)
+		Expect(err).To(MatchError("session ticket too short"))
+	})
+
+	It("errors when the TLS session state is invalid", func() {
+		b := quicvarint.Append(nil, clientSessionStateSerializationRevision)
+		b = quicvarint.Append(b, 3)
+		b = append(b, []byte("foobar")...)
+		_, err := UnmarshalClientSessionState(b)
+		Expect(err).To(HaveOccurred())
+	})
+})
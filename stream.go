@@ -10,6 +10,7 @@ import (
 	"github.com/quic-go/quic-go/internal/ackhandler"
 	"github.com/quic-go/quic-go/internal/flowcontrol"
 	"github.com/quic-go/quic-go/internal/protocol"
+	"github.com/quic-go/quic-go/internal/utils"
 	"github.com/quic-go/quic-go/internal/wire"
 )
 
@@ -26,6 +27,9 @@ var errDeadline net.Error = &deadlineError{}
 type streamSender interface {
 	queueControlFrame(wire.Frame)
 	onHasStreamData(protocol.StreamID)
+	onStreamPriorityChanged(protocol.StreamID, StreamPriority)
+	// says if the peer supports reliable stream resets (RESET_STREAM_AT)
+	supportsResetStreamAt() bool
 	// must be called without holding the mutex that is acquired by closeForShutdown
 	onStreamCompleted(protocol.StreamID)
 }
@@ -45,6 +49,14 @@ func (s *uniStreamSender) onHasStreamData(id protocol.StreamID) {
 	s.streamSender.onHasStreamData(id)
 }
 
+func (s *uniStreamSender) onStreamPriorityChanged(id protocol.StreamID, p StreamPriority) {
+	s.streamSender.onStreamPriorityChanged(id, p)
+}
+
+func (s *uniStreamSender) supportsResetStreamAt() bool {
+	return s.streamSender.supportsResetStreamAt()
+}
+
 func (s *uniStreamSender) onStreamCompleted(protocol.StreamID) {
 	s.onStreamCompletedImpl()
 }
@@ -57,6 +69,7 @@ type streamI interface {
 	// for receiving
 	handleStreamFrame(*wire.StreamFrame) error
 	handleResetStreamFrame(*wire.ResetStreamFrame) error
+	handleResetStreamAtFrame(*wire.ResetStreamAtFrame) error
 	getWindowUpdate() protocol.ByteCount
 	// for sending
 	hasData() bool
@@ -91,6 +104,7 @@ func newStream(
 	streamID protocol.StreamID,
 	sender streamSender,
 	flowController flowcontrol.StreamFlowController,
+	clock utils.Clock,
 ) *stream {
 	s := &stream{sender: sender}
 	senderForSendStream := &uniStreamSender{
@@ -102,7 +116,7 @@ func newStream(
 			s.completedMutex.Unlock()
 		},
 	}
-	s.sendStream = *newSendStream(ctx, streamID, senderForSendStream, flowController)
+	s.sendStream = *newSendStream(ctx, streamID, senderForSendStream, flowController, clock)
 	senderForReceiveStream := &uniStreamSender{
 		streamSender: sender,
 		onStreamCompletedImpl: func() {
@@ -112,7 +126,7 @@ func newStream(
 			s.completedMutex.Unlock()
 		},
 	}
-	s.receiveStream = *newReceiveStream(streamID, senderForReceiveStream, flowController)
+	s.receiveStream = *newReceiveStream(streamID, senderForReceiveStream, flowController, clock)
 	return s
 }
 
@@ -126,6 +140,14 @@ func (s *stream) Close() error {
 	return s.sendStream.Close()
 }
 
+func (s *stream) CloseRead(errorCode StreamErrorCode) {
+	s.receiveStream.CancelRead(errorCode)
+}
+
+func (s *stream) CloseWrite() {
+	s.sendStream.closeWrite()
+}
+
 func (s *stream) SetDeadline(t time.Time) error {
 	_ = s.SetReadDeadline(t)  // SetReadDeadline never errors
 	_ = s.SetWriteDeadline(t) // SetWriteDeadline never errors
@@ -3,6 +3,7 @@ package quic
 import (
 	"context"
 	"errors"
+	"fmt"
 	"io"
 	"os"
 	"strconv"
@@ -10,11 +11,13 @@ import (
 
 	"github.com/quic-go/quic-go/internal/mocks"
 	"github.com/quic-go/quic-go/internal/protocol"
+	"github.com/quic-go/quic-go/internal/utils"
 	"github.com/quic-go/quic-go/internal/wire"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	"github.com/onsi/gomega/gbytes"
+	"go.uber.org/mock/gomock"
 )
 
 // in the tests for the stream deadlines we set a deadline
@@ -42,7 +45,7 @@ var _ = Describe("Stream", func() {
 	BeforeEach(func() {
 		mockSender = NewMockStreamSender(mockCtrl)
 		mockFC = mocks.NewMockStreamFlowController(mockCtrl)
-		str = newStream(context.Background(), streamID, mockSender, mockFC)
+		str = newStream(context.Background(), streamID, mockSender, mockFC, utils.DefaultClock{})
 
 		timeout := scaleDuration(250 * time.Millisecond)
 		strWithTimeout = struct {
@@ -79,6 +82,88 @@ var _ = Describe("Stream", func() {
 		})
 	})
 
+	Context("closing the read and write direction", func() {
+		const closeRead, closeWrite = "CloseRead", "CloseWrite"
+
+		orders := [][]string{
+			{closeRead, closeWrite},
+			{closeWrite, closeRead},
+			{closeRead, closeRead, closeWrite},
+			{closeWrite, closeWrite, closeRead},
+			{closeRead, closeWrite, closeRead, closeWrite},
+			{closeWrite, closeRead, closeWrite, closeRead},
+			{closeWrite, closeRead, closeRead, closeWrite},
+		}
+
+		for _, order := range orders {
+			It(fmt.Sprintf("closes both directions, calling %v", order), func() {
+				mockSender.EXPECT().queueControlFrame(&wire.StopSendingFrame{StreamID: streamID, ErrorCode: 1234})
+				mockSender.EXPECT().onHasStreamData(streamID)
+				for _, c := range order {
+					switch c {
+					case closeRead:
+						str.CloseRead(1234)
+					case closeWrite:
+						str.CloseWrite()
+					}
+				}
+				_, err := strWithTimeout.Read([]byte{0})
+				Expect(err).To(Equal(&StreamError{StreamID: streamID, ErrorCode: 1234}))
+				_, err = strWithTimeout.Write([]byte("foobar"))
+				Expect(err).To(MatchError("write on closed stream 1337"))
+				frame, ok, _ := str.popStreamFrame(1000, protocol.Version1)
+				Expect(ok).To(BeTrue())
+				Expect(frame.Frame.Fin).To(BeTrue())
+			})
+		}
+
+		It("only closes the write direction", func() {
+			mockSender.EXPECT().onHasStreamData(streamID)
+			str.CloseWrite()
+			Expect(str.Context().Done()).To(BeClosed())
+			mockFC.EXPECT().UpdateHighestReceived(protocol.ByteCount(6), false)
+			Expect(str.handleStreamFrame(&wire.StreamFrame{Data: []byte("foobar")})).To(Succeed())
+			mockFC.EXPECT().AddBytesRead(protocol.ByteCount(6))
+			b := make([]byte, 6)
+			_, err := io.ReadFull(strWithTimeout, b)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(b).To(Equal([]byte("foobar")))
+		})
+
+		It("only closes the read direction", func() {
+			mockSender.EXPECT().queueControlFrame(&wire.StopSendingFrame{StreamID: streamID, ErrorCode: 1234})
+			str.CloseRead(1234)
+			Expect(str.Context().Done()).ToNot(BeClosed())
+			mockSender.EXPECT().onHasStreamData(streamID)
+			mockFC.EXPECT().SendWindowSize().Return(protocol.MaxByteCount).AnyTimes()
+			mockFC.EXPECT().AddBytesSent(protocol.ByteCount(6))
+			done := make(chan struct{})
+			go func() {
+				defer GinkgoRecover()
+				defer close(done)
+				_, err := strWithTimeout.Write([]byte("foobar"))
+				Expect(err).ToNot(HaveOccurred())
+			}()
+			var data []byte
+			Eventually(func() bool {
+				frame, ok, _ := str.popStreamFrame(1000, protocol.Version1)
+				if ok {
+					data = frame.Frame.Data
+				}
+				return ok
+			}).Should(BeTrue())
+			Expect(data).To(Equal([]byte("foobar")))
+			Eventually(done).Should(BeClosed())
+		})
+
+		It("doesn't close the write direction after it was canceled", func() {
+			mockSender.EXPECT().queueControlFrame(gomock.Any())
+			str.CancelWrite(1234)
+			str.CloseWrite()
+			Expect(str.Close()).To(MatchError("close called for canceled stream 1337"))
+		})
+	})
+
 	Context("completing", func() {
 		It("is not completed when only the receive side is completed", func() {
 			// don't EXPECT a call to mockSender.onStreamCompleted()
@@ -5,11 +5,13 @@ import (
 	"errors"
 	"fmt"
 	"net"
+	"slices"
 	"sync"
 
 	"github.com/quic-go/quic-go/internal/flowcontrol"
 	"github.com/quic-go/quic-go/internal/protocol"
 	"github.com/quic-go/quic-go/internal/qerr"
+	"github.com/quic-go/quic-go/internal/utils"
 	"github.com/quic-go/quic-go/internal/wire"
 )
 
@@ -50,16 +52,19 @@ type streamsMap struct {
 
 	maxIncomingBidiStreams uint64
 	maxIncomingUniStreams  uint64
+	acceptUniStreamType    func(streamType uint64) bool
+	rejectedUniStreamCode  qerr.StreamErrorCode
 
 	sender            streamSender
 	newFlowController func(protocol.StreamID) flowcontrol.StreamFlowController
+	clock             utils.Clock
 
 	mutex               sync.Mutex
 	outgoingBidiStreams *outgoingStreamsMap[streamI]
 	outgoingUniStreams  *outgoingStreamsMap[sendStreamI]
 	incomingBidiStreams *incomingStreamsMap[streamI]
 	incomingUniStreams  *incomingStreamsMap[receiveStreamI]
-	reset               bool
+	resetErr            error // set when 0-RTT was rejected
 }
 
 var _ streamManager = &streamsMap{}
@@ -70,7 +75,10 @@ func newStreamsMap(
 	newFlowController func(protocol.StreamID) flowcontrol.StreamFlowController,
 	maxIncomingBidiStreams uint64,
 	maxIncomingUniStreams uint64,
+	acceptUniStreamType func(streamType uint64) bool,
+	rejectedUniStreamCode qerr.StreamErrorCode,
 	perspective protocol.Perspective,
+	clock utils.Clock,
 ) streamManager {
 	m := &streamsMap{
 		ctx:                    ctx,
@@ -78,7 +86,10 @@ func newStreamsMap(
 		newFlowController:      newFlowController,
 		maxIncomingBidiStreams: maxIncomingBidiStreams,
 		maxIncomingUniStreams:  maxIncomingUniStreams,
+		acceptUniStreamType:    acceptUniStreamType,
+		rejectedUniStreamCode:  rejectedUniStreamCode,
 		sender:                 sender,
+		clock:                  clock,
 	}
 	m.initMaps()
 	return m
@@ -89,7 +100,7 @@ func (m *streamsMap) initMaps() {
 		protocol.StreamTypeBidi,
 		func(num protocol.StreamNum) streamI {
 			id := num.StreamID(protocol.StreamTypeBidi, m.perspective)
-			return newStream(m.ctx, id, m.sender, m.newFlowController(id))
+			return newStream(m.ctx, id, m.sender, m.newFlowController(id), m.clock)
 		},
 		m.sender.queueControlFrame,
 	)
@@ -97,7 +108,7 @@ func (m *streamsMap) initMaps() {
 		protocol.StreamTypeBidi,
 		func(num protocol.StreamNum) streamI {
 			id := num.StreamID(protocol.StreamTypeBidi, m.perspective.Opposite())
-			return newStream(m.ctx, id, m.sender, m.newFlowController(id))
+			return newStream(m.ctx, id, m.sender, m.newFlowController(id), m.clock)
 		},
 		m.maxIncomingBidiStreams,
 		m.sender.queueControlFrame,
@@ -106,7 +117,7 @@ func (m *streamsMap) initMaps() {
 		protocol.StreamTypeUni,
 		func(num protocol.StreamNum) sendStreamI {
 			id := num.StreamID(protocol.StreamTypeUni, m.perspective)
-			return newSendStream(m.ctx, id, m.sender, m.newFlowController(id))
+			return newSendStream(m.ctx, id, m.sender, m.newFlowController(id), m.clock)
 		},
 		m.sender.queueControlFrame,
 	)
@@ -114,7 +125,11 @@ func (m *streamsMap) initMaps() {
 		protocol.StreamTypeUni,
 		func(num protocol.StreamNum) receiveStreamI {
 			id := num.StreamID(protocol.StreamTypeUni, m.perspective.Opposite())
-			return newReceiveStream(id, m.sender, m.newFlowController(id))
+			str := newReceiveStream(id, m.sender, m.newFlowController(id), m.clock)
+			if m.acceptUniStreamType != nil {
+				str.setStreamTypeCheck(m.acceptUniStreamType, m.rejectedUniStreamCode)
+			}
+			return str
 		},
 		m.maxIncomingUniStreams,
 		m.sender.queueControlFrame,
@@ -123,11 +138,11 @@ func (m *streamsMap) initMaps() {
 
 func (m *streamsMap) OpenStream() (Stream, error) {
 	m.mutex.Lock()
-	reset := m.reset
+	resetErr := m.resetErr
 	mm := m.outgoingBidiStreams
 	m.mutex.Unlock()
-	if reset {
-		return nil, Err0RTTRejected
+	if resetErr != nil {
+		return nil, resetErr
 	}
 	str, err := mm.OpenStream()
 	return str, convertStreamError(err, protocol.StreamTypeBidi, m.perspective)
@@ -135,11 +150,11 @@ func (m *streamsMap) OpenStream() (Stream, error) {
 
 func (m *streamsMap) OpenStreamSync(ctx context.Context) (Stream, error) {
 	m.mutex.Lock()
-	reset := m.reset
+	resetErr := m.resetErr
 	mm := m.outgoingBidiStreams
 	m.mutex.Unlock()
-	if reset {
-		return nil, Err0RTTRejected
+	if resetErr != nil {
+		return nil, resetErr
 	}
 	str, err := mm.OpenStreamSync(ctx)
 	return str, convertStreamError(err, protocol.StreamTypeBidi, m.perspective)
@@ -147,11 +162,11 @@ func (m *streamsMap) OpenStreamSync(ctx context.Context) (Stream, error) {
 
 func (m *streamsMap) OpenUniStream() (SendStream, error) {
 	m.mutex.Lock()
-	reset := m.reset
+	resetErr := m.resetErr
 	mm := m.outgoingUniStreams
 	m.mutex.Unlock()
-	if reset {
-		return nil, Err0RTTRejected
+	if resetErr != nil {
+		return nil, resetErr
 	}
 	str, err := mm.OpenStream()
 	return str, convertStreamError(err, protocol.StreamTypeBidi, m.perspective)
@@ -159,23 +174,67 @@ func (m *streamsMap) OpenUniStream() (SendStream, error) {
 
 func (m *streamsMap) OpenUniStreamSync(ctx context.Context) (SendStream, error) {
 	m.mutex.Lock()
-	reset := m.reset
+	resetErr := m.resetErr
 	mm := m.outgoingUniStreams
 	m.mutex.Unlock()
-	if reset {
-		return nil, Err0RTTRejected
+	if resetErr != nil {
+		return nil, resetErr
 	}
 	str, err := mm.OpenStreamSync(ctx)
 	return str, convertStreamError(err, protocol.StreamTypeUni, m.perspective)
 }
 
+func (m *streamsMap) StreamsAvailable() (bidi, uni int64) {
+	m.mutex.Lock()
+	resetErr := m.resetErr
+	bidiMap := m.outgoingBidiStreams
+	uniMap := m.outgoingUniStreams
+	m.mutex.Unlock()
+	if resetErr != nil {
+		return 0, 0
+	}
+	return bidiMap.NumAvailable(), uniMap.NumAvailable()
+}
+
+func (m *streamsMap) ActiveStreams() []protocol.StreamID {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	// Hold the locks of all maps at the same time, so that the result is a consistent snapshot.
+	// None of the maps acquires the lock of another map, so this can't deadlock.
+	m.outgoingBidiStreams.mutex.RLock()
+	defer m.outgoingBidiStreams.mutex.RUnlock()
+	m.outgoingUniStreams.mutex.RLock()
+	defer m.outgoingUniStreams.mutex.RUnlock()
+	m.incomingBidiStreams.mutex.RLock()
+	defer m.incomingBidiStreams.mutex.RUnlock()
+	m.incomingUniStreams.mutex.RLock()
+	defer m.incomingUniStreams.mutex.RUnlock()
+
+	var ids []protocol.StreamID
+	for _, num := range m.outgoingBidiStreams.streamNums() {
+		ids = append(ids, num.StreamID(protocol.StreamTypeBidi, m.perspective))
+	}
+	for _, num := range m.outgoingUniStreams.streamNums() {
+		ids = append(ids, num.StreamID(protocol.StreamTypeUni, m.perspective))
+	}
+	for _, num := range m.incomingBidiStreams.streamNums() {
+		ids = append(ids, num.StreamID(protocol.StreamTypeBidi, m.perspective.Opposite()))
+	}
+	for _, num := range m.incomingUniStreams.streamNums() {
+		ids = append(ids, num.StreamID(protocol.StreamTypeUni, m.perspective.Opposite()))
+	}
+	slices.Sort(ids)
+	return ids
+}
+
 func (m *streamsMap) AcceptStream(ctx context.Context) (Stream, error) {
 	m.mutex.Lock()
-	reset := m.reset
+	resetErr := m.resetErr
 	mm := m.incomingBidiStreams
 	m.mutex.Unlock()
-	if reset {
-		return nil, Err0RTTRejected
+	if resetErr != nil {
+		return nil, resetErr
 	}
 	str, err := mm.AcceptStream(ctx)
 	return str, convertStreamError(err, protocol.StreamTypeBidi, m.perspective.Opposite())
@@ -183,11 +242,11 @@ func (m *streamsMap) AcceptStream(ctx context.Context) (Stream, error) {
 
 func (m *streamsMap) AcceptUniStream(ctx context.Context) (ReceiveStream, error) {
 	m.mutex.Lock()
-	reset := m.reset
+	resetErr := m.resetErr
 	mm := m.incomingUniStreams
 	m.mutex.Unlock()
-	if reset {
-		return nil, Err0RTTRejected
+	if resetErr != nil {
+		return nil, resetErr
 	}
 	str, err := mm.AcceptStream(ctx)
 	return str, convertStreamError(err, protocol.StreamTypeUni, m.perspective.Opposite())
@@ -287,6 +346,20 @@ func (m *streamsMap) HandleMaxStreamsFrame(f *wire.MaxStreamsFrame) {
 	}
 }
 
+func (m *streamsMap) SetMaxIncomingStreams(num uint64) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.maxIncomingBidiStreams = num
+	m.incomingBidiStreams.SetMaxStreams(num)
+}
+
+func (m *streamsMap) SetMaxIncomingUniStreams(num uint64) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.maxIncomingUniStreams = num
+	m.incomingUniStreams.SetMaxStreams(num)
+}
+
 func (m *streamsMap) UpdateLimits(p *wire.TransportParameters) {
 	m.outgoingBidiStreams.UpdateSendWindow(p.InitialMaxStreamDataBidiRemote)
 	m.outgoingBidiStreams.SetMaxStream(p.MaxBidiStreamNum)
@@ -301,21 +374,32 @@ func (m *streamsMap) CloseWithError(err error) {
 	m.incomingUniStreams.CloseWithError(err)
 }
 
+// SetInitialMaxIncomingStreams sets the number of streams the peer is allowed to open.
+// Unlike SetMaxIncomingStreams and SetMaxIncomingUniStreams, it doesn't queue MAX_STREAMS frames.
+// It must be called before the transport parameters are sent.
+func (m *streamsMap) SetInitialMaxIncomingStreams(bidi, uni uint64) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.maxIncomingBidiStreams = bidi
+	m.maxIncomingUniStreams = uni
+	m.initMaps()
+}
+
 // ResetFor0RTT resets is used when 0-RTT is rejected. In that case, the streams maps are
-// 1. closed with an Err0RTTRejected, making calls to Open{Uni}Stream{Sync} / Accept{Uni}Stream return that error.
+// 1. closed with the error (matching Err0RTTRejected), making calls to Open{Uni}Stream{Sync} / Accept{Uni}Stream return that error.
 // 2. reset to their initial state, such that we can immediately process new incoming stream data.
 // Afterwards, calls to Open{Uni}Stream{Sync} / Accept{Uni}Stream will continue to return the error,
 // until UseResetMaps() has been called.
-func (m *streamsMap) ResetFor0RTT() {
+func (m *streamsMap) ResetFor0RTT(err error) {
 	m.mutex.Lock()
 	defer m.mutex.Unlock()
-	m.reset = true
-	m.CloseWithError(Err0RTTRejected)
+	m.resetErr = err
+	m.CloseWithError(err)
 	m.initMaps()
 }
 
 func (m *streamsMap) UseResetMaps() {
 	m.mutex.Lock()
-	m.reset = false
+	m.resetErr = nil
 	m.mutex.Unlock()
 }
@@ -12,6 +12,13 @@ type incomingStream interface {
 	closeForShutdown(error)
 }
 
+// An incomingStreamChecker is an incoming stream that needs to be checked before it can be accepted.
+type incomingStreamChecker interface {
+	// streamTypeChecked says if the stream can be accepted.
+	// If the check isn't completed yet, it returns a channel that is closed once it is.
+	streamTypeChecked() (<-chan struct{}, bool)
+}
+
 // When a stream is deleted before it was accepted, we can't delete it from the map immediately.
 // We need to wait until the application accepts it, and delete it then.
 type incomingStreamEntry[T incomingStream] struct {
@@ -76,7 +83,35 @@ func (m *incomingStreamsMap[T]) AcceptStream(ctx context.Context) (T, error) {
 		var ok bool
 		entry, ok = m.streams[num]
 		if ok {
-			break
+			checker, isChecker := any(entry.stream).(incomingStreamChecker)
+			if !isChecker {
+				break
+			}
+			checked, accept := checker.streamTypeChecked()
+			if checked == nil && accept {
+				break
+			}
+			if checked == nil {
+				// The stream was rejected. Skip it, so that the application never sees it.
+				m.nextStreamToAccept++
+				if entry.shouldDelete {
+					if err := m.deleteStream(num); err != nil {
+						m.mutex.Unlock()
+						return *new(T), err
+					}
+				}
+				continue
+			}
+			m.mutex.Unlock()
+			// The newStreamChan is closed when the map is closed.
+			select {
+			case <-ctx.Done():
+				return *new(T), ctx.Err()
+			case <-checked:
+			case <-m.newStreamChan:
+			}
+			m.mutex.Lock()
+			continue
 		}
 		m.mutex.Unlock()
 		select {
@@ -138,6 +173,20 @@ func (m *incomingStreamsMap[T]) GetOrOpenStream(num protocol.StreamNum) (T, erro
 	return entry.stream, nil
 }
 
+// streamNums returns the numbers of all open streams, including streams that were not yet accepted.
+// Streams that were completed before being accepted are not included.
+// The caller must hold the mutex.
+func (m *incomingStreamsMap[T]) streamNums() []protocol.StreamNum {
+	nums := make([]protocol.StreamNum, 0, len(m.streams))
+	for num, entry := range m.streams {
+		if entry.shouldDelete {
+			continue
+		}
+		nums = append(nums, num)
+	}
+	return nums
+}
+
 func (m *incomingStreamsMap[T]) DeleteStream(num protocol.StreamNum) error {
 	m.mutex.Lock()
 	defer m.mutex.Unlock()
@@ -170,20 +219,37 @@ func (m *incomingStreamsMap[T]) deleteStream(num protocol.StreamNum) error {
 
 	delete(m.streams, num)
 	// queue a MAX_STREAM_ID frame, giving the peer the option to open a new stream
-	if m.maxNumStreams > uint64(len(m.streams)) {
-		maxStream := m.nextStreamToOpen + protocol.StreamNum(m.maxNumStreams-uint64(len(m.streams))) - 1
-		// Never send a value larger than protocol.MaxStreamCount.
-		if maxStream <= protocol.MaxStreamCount {
-			m.maxStream = maxStream
-			m.queueMaxStreamID(&wire.MaxStreamsFrame{
-				Type:         m.streamType,
-				MaxStreamNum: m.maxStream,
-			})
-		}
-	}
+	m.maybeQueueMaxStreams()
 	return nil
 }
 
+// SetMaxStreams sets the maximum number of concurrent streams that the peer is allowed to open.
+// The stream limit granted to the peer can't be reduced. When lowering the limit, no additional
+// streams are granted until the number of streams drops below the new limit.
+func (m *incomingStreamsMap[T]) SetMaxStreams(num uint64) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.maxNumStreams = num
+	m.maybeQueueMaxStreams()
+}
+
+func (m *incomingStreamsMap[T]) maybeQueueMaxStreams() {
+	if m.maxNumStreams <= uint64(len(m.streams)) {
+		return
+	}
+	maxStream := m.nextStreamToOpen + protocol.StreamNum(m.maxNumStreams-uint64(len(m.streams))) - 1
+	// Never send a value larger than protocol.MaxStreamCount.
+	if maxStream <= m.maxStream || maxStream > protocol.MaxStreamCount {
+		return
+	}
+	m.maxStream = maxStream
+	m.queueMaxStreamID(&wire.MaxStreamsFrame{
+		Type:         m.streamType,
+		MaxStreamNum: m.maxStream,
+	})
+}
+
 func (m *incomingStreamsMap[T]) CloseWithError(err error) {
 	m.mutex.Lock()
 	m.closeErr = err
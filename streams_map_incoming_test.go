@@ -21,6 +21,21 @@ type mockGenericStream struct {
 	closed     bool
 	closeErr   error
 	sendWindow protocol.ByteCount
+
+	typeChecked chan struct{} // if set, the stream can only be accepted once this channel is closed
+	rejected    bool
+}
+
+func (s *mockGenericStream) streamTypeChecked() (<-chan struct{}, bool) {
+	if s.typeChecked == nil {
+		return nil, !s.rejected
+	}
+	select {
+	case <-s.typeChecked:
+		return nil, !s.rejected
+	default:
+		return s.typeChecked, false
+	}
 }
 
 func (s *mockGenericStream) closeForShutdown(err error) {
@@ -45,7 +60,7 @@ var _ = Describe("Streams Map (incoming)", func() {
 	checkFrameSerialization := func(f wire.Frame) {
 		b, err := f.Append(nil, protocol.Version1)
 		ExpectWithOffset(1, err).ToNot(HaveOccurred())
-		_, frame, err := wire.NewFrameParser(false).ParseNext(b, protocol.Encryption1RTT, protocol.Version1)
+		_, frame, err := wire.NewFrameParser(false, false, false).ParseNext(b, protocol.Encryption1RTT, protocol.Version1)
 		ExpectWithOffset(1, err).ToNot(HaveOccurred())
 		Expect(f).To(Equal(frame))
 	}
@@ -93,6 +108,61 @@ var _ = Describe("Streams Map (incoming)", func() {
 		Expect(str.num).To(Equal(protocol.StreamNum(2)))
 	})
 
+	It("waits for the stream type check, and skips rejected streams", func() {
+		_, err := m.GetOrOpenStream(3) // open streams 1, 2 and 3
+		Expect(err).ToNot(HaveOccurred())
+		str1, _ := m.GetOrOpenStream(1)
+		str1.rejected = true
+		str2, _ := m.GetOrOpenStream(2)
+		str2.typeChecked = make(chan struct{})
+		strChan := make(chan *mockGenericStream)
+		go func() {
+			defer GinkgoRecover()
+			for i := 0; i < 2; i++ {
+				str, err := m.AcceptStream(context.Background())
+				Expect(err).ToNot(HaveOccurred())
+				strChan <- str
+			}
+		}()
+		Consistently(strChan).ShouldNot(Receive())
+		close(str2.typeChecked)
+		var str *mockGenericStream
+		Eventually(strChan).Should(Receive(&str))
+		Expect(str.num).To(Equal(protocol.StreamNum(2)))
+		Eventually(strChan).Should(Receive(&str))
+		Expect(str.num).To(Equal(protocol.StreamNum(3)))
+	})
+
+	It("unblocks AcceptStream when the context is canceled while waiting for the stream type check", func() {
+		str, err := m.GetOrOpenStream(1)
+		Expect(err).ToNot(HaveOccurred())
+		str.typeChecked = make(chan struct{})
+		ctx, cancel := context.WithTimeout(context.Background(), scaleDuration(20*time.Millisecond))
+		defer cancel()
+		_, err = m.AcceptStream(ctx)
+		Expect(err).To(MatchError(context.DeadlineExceeded))
+		// the stream can still be accepted afterwards
+		close(str.typeChecked)
+		accepted, err := m.AcceptStream(context.Background())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(accepted.num).To(Equal(protocol.StreamNum(1)))
+	})
+
+	It("unblocks AcceptStream when it is closed while waiting for the stream type check", func() {
+		str, err := m.GetOrOpenStream(1)
+		Expect(err).ToNot(HaveOccurred())
+		str.typeChecked = make(chan struct{})
+		testErr := errors.New("test error")
+		errChan := make(chan error, 1)
+		go func() {
+			_, err := m.AcceptStream(context.Background())
+			errChan <- err
+		}()
+		Consistently(errChan).ShouldNot(Receive())
+		m.CloseWithError(testErr)
+		Eventually(errChan).Should(Receive(MatchError(testErr)))
+	})
+
 	It("allows opening the maximum stream ID", func() {
 		str, err := m.GetOrOpenStream(1)
 		Expect(err).ToNot(HaveOccurred())
@@ -241,6 +311,47 @@ var _ = Describe("Streams Map (incoming)", func() {
 		Expect(m.DeleteStream(4)).To(Succeed())
 	})
 
+	It("sends a MAX_STREAMS frame when the limit is increased", func() {
+		_, err := m.GetOrOpenStream(3)
+		Expect(err).ToNot(HaveOccurred())
+		mockSender.EXPECT().queueControlFrame(gomock.Any()).Do(func(f wire.Frame) {
+			msf := f.(*wire.MaxStreamsFrame)
+			Expect(msf.Type).To(BeEquivalentTo(streamType))
+			Expect(msf.MaxStreamNum).To(Equal(protocol.StreamNum(maxNumStreams + 2)))
+		})
+		m.SetMaxStreams(maxNumStreams + 2)
+		_, err = m.GetOrOpenStream(protocol.StreamNum(maxNumStreams + 2))
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("doesn't grant new streams until enough streams are closed when the limit is reduced", func() {
+		_, err := m.GetOrOpenStream(protocol.StreamNum(maxNumStreams))
+		Expect(err).ToNot(HaveOccurred())
+		for i := uint64(0); i < maxNumStreams; i++ {
+			_, err := m.AcceptStream(context.Background())
+			Expect(err).ToNot(HaveOccurred())
+		}
+		m.SetMaxStreams(2)
+		// the streams the peer was already allowed to open can still be used
+		_, err = m.GetOrOpenStream(protocol.StreamNum(maxNumStreams))
+		Expect(err).ToNot(HaveOccurred())
+		for i := protocol.StreamNum(1); i <= protocol.StreamNum(maxNumStreams-2); i++ {
+			Expect(m.DeleteStream(i)).To(Succeed())
+		}
+		// now there are 2 streams left, which is the new limit
+		mockSender.EXPECT().queueControlFrame(gomock.Any()).Do(func(f wire.Frame) {
+			Expect(f.(*wire.MaxStreamsFrame).MaxStreamNum).To(Equal(protocol.StreamNum(maxNumStreams + 1)))
+		})
+		Expect(m.DeleteStream(protocol.StreamNum(maxNumStreams - 1))).To(Succeed())
+		_, err = m.GetOrOpenStream(protocol.StreamNum(maxNumStreams + 2))
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("doesn't send a MAX_STREAMS frame when the limit is reduced", func() {
+		m.SetMaxStreams(maxNumStreams - 1)
+		m.SetMaxStreams(maxNumStreams)
+	})
+
 	Context("using high stream limits", func() {
 		BeforeEach(func() { maxNumStreams = uint64(protocol.MaxStreamCount) - 2 })
 
@@ -96,7 +96,7 @@ func (m *outgoingStreamsMap[T]) OpenStreamSync(ctx context.Context) (T, error) {
 		case <-ctx.Done():
 			m.mutex.Lock()
 			delete(m.openQueue, queuePos)
-			return *new(T), ctx.Err()
+			return *new(T), &StreamsBlockedError{Err: ctx.Err()}
 		case <-waitChan:
 		}
 		m.mutex.Lock()
@@ -116,6 +116,18 @@ func (m *outgoingStreamsMap[T]) OpenStreamSync(ctx context.Context) (T, error) {
 	}
 }
 
+// NumAvailable returns the number of streams that can be opened without blocking.
+func (m *outgoingStreamsMap[T]) NumAvailable() int64 {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	if m.closeErr != nil {
+		return 0
+	}
+	// calls to OpenStreamSync that are waiting for a stream take precedence
+	return max(0, int64(m.maxStream-m.nextStream+1)-int64(len(m.openQueue)))
+}
+
 func (m *outgoingStreamsMap[T]) openStream() T {
 	s := m.newStream(m.nextStream)
 	m.streams[m.nextStream] = s
@@ -155,6 +167,16 @@ func (m *outgoingStreamsMap[T]) GetStream(num protocol.StreamNum) (T, error) {
 	return s, nil
 }
 
+// streamNums returns the numbers of all open streams.
+// The caller must hold the mutex.
+func (m *outgoingStreamsMap[T]) streamNums() []protocol.StreamNum {
+	nums := make([]protocol.StreamNum, 0, len(m.streams))
+	for num := range m.streams {
+		nums = append(nums, num)
+	}
+	return nums
+}
+
 func (m *outgoingStreamsMap[T]) DeleteStream(num protocol.StreamNum) error {
 	m.mutex.Lock()
 	defer m.mutex.Unlock()
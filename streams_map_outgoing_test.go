@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net"
 	"sort"
 	"sync"
 	"time"
@@ -165,7 +166,11 @@ var _ = Describe("Streams Map (outgoing)", func() {
 			go func() {
 				defer GinkgoRecover()
 				_, err := m.OpenStreamSync(ctx)
-				Expect(err).To(MatchError("context canceled"))
+				Expect(err).To(MatchError(context.Canceled))
+				var sbErr *StreamsBlockedError
+				Expect(errors.As(err, &sbErr)).To(BeTrue())
+				Expect(sbErr.Timeout()).To(BeFalse())
+				Expect(sbErr.Temporary()).To(BeTrue())
 				close(done)
 			}()
 			waitForEnqueued(1)
@@ -180,6 +185,45 @@ var _ = Describe("Streams Map (outgoing)", func() {
 			Expect(str.num).To(Equal(protocol.StreamNum(1)))
 		})
 
+		It("returns a timeout error when the context's deadline expires while blocked", func() {
+			mockSender.EXPECT().queueControlFrame(gomock.Any())
+			ctx, cancel := context.WithTimeout(context.Background(), scaleDuration(10*time.Millisecond))
+			defer cancel()
+			_, err := m.OpenStreamSync(ctx)
+			Expect(err).To(MatchError(context.DeadlineExceeded))
+			Expect(err).To(MatchError(&StreamsBlockedError{Err: context.DeadlineExceeded}))
+			var nerr net.Error
+			Expect(errors.As(err, &nerr)).To(BeTrue())
+			Expect(nerr.Timeout()).To(BeTrue())
+		})
+
+		It("says how many streams can be opened", func() {
+			Expect(m.NumAvailable()).To(BeZero())
+			m.SetMaxStream(3)
+			Expect(m.NumAvailable()).To(BeEquivalentTo(3))
+			_, err := m.OpenStream()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(m.NumAvailable()).To(BeEquivalentTo(2))
+			m.SetMaxStream(10)
+			Expect(m.NumAvailable()).To(BeEquivalentTo(9))
+			m.CloseWithError(errors.New("test done"))
+			Expect(m.NumAvailable()).To(BeZero())
+		})
+
+		It("doesn't count streams that will be opened by blocked OpenStreamSync calls", func() {
+			mockSender.EXPECT().queueControlFrame(gomock.Any()).AnyTimes()
+			for i := 1; i <= 2; i++ {
+				go func() {
+					defer GinkgoRecover()
+					_, err := m.OpenStreamSync(context.Background())
+					Expect(err).To(HaveOccurred())
+				}()
+				waitForEnqueued(i)
+			}
+			Expect(m.NumAvailable()).To(BeZero())
+			m.CloseWithError(errors.New("test done"))
+		})
+
 		It("opens streams in the right order", func() {
 			mockSender.EXPECT().queueControlFrame(gomock.Any()).AnyTimes()
 			done1 := make(chan struct{})
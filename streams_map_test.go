@@ -5,11 +5,13 @@ import (
 	"errors"
 	"fmt"
 	"net"
+	"slices"
 
 	"github.com/quic-go/quic-go/internal/flowcontrol"
 	"github.com/quic-go/quic-go/internal/mocks"
 	"github.com/quic-go/quic-go/internal/protocol"
 	"github.com/quic-go/quic-go/internal/qerr"
+	"github.com/quic-go/quic-go/internal/utils"
 	"github.com/quic-go/quic-go/internal/wire"
 
 	. "github.com/onsi/ginkgo/v2"
@@ -87,7 +89,7 @@ var _ = Describe("Streams Map", func() {
 
 			BeforeEach(func() {
 				mockSender = NewMockStreamSender(mockCtrl)
-				m = newStreamsMap(context.Background(), mockSender, newFlowController, MaxBidiStreamNum, MaxUniStreamNum, perspective).(*streamsMap)
+				m = newStreamsMap(context.Background(), mockSender, newFlowController, MaxBidiStreamNum, MaxUniStreamNum, nil, 0, perspective, utils.DefaultClock{}).(*streamsMap)
 			})
 
 			Context("opening", func() {
@@ -208,6 +210,39 @@ var _ = Describe("Streams Map", func() {
 					Expect(str.StreamID()).To(Equal(id))
 				})
 
+				It("enumerates the active streams", func() {
+					Expect(m.ActiveStreams()).To(BeEmpty())
+					_, err := m.OpenStream()
+					Expect(err).ToNot(HaveOccurred())
+					_, err = m.OpenStream()
+					Expect(err).ToNot(HaveOccurred())
+					_, err = m.OpenUniStream()
+					Expect(err).ToNot(HaveOccurred())
+					_, err = m.GetOrOpenReceiveStream(ids.firstIncomingBidiStream)
+					Expect(err).ToNot(HaveOccurred())
+					// opens the first two incoming unidirectional streams
+					_, err = m.GetOrOpenReceiveStream(ids.firstIncomingUniStream + 4)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(m.ActiveStreams()).To(ConsistOf(
+						ids.firstOutgoingBidiStream,
+						ids.firstOutgoingBidiStream+4,
+						ids.firstOutgoingUniStream,
+						ids.firstIncomingBidiStream,
+						ids.firstIncomingUniStream,
+						ids.firstIncomingUniStream+4,
+					))
+					Expect(slices.IsSorted(m.ActiveStreams())).To(BeTrue())
+					// deleted streams are not included
+					Expect(m.DeleteStream(ids.firstOutgoingBidiStream)).To(Succeed())
+					Expect(m.DeleteStream(ids.firstIncomingUniStream)).To(Succeed())
+					Expect(m.ActiveStreams()).To(ConsistOf(
+						ids.firstOutgoingBidiStream+4,
+						ids.firstOutgoingUniStream,
+						ids.firstIncomingBidiStream,
+						ids.firstIncomingUniStream+4,
+					))
+				})
+
 				It("errors when deleting unknown incoming unidirectional streams", func() {
 					id := ids.firstIncomingUniStream + 4
 					Expect(m.DeleteStream(id)).To(MatchError(fmt.Sprintf("tried to delete unknown incoming stream %d", id)))
@@ -427,6 +462,30 @@ var _ = Describe("Streams Map", func() {
 					_, err = m.OpenUniStream()
 					expectTooManyStreamsError(err)
 				})
+
+				It("updates the number of available streams", func() {
+					bidi, uni := m.StreamsAvailable()
+					Expect(bidi).To(BeZero())
+					Expect(uni).To(BeZero())
+					m.HandleMaxStreamsFrame(&wire.MaxStreamsFrame{
+						Type:         protocol.StreamTypeBidi,
+						MaxStreamNum: 5,
+					})
+					m.HandleMaxStreamsFrame(&wire.MaxStreamsFrame{
+						Type:         protocol.StreamTypeUni,
+						MaxStreamNum: 3,
+					})
+					bidi, uni = m.StreamsAvailable()
+					Expect(bidi).To(BeEquivalentTo(5))
+					Expect(uni).To(BeEquivalentTo(3))
+					_, err := m.OpenStream()
+					Expect(err).ToNot(HaveOccurred())
+					_, err = m.OpenUniStream()
+					Expect(err).ToNot(HaveOccurred())
+					bidi, uni = m.StreamsAvailable()
+					Expect(bidi).To(BeEquivalentTo(4))
+					Expect(uni).To(BeEquivalentTo(2))
+				})
 			})
 
 			Context("sending MAX_STREAMS frames", func() {
@@ -455,6 +514,18 @@ var _ = Describe("Streams Map", func() {
 				})
 			})
 
+			It("sets the initial number of incoming streams", func() {
+				m.SetInitialMaxIncomingStreams(2, 3)
+				_, err := m.GetOrOpenReceiveStream(ids.firstIncomingBidiStream + 4)
+				Expect(err).ToNot(HaveOccurred())
+				_, err = m.GetOrOpenReceiveStream(ids.firstIncomingBidiStream + 8)
+				Expect(err).To(HaveOccurred())
+				_, err = m.GetOrOpenReceiveStream(ids.firstIncomingUniStream + 8)
+				Expect(err).ToNot(HaveOccurred())
+				_, err = m.GetOrOpenReceiveStream(ids.firstIncomingUniStream + 12)
+				Expect(err).To(HaveOccurred())
+			})
+
 			It("closes", func() {
 				testErr := errors.New("test error")
 				m.CloseWithError(testErr)
@@ -475,10 +546,11 @@ var _ = Describe("Streams Map", func() {
 			if perspective == protocol.PerspectiveClient {
 				It("resets for 0-RTT", func() {
 					mockSender.EXPECT().queueControlFrame(gomock.Any()).AnyTimes()
-					m.ResetFor0RTT()
+					m.ResetFor0RTT(&ZeroRTTRejectedError{Reason: ZeroRTTRejectionReasonALPN})
 					// make sure that calls to open / accept streams fail
 					_, err := m.OpenStream()
 					Expect(err).To(MatchError(Err0RTTRejected))
+					Expect(err).To(Equal(&ZeroRTTRejectedError{Reason: ZeroRTTRejectionReasonALPN}))
 					_, err = m.AcceptStream(context.Background())
 					Expect(err).To(MatchError(Err0RTTRejected))
 					// make sure that we can still get new streams, as the server might be sending us data
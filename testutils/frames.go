@@ -5,10 +5,12 @@ import "github.com/quic-go/quic-go/internal/wire"
 type (
 	Frame                   = wire.Frame
 	AckFrame                = wire.AckFrame
+	AckFrequencyFrame       = wire.AckFrequencyFrame
 	ConnectionCloseFrame    = wire.ConnectionCloseFrame
 	CryptoFrame             = wire.CryptoFrame
 	DataBlockedFrame        = wire.DataBlockedFrame
 	HandshakeDoneFrame      = wire.HandshakeDoneFrame
+	ImmediateAckFrame       = wire.ImmediateAckFrame
 	MaxDataFrame            = wire.MaxDataFrame
 	MaxStreamDataFrame      = wire.MaxStreamDataFrame
 	MaxStreamsFrame         = wire.MaxStreamsFrame
@@ -18,6 +20,7 @@ type (
 	PathResponseFrame       = wire.PathResponseFrame
 	PingFrame               = wire.PingFrame
 	ResetStreamFrame        = wire.ResetStreamFrame
+	ResetStreamAtFrame      = wire.ResetStreamAtFrame
 	RetireConnectionIDFrame = wire.RetireConnectionIDFrame
 	StopSendingFrame        = wire.StopSendingFrame
 	StreamDataBlockedFrame  = wire.StreamDataBlockedFrame
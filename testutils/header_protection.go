@@ -0,0 +1,86 @@
+package testutils
+
+import (
+	"fmt"
+
+	"github.com/quic-go/quic-go/internal/handshake"
+	"github.com/quic-go/quic-go/internal/protocol"
+	"github.com/quic-go/quic-go/internal/wire"
+)
+
+// HeaderProtector applies and removes QUIC header protection, as defined in RFC 9001, Section 5.4.
+//
+// This is a low-level primitive that operates on raw packet bytes.
+// It is intended for interop testing and for building differential fuzzers against other QUIC implementations.
+// It does not validate the packet, and it is NOT safe for production use.
+type HeaderProtector struct {
+	long, short handshake.HeaderProtector
+}
+
+// NewHeaderProtector creates a new HeaderProtector from a header protection key.
+// cipherSuite is the TLS 1.3 cipher suite, e.g. tls.TLS_AES_128_GCM_SHA256.
+func NewHeaderProtector(cipherSuite uint16, hpKey []byte) (*HeaderProtector, error) {
+	long, err := handshake.NewHeaderProtector(cipherSuite, hpKey, true)
+	if err != nil {
+		return nil, err
+	}
+	short, err := handshake.NewHeaderProtector(cipherSuite, hpKey, false)
+	if err != nil {
+		return nil, err
+	}
+	return &HeaderProtector{long: long, short: short}, nil
+}
+
+// HeaderProtectionKey derives the header protection key from a traffic secret.
+func HeaderProtectionKey(cipherSuite uint16, trafficSecret []byte, version protocol.Version) ([]byte, error) {
+	return handshake.DeriveHeaderProtectionKey(cipherSuite, trafficSecret, version)
+}
+
+// Protect applies header protection to a packet, in place.
+// pnOffset is the offset of the packet number in the packet.
+// The length of the packet number is taken from the (unprotected) first byte.
+func (p *HeaderProtector) Protect(packet []byte, pnOffset int) error {
+	sample, err := getSample(packet, pnOffset)
+	if err != nil {
+		return err
+	}
+	pnLen := int(packet[0]&0x3) + 1
+	p.get(packet).EncryptHeader(sample, &packet[0], packet[pnOffset:pnOffset+pnLen])
+	return nil
+}
+
+// Unprotect removes header protection from a packet, in place.
+// pnOffset is the offset of the packet number in the packet.
+// It returns the length of the packet number.
+func (p *HeaderProtector) Unprotect(packet []byte, pnOffset int) (int, error) {
+	sample, err := getSample(packet, pnOffset)
+	if err != nil {
+		return 0, err
+	}
+	// The length of the packet number is only known after removing header protection from the first byte.
+	// Assume a 4 byte packet number, and restore the bytes that are not part of the packet number afterwards.
+	var origPNBytes [4]byte
+	copy(origPNBytes[:], packet[pnOffset:pnOffset+4])
+	p.get(packet).DecryptHeader(sample, &packet[0], packet[pnOffset:pnOffset+4])
+	pnLen := int(packet[0]&0x3) + 1
+	copy(packet[pnOffset+pnLen:pnOffset+4], origPNBytes[pnLen:])
+	return pnLen, nil
+}
+
+func (p *HeaderProtector) get(packet []byte) handshake.HeaderProtector {
+	if wire.IsLongHeaderPacket(packet[0]) {
+		return p.long
+	}
+	return p.short
+}
+
+// getSample returns the header protection sample, which starts 4 bytes after the packet number offset.
+func getSample(packet []byte, pnOffset int) ([]byte, error) {
+	if pnOffset < 1 {
+		return nil, fmt.Errorf("invalid packet number offset: %d", pnOffset)
+	}
+	if len(packet) < pnOffset+4+16 {
+		return nil, fmt.Errorf("packet too small: expected at least %d bytes, got %d", pnOffset+4+16, len(packet))
+	}
+	return packet[pnOffset+4 : pnOffset+4+16], nil
+}
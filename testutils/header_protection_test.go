@@ -0,0 +1,83 @@
+package testutils
+
+import (
+	"crypto/tls"
+	"encoding/hex"
+	"strings"
+
+	"github.com/quic-go/quic-go/internal/protocol"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Header Protection", func() {
+	splitHexString := func(s string) []byte {
+		data, err := hex.DecodeString(strings.ReplaceAll(s, " ", ""))
+		Expect(err).ToNot(HaveOccurred())
+		return data
+	}
+
+	// values taken from RFC 9001, Appendix A.1 and A.2
+	It("protects and unprotects a long header packet", func() {
+		key, err := HeaderProtectionKey(
+			tls.TLS_AES_128_GCM_SHA256,
+			splitHexString("c00cf151ca5be075ed0ebfb5c80323c42d6b7db67881289af4008f1f6c357aea"),
+			protocol.Version1,
+		)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(key).To(Equal(splitHexString("9f50449e04a0e810283a1e9933adedd2")))
+		hp, err := NewHeaderProtector(tls.TLS_AES_128_GCM_SHA256, key)
+		Expect(err).ToNot(HaveOccurred())
+
+		sample := splitHexString("d1b1c98dd7689fb8ec11d242b123dc9b")
+		packet := append(splitHexString("c300000001088394c8f03e5157080000449e00000002"), sample...)
+		Expect(hp.Protect(packet, 18)).To(Succeed())
+		Expect(packet).To(Equal(append(splitHexString("c000000001088394c8f03e5157080000449e7b9aec34"), sample...)))
+
+		pnLen, err := hp.Unprotect(packet, 18)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(pnLen).To(Equal(4))
+		Expect(packet).To(Equal(append(splitHexString("c300000001088394c8f03e5157080000449e00000002"), sample...)))
+	})
+
+	// values taken from RFC 9001, Appendix A.5
+	It("protects and unprotects a short header packet", func() {
+		key, err := HeaderProtectionKey(
+			tls.TLS_CHACHA20_POLY1305_SHA256,
+			splitHexString("9ac312a7f877468ebe69422748ad00a15443f18203a07d6060f688f30f21632b"),
+			protocol.Version1,
+		)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(key).To(Equal(splitHexString("25a282b9e82f06f21f488917a4fc8f1b73573685608597d0efcb076b0ab7a7a4")))
+		hp, err := NewHeaderProtector(tls.TLS_CHACHA20_POLY1305_SHA256, key)
+		Expect(err).ToNot(HaveOccurred())
+
+		// the first byte of the payload is not part of the packet number
+		payload := splitHexString("65 5e5cd55c41f69080575d7999c25a5bfb")
+		packet := append(splitHexString("4200bff4"), payload...)
+		Expect(hp.Protect(packet, 1)).To(Succeed())
+		Expect(packet).To(Equal(append(splitHexString("4cfe4189"), payload...)))
+
+		pnLen, err := hp.Unprotect(packet, 1)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(pnLen).To(Equal(3))
+		Expect(packet).To(Equal(append(splitHexString("4200bff4"), payload...)))
+	})
+
+	It("rejects packets that are too small to be sampled", func() {
+		hp, err := NewHeaderProtector(tls.TLS_AES_128_GCM_SHA256, make([]byte, 16))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(hp.Protect(make([]byte, 20), 1)).To(MatchError("packet too small: expected at least 21 bytes, got 20"))
+		_, err = hp.Unprotect(make([]byte, 20), 1)
+		Expect(err).To(MatchError("packet too small: expected at least 21 bytes, got 20"))
+		Expect(hp.Protect(make([]byte, 100), 0)).To(MatchError("invalid packet number offset: 0"))
+	})
+
+	It("rejects invalid keys", func() {
+		_, err := NewHeaderProtector(tls.TLS_AES_256_GCM_SHA384, make([]byte, 16))
+		Expect(err).To(MatchError("invalid header protection key length: 16 (expected 32)"))
+		_, err = NewHeaderProtector(0x42, make([]byte, 16))
+		Expect(err).To(MatchError("unsupported cipher suite: 0x42"))
+	})
+})
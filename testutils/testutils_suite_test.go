@@ -0,0 +1,13 @@
+package testutils
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestTestutils(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Testutils Suite")
+}
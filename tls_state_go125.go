@@ -0,0 +1,7 @@
+//go:build go1.25
+
+package quic
+
+import "crypto/tls"
+
+func keyExchangeGroup(cs *tls.ConnectionState) tls.CurveID { return cs.CurveID }
@@ -0,0 +1,7 @@
+//go:build go1.26
+
+package quic
+
+import "crypto/tls"
+
+func usedHelloRetryRequest(cs *tls.ConnectionState) bool { return cs.HelloRetryRequest }
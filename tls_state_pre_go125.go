@@ -0,0 +1,8 @@
+//go:build !go1.25
+
+package quic
+
+import "crypto/tls"
+
+// The key exchange group is only exposed by crypto/tls starting with Go 1.25.
+func keyExchangeGroup(*tls.ConnectionState) tls.CurveID { return 0 }
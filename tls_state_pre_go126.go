@@ -0,0 +1,8 @@
+//go:build !go1.26
+
+package quic
+
+import "crypto/tls"
+
+// The use of a HelloRetryRequest is only exposed by crypto/tls starting with Go 1.26.
+func usedHelloRetryRequest(*tls.ConnectionState) bool { return false }
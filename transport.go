@@ -5,9 +5,11 @@ import (
 	"crypto/rand"
 	"crypto/tls"
 	"errors"
+	"fmt"
 	"net"
 	"sync"
 	"sync/atomic"
+	"syscall"
 	"time"
 
 	"github.com/quic-go/quic-go/internal/protocol"
@@ -24,6 +26,8 @@ var errListenerAlreadySet = errors.New("listener already set")
 // for dialing an arbitrary number of outgoing connections.
 // A Transport handles a single net.PacketConn, and offers a range of configuration options
 // compared to the simple helper functions like Listen and Dial that this package provides.
+// The callbacks configured on the Transport are called synchronously while packets are received,
+// which delays the processing of all subsequent packets, so they must return quickly.
 type Transport struct {
 	// A single net.PacketConn can only be handled by one Transport.
 	// Bad things will happen if passed to multiple Transports.
@@ -41,9 +45,16 @@ type Transport struct {
 	Conn net.PacketConn
 
 	// The length of the connection ID in bytes.
-	// It can be any value between 1 and 20.
+	// It can be any value between 1 and 20. Other values are rejected when the Transport is first used.
 	// Due to the increased risk of collisions, it is not recommended to use connection IDs shorter than 4 bytes.
-	// If unset, a 4 byte connection ID will be used.
+	// If unset, a 4 byte connection ID will be used. Connections dialed using Dial and DialAddr (which don't
+	// share the underlying UDP socket) use 0 byte connection IDs.
+	//
+	// All connection IDs issued by a Transport have the same length, since the length is needed to parse
+	// the header of short header packets. Load balancers that route packets based on the connection ID
+	// therefore need to be configured with the same length. Routing on the content of the connection ID
+	// requires a ConnectionIDGenerator.
+	// When not using a Transport, the length is configured using Config.ConnectionIDLength.
 	ConnectionIDLength int
 
 	// Use for generating new connection IDs.
@@ -73,6 +84,17 @@ type Transport struct {
 	// See section 8.1.3 of RFC 9000 for details.
 	MaxTokenAge time.Duration
 
+	// RetryTokenGenerator generates and validates the tokens sent in Retry packets.
+	// This allows applications to embed their own data in Retry tokens, and
+	// to control their lifetime.
+	// If not set, Retry tokens are encrypted using the TokenGeneratorKey, and
+	// are valid for the duration configured by Config.HandshakeIdleTimeout.
+	// Tokens sent in NEW_TOKEN frames are not affected by this option.
+	// If a token can't be decoded using the TokenGeneratorKey, and IsRetryToken returns true,
+	// it is passed to ValidateRetryToken. If validation fails, the connection attempt is rejected
+	// with an INVALID_TOKEN error. Tokens that are not Retry tokens are ignored.
+	RetryTokenGenerator RetryTokenGenerator
+
 	// DisableVersionNegotiationPackets disables the sending of Version Negotiation packets.
 	// This can be useful if version information is exchanged out-of-band.
 	// It has no effect for clients.
@@ -89,6 +111,13 @@ type Transport struct {
 	// implementation of this callback (negating its return value).
 	VerifySourceAddress func(net.Addr) bool
 
+	// AcceptFilter decides if an Initial packet that would create a new connection is processed.
+	// It is called with the (unvalidated) remote address and the unprotected part of the packet header,
+	// before any cryptographic operations are performed and before any connection state is created.
+	// If it returns false, the packet is dropped.
+	// Packets belonging to connections that were already accepted are not passed to this callback.
+	AcceptFilter func(remoteAddr net.Addr, hdr *logging.Header) bool
+
 	// ConnContext is called when the server accepts a new connection.
 	// The context is closed when the connection is closed, or when the handshake fails for any reason.
 	// The context returned from the callback is used to derive every other context used during the
@@ -100,10 +129,46 @@ type Transport struct {
 	// It is not used for dialed connections.
 	ConnContext func(context.Context) context.Context
 
+	// StatelessResetReceived is called when a stateless reset is received for one of the connections
+	// handled by this Transport. It is passed the remote address the packet was received from, and the
+	// stateless reset token that was matched to the connection.
+	// The connection is closed with a StatelessResetError afterwards.
+	StatelessResetReceived func(remoteAddr net.Addr, token StatelessResetToken)
+
+	// MaxConnections is the maximum number of connections handled by this Transport, see NumConnections.
+	// Once the limit is reached, new connection attempts are refused with a CONNECTION_REFUSED error,
+	// before any cryptographic operations are performed. Connections free their slot as soon as they are closed.
+	// Dialing new connections is not limited, but dialed connections count towards the limit.
+	// This is a coarse admission control mechanism, complementing Config.MaxHandshakeRate.
+	// If zero, the number of connections is not limited.
+	MaxConnections int
+
+	// OnConnectionRejected is called when a connection attempt is refused because MaxConnections was reached.
+	// It is passed the (unvalidated) remote address of the connection attempt.
+	OnConnectionRejected func(remoteAddr net.Addr)
+
 	// A Tracer traces events that don't belong to a single QUIC connection.
 	// Tracer.Close is called when the transport is closed.
 	Tracer *logging.Tracer
 
+	// PacketInterceptor intercepts all packets sent by the Transport, see PacketInterceptor for details.
+	// It is intended for testing.
+	// Setting it disables Generic Segmentation Offload (GSO).
+	PacketInterceptor PacketInterceptor
+
+	// SetSocketOptions is called when the Transport is first used, and allows setting socket options
+	// (e.g. IP_TOS for DSCP marking, or SO_SNDBUF) on the underlying socket.
+	// It is called after quic-go configured the socket, so it overrides the send and receive buffer sizes set by quic-go.
+	// It requires Conn to implement SyscallConn, as *net.UDPConn does.
+	// If it returns an error, the Transport can't be used, and the error is returned from Listen and Dial.
+	//
+	// The available socket options, and their effect, depend on the platform:
+	// * On Linux and macOS, IP_TOS (IPv4) and IPV6_TCLASS (IPv6) set the DSCP bits of outgoing packets.
+	//   However, when sending ECN-marked packets, the TOS / Traffic Class is set on every packet,
+	//   overriding the DSCP bits set on the socket. Use Config.DisableECN to send packets with DSCP markings.
+	// * On Windows, setting IP_TOS has no effect by default, and DSCP marking requires the QoS2 API.
+	SetSocketOptions func(syscall.RawConn) error
+
 	handlerMap packetHandlerManager
 
 	mutex    sync.Mutex
@@ -119,7 +184,8 @@ type Transport struct {
 
 	server *baseServer
 
-	conn rawConn
+	conn       rawConn
+	gsoEnabled atomic.Bool // set in init
 
 	closeQueue          chan closePacket
 	statelessResetQueue chan receivedPacket
@@ -131,6 +197,12 @@ type Transport struct {
 
 	readingNonQUICPackets atomic.Bool
 	nonQUICPackets        chan receivedPacket
+	unknownPacketHandler  atomic.Pointer[func([]byte, net.Addr)]
+
+	// Connections are removed from their own goroutines, which can run while mutex is held (e.g. in Close).
+	connMutex sync.Mutex
+	numConns  int
+	onEmpty   []func()
 
 	logger utils.Logger
 }
@@ -172,9 +244,13 @@ func (t *Transport) createServer(tlsConf *tls.Config, conf *Config, allow0RTT bo
 		return nil, errListenerAlreadySet
 	}
 	conf = populateConfig(conf)
-	if err := t.init(false); err != nil {
+	if err := t.initWithConfig(conf, false); err != nil {
 		return nil, err
 	}
+	var admitConn func(net.Addr) bool
+	if t.MaxConnections > 0 {
+		admitConn = t.admitConn
+	}
 	s := newServer(
 		t.conn,
 		t.handlerMap,
@@ -184,9 +260,14 @@ func (t *Transport) createServer(tlsConf *tls.Config, conf *Config, allow0RTT bo
 		conf,
 		t.Tracer,
 		t.closeServer,
+		t.addConn,
+		t.removeConn,
 		*t.TokenGeneratorKey,
 		t.MaxTokenAge,
+		t.RetryTokenGenerator,
 		t.VerifySourceAddress,
+		t.AcceptFilter,
+		admitConn,
 		t.DisableVersionNegotiationPackets,
 		allow0RTT,
 	)
@@ -209,20 +290,115 @@ func (t *Transport) dial(ctx context.Context, addr net.Addr, host string, tlsCon
 		return nil, err
 	}
 	conf = populateConfig(conf)
-	if err := t.init(t.isSingleUse); err != nil {
+	if err := t.initWithConfig(conf, t.isSingleUse); err != nil {
 		return nil, err
 	}
-	var onClose func()
-	if t.isSingleUse {
-		onClose = func() { t.Close() }
+	// The connection is counted from the start of the dial, so that the OnEmpty callbacks are not called
+	// while connections are being established.
+	t.addConn()
+	var removeOnce sync.Once
+	removeConn := func() { removeOnce.Do(t.removeConn) }
+	onClose := func() {
+		removeConn()
+		if t.isSingleUse {
+			t.Close()
+		}
 	}
 	tlsConf = tlsConf.Clone()
 	setTLSConfigServerName(tlsConf, addr, host)
-	return dial(ctx, newSendConn(t.conn, addr, packetInfo{}, utils.DefaultLogger), t.connIDGenerator, t.handlerMap, tlsConf, conf, onClose, use0RTT)
+	conn, err := dial(ctx, newSendConn(t.conn, addr, packetInfo{}, utils.DefaultLogger), t.connIDGenerator, t.handlerMap, tlsConf, conf, onClose, use0RTT)
+	if err != nil {
+		removeConn()
+		return nil, err
+	}
+	return conn, nil
+}
+
+// NumConnections returns the number of connections handled by this Transport.
+// This includes connections that are currently being dialed, and connections accepted by the Listener,
+// starting from the moment the first packet of the connection is processed.
+// Connections are counted until they are closed.
+func (t *Transport) NumConnections() int {
+	t.connMutex.Lock()
+	defer t.connMutex.Unlock()
+	return t.numConns
+}
+
+// OnEmpty registers a callback that is called once the Transport doesn't handle any connections anymore,
+// see NumConnections. If the Transport doesn't handle any connections at the time OnEmpty is called,
+// the callback is executed right away.
+// Every registered callback is called exactly once. Since connections that are being dialed are counted
+// as well, the callback is never called while a call to Dial is in progress. However, new connections
+// can be dialed or accepted right after the callback was called.
+// The callback is run by the goroutine that removes the last connection, e.g. while a connection is being closed.
+func (t *Transport) OnEmpty(f func()) {
+	t.connMutex.Lock()
+	if t.numConns > 0 {
+		t.onEmpty = append(t.onEmpty, f)
+		t.connMutex.Unlock()
+		return
+	}
+	t.connMutex.Unlock()
+	f()
+}
+
+// admitConn says if a new incoming connection can be accepted, given the MaxConnections limit.
+func (t *Transport) admitConn(remoteAddr net.Addr) bool {
+	t.connMutex.Lock()
+	full := t.numConns >= t.MaxConnections
+	t.connMutex.Unlock()
+	if full && t.OnConnectionRejected != nil {
+		t.OnConnectionRejected(remoteAddr)
+	}
+	return !full
+}
+
+func (t *Transport) addConn() {
+	t.connMutex.Lock()
+	t.numConns++
+	t.connMutex.Unlock()
+}
+
+func (t *Transport) removeConn() {
+	t.connMutex.Lock()
+	t.numConns--
+	var callbacks []func()
+	if t.numConns == 0 {
+		callbacks = t.onEmpty
+		t.onEmpty = nil
+	}
+	t.connMutex.Unlock()
+	for _, cb := range callbacks {
+		cb()
+	}
+}
+
+func (t *Transport) setSocketOptions() error {
+	c, ok := t.Conn.(interface {
+		SyscallConn() (syscall.RawConn, error)
+	})
+	if !ok {
+		return fmt.Errorf("cannot set socket options on a %T", t.Conn)
+	}
+	rawConn, err := c.SyscallConn()
+	if err != nil {
+		return err
+	}
+	return t.SetSocketOptions(rawConn)
 }
 
 func (t *Transport) init(allowZeroLengthConnIDs bool) error {
 	t.initOnce.Do(func() {
+		if t.ConnectionIDGenerator != nil {
+			if l := t.ConnectionIDGenerator.ConnectionIDLen(); l < 0 || l > protocol.MaxConnIDLen {
+				t.initErr = fmt.Errorf("invalid connection ID length of the ConnectionIDGenerator: %d", l)
+				return
+			}
+		} else if t.ConnectionIDLength < 0 || t.ConnectionIDLength > protocol.MaxConnIDLen {
+			t.initErr = fmt.Errorf("invalid connection ID length: %d", t.ConnectionIDLength)
+			return
+		}
+
 		var conn rawConn
 		if c, ok := t.Conn.(rawConn); ok {
 			conn = c
@@ -235,8 +411,20 @@ func (t *Transport) init(allowZeroLengthConnIDs bool) error {
 			}
 		}
 
+		if t.SetSocketOptions != nil {
+			if err := t.setSocketOptions(); err != nil {
+				t.initErr = err
+				return
+			}
+		}
+
+		if t.PacketInterceptor != nil {
+			conn = newInterceptingConn(conn, t.PacketInterceptor)
+		}
+
 		t.logger = utils.DefaultLogger // TODO: make this configurable
 		t.conn = conn
+		t.gsoEnabled.Store(conn.capabilities().GSO)
 		t.handlerMap = newPacketHandlerMap(t.StatelessResetKey, t.enqueueClosePacket, t.logger)
 		t.listening = make(chan struct{})
 
@@ -270,6 +458,41 @@ func (t *Transport) init(allowZeroLengthConnIDs bool) error {
 	return t.initErr
 }
 
+// initWithConfig initializes the Transport, taking into account the Config.ConnectionIDLength.
+// Transports created by ListenAddr, Listen, DialAddr and Dial use the length configured in the Config,
+// all other Transports need to use the same length as the Config.
+func (t *Transport) initWithConfig(conf *Config, allowZeroLengthConnIDs bool) error {
+	if t.isSingleUse && t.ConnectionIDGenerator == nil && conf.ConnectionIDLength > 0 {
+		t.ConnectionIDLength = conf.ConnectionIDLength
+	}
+	if err := t.init(allowZeroLengthConnIDs); err != nil {
+		return err
+	}
+	if conf.ConnectionIDLength > 0 && conf.ConnectionIDLength != t.connIDLen {
+		return fmt.Errorf("quic: Config.ConnectionIDLength (%d) doesn't match the Transport's connection ID length (%d)", conf.ConnectionIDLength, t.connIDLen)
+	}
+	return nil
+}
+
+// PrewarmBuffers allocates n packet buffers and adds them to the packet buffer pool.
+// This avoids allocating buffers (and the resulting GC pressure) when a burst of packets is received,
+// e.g. on a server that expects a large number of connection attempts right after startup.
+// The prewarmed buffers are used like any other buffer in the pool.
+// The pool is shared by all Transports. Like all pooled buffers, buffers that remain unused
+// for a while might be freed by the garbage collector.
+// It is a no-op if n is not positive.
+func (t *Transport) PrewarmBuffers(n int) {
+	prewarmPacketBuffers(n)
+}
+
+// GSOEnabled says if the underlying connection supports Generic Segmentation Offload (GSO).
+// It returns false until the Transport is first used, e.g. by calling Dial or Listen.
+// A connection might still not use GSO, if Config.DisableGSO is set, or if sending with GSO fails.
+// Use ConnectionState.GSO to find out if a given connection uses GSO.
+func (t *Transport) GSOEnabled() bool {
+	return t.gsoEnabled.Load()
+}
+
 // WriteTo sends a packet on the underlying connection.
 func (t *Transport) WriteTo(b []byte, addr net.Addr) (int, error) {
 	if err := t.init(false); err != nil {
@@ -394,13 +617,38 @@ func (t *Transport) handlePacket(p receivedPacket) {
 	if len(p.data) == 0 {
 		return
 	}
-	if !wire.IsPotentialQUICPacket(p.data[0]) && !wire.IsLongHeaderPacket(p.data[0]) {
-		t.handleNonQUICPacket(p)
+	// The QUIC bit of Version Negotiation packets is not defined (RFC 8999, section 6).
+	if !wire.IsPotentialQUICPacket(p.data[0]) && !wire.IsVersionNegotiationPacket(p.data) {
+		// The peer might have greased the QUIC bit (RFC 9287), if the connection allowed it to.
+		// Zero-length connection IDs match every packet, so greasing can't be used with them.
+		if t.connIDLen > 0 {
+			if connID, err := wire.ParseConnectionID(p.data, t.connIDLen); err == nil {
+				if handler, ok := t.handlerMap.Get(connID); ok && handler.acceptsGreasedQUICBit() {
+					handler.handlePacket(p)
+					return
+				}
+			}
+		}
+		if !wire.IsLongHeaderPacket(p.data[0]) {
+			t.handleNonQUICPacket(p)
+			return
+		}
+		// This might be an RTP or RTCP packet (RFC 7983).
+		if t.maybeHandleUnknownPacket(p) {
+			return
+		}
+		if t.Tracer != nil && t.Tracer.DroppedPacket != nil {
+			t.Tracer.DroppedPacket(p.remoteAddr, logging.PacketTypeNotDetermined, p.Size(), logging.PacketDropHeaderParseError)
+		}
+		p.buffer.MaybeRelease()
 		return
 	}
 	connID, err := wire.ParseConnectionID(p.data, t.connIDLen)
 	if err != nil {
 		t.logger.Debugf("error parsing connection ID on packet from %s: %s", p.remoteAddr, err)
+		if t.maybeHandleUnknownPacket(p) {
+			return
+		}
 		if t.Tracer != nil && t.Tracer.DroppedPacket != nil {
 			t.Tracer.DroppedPacket(p.remoteAddr, logging.PacketTypeNotDetermined, p.Size(), logging.PacketDropHeaderParseError)
 		}
@@ -420,21 +668,47 @@ func (t *Transport) handlePacket(p receivedPacket) {
 	// Stateless resets use random connection IDs, and at reasonable connection ID lengths collisions are
 	// exceedingly rare. In the unlikely event that a stateless reset is misrouted to an existing connection,
 	// it is to be expected that the next stateless reset will be correctly detected.
-	if isStatelessReset := t.maybeHandleStatelessReset(p.data); isStatelessReset {
+	if isStatelessReset := t.maybeHandleStatelessReset(p.data, p.remoteAddr); isStatelessReset {
 		return
 	}
 	if !wire.IsLongHeaderPacket(p.data[0]) {
 		t.maybeSendStatelessReset(p)
 		return
 	}
+	// Only packets that might start a new connection are of interest to the server.
+	if !mightStartNewConnection(p.data) && t.maybeHandleUnknownPacket(p) {
+		return
+	}
 
 	t.mutex.Lock()
-	defer t.mutex.Unlock()
 	if t.server == nil { // no server set
+		t.mutex.Unlock()
 		t.logger.Debugf("received a packet with an unexpected connection ID %s", connID)
+		t.maybeHandleUnknownPacket(p)
 		return
 	}
 	t.server.handlePacket(p)
+	t.mutex.Unlock()
+}
+
+// mightStartNewConnection says if a long header packet might start a new connection on the server:
+// Initial and 0-RTT packets, as well as packets with an unknown version, which elicit a Version Negotiation packet.
+func mightStartNewConnection(data []byte) bool {
+	v, err := wire.ParseVersion(data)
+	if err != nil {
+		return false
+	}
+	//nolint:exhaustive // We only need to test QUIC versions that we support.
+	switch v {
+	case 0: // Version Negotiation packets are only sent by servers
+		return false
+	case protocol.Version1:
+		return data[0]>>4&0b11 == 0b00 || wire.Is0RTTPacket(data)
+	case protocol.Version2:
+		return data[0]>>4&0b11 == 0b01 || wire.Is0RTTPacket(data)
+	default:
+		return true
+	}
 }
 
 func (t *Transport) maybeSendStatelessReset(p receivedPacket) {
@@ -477,7 +751,7 @@ func (t *Transport) sendStatelessReset(p receivedPacket) {
 	}
 }
 
-func (t *Transport) maybeHandleStatelessReset(data []byte) bool {
+func (t *Transport) maybeHandleStatelessReset(data []byte, remoteAddr net.Addr) bool {
 	// stateless resets are always short header packets
 	if wire.IsLongHeaderPacket(data[0]) {
 		return false
@@ -489,6 +763,9 @@ func (t *Transport) maybeHandleStatelessReset(data []byte) bool {
 	token := *(*protocol.StatelessResetToken)(data[len(data)-16:])
 	if conn, ok := t.handlerMap.GetByResetToken(token); ok {
 		t.logger.Debugf("Received a stateless reset with token %#x. Closing connection.", token)
+		if t.StatelessResetReceived != nil {
+			t.StatelessResetReceived(remoteAddr, token)
+		}
 		go conn.destroy(&StatelessResetError{Token: token})
 		return true
 	}
@@ -496,6 +773,9 @@ func (t *Transport) maybeHandleStatelessReset(data []byte) bool {
 }
 
 func (t *Transport) handleNonQUICPacket(p receivedPacket) {
+	if t.maybeHandleUnknownPacket(p) {
+		return
+	}
 	// Strictly speaking, this is racy,
 	// but we only care about receiving packets at some point after ReadNonQUICPacket has been called.
 	if !t.readingNonQUICPackets.Load() {
@@ -510,6 +790,44 @@ func (t *Transport) handleNonQUICPacket(p receivedPacket) {
 	}
 }
 
+// maybeHandleUnknownPacket passes a packet to the handler set by SetUnknownPacketHandler.
+// It returns false if no handler is set.
+func (t *Transport) maybeHandleUnknownPacket(p receivedPacket) bool {
+	h := t.unknownPacketHandler.Load()
+	if h == nil {
+		return false
+	}
+	(*h)(p.data, p.remoteAddr)
+	p.buffer.MaybeRelease()
+	return true
+}
+
+// SetUnknownPacketHandler sets a handler for packets that can't be processed by QUIC,
+// allowing the underlying connection to be shared with other protocols (e.g. STUN, for ICE).
+// The handler is called for:
+//   - non-QUIC packets, i.e. packets that have the first and second bit set to 0,
+//     unless they belong to a QUIC connection that greased the QUIC bit (RFC 9287),
+//   - packets whose connection ID can't be parsed,
+//   - long header packets with the QUIC bit set to 0, such as RTP and RTCP packets (RFC 7983),
+//     unless they belong to a QUIC connection that greased the QUIC bit, and
+//   - long header packets not belonging to any connection, unless the Transport is listening for incoming connections
+//     and the packet might start a new connection, i.e. if it is an Initial or a 0-RTT packet,
+//     or if it uses an unknown QUIC version.
+//
+// Packets that are handled by the handler are not returned from ReadNonQUICPacket.
+// Short header packets not belonging to any connection are not passed to the handler,
+// since they might elicit a stateless reset.
+//
+// The data slice is reused after the handler returns, so the handler must copy it if it needs to retain it.
+// Calling SetUnknownPacketHandler with a nil handler removes the handler.
+func (t *Transport) SetUnknownPacketHandler(h func(data []byte, addr net.Addr)) {
+	if h == nil {
+		t.unknownPacketHandler.Store(nil)
+		return
+	}
+	t.unknownPacketHandler.Store(&h)
+}
+
 const maxQueuedNonQUICPackets = 32
 
 // ReadNonQUICPacket reads non-QUIC packets received on the underlying connection.
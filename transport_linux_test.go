@@ -0,0 +1,45 @@
+//go:build linux
+
+package quic
+
+import (
+	"net"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Transport socket options", func() {
+	It("sets IP_TOS", func() {
+		conn, err := net.ListenUDP("udp4", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 0})
+		Expect(err).ToNot(HaveOccurred())
+		const tos = 0xb8 // DSCP EF (46)
+		tr := &Transport{
+			Conn: conn,
+			SetSocketOptions: func(c syscall.RawConn) error {
+				var serr error
+				if err := c.Control(func(fd uintptr) {
+					serr = unix.SetsockoptInt(int(fd), unix.IPPROTO_IP, unix.IP_TOS, tos)
+				}); err != nil {
+					return err
+				}
+				return serr
+			},
+		}
+		defer tr.Close()
+		Expect(tr.init(true)).To(Succeed())
+
+		rawConn, err := conn.SyscallConn()
+		Expect(err).ToNot(HaveOccurred())
+		var val int
+		var gerr error
+		Expect(rawConn.Control(func(fd uintptr) {
+			val, gerr = unix.GetsockoptInt(int(fd), unix.IPPROTO_IP, unix.IP_TOS)
+		})).To(Succeed())
+		Expect(gerr).ToNot(HaveOccurred())
+		Expect(val).To(Equal(tos))
+	})
+})
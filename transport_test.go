@@ -7,6 +7,7 @@ import (
 	"crypto/tls"
 	"errors"
 	"net"
+	"slices"
 	"syscall"
 	"time"
 
@@ -105,6 +106,38 @@ var _ = Describe("Transport", func() {
 		tr.Close()
 	})
 
+	It("passes short header packets with a greased QUIC bit to the packet handler", func() {
+		packetChan := make(chan packetToRead)
+		tr := &Transport{Conn: newMockPacketConn(packetChan), ConnectionIDLength: 8}
+		tr.init(true)
+		phm := NewMockPacketHandlerManager(mockCtrl)
+		tr.handlerMap = phm
+		connID := protocol.ParseConnectionID([]byte{1, 2, 3, 4, 5, 6, 7, 8})
+
+		handled := make(chan struct{})
+		phm.EXPECT().Get(connID).DoAndReturn(func(protocol.ConnectionID) (packetHandler, bool) {
+			h := NewMockPacketHandler(mockCtrl)
+			h.EXPECT().acceptsGreasedQUICBit().Return(true)
+			h.EXPECT().handlePacket(gomock.Any()).Do(func(p receivedPacket) {
+				defer GinkgoRecover()
+				Expect(wire.IsPotentialQUICPacket(p.data[0])).To(BeFalse())
+				close(handled)
+			})
+			return h, true
+		})
+
+		b, err := wire.AppendShortHeader(nil, connID, 1337, protocol.PacketNumberLen2, protocol.KeyPhaseOne)
+		Expect(err).ToNot(HaveOccurred())
+		b[0] &^= 0x40 // grease the QUIC bit
+		packetChan <- packetToRead{data: append(b, make([]byte, 20)...)}
+		Eventually(handled).Should(BeClosed())
+
+		// shutdown
+		phm.EXPECT().Close(gomock.Any())
+		close(packetChan)
+		tr.Close()
+	})
+
 	It("closes listeners", func() {
 		packetChan := make(chan packetToRead)
 		tr := &Transport{Conn: newMockPacketConn(packetChan)}
@@ -278,6 +311,52 @@ var _ = Describe("Transport", func() {
 		tr.Close()
 	})
 
+	It("calls the callback when a stateless reset is received", func() {
+		connID := protocol.ParseConnectionID([]byte{2, 3, 4, 5})
+		packetChan := make(chan packetToRead)
+		type resetReceived struct {
+			addr  net.Addr
+			token StatelessResetToken
+		}
+		resets := make(chan resetReceived, 1)
+		tr := Transport{
+			Conn:               newMockPacketConn(packetChan),
+			ConnectionIDLength: connID.Len(),
+			StatelessResetReceived: func(addr net.Addr, token StatelessResetToken) {
+				resets <- resetReceived{addr: addr, token: token}
+			},
+		}
+		tr.init(true)
+		defer tr.Close()
+		phm := NewMockPacketHandlerManager(mockCtrl)
+		tr.handlerMap = phm
+
+		var token protocol.StatelessResetToken
+		rand.Read(token[:])
+
+		var b []byte
+		b, err := wire.AppendShortHeader(b, connID, 1337, 2, protocol.KeyPhaseOne)
+		Expect(err).ToNot(HaveOccurred())
+		b = append(b, token[:]...)
+		conn := NewMockPacketHandler(mockCtrl)
+		phm.EXPECT().Get(connID)
+		phm.EXPECT().GetByResetToken(token).Return(conn, true)
+		destroyed := make(chan struct{})
+		conn.EXPECT().destroy(gomock.Any()).Do(func(error) { close(destroyed) })
+		remoteAddr := &net.UDPAddr{IP: net.IPv4(192, 168, 0, 1), Port: 4321}
+		packetChan <- packetToRead{addr: remoteAddr, data: b}
+		var r resetReceived
+		Eventually(resets).Should(Receive(&r))
+		Expect(r.addr).To(Equal(remoteAddr))
+		Expect(r.token).To(Equal(token))
+		Eventually(destroyed).Should(BeClosed())
+
+		// shutdown
+		phm.EXPECT().Close(gomock.Any())
+		close(packetChan)
+		tr.Close()
+	})
+
 	It("sends stateless resets", func() {
 		connID := protocol.ParseConnectionID([]byte{2, 3, 4, 5})
 		packetChan := make(chan packetToRead)
@@ -319,6 +398,68 @@ var _ = Describe("Transport", func() {
 		tr.Close()
 	})
 
+	It("says if GSO is enabled", func() {
+		udpConn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 0})
+		Expect(err).ToNot(HaveOccurred())
+		tr := &Transport{Conn: udpConn}
+		defer tr.Close()
+		Expect(tr.GSOEnabled()).To(BeFalse())
+		Expect(tr.init(true)).To(Succeed())
+		Expect(tr.GSOEnabled()).To(Equal(tr.conn.capabilities().GSO))
+	})
+
+	It("counts connections and calls the OnEmpty callbacks", func() {
+		tr := &Transport{}
+		Expect(tr.NumConnections()).To(BeZero())
+		tr.addConn()
+		tr.addConn()
+		Expect(tr.NumConnections()).To(Equal(2))
+		var called1, called2 int
+		tr.OnEmpty(func() { called1++ })
+		tr.OnEmpty(func() { called2++ })
+		tr.removeConn()
+		Expect(tr.NumConnections()).To(Equal(1))
+		Expect(called1).To(BeZero())
+		Expect(called2).To(BeZero())
+		tr.removeConn()
+		Expect(tr.NumConnections()).To(BeZero())
+		Expect(called1).To(Equal(1))
+		Expect(called2).To(Equal(1))
+		// callbacks are only called once
+		tr.addConn()
+		tr.removeConn()
+		Expect(called1).To(Equal(1))
+		Expect(called2).To(Equal(1))
+	})
+
+	It("limits the number of connections", func() {
+		var rejected []net.Addr
+		tr := &Transport{
+			MaxConnections:       2,
+			OnConnectionRejected: func(addr net.Addr) { rejected = append(rejected, addr) },
+		}
+		addr1 := &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 1}
+		addr2 := &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 2}
+		Expect(tr.admitConn(addr1)).To(BeTrue())
+		tr.addConn()
+		Expect(tr.admitConn(addr1)).To(BeTrue())
+		tr.addConn()
+		Expect(tr.admitConn(addr1)).To(BeFalse())
+		Expect(tr.admitConn(addr2)).To(BeFalse())
+		Expect(rejected).To(Equal([]net.Addr{addr1, addr2}))
+		// closing a connection frees a slot
+		tr.removeConn()
+		Expect(tr.admitConn(addr2)).To(BeTrue())
+		Expect(rejected).To(HaveLen(2))
+	})
+
+	It("calls the OnEmpty callback right away if there are no connections", func() {
+		tr := &Transport{}
+		var called bool
+		tr.OnEmpty(func() { called = true })
+		Expect(called).To(BeTrue())
+	})
+
 	It("closes uninitialized Transport and closes underlying PacketConn", func() {
 		packetChan := make(chan packetToRead)
 		pconn := newMockPacketConn(packetChan)
@@ -350,6 +491,97 @@ var _ = Describe("Transport", func() {
 		Expect(len(conns)).To(BeZero())
 	})
 
+	It("rejects invalid connection ID lengths", func() {
+		tr := &Transport{Conn: newMockPacketConn(make(chan packetToRead)), ConnectionIDLength: 21}
+		Expect(tr.init(false)).To(MatchError("invalid connection ID length: 21"))
+		tr = &Transport{Conn: newMockPacketConn(make(chan packetToRead)), ConnectionIDLength: -1}
+		Expect(tr.init(false)).To(MatchError("invalid connection ID length: -1"))
+		tr = &Transport{
+			Conn:                  newMockPacketConn(make(chan packetToRead)),
+			ConnectionIDGenerator: &protocol.DefaultConnectionIDGenerator{ConnLen: 21},
+		}
+		Expect(tr.init(false)).To(MatchError("invalid connection ID length of the ConnectionIDGenerator: 21"))
+	})
+
+	It("uses the connection ID length from the Config for single-use Transports", func() {
+		packetChan := make(chan packetToRead)
+		tr := &Transport{Conn: newMockPacketConn(packetChan), isSingleUse: true}
+		ln, err := tr.Listen(&tls.Config{}, &Config{ConnectionIDLength: 20})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(tr.connIDLen).To(Equal(20))
+		Expect(tr.connIDGenerator.ConnectionIDLen()).To(Equal(20))
+
+		// shutdown
+		close(packetChan)
+		ln.Close()
+	})
+
+	It("rejects a Config with a connection ID length different from the Transport's", func() {
+		packetChan := make(chan packetToRead)
+		tr := &Transport{Conn: newMockPacketConn(packetChan), ConnectionIDLength: 8}
+		_, err := tr.Listen(&tls.Config{}, &Config{ConnectionIDLength: 20})
+		Expect(err).To(MatchError("quic: Config.ConnectionIDLength (20) doesn't match the Transport's connection ID length (8)"))
+		_, err = tr.Dial(context.Background(), &net.UDPAddr{}, &tls.Config{}, &Config{ConnectionIDLength: 4})
+		Expect(err).To(MatchError("quic: Config.ConnectionIDLength (4) doesn't match the Transport's connection ID length (8)"))
+		ln, err := tr.Listen(&tls.Config{}, &Config{ConnectionIDLength: 8})
+		Expect(err).ToNot(HaveOccurred())
+
+		// shutdown
+		ln.Close()
+		close(packetChan)
+		tr.Close()
+	})
+
+	It("returns the error from setting socket options", func() {
+		conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 0})
+		Expect(err).ToNot(HaveOccurred())
+		defer conn.Close()
+		var called bool
+		tr := &Transport{
+			Conn: conn,
+			SetSocketOptions: func(syscall.RawConn) error {
+				called = true
+				return errors.New("test error")
+			},
+		}
+		_, err = tr.Listen(&tls.Config{}, nil)
+		Expect(err).To(MatchError("test error"))
+		Expect(called).To(BeTrue())
+	})
+
+	It("doesn't set socket options if the PacketConn doesn't expose the socket", func() {
+		tr := &Transport{
+			Conn:             newMockPacketConn(make(chan packetToRead)),
+			SetSocketOptions: func(syscall.RawConn) error { return nil },
+		}
+		Expect(tr.init(false)).To(MatchError("cannot set socket options on a *quic.MockPacketConn"))
+	})
+
+	DescribeTable("connection ID lengths",
+		func(connIDLen int, allowZeroLengthConnIDs bool, expectedLen int) {
+			packetChan := make(chan packetToRead)
+			tr := &Transport{Conn: newMockPacketConn(packetChan), ConnectionIDLength: connIDLen}
+			Expect(tr.init(allowZeroLengthConnIDs)).To(Succeed())
+			Expect(tr.connIDLen).To(Equal(expectedLen))
+			connID, err := tr.connIDGenerator.GenerateConnectionID()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(connID.Len()).To(Equal(expectedLen))
+			// make sure that short header packets are parsed using this length
+			b, err := wire.AppendShortHeader(nil, connID, 1337, protocol.PacketNumberLen2, protocol.KeyPhaseOne)
+			Expect(err).ToNot(HaveOccurred())
+			parsed, err := wire.ParseConnectionID(append(b, make([]byte, 20)...), tr.connIDLen)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(parsed).To(Equal(connID))
+			// shutdown
+			close(packetChan)
+			tr.Close()
+		},
+		Entry("20 bytes", 20, false, 20),
+		Entry("1 byte", 1, false, 1),
+		Entry("default", 0, false, protocol.DefaultConnectionIDLength),
+		Entry("0 bytes, for single-use clients", 0, true, 0),
+	)
+
 	It("allows receiving non-QUIC packets", func() {
 		remoteAddr := &net.UDPAddr{IP: net.IPv4(9, 8, 7, 6), Port: 1234}
 		packetChan := make(chan packetToRead)
@@ -382,6 +614,160 @@ var _ = Describe("Transport", func() {
 		tr.Close()
 	})
 
+	It("passes non-QUIC packets to the unknown packet handler", func() {
+		remoteAddr := &net.UDPAddr{IP: net.IPv4(9, 8, 7, 6), Port: 1234}
+		packetChan := make(chan packetToRead)
+		tr := &Transport{
+			Conn:               newMockPacketConn(packetChan),
+			ConnectionIDLength: 10,
+		}
+		type unknownPacket struct {
+			data []byte
+			addr net.Addr
+		}
+		receivedPacketChan := make(chan unknownPacket, 1)
+		tr.SetUnknownPacketHandler(func(data []byte, addr net.Addr) {
+			receivedPacketChan <- unknownPacket{data: slices.Clone(data), addr: addr}
+		})
+		tr.init(true)
+		packetChan <- packetToRead{
+			addr: remoteAddr,
+			data: []byte{0 /* don't set the QUIC bit */, 1, 2, 3},
+		}
+		var p unknownPacket
+		Eventually(receivedPacketChan).Should(Receive(&p))
+		Expect(p.data).To(Equal([]byte{0, 1, 2, 3}))
+		Expect(p.addr).To(Equal(remoteAddr))
+
+		// shutdown
+		close(packetChan)
+		tr.Close()
+	})
+
+	It("doesn't pass packets with an unset QUIC bit to a connection that didn't enable greasing", func() {
+		packetChan := make(chan packetToRead)
+		tr := &Transport{Conn: newMockPacketConn(packetChan), ConnectionIDLength: 8}
+		receivedPacketChan := make(chan []byte, 1)
+		tr.SetUnknownPacketHandler(func(data []byte, _ net.Addr) { receivedPacketChan <- slices.Clone(data) })
+		tr.init(true)
+		connID := protocol.ParseConnectionID([]byte{1, 2, 3, 4, 5, 6, 7, 8})
+		h := NewMockPacketHandler(mockCtrl)
+		h.EXPECT().acceptsGreasedQUICBit().Return(false)
+		Expect(tr.handlerMap.Add(connID, h)).To(BeTrue())
+
+		b := []byte{0 /* don't set the QUIC bit */, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+		packetChan <- packetToRead{data: b}
+		Eventually(receivedPacketChan).Should(Receive(Equal(b)))
+
+		// shutdown
+		h.EXPECT().destroy(gomock.Any())
+		close(packetChan)
+		tr.Close()
+	})
+
+	It("doesn't pass packets with an unset QUIC bit to a connection using zero-length connection IDs", func() {
+		remoteAddr := &net.UDPAddr{IP: net.IPv4(9, 8, 7, 6), Port: 1234}
+		packetChan := make(chan packetToRead)
+		// a Transport used for dialing, like the one created by Dial
+		tr := &Transport{Conn: newMockPacketConn(packetChan)}
+		tr.init(true)
+		h := NewMockPacketHandler(mockCtrl)
+		Expect(tr.handlerMap.Add(protocol.ConnectionID{}, h)).To(BeTrue())
+
+		receivedPacketChan := make(chan []byte)
+		go func() {
+			defer GinkgoRecover()
+			b := make([]byte, 100)
+			n, addr, err := tr.ReadNonQUICPacket(context.Background(), b)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(addr).To(Equal(remoteAddr))
+			receivedPacketChan <- b[:n]
+		}()
+		// Receiving of non-QUIC packets is enabled when ReadNonQUICPacket is called.
+		// Give the Go routine some time to spin up.
+		time.Sleep(scaleDuration(50 * time.Millisecond))
+		packetChan <- packetToRead{
+			addr: remoteAddr,
+			data: []byte{0 /* don't set the QUIC bit */, 1, 2, 3},
+		}
+		Eventually(receivedPacketChan).Should(Receive(Equal([]byte{0, 1, 2, 3})))
+
+		// shutdown
+		h.EXPECT().destroy(gomock.Any())
+		close(packetChan)
+		tr.Close()
+	})
+
+	It("passes long header packets to the unknown packet handler, if no server is set", func() {
+		packetChan := make(chan packetToRead)
+		tr := &Transport{
+			Conn:               newMockPacketConn(packetChan),
+			ConnectionIDLength: 10,
+		}
+		receivedPacketChan := make(chan []byte, 1)
+		tr.SetUnknownPacketHandler(func(data []byte, _ net.Addr) { receivedPacketChan <- slices.Clone(data) })
+		tr.init(true)
+		b := getPacket(protocol.ParseConnectionID([]byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}))
+		packetChan <- packetToRead{data: b}
+		Eventually(receivedPacketChan).Should(Receive(Equal(b)))
+
+		// once the handler is removed, packets are dropped
+		tr.SetUnknownPacketHandler(nil)
+		packetChan <- packetToRead{data: b}
+		Consistently(receivedPacketChan).ShouldNot(Receive())
+
+		// shutdown
+		close(packetChan)
+		tr.Close()
+	})
+
+	It("passes long header packets that don't start a new connection to the unknown packet handler, if a server is set", func() {
+		packetChan := make(chan packetToRead)
+		tr := &Transport{Conn: newMockPacketConn(packetChan), ConnectionIDLength: 10}
+		ln, err := tr.Listen(&tls.Config{}, nil)
+		Expect(err).ToNot(HaveOccurred())
+		receivedPacketChan := make(chan []byte, 1)
+		tr.SetUnknownPacketHandler(func(data []byte, _ net.Addr) { receivedPacketChan <- slices.Clone(data) })
+
+		// an RTP packet (RFC 7983)
+		rtp := []byte{0x80, 0x60, 0x12, 0x34, 0x00, 0x00, 0x00, 0x01, 0xde, 0xad, 0xbe, 0xef}
+		packetChan <- packetToRead{data: rtp}
+		Eventually(receivedPacketChan).Should(Receive(Equal(rtp)))
+		// a Handshake packet not belonging to any connection
+		b := getPacket(protocol.ParseConnectionID([]byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}))
+		packetChan <- packetToRead{data: b}
+		Eventually(receivedPacketChan).Should(Receive(Equal(b)))
+		// Initial packets are passed to the server
+		packetChan <- packetToRead{data: getPacketWithPacketType(protocol.ParseConnectionID([]byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}), protocol.PacketTypeInitial, 2)}
+		Consistently(receivedPacketChan).ShouldNot(Receive())
+
+		// shutdown
+		Expect(ln.Close()).To(Succeed())
+		close(packetChan)
+		tr.Close()
+	})
+
+	It("passes RTP packets to the unknown packet handler, when using zero-length connection IDs", func() {
+		packetChan := make(chan packetToRead)
+		// a Transport used for dialing, like the one created by Dial
+		tr := &Transport{Conn: newMockPacketConn(packetChan)}
+		receivedPacketChan := make(chan []byte, 1)
+		tr.SetUnknownPacketHandler(func(data []byte, _ net.Addr) { receivedPacketChan <- slices.Clone(data) })
+		tr.init(true)
+		h := NewMockPacketHandler(mockCtrl)
+		Expect(tr.handlerMap.Add(protocol.ConnectionID{}, h)).To(BeTrue())
+
+		// Parsed as a long header packet, the destination connection ID of this RTP packet would be empty.
+		rtp := []byte{0x80, 0x60, 0x12, 0x34, 0x00, 0x00, 0x00, 0x01, 0xde, 0xad, 0xbe, 0xef}
+		packetChan <- packetToRead{data: rtp}
+		Eventually(receivedPacketChan).Should(Receive(Equal(rtp)))
+
+		// shutdown
+		h.EXPECT().destroy(gomock.Any())
+		close(packetChan)
+		tr.Close()
+	})
+
 	It("drops non-QUIC packet if the application doesn't process them quickly enough", func() {
 		remoteAddr := &net.UDPAddr{IP: net.IPv4(9, 8, 7, 6), Port: 1234}
 		packetChan := make(chan packetToRead)